@@ -7,6 +7,7 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // clinic time zones must load on images without a zoneinfo database
 
 	"github.com/labstack/echo/v4"
 	echomw "github.com/labstack/echo/v4/middleware"
@@ -16,6 +17,7 @@ import (
 	"github.com/tqvdang/physioflow/apps/api/internal/config"
 	"github.com/tqvdang/physioflow/apps/api/internal/handler"
 	"github.com/tqvdang/physioflow/apps/api/internal/middleware"
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
 	"github.com/tqvdang/physioflow/apps/api/internal/repository"
 	"github.com/tqvdang/physioflow/apps/api/internal/service"
 )
@@ -72,7 +74,32 @@ func main() {
 	h := handler.New(svc)
 
 	// Register routes
-	registerRoutes(e, h, cfg)
+	registerRoutes(e, h, svc, cfg)
+
+	// End status board event streams on shutdown so that the server does not
+	// wait for them to close
+	e.Server.RegisterOnShutdown(func() {
+		repo.Events().Close()
+	})
+
+	// Start background jobs
+	jobCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	if cfg.Jobs.NoShowInterval > 0 {
+		go service.RunNoShowJob(jobCtx, svc.Attendance(), time.Duration(cfg.Jobs.NoShowInterval)*time.Second)
+	}
+	if cfg.Jobs.ProgressionInterval > 0 {
+		go service.RunProgressionJob(jobCtx, svc.Progression(), time.Duration(cfg.Jobs.ProgressionInterval)*time.Second)
+	}
+	if cfg.Jobs.ProtocolInterval > 0 {
+		go service.RunProtocolJob(jobCtx, svc.Protocol(), time.Duration(cfg.Jobs.ProtocolInterval)*time.Second)
+	}
+	if cfg.Jobs.AdherenceInterval > 0 {
+		go service.RunAdherenceJob(jobCtx, svc.Adherence(), time.Duration(cfg.Jobs.AdherenceInterval)*time.Second)
+	}
+	if cfg.Jobs.ExportInterval > 0 {
+		go svc.Export().RunWorker(jobCtx, time.Duration(cfg.Jobs.ExportInterval)*time.Second)
+	}
 
 	// Start server
 	go func() {
@@ -89,6 +116,7 @@ func main() {
 	<-quit
 
 	log.Info().Msg("shutting down server")
+	stopJobs()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -100,20 +128,35 @@ func main() {
 	log.Info().Msg("server exited")
 }
 
-func registerRoutes(e *echo.Echo, h *handler.Handler, cfg *config.Config) {
+func registerRoutes(e *echo.Echo, h *handler.Handler, svc *service.Service, cfg *config.Config) {
 	// Health endpoints (no auth required)
 	e.GET("/health", h.Health.Health)
 	e.GET("/ready", h.Health.Ready)
 
+	// Calendar feeds (the secret token in the URL is the credential, since
+	// calendar clients cannot send auth headers)
+	e.GET("/ical/therapist/:token", h.Calendar.TherapistFeed)
+	e.GET("/ical/patient/:token", h.Calendar.PatientFeed)
+
 	// API v1 routes
 	v1 := e.Group("/api/v1")
 
+	// Self check-in kiosks (authenticated with a kiosk device token, which
+	// grants nothing else)
+	kiosk := v1.Group("/kiosk", middleware.KioskAuth(svc.Kiosk()))
+	kiosk.POST("/check-in/lookup", h.Kiosk.Lookup)
+	kiosk.POST("/check-in", h.Kiosk.CheckIn)
+
+	// Status board event stream (a bearer token, or a short-lived ticket in the
+	// URL for browsers' EventSource, which cannot send auth headers)
+	v1.GET("/status-board/events", h.StatusBoard.Events, middleware.StreamAuth(cfg, svc.StatusBoard()))
+
 	// Protected routes
 	api := v1.Group("")
 	api.Use(middleware.Auth(cfg))
 
 	// Patient routes
-	patients := api.Group("/patients")
+	patients := api.Group("/patients", middleware.RequireStaff())
 	patients.GET("", h.Patient.List)
 	patients.POST("", h.Patient.Create)
 	patients.GET("/search", h.Patient.Search)
@@ -122,6 +165,43 @@ func registerRoutes(e *echo.Echo, h *handler.Handler, cfg *config.Config) {
 	patients.PUT("/:id", h.Patient.Update)
 	patients.DELETE("/:id", h.Patient.Delete)
 	patients.GET("/:id/dashboard", h.Patient.Dashboard)
+	patients.GET("/:id/timeline", h.Patient.Timeline)
+
+	// Caregiver proxy access (nested under patients)
+	patients.GET("/:id/proxies", h.Proxy.List)
+	patients.POST("/:id/proxies", h.Proxy.Create)
+	patients.DELETE("/:id/proxies/:grantId", h.Proxy.Revoke)
+
+	// Patient consents (nested under patients)
+	patients.GET("/:id/consents", h.Consent.ListPatientConsents)
+	patients.POST("/:id/consents", h.Consent.RecordConsent)
+	patients.POST("/:id/consents/:consentId/withdraw", h.Consent.WithdrawConsent)
+
+	// Patient attachments (nested under patients)
+	patients.GET("/:id/attachments", h.Attachment.List)
+	patients.POST("/:id/attachments", h.Attachment.Upload)
+	patients.GET("/:id/attachments/:attachmentId", h.Attachment.Download)
+	patients.DELETE("/:id/attachments/:attachmentId", h.Attachment.Delete)
+
+	// Patient data exports (nested under patients)
+	patients.GET("/:id/exports", h.Export.List)
+	patients.POST("/:id/exports", h.Export.Request)
+
+	// Patient calendar feeds (nested under patients)
+	patients.GET("/:id/calendar-feeds", h.Calendar.ListPatientFeeds)
+	patients.POST("/:id/calendar-feeds", h.Calendar.IssuePatientFeed)
+	patients.DELETE("/:id/calendar-feeds/:feedId", h.Calendar.RevokePatientFeed)
+
+	// Export jobs
+	exports := api.Group("/exports", middleware.RequireStaff())
+	exports.GET("/:id", h.Export.Get)
+	exports.GET("/:id/download", h.Export.Download)
+
+	// Consent templates
+	consentTemplates := api.Group("/consent-templates", middleware.RequireStaff())
+	consentTemplates.GET("", h.Consent.ListTemplates)
+	consentTemplates.GET("/:id", h.Consent.GetTemplate)
+	consentTemplates.POST("", h.Consent.PublishTemplate, middleware.RequireAdmin())
 
 	// Patient visit checklists (nested under patients)
 	patients.POST("/:pid/visit-checklists", h.Checklist.StartChecklist)
@@ -134,12 +214,12 @@ func registerRoutes(e *echo.Echo, h *handler.Handler, cfg *config.Config) {
 	patients.GET("/:pid/rom-history", h.QuickActions.GetROMHistory)
 
 	// Checklist templates
-	templates := api.Group("/checklist-templates")
+	templates := api.Group("/checklist-templates", middleware.RequireStaff())
 	templates.GET("", h.Checklist.ListTemplates)
 	templates.GET("/:id", h.Checklist.GetTemplate)
 
 	// Visit checklists
-	checklists := api.Group("/visit-checklists")
+	checklists := api.Group("/visit-checklists", middleware.RequireStaff())
 	checklists.GET("/:id", h.Checklist.GetChecklist)
 	checklists.PATCH("/:id/responses", h.Checklist.UpdateResponses)
 	checklists.PATCH("/:id/responses/:itemId", h.Checklist.UpdateResponse)
@@ -147,27 +227,168 @@ func registerRoutes(e *echo.Echo, h *handler.Handler, cfg *config.Config) {
 	checklists.GET("/:id/auto-note", h.Checklist.PreviewNote)
 
 	// Appointment routes
-	appointments := api.Group("/appointments")
+	appointments := api.Group("/appointments", middleware.RequireStaff())
 	appointments.GET("", h.Appointment.List)
 	appointments.POST("", h.Appointment.Create)
+	appointments.POST("/availability/search", h.Appointment.SearchAvailability)
+	appointments.POST("/series/preview", h.Appointment.PreviewSeries)
+	appointments.POST("/series", h.Appointment.CreateSeries)
+	appointments.GET("/series/:id", h.Appointment.GetSeries)
 	appointments.GET("/:id", h.Appointment.Get)
 	appointments.PUT("/:id", h.Appointment.Update)
+	appointments.PUT("/:id/series", h.Appointment.UpdateSeries)
 	appointments.DELETE("/:id", h.Appointment.Delete)
 	appointments.POST("/:id/cancel", h.Appointment.Cancel)
+	appointments.GET("/:id/ics", h.Calendar.AppointmentICS)
+	appointments.POST("/:id/check-in", h.StatusBoard.CheckIn)
+	appointments.GET("/:id/check-in-token", h.Kiosk.CheckInToken)
 	appointments.GET("/day/:date", h.Appointment.GetDaySchedule)
 
+	// Live clinic status board
+	statusBoard := api.Group("/status-board", middleware.RequireStaff())
+	statusBoard.GET("", h.StatusBoard.Snapshot)
+	statusBoard.POST("/events/ticket", h.StatusBoard.StreamTicket)
+
+	// Kiosk device routes
+	kiosks := api.Group("/kiosks", middleware.RequireAdmin())
+	kiosks.GET("", h.Kiosk.ListDevices)
+	kiosks.POST("", h.Kiosk.RegisterDevice)
+	kiosks.DELETE("/:id", h.Kiosk.RevokeDevice)
+
 	// Therapist routes
-	therapists := api.Group("/therapists")
+	therapists := api.Group("/therapists", middleware.RequireStaff())
 	therapists.GET("", h.Appointment.GetTherapists)
 	therapists.GET("/:id/availability", h.Appointment.GetTherapistAvailability)
+	therapists.GET("/:id/schedules", h.Schedule.ListSchedules)
+	therapists.POST("/:id/schedules", h.Schedule.CreateSchedule, middleware.RequireAdmin())
+	therapists.PUT("/:id/schedules/:scheduleId", h.Schedule.UpdateSchedule, middleware.RequireAdmin())
+	therapists.DELETE("/:id/schedules/:scheduleId", h.Schedule.DeleteSchedule, middleware.RequireAdmin())
+	therapists.GET("/:id/exceptions", h.Schedule.ListExceptions)
+	therapists.POST("/:id/exceptions", h.Schedule.CreateException, middleware.RequireAdmin())
+	therapists.PUT("/:id/exceptions/:exceptionId", h.Schedule.UpdateException, middleware.RequireAdmin())
+	therapists.DELETE("/:id/exceptions/:exceptionId", h.Schedule.DeleteException, middleware.RequireAdmin())
+	therapists.GET("/:id/adherence-alerts", h.Adherence.ListTherapistAlerts)
+
+	// Therapist calendar feeds (nested under therapists)
+	therapists.GET("/:id/calendar-feeds", h.Calendar.ListTherapistFeeds)
+	therapists.POST("/:id/calendar-feeds", h.Calendar.IssueTherapistFeed)
+	therapists.DELETE("/:id/calendar-feeds/:feedId", h.Calendar.RevokeTherapistFeed)
+
+	// Resource routes (rooms and equipment)
+	resources := api.Group("/resources", middleware.RequireStaff())
+	resources.GET("", h.Resource.List)
+	resources.GET("/day/:date", h.Resource.GetDayView)
+	resources.GET("/:id", h.Resource.Get)
+	resources.POST("", h.Resource.Create, middleware.RequireAdmin())
+	resources.PUT("/:id", h.Resource.Update, middleware.RequireAdmin())
+	resources.DELETE("/:id", h.Resource.Delete, middleware.RequireAdmin())
+
+	// Appointment type routes (per-clinic configuration)
+	appointmentTypes := api.Group("/appointment-types", middleware.RequireStaff())
+	appointmentTypes.GET("", h.AppointmentType.List)
+	appointmentTypes.GET("/:id", h.AppointmentType.Get)
+	appointmentTypes.POST("", h.AppointmentType.Create, middleware.RequireAdmin())
+	appointmentTypes.PUT("/:id", h.AppointmentType.Update, middleware.RequireAdmin())
+	appointmentTypes.DELETE("/:id", h.AppointmentType.Delete, middleware.RequireAdmin())
+
+	// Group session routes (classes with a roster and waitlist)
+	groupSessions := api.Group("/group-sessions", middleware.RequireStaff())
+	groupSessions.GET("", h.GroupSession.List)
+	groupSessions.POST("", h.GroupSession.Create)
+	groupSessions.GET("/:id", h.GroupSession.Get)
+	groupSessions.PUT("/:id", h.GroupSession.Update)
+	groupSessions.POST("/:id/cancel", h.GroupSession.Cancel)
+	groupSessions.POST("/:id/participants", h.GroupSession.AddParticipant)
+	groupSessions.PUT("/:id/participants/:participantId", h.GroupSession.UpdateParticipant)
 
 	// Exercise routes
-	exercises := api.Group("/exercises")
+	exercises := api.Group("/exercises", middleware.RequireStaff())
 	exercises.GET("", h.Exercise.List)
 	exercises.GET("/:id", h.Exercise.Get)
 	exercises.POST("", h.Exercise.Create)
 	exercises.PUT("/:id", h.Exercise.Update)
 	exercises.DELETE("/:id", h.Exercise.Delete)
 	exercises.POST("/:id/prescribe", h.Exercise.PrescribeExercise)
+	exercises.GET("/:id/media", h.Exercise.ListExerciseMedia)
+	exercises.POST("/:id/media", h.Exercise.UploadExerciseMedia)
+	exercises.PUT("/:id/media/order", h.Exercise.ReorderExerciseMedia)
+	exercises.GET("/:id/media/:mediaId", h.Exercise.GetExerciseMedia)
+	exercises.GET("/:id/media/:mediaId/thumbnail", h.Exercise.GetExerciseMediaThumbnail)
+	exercises.PUT("/:id/media/:mediaId", h.Exercise.UpdateExerciseMedia)
+	exercises.DELETE("/:id/media/:mediaId", h.Exercise.DeleteExerciseMedia)
 	exercises.GET("/search", h.Exercise.Search)
+	exercises.GET("/export", h.Exercise.ExportExercises)
+	exercises.POST("/recommendations", h.Exercise.RecommendExercises)
+	exercises.POST("/import", h.Exercise.ImportExercises, middleware.RequireAdmin())
+	exercises.POST("/:id/publish", h.Exercise.PublishExercise, middleware.RequireAdmin())
+
+	// Patient exercise prescriptions (nested under patients)
+	patients.GET("/:pid/exercises", h.Exercise.GetPatientExercises)
+	patients.POST("/:pid/exercises", h.Exercise.PrescribeExercise)
+	patients.GET("/:pid/exercises/handout", h.Exercise.GetHandout)
+	patients.GET("/:pid/exercises/compliance", h.Exercise.GetComplianceSummary)
+	patients.GET("/:pid/exercises/adherence", h.Adherence.GetPatientAdherence)
+	patients.PUT("/:pid/exercises/:id", h.Exercise.UpdatePrescription)
+	patients.DELETE("/:pid/exercises/:id", h.Exercise.DeletePrescription)
+	patients.POST("/:pid/exercises/:id/log", h.Exercise.LogCompliance)
+	patients.GET("/:pid/exercises/:id/history", h.Exercise.GetPrescriptionHistory)
+	patients.GET("/:pid/exercises/:id/progression-rules", h.Exercise.ListProgressionRules)
+	patients.POST("/:pid/exercises/:id/progression-rules", h.Exercise.CreateProgressionRule)
+	patients.POST("/:pid/exercises/:id/progression-rules/evaluate", h.Exercise.EvaluateProgression)
+	patients.PUT("/:pid/exercises/:id/progression-rules/:ruleId", h.Exercise.UpdateProgressionRule)
+	patients.DELETE("/:pid/exercises/:id/progression-rules/:ruleId", h.Exercise.DeleteProgressionRule)
+
+	// Exercise progressions awaiting approval
+	progressions := api.Group("/exercise-progressions", middleware.RequireStaff())
+	progressions.GET("", h.Exercise.ListPendingProgressions)
+	progressions.POST("/:id/approve", h.Exercise.ApproveProgression)
+	progressions.POST("/:id/reject", h.Exercise.RejectProgression)
+
+	// Exercise protocol routes
+	protocols := api.Group("/exercise-protocols", middleware.RequireStaff())
+	protocols.GET("", h.Protocol.List)
+	protocols.GET("/:id", h.Protocol.Get)
+	protocols.POST("", h.Protocol.Create)
+	protocols.PUT("/:id", h.Protocol.Update)
+	patients.POST("/:pid/exercise-programs/from-protocol", h.Protocol.Instantiate)
+	patients.GET("/:pid/exercise-programs/:programId", h.Protocol.GetProgram)
+
+	// Exercise adherence routes
+	adherence := api.Group("/exercise-adherence", middleware.RequireStaff())
+	adherence.GET("/low", h.Adherence.ListLowAdherence)
+	adherence.GET("/alerts", h.Adherence.ListAlerts)
+	adherence.POST("/alerts/:id/acknowledge", h.Adherence.AcknowledgeAlert)
+
+	// Patient portal routes (self-service for the authenticated patient, or a
+	// caregiver acting under a proxy grant)
+	me := api.Group("/me",
+		middleware.RequireRole(middleware.RolePatient, middleware.RoleCaregiver),
+		middleware.ProxyAccess(svc.Proxy()),
+	)
+	me.GET("", h.Portal.GetProfile, middleware.RequireProxyScope(model.ProxyScopeViewProfile))
+	me.GET("/appointments", h.Portal.ListAppointments, middleware.RequireProxyScope(model.ProxyScopeViewAppointments))
+	me.POST("/appointments/:id/cancel", h.Portal.CancelAppointment, middleware.RequireProxyScope(model.ProxyScopeManageAppointments))
+	me.POST("/appointments/:id/reschedule", h.Portal.RescheduleAppointment, middleware.RequireProxyScope(model.ProxyScopeManageAppointments))
+	me.GET("/appointments/:id/ics", h.Calendar.PortalAppointmentICS, middleware.RequireProxyScope(model.ProxyScopeViewAppointments))
+	me.GET("/appointments/:id/check-in-token", h.Kiosk.PortalCheckInToken, middleware.RequireProxyScope(model.ProxyScopeViewAppointments))
+	me.GET("/exercises", h.Portal.GetExercisePlan, middleware.RequireProxyScope(model.ProxyScopeViewExercises))
+	me.POST("/exercises/:id/log", h.Portal.LogCompliance, middleware.RequireProxyScope(model.ProxyScopeLogExercises))
+	me.GET("/progress", h.Portal.GetProgress, middleware.RequireProxyScope(model.ProxyScopeViewProgress))
+	me.GET("/proxies", h.Portal.ListProxyGrants)
+	me.POST("/proxies", h.Portal.GrantProxyAccess)
+	me.DELETE("/proxies/:id", h.Portal.RevokeProxyAccess)
+	me.GET("/caring-for", h.Portal.ListCaregiverPatients)
+	me.GET("/consent-templates", h.Portal.ListConsentTemplates)
+	me.GET("/consents", h.Portal.ListConsents)
+	me.POST("/consents", h.Portal.RecordConsent)
+	me.POST("/consents/:id/withdraw", h.Portal.WithdrawConsent)
+	me.GET("/exports", h.Portal.ListExports)
+	me.POST("/exports", h.Portal.RequestExport)
+	me.GET("/exports/:id", h.Portal.GetExport)
+	me.GET("/exports/:id/download", h.Portal.DownloadExport)
+
+	// Portal calendar feeds
+	me.GET("/calendar-feeds", h.Calendar.ListPortalFeeds)
+	me.POST("/calendar-feeds", h.Calendar.IssuePortalFeed)
+	me.DELETE("/calendar-feeds/:id", h.Calendar.RevokePortalFeed)
 }
@@ -0,0 +1,49 @@
+// Command backfill-frequencies parses the free-text frequencies of exercise
+// prescriptions and home exercise programs stored before structured schedules
+// existed, and stores the schedules of those it understands.
+//
+// Usage:
+//
+//	backfill-frequencies
+//
+// Run it once after applying migration 021_frequency_schedules.sql. Running
+// it again only parses frequencies that still have no schedule.
+package main
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/config"
+	"github.com/tqvdang/physioflow/apps/api/internal/repository"
+	"github.com/tqvdang/physioflow/apps/api/internal/service"
+)
+
+func main() {
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to load configuration")
+	}
+
+	db, err := repository.NewDB(&cfg.Database)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to connect to database")
+	}
+	repo := repository.NewWithDB(cfg, db)
+	defer repo.Close()
+
+	svc := service.New(repo)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	if _, err := svc.Exercise().MigrateFrequencySchedules(ctx); err != nil {
+		log.Fatal().Err(err).Msg("failed to backfill frequency schedules")
+	}
+}
@@ -7,12 +7,16 @@ import (
 
 // Config holds all application configuration.
 type Config struct {
-	Env      string
-	Server   ServerConfig
-	Database DatabaseConfig
-	Redis    RedisConfig
-	JWT      JWTConfig
-	Keycloak KeycloakConfig
+	Env         string
+	Server      ServerConfig
+	Database    DatabaseConfig
+	Redis       RedisConfig
+	JWT         JWTConfig
+	Keycloak    KeycloakConfig
+	Storage     StorageConfig
+	Jobs        JobsConfig
+	CheckIn     CheckInConfig
+	StatusBoard StatusBoardConfig
 }
 
 // ServerConfig holds HTTP server settings.
@@ -32,9 +36,10 @@ type DatabaseConfig struct {
 
 // RedisConfig holds Redis connection settings.
 type RedisConfig struct {
-	URL      string
+	URL      string // host:port or redis:// URL
 	Password string
 	DB       int
+	PubSub   bool // share live clinic events between API instances through Redis
 }
 
 // JWTConfig holds JWT authentication settings.
@@ -52,6 +57,40 @@ type KeycloakConfig struct {
 	Secret   string
 }
 
+// StorageConfig holds blob storage settings for attachments, exports and
+// exercise media.
+type StorageConfig struct {
+	Path    string // local blob directory, used when no S3 endpoint is set
+	FFprobe string // reads non-MP4 exercise videos; skipped when not installed
+	FFmpeg  string // makes exercise video thumbnails; skipped when not installed
+
+	// S3-compatible object store (e.g. MinIO) shared by every API instance
+	S3Endpoint  string
+	S3Region    string
+	S3Bucket    string
+	S3AccessKey string
+	S3SecretKey string
+}
+
+// CheckInConfig holds patient self check-in settings.
+type CheckInConfig struct {
+	TokenSecret string // signs QR check-in codes; must be shared by every API instance
+}
+
+// StatusBoardConfig holds live status board settings.
+type StatusBoardConfig struct {
+	StreamSecret string // signs event stream tickets; must be shared by every API instance
+}
+
+// JobsConfig holds background job settings. An interval of 0 disables a job.
+type JobsConfig struct {
+	NoShowInterval      int // seconds between no-show sweeps
+	ProgressionInterval int // seconds between exercise progression evaluations
+	ProtocolInterval    int // seconds between advancing protocol program phases
+	AdherenceInterval   int // seconds between low exercise adherence checks
+	ExportInterval      int // seconds between polls for queued patient exports
+}
+
 // Load reads configuration from environment variables.
 func Load() (*Config, error) {
 	return &Config{
@@ -71,6 +110,7 @@ func Load() (*Config, error) {
 			URL:      getEnv("REDIS_URL", "localhost:7013"),
 			Password: getEnv("REDIS_PASSWORD", ""),
 			DB:       getEnvAsInt("REDIS_DB", 0),
+			PubSub:   getEnvAsBool("REDIS_PUBSUB", false),
 		},
 		JWT: JWTConfig{
 			Secret:     getEnv("JWT_SECRET", ""),
@@ -83,6 +123,30 @@ func Load() (*Config, error) {
 			ClientID: getEnv("KEYCLOAK_CLIENT_ID", "physioflow-api"),
 			Secret:   getEnv("KEYCLOAK_SECRET", ""),
 		},
+		Storage: StorageConfig{
+			Path:    getEnv("STORAGE_PATH", "./data/blobs"),
+			FFprobe: getEnv("FFPROBE_PATH", "ffprobe"),
+			FFmpeg:  getEnv("FFMPEG_PATH", "ffmpeg"),
+
+			S3Endpoint:  getEnv("S3_ENDPOINT", ""),
+			S3Region:    getEnv("S3_REGION", "us-east-1"),
+			S3Bucket:    getEnv("S3_BUCKET", "physioflow"),
+			S3AccessKey: getEnv("S3_ACCESS_KEY", ""),
+			S3SecretKey: getEnv("S3_SECRET_KEY", ""),
+		},
+		Jobs: JobsConfig{
+			NoShowInterval:      getEnvAsInt("NO_SHOW_JOB_INTERVAL", 300),
+			ProgressionInterval: getEnvAsInt("PROGRESSION_JOB_INTERVAL", 3600),
+			ProtocolInterval:    getEnvAsInt("PROTOCOL_JOB_INTERVAL", 3600),
+			AdherenceInterval:   getEnvAsInt("ADHERENCE_JOB_INTERVAL", 21600),
+			ExportInterval:      getEnvAsInt("EXPORT_JOB_INTERVAL", 30),
+		},
+		CheckIn: CheckInConfig{
+			TokenSecret: getEnv("CHECKIN_TOKEN_SECRET", ""),
+		},
+		StatusBoard: StatusBoardConfig{
+			StreamSecret: getEnv("STATUS_BOARD_STREAM_SECRET", ""),
+		},
 	}, nil
 }
 
@@ -111,3 +175,12 @@ func getEnvAsInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+func getEnvAsBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
@@ -0,0 +1,115 @@
+// Package events fans clinic events out to live subscribers such as the
+// status board, within one API instance or across instances through Redis
+// pub/sub. Events are opaque payloads addressed to a clinic; delivery is best
+// effort and subscribers resync from a snapshot when they fall behind.
+package events
+
+import (
+	"context"
+	"sync"
+)
+
+// subscriberBuffer is how many events a subscriber may fall behind before it
+// is dropped.
+const subscriberBuffer = 64
+
+// Bus publishes events to the subscribers of a clinic.
+type Bus interface {
+	Publish(ctx context.Context, clinicID string, payload []byte) error
+	Subscribe(clinicID string) *Subscription
+	Close() error
+}
+
+// Subscription receives a clinic's events on C. C is closed when the
+// subscription is closed, when the bus shuts down, or when the subscriber
+// falls too far behind; in the last case it should resync and subscribe again.
+type Subscription struct {
+	C <-chan []byte
+
+	ch       chan []byte
+	clinicID string
+	bus      *MemoryBus
+	once     sync.Once
+}
+
+// Close ends the subscription.
+func (s *Subscription) Close() {
+	s.bus.remove(s)
+}
+
+// MemoryBus delivers events to subscribers in this process.
+type MemoryBus struct {
+	mu     sync.Mutex
+	subs   map[string]map[*Subscription]struct{}
+	closed bool
+}
+
+// NewMemoryBus creates an in-process bus.
+func NewMemoryBus() *MemoryBus {
+	return &MemoryBus{subs: make(map[string]map[*Subscription]struct{})}
+}
+
+// Publish delivers an event to the clinic's subscribers without blocking.
+// Subscribers whose buffer is full are dropped.
+func (b *MemoryBus) Publish(ctx context.Context, clinicID string, payload []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for s := range b.subs[clinicID] {
+		select {
+		case s.ch <- payload:
+		default:
+			b.removeLocked(s)
+		}
+	}
+	return nil
+}
+
+// Subscribe starts receiving a clinic's events.
+func (b *MemoryBus) Subscribe(clinicID string) *Subscription {
+	ch := make(chan []byte, subscriberBuffer)
+	s := &Subscription{C: ch, ch: ch, clinicID: clinicID, bus: b}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		close(ch)
+		return s
+	}
+	if b.subs[clinicID] == nil {
+		b.subs[clinicID] = make(map[*Subscription]struct{})
+	}
+	b.subs[clinicID][s] = struct{}{}
+	return s
+}
+
+// Close ends every subscription.
+func (b *MemoryBus) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for _, subs := range b.subs {
+		for s := range subs {
+			b.removeLocked(s)
+		}
+	}
+	return nil
+}
+
+func (b *MemoryBus) remove(s *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.removeLocked(s)
+}
+
+func (b *MemoryBus) removeLocked(s *Subscription) {
+	if subs, ok := b.subs[s.clinicID]; ok {
+		delete(subs, s)
+		if len(subs) == 0 {
+			delete(b.subs, s.clinicID)
+		}
+	}
+	s.once.Do(func() { close(s.ch) })
+}
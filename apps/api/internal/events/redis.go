@@ -0,0 +1,185 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/tqvdang/physioflow/apps/api/pkg/redis"
+)
+
+// channelPrefix is prepended to the clinic ID to name a clinic's channel.
+const channelPrefix = "physioflow:clinic-events:"
+
+// Reconnect backoff for the subscriber connection
+const (
+	minReconnectDelay = 500 * time.Millisecond
+	maxReconnectDelay = 30 * time.Second
+)
+
+// RedisBus shares events between API instances through Redis pub/sub. Every
+// instance, including the publisher, receives events back from its
+// subscription and delivers them to its own subscribers.
+type RedisBus struct {
+	opts  redis.Options
+	local *MemoryBus
+
+	mu  sync.Mutex
+	pub *redis.Conn // publishing connection, dialled on demand
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewRedisBus creates a bus on Redis and starts listening for events.
+func NewRedisBus(opts redis.Options) *RedisBus {
+	ctx, cancel := context.WithCancel(context.Background())
+	b := &RedisBus{
+		opts:   opts,
+		local:  NewMemoryBus(),
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	go b.listen(ctx)
+	return b
+}
+
+// Publish sends an event to every instance. If Redis cannot be reached the
+// event is still delivered to this instance's subscribers.
+func (b *RedisBus) Publish(ctx context.Context, clinicID string, payload []byte) error {
+	if err := b.publish(ctx, clinicID, payload); err != nil {
+		b.local.Publish(ctx, clinicID, payload)
+		return err
+	}
+	return nil
+}
+
+func (b *RedisBus) publish(ctx context.Context, clinicID string, payload []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// One retry covers a publishing connection the server has closed
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if b.pub == nil {
+			if b.pub, err = redis.Dial(ctx, b.opts); err != nil {
+				return fmt.Errorf("failed to publish event: %w", err)
+			}
+		}
+		if deadline, ok := ctx.Deadline(); ok {
+			b.pub.SetDeadline(deadline)
+		} else {
+			b.pub.SetDeadline(time.Now().Add(5 * time.Second))
+		}
+		if _, err = b.pub.Do("PUBLISH", channelPrefix+clinicID, string(payload)); err == nil {
+			return nil
+		}
+		b.pub.Close()
+		b.pub = nil
+	}
+	return fmt.Errorf("failed to publish event: %w", err)
+}
+
+// Subscribe starts receiving a clinic's events.
+func (b *RedisBus) Subscribe(clinicID string) *Subscription {
+	return b.local.Subscribe(clinicID)
+}
+
+// Ping checks that Redis is reachable.
+func (b *RedisBus) Ping(ctx context.Context) error {
+	conn, err := redis.Dial(ctx, b.opts)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	_, err = conn.Do("PING")
+	return err
+}
+
+// Close stops listening and ends every subscription.
+func (b *RedisBus) Close() error {
+	b.cancel()
+	<-b.done
+
+	b.mu.Lock()
+	if b.pub != nil {
+		b.pub.Close()
+		b.pub = nil
+	}
+	b.mu.Unlock()
+
+	return b.local.Close()
+}
+
+// listen keeps a pattern subscription to every clinic's channel open,
+// reconnecting with backoff, until the bus is closed.
+func (b *RedisBus) listen(ctx context.Context) {
+	defer close(b.done)
+
+	delay := minReconnectDelay
+	for ctx.Err() == nil {
+		err := b.receive(ctx, func() { delay = minReconnectDelay })
+		if ctx.Err() != nil {
+			return
+		}
+		log.Warn().Err(err).Dur("retry_in", delay).Msg("event subscription to redis lost")
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		if delay *= 2; delay > maxReconnectDelay {
+			delay = maxReconnectDelay
+		}
+	}
+}
+
+// receive subscribes and delivers messages until the connection fails or the
+// context is done. subscribed is called once the subscription is confirmed.
+func (b *RedisBus) receive(ctx context.Context, subscribed func()) error {
+	conn, err := redis.Dial(ctx, b.opts)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// Unblock the read below when the bus is closed
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	if err := conn.Send("PSUBSCRIBE", channelPrefix+"*"); err != nil {
+		return err
+	}
+
+	for {
+		reply, err := conn.Receive()
+		if err != nil {
+			return err
+		}
+
+		msg, ok := reply.([]interface{})
+		if !ok || len(msg) == 0 {
+			continue
+		}
+		switch kind, _ := msg[0].(string); kind {
+		case "psubscribe":
+			log.Info().Str("addr", b.opts.Addr).Msg("subscribed to clinic events on redis")
+			subscribed()
+		case "pmessage":
+			if len(msg) != 4 {
+				continue
+			}
+			channel, _ := msg[2].(string)
+			payload, _ := msg[3].(string)
+			clinicID := strings.TrimPrefix(channel, channelPrefix)
+			b.local.Publish(ctx, clinicID, []byte(payload))
+		}
+	}
+}
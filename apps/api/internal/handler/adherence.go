@@ -0,0 +1,226 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/middleware"
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/repository"
+	"github.com/tqvdang/physioflow/apps/api/internal/service"
+)
+
+// AdherenceHandler handles exercise adherence analytics and alerts.
+type AdherenceHandler struct {
+	svc *service.Service
+}
+
+// NewAdherenceHandler creates a new AdherenceHandler.
+func NewAdherenceHandler(svc *service.Service) *AdherenceHandler {
+	return &AdherenceHandler{svc: svc}
+}
+
+// GetPatientAdherence returns a patient's exercise adherence by week.
+// @Summary Get exercise adherence
+// @Description Compares the sessions the patient logged with those their prescribed frequency called for, in seven day windows ending yesterday, overall and per exercise, with each exercise's pain trend
+// @Tags exercises
+// @Produce json
+// @Param pid path string true "Patient ID"
+// @Param weeks query int false "Number of weeks, 1 to 26" default(4)
+// @Success 200 {object} model.PatientAdherence
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/patients/{pid}/exercises/adherence [get]
+func (h *AdherenceHandler) GetPatientAdherence(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	weeks := 4
+	if raw := c.QueryParam("weeks"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_request",
+				Message: "weeks must be a number",
+			})
+		}
+		weeks = parsed
+	}
+
+	adherence, err := h.svc.Adherence().GetPatientAdherence(c.Request().Context(), user.ClinicID, c.Param("pid"), weeks)
+	if err != nil {
+		return adherenceError(c, err, "Patient not found", "Failed to get exercise adherence")
+	}
+	return c.JSON(http.StatusOK, adherence)
+}
+
+// ListLowAdherence returns the clinic's patients with low adherence this week.
+// @Summary List patients with low adherence
+// @Description Returns the patients whose adherence over the past seven days is below the threshold, lowest first, with their adherence the week before
+// @Tags exercises
+// @Produce json
+// @Param threshold query number false "Adherence from 0 to 1; defaults to the clinic's setting"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/exercise-adherence/low [get]
+func (h *AdherenceHandler) ListLowAdherence(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	var threshold *float64
+	if raw := c.QueryParam("threshold"); raw != "" {
+		parsed, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_request",
+				Message: "threshold must be a number",
+			})
+		}
+		threshold = &parsed
+	}
+
+	patients, err := h.svc.Adherence().ListLowAdherence(c.Request().Context(), user.ClinicID, threshold)
+	if err != nil {
+		return adherenceError(c, err, "Clinic not found", "Failed to list low adherence")
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": patients,
+	})
+}
+
+// ListAlerts returns the clinic's low adherence alerts.
+// @Summary List adherence alerts
+// @Description Returns the weekly low adherence alerts, open ones unless status is given, optionally only for patients a therapist prescribed exercises to
+// @Tags exercises
+// @Produce json
+// @Param therapist_id query string false "Therapist ID"
+// @Param status query string false "open, acknowledged, resolved or all" default(open)
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/exercise-adherence/alerts [get]
+func (h *AdherenceHandler) ListAlerts(c echo.Context) error {
+	return h.listAlerts(c, c.QueryParam("therapist_id"))
+}
+
+// ListTherapistAlerts returns the low adherence alerts for a therapist's
+// dashboard.
+// @Summary List a therapist's adherence alerts
+// @Description Returns the weekly low adherence alerts of the patients the therapist prescribed exercises to, open ones unless status is given
+// @Tags therapists
+// @Produce json
+// @Param id path string true "Therapist ID"
+// @Param status query string false "open, acknowledged, resolved or all" default(open)
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/therapists/{id}/adherence-alerts [get]
+func (h *AdherenceHandler) ListTherapistAlerts(c echo.Context) error {
+	return h.listAlerts(c, c.Param("id"))
+}
+
+// listAlerts lists alerts, optionally for one therapist.
+func (h *AdherenceHandler) listAlerts(c echo.Context, therapistID string) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	status := c.QueryParam("status")
+	switch model.AdherenceAlertStatus(status) {
+	case "":
+		status = string(model.AdherenceAlertOpen)
+	case "all":
+		status = ""
+	case model.AdherenceAlertOpen, model.AdherenceAlertAcknowledged, model.AdherenceAlertResolved:
+	default:
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "status must be open, acknowledged, resolved or all",
+		})
+	}
+
+	alerts, err := h.svc.Adherence().ListAlerts(c.Request().Context(), model.AdherenceAlertParams{
+		ClinicID:    user.ClinicID,
+		TherapistID: therapistID,
+		Status:      status,
+	})
+	if err != nil {
+		return adherenceError(c, err, "Alert not found", "Failed to list adherence alerts")
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": alerts,
+	})
+}
+
+// AcknowledgeAlert marks a low adherence alert as seen.
+// @Summary Acknowledge adherence alert
+// @Tags exercises
+// @Produce json
+// @Param id path string true "Alert ID"
+// @Success 200 {object} model.AdherenceAlert
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/exercise-adherence/alerts/{id}/acknowledge [post]
+func (h *AdherenceHandler) AcknowledgeAlert(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	alert, err := h.svc.Adherence().AcknowledgeAlert(c.Request().Context(), user.ClinicID, c.Param("id"), user.UserID)
+	if err != nil {
+		return adherenceError(c, err, "Alert not found", "Failed to acknowledge adherence alert")
+	}
+	return c.JSON(http.StatusOK, alert)
+}
+
+// adherenceError maps adherence service errors to HTTP responses.
+func adherenceError(c echo.Context, err error, notFoundMsg, failureMsg string) error {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "not_found",
+			Message: notFoundMsg,
+		})
+	case errors.Is(err, repository.ErrInvalidInput):
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+	}
+
+	log.Error().Err(err).Str("path", c.Path()).Msg(strings.ToLower(failureMsg))
+	return c.JSON(http.StatusInternalServerError, ErrorResponse{
+		Error:   "internal_error",
+		Message: failureMsg,
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/labstack/echo/v4"
@@ -28,25 +29,29 @@ func NewAppointmentHandler(svc *service.Service) *AppointmentHandler {
 
 // AppointmentResponse represents an appointment in API responses.
 type AppointmentResponse struct {
-	ID                 string `json:"id"`
-	ClinicID           string `json:"clinic_id"`
-	PatientID          string `json:"patient_id"`
-	TherapistID        string `json:"therapist_id"`
-	StartTime          string `json:"start_time"`
-	EndTime            string `json:"end_time"`
-	Duration           int    `json:"duration"`
-	Type               string `json:"type"`
-	Status             string `json:"status"`
-	Room               string `json:"room,omitempty"`
-	Notes              string `json:"notes,omitempty"`
-	CancellationReason string `json:"cancellation_reason,omitempty"`
-	RecurrenceID       string `json:"recurrence_id,omitempty"`
-	PatientName        string `json:"patient_name,omitempty"`
-	PatientMRN         string `json:"patient_mrn,omitempty"`
-	PatientPhone       string `json:"patient_phone,omitempty"`
-	TherapistName      string `json:"therapist_name,omitempty"`
-	CreatedAt          string `json:"created_at"`
-	UpdatedAt          string `json:"updated_at"`
+	ID                 string                     `json:"id"`
+	ClinicID           string                     `json:"clinic_id"`
+	PatientID          string                     `json:"patient_id"`
+	TherapistID        string                     `json:"therapist_id"`
+	StartTime          string                     `json:"start_time"`
+	EndTime            string                     `json:"end_time"`
+	Duration           int                        `json:"duration"`
+	Type               string                     `json:"type"`
+	Status             string                     `json:"status"`
+	Room               string                     `json:"room,omitempty"`
+	Notes              string                     `json:"notes,omitempty"`
+	CancellationReason string                     `json:"cancellation_reason,omitempty"`
+	RecurrenceID       string                     `json:"recurrence_id,omitempty"`
+	ResourceIDs        []string                   `json:"resource_ids,omitempty"`
+	GroupSessionID     string                     `json:"group_session_id,omitempty"`
+	AppointmentTypeID  string                     `json:"appointment_type_id,omitempty"`
+	PatientName        string                     `json:"patient_name,omitempty"`
+	PatientMRN         string                     `json:"patient_mrn,omitempty"`
+	PatientPhone       string                     `json:"patient_phone,omitempty"`
+	TherapistName      string                     `json:"therapist_name,omitempty"`
+	PatientAttendance  *PatientAttendanceResponse `json:"patient_attendance,omitempty"`
+	CreatedAt          string                     `json:"created_at"`
+	UpdatedAt          string                     `json:"updated_at"`
 }
 
 // AppointmentListResponse represents a paginated list of appointments.
@@ -82,6 +87,7 @@ type TherapistResponse struct {
 // DayScheduleResponse represents a day's schedule.
 type DayScheduleResponse struct {
 	Date         string                `json:"date"`
+	Timezone     string                `json:"timezone"`
 	Appointments []AppointmentResponse `json:"appointments"`
 	TotalCount   int                   `json:"total_count"`
 }
@@ -183,7 +189,7 @@ func (h *AppointmentHandler) List(c echo.Context) error {
 
 // Create creates a new appointment.
 // @Summary Create appointment
-// @Description Creates a new appointment with optional recurrence
+// @Description Creates a new appointment of one of the clinic's appointment types, by appointment_type_id or type code; duration defaults to the type's. With rrule or recurrence_pattern the whole series is created and its first appointment returned; conflicting occurrences are reported with a preview.
 // @Tags appointments
 // @Accept json
 // @Produce json
@@ -191,7 +197,7 @@ func (h *AppointmentHandler) List(c echo.Context) error {
 // @Success 201 {object} AppointmentResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
-// @Failure 409 {object} ErrorResponse "Scheduling conflict"
+// @Failure 409 {object} SeriesConflictResponse "Scheduling conflict"
 // @Failure 422 {object} ErrorResponse
 // @Security BearerAuth
 // @Router /api/v1/appointments [post]
@@ -222,6 +228,10 @@ func (h *AppointmentHandler) Create(c echo.Context) error {
 
 	appointment, err := h.svc.Appointment().Create(c.Request().Context(), user.ClinicID, user.UserID, &req)
 	if err != nil {
+		var seriesConflict *service.SeriesConflictError
+		if errors.As(err, &seriesConflict) {
+			return seriesError(c, err, "Failed to create appointment")
+		}
 		if errors.Is(err, repository.ErrInvalidInput) {
 			return c.JSON(http.StatusBadRequest, ErrorResponse{
 				Error:   "invalid_input",
@@ -349,6 +359,12 @@ func (h *AppointmentHandler) Update(c echo.Context) error {
 				Message: "Appointment not found",
 			})
 		}
+		if errors.Is(err, repository.ErrInvalidInput) {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_input",
+				Message: err.Error(),
+			})
+		}
 		// Check for conflict error
 		if errMsg := err.Error(); len(errMsg) > 20 && errMsg[:19] == "scheduling conflict" {
 			return c.JSON(http.StatusConflict, ErrorResponse{
@@ -523,6 +539,7 @@ func (h *AppointmentHandler) GetDaySchedule(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, DayScheduleResponse{
 		Date:         schedule.Date,
+		Timezone:     schedule.Timezone,
 		Appointments: appointments,
 		TotalCount:   schedule.TotalCount,
 	})
@@ -537,6 +554,7 @@ func (h *AppointmentHandler) GetDaySchedule(c echo.Context) error {
 // @Param id path string true "Therapist ID (UUID)"
 // @Param date query string true "Date (YYYY-MM-DD)"
 // @Param duration query int false "Duration in minutes" default(30)
+// @Param resource_ids query string false "Comma-separated IDs of rooms or equipment that must also be free"
 // @Success 200 {array} AvailabilitySlotResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
@@ -582,8 +600,19 @@ func (h *AppointmentHandler) GetTherapistAvailability(c echo.Context) error {
 		}
 	}
 
-	slots, err := h.svc.Appointment().GetAvailableSlots(c.Request().Context(), user.ClinicID, therapistID, date, duration)
+	var resourceIDs []string
+	if ids := c.QueryParam("resource_ids"); ids != "" {
+		resourceIDs = strings.Split(ids, ",")
+	}
+
+	slots, err := h.svc.Appointment().GetAvailableSlots(c.Request().Context(), user.ClinicID, therapistID, date, duration, resourceIDs)
 	if err != nil {
+		if errors.Is(err, repository.ErrInvalidInput) {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_input",
+				Message: err.Error(),
+			})
+		}
 		log.Error().Err(err).Str("therapist_id", therapistID).Str("date", dateStr).Msg("failed to get availability")
 		return c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Error:   "internal_error",
@@ -676,6 +705,18 @@ func toAppointmentResponse(a model.AppointmentWithDetails) AppointmentResponse {
 	if a.RecurrenceID != nil {
 		resp.RecurrenceID = *a.RecurrenceID
 	}
+	if len(a.ResourceIDs) > 0 {
+		resp.ResourceIDs = a.ResourceIDs
+	}
+	if a.GroupSessionID != nil {
+		resp.GroupSessionID = *a.GroupSessionID
+	}
+	if a.AppointmentTypeID != nil {
+		resp.AppointmentTypeID = *a.AppointmentTypeID
+	}
+	if a.PatientAttendance != nil {
+		resp.PatientAttendance = toPatientAttendanceResponse(a.PatientAttendance)
+	}
 
 	return resp
 }
@@ -0,0 +1,353 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/middleware"
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/repository"
+	"github.com/tqvdang/physioflow/apps/api/internal/service"
+	"github.com/tqvdang/physioflow/apps/api/pkg/validator"
+)
+
+// SeriesResponse represents a recurring appointment series in API responses.
+type SeriesResponse struct {
+	ID          string   `json:"id"`
+	PatientID   string   `json:"patient_id"`
+	TherapistID string   `json:"therapist_id"`
+	RRule       string   `json:"rrule"`
+	DTStart     string   `json:"dtstart"`
+	Timezone    string   `json:"timezone"`
+	Duration    int      `json:"duration"`
+	Type        string   `json:"type"`
+	Room        string   `json:"room,omitempty"`
+	Notes       string   `json:"notes,omitempty"`
+	ResourceIDs []string `json:"resource_ids,omitempty"`
+	ExDates     []string `json:"exdates"`
+	CreatedAt   string   `json:"created_at"`
+}
+
+// SeriesOccurrenceResponse represents one occurrence in a series preview.
+type SeriesOccurrenceResponse struct {
+	OriginalStart string               `json:"original_start"`
+	StartTime     string               `json:"start_time"`
+	EndTime       string               `json:"end_time"`
+	Status        string               `json:"status"`
+	Conflicts     []model.ConflictInfo `json:"conflicts,omitempty"`
+}
+
+// SeriesPreviewResponse lists the occurrences a series would create.
+type SeriesPreviewResponse struct {
+	RRule         string                     `json:"rrule"`
+	Occurrences   []SeriesOccurrenceResponse `json:"occurrences"`
+	ConflictCount int                        `json:"conflict_count"`
+}
+
+// SeriesResultResponse represents a series with its appointments.
+type SeriesResultResponse struct {
+	Series       SeriesResponse             `json:"series"`
+	Appointments []AppointmentResponse      `json:"appointments"`
+	Skipped      []SeriesOccurrenceResponse `json:"skipped"`
+}
+
+// SeriesUpdateResponse represents the outcome of editing a recurring series.
+type SeriesUpdateResponse struct {
+	Scope        string                     `json:"scope"`
+	Series       *SeriesResponse            `json:"series,omitempty"`
+	Appointments []AppointmentResponse      `json:"appointments"`
+	NotMoved     []SeriesOccurrenceResponse `json:"not_moved"`
+}
+
+// SeriesConflictResponse is returned when occurrences of a new series conflict
+// with existing appointments.
+type SeriesConflictResponse struct {
+	Error   string                `json:"error"`
+	Message string                `json:"message"`
+	Preview SeriesPreviewResponse `json:"preview"`
+}
+
+// PreviewSeries expands a recurring appointment request without creating it.
+// @Summary Preview recurring series
+// @Description Expands an RRULE (or legacy recurrence pattern) and reports the conflicts of every occurrence
+// @Tags appointments
+// @Accept json
+// @Produce json
+// @Param appointment body model.CreateAppointmentRequest true "Recurring appointment data"
+// @Success 200 {object} SeriesPreviewResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/appointments/series/preview [post]
+func (h *AppointmentHandler) PreviewSeries(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	var req model.CreateAppointmentRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Failed to parse request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Request validation failed",
+			Details: validator.FormatErrors(err),
+		})
+	}
+
+	preview, err := h.svc.Appointment().PreviewSeries(c.Request().Context(), user.ClinicID, &req)
+	if err != nil {
+		return seriesError(c, err, "Failed to preview series")
+	}
+
+	return c.JSON(http.StatusOK, toSeriesPreviewResponse(preview))
+}
+
+// CreateSeries creates a recurring appointment series.
+// @Summary Create recurring series
+// @Description Creates every occurrence of a recurring series in one transaction. Conflicting occurrences must be moved or skipped with overrides, or accepted with accept_conflicts.
+// @Tags appointments
+// @Accept json
+// @Produce json
+// @Param appointment body model.CreateAppointmentRequest true "Recurring appointment data"
+// @Success 201 {object} SeriesResultResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} SeriesConflictResponse "Scheduling conflict"
+// @Failure 422 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/appointments/series [post]
+func (h *AppointmentHandler) CreateSeries(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	var req model.CreateAppointmentRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Failed to parse request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Request validation failed",
+			Details: validator.FormatErrors(err),
+		})
+	}
+
+	result, err := h.svc.Appointment().CreateSeries(c.Request().Context(), user.ClinicID, user.UserID, &req)
+	if err != nil {
+		return seriesError(c, err, "Failed to create series")
+	}
+
+	return c.JSON(http.StatusCreated, toSeriesResultResponse(result))
+}
+
+// GetSeries retrieves a recurring series with its appointments.
+// @Summary Get recurring series
+// @Description Returns a recurring series and all of its appointments
+// @Tags appointments
+// @Accept json
+// @Produce json
+// @Param id path string true "Series ID (recurrence_id)"
+// @Success 200 {object} SeriesResultResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/appointments/series/{id} [get]
+func (h *AppointmentHandler) GetSeries(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	result, err := h.svc.Appointment().GetSeries(c.Request().Context(), user.ClinicID, c.Param("id"))
+	if err != nil {
+		return seriesError(c, err, "Failed to get series")
+	}
+
+	return c.JSON(http.StatusOK, toSeriesResultResponse(result))
+}
+
+// UpdateSeries edits an appointment of a recurring series.
+// @Summary Update recurring appointments
+// @Description Edits this appointment only, this and the following occurrences (splitting the series), or every open occurrence of the series. A new start_time moves each occurrence by the same days and clock offset; occurrences that would conflict stay where they are and are listed in not_moved.
+// @Tags appointments
+// @Accept json
+// @Produce json
+// @Param id path string true "Appointment ID (UUID)"
+// @Param appointment body model.UpdateSeriesRequest true "Changes and scope"
+// @Success 200 {object} SeriesUpdateResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse "Scheduling conflict"
+// @Failure 422 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/appointments/{id}/series [put]
+func (h *AppointmentHandler) UpdateSeries(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	var req model.UpdateSeriesRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Failed to parse request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Request validation failed",
+			Details: validator.FormatErrors(err),
+		})
+	}
+
+	result, err := h.svc.Appointment().UpdateSeries(c.Request().Context(), user.ClinicID, c.Param("id"), user.UserID, &req)
+	if err != nil {
+		return seriesError(c, err, "Failed to update series")
+	}
+
+	resp := SeriesUpdateResponse{
+		Scope:        string(result.Scope),
+		Appointments: make([]AppointmentResponse, len(result.Appointments)),
+		NotMoved:     toSeriesOccurrenceResponses(result.NotMoved),
+	}
+	for i, a := range result.Appointments {
+		resp.Appointments[i] = toAppointmentResponse(a)
+	}
+	if result.Series != nil {
+		series := toSeriesResponse(result.Series)
+		resp.Series = &series
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// seriesError maps recurring series errors to HTTP responses.
+func seriesError(c echo.Context, err error, failureMsg string) error {
+	var conflict *service.SeriesConflictError
+	switch {
+	case errors.As(err, &conflict):
+		return c.JSON(http.StatusConflict, SeriesConflictResponse{
+			Error:   "conflict",
+			Message: err.Error(),
+			Preview: toSeriesPreviewResponse(conflict.Preview),
+		})
+	case errors.Is(err, repository.ErrConflict) || strings.HasPrefix(err.Error(), "scheduling conflict"):
+		return c.JSON(http.StatusConflict, ErrorResponse{
+			Error:   "conflict",
+			Message: err.Error(),
+		})
+	case errors.Is(err, repository.ErrNotFound):
+		return c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "not_found",
+			Message: "Series or appointment not found",
+		})
+	case errors.Is(err, repository.ErrInvalidInput):
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_input",
+			Message: err.Error(),
+		})
+	}
+
+	log.Error().Err(err).Str("path", c.Path()).Msg("series request failed")
+	return c.JSON(http.StatusInternalServerError, ErrorResponse{
+		Error:   "internal_error",
+		Message: failureMsg,
+	})
+}
+
+// toSeriesResponse converts a RecurrenceSeries to SeriesResponse.
+func toSeriesResponse(s *model.RecurrenceSeries) SeriesResponse {
+	exdates := make([]string, len(s.ExDates))
+	for i, t := range s.ExDates {
+		exdates[i] = t.Format(time.RFC3339)
+	}
+
+	return SeriesResponse{
+		ID:          s.ID,
+		PatientID:   s.PatientID,
+		TherapistID: s.TherapistID,
+		RRule:       s.RRule,
+		DTStart:     s.DTStart.Format(time.RFC3339),
+		Timezone:    s.Timezone,
+		Duration:    s.Duration,
+		Type:        string(s.Type),
+		Room:        s.Room,
+		Notes:       s.Notes,
+		ResourceIDs: s.ResourceIDs,
+		ExDates:     exdates,
+		CreatedAt:   s.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// toSeriesOccurrenceResponses converts series occurrences to responses.
+func toSeriesOccurrenceResponses(occurrences []model.SeriesOccurrence) []SeriesOccurrenceResponse {
+	out := make([]SeriesOccurrenceResponse, len(occurrences))
+	for i, o := range occurrences {
+		out[i] = SeriesOccurrenceResponse{
+			OriginalStart: o.OriginalStart.Format(time.RFC3339),
+			StartTime:     o.StartTime.Format(time.RFC3339),
+			EndTime:       o.EndTime.Format(time.RFC3339),
+			Status:        string(o.Status),
+			Conflicts:     o.Conflicts,
+		}
+	}
+	return out
+}
+
+// toSeriesPreviewResponse converts a SeriesPreview to SeriesPreviewResponse.
+func toSeriesPreviewResponse(p *model.SeriesPreview) SeriesPreviewResponse {
+	return SeriesPreviewResponse{
+		RRule:         p.RRule,
+		Occurrences:   toSeriesOccurrenceResponses(p.Occurrences),
+		ConflictCount: p.ConflictCount,
+	}
+}
+
+// toSeriesResultResponse converts a SeriesResult to SeriesResultResponse.
+func toSeriesResultResponse(r *model.SeriesResult) SeriesResultResponse {
+	appointments := make([]AppointmentResponse, len(r.Appointments))
+	for i, a := range r.Appointments {
+		appointments[i] = toAppointmentResponse(a)
+	}
+
+	return SeriesResultResponse{
+		Series:       toSeriesResponse(r.Series),
+		Appointments: appointments,
+		Skipped:      toSeriesOccurrenceResponses(r.Skipped),
+	}
+}
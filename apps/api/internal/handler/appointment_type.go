@@ -0,0 +1,296 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/middleware"
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/repository"
+	"github.com/tqvdang/physioflow/apps/api/internal/service"
+	"github.com/tqvdang/physioflow/apps/api/pkg/validator"
+)
+
+// AppointmentTypeHandler handles clinic appointment type requests.
+type AppointmentTypeHandler struct {
+	svc *service.Service
+}
+
+// NewAppointmentTypeHandler creates a new AppointmentTypeHandler.
+func NewAppointmentTypeHandler(svc *service.Service) *AppointmentTypeHandler {
+	return &AppointmentTypeHandler{svc: svc}
+}
+
+// AppointmentTypeResponse represents an appointment type in API responses.
+type AppointmentTypeResponse struct {
+	ID                  string   `json:"id,omitempty"`
+	Name                string   `json:"name"`
+	NameVi              string   `json:"name_vi,omitempty"`
+	Code                string   `json:"code"`
+	Description         string   `json:"description,omitempty"`
+	DescriptionVi       string   `json:"description_vi,omitempty"`
+	DefaultDuration     int      `json:"default_duration"`
+	Color               string   `json:"color,omitempty"`
+	Icon                string   `json:"icon,omitempty"`
+	RequiresEvaluation  bool     `json:"requires_evaluation"`
+	IsEvaluation        bool     `json:"is_evaluation"`
+	AllowsGroup         bool     `json:"allows_group"`
+	MaxGroupSize        int      `json:"max_group_size"`
+	DefaultBillingCodes []string `json:"default_billing_codes"`
+	IsActive            bool     `json:"is_active"`
+	SortOrder           int      `json:"sort_order"`
+	CreatedAt           string   `json:"created_at,omitempty"`
+	UpdatedAt           string   `json:"updated_at,omitempty"`
+}
+
+// List returns the clinic's appointment types.
+// @Summary List appointment types
+// @Description Returns the clinic's appointment types in display order; clinics without configured types get the built-in ones, which have no ID
+// @Tags appointment-types
+// @Accept json
+// @Produce json
+// @Param active_only query bool false "Only active types" default(false)
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/appointment-types [get]
+func (h *AppointmentTypeHandler) List(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	activeOnly := c.QueryParam("active_only") == "true"
+
+	types, err := h.svc.AppointmentType().List(c.Request().Context(), user.ClinicID, activeOnly)
+	if err != nil {
+		return appointmentTypeError(c, err, "Failed to list appointment types")
+	}
+
+	data := make([]AppointmentTypeResponse, len(types))
+	for i := range types {
+		data[i] = toAppointmentTypeResponse(&types[i])
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": data,
+	})
+}
+
+// Get returns an appointment type by ID.
+// @Summary Get appointment type
+// @Description Returns one of the clinic's appointment types
+// @Tags appointment-types
+// @Accept json
+// @Produce json
+// @Param id path string true "Appointment type ID (UUID)"
+// @Success 200 {object} AppointmentTypeResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/appointment-types/{id} [get]
+func (h *AppointmentTypeHandler) Get(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	t, err := h.svc.AppointmentType().Get(c.Request().Context(), user.ClinicID, c.Param("id"))
+	if err != nil {
+		return appointmentTypeError(c, err, "Failed to retrieve appointment type")
+	}
+
+	return c.JSON(http.StatusOK, toAppointmentTypeResponse(t))
+}
+
+// Create adds an appointment type to the clinic.
+// @Summary Create appointment type
+// @Description Adds an appointment type; its code is what appointments record and cannot be changed later
+// @Tags appointment-types
+// @Accept json
+// @Produce json
+// @Param request body model.CreateAppointmentTypeRequest true "Appointment type"
+// @Success 201 {object} AppointmentTypeResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/appointment-types [post]
+func (h *AppointmentTypeHandler) Create(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	var req model.CreateAppointmentTypeRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Failed to parse request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Request validation failed",
+			Details: validator.FormatErrors(err),
+		})
+	}
+
+	t, err := h.svc.AppointmentType().Create(c.Request().Context(), user.ClinicID, user.UserID, &req)
+	if err != nil {
+		return appointmentTypeError(c, err, "Failed to create appointment type")
+	}
+
+	return c.JSON(http.StatusCreated, toAppointmentTypeResponse(t))
+}
+
+// Update changes an appointment type.
+// @Summary Update appointment type
+// @Description Changes an appointment type's names, defaults and rules; set is_active to false to retire a type that is in use
+// @Tags appointment-types
+// @Accept json
+// @Produce json
+// @Param id path string true "Appointment type ID (UUID)"
+// @Param request body model.UpdateAppointmentTypeRequest true "Changes"
+// @Success 200 {object} AppointmentTypeResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/appointment-types/{id} [put]
+func (h *AppointmentTypeHandler) Update(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	var req model.UpdateAppointmentTypeRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Failed to parse request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Request validation failed",
+			Details: validator.FormatErrors(err),
+		})
+	}
+
+	t, err := h.svc.AppointmentType().Update(c.Request().Context(), user.ClinicID, c.Param("id"), user.UserID, &req)
+	if err != nil {
+		return appointmentTypeError(c, err, "Failed to update appointment type")
+	}
+
+	return c.JSON(http.StatusOK, toAppointmentTypeResponse(t))
+}
+
+// Delete removes an appointment type that has never been booked.
+// @Summary Delete appointment type
+// @Description Removes an appointment type; types used by appointments must be deactivated instead
+// @Tags appointment-types
+// @Param id path string true "Appointment type ID (UUID)"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/appointment-types/{id} [delete]
+func (h *AppointmentTypeHandler) Delete(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	if err := h.svc.AppointmentType().Delete(c.Request().Context(), user.ClinicID, c.Param("id"), user.UserID); err != nil {
+		return appointmentTypeError(c, err, "Failed to delete appointment type")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// appointmentTypeError maps appointment type service errors to responses.
+func appointmentTypeError(c echo.Context, err error, failureMsg string) error {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "not_found",
+			Message: "Appointment type not found",
+		})
+	case errors.Is(err, repository.ErrAlreadyExists):
+		return c.JSON(http.StatusConflict, ErrorResponse{
+			Error:   "conflict",
+			Message: "An appointment type with this code already exists",
+		})
+	case errors.Is(err, repository.ErrInvalidInput):
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_input",
+			Message: err.Error(),
+		})
+	}
+
+	log.Error().Err(err).Str("path", c.Path()).Msg("appointment type request failed")
+	return c.JSON(http.StatusInternalServerError, ErrorResponse{
+		Error:   "internal_error",
+		Message: failureMsg,
+	})
+}
+
+// toAppointmentTypeResponse converts an AppointmentTypeConfig to AppointmentTypeResponse.
+func toAppointmentTypeResponse(t *model.AppointmentTypeConfig) AppointmentTypeResponse {
+	resp := AppointmentTypeResponse{
+		ID:                  t.ID,
+		Name:                t.Name,
+		NameVi:              t.NameVi,
+		Code:                t.Code,
+		Description:         t.Description,
+		DescriptionVi:       t.DescriptionVi,
+		DefaultDuration:     t.DefaultDuration,
+		Color:               t.Color,
+		Icon:                t.Icon,
+		RequiresEvaluation:  t.RequiresEvaluation,
+		IsEvaluation:        t.IsEvaluation,
+		AllowsGroup:         t.AllowsGroup,
+		MaxGroupSize:        t.MaxGroupSize,
+		DefaultBillingCodes: t.DefaultBillingCodes,
+		IsActive:            t.IsActive,
+		SortOrder:           t.SortOrder,
+	}
+	if resp.DefaultBillingCodes == nil {
+		resp.DefaultBillingCodes = []string{}
+	}
+	if !t.CreatedAt.IsZero() {
+		resp.CreatedAt = t.CreatedAt.Format(time.RFC3339)
+		resp.UpdatedAt = t.UpdatedAt.Format(time.RFC3339)
+	}
+	return resp
+}
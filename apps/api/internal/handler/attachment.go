@@ -0,0 +1,290 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/middleware"
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/repository"
+	"github.com/tqvdang/physioflow/apps/api/internal/service"
+	"github.com/tqvdang/physioflow/apps/api/pkg/validator"
+)
+
+// AttachmentHandler handles files attached to patient records.
+type AttachmentHandler struct {
+	svc *service.Service
+}
+
+// NewAttachmentHandler creates a new AttachmentHandler.
+func NewAttachmentHandler(svc *service.Service) *AttachmentHandler {
+	return &AttachmentHandler{svc: svc}
+}
+
+// AttachmentResponse represents a patient attachment in API responses.
+type AttachmentResponse struct {
+	ID          string `json:"id"`
+	PatientID   string `json:"patient_id"`
+	Category    string `json:"category"`
+	FileName    string `json:"file_name"`
+	ContentType string `json:"content_type"`
+	SizeBytes   int64  `json:"size_bytes"`
+	SHA256      string `json:"sha256"`
+	Description string `json:"description,omitempty"`
+	UploadedBy  string `json:"uploaded_by,omitempty"`
+	CreatedAt   string `json:"created_at"`
+}
+
+// Upload attaches a file to a patient record.
+// @Summary Upload patient attachment
+// @Description Uploads a file (max 25 MB) to the patient record. Photos require the patient's photo_use consent.
+// @Tags attachments
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Patient ID"
+// @Param file formData file true "File"
+// @Param category formData string true "document, photo, imaging, referral or other"
+// @Param description formData string false "Description"
+// @Success 201 {object} AttachmentResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/patients/{id}/attachments [post]
+func (h *AttachmentHandler) Upload(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	patientID := c.Param("id")
+	if patientID == "" {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Patient ID is required",
+		})
+	}
+
+	c.Request().Body = http.MaxBytesReader(c.Response(), c.Request().Body, service.MaxAttachmentSize+1<<20)
+
+	req := model.CreateAttachmentRequest{
+		Category:    c.FormValue("category"),
+		Description: c.FormValue("description"),
+	}
+	if err := validator.Validate(req); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Request validation failed",
+			Details: validator.FormatErrors(err),
+		})
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "A file is required",
+		})
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Failed to read uploaded file",
+		})
+	}
+	defer file.Close()
+
+	attachment, err := h.svc.Attachment().Upload(c.Request().Context(), user.ClinicID, patientID, user.UserID,
+		&req, fileHeader.Filename, fileHeader.Header.Get(echo.HeaderContentType), file)
+	if err != nil {
+		return attachmentError(c, err, "Failed to upload attachment")
+	}
+
+	return c.JSON(http.StatusCreated, toAttachmentResponse(*attachment))
+}
+
+// List returns the attachments on a patient record.
+// @Summary List patient attachments
+// @Description Returns the files attached to a patient record
+// @Tags attachments
+// @Accept json
+// @Produce json
+// @Param id path string true "Patient ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/patients/{id}/attachments [get]
+func (h *AttachmentHandler) List(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	patientID := c.Param("id")
+	if patientID == "" {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Patient ID is required",
+		})
+	}
+
+	attachments, err := h.svc.Attachment().List(c.Request().Context(), user.ClinicID, patientID)
+	if err != nil {
+		log.Error().Err(err).Str("patient_id", patientID).Msg("failed to list attachments")
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to list attachments",
+		})
+	}
+
+	data := make([]AttachmentResponse, len(attachments))
+	for i, a := range attachments {
+		data[i] = toAttachmentResponse(a)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": data,
+	})
+}
+
+// Download streams an attachment's content.
+// @Summary Download patient attachment
+// @Description Downloads the file content of an attachment
+// @Tags attachments
+// @Produce octet-stream
+// @Param id path string true "Patient ID"
+// @Param attachmentId path string true "Attachment ID"
+// @Success 200 {file} binary
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/patients/{id}/attachments/{attachmentId} [get]
+func (h *AttachmentHandler) Download(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	patientID := c.Param("id")
+	attachmentID := c.Param("attachmentId")
+	if patientID == "" || attachmentID == "" {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Patient ID and Attachment ID are required",
+		})
+	}
+
+	content, attachment, err := h.svc.Attachment().Open(c.Request().Context(), user.ClinicID, patientID, attachmentID, user.UserID)
+	if err != nil {
+		return attachmentError(c, err, "Failed to download attachment")
+	}
+	defer content.Close()
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", attachment.FileName))
+	c.Response().Header().Set(echo.HeaderContentLength, strconv.FormatInt(attachment.SizeBytes, 10))
+	c.Response().Header().Set("Cache-Control", "private, no-store")
+	return c.Stream(http.StatusOK, attachment.ContentType, content)
+}
+
+// Delete removes an attachment from a patient record.
+// @Summary Delete patient attachment
+// @Description Removes an attachment and its stored file
+// @Tags attachments
+// @Param id path string true "Patient ID"
+// @Param attachmentId path string true "Attachment ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/patients/{id}/attachments/{attachmentId} [delete]
+func (h *AttachmentHandler) Delete(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	patientID := c.Param("id")
+	attachmentID := c.Param("attachmentId")
+	if patientID == "" || attachmentID == "" {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Patient ID and Attachment ID are required",
+		})
+	}
+
+	if err := h.svc.Attachment().Delete(c.Request().Context(), user.ClinicID, patientID, attachmentID, user.UserID); err != nil {
+		return attachmentError(c, err, "Failed to delete attachment")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// attachmentError maps attachment service errors to HTTP responses.
+func attachmentError(c echo.Context, err error, failureMsg string) error {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "not_found",
+			Message: "Attachment or patient not found",
+		})
+	case errors.Is(err, service.ErrConsentRequired):
+		return c.JSON(http.StatusForbidden, ErrorResponse{
+			Error:   "consent_required",
+			Message: err.Error(),
+		})
+	case errors.Is(err, repository.ErrInvalidInput):
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+	}
+
+	log.Error().Err(err).Str("path", c.Path()).Msg("attachment request failed")
+	return c.JSON(http.StatusInternalServerError, ErrorResponse{
+		Error:   "internal_error",
+		Message: failureMsg,
+	})
+}
+
+// toAttachmentResponse converts a PatientAttachment to AttachmentResponse.
+func toAttachmentResponse(a model.PatientAttachment) AttachmentResponse {
+	resp := AttachmentResponse{
+		ID:          a.ID,
+		PatientID:   a.PatientID,
+		Category:    string(a.Category),
+		FileName:    a.FileName,
+		ContentType: a.ContentType,
+		SizeBytes:   a.SizeBytes,
+		SHA256:      a.SHA256,
+		Description: a.Description,
+		CreatedAt:   a.CreatedAt.Format(time.RFC3339),
+	}
+	if a.UploadedBy != nil {
+		resp.UploadedBy = *a.UploadedBy
+	}
+	return resp
+}
@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/middleware"
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/repository"
+	"github.com/tqvdang/physioflow/apps/api/pkg/validator"
+)
+
+// RankedSlotResponse represents a free slot found by an availability search.
+type RankedSlotResponse struct {
+	AvailabilitySlotResponse
+	Score   int      `json:"score"`
+	Reasons []string `json:"reasons"`
+}
+
+// AvailabilitySearchResponse represents the result of an availability search.
+type AvailabilitySearchResponse struct {
+	Slots              []RankedSlotResponse `json:"slots"`
+	Earliest           *RankedSlotResponse  `json:"earliest,omitempty"`
+	UsualTherapistID   string               `json:"usual_therapist_id,omitempty"`
+	TherapistsSearched int                  `json:"therapists_searched"`
+	From               string               `json:"from"`
+	To                 string               `json:"to"`
+}
+
+// SearchAvailability finds free slots across therapists and days.
+// @Summary Search availability
+// @Description Finds free slots across therapists over a range of days (14 by default), filtered by therapist, specialty, weekday and clock time, and ranked by continuity of care, patient preferences and how soon the slot is
+// @Tags appointments
+// @Accept json
+// @Produce json
+// @Param request body model.AvailabilitySearchRequest true "Search criteria"
+// @Success 200 {object} AvailabilitySearchResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/appointments/availability/search [post]
+func (h *AppointmentHandler) SearchAvailability(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	var req model.AvailabilitySearchRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Failed to parse request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Request validation failed",
+			Details: validator.FormatErrors(err),
+		})
+	}
+
+	result, err := h.svc.Appointment().SearchAvailability(c.Request().Context(), user.ClinicID, &req)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidInput) {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_input",
+				Message: err.Error(),
+			})
+		}
+		log.Error().Err(err).Msg("failed to search availability")
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to search availability",
+		})
+	}
+
+	resp := AvailabilitySearchResponse{
+		Slots:              make([]RankedSlotResponse, len(result.Slots)),
+		UsualTherapistID:   result.UsualTherapistID,
+		TherapistsSearched: result.TherapistsSearched,
+		From:               result.From.Format("2006-01-02"),
+		To:                 result.To.AddDate(0, 0, -1).Format("2006-01-02"),
+	}
+	for i, slot := range result.Slots {
+		resp.Slots[i] = toRankedSlotResponse(slot)
+	}
+	if result.Earliest != nil {
+		earliest := toRankedSlotResponse(*result.Earliest)
+		resp.Earliest = &earliest
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// toRankedSlotResponse converts a RankedSlot to RankedSlotResponse.
+func toRankedSlotResponse(s model.RankedSlot) RankedSlotResponse {
+	return RankedSlotResponse{
+		AvailabilitySlotResponse: AvailabilitySlotResponse{
+			StartTime:     s.StartTime.Format(time.RFC3339),
+			EndTime:       s.EndTime.Format(time.RFC3339),
+			TherapistID:   s.TherapistID,
+			TherapistName: s.TherapistName,
+			Duration:      s.Duration,
+		},
+		Score:   s.Score,
+		Reasons: s.Reasons,
+	}
+}
@@ -0,0 +1,483 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/middleware"
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/repository"
+	"github.com/tqvdang/physioflow/apps/api/internal/service"
+)
+
+// calendarContentType is the media type of iCalendar responses.
+const calendarContentType = "text/calendar; charset=utf-8"
+
+// CalendarHandler handles iCalendar feeds and .ics downloads.
+type CalendarHandler struct {
+	svc *service.Service
+}
+
+// NewCalendarHandler creates a new CalendarHandler.
+func NewCalendarHandler(svc *service.Service) *CalendarHandler {
+	return &CalendarHandler{svc: svc}
+}
+
+// CalendarFeedResponse represents a calendar feed in API responses.
+type CalendarFeedResponse struct {
+	ID             string  `json:"id"`
+	Kind           string  `json:"kind"`
+	OwnerID        string  `json:"owner_id"`
+	Active         bool    `json:"active"`
+	URL            string  `json:"url,omitempty"` // only returned when the feed is issued
+	RevokedAt      *string `json:"revoked_at,omitempty"`
+	LastAccessedAt *string `json:"last_accessed_at,omitempty"`
+	CreatedAt      string  `json:"created_at"`
+}
+
+// TherapistFeed serves a therapist's calendar feed.
+// @Summary Therapist calendar feed
+// @Description Serves a therapist's appointments and group sessions as an iCalendar feed. The token in the URL is the only credential.
+// @Tags calendar
+// @Produce text/calendar
+// @Param token path string true "Feed token, optionally followed by .ics"
+// @Success 200 {string} string
+// @Failure 404 {object} ErrorResponse
+// @Router /ical/therapist/{token}.ics [get]
+func (h *CalendarHandler) TherapistFeed(c echo.Context) error {
+	return h.serveFeed(c, model.CalendarFeedTherapist)
+}
+
+// PatientFeed serves a patient's calendar feed.
+// @Summary Patient calendar feed
+// @Description Serves a patient's appointments as an iCalendar feed. The token in the URL is the only credential.
+// @Tags calendar
+// @Produce text/calendar
+// @Param token path string true "Feed token, optionally followed by .ics"
+// @Success 200 {string} string
+// @Failure 404 {object} ErrorResponse
+// @Router /ical/patient/{token}.ics [get]
+func (h *CalendarHandler) PatientFeed(c echo.Context) error {
+	return h.serveFeed(c, model.CalendarFeedPatient)
+}
+
+func (h *CalendarHandler) serveFeed(c echo.Context, kind model.CalendarFeedKind) error {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+
+	body, err := h.svc.Calendar().RenderFeed(c.Request().Context(), kind, token)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "not_found",
+				Message: "Calendar feed not found",
+			})
+		}
+		log.Error().Err(err).Str("kind", string(kind)).Msg("failed to render calendar feed")
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to render calendar feed",
+		})
+	}
+
+	// The URL is a credential; keep the feed out of shared caches
+	c.Response().Header().Set("Cache-Control", "private, max-age=300")
+	c.Response().Header().Set("Referrer-Policy", "no-referrer")
+	return c.Blob(http.StatusOK, calendarContentType, body)
+}
+
+// ListTherapistFeeds returns a therapist's calendar feeds.
+// @Summary List therapist calendar feeds
+// @Description Returns a therapist's calendar feeds, including revoked ones. Therapists see their own; admins see anyone's.
+// @Tags calendar
+// @Produce json
+// @Param id path string true "Therapist ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/therapists/{id}/calendar-feeds [get]
+func (h *CalendarHandler) ListTherapistFeeds(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+	if !canManageTherapistFeeds(user, c.Param("id")) {
+		return c.JSON(http.StatusForbidden, ErrorResponse{
+			Error:   "forbidden",
+			Message: "You can only manage your own calendar feeds",
+		})
+	}
+
+	feeds, err := h.svc.Calendar().ListFeeds(c.Request().Context(), user.ClinicID, model.CalendarFeedTherapist, c.Param("id"))
+	if err != nil {
+		return calendarError(c, err, "Therapist not found", "Failed to list calendar feeds")
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": toCalendarFeedResponses(c, feeds),
+	})
+}
+
+// IssueTherapistFeed issues a new calendar feed URL for a therapist.
+// @Summary Issue therapist calendar feed
+// @Description Issues a secret calendar feed URL for a therapist and revokes the previous one. The URL is only returned once.
+// @Tags calendar
+// @Produce json
+// @Param id path string true "Therapist ID"
+// @Success 201 {object} CalendarFeedResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/therapists/{id}/calendar-feeds [post]
+func (h *CalendarHandler) IssueTherapistFeed(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+	if !canManageTherapistFeeds(user, c.Param("id")) {
+		return c.JSON(http.StatusForbidden, ErrorResponse{
+			Error:   "forbidden",
+			Message: "You can only manage your own calendar feeds",
+		})
+	}
+
+	feed, err := h.svc.Calendar().IssueFeed(c.Request().Context(), user.ClinicID, model.CalendarFeedTherapist, c.Param("id"), user.UserID)
+	if err != nil {
+		return calendarError(c, err, "Therapist not found", "Failed to issue calendar feed")
+	}
+	return c.JSON(http.StatusCreated, toCalendarFeedResponse(c, *feed))
+}
+
+// RevokeTherapistFeed revokes one of a therapist's calendar feeds.
+// @Summary Revoke therapist calendar feed
+// @Description Revokes a therapist's calendar feed; its URL stops working immediately
+// @Tags calendar
+// @Param id path string true "Therapist ID"
+// @Param feedId path string true "Feed ID"
+// @Success 204
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/therapists/{id}/calendar-feeds/{feedId} [delete]
+func (h *CalendarHandler) RevokeTherapistFeed(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+	if !canManageTherapistFeeds(user, c.Param("id")) {
+		return c.JSON(http.StatusForbidden, ErrorResponse{
+			Error:   "forbidden",
+			Message: "You can only manage your own calendar feeds",
+		})
+	}
+
+	err := h.svc.Calendar().RevokeFeed(c.Request().Context(), user.ClinicID, model.CalendarFeedTherapist, c.Param("id"), c.Param("feedId"), user.UserID)
+	if err != nil {
+		return calendarError(c, err, "Active calendar feed not found", "Failed to revoke calendar feed")
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ListPatientFeeds returns a patient's calendar feeds.
+// @Summary List patient calendar feeds
+// @Description Returns a patient's calendar feeds, including revoked ones
+// @Tags calendar
+// @Produce json
+// @Param id path string true "Patient ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/patients/{id}/calendar-feeds [get]
+func (h *CalendarHandler) ListPatientFeeds(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	feeds, err := h.svc.Calendar().ListFeeds(c.Request().Context(), user.ClinicID, model.CalendarFeedPatient, c.Param("id"))
+	if err != nil {
+		return calendarError(c, err, "Patient not found", "Failed to list calendar feeds")
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": toCalendarFeedResponses(c, feeds),
+	})
+}
+
+// IssuePatientFeed issues a new calendar feed URL for a patient, for patients
+// who do not use the portal.
+// @Summary Issue patient calendar feed
+// @Description Issues a secret calendar feed URL for a patient and revokes the previous one. The URL is only returned once.
+// @Tags calendar
+// @Produce json
+// @Param id path string true "Patient ID"
+// @Success 201 {object} CalendarFeedResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/patients/{id}/calendar-feeds [post]
+func (h *CalendarHandler) IssuePatientFeed(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	feed, err := h.svc.Calendar().IssueFeed(c.Request().Context(), user.ClinicID, model.CalendarFeedPatient, c.Param("id"), user.UserID)
+	if err != nil {
+		return calendarError(c, err, "Patient not found", "Failed to issue calendar feed")
+	}
+	return c.JSON(http.StatusCreated, toCalendarFeedResponse(c, *feed))
+}
+
+// RevokePatientFeed revokes one of a patient's calendar feeds.
+// @Summary Revoke patient calendar feed
+// @Description Revokes a patient's calendar feed; its URL stops working immediately
+// @Tags calendar
+// @Param id path string true "Patient ID"
+// @Param feedId path string true "Feed ID"
+// @Success 204
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/patients/{id}/calendar-feeds/{feedId} [delete]
+func (h *CalendarHandler) RevokePatientFeed(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	err := h.svc.Calendar().RevokeFeed(c.Request().Context(), user.ClinicID, model.CalendarFeedPatient, c.Param("id"), c.Param("feedId"), user.UserID)
+	if err != nil {
+		return calendarError(c, err, "Active calendar feed not found", "Failed to revoke calendar feed")
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// AppointmentICS downloads an appointment as an .ics file.
+// @Summary Download appointment .ics
+// @Description Returns the appointment as a patient-facing iCalendar file, suitable for attaching to reminder messages
+// @Tags calendar
+// @Produce text/calendar
+// @Param id path string true "Appointment ID"
+// @Success 200 {string} string
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/appointments/{id}/ics [get]
+func (h *CalendarHandler) AppointmentICS(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	id := c.Param("id")
+	body, err := h.svc.Calendar().AppointmentICS(c.Request().Context(), user.ClinicID, id)
+	if err != nil {
+		return calendarError(c, err, "Appointment not found", "Failed to render appointment")
+	}
+	return sendICS(c, id, body)
+}
+
+// PortalAppointmentICS downloads one of the current patient's appointments as an .ics file.
+// @Summary Download my appointment .ics
+// @Description Returns one of the authenticated patient's appointments as an iCalendar file
+// @Tags portal
+// @Produce text/calendar
+// @Param id path string true "Appointment ID"
+// @Success 200 {string} string
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/me/appointments/{id}/ics [get]
+func (h *CalendarHandler) PortalAppointmentICS(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	id := c.Param("id")
+	body, err := h.svc.Portal().AppointmentICS(c.Request().Context(), portalActor(c, user), id)
+	if err != nil {
+		return portalError(c, err, "Appointment not found", "Failed to render appointment")
+	}
+	return sendICS(c, id, body)
+}
+
+// ListPortalFeeds returns the current patient's calendar feeds.
+// @Summary List my calendar feeds
+// @Description Returns the authenticated patient's calendar feeds, including revoked ones
+// @Tags portal
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/me/calendar-feeds [get]
+func (h *CalendarHandler) ListPortalFeeds(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	feeds, err := h.svc.Portal().ListCalendarFeeds(c.Request().Context(), portalActor(c, user))
+	if err != nil {
+		return portalError(c, err, "Patient not found", "Failed to list calendar feeds")
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": toCalendarFeedResponses(c, feeds),
+	})
+}
+
+// IssuePortalFeed issues a new calendar feed URL for the current patient.
+// @Summary Subscribe to my appointments
+// @Description Issues a secret calendar feed URL for the authenticated patient and revokes the previous one. The URL is only returned once.
+// @Tags portal
+// @Produce json
+// @Success 201 {object} CalendarFeedResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/me/calendar-feeds [post]
+func (h *CalendarHandler) IssuePortalFeed(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	feed, err := h.svc.Portal().IssueCalendarFeed(c.Request().Context(), portalActor(c, user))
+	if err != nil {
+		return portalError(c, err, "Patient not found", "Failed to issue calendar feed")
+	}
+	return c.JSON(http.StatusCreated, toCalendarFeedResponse(c, *feed))
+}
+
+// RevokePortalFeed revokes one of the current patient's calendar feeds.
+// @Summary Revoke my calendar feed
+// @Description Revokes one of the authenticated patient's calendar feeds
+// @Tags portal
+// @Param id path string true "Feed ID"
+// @Success 204
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/me/calendar-feeds/{id} [delete]
+func (h *CalendarHandler) RevokePortalFeed(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	if err := h.svc.Portal().RevokeCalendarFeed(c.Request().Context(), portalActor(c, user), c.Param("id")); err != nil {
+		return portalError(c, err, "Active calendar feed not found", "Failed to revoke calendar feed")
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// canManageTherapistFeeds reports whether the user may manage a therapist's
+// calendar feeds: their own, or anyone's for admins.
+func canManageTherapistFeeds(user *middleware.AuthClaims, therapistID string) bool {
+	return user.UserID == therapistID || user.IsAdmin()
+}
+
+// calendarError maps calendar service errors to HTTP responses.
+func calendarError(c echo.Context, err error, notFoundMsg, failureMsg string) error {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "not_found",
+			Message: notFoundMsg,
+		})
+	case errors.Is(err, repository.ErrInvalidInput):
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+	}
+
+	log.Error().Err(err).Str("path", c.Path()).Msg(strings.ToLower(failureMsg))
+	return c.JSON(http.StatusInternalServerError, ErrorResponse{
+		Error:   "internal_error",
+		Message: failureMsg,
+	})
+}
+
+// sendICS writes an appointment .ics file as a download.
+func sendICS(c echo.Context, appointmentID string, body []byte) error {
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", "appointment-"+appointmentID+".ics"))
+	c.Response().Header().Set("Cache-Control", "no-store")
+	return c.Blob(http.StatusOK, calendarContentType, body)
+}
+
+// toCalendarFeedResponse converts a CalendarFeed to CalendarFeedResponse. The
+// URL is absolute so it can be pasted into a calendar client.
+func toCalendarFeedResponse(c echo.Context, f model.CalendarFeed) CalendarFeedResponse {
+	resp := CalendarFeedResponse{
+		ID:        f.ID,
+		Kind:      string(f.Kind),
+		OwnerID:   f.OwnerID,
+		Active:    f.IsActive(),
+		CreatedAt: f.CreatedAt.Format(time.RFC3339),
+	}
+	if path := f.Path(); path != "" {
+		resp.URL = c.Scheme() + "://" + c.Request().Host + path
+	}
+	if f.RevokedAt != nil {
+		s := f.RevokedAt.Format(time.RFC3339)
+		resp.RevokedAt = &s
+	}
+	if f.LastAccessedAt != nil {
+		s := f.LastAccessedAt.Format(time.RFC3339)
+		resp.LastAccessedAt = &s
+	}
+	return resp
+}
+
+func toCalendarFeedResponses(c echo.Context, feeds []model.CalendarFeed) []CalendarFeedResponse {
+	data := make([]CalendarFeedResponse, len(feeds))
+	for i, f := range feeds {
+		data[i] = toCalendarFeedResponse(c, f)
+	}
+	return data
+}
@@ -0,0 +1,473 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/middleware"
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/repository"
+	"github.com/tqvdang/physioflow/apps/api/internal/service"
+	"github.com/tqvdang/physioflow/apps/api/pkg/validator"
+)
+
+// ConsentHandler handles consent-related HTTP requests.
+type ConsentHandler struct {
+	svc *service.Service
+}
+
+// NewConsentHandler creates a new ConsentHandler.
+func NewConsentHandler(svc *service.Service) *ConsentHandler {
+	return &ConsentHandler{svc: svc}
+}
+
+// ConsentTemplateResponse represents a consent text version in API responses.
+type ConsentTemplateResponse struct {
+	ID          string `json:"id"`
+	ConsentType string `json:"consent_type"`
+	Version     int    `json:"version"`
+	IsCurrent   bool   `json:"is_current"`
+	IsGlobal    bool   `json:"is_global"`
+	Title       string `json:"title"`
+	TitleVi     string `json:"title_vi"`
+	Body        string `json:"body"`
+	BodyVi      string `json:"body_vi"`
+	CreatedAt   string `json:"created_at"`
+}
+
+// PatientConsentResponse represents a patient consent in API responses.
+type PatientConsentResponse struct {
+	ID                 string                   `json:"id"`
+	PatientID          string                   `json:"patient_id"`
+	TemplateID         string                   `json:"template_id"`
+	ConsentType        string                   `json:"consent_type"`
+	TemplateVersion    int                      `json:"template_version"`
+	Title              string                   `json:"title,omitempty"`
+	TitleVi            string                   `json:"title_vi,omitempty"`
+	Status             string                   `json:"status"`
+	Signature          *model.SignatureResponse `json:"signature,omitempty"`
+	SignedByName       string                   `json:"signed_by_name,omitempty"`
+	SignerRelationship string                   `json:"signer_relationship,omitempty"`
+	GrantedAt          string                   `json:"granted_at"`
+	WithdrawnAt        *string                  `json:"withdrawn_at,omitempty"`
+	WithdrawalReason   string                   `json:"withdrawal_reason,omitempty"`
+}
+
+// ConsentSummaryResponse represents a patient's standing for one consent type.
+type ConsentSummaryResponse struct {
+	ConsentType    string                  `json:"consent_type"`
+	Active         bool                    `json:"active"`
+	CurrentVersion int                     `json:"current_version"`
+	NeedsRenewal   bool                    `json:"needs_renewal"`
+	Consent        *PatientConsentResponse `json:"consent,omitempty"`
+}
+
+// PatientConsentsResponse represents a patient's consent summary and history.
+type PatientConsentsResponse struct {
+	Summary []ConsentSummaryResponse `json:"summary"`
+	History []PatientConsentResponse `json:"history"`
+}
+
+// ListTemplates returns the current consent texts for the clinic.
+// @Summary List consent templates
+// @Description Returns the current bilingual consent text for each consent type
+// @Tags consents
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/consent-templates [get]
+func (h *ConsentHandler) ListTemplates(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	templates, err := h.svc.Consent().ListTemplates(c.Request().Context(), user.ClinicID)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to list consent templates")
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to list consent templates",
+		})
+	}
+
+	data := make([]ConsentTemplateResponse, len(templates))
+	for i, t := range templates {
+		data[i] = toConsentTemplateResponse(t)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": data,
+	})
+}
+
+// GetTemplate returns a specific consent text version.
+// @Summary Get consent template
+// @Description Returns a consent text version, including superseded ones
+// @Tags consents
+// @Accept json
+// @Produce json
+// @Param id path string true "Template ID"
+// @Success 200 {object} ConsentTemplateResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/consent-templates/{id} [get]
+func (h *ConsentHandler) GetTemplate(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	id := c.Param("id")
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Template ID is required",
+		})
+	}
+
+	tmpl, err := h.svc.Consent().GetTemplate(c.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "not_found",
+				Message: "Consent template not found",
+			})
+		}
+		log.Error().Err(err).Str("template_id", id).Msg("failed to get consent template")
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to get consent template",
+		})
+	}
+
+	return c.JSON(http.StatusOK, toConsentTemplateResponse(*tmpl))
+}
+
+// PublishTemplate publishes a new version of a consent text for the clinic.
+// @Summary Publish consent template
+// @Description Publishes a new clinic-specific version of a consent text. Earlier versions are kept for signed consents.
+// @Tags consents
+// @Accept json
+// @Produce json
+// @Param request body model.CreateConsentTemplateRequest true "Consent text"
+// @Success 201 {object} ConsentTemplateResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/consent-templates [post]
+func (h *ConsentHandler) PublishTemplate(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	var req model.CreateConsentTemplateRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Failed to parse request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Request validation failed",
+			Details: validator.FormatErrors(err),
+		})
+	}
+
+	tmpl, err := h.svc.Consent().PublishTemplate(c.Request().Context(), user.ClinicID, user.UserID, &req)
+	if err != nil {
+		log.Error().Err(err).Str("consent_type", req.ConsentType).Msg("failed to publish consent template")
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to publish consent template",
+		})
+	}
+
+	return c.JSON(http.StatusCreated, toConsentTemplateResponse(*tmpl))
+}
+
+// ListPatientConsents returns a patient's consent summary and history.
+// @Summary List patient consents
+// @Description Returns the patient's standing for every consent type and their full consent history
+// @Tags consents
+// @Accept json
+// @Produce json
+// @Param id path string true "Patient ID"
+// @Success 200 {object} PatientConsentsResponse
+// @Failure 401 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/patients/{id}/consents [get]
+func (h *ConsentHandler) ListPatientConsents(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	patientID := c.Param("id")
+	if patientID == "" {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Patient ID is required",
+		})
+	}
+
+	ctx := c.Request().Context()
+
+	summary, err := h.svc.Consent().GetPatientSummary(ctx, user.ClinicID, patientID)
+	if err != nil {
+		log.Error().Err(err).Str("patient_id", patientID).Msg("failed to get consent summary")
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to get patient consents",
+		})
+	}
+
+	history, err := h.svc.Consent().ListPatientConsents(ctx, user.ClinicID, patientID)
+	if err != nil {
+		log.Error().Err(err).Str("patient_id", patientID).Msg("failed to list patient consents")
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to get patient consents",
+		})
+	}
+
+	response := PatientConsentsResponse{
+		Summary: make([]ConsentSummaryResponse, len(summary)),
+		History: make([]PatientConsentResponse, len(history)),
+	}
+	for i, s := range summary {
+		response.Summary[i] = toConsentSummaryResponse(s)
+	}
+	for i, pc := range history {
+		response.History[i] = toPatientConsentResponse(pc)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// RecordConsent captures a patient's signed consent.
+// @Summary Record patient consent
+// @Description Records the patient's signature against the current text of a consent type. A guardian may sign with signer_relationship set.
+// @Tags consents
+// @Accept json
+// @Produce json
+// @Param id path string true "Patient ID"
+// @Param request body model.RecordConsentRequest true "Consent and signature"
+// @Success 201 {object} PatientConsentResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/patients/{id}/consents [post]
+func (h *ConsentHandler) RecordConsent(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	patientID := c.Param("id")
+	if patientID == "" {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Patient ID is required",
+		})
+	}
+
+	var req model.RecordConsentRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Failed to parse request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Request validation failed",
+			Details: validator.FormatErrors(err),
+		})
+	}
+
+	consent, err := h.svc.Consent().RecordConsent(c.Request().Context(), user.ClinicID, patientID, user.UserID, &req)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "not_found",
+				Message: "Patient not found",
+			})
+		}
+		if errors.Is(err, repository.ErrInvalidInput) {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_request",
+				Message: err.Error(),
+			})
+		}
+		log.Error().Err(err).Str("patient_id", patientID).Msg("failed to record consent")
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to record consent",
+		})
+	}
+
+	return c.JSON(http.StatusCreated, toPatientConsentResponse(*consent))
+}
+
+// WithdrawConsent withdraws a patient's consent.
+// @Summary Withdraw patient consent
+// @Description Withdraws an active consent. Features that depend on it stop immediately.
+// @Tags consents
+// @Accept json
+// @Produce json
+// @Param id path string true "Patient ID"
+// @Param consentId path string true "Consent ID"
+// @Param request body model.WithdrawConsentRequest false "Withdrawal reason"
+// @Success 204 "No Content"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/patients/{id}/consents/{consentId}/withdraw [post]
+func (h *ConsentHandler) WithdrawConsent(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	patientID := c.Param("id")
+	consentID := c.Param("consentId")
+	if patientID == "" || consentID == "" {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Patient ID and Consent ID are required",
+		})
+	}
+
+	var req model.WithdrawConsentRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Failed to parse request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Request validation failed",
+			Details: validator.FormatErrors(err),
+		})
+	}
+
+	if err := h.svc.Consent().WithdrawConsent(c.Request().Context(), user.ClinicID, patientID, consentID, user.UserID, &req); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "not_found",
+				Message: "Active consent not found",
+			})
+		}
+		log.Error().Err(err).Str("consent_id", consentID).Msg("failed to withdraw consent")
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to withdraw consent",
+		})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// toConsentTemplateResponse converts a ConsentTemplate model to ConsentTemplateResponse.
+func toConsentTemplateResponse(t model.ConsentTemplate) ConsentTemplateResponse {
+	return ConsentTemplateResponse{
+		ID:          t.ID,
+		ConsentType: string(t.ConsentType),
+		Version:     t.Version,
+		IsCurrent:   t.IsCurrent,
+		IsGlobal:    t.ClinicID == nil,
+		Title:       t.Title,
+		TitleVi:     t.TitleVi,
+		Body:        t.Body,
+		BodyVi:      t.BodyVi,
+		CreatedAt:   t.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// toPatientConsentResponse converts a PatientConsent model to PatientConsentResponse.
+func toPatientConsentResponse(pc model.PatientConsent) PatientConsentResponse {
+	resp := PatientConsentResponse{
+		ID:                 pc.ID,
+		PatientID:          pc.PatientID,
+		TemplateID:         pc.TemplateID,
+		ConsentType:        string(pc.ConsentType),
+		TemplateVersion:    pc.TemplateVersion,
+		Title:              pc.Title,
+		TitleVi:            pc.TitleVi,
+		Status:             string(pc.Status),
+		SignedByName:       pc.SignedByName,
+		SignerRelationship: pc.SignerRelationship,
+		GrantedAt:          pc.GrantedAt.Format(time.RFC3339),
+		WithdrawalReason:   pc.WithdrawalReason,
+	}
+
+	if pc.Signature.SignatureData != "" {
+		signature := pc.Signature
+		resp.Signature = &signature
+	}
+
+	if pc.WithdrawnAt != nil {
+		formatted := pc.WithdrawnAt.Format(time.RFC3339)
+		resp.WithdrawnAt = &formatted
+	}
+
+	return resp
+}
+
+// toConsentSummaryResponse converts a ConsentSummary model to ConsentSummaryResponse.
+func toConsentSummaryResponse(s model.ConsentSummary) ConsentSummaryResponse {
+	resp := ConsentSummaryResponse{
+		ConsentType:    string(s.ConsentType),
+		Active:         s.Active,
+		CurrentVersion: s.CurrentVersion,
+		NeedsRenewal:   s.NeedsRenewal,
+	}
+
+	if s.Consent != nil {
+		consent := toPatientConsentResponse(*s.Consent)
+		resp.Consent = &consent
+	}
+
+	return resp
+}
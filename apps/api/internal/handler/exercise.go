@@ -52,6 +52,8 @@ type ExerciseResponse struct {
 	IsActive        bool     `json:"is_active"`
 	CreatedAt       string   `json:"created_at"`
 	UpdatedAt       string   `json:"updated_at"`
+
+	Media []ExerciseMediaResponse `json:"media,omitempty"`
 }
 
 // ExerciseListResponse represents a paginated list of exercises.
@@ -65,40 +67,43 @@ type ExerciseListResponse struct {
 
 // PrescriptionResponse represents a prescription in API responses.
 type PrescriptionResponse struct {
-	ID                 string            `json:"id"`
-	PatientID          string            `json:"patient_id"`
-	ExerciseID         string            `json:"exercise_id"`
-	ProgramID          *string           `json:"program_id,omitempty"`
-	Sets               int               `json:"sets"`
-	Reps               int               `json:"reps"`
-	HoldSeconds        int               `json:"hold_seconds"`
-	Frequency          string            `json:"frequency"`
-	DurationWeeks      int               `json:"duration_weeks"`
-	CustomInstructions string            `json:"custom_instructions,omitempty"`
-	Notes              string            `json:"notes,omitempty"`
-	Status             string            `json:"status"`
-	StartDate          string            `json:"start_date"`
-	EndDate            *string           `json:"end_date,omitempty"`
-	CreatedAt          string            `json:"created_at"`
-	UpdatedAt          string            `json:"updated_at"`
-	Exercise           *ExerciseResponse `json:"exercise,omitempty"`
+	ID                 string                   `json:"id"`
+	PatientID          string                   `json:"patient_id"`
+	ExerciseID         string                   `json:"exercise_id"`
+	ProgramID          *string                  `json:"program_id,omitempty"`
+	Sets               int                      `json:"sets"`
+	Reps               int                      `json:"reps"`
+	HoldSeconds        int                      `json:"hold_seconds"`
+	Frequency          string                   `json:"frequency"`
+	FrequencySchedule  *model.FrequencySchedule `json:"frequency_schedule,omitempty"`
+	DurationWeeks      int                      `json:"duration_weeks"`
+	CustomInstructions string                   `json:"custom_instructions,omitempty"`
+	Notes              string                   `json:"notes,omitempty"`
+	Status             string                   `json:"status"`
+	StartDate          string                   `json:"start_date"`
+	EndDate            *string                  `json:"end_date,omitempty"`
+	CreatedAt          string                   `json:"created_at"`
+	UpdatedAt          string                   `json:"updated_at"`
+	Exercise           *ExerciseResponse        `json:"exercise,omitempty"`
 }
 
 // ProgramResponse represents a home exercise program in API responses.
 type ProgramResponse struct {
-	ID            string                 `json:"id"`
-	PatientID     string                 `json:"patient_id"`
-	Name          string                 `json:"name"`
-	NameVi        string                 `json:"name_vi,omitempty"`
-	Description   string                 `json:"description,omitempty"`
-	DescriptionVi string                 `json:"description_vi,omitempty"`
-	Frequency     string                 `json:"frequency"`
-	DurationWeeks int                    `json:"duration_weeks"`
-	StartDate     string                 `json:"start_date"`
-	EndDate       *string                `json:"end_date,omitempty"`
-	IsActive      bool                   `json:"is_active"`
-	CreatedAt     string                 `json:"created_at"`
-	Exercises     []PrescriptionResponse `json:"exercises,omitempty"`
+	ID                string                   `json:"id"`
+	PatientID         string                   `json:"patient_id"`
+	Name              string                   `json:"name"`
+	NameVi            string                   `json:"name_vi,omitempty"`
+	Description       string                   `json:"description,omitempty"`
+	DescriptionVi     string                   `json:"description_vi,omitempty"`
+	Frequency         string                   `json:"frequency"`
+	FrequencySchedule *model.FrequencySchedule `json:"frequency_schedule,omitempty"`
+	DurationWeeks     int                      `json:"duration_weeks"`
+	StartDate         string                   `json:"start_date"`
+	EndDate           *string                  `json:"end_date,omitempty"`
+	IsActive          bool                     `json:"is_active"`
+	CreatedAt         string                   `json:"created_at"`
+	Exercises         []PrescriptionResponse   `json:"exercises,omitempty"`
+	Phases            []model.ProgramPhase     `json:"phases,omitempty"`
 }
 
 // ComplianceLogResponse represents a compliance log in API responses.
@@ -373,13 +378,14 @@ func (h *ExerciseHandler) Update(c echo.Context) error {
 
 // Delete deletes an exercise.
 // @Summary Delete exercise
-// @Description Soft deletes an exercise
+// @Description Soft deletes one of the clinic's own exercises. Its media is kept. Global exercises cannot be deleted.
 // @Tags exercises
 // @Accept json
 // @Produce json
 // @Param id path string true "Exercise ID"
 // @Success 204 "No Content"
 // @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
 // @Security BearerAuth
 // @Router /api/v1/exercises/{id} [delete]
@@ -400,7 +406,7 @@ func (h *ExerciseHandler) Delete(c echo.Context) error {
 		})
 	}
 
-	err := h.svc.Exercise().DeleteExercise(c.Request().Context(), id)
+	err := h.svc.Exercise().DeleteExercise(c.Request().Context(), user.ClinicID, id)
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
 			return c.JSON(http.StatusNotFound, ErrorResponse{
@@ -408,6 +414,12 @@ func (h *ExerciseHandler) Delete(c echo.Context) error {
 				Message: "Exercise not found",
 			})
 		}
+		if errors.Is(err, service.ErrReadOnlyExercise) {
+			return c.JSON(http.StatusForbidden, ErrorResponse{
+				Error:   "forbidden",
+				Message: err.Error(),
+			})
+		}
 		log.Error().Err(err).Str("exercise_id", id).Msg("failed to delete exercise")
 		return c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Error:   "internal_error",
@@ -507,8 +519,14 @@ func (h *ExerciseHandler) GetPatientExercises(c echo.Context) error {
 		activeOnly = true
 	}
 
-	prescriptions, err := h.svc.Exercise().GetPatientPrescriptions(c.Request().Context(), patientID, activeOnly)
+	prescriptions, err := h.svc.Exercise().GetPatientPrescriptions(c.Request().Context(), user.ClinicID, patientID, activeOnly)
 	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "not_found",
+				Message: "Patient not found",
+			})
+		}
 		log.Error().Err(err).Str("patient_id", patientID).Msg("failed to get patient exercises")
 		return c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Error:   "internal_error",
@@ -580,6 +598,12 @@ func (h *ExerciseHandler) PrescribeExercise(c echo.Context) error {
 				Message: "Exercise not found",
 			})
 		}
+		if errors.Is(err, repository.ErrInvalidInput) {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_request",
+				Message: err.Error(),
+			})
+		}
 		log.Error().Err(err).Str("patient_id", patientID).Msg("failed to prescribe exercise")
 		return c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Error:   "internal_error",
@@ -615,11 +639,12 @@ func (h *ExerciseHandler) UpdatePrescription(c echo.Context) error {
 		})
 	}
 
+	patientID := c.Param("pid")
 	id := c.Param("id")
-	if id == "" {
+	if patientID == "" || id == "" {
 		return c.JSON(http.StatusBadRequest, ErrorResponse{
 			Error:   "invalid_request",
-			Message: "Prescription ID is required",
+			Message: "Patient ID and Prescription ID are required",
 		})
 	}
 
@@ -639,7 +664,7 @@ func (h *ExerciseHandler) UpdatePrescription(c echo.Context) error {
 		})
 	}
 
-	prescription, err := h.svc.Exercise().UpdatePrescription(c.Request().Context(), id, &req)
+	prescription, err := h.svc.Exercise().UpdatePrescription(c.Request().Context(), user.ClinicID, patientID, id, user.UserID, &req)
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
 			return c.JSON(http.StatusNotFound, ErrorResponse{
@@ -647,6 +672,12 @@ func (h *ExerciseHandler) UpdatePrescription(c echo.Context) error {
 				Message: "Prescription not found",
 			})
 		}
+		if errors.Is(err, repository.ErrInvalidInput) {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_request",
+				Message: err.Error(),
+			})
+		}
 		log.Error().Err(err).Str("prescription_id", id).Msg("failed to update prescription")
 		return c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Error:   "internal_error",
@@ -679,15 +710,16 @@ func (h *ExerciseHandler) DeletePrescription(c echo.Context) error {
 		})
 	}
 
+	patientID := c.Param("pid")
 	id := c.Param("id")
-	if id == "" {
+	if patientID == "" || id == "" {
 		return c.JSON(http.StatusBadRequest, ErrorResponse{
 			Error:   "invalid_request",
-			Message: "Prescription ID is required",
+			Message: "Patient ID and Prescription ID are required",
 		})
 	}
 
-	err := h.svc.Exercise().DeletePrescription(c.Request().Context(), id)
+	err := h.svc.Exercise().DeletePrescription(c.Request().Context(), user.ClinicID, patientID, id)
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
 			return c.JSON(http.StatusNotFound, ErrorResponse{
@@ -740,8 +772,14 @@ func (h *ExerciseHandler) GetHandout(c echo.Context) error {
 		language = "vi"
 	}
 
-	pdfData, err := h.svc.Exercise().GenerateHandoutPDF(c.Request().Context(), patientID, language)
+	pdfData, err := h.svc.Exercise().GenerateHandoutPDF(c.Request().Context(), user.ClinicID, patientID, language)
 	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "not_found",
+				Message: "Patient not found",
+			})
+		}
 		log.Error().Err(err).Str("patient_id", patientID).Msg("failed to generate handout")
 		return c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Error:   "internal_error",
@@ -786,8 +824,14 @@ func (h *ExerciseHandler) GetComplianceSummary(c echo.Context) error {
 		})
 	}
 
-	summary, err := h.svc.Exercise().GetPatientComplianceSummary(c.Request().Context(), patientID)
+	summary, err := h.svc.Exercise().GetPatientComplianceSummary(c.Request().Context(), user.ClinicID, patientID)
 	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "not_found",
+				Message: "Patient not found",
+			})
+		}
 		log.Error().Err(err).Str("patient_id", patientID).Msg("failed to get compliance summary")
 		return c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Error:   "internal_error",
@@ -861,7 +905,7 @@ func (h *ExerciseHandler) LogCompliance(c echo.Context) error {
 		})
 	}
 
-	complianceLog, err := h.svc.Exercise().LogCompliance(c.Request().Context(), prescriptionID, patientID, &req)
+	complianceLog, err := h.svc.Exercise().LogCompliance(c.Request().Context(), user.ClinicID, patientID, prescriptionID, &req)
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
 			return c.JSON(http.StatusNotFound, ErrorResponse{
@@ -886,7 +930,7 @@ func toExerciseResponse(e model.Exercise) ExerciseResponse {
 		muscleGroups[i] = string(mg)
 	}
 
-	return ExerciseResponse{
+	resp := ExerciseResponse{
 		ID:              e.ID,
 		ClinicID:        e.ClinicID,
 		Name:            e.Name,
@@ -912,6 +956,10 @@ func toExerciseResponse(e model.Exercise) ExerciseResponse {
 		CreatedAt:       e.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:       e.UpdatedAt.Format(time.RFC3339),
 	}
+	if len(e.Media) > 0 {
+		resp.Media = toExerciseMediaResponses(e.Media)
+	}
+	return resp
 }
 
 // toPrescriptionResponse converts an ExercisePrescription model to PrescriptionResponse.
@@ -925,6 +973,7 @@ func toPrescriptionResponse(p model.ExercisePrescription) PrescriptionResponse {
 		Reps:               p.Reps,
 		HoldSeconds:        p.HoldSeconds,
 		Frequency:          p.Frequency,
+		FrequencySchedule:  p.FrequencySchedule,
 		DurationWeeks:      p.DurationWeeks,
 		CustomInstructions: p.CustomInstructions,
 		Notes:              p.Notes,
@@ -960,3 +1009,36 @@ func toComplianceLogResponse(l model.ExerciseComplianceLog) ComplianceLogRespons
 		Notes:          l.Notes,
 	}
 }
+
+// toProgramResponse converts a HomeExerciseProgram model to ProgramResponse.
+func toProgramResponse(p model.HomeExerciseProgram) ProgramResponse {
+	resp := ProgramResponse{
+		ID:                p.ID,
+		PatientID:         p.PatientID,
+		Name:              p.Name,
+		NameVi:            p.NameVi,
+		Description:       p.Description,
+		DescriptionVi:     p.DescriptionVi,
+		Frequency:         p.Frequency,
+		FrequencySchedule: p.FrequencySchedule,
+		DurationWeeks:     p.DurationWeeks,
+		StartDate:         p.StartDate.Format("2006-01-02"),
+		IsActive:          p.IsActive,
+		CreatedAt:         p.CreatedAt.Format(time.RFC3339),
+	}
+
+	if p.EndDate != nil {
+		formatted := p.EndDate.Format("2006-01-02")
+		resp.EndDate = &formatted
+	}
+
+	if len(p.Exercises) > 0 {
+		resp.Exercises = make([]PrescriptionResponse, len(p.Exercises))
+		for i, e := range p.Exercises {
+			resp.Exercises[i] = toPrescriptionResponse(e)
+		}
+	}
+	resp.Phases = p.Phases
+
+	return resp
+}
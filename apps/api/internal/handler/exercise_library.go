@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/middleware"
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/service"
+	"github.com/tqvdang/physioflow/apps/api/pkg/validator"
+)
+
+// ExportExercises downloads the clinic's exercise library.
+// @Summary Export exercise library
+// @Description Downloads the clinic's active exercises as JSON or CSV, with references to their uploaded media. CSV list cells (equipment, muscle_groups, media) separate values with "|".
+// @Tags exercises
+// @Produce json
+// @Produce text/csv
+// @Param format query string false "json (default) or csv"
+// @Param include_global query bool false "Include the global exercise library"
+// @Success 200 {object} model.ExerciseLibrary
+// @Failure 401 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/exercises/export [get]
+func (h *ExerciseHandler) ExportExercises(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	var params model.ExportExercisesParams
+	if err := c.Bind(&params); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid query parameters",
+		})
+	}
+
+	if err := validator.Validate(params); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Request validation failed",
+			Details: validator.FormatErrors(err),
+		})
+	}
+	if params.Format == "" {
+		params.Format = string(model.ExerciseLibraryJSON)
+	}
+
+	data, err := h.svc.ExerciseLibrary().Export(c.Request().Context(), user.ClinicID, params)
+	if err != nil {
+		return exerciseMediaError(c, err, "Failed to export exercises")
+	}
+
+	contentType := echo.MIMEApplicationJSONCharsetUTF8
+	if params.Format == string(model.ExerciseLibraryCSV) {
+		contentType = "text/csv; charset=utf-8"
+	}
+	fileName := fmt.Sprintf("exercises-%s.%s", time.Now().Format("20060102"), params.Format)
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", fileName))
+	return c.Blob(http.StatusOK, contentType, data)
+}
+
+// ImportExercises adds exercises from a JSON or CSV library file.
+// @Summary Import exercise library
+// @Description Creates exercises in the clinic's library from a file made by the export endpoint, or a bare JSON array of exercises. Each exercise is validated by the rules for creating one. Exercises whose name or name_vi matches an exercise already in the clinic's or global library, or earlier in the file, are skipped unless allow_duplicates is set. Referenced media are copied when they belong to a clinic of the same tenant. With dry_run nothing is created.
+// @Tags exercises
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Exercise library (max 5 MB)"
+// @Param format query string false "json or csv; defaults from the file extension"
+// @Param dry_run query bool false "Only validate"
+// @Param allow_duplicates query bool false "Import exercises with duplicate names"
+// @Success 200 {object} model.ExerciseImportResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/exercises/import [post]
+func (h *ExerciseHandler) ImportExercises(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	var params model.ImportExercisesParams
+	if err := (&echo.DefaultBinder{}).BindQueryParams(c, &params); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid query parameters",
+		})
+	}
+
+	if err := validator.Validate(params); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Request validation failed",
+			Details: validator.FormatErrors(err),
+		})
+	}
+
+	c.Request().Body = http.MaxBytesReader(c.Response(), c.Request().Body, service.MaxExerciseImportSize+1<<20)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "A file is required",
+		})
+	}
+	if fileHeader.Size > service.MaxExerciseImportSize {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: fmt.Sprintf("File exceeds %d MB", service.MaxExerciseImportSize>>20),
+		})
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Failed to read uploaded file",
+		})
+	}
+	defer file.Close()
+
+	if params.Format == "" {
+		params.Format = string(model.ExerciseLibraryJSON)
+		if strings.EqualFold(path.Ext(fileHeader.Filename), ".csv") {
+			params.Format = string(model.ExerciseLibraryCSV)
+		}
+	}
+
+	result, err := h.svc.ExerciseLibrary().Import(c.Request().Context(), user.ClinicID, user.UserID, params, file)
+	if err != nil {
+		return exerciseMediaError(c, err, "Failed to import exercises")
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// PublishExercise copies an exercise to the other clinics of the tenant.
+// @Summary Publish exercise to tenant library
+// @Description Copies one of the clinic's exercises, with its media, to every other active clinic of the same tenant. Clinics that already have an exercise with the same name or name_vi are skipped.
+// @Tags exercises
+// @Produce json
+// @Param id path string true "Exercise ID"
+// @Success 200 {object} model.ExercisePublishResult
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/exercises/{id}/publish [post]
+func (h *ExerciseHandler) PublishExercise(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	result, err := h.svc.ExerciseLibrary().Publish(c.Request().Context(), user.ClinicID, user.UserID, c.Param("id"))
+	if err != nil {
+		return exerciseMediaError(c, err, "Failed to publish exercise")
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
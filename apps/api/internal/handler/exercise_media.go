@@ -0,0 +1,398 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/middleware"
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/repository"
+	"github.com/tqvdang/physioflow/apps/api/internal/service"
+	"github.com/tqvdang/physioflow/apps/api/pkg/validator"
+)
+
+// exerciseMediaCacheControl lets browsers keep media files, which never
+// change once uploaded; a replaced file gets a new ID and URL.
+const exerciseMediaCacheControl = "private, max-age=31536000, immutable"
+
+// ExerciseMediaResponse represents an exercise photo or clip in API responses.
+type ExerciseMediaResponse struct {
+	ID              string   `json:"id"`
+	ExerciseID      string   `json:"exercise_id"`
+	Kind            string   `json:"kind"`
+	Position        int      `json:"position"`
+	Caption         string   `json:"caption,omitempty"`
+	CaptionVi       string   `json:"caption_vi,omitempty"`
+	FileName        string   `json:"file_name"`
+	ContentType     string   `json:"content_type"`
+	SizeBytes       int64    `json:"size_bytes"`
+	Width           *int     `json:"width,omitempty"`
+	Height          *int     `json:"height,omitempty"`
+	DurationSeconds *float64 `json:"duration_seconds,omitempty"`
+	URL             string   `json:"url"`
+	ThumbnailURL    string   `json:"thumbnail_url,omitempty"`
+	CreatedAt       string   `json:"created_at"`
+}
+
+// ListExerciseMedia returns an exercise's photos and clips.
+// @Summary List exercise media
+// @Description Returns the photos and clips uploaded for an exercise, in display order
+// @Tags exercises
+// @Produce json
+// @Param id path string true "Exercise ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/exercises/{id}/media [get]
+func (h *ExerciseHandler) ListExerciseMedia(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	items, err := h.svc.ExerciseMedia().List(c.Request().Context(), user.ClinicID, c.Param("id"))
+	if err != nil {
+		return exerciseMediaError(c, err, "Failed to list exercise media")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": toExerciseMediaResponses(items),
+	})
+}
+
+// UploadExerciseMedia adds a photo or clip to one of the clinic's exercises.
+// @Summary Upload exercise media
+// @Description Uploads a photo (JPEG, PNG, GIF or WebP, max 10 MB) or clip (MP4, MOV or WebM, max 100 MB and 5 minutes) after the exercise's other media. Video duration and dimensions are read from the file, and a thumbnail is made when ffmpeg is installed. Exercises from the global library are read-only.
+// @Tags exercises
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Exercise ID"
+// @Param file formData file true "Photo or clip"
+// @Param caption formData string false "Caption"
+// @Param caption_vi formData string false "Vietnamese caption"
+// @Success 201 {object} ExerciseMediaResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/exercises/{id}/media [post]
+func (h *ExerciseHandler) UploadExerciseMedia(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	c.Request().Body = http.MaxBytesReader(c.Response(), c.Request().Body, service.MaxExerciseVideoSize+1<<20)
+
+	req := model.UploadExerciseMediaRequest{
+		Caption:   c.FormValue("caption"),
+		CaptionVi: c.FormValue("caption_vi"),
+	}
+	if err := validator.Validate(req); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Request validation failed",
+			Details: validator.FormatErrors(err),
+		})
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "A file is required",
+		})
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Failed to read uploaded file",
+		})
+	}
+	defer file.Close()
+
+	m, err := h.svc.ExerciseMedia().Upload(c.Request().Context(), user.ClinicID, c.Param("id"), user.UserID,
+		&req, fileHeader.Filename, fileHeader.Header.Get(echo.HeaderContentType), file)
+	if err != nil {
+		return exerciseMediaError(c, err, "Failed to upload exercise media")
+	}
+
+	return c.JSON(http.StatusCreated, toExerciseMediaResponse(*m))
+}
+
+// GetExerciseMedia streams an exercise photo or clip.
+// @Summary Get exercise media file
+// @Description Streams the file. Responses may be cached; send If-None-Match with the ETag to revalidate.
+// @Tags exercises
+// @Produce octet-stream
+// @Param id path string true "Exercise ID"
+// @Param mediaId path string true "Media ID"
+// @Success 200 {file} binary
+// @Success 304 "Not Modified"
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/exercises/{id}/media/{mediaId} [get]
+func (h *ExerciseHandler) GetExerciseMedia(c echo.Context) error {
+	return h.serveExerciseMedia(c, false)
+}
+
+// GetExerciseMediaThumbnail streams a clip's thumbnail.
+// @Summary Get exercise video thumbnail
+// @Description Streams the JPEG thumbnail of a clip. Not every clip has one.
+// @Tags exercises
+// @Produce jpeg
+// @Param id path string true "Exercise ID"
+// @Param mediaId path string true "Media ID"
+// @Success 200 {file} binary
+// @Success 304 "Not Modified"
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/exercises/{id}/media/{mediaId}/thumbnail [get]
+func (h *ExerciseHandler) GetExerciseMediaThumbnail(c echo.Context) error {
+	return h.serveExerciseMedia(c, true)
+}
+
+// serveExerciseMedia streams a media file or its thumbnail with cache headers.
+func (h *ExerciseHandler) serveExerciseMedia(c echo.Context, thumbnail bool) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	content, m, err := h.svc.ExerciseMedia().Open(c.Request().Context(), user.ClinicID, c.Param("id"), c.Param("mediaId"), thumbnail)
+	if err != nil {
+		return exerciseMediaError(c, err, "Failed to get exercise media")
+	}
+	defer content.Close()
+
+	etag := fmt.Sprintf("%q", m.SHA256)
+	contentType := m.ContentType
+	if thumbnail {
+		etag = fmt.Sprintf("%q", m.SHA256+"-thumbnail")
+		contentType = "image/jpeg"
+	}
+
+	header := c.Response().Header()
+	header.Set("Cache-Control", exerciseMediaCacheControl)
+	header.Set("ETag", etag)
+	if c.Request().Header.Get("If-None-Match") == etag {
+		return c.NoContent(http.StatusNotModified)
+	}
+
+	if !thumbnail {
+		header.Set(echo.HeaderContentLength, strconv.FormatInt(m.SizeBytes, 10))
+		header.Set(echo.HeaderContentDisposition, fmt.Sprintf("inline; filename=%q", m.FileName))
+	}
+	return c.Stream(http.StatusOK, contentType, content)
+}
+
+// UpdateExerciseMedia changes a photo or clip's captions.
+// @Summary Update exercise media captions
+// @Description Changes the English and Vietnamese captions; omitted fields are left unchanged
+// @Tags exercises
+// @Accept json
+// @Produce json
+// @Param id path string true "Exercise ID"
+// @Param mediaId path string true "Media ID"
+// @Param media body model.UpdateExerciseMediaRequest true "Captions"
+// @Success 200 {object} ExerciseMediaResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/exercises/{id}/media/{mediaId} [put]
+func (h *ExerciseHandler) UpdateExerciseMedia(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	var req model.UpdateExerciseMediaRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Failed to parse request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Request validation failed",
+			Details: validator.FormatErrors(err),
+		})
+	}
+
+	m, err := h.svc.ExerciseMedia().Update(c.Request().Context(), user.ClinicID, c.Param("id"), c.Param("mediaId"), &req)
+	if err != nil {
+		return exerciseMediaError(c, err, "Failed to update exercise media")
+	}
+
+	return c.JSON(http.StatusOK, toExerciseMediaResponse(*m))
+}
+
+// ReorderExerciseMedia sets the display order of an exercise's media.
+// @Summary Reorder exercise media
+// @Description Puts the exercise's photos and clips in the given order. media_ids must list all of them.
+// @Tags exercises
+// @Accept json
+// @Produce json
+// @Param id path string true "Exercise ID"
+// @Param order body model.ReorderExerciseMediaRequest true "Media IDs in display order"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/exercises/{id}/media/order [put]
+func (h *ExerciseHandler) ReorderExerciseMedia(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	var req model.ReorderExerciseMediaRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Failed to parse request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Request validation failed",
+			Details: validator.FormatErrors(err),
+		})
+	}
+
+	items, err := h.svc.ExerciseMedia().Reorder(c.Request().Context(), user.ClinicID, c.Param("id"), &req)
+	if err != nil {
+		return exerciseMediaError(c, err, "Failed to reorder exercise media")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": toExerciseMediaResponses(items),
+	})
+}
+
+// DeleteExerciseMedia removes a photo or clip from an exercise.
+// @Summary Delete exercise media
+// @Description Removes the media and its stored files
+// @Tags exercises
+// @Param id path string true "Exercise ID"
+// @Param mediaId path string true "Media ID"
+// @Success 204 "No Content"
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/exercises/{id}/media/{mediaId} [delete]
+func (h *ExerciseHandler) DeleteExerciseMedia(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	if err := h.svc.ExerciseMedia().Delete(c.Request().Context(), user.ClinicID, c.Param("id"), c.Param("mediaId")); err != nil {
+		return exerciseMediaError(c, err, "Failed to delete exercise media")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// exerciseMediaError maps exercise media service errors to HTTP responses.
+func exerciseMediaError(c echo.Context, err error, failureMsg string) error {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "not_found",
+			Message: "Exercise or media not found",
+		})
+	case errors.Is(err, service.ErrReadOnlyExercise):
+		return c.JSON(http.StatusForbidden, ErrorResponse{
+			Error:   "forbidden",
+			Message: err.Error(),
+		})
+	case errors.Is(err, repository.ErrInvalidInput):
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+	}
+
+	log.Error().Err(err).Str("path", c.Path()).Msg("exercise media request failed")
+	return c.JSON(http.StatusInternalServerError, ErrorResponse{
+		Error:   "internal_error",
+		Message: failureMsg,
+	})
+}
+
+// toExerciseMediaResponse converts an ExerciseMedia to ExerciseMediaResponse.
+func toExerciseMediaResponse(m model.ExerciseMedia) ExerciseMediaResponse {
+	url := m.URL()
+	resp := ExerciseMediaResponse{
+		ID:              m.ID,
+		ExerciseID:      m.ExerciseID,
+		Kind:            string(m.Kind),
+		Position:        m.Position,
+		Caption:         m.Caption,
+		CaptionVi:       m.CaptionVi,
+		FileName:        m.FileName,
+		ContentType:     m.ContentType,
+		SizeBytes:       m.SizeBytes,
+		Width:           m.Width,
+		Height:          m.Height,
+		DurationSeconds: m.DurationSeconds,
+		URL:             url,
+		CreatedAt:       m.CreatedAt.Format(time.RFC3339),
+	}
+	if m.HasThumbnail() {
+		resp.ThumbnailURL = url + "/thumbnail"
+	}
+	return resp
+}
+
+// toExerciseMediaResponses converts a list of ExerciseMedia.
+func toExerciseMediaResponses(items []model.ExerciseMedia) []ExerciseMediaResponse {
+	data := make([]ExerciseMediaResponse, len(items))
+	for i, m := range items {
+		data[i] = toExerciseMediaResponse(m)
+	}
+	return data
+}
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/middleware"
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/repository"
+	"github.com/tqvdang/physioflow/apps/api/pkg/validator"
+)
+
+// RecommendExercises suggests exercises for a patient presentation.
+// @Summary Recommend exercises
+// @Description Suggests exercises from the clinic's and global library for a diagnosis code, body regions and findings, given directly or read from a visit checklist's body diagram and examination items. Exercises are ranked by deterministic rules: muscle groups against body regions, category against findings, difficulty against the patient's age and pain level (taken from the patient record when not given), and how often the clinic has prescribed them for the diagnosis. Each recommendation has a reason naming the rules it met.
+// @Tags exercises
+// @Accept json
+// @Produce json
+// @Param request body model.ExerciseRecommendationRequest true "Patient presentation"
+// @Success 200 {object} model.ExerciseRecommendationResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/exercises/recommendations [post]
+func (h *ExerciseHandler) RecommendExercises(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	var req model.ExerciseRecommendationRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Request validation failed",
+			Details: validator.FormatErrors(err),
+		})
+	}
+
+	result, err := h.svc.ExerciseRecommendation().Recommend(c.Request().Context(), user.ClinicID, &req)
+	if err != nil {
+		return exerciseRecommendationError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// exerciseRecommendationError maps recommendation errors to responses.
+func exerciseRecommendationError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "not_found",
+			Message: "Patient or visit checklist not found",
+		})
+	case errors.Is(err, repository.ErrInvalidInput):
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+	}
+
+	log.Error().Err(err).Str("path", c.Path()).Msg("exercise recommendation request failed")
+	return c.JSON(http.StatusInternalServerError, ErrorResponse{
+		Error:   "internal_error",
+		Message: "Failed to recommend exercises",
+	})
+}
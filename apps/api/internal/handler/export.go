@@ -0,0 +1,318 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/middleware"
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/repository"
+	"github.com/tqvdang/physioflow/apps/api/internal/service"
+	"github.com/tqvdang/physioflow/apps/api/pkg/validator"
+)
+
+// ExportHandler handles patient record export requests.
+type ExportHandler struct {
+	svc *service.Service
+}
+
+// NewExportHandler creates a new ExportHandler.
+func NewExportHandler(svc *service.Service) *ExportHandler {
+	return &ExportHandler{svc: svc}
+}
+
+// ExportResponse represents a patient data export job in API responses.
+type ExportResponse struct {
+	ID                 string  `json:"id"`
+	PatientID          string  `json:"patient_id"`
+	Purpose            string  `json:"purpose"`
+	Recipient          string  `json:"recipient,omitempty"`
+	IncludeAttachments bool    `json:"include_attachments"`
+	Status             string  `json:"status"`
+	SizeBytes          int64   `json:"size_bytes,omitempty"`
+	SHA256             string  `json:"sha256,omitempty"`
+	Error              string  `json:"error,omitempty"`
+	DownloadURL        string  `json:"download_url,omitempty"`
+	CompletedAt        *string `json:"completed_at,omitempty"`
+	ExpiresAt          *string `json:"expires_at,omitempty"`
+	CreatedAt          string  `json:"created_at"`
+}
+
+// Request queues an export of a patient's record.
+// @Summary Export patient record
+// @Description Queues a zip export of the patient's full record. Poll the returned job until it completes, then follow download_url.
+// @Tags exports
+// @Accept json
+// @Produce json
+// @Param id path string true "Patient ID"
+// @Param request body model.CreateExportRequest true "Export details"
+// @Success 202 {object} ExportResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/patients/{id}/exports [post]
+func (h *ExportHandler) Request(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	patientID := c.Param("id")
+	if patientID == "" {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Patient ID is required",
+		})
+	}
+
+	var req model.CreateExportRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Failed to parse request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Request validation failed",
+			Details: validator.FormatErrors(err),
+		})
+	}
+
+	job, err := h.svc.Export().RequestExport(c.Request().Context(), user.ClinicID, patientID, user.UserID, &req)
+	if err != nil {
+		return exportError(c, err, "Patient not found", "Failed to request export")
+	}
+
+	return c.JSON(http.StatusAccepted, toExportResponse(*job, staffExportDownloadURL))
+}
+
+// List returns the export jobs for a patient.
+// @Summary List patient exports
+// @Description Returns the export jobs requested for a patient
+// @Tags exports
+// @Accept json
+// @Produce json
+// @Param id path string true "Patient ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/patients/{id}/exports [get]
+func (h *ExportHandler) List(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	patientID := c.Param("id")
+	if patientID == "" {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Patient ID is required",
+		})
+	}
+
+	jobs, err := h.svc.Export().ListPatientExports(c.Request().Context(), user.ClinicID, patientID)
+	if err != nil {
+		log.Error().Err(err).Str("patient_id", patientID).Msg("failed to list exports")
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to list exports",
+		})
+	}
+
+	data := make([]ExportResponse, len(jobs))
+	for i, j := range jobs {
+		data[i] = toExportResponse(j, staffExportDownloadURL)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": data,
+	})
+}
+
+// Get returns the status of an export job.
+// @Summary Get export status
+// @Description Returns an export job; download_url is set once the bundle is ready
+// @Tags exports
+// @Accept json
+// @Produce json
+// @Param id path string true "Export ID"
+// @Success 200 {object} ExportResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/exports/{id} [get]
+func (h *ExportHandler) Get(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	id := c.Param("id")
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Export ID is required",
+		})
+	}
+
+	job, err := h.svc.Export().GetExport(c.Request().Context(), user.ClinicID, id)
+	if err != nil {
+		return exportError(c, err, "Export not found", "Failed to get export")
+	}
+
+	return c.JSON(http.StatusOK, toExportResponse(*job, staffExportDownloadURL))
+}
+
+// Download streams a finished export bundle.
+// @Summary Download export
+// @Description Downloads the zip bundle of a completed export
+// @Tags exports
+// @Produce application/zip
+// @Param id path string true "Export ID"
+// @Success 200 {file} binary
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 410 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/exports/{id}/download [get]
+func (h *ExportHandler) Download(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	id := c.Param("id")
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Export ID is required",
+		})
+	}
+
+	content, job, err := h.svc.Export().OpenExport(c.Request().Context(), user.ClinicID, id, user.UserID)
+	if err != nil {
+		return exportError(c, err, "Export not found", "Failed to download export")
+	}
+	defer content.Close()
+
+	return streamExport(c, content, job)
+}
+
+// exportError maps export service errors to HTTP responses.
+func exportError(c echo.Context, err error, notFoundMsg, failureMsg string) error {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "not_found",
+			Message: notFoundMsg,
+		})
+	case errors.Is(err, service.ErrConsentRequired):
+		return c.JSON(http.StatusForbidden, ErrorResponse{
+			Error:   "consent_required",
+			Message: err.Error(),
+		})
+	case errors.Is(err, service.ErrExportNotReady):
+		return c.JSON(http.StatusConflict, ErrorResponse{
+			Error:   "not_ready",
+			Message: "Export is still being prepared",
+		})
+	case errors.Is(err, service.ErrExportExpired):
+		return c.JSON(http.StatusGone, ErrorResponse{
+			Error:   "expired",
+			Message: "Export download link has expired, please request a new export",
+		})
+	case errors.Is(err, repository.ErrInvalidInput):
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+	}
+
+	log.Error().Err(err).Str("path", c.Path()).Msg("export request failed")
+	return c.JSON(http.StatusInternalServerError, ErrorResponse{
+		Error:   "internal_error",
+		Message: failureMsg,
+	})
+}
+
+// streamExport writes an export bundle as a zip download.
+func streamExport(c echo.Context, content io.Reader, job *model.DataExport) error {
+	fileName := fmt.Sprintf("patient-export-%s-%s.zip", job.PatientID, job.CreatedAt.Format("20060102"))
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", fileName))
+	c.Response().Header().Set("Cache-Control", "no-store")
+	if job.SizeBytes > 0 {
+		c.Response().Header().Set(echo.HeaderContentLength, strconv.FormatInt(job.SizeBytes, 10))
+	}
+	return c.Stream(http.StatusOK, "application/zip", content)
+}
+
+// staffExportDownloadURL returns the staff download path for an export.
+func staffExportDownloadURL(id string) string {
+	return "/api/v1/exports/" + id + "/download"
+}
+
+// portalExportDownloadURL returns the patient portal download path for an export.
+func portalExportDownloadURL(id string) string {
+	return "/api/v1/me/exports/" + id + "/download"
+}
+
+// toExportResponse converts a DataExport to ExportResponse. The download URL is
+// only set while the bundle can be downloaded.
+func toExportResponse(e model.DataExport, downloadURL func(id string) string) ExportResponse {
+	resp := ExportResponse{
+		ID:                 e.ID,
+		PatientID:          e.PatientID,
+		Purpose:            string(e.Purpose),
+		Recipient:          e.Recipient,
+		IncludeAttachments: e.IncludeAttachments,
+		Status:             string(e.Status),
+		SizeBytes:          e.SizeBytes,
+		SHA256:             e.SHA256,
+		Error:              e.Error,
+		CreatedAt:          e.CreatedAt.Format(time.RFC3339),
+	}
+
+	if e.IsDownloadable(time.Now()) {
+		resp.DownloadURL = downloadURL(e.ID)
+	}
+	if e.CompletedAt != nil {
+		s := e.CompletedAt.Format(time.RFC3339)
+		resp.CompletedAt = &s
+	}
+	if e.ExpiresAt != nil {
+		s := e.ExpiresAt.Format(time.RFC3339)
+		resp.ExpiresAt = &s
+	}
+
+	return resp
+}
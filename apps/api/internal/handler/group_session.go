@@ -0,0 +1,501 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/middleware"
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/repository"
+	"github.com/tqvdang/physioflow/apps/api/internal/service"
+	"github.com/tqvdang/physioflow/apps/api/pkg/validator"
+)
+
+// GroupSessionHandler handles group class requests.
+type GroupSessionHandler struct {
+	svc *service.Service
+}
+
+// NewGroupSessionHandler creates a new GroupSessionHandler.
+func NewGroupSessionHandler(svc *service.Service) *GroupSessionHandler {
+	return &GroupSessionHandler{svc: svc}
+}
+
+// GroupSessionResponse represents a group session in API responses.
+type GroupSessionResponse struct {
+	ID                 string   `json:"id"`
+	TherapistID        string   `json:"therapist_id"`
+	TherapistName      string   `json:"therapist_name,omitempty"`
+	AppointmentTypeID  string   `json:"appointment_type_id,omitempty"`
+	Type               string   `json:"type"`
+	Title              string   `json:"title"`
+	StartTime          string   `json:"start_time"`
+	EndTime            string   `json:"end_time"`
+	Duration           int      `json:"duration"`
+	Capacity           int      `json:"capacity"`
+	BookedCount        int      `json:"booked_count"`
+	WaitlistCount      int      `json:"waitlist_count"`
+	SeatsLeft          int      `json:"seats_left"`
+	Room               string   `json:"room,omitempty"`
+	ResourceIDs        []string `json:"resource_ids,omitempty"`
+	Status             string   `json:"status"`
+	Notes              string   `json:"notes,omitempty"`
+	CancellationReason string   `json:"cancellation_reason,omitempty"`
+	CreatedAt          string   `json:"created_at"`
+	UpdatedAt          string   `json:"updated_at"`
+}
+
+// GroupParticipantResponse represents a patient on a group session roster.
+type GroupParticipantResponse struct {
+	ID               string `json:"id"`
+	PatientID        string `json:"patient_id"`
+	PatientName      string `json:"patient_name,omitempty"`
+	PatientMRN       string `json:"patient_mrn,omitempty"`
+	AppointmentID    string `json:"appointment_id,omitempty"`
+	Status           string `json:"status"`
+	WaitlistPosition *int   `json:"waitlist_position,omitempty"`
+	Notes            string `json:"notes,omitempty"`
+	UpdatedAt        string `json:"updated_at"`
+}
+
+// GroupSessionDetailResponse represents a group session with its roster.
+type GroupSessionDetailResponse struct {
+	GroupSessionResponse
+	Participants []GroupParticipantResponse `json:"participants"`
+}
+
+// List returns the clinic's group sessions.
+// @Summary List group sessions
+// @Description Returns the clinic's group sessions between two dates, by default the next two weeks
+// @Tags group-sessions
+// @Accept json
+// @Produce json
+// @Param from query string false "First day (YYYY-MM-DD)"
+// @Param to query string false "Last day (YYYY-MM-DD)"
+// @Param therapist_id query string false "Therapist ID"
+// @Param include_cancelled query bool false "Include cancelled sessions" default(false)
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/group-sessions [get]
+func (h *GroupSessionHandler) List(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	params := model.GroupSessionSearchParams{
+		ClinicID:         user.ClinicID,
+		TherapistID:      c.QueryParam("therapist_id"),
+		IncludeCancelled: c.QueryParam("include_cancelled") == "true",
+	}
+	if from := c.QueryParam("from"); from != "" {
+		date, err := time.Parse("2006-01-02", from)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_request",
+				Message: "Invalid from date format. Use YYYY-MM-DD",
+			})
+		}
+		params.From = date
+	}
+	if to := c.QueryParam("to"); to != "" {
+		date, err := time.Parse("2006-01-02", to)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_request",
+				Message: "Invalid to date format. Use YYYY-MM-DD",
+			})
+		}
+		params.To = date.AddDate(0, 0, 1)
+	}
+
+	sessions, err := h.svc.GroupSession().List(c.Request().Context(), params)
+	if err != nil {
+		return groupSessionError(c, err, "Failed to list group sessions")
+	}
+
+	data := make([]GroupSessionResponse, len(sessions))
+	for i := range sessions {
+		data[i] = toGroupSessionResponse(&sessions[i])
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": data,
+	})
+}
+
+// Get returns a group session with its roster.
+// @Summary Get group session
+// @Description Returns a group session with its booked participants and waitlist
+// @Tags group-sessions
+// @Accept json
+// @Produce json
+// @Param id path string true "Group session ID (UUID)"
+// @Success 200 {object} GroupSessionDetailResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/group-sessions/{id} [get]
+func (h *GroupSessionHandler) Get(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	session, err := h.svc.GroupSession().Get(c.Request().Context(), user.ClinicID, c.Param("id"))
+	if err != nil {
+		return groupSessionError(c, err, "Failed to retrieve group session")
+	}
+
+	return c.JSON(http.StatusOK, toGroupSessionDetailResponse(session))
+}
+
+// Create schedules a group session.
+// @Summary Create group session
+// @Description Schedules a group class; duration and capacity default to those of the appointment type, which must allow groups
+// @Tags group-sessions
+// @Accept json
+// @Produce json
+// @Param request body model.CreateGroupSessionRequest true "Group session"
+// @Success 201 {object} GroupSessionDetailResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/group-sessions [post]
+func (h *GroupSessionHandler) Create(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	var req model.CreateGroupSessionRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Failed to parse request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Request validation failed",
+			Details: validator.FormatErrors(err),
+		})
+	}
+
+	session, err := h.svc.GroupSession().Create(c.Request().Context(), user.ClinicID, user.UserID, &req)
+	if err != nil {
+		return groupSessionError(c, err, "Failed to create group session")
+	}
+
+	return c.JSON(http.StatusCreated, toGroupSessionDetailResponse(session))
+}
+
+// Update changes a group session.
+// @Summary Update group session
+// @Description Changes a group session; moving it moves its participants' appointments, and a higher capacity books patients from the waitlist
+// @Tags group-sessions
+// @Accept json
+// @Produce json
+// @Param id path string true "Group session ID (UUID)"
+// @Param request body model.UpdateGroupSessionRequest true "Changes"
+// @Success 200 {object} GroupSessionDetailResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/group-sessions/{id} [put]
+func (h *GroupSessionHandler) Update(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	var req model.UpdateGroupSessionRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Failed to parse request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Request validation failed",
+			Details: validator.FormatErrors(err),
+		})
+	}
+
+	session, err := h.svc.GroupSession().Update(c.Request().Context(), user.ClinicID, c.Param("id"), user.UserID, &req)
+	if err != nil {
+		return groupSessionError(c, err, "Failed to update group session")
+	}
+
+	return c.JSON(http.StatusOK, toGroupSessionDetailResponse(session))
+}
+
+// Cancel cancels a group session.
+// @Summary Cancel group session
+// @Description Cancels a group session, its participants' appointments and its waitlist
+// @Tags group-sessions
+// @Accept json
+// @Produce json
+// @Param id path string true "Group session ID (UUID)"
+// @Param request body model.CancelGroupSessionRequest true "Cancellation reason"
+// @Success 204 "No Content"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/group-sessions/{id}/cancel [post]
+func (h *GroupSessionHandler) Cancel(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	var req model.CancelGroupSessionRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Failed to parse request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Request validation failed",
+			Details: validator.FormatErrors(err),
+		})
+	}
+
+	if err := h.svc.GroupSession().Cancel(c.Request().Context(), user.ClinicID, c.Param("id"), user.UserID, &req); err != nil {
+		return groupSessionError(c, err, "Failed to cancel group session")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// AddParticipant adds a patient to a group session.
+// @Summary Add group session participant
+// @Description Books a patient into a group session with an appointment of their own; a full session puts them on the waitlist unless decline_waitlist is set
+// @Tags group-sessions
+// @Accept json
+// @Produce json
+// @Param id path string true "Group session ID (UUID)"
+// @Param request body model.AddParticipantRequest true "Participant"
+// @Success 201 {object} GroupParticipantResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/group-sessions/{id}/participants [post]
+func (h *GroupSessionHandler) AddParticipant(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	var req model.AddParticipantRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Failed to parse request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Request validation failed",
+			Details: validator.FormatErrors(err),
+		})
+	}
+
+	participant, err := h.svc.GroupSession().AddParticipant(c.Request().Context(), user.ClinicID, c.Param("id"), user.UserID, &req)
+	if err != nil {
+		return groupSessionError(c, err, "Failed to add participant")
+	}
+
+	return c.JSON(http.StatusCreated, toGroupParticipantResponse(participant))
+}
+
+// UpdateParticipant changes a participant of a group session.
+// @Summary Update group session participant
+// @Description Records a participant's attendance or no-show, books them from the waitlist, removes them, or changes their notes. A cancelled place goes to the first patient on the waitlist
+// @Tags group-sessions
+// @Accept json
+// @Produce json
+// @Param id path string true "Group session ID (UUID)"
+// @Param participantId path string true "Participant ID (UUID)"
+// @Param request body model.UpdateParticipantRequest true "Changes"
+// @Success 200 {object} GroupParticipantResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/group-sessions/{id}/participants/{participantId} [put]
+func (h *GroupSessionHandler) UpdateParticipant(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	var req model.UpdateParticipantRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Failed to parse request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Request validation failed",
+			Details: validator.FormatErrors(err),
+		})
+	}
+
+	participant, err := h.svc.GroupSession().UpdateParticipant(c.Request().Context(), user.ClinicID, c.Param("id"), c.Param("participantId"), user.UserID, &req)
+	if err != nil {
+		return groupSessionError(c, err, "Failed to update participant")
+	}
+
+	return c.JSON(http.StatusOK, toGroupParticipantResponse(participant))
+}
+
+// groupSessionError maps group session service errors to responses.
+func groupSessionError(c echo.Context, err error, failureMsg string) error {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "not_found",
+			Message: "Group session or participant not found",
+		})
+	case errors.Is(err, repository.ErrAlreadyExists):
+		return c.JSON(http.StatusConflict, ErrorResponse{
+			Error:   "conflict",
+			Message: "The patient is already on the roster",
+		})
+	case errors.Is(err, repository.ErrConflict):
+		return c.JSON(http.StatusConflict, ErrorResponse{
+			Error:   "conflict",
+			Message: err.Error(),
+		})
+	case errors.Is(err, repository.ErrInvalidInput):
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_input",
+			Message: err.Error(),
+		})
+	}
+
+	log.Error().Err(err).Str("path", c.Path()).Msg("group session request failed")
+	return c.JSON(http.StatusInternalServerError, ErrorResponse{
+		Error:   "internal_error",
+		Message: failureMsg,
+	})
+}
+
+// toGroupSessionResponse converts a GroupSession to GroupSessionResponse.
+func toGroupSessionResponse(s *model.GroupSession) GroupSessionResponse {
+	resp := GroupSessionResponse{
+		ID:                 s.ID,
+		TherapistID:        s.TherapistID,
+		TherapistName:      s.TherapistName,
+		Type:               string(s.Type),
+		Title:              s.Title,
+		StartTime:          s.StartTime.Format(time.RFC3339),
+		EndTime:            s.EndTime.Format(time.RFC3339),
+		Duration:           s.Duration,
+		Capacity:           s.Capacity,
+		BookedCount:        s.BookedCount,
+		WaitlistCount:      s.WaitlistCount,
+		SeatsLeft:          s.SeatsLeft(),
+		Room:               s.Room,
+		Status:             string(s.Status),
+		Notes:              s.Notes,
+		CancellationReason: s.CancellationReason,
+		CreatedAt:          s.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:          s.UpdatedAt.Format(time.RFC3339),
+	}
+
+	if s.AppointmentTypeID != nil {
+		resp.AppointmentTypeID = *s.AppointmentTypeID
+	}
+	if len(s.ResourceIDs) > 0 {
+		resp.ResourceIDs = s.ResourceIDs
+	}
+
+	return resp
+}
+
+// toGroupSessionDetailResponse converts a GroupSessionWithRoster to GroupSessionDetailResponse.
+func toGroupSessionDetailResponse(s *model.GroupSessionWithRoster) GroupSessionDetailResponse {
+	resp := GroupSessionDetailResponse{
+		GroupSessionResponse: toGroupSessionResponse(&s.GroupSession),
+		Participants:         make([]GroupParticipantResponse, len(s.Participants)),
+	}
+	for i := range s.Participants {
+		resp.Participants[i] = toGroupParticipantResponse(&s.Participants[i])
+	}
+	return resp
+}
+
+// toGroupParticipantResponse converts a GroupParticipant to GroupParticipantResponse.
+func toGroupParticipantResponse(p *model.GroupParticipant) GroupParticipantResponse {
+	resp := GroupParticipantResponse{
+		ID:               p.ID,
+		PatientID:        p.PatientID,
+		PatientName:      p.PatientName,
+		PatientMRN:       p.PatientMRN,
+		Status:           string(p.Status),
+		WaitlistPosition: p.WaitlistPosition,
+		Notes:            p.Notes,
+		UpdatedAt:        p.UpdatedAt.Format(time.RFC3339),
+	}
+
+	if p.AppointmentID != nil {
+		resp.AppointmentID = *p.AppointmentID
+	}
+
+	return resp
+}
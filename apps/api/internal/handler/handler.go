@@ -6,22 +6,50 @@ import (
 
 // Handler aggregates all HTTP handlers.
 type Handler struct {
-	Health       *HealthHandler
-	Patient      *PatientHandler
-	Checklist    *ChecklistHandler
-	QuickActions *QuickActionsHandler
-	Appointment  *AppointmentHandler
-	Exercise     *ExerciseHandler
+	Health          *HealthHandler
+	Patient         *PatientHandler
+	Checklist       *ChecklistHandler
+	QuickActions    *QuickActionsHandler
+	Appointment     *AppointmentHandler
+	Schedule        *ScheduleHandler
+	Resource        *ResourceHandler
+	GroupSession    *GroupSessionHandler
+	AppointmentType *AppointmentTypeHandler
+	Exercise        *ExerciseHandler
+	Portal          *PortalHandler
+	Proxy           *ProxyHandler
+	Consent         *ConsentHandler
+	Attachment      *AttachmentHandler
+	Export          *ExportHandler
+	Calendar        *CalendarHandler
+	StatusBoard     *StatusBoardHandler
+	Kiosk           *KioskHandler
+	Protocol        *ProtocolHandler
+	Adherence       *AdherenceHandler
 }
 
 // New creates a new Handler with all sub-handlers initialized.
 func New(svc *service.Service) *Handler {
 	return &Handler{
-		Health:       NewHealthHandler(svc),
-		Patient:      NewPatientHandler(svc),
-		Checklist:    NewChecklistHandler(svc),
-		QuickActions: NewQuickActionsHandler(svc),
-		Appointment:  NewAppointmentHandler(svc),
-		Exercise:     NewExerciseHandler(svc),
+		Health:          NewHealthHandler(svc),
+		Patient:         NewPatientHandler(svc),
+		Checklist:       NewChecklistHandler(svc),
+		QuickActions:    NewQuickActionsHandler(svc),
+		Appointment:     NewAppointmentHandler(svc),
+		Schedule:        NewScheduleHandler(svc),
+		Resource:        NewResourceHandler(svc),
+		GroupSession:    NewGroupSessionHandler(svc),
+		AppointmentType: NewAppointmentTypeHandler(svc),
+		Exercise:        NewExerciseHandler(svc),
+		Portal:          NewPortalHandler(svc),
+		Proxy:           NewProxyHandler(svc),
+		Consent:         NewConsentHandler(svc),
+		Attachment:      NewAttachmentHandler(svc),
+		Export:          NewExportHandler(svc),
+		Calendar:        NewCalendarHandler(svc),
+		StatusBoard:     NewStatusBoardHandler(svc),
+		Kiosk:           NewKioskHandler(svc),
+		Protocol:        NewProtocolHandler(svc),
+		Adherence:       NewAdherenceHandler(svc),
 	}
 }
@@ -0,0 +1,344 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/middleware"
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/repository"
+	"github.com/tqvdang/physioflow/apps/api/internal/service"
+	"github.com/tqvdang/physioflow/apps/api/pkg/validator"
+)
+
+// KioskHandler handles kiosk devices and patient self check-in.
+type KioskHandler struct {
+	svc *service.Service
+}
+
+// NewKioskHandler creates a new KioskHandler.
+func NewKioskHandler(svc *service.Service) *KioskHandler {
+	return &KioskHandler{svc: svc}
+}
+
+// KioskDeviceResponse represents a kiosk device in API responses.
+type KioskDeviceResponse struct {
+	ID         string  `json:"id"`
+	Name       string  `json:"name"`
+	Active     bool    `json:"active"`
+	Token      string  `json:"token,omitempty"` // only returned when the device is registered
+	LastSeenAt *string `json:"last_seen_at,omitempty"`
+	RevokedAt  *string `json:"revoked_at,omitempty"`
+	CreatedAt  string  `json:"created_at"`
+}
+
+// ListDevices returns the clinic's kiosk devices.
+// @Summary List kiosk devices
+// @Description Returns the clinic's self check-in kiosks, including revoked ones
+// @Tags kiosk
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/kiosks [get]
+func (h *KioskHandler) ListDevices(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	devices, err := h.svc.Kiosk().ListDevices(c.Request().Context(), user.ClinicID)
+	if err != nil {
+		return kioskError(c, err, "Clinic not found", "Failed to list kiosk devices")
+	}
+
+	data := make([]KioskDeviceResponse, len(devices))
+	for i, d := range devices {
+		data[i] = toKioskDeviceResponse(d)
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": data,
+	})
+}
+
+// RegisterDevice registers a kiosk device and returns its token.
+// @Summary Register kiosk device
+// @Description Registers a self check-in kiosk for the clinic. The device token is only returned once; the kiosk sends it as a bearer token to the /api/v1/kiosk endpoints, which accept nothing else.
+// @Tags kiosk
+// @Accept json
+// @Produce json
+// @Param request body model.RegisterKioskRequest true "Device"
+// @Success 201 {object} KioskDeviceResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/kiosks [post]
+func (h *KioskHandler) RegisterDevice(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	var req model.RegisterKioskRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Failed to parse request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Request validation failed",
+			Details: validator.FormatErrors(err),
+		})
+	}
+
+	device, err := h.svc.Kiosk().RegisterDevice(c.Request().Context(), user.ClinicID, user.UserID, &req)
+	if err != nil {
+		return kioskError(c, err, "Clinic not found", "Failed to register kiosk device")
+	}
+	return c.JSON(http.StatusCreated, toKioskDeviceResponse(*device))
+}
+
+// RevokeDevice revokes a kiosk device.
+// @Summary Revoke kiosk device
+// @Description Revokes a kiosk; its token stops working immediately
+// @Tags kiosk
+// @Param id path string true "Kiosk device ID"
+// @Success 204
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/kiosks/{id} [delete]
+func (h *KioskHandler) RevokeDevice(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	if err := h.svc.Kiosk().RevokeDevice(c.Request().Context(), user.ClinicID, c.Param("id"), user.UserID); err != nil {
+		return kioskError(c, err, "Active kiosk device not found", "Failed to revoke kiosk device")
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// CheckInToken returns the self check-in code of an appointment.
+// @Summary Get appointment check-in code
+// @Description Returns a signed code for checking in at a kiosk, to be shown as a QR code in reminders. It is accepted from two hours before the start until 30 minutes after it (or the end of a shorter appointment), and only by the clinic's kiosks.
+// @Tags kiosk
+// @Produce json
+// @Param id path string true "Appointment ID"
+// @Success 200 {object} model.CheckInToken
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/appointments/{id}/check-in-token [get]
+func (h *KioskHandler) CheckInToken(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	token, err := h.svc.Kiosk().IssueCheckInToken(c.Request().Context(), user.ClinicID, c.Param("id"))
+	if err != nil {
+		return kioskError(c, err, "Appointment not found", "Failed to issue check-in code")
+	}
+	c.Response().Header().Set("Cache-Control", "no-store")
+	return c.JSON(http.StatusOK, token)
+}
+
+// PortalCheckInToken returns the self check-in code of one of the current
+// patient's appointments.
+// @Summary Get my appointment check-in code
+// @Description Returns a signed code the patient can show as a QR code at a clinic kiosk to check in
+// @Tags portal
+// @Produce json
+// @Param id path string true "Appointment ID"
+// @Success 200 {object} model.CheckInToken
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/me/appointments/{id}/check-in-token [get]
+func (h *KioskHandler) PortalCheckInToken(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	token, err := h.svc.Portal().CheckInToken(c.Request().Context(), portalActor(c, user), c.Param("id"))
+	if err != nil {
+		return portalError(c, err, "Appointment not found", "Failed to issue check-in code")
+	}
+	c.Response().Header().Set("Cache-Control", "no-store")
+	return c.JSON(http.StatusOK, token)
+}
+
+// Lookup returns what a patient reviews before checking in.
+// @Summary Look up a check-in code
+// @Description Validates a scanned check-in code and returns the appointment, the patient's contact details and their insurance (with masked policy numbers and validity on the appointment day) for the patient to review. Requires a kiosk device token.
+// @Tags kiosk
+// @Accept json
+// @Produce json
+// @Param request body model.KioskLookupRequest true "Scanned code"
+// @Success 200 {object} model.KioskCheckIn
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Security KioskAuth
+// @Router /api/v1/kiosk/check-in/lookup [post]
+func (h *KioskHandler) Lookup(c echo.Context) error {
+	device := middleware.GetKiosk(c)
+	if device == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "Kiosk not authenticated",
+		})
+	}
+
+	var req model.KioskLookupRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Failed to parse request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Request validation failed",
+			Details: validator.FormatErrors(err),
+		})
+	}
+
+	review, err := h.svc.Kiosk().Lookup(c.Request().Context(), device, req.Token)
+	if err != nil {
+		return kioskError(c, err, "Appointment not found", "Failed to look up check-in code")
+	}
+	c.Response().Header().Set("Cache-Control", "no-store")
+	return c.JSON(http.StatusOK, review)
+}
+
+// CheckIn checks a patient in at a kiosk.
+// @Summary Check in at a kiosk
+// @Description Checks the patient in with their confirmation of contact details and insurance. Unconfirmed details and notes are flagged for the front desk on the status board. Requires a kiosk device token.
+// @Tags kiosk
+// @Accept json
+// @Produce json
+// @Param request body model.KioskCheckInRequest true "Check-in"
+// @Success 200 {object} model.KioskCheckIn
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Security KioskAuth
+// @Router /api/v1/kiosk/check-in [post]
+func (h *KioskHandler) CheckIn(c echo.Context) error {
+	device := middleware.GetKiosk(c)
+	if device == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "Kiosk not authenticated",
+		})
+	}
+
+	var req model.KioskCheckInRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Failed to parse request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Request validation failed",
+			Details: validator.FormatErrors(err),
+		})
+	}
+
+	review, err := h.svc.Kiosk().CheckIn(c.Request().Context(), device, &req)
+	if err != nil {
+		return kioskError(c, err, "Appointment not found", "Failed to check in")
+	}
+	c.Response().Header().Set("Cache-Control", "no-store")
+	return c.JSON(http.StatusOK, review)
+}
+
+// kioskError maps kiosk service errors to responses.
+func kioskError(c echo.Context, err error, notFoundMsg, failureMsg string) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidCheckInToken):
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_token",
+			Message: "This check-in code is not valid or has expired, please see the front desk",
+		})
+	case errors.Is(err, repository.ErrNotFound):
+		return c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "not_found",
+			Message: notFoundMsg,
+		})
+	case errors.Is(err, repository.ErrInvalidInput):
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+	}
+
+	log.Error().Err(err).Str("path", c.Path()).Msg(strings.ToLower(failureMsg))
+	return c.JSON(http.StatusInternalServerError, ErrorResponse{
+		Error:   "internal_error",
+		Message: failureMsg,
+	})
+}
+
+// toKioskDeviceResponse converts a KioskDevice to KioskDeviceResponse.
+func toKioskDeviceResponse(d model.KioskDevice) KioskDeviceResponse {
+	resp := KioskDeviceResponse{
+		ID:        d.ID,
+		Name:      d.Name,
+		Active:    d.IsActive(),
+		Token:     d.Token,
+		CreatedAt: d.CreatedAt.Format(time.RFC3339),
+	}
+	if d.LastSeenAt != nil {
+		s := d.LastSeenAt.Format(time.RFC3339)
+		resp.LastSeenAt = &s
+	}
+	if d.RevokedAt != nil {
+		s := d.RevokedAt.Format(time.RFC3339)
+		resp.RevokedAt = &s
+	}
+	return resp
+}
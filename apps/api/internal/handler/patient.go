@@ -4,6 +4,7 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/labstack/echo/v4"
@@ -64,14 +65,48 @@ type PatientListResponse struct {
 
 // PatientDashboardResponse represents aggregated patient dashboard data.
 type PatientDashboardResponse struct {
-	Patient              PatientResponse          `json:"patient"`
-	TotalAppointments    int                      `json:"total_appointments"`
-	UpcomingAppointments int                      `json:"upcoming_appointments"`
-	CompletedSessions    int                      `json:"completed_sessions"`
-	ActiveTreatmentPlans int                      `json:"active_treatment_plans"`
-	LastVisit            *string                  `json:"last_visit,omitempty"`
-	NextAppointment      *string                  `json:"next_appointment,omitempty"`
-	InsuranceInfo        []model.PatientInsurance `json:"insurance_info,omitempty"`
+	Patient              PatientResponse            `json:"patient"`
+	TotalAppointments    int                        `json:"total_appointments"`
+	UpcomingAppointments int                        `json:"upcoming_appointments"`
+	CompletedSessions    int                        `json:"completed_sessions"`
+	ActiveTreatmentPlans int                        `json:"active_treatment_plans"`
+	LastVisit            *string                    `json:"last_visit,omitempty"`
+	NextAppointment      *string                    `json:"next_appointment,omitempty"`
+	InsuranceInfo        []model.PatientInsurance   `json:"insurance_info,omitempty"`
+	RecentNotes          []model.PatientNote        `json:"recent_notes,omitempty"`
+	Attendance           *PatientAttendanceResponse `json:"attendance,omitempty"`
+}
+
+// PatientAttendanceResponse represents a patient's no-show counters and
+// standing under the clinic's no-show policy.
+type PatientAttendanceResponse struct {
+	NoShowCount     int     `json:"no_show_count"`
+	LateCancelCount int     `json:"late_cancel_count"`
+	LastNoShowAt    *string `json:"last_no_show_at,omitempty"`
+	Standing        string  `json:"standing"`
+	Since           *string `json:"since,omitempty"`
+}
+
+// TimelineEventResponse represents a single patient timeline entry in API responses.
+type TimelineEventResponse struct {
+	ID          string                 `json:"id"`
+	Type        string                 `json:"type"`
+	ReferenceID string                 `json:"reference_id"`
+	OccurredAt  string                 `json:"occurred_at"`
+	ActorID     *string                `json:"actor_id,omitempty"`
+	ActorName   string                 `json:"actor_name,omitempty"`
+	Title       string                 `json:"title"`
+	TitleVi     string                 `json:"title_vi"`
+	Summary     string                 `json:"summary,omitempty"`
+	SummaryVi   string                 `json:"summary_vi,omitempty"`
+	Details     map[string]interface{} `json:"details,omitempty"`
+}
+
+// TimelineResponse represents a cursor-paginated patient timeline.
+type TimelineResponse struct {
+	Data       []TimelineEventResponse `json:"data"`
+	NextCursor string                  `json:"next_cursor,omitempty"`
+	HasMore    bool                    `json:"has_more"`
 }
 
 // DuplicateCheckResponse represents potential duplicate patients.
@@ -508,6 +543,7 @@ func (h *PatientHandler) Dashboard(c echo.Context) error {
 		CompletedSessions:    dashboard.CompletedSessions,
 		ActiveTreatmentPlans: dashboard.ActiveTreatmentPlans,
 		InsuranceInfo:        dashboard.InsuranceInfo,
+		RecentNotes:          dashboard.RecentNotes,
 	}
 
 	if dashboard.LastVisit != nil {
@@ -518,10 +554,131 @@ func (h *PatientHandler) Dashboard(c echo.Context) error {
 		formatted := dashboard.NextAppointment.Format(time.RFC3339)
 		response.NextAppointment = &formatted
 	}
+	if dashboard.Attendance != nil {
+		response.Attendance = toPatientAttendanceResponse(dashboard.Attendance)
+	}
 
 	return c.JSON(http.StatusOK, response)
 }
 
+// Timeline retrieves the clinical timeline for a patient.
+// @Summary Get patient timeline
+// @Description Returns appointments, visit notes, pain/ROM records, prescriptions, compliance logs and status changes as one feed, newest first
+// @Tags patients
+// @Accept json
+// @Produce json
+// @Param id path string true "Patient ID (UUID)"
+// @Param types query string false "Comma-separated event types" Enums(appointment, visit_checklist, pain_record, rom_record, prescription, compliance_log, status_change)
+// @Param from query string false "Only events at or after this time (RFC3339)"
+// @Param to query string false "Only events before this time (RFC3339)"
+// @Param cursor query string false "Cursor returned by the previous page"
+// @Param per_page query int false "Items per page" default(20) maximum(100)
+// @Success 200 {object} TimelineResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/patients/{id}/timeline [get]
+func (h *PatientHandler) Timeline(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	id := c.Param("id")
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Patient ID is required",
+		})
+	}
+
+	params := model.NewTimelineParams()
+	params.ClinicID = user.ClinicID
+	params.PatientID = id
+
+	if perPage := c.QueryParam("per_page"); perPage != "" {
+		if pp, err := strconv.Atoi(perPage); err == nil && pp > 0 {
+			params.PerPage = pp
+		}
+	}
+
+	if types := c.QueryParam("types"); types != "" {
+		for _, t := range strings.Split(types, ",") {
+			eventType := model.TimelineEventType(strings.TrimSpace(t))
+			if !eventType.IsValid() {
+				return c.JSON(http.StatusBadRequest, ErrorResponse{
+					Error:   "invalid_request",
+					Message: "Unknown timeline event type: " + string(eventType),
+				})
+			}
+			params.Types = append(params.Types, eventType)
+		}
+	}
+
+	if from := c.QueryParam("from"); from != "" {
+		t, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_request",
+				Message: "Invalid from time format, expected RFC3339",
+			})
+		}
+		params.From = &t
+	}
+
+	if to := c.QueryParam("to"); to != "" {
+		t, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_request",
+				Message: "Invalid to time format, expected RFC3339",
+			})
+		}
+		params.To = &t
+	}
+
+	if cursor := c.QueryParam("cursor"); cursor != "" {
+		decoded, err := model.DecodeTimelineCursor(cursor)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_request",
+				Message: "Invalid cursor",
+			})
+		}
+		params.Cursor = decoded
+	}
+
+	page, err := h.svc.Timeline().GetPatientTimeline(c.Request().Context(), params)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "not_found",
+				Message: "Patient not found",
+			})
+		}
+		log.Error().Err(err).Str("patient_id", id).Msg("failed to get patient timeline")
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to retrieve patient timeline",
+		})
+	}
+
+	data := make([]TimelineEventResponse, len(page.Data))
+	for i, ev := range page.Data {
+		data[i] = toTimelineEventResponse(ev)
+	}
+
+	return c.JSON(http.StatusOK, TimelineResponse{
+		Data:       data,
+		NextCursor: page.NextCursor,
+		HasMore:    page.HasMore,
+	})
+}
+
 // CheckDuplicates checks for potential duplicate patients.
 // @Summary Check for duplicate patients
 // @Description Checks if a patient with similar information already exists
@@ -618,3 +775,38 @@ func toPatientResponse(p model.Patient) PatientResponse {
 
 	return resp
 }
+
+// toTimelineEventResponse converts a TimelineEvent model to TimelineEventResponse.
+func toTimelineEventResponse(ev model.TimelineEvent) TimelineEventResponse {
+	return TimelineEventResponse{
+		ID:          ev.ID,
+		Type:        string(ev.Type),
+		ReferenceID: ev.ReferenceID,
+		OccurredAt:  ev.OccurredAt.Format(time.RFC3339),
+		ActorID:     ev.ActorID,
+		ActorName:   ev.ActorName,
+		Title:       ev.Title,
+		TitleVi:     ev.TitleVi,
+		Summary:     ev.Summary,
+		SummaryVi:   ev.SummaryVi,
+		Details:     ev.Details,
+	}
+}
+
+// toPatientAttendanceResponse converts a PatientAttendance to PatientAttendanceResponse.
+func toPatientAttendanceResponse(a *model.PatientAttendance) *PatientAttendanceResponse {
+	resp := &PatientAttendanceResponse{
+		NoShowCount:     a.NoShowCount,
+		LateCancelCount: a.LateCancelCount,
+		Standing:        string(a.Standing),
+	}
+	if a.LastNoShowAt != nil {
+		formatted := a.LastNoShowAt.Format(time.RFC3339)
+		resp.LastNoShowAt = &formatted
+	}
+	if a.Since != nil {
+		formatted := a.Since.Format(time.RFC3339)
+		resp.Since = &formatted
+	}
+	return resp
+}
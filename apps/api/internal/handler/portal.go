@@ -0,0 +1,890 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/middleware"
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/repository"
+	"github.com/tqvdang/physioflow/apps/api/internal/service"
+	"github.com/tqvdang/physioflow/apps/api/pkg/validator"
+)
+
+// PortalHandler handles patient self-service HTTP requests under /me.
+type PortalHandler struct {
+	svc *service.Service
+}
+
+// NewPortalHandler creates a new PortalHandler.
+func NewPortalHandler(svc *service.Service) *PortalHandler {
+	return &PortalHandler{svc: svc}
+}
+
+// PortalAppointmentResponse represents an appointment as shown to the patient.
+// Staff notes and patient identifiers are omitted.
+type PortalAppointmentResponse struct {
+	ID                 string `json:"id"`
+	TherapistName      string `json:"therapist_name,omitempty"`
+	StartTime          string `json:"start_time"`
+	EndTime            string `json:"end_time"`
+	Duration           int    `json:"duration"`
+	Type               string `json:"type"`
+	Status             string `json:"status"`
+	Room               string `json:"room,omitempty"`
+	CancellationReason string `json:"cancellation_reason,omitempty"`
+}
+
+// PortalExercisePlanResponse represents the patient's active exercise plan.
+type PortalExercisePlanResponse struct {
+	Programs      []ProgramResponse      `json:"programs"`
+	Prescriptions []PrescriptionResponse `json:"prescriptions"`
+}
+
+// GetProfile returns the patient record linked to the current user.
+// @Summary Get my profile
+// @Description Returns the patient record linked to the authenticated patient account
+// @Tags portal
+// @Accept json
+// @Produce json
+// @Success 200 {object} PatientResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/me [get]
+func (h *PortalHandler) GetProfile(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	patient, err := h.svc.Portal().GetPatient(c.Request().Context(), portalActor(c, user))
+	if err != nil {
+		return portalError(c, err, "Patient not found", "Failed to get profile")
+	}
+
+	return c.JSON(http.StatusOK, toPatientResponse(*patient))
+}
+
+// ListAppointments returns the current patient's appointments.
+// @Summary List my appointments
+// @Description Returns the authenticated patient's appointments, most recent first
+// @Tags portal
+// @Accept json
+// @Produce json
+// @Param upcoming query bool false "Only return upcoming scheduled or confirmed appointments"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/me/appointments [get]
+func (h *PortalHandler) ListAppointments(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	upcoming, _ := strconv.ParseBool(c.QueryParam("upcoming"))
+
+	appointments, err := h.svc.Portal().ListAppointments(c.Request().Context(), portalActor(c, user), upcoming)
+	if err != nil {
+		return portalError(c, err, "Patient not found", "Failed to list appointments")
+	}
+
+	data := make([]PortalAppointmentResponse, len(appointments))
+	for i, a := range appointments {
+		data[i] = toPortalAppointmentResponse(a)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": data,
+	})
+}
+
+// CancelAppointment cancels one of the current patient's appointments.
+// @Summary Cancel my appointment
+// @Description Cancels an appointment if the clinic's cancellation notice window allows it
+// @Tags portal
+// @Accept json
+// @Produce json
+// @Param id path string true "Appointment ID"
+// @Param request body model.PortalCancelRequest false "Cancellation reason"
+// @Success 204 "No Content"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/me/appointments/{id}/cancel [post]
+func (h *PortalHandler) CancelAppointment(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	id := c.Param("id")
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Appointment ID is required",
+		})
+	}
+
+	var req model.PortalCancelRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Failed to parse request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Request validation failed",
+			Details: validator.FormatErrors(err),
+		})
+	}
+
+	if err := h.svc.Portal().CancelAppointment(c.Request().Context(), portalActor(c, user), id, &req); err != nil {
+		return portalError(c, err, "Appointment not found", "Failed to cancel appointment")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// RescheduleAppointment moves one of the current patient's appointments.
+// @Summary Reschedule my appointment
+// @Description Moves an appointment to a new start time within the clinic's rescheduling policy
+// @Tags portal
+// @Accept json
+// @Produce json
+// @Param id path string true "Appointment ID"
+// @Param request body model.PortalRescheduleRequest true "New start time (RFC3339)"
+// @Success 200 {object} PortalAppointmentResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse "Scheduling conflict"
+// @Failure 422 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/me/appointments/{id}/reschedule [post]
+func (h *PortalHandler) RescheduleAppointment(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	id := c.Param("id")
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Appointment ID is required",
+		})
+	}
+
+	var req model.PortalRescheduleRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Failed to parse request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Request validation failed",
+			Details: validator.FormatErrors(err),
+		})
+	}
+
+	appointment, err := h.svc.Portal().RescheduleAppointment(c.Request().Context(), portalActor(c, user), id, &req)
+	if err != nil {
+		return portalError(c, err, "Appointment not found", "Failed to reschedule appointment")
+	}
+
+	return c.JSON(http.StatusOK, toPortalAppointmentResponse(*appointment))
+}
+
+// GetExercisePlan returns the current patient's active exercise plan.
+// @Summary Get my exercise plan
+// @Description Returns the authenticated patient's active home exercise programs and prescriptions
+// @Tags portal
+// @Accept json
+// @Produce json
+// @Success 200 {object} PortalExercisePlanResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/me/exercises [get]
+func (h *PortalHandler) GetExercisePlan(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	plan, err := h.svc.Portal().GetExercisePlan(c.Request().Context(), portalActor(c, user))
+	if err != nil {
+		return portalError(c, err, "Patient not found", "Failed to get exercise plan")
+	}
+
+	response := PortalExercisePlanResponse{
+		Programs:      make([]ProgramResponse, len(plan.Programs)),
+		Prescriptions: make([]PrescriptionResponse, len(plan.Prescriptions)),
+	}
+	for i, p := range plan.Programs {
+		response.Programs[i] = toProgramResponse(p)
+	}
+	for i, p := range plan.Prescriptions {
+		response.Prescriptions[i] = toPrescriptionResponse(p)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// LogCompliance records a completed exercise session for the current patient.
+// @Summary Log my exercise session
+// @Description Records completion of one of the authenticated patient's active prescriptions
+// @Tags portal
+// @Accept json
+// @Produce json
+// @Param id path string true "Prescription ID"
+// @Param log body model.LogComplianceRequest true "Compliance data"
+// @Success 201 {object} ComplianceLogResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/me/exercises/{id}/log [post]
+func (h *PortalHandler) LogCompliance(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	prescriptionID := c.Param("id")
+	if prescriptionID == "" {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Prescription ID is required",
+		})
+	}
+
+	var req model.LogComplianceRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Failed to parse request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Request validation failed",
+			Details: validator.FormatErrors(err),
+		})
+	}
+
+	complianceLog, err := h.svc.Portal().LogCompliance(c.Request().Context(), portalActor(c, user), prescriptionID, &req)
+	if err != nil {
+		return portalError(c, err, "Prescription not found", "Failed to log exercise completion")
+	}
+
+	return c.JSON(http.StatusCreated, toComplianceLogResponse(*complianceLog))
+}
+
+// GetProgress returns the current patient's pain and ROM history.
+// @Summary Get my progress
+// @Description Returns the authenticated patient's recent pain and ROM measurements
+// @Tags portal
+// @Accept json
+// @Produce json
+// @Param joint query string false "Filter ROM by joint"
+// @Param limit query int false "Number of records per measure" default(20) maximum(100)
+// @Success 200 {object} model.PortalProgress
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/me/progress [get]
+func (h *PortalHandler) GetProgress(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	limit := 20
+	if l, err := strconv.Atoi(c.QueryParam("limit")); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+
+	progress, err := h.svc.Portal().GetProgress(c.Request().Context(), portalActor(c, user), c.QueryParam("joint"), limit)
+	if err != nil {
+		return portalError(c, err, "Patient not found", "Failed to get progress")
+	}
+
+	return c.JSON(http.StatusOK, progress)
+}
+
+// ListProxyGrants returns the caregiver grants the current patient has issued.
+// @Summary List my caregivers
+// @Description Returns the proxy grants the authenticated patient has issued, including expired and revoked ones. Not available to caregivers acting as a proxy.
+// @Tags portal
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/me/proxies [get]
+func (h *PortalHandler) ListProxyGrants(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	grants, err := h.svc.Portal().ListProxyGrants(c.Request().Context(), portalActor(c, user))
+	if err != nil {
+		return portalError(c, err, "Patient not found", "Failed to list proxy access")
+	}
+
+	data := make([]ProxyGrantResponse, len(grants))
+	for i, g := range grants {
+		data[i] = toProxyGrantResponse(g)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": data,
+	})
+}
+
+// GrantProxyAccess authorizes a caregiver to act for the current patient.
+// @Summary Grant caregiver access
+// @Description Gives a caregiver account scoped, time-limited access to the authenticated patient's portal
+// @Tags portal
+// @Accept json
+// @Produce json
+// @Param request body model.CreateProxyGrantRequest true "Grant details"
+// @Success 201 {object} ProxyGrantResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/me/proxies [post]
+func (h *PortalHandler) GrantProxyAccess(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	var req model.CreateProxyGrantRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Failed to parse request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Request validation failed",
+			Details: validator.FormatErrors(err),
+		})
+	}
+
+	grant, err := h.svc.Portal().GrantProxyAccess(c.Request().Context(), portalActor(c, user), &req)
+	if err != nil {
+		return portalError(c, err, "Patient not found", "Failed to grant proxy access")
+	}
+
+	return c.JSON(http.StatusCreated, toProxyGrantResponse(*grant))
+}
+
+// RevokeProxyAccess withdraws a caregiver's access to the current patient.
+// @Summary Revoke caregiver access
+// @Description Ends a proxy grant issued by the authenticated patient
+// @Tags portal
+// @Accept json
+// @Produce json
+// @Param id path string true "Grant ID"
+// @Success 204 "No Content"
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/me/proxies/{id} [delete]
+func (h *PortalHandler) RevokeProxyAccess(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	id := c.Param("id")
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Grant ID is required",
+		})
+	}
+
+	if err := h.svc.Portal().RevokeProxyAccess(c.Request().Context(), portalActor(c, user), id); err != nil {
+		return portalError(c, err, "Proxy grant not found", "Failed to revoke proxy access")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ListCaregiverPatients returns the patients the current user may act for.
+// @Summary List patients I care for
+// @Description Returns the active proxy grants held by the authenticated caregiver. Use a grant's patient_id in the X-Proxy-Patient-ID header to act for that patient.
+// @Tags portal
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/me/caring-for [get]
+func (h *PortalHandler) ListCaregiverPatients(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	grants, err := h.svc.Proxy().ListGrantsForProxy(c.Request().Context(), user.UserID)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", user.UserID).Msg("failed to list caregiver grants")
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to list proxy access",
+		})
+	}
+
+	data := make([]ProxyGrantResponse, len(grants))
+	for i, g := range grants {
+		data[i] = toProxyGrantResponse(g)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": data,
+	})
+}
+
+// ListConsentTemplates returns the consent texts the current patient may sign.
+// @Summary List consent texts
+// @Description Returns the current bilingual consent text for each consent type. Not available to caregivers acting as a proxy.
+// @Tags portal
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/me/consent-templates [get]
+func (h *PortalHandler) ListConsentTemplates(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	templates, err := h.svc.Portal().ListConsentTemplates(c.Request().Context(), portalActor(c, user))
+	if err != nil {
+		return portalError(c, err, "Patient not found", "Failed to list consent templates")
+	}
+
+	data := make([]ConsentTemplateResponse, len(templates))
+	for i, t := range templates {
+		data[i] = toConsentTemplateResponse(t)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": data,
+	})
+}
+
+// ListConsents returns the current patient's consent standing.
+// @Summary List my consents
+// @Description Returns whether the authenticated patient has an active consent of each type. Not available to caregivers acting as a proxy.
+// @Tags portal
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/me/consents [get]
+func (h *PortalHandler) ListConsents(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	summary, err := h.svc.Portal().GetConsentSummary(c.Request().Context(), portalActor(c, user))
+	if err != nil {
+		return portalError(c, err, "Patient not found", "Failed to get consents")
+	}
+
+	data := make([]ConsentSummaryResponse, len(summary))
+	for i, s := range summary {
+		data[i] = toConsentSummaryResponse(s)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": data,
+	})
+}
+
+// RecordConsent signs a consent as the current patient.
+// @Summary Give consent
+// @Description Signs the current text of a consent type as the authenticated patient
+// @Tags portal
+// @Accept json
+// @Produce json
+// @Param request body model.RecordConsentRequest true "Consent and signature"
+// @Success 201 {object} PatientConsentResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/me/consents [post]
+func (h *PortalHandler) RecordConsent(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	var req model.RecordConsentRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Failed to parse request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Request validation failed",
+			Details: validator.FormatErrors(err),
+		})
+	}
+
+	consent, err := h.svc.Portal().RecordConsent(c.Request().Context(), portalActor(c, user), &req)
+	if err != nil {
+		return portalError(c, err, "Patient not found", "Failed to record consent")
+	}
+
+	return c.JSON(http.StatusCreated, toPatientConsentResponse(*consent))
+}
+
+// WithdrawConsent withdraws one of the current patient's consents.
+// @Summary Withdraw consent
+// @Description Withdraws an active consent given by the authenticated patient
+// @Tags portal
+// @Accept json
+// @Produce json
+// @Param id path string true "Consent ID"
+// @Param request body model.WithdrawConsentRequest false "Withdrawal reason"
+// @Success 204 "No Content"
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/me/consents/{id}/withdraw [post]
+func (h *PortalHandler) WithdrawConsent(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	id := c.Param("id")
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Consent ID is required",
+		})
+	}
+
+	var req model.WithdrawConsentRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Failed to parse request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Request validation failed",
+			Details: validator.FormatErrors(err),
+		})
+	}
+
+	if err := h.svc.Portal().WithdrawConsent(c.Request().Context(), portalActor(c, user), id, &req); err != nil {
+		return portalError(c, err, "Active consent not found", "Failed to withdraw consent")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// RequestExport queues an export of the current patient's full record.
+// @Summary Export my record
+// @Description Queues a zip export of the authenticated patient's full record. Poll the job until download_url is set.
+// @Tags portal
+// @Accept json
+// @Produce json
+// @Success 202 {object} ExportResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/me/exports [post]
+func (h *PortalHandler) RequestExport(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	job, err := h.svc.Portal().RequestExport(c.Request().Context(), portalActor(c, user))
+	if err != nil {
+		return portalError(c, err, "Patient not found", "Failed to request export")
+	}
+
+	return c.JSON(http.StatusAccepted, toExportResponse(*job, portalExportDownloadURL))
+}
+
+// ListExports returns the current patient's export jobs.
+// @Summary List my exports
+// @Description Returns the export jobs of the authenticated patient
+// @Tags portal
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/me/exports [get]
+func (h *PortalHandler) ListExports(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	jobs, err := h.svc.Portal().ListExports(c.Request().Context(), portalActor(c, user))
+	if err != nil {
+		return portalError(c, err, "Patient not found", "Failed to list exports")
+	}
+
+	data := make([]ExportResponse, len(jobs))
+	for i, j := range jobs {
+		data[i] = toExportResponse(j, portalExportDownloadURL)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": data,
+	})
+}
+
+// GetExport returns one of the current patient's export jobs.
+// @Summary Get my export
+// @Description Returns an export job of the authenticated patient
+// @Tags portal
+// @Accept json
+// @Produce json
+// @Param id path string true "Export ID"
+// @Success 200 {object} ExportResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/me/exports/{id} [get]
+func (h *PortalHandler) GetExport(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	job, err := h.svc.Portal().GetExport(c.Request().Context(), portalActor(c, user), c.Param("id"))
+	if err != nil {
+		return portalError(c, err, "Export not found", "Failed to get export")
+	}
+
+	return c.JSON(http.StatusOK, toExportResponse(*job, portalExportDownloadURL))
+}
+
+// DownloadExport streams one of the current patient's finished export bundles.
+// @Summary Download my export
+// @Description Downloads the zip bundle of a completed export
+// @Tags portal
+// @Produce application/zip
+// @Param id path string true "Export ID"
+// @Success 200 {file} binary
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 410 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/me/exports/{id}/download [get]
+func (h *PortalHandler) DownloadExport(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	content, job, err := h.svc.Portal().OpenExport(c.Request().Context(), portalActor(c, user), c.Param("id"))
+	if err != nil {
+		return portalError(c, err, "Export not found", "Failed to download export")
+	}
+	defer content.Close()
+
+	return streamExport(c, content, job)
+}
+
+// portalActor builds the portal actor for the request, including the proxy
+// grant when a caregiver is acting for a patient.
+func portalActor(c echo.Context, user *middleware.AuthClaims) model.PortalActor {
+	return model.PortalActor{
+		UserID: user.UserID,
+		Grant:  middleware.GetProxyGrant(c),
+	}
+}
+
+// portalError maps portal service errors to HTTP responses.
+func portalError(c echo.Context, err error, notFoundMsg, failureMsg string) error {
+	switch {
+	case errors.Is(err, service.ErrPatientNotLinked):
+		return c.JSON(http.StatusForbidden, ErrorResponse{
+			Error:   "forbidden",
+			Message: "No patient record is linked to this account",
+		})
+	case errors.Is(err, repository.ErrNotFound):
+		return c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "not_found",
+			Message: notFoundMsg,
+		})
+	case errors.Is(err, service.ErrPolicyViolation):
+		return c.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Error:   "policy_violation",
+			Message: err.Error(),
+		})
+	case errors.Is(err, service.ErrConsentRequired):
+		return c.JSON(http.StatusForbidden, ErrorResponse{
+			Error:   "consent_required",
+			Message: err.Error(),
+		})
+	case errors.Is(err, repository.ErrInvalidInput):
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+	case errors.Is(err, service.ErrExportNotReady):
+		return c.JSON(http.StatusConflict, ErrorResponse{
+			Error:   "not_ready",
+			Message: "Export is still being prepared",
+		})
+	case errors.Is(err, service.ErrExportExpired):
+		return c.JSON(http.StatusGone, ErrorResponse{
+			Error:   "expired",
+			Message: "Export download link has expired, please request a new export",
+		})
+	case strings.HasPrefix(err.Error(), "scheduling conflict"):
+		return c.JSON(http.StatusConflict, ErrorResponse{
+			Error:   "conflict",
+			Message: "The requested time is no longer available",
+		})
+	}
+
+	log.Error().Err(err).Str("path", c.Path()).Msg(strings.ToLower(failureMsg))
+	return c.JSON(http.StatusInternalServerError, ErrorResponse{
+		Error:   "internal_error",
+		Message: failureMsg,
+	})
+}
+
+// toPortalAppointmentResponse converts an AppointmentWithDetails to PortalAppointmentResponse.
+func toPortalAppointmentResponse(a model.AppointmentWithDetails) PortalAppointmentResponse {
+	return PortalAppointmentResponse{
+		ID:                 a.ID,
+		TherapistName:      a.TherapistName,
+		StartTime:          a.StartTime.Format(time.RFC3339),
+		EndTime:            a.EndTime.Format(time.RFC3339),
+		Duration:           a.Duration,
+		Type:               string(a.Type),
+		Status:             string(a.Status),
+		Room:               a.Room,
+		CancellationReason: a.CancellationReason,
+	}
+}
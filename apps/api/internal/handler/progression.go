@@ -0,0 +1,363 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/middleware"
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/repository"
+	"github.com/tqvdang/physioflow/apps/api/pkg/validator"
+)
+
+// ListProgressionRules returns a prescription's progression rules.
+// @Summary List progression rules
+// @Description Returns the rules that progress the prescription's sets, reps or hold time based on the patient's compliance logs
+// @Tags exercises
+// @Produce json
+// @Param pid path string true "Patient ID"
+// @Param id path string true "Prescription ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/patients/{pid}/exercises/{id}/progression-rules [get]
+func (h *ExerciseHandler) ListProgressionRules(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	rules, err := h.svc.Progression().ListRules(c.Request().Context(), user.ClinicID, c.Param("pid"), c.Param("id"))
+	if err != nil {
+		return progressionError(c, err, "Prescription not found", "Failed to list progression rules")
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": rules,
+	})
+}
+
+// CreateProgressionRule adds a progression rule to a prescription.
+// @Summary Create progression rule
+// @Description Adds a rule such as "+2 reps every 7 days while pain is at most 3 and difficulty is easy". Each window of interval_days is judged on the compliance logs completed in it: every log that rates pain or difficulty must be within the limits, and at least min_sessions logs must be rated within them. Progressions wait for a therapist's approval unless the clinic applies them automatically.
+// @Tags exercises
+// @Accept json
+// @Produce json
+// @Param pid path string true "Patient ID"
+// @Param id path string true "Prescription ID"
+// @Param rule body model.CreateProgressionRuleRequest true "Rule"
+// @Success 201 {object} model.ProgressionRule
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/patients/{pid}/exercises/{id}/progression-rules [post]
+func (h *ExerciseHandler) CreateProgressionRule(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	var req model.CreateProgressionRuleRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Failed to parse request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Request validation failed",
+			Details: validator.FormatErrors(err),
+		})
+	}
+
+	rule, err := h.svc.Progression().CreateRule(c.Request().Context(), user.ClinicID, c.Param("pid"), c.Param("id"), user.UserID, &req)
+	if err != nil {
+		return progressionError(c, err, "Prescription not found", "Failed to create progression rule")
+	}
+	return c.JSON(http.StatusCreated, rule)
+}
+
+// UpdateProgressionRule changes a progression rule.
+// @Summary Update progression rule
+// @Description Changes a rule's step, ceiling, interval or conditions, or pauses it. Changes apply from the next evaluation. A resumed rule starts a new window.
+// @Tags exercises
+// @Accept json
+// @Produce json
+// @Param pid path string true "Patient ID"
+// @Param id path string true "Prescription ID"
+// @Param ruleId path string true "Rule ID"
+// @Param rule body model.UpdateProgressionRuleRequest true "Changes"
+// @Success 200 {object} model.ProgressionRule
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/patients/{pid}/exercises/{id}/progression-rules/{ruleId} [put]
+func (h *ExerciseHandler) UpdateProgressionRule(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	var req model.UpdateProgressionRuleRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Failed to parse request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Request validation failed",
+			Details: validator.FormatErrors(err),
+		})
+	}
+
+	rule, err := h.svc.Progression().UpdateRule(c.Request().Context(), user.ClinicID, c.Param("pid"), c.Param("id"), c.Param("ruleId"), &req)
+	if err != nil {
+		return progressionError(c, err, "Progression rule not found", "Failed to update progression rule")
+	}
+	return c.JSON(http.StatusOK, rule)
+}
+
+// DeleteProgressionRule deletes a progression rule.
+// @Summary Delete progression rule
+// @Description Deletes a rule and any of its progressions awaiting approval. Changes it made stay in the prescription's history.
+// @Tags exercises
+// @Param pid path string true "Patient ID"
+// @Param id path string true "Prescription ID"
+// @Param ruleId path string true "Rule ID"
+// @Success 204
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/patients/{pid}/exercises/{id}/progression-rules/{ruleId} [delete]
+func (h *ExerciseHandler) DeleteProgressionRule(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	err := h.svc.Progression().DeleteRule(c.Request().Context(), user.ClinicID, c.Param("pid"), c.Param("id"), c.Param("ruleId"))
+	if err != nil {
+		return progressionError(c, err, "Progression rule not found", "Failed to delete progression rule")
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// EvaluateProgression evaluates a prescription's due progression rules now.
+// @Summary Evaluate progression rules
+// @Description Evaluates the prescription's rules whose window has ended, without waiting for the background job, and returns the progressions made
+// @Tags exercises
+// @Produce json
+// @Param pid path string true "Patient ID"
+// @Param id path string true "Prescription ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/patients/{pid}/exercises/{id}/progression-rules/evaluate [post]
+func (h *ExerciseHandler) EvaluateProgression(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	changes, err := h.svc.Progression().Evaluate(c.Request().Context(), user.ClinicID, c.Param("pid"), c.Param("id"))
+	if err != nil {
+		return progressionError(c, err, "Prescription not found", "Failed to evaluate progression rules")
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": changes,
+	})
+}
+
+// GetPrescriptionHistory returns a prescription's parameter changes.
+// @Summary Get prescription history
+// @Description Returns the changes to the prescription's sets, reps, hold time and duration, newest first: manual edits, and progressions with their status
+// @Tags exercises
+// @Produce json
+// @Param pid path string true "Patient ID"
+// @Param id path string true "Prescription ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/patients/{pid}/exercises/{id}/history [get]
+func (h *ExerciseHandler) GetPrescriptionHistory(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	changes, err := h.svc.Progression().ListHistory(c.Request().Context(), user.ClinicID, c.Param("pid"), c.Param("id"))
+	if err != nil {
+		return progressionError(c, err, "Prescription not found", "Failed to get prescription history")
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": changes,
+	})
+}
+
+// ListPendingProgressions returns the clinic's progressions awaiting approval.
+// @Summary List pending progressions
+// @Description Returns progressions proposed by rules that are waiting for a therapist, oldest first
+// @Tags exercises
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/exercise-progressions [get]
+func (h *ExerciseHandler) ListPendingProgressions(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	changes, err := h.svc.Progression().ListPending(c.Request().Context(), user.ClinicID)
+	if err != nil {
+		return progressionError(c, err, "Clinic not found", "Failed to list pending progressions")
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": changes,
+	})
+}
+
+// ApproveProgression applies a proposed progression.
+// @Summary Approve progression
+// @Description Applies the progression to its prescription. Fails with 409 if the parameter was changed after the progression was proposed.
+// @Tags exercises
+// @Accept json
+// @Produce json
+// @Param id path string true "Progression ID"
+// @Param decision body model.DecideProgressionRequest false "Note"
+// @Success 200 {object} model.PrescriptionChange
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/exercise-progressions/{id}/approve [post]
+func (h *ExerciseHandler) ApproveProgression(c echo.Context) error {
+	return h.decideProgression(c, true)
+}
+
+// RejectProgression declines a proposed progression.
+// @Summary Reject progression
+// @Description Declines the progression; its rule carries on with the next window
+// @Tags exercises
+// @Accept json
+// @Produce json
+// @Param id path string true "Progression ID"
+// @Param decision body model.DecideProgressionRequest false "Note"
+// @Success 200 {object} model.PrescriptionChange
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/exercise-progressions/{id}/reject [post]
+func (h *ExerciseHandler) RejectProgression(c echo.Context) error {
+	return h.decideProgression(c, false)
+}
+
+// decideProgression approves or rejects a proposed progression.
+func (h *ExerciseHandler) decideProgression(c echo.Context, approve bool) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	var req model.DecideProgressionRequest
+	if c.Request().ContentLength != 0 {
+		if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_request",
+				Message: "Failed to parse request body",
+			})
+		}
+	}
+
+	if err := validator.Validate(req); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Request validation failed",
+			Details: validator.FormatErrors(err),
+		})
+	}
+
+	progression := h.svc.Progression()
+	decide, failureMsg := progression.Reject, "Failed to reject progression"
+	if approve {
+		decide, failureMsg = progression.Approve, "Failed to approve progression"
+	}
+
+	change, err := decide(c.Request().Context(), user.ClinicID, c.Param("id"), user.UserID, &req)
+	if err != nil {
+		return progressionError(c, err, "Progression not found", failureMsg)
+	}
+	return c.JSON(http.StatusOK, change)
+}
+
+// progressionError maps progression service errors to responses.
+func progressionError(c echo.Context, err error, notFoundMsg, failureMsg string) error {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "not_found",
+			Message: notFoundMsg,
+		})
+	case errors.Is(err, repository.ErrInvalidInput):
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+	case errors.Is(err, repository.ErrConflict):
+		return c.JSON(http.StatusConflict, ErrorResponse{
+			Error:   "conflict",
+			Message: err.Error(),
+		})
+	}
+
+	log.Error().Err(err).Str("path", c.Path()).Msg(strings.ToLower(failureMsg))
+	return c.JSON(http.StatusInternalServerError, ErrorResponse{
+		Error:   "internal_error",
+		Message: failureMsg,
+	})
+}
@@ -0,0 +1,271 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/middleware"
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/repository"
+	"github.com/tqvdang/physioflow/apps/api/internal/service"
+	"github.com/tqvdang/physioflow/apps/api/pkg/validator"
+)
+
+// ProtocolHandler handles exercise protocol templates and the programs
+// created from them.
+type ProtocolHandler struct {
+	svc *service.Service
+}
+
+// NewProtocolHandler creates a new ProtocolHandler.
+func NewProtocolHandler(svc *service.Service) *ProtocolHandler {
+	return &ProtocolHandler{svc: svc}
+}
+
+// List returns the clinic's exercise protocols.
+// @Summary List exercise protocols
+// @Description Returns the clinic's protocol templates, active ones only unless active_only=false
+// @Tags exercises
+// @Produce json
+// @Param active_only query bool false "Only active protocols" default(true)
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/exercise-protocols [get]
+func (h *ProtocolHandler) List(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	includeInactive := c.QueryParam("active_only") == "false"
+	protocols, err := h.svc.Protocol().ListProtocols(c.Request().Context(), user.ClinicID, includeInactive)
+	if err != nil {
+		return protocolError(c, err, "Protocol not found", "Failed to list protocols")
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": protocols,
+	})
+}
+
+// Get returns an exercise protocol.
+// @Summary Get exercise protocol
+// @Tags exercises
+// @Produce json
+// @Param id path string true "Protocol ID"
+// @Success 200 {object} model.ExerciseProtocol
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/exercise-protocols/{id} [get]
+func (h *ProtocolHandler) Get(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	protocol, err := h.svc.Protocol().GetProtocol(c.Request().Context(), user.ClinicID, c.Param("id"))
+	if err != nil {
+		return protocolError(c, err, "Protocol not found", "Failed to get protocol")
+	}
+	return c.JSON(http.StatusOK, protocol)
+}
+
+// Create creates an exercise protocol.
+// @Summary Create exercise protocol
+// @Description Creates a named, phased template such as an ACL reconstruction protocol. Each phase lasts duration_weeks and lists exercises with their default parameters.
+// @Tags exercises
+// @Accept json
+// @Produce json
+// @Param protocol body model.CreateProtocolRequest true "Protocol"
+// @Success 201 {object} model.ExerciseProtocol
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/exercise-protocols [post]
+func (h *ProtocolHandler) Create(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	var req model.CreateProtocolRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Failed to parse request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Request validation failed",
+			Details: validator.FormatErrors(err),
+		})
+	}
+
+	protocol, err := h.svc.Protocol().CreateProtocol(c.Request().Context(), user.ClinicID, user.UserID, &req)
+	if err != nil {
+		return protocolError(c, err, "Protocol not found", "Failed to create protocol")
+	}
+	return c.JSON(http.StatusCreated, protocol)
+}
+
+// Update changes an exercise protocol.
+// @Summary Update exercise protocol
+// @Description Changes the template and increments its version. Programs already created from the protocol keep the phases they were created with.
+// @Tags exercises
+// @Accept json
+// @Produce json
+// @Param id path string true "Protocol ID"
+// @Param protocol body model.UpdateProtocolRequest true "Changes"
+// @Success 200 {object} model.ExerciseProtocol
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/exercise-protocols/{id} [put]
+func (h *ProtocolHandler) Update(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	var req model.UpdateProtocolRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Failed to parse request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Request validation failed",
+			Details: validator.FormatErrors(err),
+		})
+	}
+
+	protocol, err := h.svc.Protocol().UpdateProtocol(c.Request().Context(), user.ClinicID, c.Param("id"), &req)
+	if err != nil {
+		return protocolError(c, err, "Protocol not found", "Failed to update protocol")
+	}
+	return c.JSON(http.StatusOK, protocol)
+}
+
+// Instantiate creates a patient's home exercise program from a protocol.
+// @Summary Create program from protocol
+// @Description Copies the protocol's phases into a new program for the patient, scheduled back to back from start_date, with per-phase duration and per-exercise overrides. Each phase's prescriptions are created when it starts and completed when it ends.
+// @Tags exercises
+// @Accept json
+// @Produce json
+// @Param pid path string true "Patient ID"
+// @Param program body model.InstantiateProtocolRequest true "Protocol, start date and overrides"
+// @Success 201 {object} ProgramResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/patients/{pid}/exercise-programs/from-protocol [post]
+func (h *ProtocolHandler) Instantiate(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	var req model.InstantiateProtocolRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Failed to parse request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Request validation failed",
+			Details: validator.FormatErrors(err),
+		})
+	}
+
+	program, err := h.svc.Protocol().Instantiate(c.Request().Context(), user.ClinicID, c.Param("pid"), user.UserID, &req)
+	if err != nil {
+		return protocolError(c, err, "Patient or protocol not found", "Failed to create program from protocol")
+	}
+	return c.JSON(http.StatusCreated, toProgramResponse(*program))
+}
+
+// GetProgram returns a patient's home exercise program.
+// @Summary Get exercise program
+// @Description Returns the program with its prescriptions and, for programs created from a protocol, its phases
+// @Tags exercises
+// @Produce json
+// @Param pid path string true "Patient ID"
+// @Param programId path string true "Program ID"
+// @Success 200 {object} ProgramResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/patients/{pid}/exercise-programs/{programId} [get]
+func (h *ProtocolHandler) GetProgram(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	program, err := h.svc.Protocol().GetProgram(c.Request().Context(), user.ClinicID, c.Param("pid"), c.Param("programId"))
+	if err != nil {
+		return protocolError(c, err, "Program not found", "Failed to get program")
+	}
+	return c.JSON(http.StatusOK, toProgramResponse(*program))
+}
+
+// protocolError maps protocol service errors to HTTP responses.
+func protocolError(c echo.Context, err error, notFoundMsg, failureMsg string) error {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "not_found",
+			Message: notFoundMsg,
+		})
+	case errors.Is(err, repository.ErrInvalidInput):
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+	}
+
+	log.Error().Err(err).Str("path", c.Path()).Msg(strings.ToLower(failureMsg))
+	return c.JSON(http.StatusInternalServerError, ErrorResponse{
+		Error:   "internal_error",
+		Message: failureMsg,
+	})
+}
@@ -0,0 +1,244 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/middleware"
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/repository"
+	"github.com/tqvdang/physioflow/apps/api/internal/service"
+	"github.com/tqvdang/physioflow/apps/api/pkg/validator"
+)
+
+// ProxyHandler handles staff management of caregiver proxy access.
+type ProxyHandler struct {
+	svc *service.Service
+}
+
+// NewProxyHandler creates a new ProxyHandler.
+func NewProxyHandler(svc *service.Service) *ProxyHandler {
+	return &ProxyHandler{svc: svc}
+}
+
+// ProxyGrantResponse represents a caregiver proxy grant in API responses.
+type ProxyGrantResponse struct {
+	ID               string   `json:"id"`
+	PatientID        string   `json:"patient_id"`
+	PatientName      string   `json:"patient_name,omitempty"`
+	ProxyUserID      string   `json:"proxy_user_id"`
+	ProxyName        string   `json:"proxy_name"`
+	Relationship     string   `json:"relationship,omitempty"`
+	Phone            string   `json:"phone,omitempty"`
+	Scopes           []string `json:"scopes"`
+	StartsAt         string   `json:"starts_at"`
+	ExpiresAt        string   `json:"expires_at"`
+	RevokedAt        *string  `json:"revoked_at,omitempty"`
+	IsActive         bool     `json:"is_active"`
+	GrantedByPatient bool     `json:"granted_by_patient"`
+	CreatedAt        string   `json:"created_at"`
+}
+
+// List returns the caregiver grants for a patient.
+// @Summary List patient proxy access
+// @Description Returns all caregiver grants for a patient, including expired and revoked ones
+// @Tags proxies
+// @Accept json
+// @Produce json
+// @Param id path string true "Patient ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/patients/{id}/proxies [get]
+func (h *ProxyHandler) List(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	patientID := c.Param("id")
+	if patientID == "" {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Patient ID is required",
+		})
+	}
+
+	grants, err := h.svc.Proxy().ListPatientGrants(c.Request().Context(), user.ClinicID, patientID)
+	if err != nil {
+		log.Error().Err(err).Str("patient_id", patientID).Msg("failed to list proxy grants")
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to list proxy access",
+		})
+	}
+
+	data := make([]ProxyGrantResponse, len(grants))
+	for i, g := range grants {
+		data[i] = toProxyGrantResponse(g)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": data,
+	})
+}
+
+// Create grants a caregiver access to a patient.
+// @Summary Grant proxy access
+// @Description Gives a caregiver account scoped, time-limited access to act for a patient
+// @Tags proxies
+// @Accept json
+// @Produce json
+// @Param id path string true "Patient ID"
+// @Param request body model.CreateProxyGrantRequest true "Grant details"
+// @Success 201 {object} ProxyGrantResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/patients/{id}/proxies [post]
+func (h *ProxyHandler) Create(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	patientID := c.Param("id")
+	if patientID == "" {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Patient ID is required",
+		})
+	}
+
+	var req model.CreateProxyGrantRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Failed to parse request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Request validation failed",
+			Details: validator.FormatErrors(err),
+		})
+	}
+
+	grant, err := h.svc.Proxy().GrantAccess(c.Request().Context(), user.ClinicID, patientID, user.UserID, false, &req)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "not_found",
+				Message: "Patient not found",
+			})
+		}
+		if errors.Is(err, repository.ErrInvalidInput) {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_request",
+				Message: err.Error(),
+			})
+		}
+		log.Error().Err(err).Str("patient_id", patientID).Msg("failed to grant proxy access")
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to grant proxy access",
+		})
+	}
+
+	return c.JSON(http.StatusCreated, toProxyGrantResponse(*grant))
+}
+
+// Revoke ends a caregiver's access to a patient.
+// @Summary Revoke proxy access
+// @Description Immediately ends a caregiver grant
+// @Tags proxies
+// @Accept json
+// @Produce json
+// @Param id path string true "Patient ID"
+// @Param grantId path string true "Grant ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/patients/{id}/proxies/{grantId} [delete]
+func (h *ProxyHandler) Revoke(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	patientID := c.Param("id")
+	grantID := c.Param("grantId")
+	if patientID == "" || grantID == "" {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Patient ID and Grant ID are required",
+		})
+	}
+
+	if err := h.svc.Proxy().RevokeAccess(c.Request().Context(), user.ClinicID, patientID, grantID, user.UserID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "not_found",
+				Message: "Proxy grant not found",
+			})
+		}
+		log.Error().Err(err).Str("grant_id", grantID).Msg("failed to revoke proxy access")
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to revoke proxy access",
+		})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// toProxyGrantResponse converts a ProxyGrant model to ProxyGrantResponse.
+func toProxyGrantResponse(g model.ProxyGrant) ProxyGrantResponse {
+	scopes := make([]string, len(g.Scopes))
+	for i, s := range g.Scopes {
+		scopes[i] = string(s)
+	}
+
+	resp := ProxyGrantResponse{
+		ID:               g.ID,
+		PatientID:        g.PatientID,
+		PatientName:      g.PatientName,
+		ProxyUserID:      g.ProxyUserID,
+		ProxyName:        g.ProxyName,
+		Relationship:     g.Relationship,
+		Phone:            g.Phone,
+		Scopes:           scopes,
+		StartsAt:         g.StartsAt.Format(time.RFC3339),
+		ExpiresAt:        g.ExpiresAt.Format(time.RFC3339),
+		IsActive:         g.IsActiveAt(time.Now()),
+		GrantedByPatient: g.GrantedByPatient,
+		CreatedAt:        g.CreatedAt.Format(time.RFC3339),
+	}
+
+	if g.RevokedAt != nil {
+		formatted := g.RevokedAt.Format(time.RFC3339)
+		resp.RevokedAt = &formatted
+	}
+
+	return resp
+}
@@ -1,13 +1,17 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
 
 	"github.com/tqvdang/physioflow/apps/api/internal/middleware"
 	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/repository"
 	"github.com/tqvdang/physioflow/apps/api/internal/service"
 	"github.com/tqvdang/physioflow/apps/api/pkg/validator"
 )
@@ -89,25 +93,44 @@ type ROMDeltaResponse struct {
 	Trend           string   `json:"trend"` // improved, worsened, stable, first_record
 }
 
-// QuickScheduleRequest represents the request body for quick scheduling.
+// QuickScheduleRequest represents the request body for quick scheduling. The
+// visit day is given either as a date or as an offset from today, such as
+// "+3 days" or "+1 week".
 type QuickScheduleRequest struct {
-	Date     string `json:"date" validate:"required"`      // ISO date
-	TimeSlot string `json:"time_slot" validate:"required"` // HH:MM
-	Duration int    `json:"duration" validate:"required,min=15,max=180"`
-	Notes    string `json:"notes,omitempty"`
+	Date              string `json:"date" validate:"required_without=Offset,omitempty,datetime=2006-01-02"` // ISO date
+	Offset            string `json:"offset" validate:"required_without=Date,omitempty,max=20"`              // e.g. "+1 week"
+	TimeSlot          string `json:"time_slot" validate:"omitempty,datetime=15:04"`                         // HH:MM; defaults to the last visit's time
+	Duration          int    `json:"duration" validate:"required,min=15,max=180"`
+	AppointmentTypeID string `json:"appointment_type_id" validate:"omitempty,uuid"`
+	Snap              bool   `json:"snap"` // book the nearest free slot if the requested one is taken
+	Notes             string `json:"notes,omitempty"`
 }
 
 // QuickScheduleResponse represents the response for quick scheduling.
 type QuickScheduleResponse struct {
-	ID           string `json:"id"`
-	PatientID    string `json:"patient_id"`
-	TherapistID  string `json:"therapist_id"`
-	Date         string `json:"date"`
-	TimeSlot     string `json:"time_slot"`
-	Duration     int    `json:"duration"`
-	Status       string `json:"status"`
-	Notes        string `json:"notes,omitempty"`
-	CreatedAt    string `json:"created_at"`
+	ID                 string `json:"id"`
+	PatientID          string `json:"patient_id"`
+	TherapistID        string `json:"therapist_id"`
+	Date               string `json:"date"`
+	TimeSlot           string `json:"time_slot"`
+	StartTime          string `json:"start_time"`
+	EndTime            string `json:"end_time"`
+	Timezone           string `json:"timezone"`
+	Duration           int    `json:"duration"`
+	Status             string `json:"status"`
+	Notes              string `json:"notes,omitempty"`
+	Snapped            bool   `json:"snapped"`
+	RequestedStartTime string `json:"requested_start_time"`
+	CreatedAt          string `json:"created_at"`
+}
+
+// QuickScheduleConflictResponse is returned when the requested slot is taken.
+// Alternatives lists the therapist's nearest free slots, best first.
+type QuickScheduleConflictResponse struct {
+	Error              string                   `json:"error"`
+	Message            string                   `json:"message"`
+	RequestedStartTime string                   `json:"requested_start_time"`
+	Alternatives       []model.AvailabilitySlot `json:"alternatives"`
 }
 
 // =============================================================================
@@ -309,7 +332,7 @@ func (h *QuickActionsHandler) RecordQuickROM(c echo.Context) error {
 
 // QuickSchedule quickly schedules the next visit.
 // @Summary Quick schedule next visit
-// @Description Quickly schedules the next visit for a patient
+// @Description Books the patient's next visit with the current therapist on a date or after an offset such as "+1 week". When the slot is taken, the nearest free slots are returned, or the nearest one is booked if snap is set.
 // @Tags quick-actions
 // @Accept json
 // @Produce json
@@ -356,41 +379,71 @@ func (h *QuickActionsHandler) QuickSchedule(c echo.Context) error {
 	}
 
 	scheduleReq := model.QuickScheduleRequest{
-		PatientID:   patientID,
-		ClinicID:    user.ClinicID,
-		TherapistID: user.UserID,
-		Date:        req.Date,
-		TimeSlot:    req.TimeSlot,
-		Duration:    req.Duration,
-		Notes:       req.Notes,
+		PatientID:         patientID,
+		ClinicID:          user.ClinicID,
+		TherapistID:       user.UserID,
+		UserID:            user.UserID,
+		Date:              req.Date,
+		Offset:            req.Offset,
+		TimeSlot:          req.TimeSlot,
+		Duration:          req.Duration,
+		AppointmentTypeID: req.AppointmentTypeID,
+		Snap:              req.Snap,
+		Notes:             req.Notes,
 	}
 
 	// Create the appointment
 	result, err := h.svc.QuickActions().QuickSchedule(c.Request().Context(), scheduleReq)
 	if err != nil {
-		// Check for scheduling conflicts
-		if err.Error() == "time slot not available" {
+		var unavailable *service.SlotUnavailableError
+		if errors.As(err, &unavailable) {
+			return c.JSON(http.StatusConflict, QuickScheduleConflictResponse{
+				Error:              "conflict",
+				Message:            "The requested time slot is not available",
+				RequestedStartTime: unavailable.Requested.Format(time.RFC3339),
+				Alternatives:       unavailable.Alternatives,
+			})
+		}
+		if errors.Is(err, repository.ErrConflict) || strings.HasPrefix(err.Error(), "scheduling conflict") {
 			return c.JSON(http.StatusConflict, ErrorResponse{
 				Error:   "conflict",
-				Message: "The requested time slot is not available",
+				Message: err.Error(),
+			})
+		}
+		if errors.Is(err, repository.ErrInvalidInput) {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_input",
+				Message: err.Error(),
+			})
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "not_found",
+				Message: "Patient or appointment type not found",
 			})
 		}
+		log.Error().Err(err).Str("patient_id", patientID).Msg("failed to quick schedule appointment")
 		return c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Error:   "internal_error",
-			Message: "Failed to schedule appointment: " + err.Error(),
+			Message: "Failed to schedule appointment",
 		})
 	}
 
 	return c.JSON(http.StatusCreated, QuickScheduleResponse{
-		ID:          result.ID,
-		PatientID:   result.PatientID,
-		TherapistID: result.TherapistID,
-		Date:        result.Date,
-		TimeSlot:    result.TimeSlot,
-		Duration:    result.Duration,
-		Status:      result.Status,
-		Notes:       result.Notes,
-		CreatedAt:   result.CreatedAt,
+		ID:                 result.ID,
+		PatientID:          result.PatientID,
+		TherapistID:        result.TherapistID,
+		Date:               result.Date,
+		TimeSlot:           result.TimeSlot,
+		StartTime:          result.StartTime.Format(time.RFC3339),
+		EndTime:            result.EndTime.Format(time.RFC3339),
+		Timezone:           result.Timezone,
+		Duration:           result.Duration,
+		Status:             result.Status,
+		Notes:              result.Notes,
+		Snapped:            result.Snapped,
+		RequestedStartTime: result.RequestedStartTime.Format(time.RFC3339),
+		CreatedAt:          result.CreatedAt,
 	})
 }
 
@@ -0,0 +1,370 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/middleware"
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/repository"
+	"github.com/tqvdang/physioflow/apps/api/internal/service"
+	"github.com/tqvdang/physioflow/apps/api/pkg/validator"
+)
+
+// ResourceHandler handles clinic room and equipment requests.
+type ResourceHandler struct {
+	svc *service.Service
+}
+
+// NewResourceHandler creates a new ResourceHandler.
+func NewResourceHandler(svc *service.Service) *ResourceHandler {
+	return &ResourceHandler{svc: svc}
+}
+
+// ResourceResponse represents a room or piece of equipment in API responses.
+type ResourceResponse struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	NameVi       string `json:"name_vi,omitempty"`
+	ResourceType string `json:"resource_type"`
+	Capacity     int    `json:"capacity"`
+	Description  string `json:"description,omitempty"`
+	IsActive     bool   `json:"is_active"`
+	CreatedAt    string `json:"created_at"`
+	UpdatedAt    string `json:"updated_at"`
+}
+
+// ResourceDayScheduleResponse represents one resource's bookings on a day.
+type ResourceDayScheduleResponse struct {
+	Resource      ResourceResponse      `json:"resource"`
+	Appointments  []AppointmentResponse `json:"appointments"`
+	BookedMinutes int                   `json:"booked_minutes"`
+}
+
+// ResourceDayViewResponse represents the bookings of a clinic's resources on a day.
+type ResourceDayViewResponse struct {
+	Date      string                        `json:"date"`
+	Resources []ResourceDayScheduleResponse `json:"resources"`
+}
+
+// List returns the clinic's rooms and equipment.
+// @Summary List resources
+// @Description Returns the clinic's rooms and equipment, optionally of one type
+// @Tags resources
+// @Accept json
+// @Produce json
+// @Param type query string false "Resource type (room, equipment)"
+// @Param active_only query bool false "Only active resources" default(false)
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/resources [get]
+func (h *ResourceHandler) List(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	resourceType, ok := parseResourceType(c.QueryParam("type"))
+	if !ok {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid resource type. Use room or equipment",
+		})
+	}
+	activeOnly := c.QueryParam("active_only") == "true"
+
+	resources, err := h.svc.Resource().List(c.Request().Context(), user.ClinicID, resourceType, activeOnly)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to list resources")
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to list resources",
+		})
+	}
+
+	data := make([]ResourceResponse, len(resources))
+	for i := range resources {
+		data[i] = toResourceResponse(&resources[i])
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": data,
+	})
+}
+
+// Get returns a resource by ID.
+// @Summary Get resource
+// @Description Returns a room or piece of equipment
+// @Tags resources
+// @Accept json
+// @Produce json
+// @Param id path string true "Resource ID (UUID)"
+// @Success 200 {object} ResourceResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/resources/{id} [get]
+func (h *ResourceHandler) Get(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	resource, err := h.svc.Resource().Get(c.Request().Context(), user.ClinicID, c.Param("id"))
+	if err != nil {
+		return resourceError(c, err, "Failed to retrieve resource")
+	}
+
+	return c.JSON(http.StatusOK, toResourceResponse(resource))
+}
+
+// Create adds a room or piece of equipment.
+// @Summary Create resource
+// @Description Adds a bookable room or piece of equipment; capacity defaults to 1
+// @Tags resources
+// @Accept json
+// @Produce json
+// @Param request body model.CreateResourceRequest true "Resource"
+// @Success 201 {object} ResourceResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/resources [post]
+func (h *ResourceHandler) Create(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	var req model.CreateResourceRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Failed to parse request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Request validation failed",
+			Details: validator.FormatErrors(err),
+		})
+	}
+
+	resource, err := h.svc.Resource().Create(c.Request().Context(), user.ClinicID, user.UserID, &req)
+	if err != nil {
+		return resourceError(c, err, "Failed to create resource")
+	}
+
+	return c.JSON(http.StatusCreated, toResourceResponse(resource))
+}
+
+// Update changes a resource.
+// @Summary Update resource
+// @Description Changes the name, capacity or description of a resource, or deactivates it
+// @Tags resources
+// @Accept json
+// @Produce json
+// @Param id path string true "Resource ID (UUID)"
+// @Param request body model.UpdateResourceRequest true "Changes"
+// @Success 200 {object} ResourceResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/resources/{id} [put]
+func (h *ResourceHandler) Update(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	var req model.UpdateResourceRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Failed to parse request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Request validation failed",
+			Details: validator.FormatErrors(err),
+		})
+	}
+
+	resource, err := h.svc.Resource().Update(c.Request().Context(), user.ClinicID, c.Param("id"), user.UserID, &req)
+	if err != nil {
+		return resourceError(c, err, "Failed to update resource")
+	}
+
+	return c.JSON(http.StatusOK, toResourceResponse(resource))
+}
+
+// Delete removes a resource without upcoming bookings.
+// @Summary Delete resource
+// @Description Removes a resource; resources with upcoming appointments must be deactivated instead
+// @Tags resources
+// @Param id path string true "Resource ID (UUID)"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/resources/{id} [delete]
+func (h *ResourceHandler) Delete(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	if err := h.svc.Resource().Delete(c.Request().Context(), user.ClinicID, c.Param("id"), user.UserID); err != nil {
+		return resourceError(c, err, "Failed to delete resource")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetDayView returns the bookings of each resource on a day.
+// @Summary Get resource day view
+// @Description Returns each active room or piece of equipment with its appointments on a day
+// @Tags resources
+// @Accept json
+// @Produce json
+// @Param date path string true "Date (YYYY-MM-DD)"
+// @Param type query string false "Resource type (room, equipment)"
+// @Success 200 {object} ResourceDayViewResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/resources/day/{date} [get]
+func (h *ResourceHandler) GetDayView(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	date, err := time.Parse("2006-01-02", c.Param("date"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid date format. Use YYYY-MM-DD",
+		})
+	}
+
+	resourceType, ok := parseResourceType(c.QueryParam("type"))
+	if !ok {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid resource type. Use room or equipment",
+		})
+	}
+
+	view, err := h.svc.Resource().GetDayView(c.Request().Context(), user.ClinicID, date, resourceType)
+	if err != nil {
+		return resourceError(c, err, "Failed to retrieve resource day view")
+	}
+
+	resp := ResourceDayViewResponse{
+		Date:      view.Date,
+		Resources: make([]ResourceDayScheduleResponse, len(view.Resources)),
+	}
+	for i, day := range view.Resources {
+		appointments := make([]AppointmentResponse, len(day.Appointments))
+		for j, a := range day.Appointments {
+			appointments[j] = toAppointmentResponse(a)
+		}
+		resp.Resources[i] = ResourceDayScheduleResponse{
+			Resource:      toResourceResponse(&day.Resource),
+			Appointments:  appointments,
+			BookedMinutes: day.BookedMinutes,
+		}
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// parseResourceType validates an optional resource type filter.
+func parseResourceType(value string) (model.ResourceType, bool) {
+	switch t := model.ResourceType(value); t {
+	case "", model.ResourceTypeRoom, model.ResourceTypeEquipment:
+		return t, true
+	}
+	return "", false
+}
+
+// resourceError maps resource service errors to HTTP responses.
+func resourceError(c echo.Context, err error, failureMsg string) error {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "not_found",
+			Message: "Resource not found",
+		})
+	case errors.Is(err, repository.ErrAlreadyExists):
+		return c.JSON(http.StatusConflict, ErrorResponse{
+			Error:   "conflict",
+			Message: "A resource with this name already exists",
+		})
+	case errors.Is(err, repository.ErrInvalidInput):
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_input",
+			Message: err.Error(),
+		})
+	}
+
+	log.Error().Err(err).Str("path", c.Path()).Msg("resource request failed")
+	return c.JSON(http.StatusInternalServerError, ErrorResponse{
+		Error:   "internal_error",
+		Message: failureMsg,
+	})
+}
+
+// toResourceResponse converts a Resource to ResourceResponse.
+func toResourceResponse(r *model.Resource) ResourceResponse {
+	return ResourceResponse{
+		ID:           r.ID,
+		Name:         r.Name,
+		NameVi:       r.NameVi,
+		ResourceType: string(r.Type),
+		Capacity:     r.Capacity,
+		Description:  r.Description,
+		IsActive:     r.IsActive,
+		CreatedAt:    r.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:    r.UpdatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,559 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/middleware"
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/repository"
+	"github.com/tqvdang/physioflow/apps/api/internal/service"
+	"github.com/tqvdang/physioflow/apps/api/pkg/validator"
+)
+
+// defaultExceptionRangeDays is how far ahead exceptions are listed when no end date is given.
+const defaultExceptionRangeDays = 90
+
+// ScheduleHandler handles therapist working hours and schedule exceptions.
+type ScheduleHandler struct {
+	svc *service.Service
+}
+
+// NewScheduleHandler creates a new ScheduleHandler.
+func NewScheduleHandler(svc *service.Service) *ScheduleHandler {
+	return &ScheduleHandler{svc: svc}
+}
+
+// ScheduleResponse represents a weekly working period in API responses.
+type ScheduleResponse struct {
+	ID            string  `json:"id"`
+	TherapistID   string  `json:"therapist_id"`
+	DayOfWeek     int     `json:"day_of_week"`
+	StartTime     string  `json:"start_time"`
+	EndTime       string  `json:"end_time"`
+	EffectiveFrom string  `json:"effective_from"`
+	EffectiveTo   *string `json:"effective_to,omitempty"`
+	IsActive      bool    `json:"is_active"`
+	CreatedAt     string  `json:"created_at"`
+	UpdatedAt     string  `json:"updated_at"`
+}
+
+// ScheduleExceptionResponse represents a schedule exception in API responses.
+type ScheduleExceptionResponse struct {
+	ID            string  `json:"id"`
+	TherapistID   string  `json:"therapist_id"`
+	Date          string  `json:"date"`
+	StartTime     *string `json:"start_time,omitempty"`
+	EndTime       *string `json:"end_time,omitempty"`
+	AllDay        bool    `json:"all_day"`
+	ExceptionType string  `json:"exception_type"`
+	IsAvailable   bool    `json:"is_available"`
+	Reason        string  `json:"reason,omitempty"`
+	Notes         string  `json:"notes,omitempty"`
+	CreatedAt     string  `json:"created_at"`
+	UpdatedAt     string  `json:"updated_at"`
+}
+
+// AffectedAppointmentResponse represents a booked appointment that a schedule
+// exception leaves outside the therapist's working time.
+type AffectedAppointmentResponse struct {
+	AppointmentID string `json:"appointment_id"`
+	PatientID     string `json:"patient_id"`
+	PatientName   string `json:"patient_name"`
+	PatientMRN    string `json:"patient_mrn"`
+	PatientPhone  string `json:"patient_phone,omitempty"`
+	StartTime     string `json:"start_time"`
+	EndTime       string `json:"end_time"`
+	Status        string `json:"status"`
+}
+
+// ScheduleExceptionResultResponse represents a saved schedule exception and
+// the appointments that need to be rescheduled because of it.
+type ScheduleExceptionResultResponse struct {
+	Exception            ScheduleExceptionResponse     `json:"exception"`
+	AffectedAppointments []AffectedAppointmentResponse `json:"affected_appointments"`
+	Warning              string                        `json:"warning,omitempty"`
+}
+
+// AffectedAppointmentsConflictResponse is returned when a schedule exception
+// overlaps booked appointments that were not acknowledged.
+type AffectedAppointmentsConflictResponse struct {
+	Error                string                        `json:"error"`
+	Message              string                        `json:"message"`
+	AffectedAppointments []AffectedAppointmentResponse `json:"affected_appointments"`
+}
+
+// ListSchedules returns a therapist's weekly working periods.
+// @Summary List therapist schedules
+// @Description Returns every weekly working period of a therapist, including inactive and expired ones
+// @Tags therapists
+// @Accept json
+// @Produce json
+// @Param id path string true "Therapist ID (UUID)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/therapists/{id}/schedules [get]
+func (h *ScheduleHandler) ListSchedules(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	therapistID := c.Param("id")
+	schedules, err := h.svc.Schedule().ListSchedules(c.Request().Context(), user.ClinicID, therapistID)
+	if err != nil {
+		log.Error().Err(err).Str("therapist_id", therapistID).Msg("failed to list therapist schedules")
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to list schedules",
+		})
+	}
+
+	data := make([]ScheduleResponse, len(schedules))
+	for i := range schedules {
+		data[i] = toScheduleResponse(&schedules[i])
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": data,
+	})
+}
+
+// CreateSchedule adds a weekly working period to a therapist's schedule.
+// @Summary Create therapist schedule
+// @Description Adds working hours on one weekday, effective from a date and optionally until a date
+// @Tags therapists
+// @Accept json
+// @Produce json
+// @Param id path string true "Therapist ID (UUID)"
+// @Param request body model.CreateScheduleRequest true "Working period"
+// @Success 201 {object} ScheduleResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/therapists/{id}/schedules [post]
+func (h *ScheduleHandler) CreateSchedule(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	var req model.CreateScheduleRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Failed to parse request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Request validation failed",
+			Details: validator.FormatErrors(err),
+		})
+	}
+
+	schedule, err := h.svc.Schedule().CreateSchedule(c.Request().Context(), user.ClinicID, c.Param("id"), user.UserID, &req)
+	if err != nil {
+		return scheduleError(c, err, "Failed to create schedule")
+	}
+
+	return c.JSON(http.StatusCreated, toScheduleResponse(schedule))
+}
+
+// UpdateSchedule changes a weekly working period.
+// @Summary Update therapist schedule
+// @Description Changes the hours, weekday or effective dates of a working period
+// @Tags therapists
+// @Accept json
+// @Produce json
+// @Param id path string true "Therapist ID (UUID)"
+// @Param scheduleId path string true "Schedule ID (UUID)"
+// @Param request body model.UpdateScheduleRequest true "Changes"
+// @Success 200 {object} ScheduleResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/therapists/{id}/schedules/{scheduleId} [put]
+func (h *ScheduleHandler) UpdateSchedule(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	var req model.UpdateScheduleRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Failed to parse request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Request validation failed",
+			Details: validator.FormatErrors(err),
+		})
+	}
+
+	schedule, err := h.svc.Schedule().UpdateSchedule(c.Request().Context(), user.ClinicID, c.Param("id"), c.Param("scheduleId"), &req)
+	if err != nil {
+		return scheduleError(c, err, "Failed to update schedule")
+	}
+
+	return c.JSON(http.StatusOK, toScheduleResponse(schedule))
+}
+
+// DeleteSchedule removes a weekly working period.
+// @Summary Delete therapist schedule
+// @Description Removes a working period. Set effective_to instead to keep its history.
+// @Tags therapists
+// @Accept json
+// @Produce json
+// @Param id path string true "Therapist ID (UUID)"
+// @Param scheduleId path string true "Schedule ID (UUID)"
+// @Success 204
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/therapists/{id}/schedules/{scheduleId} [delete]
+func (h *ScheduleHandler) DeleteSchedule(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	if err := h.svc.Schedule().DeleteSchedule(c.Request().Context(), user.ClinicID, c.Param("id"), c.Param("scheduleId")); err != nil {
+		return scheduleError(c, err, "Failed to delete schedule")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ListExceptions returns a therapist's schedule exceptions in a date range.
+// @Summary List schedule exceptions
+// @Description Returns leave, training, holidays and extra shifts dated from start (default today) up to but excluding end (default 90 days later)
+// @Tags therapists
+// @Accept json
+// @Produce json
+// @Param id path string true "Therapist ID (UUID)"
+// @Param start query string false "Start date (YYYY-MM-DD)"
+// @Param end query string false "End date (YYYY-MM-DD)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/therapists/{id}/exceptions [get]
+func (h *ScheduleHandler) ListExceptions(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	now := time.Now().UTC()
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if s := c.QueryParam("start"); s != "" {
+		parsed, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_request",
+				Message: "Invalid start date format. Use YYYY-MM-DD",
+			})
+		}
+		start = parsed
+	}
+
+	end := start.AddDate(0, 0, defaultExceptionRangeDays)
+	if e := c.QueryParam("end"); e != "" {
+		parsed, err := time.Parse("2006-01-02", e)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_request",
+				Message: "Invalid end date format. Use YYYY-MM-DD",
+			})
+		}
+		end = parsed
+	}
+
+	if !end.After(start) {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "End date must be after start date",
+		})
+	}
+
+	therapistID := c.Param("id")
+	exceptions, err := h.svc.Schedule().ListExceptions(c.Request().Context(), user.ClinicID, therapistID, start, end)
+	if err != nil {
+		log.Error().Err(err).Str("therapist_id", therapistID).Msg("failed to list schedule exceptions")
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to list schedule exceptions",
+		})
+	}
+
+	data := make([]ScheduleExceptionResponse, len(exceptions))
+	for i := range exceptions {
+		data[i] = toScheduleExceptionResponse(&exceptions[i])
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": data,
+	})
+}
+
+// CreateException records leave, training, a holiday or an extra shift.
+// @Summary Create schedule exception
+// @Description Records a one-time change to a therapist's schedule. If a blocking exception overlaps booked appointments, the request fails with 409 and the affected patients unless acknowledge_affected is set.
+// @Tags therapists
+// @Accept json
+// @Produce json
+// @Param id path string true "Therapist ID (UUID)"
+// @Param request body model.CreateScheduleExceptionRequest true "Exception"
+// @Success 201 {object} ScheduleExceptionResultResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} AffectedAppointmentsConflictResponse
+// @Failure 422 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/therapists/{id}/exceptions [post]
+func (h *ScheduleHandler) CreateException(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	var req model.CreateScheduleExceptionRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Failed to parse request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Request validation failed",
+			Details: validator.FormatErrors(err),
+		})
+	}
+
+	result, err := h.svc.Schedule().CreateException(c.Request().Context(), user.ClinicID, c.Param("id"), user.UserID, &req)
+	if err != nil {
+		return scheduleError(c, err, "Failed to create schedule exception")
+	}
+
+	return c.JSON(http.StatusCreated, toScheduleExceptionResultResponse(result))
+}
+
+// UpdateException changes a schedule exception.
+// @Summary Update schedule exception
+// @Description Changes a schedule exception. Newly affected appointments must be acknowledged as on create.
+// @Tags therapists
+// @Accept json
+// @Produce json
+// @Param id path string true "Therapist ID (UUID)"
+// @Param exceptionId path string true "Exception ID (UUID)"
+// @Param request body model.UpdateScheduleExceptionRequest true "Changes"
+// @Success 200 {object} ScheduleExceptionResultResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} AffectedAppointmentsConflictResponse
+// @Failure 422 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/therapists/{id}/exceptions/{exceptionId} [put]
+func (h *ScheduleHandler) UpdateException(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	var req model.UpdateScheduleExceptionRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Failed to parse request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Error:   "validation_failed",
+			Message: "Request validation failed",
+			Details: validator.FormatErrors(err),
+		})
+	}
+
+	result, err := h.svc.Schedule().UpdateException(c.Request().Context(), user.ClinicID, c.Param("id"), c.Param("exceptionId"), &req)
+	if err != nil {
+		return scheduleError(c, err, "Failed to update schedule exception")
+	}
+
+	return c.JSON(http.StatusOK, toScheduleExceptionResultResponse(result))
+}
+
+// DeleteException removes a schedule exception.
+// @Summary Delete schedule exception
+// @Description Removes a schedule exception, restoring the therapist's regular hours for that date
+// @Tags therapists
+// @Accept json
+// @Produce json
+// @Param id path string true "Therapist ID (UUID)"
+// @Param exceptionId path string true "Exception ID (UUID)"
+// @Success 204
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/therapists/{id}/exceptions/{exceptionId} [delete]
+func (h *ScheduleHandler) DeleteException(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	if err := h.svc.Schedule().DeleteException(c.Request().Context(), user.ClinicID, c.Param("id"), c.Param("exceptionId")); err != nil {
+		return scheduleError(c, err, "Failed to delete schedule exception")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// scheduleError maps schedule service errors to HTTP responses.
+func scheduleError(c echo.Context, err error, failureMsg string) error {
+	var affected *service.AffectedAppointmentsError
+	switch {
+	case errors.As(err, &affected):
+		return c.JSON(http.StatusConflict, AffectedAppointmentsConflictResponse{
+			Error:                "conflict",
+			Message:              err.Error(),
+			AffectedAppointments: toAffectedAppointmentResponses(affected.Appointments),
+		})
+	case errors.Is(err, repository.ErrNotFound):
+		return c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "not_found",
+			Message: "Schedule or exception not found",
+		})
+	case errors.Is(err, repository.ErrInvalidInput):
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_input",
+			Message: err.Error(),
+		})
+	}
+
+	log.Error().Err(err).Str("path", c.Path()).Msg("schedule request failed")
+	return c.JSON(http.StatusInternalServerError, ErrorResponse{
+		Error:   "internal_error",
+		Message: failureMsg,
+	})
+}
+
+// toScheduleResponse converts a TherapistSchedule to ScheduleResponse.
+func toScheduleResponse(s *model.TherapistSchedule) ScheduleResponse {
+	resp := ScheduleResponse{
+		ID:            s.ID,
+		TherapistID:   s.TherapistID,
+		DayOfWeek:     s.DayOfWeek,
+		StartTime:     s.StartTime,
+		EndTime:       s.EndTime,
+		EffectiveFrom: s.EffectiveFrom.Format("2006-01-02"),
+		IsActive:      s.IsActive,
+		CreatedAt:     s.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:     s.UpdatedAt.Format(time.RFC3339),
+	}
+	if s.EffectiveTo != nil {
+		effectiveTo := s.EffectiveTo.Format("2006-01-02")
+		resp.EffectiveTo = &effectiveTo
+	}
+	return resp
+}
+
+// toScheduleExceptionResponse converts a ScheduleException to ScheduleExceptionResponse.
+func toScheduleExceptionResponse(e *model.ScheduleException) ScheduleExceptionResponse {
+	return ScheduleExceptionResponse{
+		ID:            e.ID,
+		TherapistID:   e.TherapistID,
+		Date:          e.Date.Format("2006-01-02"),
+		StartTime:     e.StartTime,
+		EndTime:       e.EndTime,
+		AllDay:        e.StartTime == nil,
+		ExceptionType: string(e.Type),
+		IsAvailable:   e.IsAvailable,
+		Reason:        e.Reason,
+		Notes:         e.Notes,
+		CreatedAt:     e.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:     e.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// toScheduleExceptionResultResponse converts a ScheduleExceptionResult to its response.
+func toScheduleExceptionResultResponse(r *model.ScheduleExceptionResult) ScheduleExceptionResultResponse {
+	resp := ScheduleExceptionResultResponse{
+		Exception:            toScheduleExceptionResponse(r.Exception),
+		AffectedAppointments: toAffectedAppointmentResponses(r.AffectedAppointments),
+	}
+	if n := len(r.AffectedAppointments); n > 0 {
+		resp.Warning = fmt.Sprintf("%d booked appointment(s) fall outside the therapist's working time and need to be rescheduled", n)
+	}
+	return resp
+}
+
+// toAffectedAppointmentResponses lists the patients of affected appointments.
+func toAffectedAppointmentResponses(appointments []model.AppointmentWithDetails) []AffectedAppointmentResponse {
+	resp := make([]AffectedAppointmentResponse, len(appointments))
+	for i, a := range appointments {
+		resp[i] = AffectedAppointmentResponse{
+			AppointmentID: a.ID,
+			PatientID:     a.PatientID,
+			PatientName:   a.PatientName,
+			PatientMRN:    a.PatientMRN,
+			PatientPhone:  a.PatientPhone,
+			StartTime:     a.StartTime.Format(time.RFC3339),
+			EndTime:       a.EndTime.Format(time.RFC3339),
+			Status:        string(a.Status),
+		}
+	}
+	return resp
+}
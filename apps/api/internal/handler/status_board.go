@@ -0,0 +1,224 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/middleware"
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/repository"
+	"github.com/tqvdang/physioflow/apps/api/internal/service"
+)
+
+// statusBoardHeartbeat is how often an idle event stream sends a comment, so
+// that proxies and load balancers keep the connection open.
+const statusBoardHeartbeat = 25 * time.Second
+
+// StatusBoardHandler handles patient check-in and the live clinic status board.
+type StatusBoardHandler struct {
+	svc *service.Service
+}
+
+// NewStatusBoardHandler creates a new StatusBoardHandler.
+func NewStatusBoardHandler(svc *service.Service) *StatusBoardHandler {
+	return &StatusBoardHandler{svc: svc}
+}
+
+// Snapshot returns the clinic's status board for a day.
+// @Summary Get clinic status board
+// @Description Returns every appointment of the day with its stage (expected, arrived, in session, done, cancelled, no-show) and counts per stage. Clients load it on connect and whenever the event stream asks them to resync.
+// @Tags status-board
+// @Produce json
+// @Param date query string false "Date at the clinic (YYYY-MM-DD), defaults to today"
+// @Success 200 {object} model.StatusBoard
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/status-board [get]
+func (h *StatusBoardHandler) Snapshot(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	var date *time.Time
+	if dateStr := c.QueryParam("date"); dateStr != "" {
+		d, err := time.Parse("2006-01-02", dateStr)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_request",
+				Message: "Invalid date format. Use YYYY-MM-DD",
+			})
+		}
+		date = &d
+	}
+
+	board, err := h.svc.StatusBoard().Snapshot(c.Request().Context(), user.ClinicID, date)
+	if err != nil {
+		return statusBoardError(c, err, "Clinic not found", "Failed to get status board")
+	}
+	return c.JSON(http.StatusOK, board)
+}
+
+// Events streams the clinic's status board changes as Server-Sent Events.
+// @Summary Stream clinic status board events
+// @Description Streams check-ins, session starts, completions, cancellations, no-shows and checklist progress as Server-Sent Events. Each event is named by its type and carries a JSON model.ClinicEvent; appointment events include the appointment's current board entry. The stream opens with a "ready" event, after which clients should load the snapshot. When the stream ends, clients reconnect and load the snapshot again, since events are not replayed. Clients that can send headers authenticate with a bearer token; browsers using EventSource pass a ticket from POST /api/v1/status-board/events/ticket instead.
+// @Tags status-board
+// @Produce text/event-stream
+// @Param ticket query string false "Stream ticket, for clients that cannot send an Authorization header"
+// @Success 200 {string} string
+// @Failure 401 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/status-board/events [get]
+func (h *StatusBoardHandler) Events(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	sub := h.svc.StatusBoard().Subscribe(user.ClinicID)
+	defer sub.Close()
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set("Cache-Control", "no-cache")
+	res.Header().Set("Connection", "keep-alive")
+	res.Header().Set("X-Accel-Buffering", "no") // stop nginx buffering the stream
+	res.WriteHeader(http.StatusOK)
+
+	if _, err := fmt.Fprint(res, "retry: 3000\nevent: ready\ndata: {}\n\n"); err != nil {
+		return nil
+	}
+	res.Flush()
+
+	heartbeat := time.NewTicker(statusBoardHeartbeat)
+	defer heartbeat.Stop()
+
+	ctx := c.Request().Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case payload, ok := <-sub.C:
+			if !ok {
+				// Closed on shutdown or after falling behind; the client
+				// reconnects and resyncs
+				return nil
+			}
+			if err := writeClinicEvent(res, payload); err != nil {
+				return nil
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(res, ": heartbeat\n\n"); err != nil {
+				return nil
+			}
+		}
+		res.Flush()
+	}
+}
+
+// StreamTicket issues a ticket for opening the event stream.
+// @Summary Get a status board stream ticket
+// @Description Returns a ticket for opening the status board event stream with a browser EventSource, which cannot send an Authorization header. Pass it as the ticket query parameter of GET /api/v1/status-board/events. The ticket must be used within a minute; an open stream stays open after it expires, and reconnecting needs a new ticket.
+// @Tags status-board
+// @Produce json
+// @Success 200 {object} model.StreamTicket
+// @Failure 401 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/status-board/events/ticket [post]
+func (h *StatusBoardHandler) StreamTicket(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	ticket := h.svc.StatusBoard().IssueStreamTicket(user.ClinicID, user.UserID)
+	c.Response().Header().Set("Cache-Control", "no-store")
+	return c.JSON(http.StatusOK, ticket)
+}
+
+// CheckIn records a patient's arrival at the front desk.
+// @Summary Check in a patient
+// @Description Records that the patient of a scheduled or confirmed appointment has arrived and updates the status board. Checking in again keeps the first check-in. Checked-in appointments are never marked as no-shows.
+// @Tags status-board
+// @Produce json
+// @Param id path string true "Appointment ID"
+// @Success 200 {object} model.StatusBoardEntry
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/appointments/{id}/check-in [post]
+func (h *StatusBoardHandler) CheckIn(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+	}
+
+	entry, err := h.svc.StatusBoard().CheckIn(c.Request().Context(), &model.AppointmentCheckIn{
+		AppointmentID: c.Param("id"),
+		ClinicID:      user.ClinicID,
+		Method:        model.CheckInFrontDesk,
+		CheckedInBy:   &user.UserID,
+	})
+	if err != nil {
+		return statusBoardError(c, err, "Appointment not found", "Failed to check in")
+	}
+	return c.JSON(http.StatusOK, entry)
+}
+
+// writeClinicEvent writes an encoded model.ClinicEvent as an SSE message
+// named by its type.
+func writeClinicEvent(res *echo.Response, payload []byte) error {
+	var head struct {
+		ID   string `json:"id"`
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(payload, &head); err != nil || head.Type == "" {
+		log.Warn().Err(err).Msg("skipping malformed clinic event")
+		return nil
+	}
+	_, err := fmt.Fprintf(res, "id: %s\nevent: %s\ndata: %s\n\n", head.ID, head.Type, payload)
+	return err
+}
+
+// statusBoardError maps status board service errors to responses.
+func statusBoardError(c echo.Context, err error, notFoundMsg, failureMsg string) error {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "not_found",
+			Message: notFoundMsg,
+		})
+	case errors.Is(err, repository.ErrInvalidInput):
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+	}
+
+	log.Error().Err(err).Str("path", c.Path()).Msg(strings.ToLower(failureMsg))
+	return c.JSON(http.StatusInternalServerError, ErrorResponse{
+		Error:   "internal_error",
+		Message: failureMsg,
+	})
+}
@@ -0,0 +1,278 @@
+// Package media reads the dimensions and duration of uploaded images and
+// videos and makes video thumbnails.
+//
+// MP4 and QuickTime files are read in pure Go. Other video formats, and
+// thumbnails, need ffprobe and ffmpeg, which are optional: without them
+// such uploads are stored without metadata or thumbnail.
+package media
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // register decoders for image.DecodeConfig
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+)
+
+// ErrUnavailable is returned when a file cannot be read without a tool that
+// is not installed.
+var ErrUnavailable = errors.New("media tool not available")
+
+// Info describes an image or video. Fields that could not be read are zero.
+type Info struct {
+	Width           int
+	Height          int
+	DurationSeconds float64
+}
+
+// ImageInfo reads the dimensions of a JPEG, PNG or GIF image.
+func ImageInfo(r io.Reader) (*Info, error) {
+	cfg, _, err := image.DecodeConfig(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+	return &Info{Width: cfg.Width, Height: cfg.Height}, nil
+}
+
+// MP4Info reads the duration and video dimensions of an MP4 or QuickTime
+// file from its moov box.
+func MP4Info(r io.ReadSeeker) (*Info, error) {
+	end, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read video: %w", err)
+	}
+
+	info := &Info{}
+	found := false
+	err = walkBoxes(r, 0, end, func(boxType string, body []byte) {
+		switch boxType {
+		case "mvhd":
+			if d, ok := mvhdDuration(body); ok {
+				info.DurationSeconds = d
+				found = true
+			}
+		case "tkhd":
+			// Width and height are the last two 16.16 fixed-point fields;
+			// audio tracks have zero
+			if len(body) >= 8 {
+				w := int(binary.BigEndian.Uint32(body[len(body)-8:]) >> 16)
+				h := int(binary.BigEndian.Uint32(body[len(body)-4:]) >> 16)
+				if w > info.Width {
+					info.Width, info.Height = w, h
+				}
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, errors.New("not an MP4 file: no movie header")
+	}
+	return info, nil
+}
+
+// containerBoxes are the boxes walked into on the way to mvhd and tkhd.
+var containerBoxes = map[string]bool{"moov": true, "trak": true}
+
+// walkBoxes calls fn with the body of every mvhd and tkhd box between start
+// and end, descending into moov and trak.
+func walkBoxes(r io.ReadSeeker, start, end int64, fn func(boxType string, body []byte)) error {
+	var header [16]byte
+	for pos := start; pos+8 <= end; {
+		if _, err := r.Seek(pos, io.SeekStart); err != nil {
+			return fmt.Errorf("failed to read video: %w", err)
+		}
+		if _, err := io.ReadFull(r, header[:8]); err != nil {
+			return fmt.Errorf("failed to read video: %w", err)
+		}
+
+		size := int64(binary.BigEndian.Uint32(header[:4]))
+		boxType := string(header[4:8])
+		headerSize := int64(8)
+		switch size {
+		case 0:
+			size = end - pos
+		case 1:
+			if _, err := io.ReadFull(r, header[8:16]); err != nil {
+				return fmt.Errorf("failed to read video: %w", err)
+			}
+			size = int64(binary.BigEndian.Uint64(header[8:16]))
+			headerSize = 16
+		}
+		if size < headerSize || pos+size > end {
+			return fmt.Errorf("not an MP4 file: bad %q box", boxType)
+		}
+
+		bodyStart, bodyEnd := pos+headerSize, pos+size
+		switch {
+		case containerBoxes[boxType]:
+			if err := walkBoxes(r, bodyStart, bodyEnd, fn); err != nil {
+				return err
+			}
+		case boxType == "mvhd" || boxType == "tkhd":
+			// Both are a little over 100 bytes
+			if bodyEnd-bodyStart > 1<<10 {
+				return fmt.Errorf("not an MP4 file: bad %q box", boxType)
+			}
+			body := make([]byte, bodyEnd-bodyStart)
+			if _, err := io.ReadFull(r, body); err != nil {
+				return fmt.Errorf("failed to read video: %w", err)
+			}
+			fn(boxType, body)
+		}
+		pos = bodyEnd
+	}
+	return nil
+}
+
+// mvhdDuration reads the duration in seconds from a movie header box body.
+func mvhdDuration(body []byte) (float64, bool) {
+	if len(body) < 1 {
+		return 0, false
+	}
+	var timescale uint32
+	var duration uint64
+	switch body[0] {
+	case 0:
+		// version, flags, creation and modification times, timescale, duration
+		if len(body) < 20 {
+			return 0, false
+		}
+		timescale = binary.BigEndian.Uint32(body[12:16])
+		duration = uint64(binary.BigEndian.Uint32(body[16:20]))
+	case 1:
+		if len(body) < 32 {
+			return 0, false
+		}
+		timescale = binary.BigEndian.Uint32(body[20:24])
+		duration = binary.BigEndian.Uint64(body[24:32])
+	default:
+		return 0, false
+	}
+	if timescale == 0 {
+		return 0, false
+	}
+	return float64(duration) / float64(timescale), true
+}
+
+// Prober reads video files, using ffprobe and ffmpeg when they are installed.
+type Prober struct {
+	ffprobe string
+	ffmpeg  string
+}
+
+// NewProber creates a prober using the given ffprobe and ffmpeg commands,
+// looked up on PATH. Either may be empty or missing.
+func NewProber(ffprobe, ffmpeg string) *Prober {
+	return &Prober{ffprobe: lookPath(ffprobe), ffmpeg: lookPath(ffmpeg)}
+}
+
+// lookPath resolves a command, returning "" when it is not installed.
+func lookPath(name string) string {
+	if name == "" {
+		return ""
+	}
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return ""
+	}
+	return path
+}
+
+// CanThumbnail reports whether ffmpeg is available for thumbnails.
+func (p *Prober) CanThumbnail() bool {
+	return p.ffmpeg != ""
+}
+
+// VideoInfo reads the duration and dimensions of a video file, in pure Go
+// for MP4 and QuickTime and otherwise with ffprobe.
+func (p *Prober) VideoInfo(ctx context.Context, path string) (*Info, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open video: %w", err)
+	}
+	info, mp4Err := MP4Info(f)
+	f.Close()
+	if mp4Err == nil {
+		return info, nil
+	}
+	if p.ffprobe == "" {
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, mp4Err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, p.ffprobe,
+		"-v", "error",
+		"-select_streams", "v:0",
+		"-show_entries", "format=duration:stream=width,height",
+		"-of", "json",
+		path,
+	).Output()
+	if err != nil {
+		return nil, fmt.Errorf("ffprobe failed: %w", err)
+	}
+
+	var probe struct {
+		Streams []struct {
+			Width  int `json:"width"`
+			Height int `json:"height"`
+		} `json:"streams"`
+		Format struct {
+			Duration string `json:"duration"`
+		} `json:"format"`
+	}
+	if err := json.Unmarshal(out, &probe); err != nil {
+		return nil, fmt.Errorf("failed to parse ffprobe output: %w", err)
+	}
+
+	info = &Info{}
+	if len(probe.Streams) > 0 {
+		info.Width, info.Height = probe.Streams[0].Width, probe.Streams[0].Height
+	}
+	if d, err := strconv.ParseFloat(probe.Format.Duration, 64); err == nil {
+		info.DurationSeconds = d
+	}
+	return info, nil
+}
+
+// Thumbnail renders a JPEG frame of a video at the given second, scaled to
+// at most width pixels wide. It needs ffmpeg.
+func (p *Prober) Thumbnail(ctx context.Context, path string, at float64, width int) ([]byte, error) {
+	if p.ffmpeg == "" {
+		return nil, ErrUnavailable
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.ffmpeg,
+		"-v", "error",
+		"-ss", strconv.FormatFloat(at, 'f', 2, 64),
+		"-i", path,
+		"-frames:v", "1",
+		"-vf", fmt.Sprintf("scale='min(%d,iw)':-2", width),
+		"-f", "image2",
+		"-c:v", "mjpeg",
+		"pipe:1",
+	)
+	cmd.Stdout = &out
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("ffmpeg failed: %w", err)
+	}
+	if out.Len() == 0 {
+		return nil, errors.New("ffmpeg produced no frame")
+	}
+	return out.Bytes(), nil
+}
@@ -0,0 +1,76 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/png"
+	"testing"
+)
+
+// box builds an MP4 box.
+func box(boxType string, body ...[]byte) []byte {
+	content := bytes.Join(body, nil)
+	b := make([]byte, 8, 8+len(content))
+	binary.BigEndian.PutUint32(b, uint32(8+len(content)))
+	copy(b[4:], boxType)
+	return append(b, content...)
+}
+
+// mvhd builds a version 0 movie header body.
+func mvhd(timescale, duration uint32) []byte {
+	body := make([]byte, 100)
+	binary.BigEndian.PutUint32(body[12:], timescale)
+	binary.BigEndian.PutUint32(body[16:], duration)
+	return body
+}
+
+// tkhd builds a version 0 track header body.
+func tkhd(width, height uint32) []byte {
+	body := make([]byte, 84)
+	binary.BigEndian.PutUint32(body[76:], width<<16)
+	binary.BigEndian.PutUint32(body[80:], height<<16)
+	return body
+}
+
+func TestMP4Info(t *testing.T) {
+	file := bytes.Join([][]byte{
+		box("ftyp", []byte("isom\x00\x00\x02\x00")),
+		box("mdat", make([]byte, 64)),
+		box("moov",
+			box("mvhd", mvhd(1000, 12500)),
+			box("trak", box("tkhd", tkhd(0, 0))), // audio
+			box("trak", box("tkhd", tkhd(1280, 720))),
+		),
+	}, nil)
+
+	info, err := MP4Info(bytes.NewReader(file))
+	if err != nil {
+		t.Fatalf("MP4Info() error = %v", err)
+	}
+	if info.DurationSeconds != 12.5 || info.Width != 1280 || info.Height != 720 {
+		t.Errorf("MP4Info() = %+v; want 12.5s at 1280x720", info)
+	}
+
+	if _, err := MP4Info(bytes.NewReader([]byte("\x1aE\xdf\xa3 not an mp4 file"))); err == nil {
+		t.Error("MP4Info() of a WebM file: want error")
+	}
+	if _, err := MP4Info(bytes.NewReader(box("moov", []byte{0, 0, 0, 99, 'm', 'v', 'h', 'd'}))); err == nil {
+		t.Error("MP4Info() with a truncated box: want error")
+	}
+}
+
+func TestImageInfo(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 64, 48))); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := ImageInfo(&buf)
+	if err != nil {
+		t.Fatalf("ImageInfo() error = %v", err)
+	}
+	if info.Width != 64 || info.Height != 48 {
+		t.Errorf("ImageInfo() = %+v; want 64x48", info)
+	}
+}
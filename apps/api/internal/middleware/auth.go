@@ -25,6 +25,7 @@ const (
 	RoleAssistant  = "assistant"
 	RoleFrontDesk  = "front_desk"
 	RolePatient    = "patient"
+	RoleCaregiver  = "caregiver"
 )
 
 // AuthClaims represents the claims extracted from JWT token.
@@ -371,7 +372,7 @@ func extractRoles(raw map[string]any) []string {
 				if s, ok := role.(string); ok {
 					// Filter to known roles
 					switch s {
-					case RoleSuperAdmin, RoleClinicAdmin, RoleTherapist, RoleAssistant, RoleFrontDesk, RolePatient:
+					case RoleSuperAdmin, RoleClinicAdmin, RoleTherapist, RoleAssistant, RoleFrontDesk, RolePatient, RoleCaregiver:
 						roles = append(roles, s)
 					}
 				}
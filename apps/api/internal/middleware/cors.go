@@ -34,6 +34,7 @@ func CORS(cfg *config.Config) echo.MiddlewareFunc {
 				echo.HeaderAuthorization,
 				echo.HeaderXRequestID,
 				"X-Tenant-ID",
+				ProxyPatientHeader,
 			},
 			ExposeHeaders: []string{
 				echo.HeaderContentLength,
@@ -65,6 +66,7 @@ func CORS(cfg *config.Config) echo.MiddlewareFunc {
 			echo.HeaderAccept,
 			echo.HeaderAuthorization,
 			"X-Tenant-ID",
+			ProxyPatientHeader,
 		},
 		ExposeHeaders: []string{
 			"X-Total-Count",
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+)
+
+// KioskAuthenticator resolves kiosk device tokens.
+type KioskAuthenticator interface {
+	// AuthenticateKiosk returns the active device holding the token, or nil if there is none.
+	AuthenticateKiosk(ctx context.Context, token string) (*model.KioskDevice, error)
+}
+
+// KioskAuth returns a middleware that authenticates kiosk devices by the
+// device token in the Authorization header. Kiosk tokens are a separate
+// credential from user JWTs: they are only accepted on kiosk routes and only
+// permit self check-in for the device's clinic.
+func KioskAuth(authn KioskAuthenticator) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			authHeader := c.Request().Header.Get("Authorization")
+			parts := strings.Split(authHeader, " ")
+			if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" || parts[1] == "" {
+				return c.JSON(http.StatusUnauthorized, map[string]interface{}{
+					"error":   "unauthorized",
+					"message": "Missing kiosk token",
+				})
+			}
+
+			device, err := authn.AuthenticateKiosk(c.Request().Context(), parts[1])
+			if err != nil {
+				log.Error().Err(err).Msg("failed to authenticate kiosk")
+				return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+					"error":   "internal_error",
+					"message": "Failed to verify kiosk",
+				})
+			}
+			if device == nil {
+				return c.JSON(http.StatusUnauthorized, map[string]interface{}{
+					"error":   "unauthorized",
+					"message": "Invalid or revoked kiosk token",
+				})
+			}
+
+			c.Set("kiosk", device)
+			return next(c)
+		}
+	}
+}
+
+// GetKiosk retrieves the kiosk device making the request, if any.
+func GetKiosk(c echo.Context) *model.KioskDevice {
+	if device, ok := c.Get("kiosk").(*model.KioskDevice); ok {
+		return device
+	}
+	return nil
+}
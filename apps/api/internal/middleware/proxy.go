@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+)
+
+// ProxyPatientHeader names the patient a caregiver is acting for.
+const ProxyPatientHeader = "X-Proxy-Patient-ID"
+
+// ProxyAuthorizer resolves caregiver grants and records the actions taken under them.
+type ProxyAuthorizer interface {
+	// ResolveGrant returns the active grant for the caregiver and patient, or nil if there is none.
+	ResolveGrant(ctx context.Context, proxyUserID, patientID string) (*model.ProxyGrant, error)
+	RecordAction(ctx context.Context, entry *model.AuditLog) error
+}
+
+// ProxyAccess returns a middleware that evaluates caregiver grants. When the
+// request names a patient in ProxyPatientHeader, the caller must hold an active
+// grant for that patient; the grant is stored on the context and the action is
+// recorded in the audit trail against both the caregiver and the patient.
+// Requests without the header pass through unchanged.
+func ProxyAccess(authz ProxyAuthorizer) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			patientID := c.Request().Header.Get(ProxyPatientHeader)
+			if patientID == "" {
+				return next(c)
+			}
+
+			user := GetUser(c)
+			if user == nil {
+				return c.JSON(http.StatusUnauthorized, map[string]interface{}{
+					"error":   "unauthorized",
+					"message": "User not authenticated",
+				})
+			}
+
+			ctx := c.Request().Context()
+			grant, err := authz.ResolveGrant(ctx, user.UserID, patientID)
+			if err != nil {
+				log.Error().Err(err).Str("user_id", user.UserID).Str("patient_id", patientID).Msg("failed to resolve proxy grant")
+				return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+					"error":   "internal_error",
+					"message": "Failed to verify proxy access",
+				})
+			}
+			if grant == nil {
+				return c.JSON(http.StatusForbidden, map[string]interface{}{
+					"error":   "forbidden",
+					"message": "No active proxy access for this patient",
+				})
+			}
+
+			c.Set("proxy_grant", grant)
+
+			err = next(c)
+
+			entry := &model.AuditLog{
+				ClinicID:     &grant.ClinicID,
+				ActorID:      user.UserID,
+				PatientID:    &grant.PatientID,
+				ProxyGrantID: &grant.ID,
+				Action:       c.Request().Method + " " + c.Path(),
+				ResourceID:   c.Param("id"),
+				StatusCode:   c.Response().Status,
+				IPAddress:    c.RealIP(),
+			}
+			if recErr := authz.RecordAction(ctx, entry); recErr != nil {
+				log.Error().Err(recErr).Str("grant_id", grant.ID).Msg("failed to record proxy action")
+			}
+
+			return err
+		}
+	}
+}
+
+// GetProxyGrant retrieves the caregiver grant the request is acting under, if any.
+func GetProxyGrant(c echo.Context) *model.ProxyGrant {
+	if grant, ok := c.Get("proxy_grant").(*model.ProxyGrant); ok {
+		return grant
+	}
+	return nil
+}
+
+// RequireProxyScope returns a middleware that, for requests made under a proxy
+// grant, requires the grant to include the scope. Patients acting for
+// themselves are not affected.
+func RequireProxyScope(scope model.ProxyScope) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			grant := GetProxyGrant(c)
+			if grant != nil && !grant.HasScope(scope) {
+				return c.JSON(http.StatusForbidden, map[string]interface{}{
+					"error":   "forbidden",
+					"message": "Proxy access does not permit this action",
+				})
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/config"
+)
+
+// StreamTicketVerifier resolves event stream tickets.
+type StreamTicketVerifier interface {
+	// VerifyStreamTicket returns the clinic and user a ticket was issued to.
+	VerifyStreamTicket(ticket string) (clinicID, userID string, err error)
+}
+
+// StreamAuth returns a middleware for Server-Sent Event streams. Browsers'
+// EventSource cannot send an Authorization header, so a short-lived ticket in
+// the "ticket" query parameter is accepted instead of a bearer token. Tickets
+// are only issued to staff, and grant nothing but the stream they are used on.
+// Requests without a ticket must carry a staff bearer token.
+func StreamAuth(cfg *config.Config, tickets StreamTicketVerifier) echo.MiddlewareFunc {
+	bearer := Auth(cfg)
+	staff := RequireStaff()
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		withBearer := bearer(staff(next))
+
+		return func(c echo.Context) error {
+			ticket := c.QueryParam("ticket")
+			if ticket == "" {
+				return withBearer(c)
+			}
+
+			clinicID, userID, err := tickets.VerifyStreamTicket(ticket)
+			if err != nil {
+				return c.JSON(http.StatusUnauthorized, map[string]interface{}{
+					"error":   "unauthorized",
+					"message": "Invalid or expired stream ticket",
+				})
+			}
+
+			c.Set("user", &AuthClaims{UserID: userID, ClinicID: clinicID})
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package model
+
+import "time"
+
+// AdherenceAlertStatus represents the state of a low adherence alert.
+type AdherenceAlertStatus string
+
+const (
+	AdherenceAlertOpen         AdherenceAlertStatus = "open"
+	AdherenceAlertAcknowledged AdherenceAlertStatus = "acknowledged"
+	// AdherenceAlertResolved is set when the patient's adherence recovers
+	// before the week is over.
+	AdherenceAlertResolved AdherenceAlertStatus = "resolved"
+)
+
+// PainTrendDirection summarizes how a patient's reported pain is changing.
+type PainTrendDirection string
+
+const (
+	PainTrendImproving    PainTrendDirection = "improving"
+	PainTrendStable       PainTrendDirection = "stable"
+	PainTrendWorsening    PainTrendDirection = "worsening"
+	PainTrendInsufficient PainTrendDirection = "insufficient_data"
+)
+
+// AdherencePolicy holds the clinic rules for low adherence alerts, stored in
+// the clinic settings under exercise_adherence.
+type AdherencePolicy struct {
+	// Threshold is the adherence, from 0 to 1, below which a patient is
+	// alerted on.
+	Threshold float64 `json:"threshold"`
+	// MinExpectedSessions keeps patients with very few expected sessions in
+	// the week, e.g. a prescription that just started, from being alerted on.
+	MinExpectedSessions float64 `json:"min_expected_sessions"`
+	// NudgePatients sends alerted patients a reminder to do their exercises.
+	NudgePatients bool `json:"nudge_patients"`
+}
+
+// DefaultAdherencePolicy returns the policy used when a clinic has not configured one.
+func DefaultAdherencePolicy() AdherencePolicy {
+	return AdherencePolicy{
+		Threshold:           0.6,
+		MinExpectedSessions: 3,
+	}
+}
+
+// AdherenceWindow compares the sessions a patient completed in a period with
+// the sessions their prescribed frequency called for. Adherence is nil when
+// no sessions were expected.
+type AdherenceWindow struct {
+	Start             time.Time `json:"start"`
+	End               time.Time `json:"end"`
+	ExpectedSessions  float64   `json:"expected_sessions"`
+	CompletedSessions int       `json:"completed_sessions"`
+	Adherence         *float64  `json:"adherence,omitempty"`
+	AvgPain           *float64  `json:"avg_pain,omitempty"`
+}
+
+// PainTrend describes the change in the pain a patient reported for an
+// exercise. SlopePerWeek is the least squares change in pain level per week.
+type PainTrend struct {
+	Direction    PainTrendDirection `json:"direction"`
+	SlopePerWeek *float64           `json:"slope_per_week,omitempty"`
+	FirstPain    *int               `json:"first_pain,omitempty"`
+	LastPain     *int               `json:"last_pain,omitempty"`
+	RatedLogs    int                `json:"rated_logs"`
+}
+
+// ExerciseAdherence is the adherence and pain trend of one prescription.
+// SessionsPerWeek is nil when the prescription's frequency could not be
+// understood; such prescriptions are left out of expected sessions.
+type ExerciseAdherence struct {
+	PrescriptionID    string             `json:"prescription_id"`
+	ExerciseID        string             `json:"exercise_id"`
+	ExerciseName      string             `json:"exercise_name"`
+	ExerciseNameVi    string             `json:"exercise_name_vi,omitempty"`
+	Frequency         string             `json:"frequency"`
+	FrequencySchedule *FrequencySchedule `json:"frequency_schedule,omitempty"`
+	SessionsPerWeek   *float64           `json:"sessions_per_week,omitempty"`
+	Windows           []AdherenceWindow  `json:"windows"`
+	Overall           AdherenceWindow    `json:"overall"`
+	PainTrend         PainTrend          `json:"pain_trend"`
+}
+
+// PatientAdherence is a patient's exercise adherence over consecutive
+// windows, oldest first, across all their prescriptions and per exercise.
+type PatientAdherence struct {
+	PatientID  string              `json:"patient_id"`
+	From       time.Time           `json:"from"`
+	To         time.Time           `json:"to"`
+	WindowDays int                 `json:"window_days"`
+	Windows    []AdherenceWindow   `json:"windows"`
+	Overall    AdherenceWindow     `json:"overall"`
+	Exercises  []ExerciseAdherence `json:"exercises"`
+}
+
+// LowAdherencePatient is a patient whose adherence over the past week is
+// below the clinic threshold.
+type LowAdherencePatient struct {
+	PatientID         string    `json:"patient_id"`
+	PatientName       string    `json:"patient_name"`
+	WindowStart       time.Time `json:"window_start"`
+	WindowEnd         time.Time `json:"window_end"`
+	ExpectedSessions  float64   `json:"expected_sessions"`
+	CompletedSessions int       `json:"completed_sessions"`
+	Adherence         float64   `json:"adherence"`
+	// PreviousAdherence is the adherence over the week before, if any
+	// sessions were expected then.
+	PreviousAdherence *float64 `json:"previous_adherence,omitempty"`
+	// TherapistIDs are the therapists who prescribed the exercises.
+	TherapistIDs []string `json:"therapist_ids"`
+}
+
+// AdherenceAlert records a patient's low adherence in a week for the
+// therapists' dashboard. There is at most one alert per patient and week.
+type AdherenceAlert struct {
+	ID                string               `json:"id" db:"id"`
+	ClinicID          string               `json:"clinic_id" db:"clinic_id"`
+	PatientID         string               `json:"patient_id" db:"patient_id"`
+	PatientName       string               `json:"patient_name" db:"patient_name"`
+	WeekStart         time.Time            `json:"week_start" db:"week_start"`
+	WindowStart       time.Time            `json:"window_start" db:"window_start"`
+	WindowEnd         time.Time            `json:"window_end" db:"window_end"`
+	ExpectedSessions  float64              `json:"expected_sessions" db:"expected_sessions"`
+	CompletedSessions int                  `json:"completed_sessions" db:"completed_sessions"`
+	Adherence         float64              `json:"adherence" db:"adherence"`
+	PreviousAdherence *float64             `json:"previous_adherence,omitempty" db:"previous_adherence"`
+	Threshold         float64              `json:"threshold" db:"threshold"`
+	TherapistIDs      []string             `json:"therapist_ids" db:"therapist_ids"`
+	Status            AdherenceAlertStatus `json:"status" db:"status"`
+	NudgedAt          *time.Time           `json:"nudged_at,omitempty" db:"nudged_at"`
+	AcknowledgedAt    *time.Time           `json:"acknowledged_at,omitempty" db:"acknowledged_at"`
+	AcknowledgedBy    *string              `json:"acknowledged_by,omitempty" db:"acknowledged_by"`
+	CreatedAt         time.Time            `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time            `json:"updated_at" db:"updated_at"`
+}
+
+// AdherenceAlertParams filters the clinic's adherence alerts.
+type AdherenceAlertParams struct {
+	ClinicID    string
+	TherapistID string
+	Status      string
+}
@@ -1,6 +1,9 @@
 package model
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 // AppointmentStatus represents the status of an appointment.
 type AppointmentStatus string
@@ -51,12 +54,25 @@ type Appointment struct {
 	Notes              string            `json:"notes,omitempty" db:"notes"`
 	CancellationReason string            `json:"cancellation_reason,omitempty" db:"cancellation_reason"`
 	RecurrenceID       *string           `json:"recurrence_id,omitempty" db:"recurrence_id"`
+	ResourceIDs        []string          `json:"resource_ids" db:"resource_ids"` // rooms and equipment the appointment requires
+	GroupSessionID     *string           `json:"group_session_id,omitempty" db:"group_session_id"`
+	AppointmentTypeID  *string           `json:"appointment_type_id,omitempty" db:"appointment_type_id"` // the clinic's configured type with the code in Type
 	CreatedAt          time.Time         `json:"created_at" db:"created_at"`
 	UpdatedAt          time.Time         `json:"updated_at" db:"updated_at"`
 	CreatedBy          *string           `json:"created_by,omitempty" db:"created_by"`
 	UpdatedBy          *string           `json:"updated_by,omitempty" db:"updated_by"`
 }
 
+// UsesResource reports whether the appointment requires the resource.
+func (a Appointment) UsesResource(resourceID string) bool {
+	for _, id := range a.ResourceIDs {
+		if id == resourceID {
+			return true
+		}
+	}
+	return false
+}
+
 // AppointmentWithDetails includes patient and therapist information.
 type AppointmentWithDetails struct {
 	Appointment
@@ -64,33 +80,42 @@ type AppointmentWithDetails struct {
 	PatientMRN     string `json:"patient_mrn" db:"patient_mrn"`
 	PatientPhone   string `json:"patient_phone,omitempty" db:"patient_phone"`
 	TherapistName  string `json:"therapist_name" db:"therapist_name"`
+
+	// PatientAttendance holds the patient's no-show counters, where loaded.
+	PatientAttendance *PatientAttendance `json:"patient_attendance,omitempty" db:"-"`
 }
 
 // TherapistSchedule represents a therapist's regular working schedule.
 type TherapistSchedule struct {
-	ID          string    `json:"id" db:"id"`
-	ClinicID    string    `json:"clinic_id" db:"clinic_id"`
-	TherapistID string    `json:"therapist_id" db:"therapist_id"`
-	DayOfWeek   int       `json:"day_of_week" db:"day_of_week"` // 0=Sunday, 6=Saturday
-	StartTime   string    `json:"start_time" db:"start_time"`   // "HH:MM" format
-	EndTime     string    `json:"end_time" db:"end_time"`       // "HH:MM" format
-	IsActive    bool      `json:"is_active" db:"is_active"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	ID            string     `json:"id" db:"id"`
+	ClinicID      string     `json:"clinic_id" db:"clinic_id"`
+	TherapistID   string     `json:"therapist_id" db:"therapist_id"`
+	DayOfWeek     int        `json:"day_of_week" db:"day_of_week"` // 0=Sunday, 6=Saturday
+	StartTime     string     `json:"start_time" db:"start_time"`   // "HH:MM" format
+	EndTime       string     `json:"end_time" db:"end_time"`       // "HH:MM" format
+	EffectiveFrom time.Time  `json:"effective_from" db:"effective_from"`
+	EffectiveTo   *time.Time `json:"effective_to,omitempty" db:"effective_to"` // nil for ongoing
+	IsActive      bool       `json:"is_active" db:"is_active"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+	CreatedBy     *string    `json:"created_by,omitempty" db:"created_by"`
 }
 
 // ScheduleException represents a one-time exception to a therapist's schedule.
 type ScheduleException struct {
-	ID          string     `json:"id" db:"id"`
-	ClinicID    string     `json:"clinic_id" db:"clinic_id"`
-	TherapistID string     `json:"therapist_id" db:"therapist_id"`
-	Date        time.Time  `json:"date" db:"date"`
-	StartTime   *string    `json:"start_time,omitempty" db:"start_time"` // nil means entire day off
-	EndTime     *string    `json:"end_time,omitempty" db:"end_time"`
-	IsAvailable bool       `json:"is_available" db:"is_available"` // false = blocked, true = extra availability
-	Reason      string     `json:"reason,omitempty" db:"reason"`
-	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+	ID          string        `json:"id" db:"id"`
+	ClinicID    string        `json:"clinic_id" db:"clinic_id"`
+	TherapistID string        `json:"therapist_id" db:"therapist_id"`
+	Date        time.Time     `json:"date" db:"exception_date"`
+	StartTime   *string       `json:"start_time,omitempty" db:"start_time"` // nil means entire day off
+	EndTime     *string       `json:"end_time,omitempty" db:"end_time"`
+	Type        ExceptionType `json:"exception_type" db:"exception_type"`
+	IsAvailable bool          `json:"is_available" db:"is_available"` // false = blocked, true = extra availability
+	Reason      string        `json:"reason,omitempty" db:"reason"`
+	Notes       string        `json:"notes,omitempty" db:"notes"`
+	CreatedAt   time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at" db:"updated_at"`
+	CreatedBy   *string       `json:"created_by,omitempty" db:"created_by"`
 }
 
 // TimeRange represents a time range.
@@ -113,24 +138,43 @@ type CreateAppointmentRequest struct {
 	PatientID         string            `json:"patient_id" validate:"required,uuid"`
 	TherapistID       string            `json:"therapist_id" validate:"required,uuid"`
 	StartTime         string            `json:"start_time" validate:"required,datetime=2006-01-02T15:04:05Z07:00"`
-	Duration          int               `json:"duration" validate:"required,min=15,max=240"`
-	Type              string            `json:"type" validate:"required,oneof=assessment treatment followup consultation other"`
+	Duration          int               `json:"duration" validate:"omitempty,min=15,max=240"` // defaults to the type's duration
+	Type              string            `json:"type" validate:"required_without=AppointmentTypeID,omitempty,max=50"`
+	AppointmentTypeID string            `json:"appointment_type_id" validate:"omitempty,uuid"` // takes precedence over type
 	Room              string            `json:"room" validate:"max=100"`
 	Notes             string            `json:"notes" validate:"max=1000"`
 	RecurrencePattern string            `json:"recurrence_pattern" validate:"omitempty,oneof=none daily weekly biweekly monthly"`
 	RecurrenceEndDate *string           `json:"recurrence_end_date" validate:"omitempty,datetime=2006-01-02"`
-	RecurrenceCount   *int              `json:"recurrence_count" validate:"omitempty,min=1,max=52"`
+	RecurrenceCount   *int              `json:"recurrence_count" validate:"omitempty,min=1,max=259"`
+	ResourceIDs       []string          `json:"resource_ids" validate:"omitempty,max=10,dive,uuid"`
+
+	// RRule is an RFC 5545 recurrence rule, e.g. "FREQ=WEEKLY;BYDAY=MO,WE,FR;COUNT=18".
+	// It takes precedence over RecurrencePattern. ExDates are RFC 3339 times or
+	// dates of occurrences to leave out.
+	RRule           string               `json:"rrule" validate:"omitempty,max=500"`
+	ExDates         []string             `json:"exdates" validate:"omitempty,max=260,dive,datetime=2006-01-02T15:04:05Z07:00|datetime=2006-01-02"`
+	Overrides       []OccurrenceOverride `json:"overrides" validate:"omitempty,max=260,dive"`
+	AcceptConflicts bool                 `json:"accept_conflicts"` // skip conflicting occurrences instead of failing
+}
+
+// IsRecurring reports whether the request creates a recurring series.
+func (r CreateAppointmentRequest) IsRecurring() bool {
+	return r.RRule != "" || (r.RecurrencePattern != "" && r.RecurrencePattern != string(RecurrenceNone))
 }
 
 // UpdateAppointmentRequest represents the request body for updating an appointment.
 type UpdateAppointmentRequest struct {
 	StartTime  *string `json:"start_time" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
 	Duration   *int    `json:"duration" validate:"omitempty,min=15,max=240"`
-	Type       *string `json:"type" validate:"omitempty,oneof=assessment treatment followup consultation other"`
+	Type       *string `json:"type" validate:"omitempty,min=1,max=50"`
 	Status     *string `json:"status" validate:"omitempty,oneof=scheduled confirmed in_progress completed cancelled no_show"`
 	Room       *string `json:"room" validate:"omitempty,max=100"`
 	Notes      *string `json:"notes" validate:"omitempty,max=1000"`
 	TherapistID *string `json:"therapist_id" validate:"omitempty,uuid"`
+
+	// ResourceIDs replaces the required resources when present; an empty
+	// list removes them all.
+	ResourceIDs []string `json:"resource_ids" validate:"omitempty,max=10,dive,uuid"`
 }
 
 // CancelAppointmentRequest represents the request body for canceling an appointment.
@@ -200,6 +244,7 @@ type AvailabilityRequest struct {
 // DaySchedule represents all appointments for a specific day.
 type DaySchedule struct {
 	Date         string                    `json:"date"`
+	Timezone     string                    `json:"timezone"` // clinic time zone the day is taken in
 	Appointments []AppointmentWithDetails  `json:"appointments"`
 	TotalCount   int                       `json:"total_count"`
 }
@@ -214,11 +259,32 @@ type Therapist struct {
 	Specialty string `json:"specialty,omitempty"`
 	AvatarURL string `json:"avatar_url,omitempty"`
 	IsActive  bool   `json:"is_active"`
+
+	Specializations []string `json:"specializations,omitempty"`
+}
+
+// HasSpecializations reports whether the therapist has every one of the given
+// specializations, compared case-insensitively.
+func (t Therapist) HasSpecializations(required []string) bool {
+	for _, r := range required {
+		found := false
+		for _, s := range t.Specializations {
+			if strings.EqualFold(strings.TrimSpace(s), strings.TrimSpace(r)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
 }
 
 // ConflictInfo represents information about a scheduling conflict.
 type ConflictInfo struct {
-	ConflictType string      `json:"conflict_type"` // "overlap", "outside_hours", "exception"
+	ConflictType string      `json:"conflict_type"` // "overlap", "outside_hours", "exception", "resource"
 	Message      string      `json:"message"`
 	Appointment  *Appointment `json:"appointment,omitempty"`
+	ResourceID   string      `json:"resource_id,omitempty"`
 }
@@ -0,0 +1,82 @@
+package model
+
+import "time"
+
+// AppointmentTypeConfig is a clinic's configuration of an appointment type,
+// stored in appointment_types. Appointments record the type's code.
+type AppointmentTypeConfig struct {
+	ID                  string    `json:"id" db:"id"`
+	ClinicID            string    `json:"clinic_id" db:"clinic_id"`
+	Name                string    `json:"name" db:"name"`
+	NameVi              string    `json:"name_vi,omitempty" db:"name_vi"`
+	Code                string    `json:"code" db:"code"`
+	Description         string    `json:"description,omitempty" db:"description"`
+	DescriptionVi       string    `json:"description_vi,omitempty" db:"description_vi"`
+	DefaultDuration     int       `json:"default_duration" db:"default_duration_minutes"`
+	Color               string    `json:"color,omitempty" db:"color"`
+	Icon                string    `json:"icon,omitempty" db:"icon"`
+	RequiresEvaluation  bool      `json:"requires_evaluation" db:"requires_evaluation"`
+	IsEvaluation        bool      `json:"is_evaluation" db:"is_evaluation"`
+	AllowsGroup         bool      `json:"allows_group" db:"allows_group"`
+	MaxGroupSize        int       `json:"max_group_size" db:"max_group_size"`
+	DefaultBillingCodes []string  `json:"default_billing_codes" db:"default_billing_codes"`
+	IsActive            bool      `json:"is_active" db:"is_active"`
+	SortOrder           int       `json:"sort_order" db:"sort_order"`
+	CreatedAt           time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// DefaultAppointmentTypes returns the built-in appointment types, used by
+// clinics that have not configured their own. Migration
+// 013_appointment_type_config.sql seeds the same types for existing clinics.
+func DefaultAppointmentTypes() []AppointmentTypeConfig {
+	return []AppointmentTypeConfig{
+		{Code: string(AppointmentTypeAssessment), Name: "Assessment", NameVi: "Đánh giá", DefaultDuration: 60, IsEvaluation: true, MaxGroupSize: 1, IsActive: true, SortOrder: 1},
+		{Code: string(AppointmentTypeTreatment), Name: "Treatment", NameVi: "Điều trị", DefaultDuration: 45, MaxGroupSize: 1, IsActive: true, SortOrder: 2},
+		{Code: string(AppointmentTypeFollowUp), Name: "Follow-up", NameVi: "Tái khám", DefaultDuration: 30, MaxGroupSize: 1, IsActive: true, SortOrder: 3},
+		{Code: string(AppointmentTypeConsultation), Name: "Consultation", NameVi: "Tư vấn", DefaultDuration: 30, MaxGroupSize: 1, IsActive: true, SortOrder: 4},
+		{Code: string(AppointmentTypeOther), Name: "Other", NameVi: "Khác", DefaultDuration: 30, MaxGroupSize: 1, IsActive: true, SortOrder: 5},
+	}
+}
+
+// CreateAppointmentTypeRequest represents the request body for adding an
+// appointment type to a clinic. The code is what appointments record and
+// cannot be changed later.
+type CreateAppointmentTypeRequest struct {
+	Name                string   `json:"name" validate:"required,max=255"`
+	NameVi              string   `json:"name_vi" validate:"max=255"`
+	Code                string   `json:"code" validate:"required,max=50"`
+	Description         string   `json:"description" validate:"max=2000"`
+	DescriptionVi       string   `json:"description_vi" validate:"max=2000"`
+	DefaultDuration     int      `json:"default_duration" validate:"required,min=15,max=240"`
+	Color               string   `json:"color" validate:"omitempty,hexcolor"`
+	Icon                string   `json:"icon" validate:"max=50"`
+	RequiresEvaluation  bool     `json:"requires_evaluation"`
+	IsEvaluation        bool     `json:"is_evaluation"`
+	AllowsGroup         bool     `json:"allows_group"`
+	MaxGroupSize        int      `json:"max_group_size" validate:"omitempty,min=1,max=50"`
+	DefaultBillingCodes []string `json:"default_billing_codes" validate:"omitempty,max=20,dive,required,max=20"`
+	SortOrder           int      `json:"sort_order" validate:"min=0"`
+}
+
+// UpdateAppointmentTypeRequest represents the request body for changing an
+// appointment type.
+type UpdateAppointmentTypeRequest struct {
+	Name               *string `json:"name" validate:"omitempty,min=1,max=255"`
+	NameVi             *string `json:"name_vi" validate:"omitempty,max=255"`
+	Description        *string `json:"description" validate:"omitempty,max=2000"`
+	DescriptionVi      *string `json:"description_vi" validate:"omitempty,max=2000"`
+	DefaultDuration    *int    `json:"default_duration" validate:"omitempty,min=15,max=240"`
+	Color              *string `json:"color" validate:"omitempty,hexcolor"`
+	Icon               *string `json:"icon" validate:"omitempty,max=50"`
+	RequiresEvaluation *bool   `json:"requires_evaluation"`
+	IsEvaluation       *bool   `json:"is_evaluation"`
+	AllowsGroup        *bool   `json:"allows_group"`
+	MaxGroupSize       *int    `json:"max_group_size" validate:"omitempty,min=1,max=50"`
+	IsActive           *bool   `json:"is_active"`
+	SortOrder          *int    `json:"sort_order" validate:"omitempty,min=0"`
+
+	// DefaultBillingCodes replaces the billing codes when present; an empty
+	// list removes them all.
+	DefaultBillingCodes []string `json:"default_billing_codes" validate:"omitempty,max=20,dive,required,max=20"`
+}
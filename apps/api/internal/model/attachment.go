@@ -0,0 +1,38 @@
+package model
+
+import "time"
+
+// AttachmentCategory classifies a file attached to a patient record.
+type AttachmentCategory string
+
+const (
+	AttachmentCategoryDocument AttachmentCategory = "document"
+	AttachmentCategoryPhoto    AttachmentCategory = "photo"
+	AttachmentCategoryImaging  AttachmentCategory = "imaging"
+	AttachmentCategoryReferral AttachmentCategory = "referral"
+	AttachmentCategoryOther    AttachmentCategory = "other"
+)
+
+// PatientAttachment represents a file attached to a patient record.
+// The file content lives in the blob store under BlobKey.
+type PatientAttachment struct {
+	ID          string             `json:"id" db:"id"`
+	ClinicID    string             `json:"clinic_id" db:"clinic_id"`
+	PatientID   string             `json:"patient_id" db:"patient_id"`
+	Category    AttachmentCategory `json:"category" db:"category"`
+	FileName    string             `json:"file_name" db:"file_name"`
+	ContentType string             `json:"content_type" db:"content_type"`
+	SizeBytes   int64              `json:"size_bytes" db:"size_bytes"`
+	SHA256      string             `json:"sha256" db:"sha256"`
+	BlobKey     string             `json:"-" db:"blob_key"`
+	Description string             `json:"description,omitempty" db:"description"`
+	UploadedBy  *string            `json:"uploaded_by,omitempty" db:"uploaded_by"`
+	CreatedAt   time.Time          `json:"created_at" db:"created_at"`
+	DeletedAt   *time.Time         `json:"deleted_at,omitempty" db:"deleted_at"`
+}
+
+// CreateAttachmentRequest represents the form fields sent with an attachment upload.
+type CreateAttachmentRequest struct {
+	Category    string `form:"category" validate:"required,oneof=document photo imaging referral other"`
+	Description string `form:"description" validate:"max=1000"`
+}
@@ -0,0 +1,77 @@
+package model
+
+import "time"
+
+// NoShowAction is what a clinic's no-show policy does once a patient reaches
+// its threshold.
+type NoShowAction string
+
+const (
+	NoShowActionWarn    NoShowAction = "warn"
+	NoShowActionDeposit NoShowAction = "deposit"
+	NoShowActionBlock   NoShowAction = "block"
+)
+
+// AttendanceStanding is a patient's standing under the clinic's no-show policy.
+type AttendanceStanding string
+
+const (
+	AttendanceStandingGood            AttendanceStanding = "good"
+	AttendanceStandingWarned          AttendanceStanding = "warned"
+	AttendanceStandingDepositRequired AttendanceStanding = "deposit_required"
+	AttendanceStandingBlocked         AttendanceStanding = "blocked"
+)
+
+// NoShowPolicy holds the clinic rules for missed appointments, stored in the
+// clinic settings under no_show_policy.
+type NoShowPolicy struct {
+	// GracePeriodMinutes is how long after its start an appointment nobody
+	// checked in for is marked as a no-show.
+	GracePeriodMinutes int `json:"grace_period_minutes"`
+	// LateCancelNoticeHours is the notice a cancellation needs not to count
+	// as late.
+	LateCancelNoticeHours int `json:"late_cancel_notice_hours"`
+	// Threshold is the number of no-shows at which Action applies; 0 turns
+	// enforcement off.
+	Threshold int          `json:"threshold"`
+	Action    NoShowAction `json:"action"`
+	// LookbackDays limits the counters to recent appointments; 0 counts the
+	// patient's whole history.
+	LookbackDays int `json:"lookback_days"`
+}
+
+// DefaultNoShowPolicy returns the policy used when a clinic has not configured one.
+func DefaultNoShowPolicy() NoShowPolicy {
+	return NoShowPolicy{
+		GracePeriodMinutes:    15,
+		LateCancelNoticeHours: 24,
+		Threshold:             3,
+		Action:                NoShowActionWarn,
+		LookbackDays:          365,
+	}
+}
+
+// StandingFor returns the standing of a patient with the given number of no-shows.
+func (p NoShowPolicy) StandingFor(noShows int) AttendanceStanding {
+	if p.Threshold <= 0 || noShows < p.Threshold {
+		return AttendanceStandingGood
+	}
+	switch p.Action {
+	case NoShowActionBlock:
+		return AttendanceStandingBlocked
+	case NoShowActionDeposit:
+		return AttendanceStandingDepositRequired
+	default:
+		return AttendanceStandingWarned
+	}
+}
+
+// PatientAttendance counts a patient's missed and late-cancelled appointments.
+type PatientAttendance struct {
+	PatientID       string             `json:"patient_id"`
+	NoShowCount     int                `json:"no_show_count"`
+	LateCancelCount int                `json:"late_cancel_count"`
+	LastNoShowAt    *time.Time         `json:"last_no_show_at,omitempty"`
+	Standing        AttendanceStanding `json:"standing"`
+	Since           *time.Time         `json:"since,omitempty"` // start of the counted period, if limited
+}
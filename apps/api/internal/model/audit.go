@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// AuditLog records a sensitive action. For actions taken by a caregiver the
+// entry carries both the caregiver (ActorID) and the patient (PatientID).
+type AuditLog struct {
+	ID           string                 `json:"id" db:"id"`
+	ClinicID     *string                `json:"clinic_id,omitempty" db:"clinic_id"`
+	ActorID      string                 `json:"actor_id" db:"actor_id"`
+	PatientID    *string                `json:"patient_id,omitempty" db:"patient_id"`
+	ProxyGrantID *string                `json:"proxy_grant_id,omitempty" db:"proxy_grant_id"`
+	Action       string                 `json:"action" db:"action"`
+	ResourceType string                 `json:"resource_type,omitempty" db:"resource_type"`
+	ResourceID   string                 `json:"resource_id,omitempty" db:"resource_id"`
+	StatusCode   int                    `json:"status_code,omitempty" db:"status_code"`
+	Metadata     map[string]interface{} `json:"metadata,omitempty" db:"metadata"`
+	IPAddress    string                 `json:"ip_address,omitempty" db:"ip_address"`
+	CreatedAt    time.Time              `json:"created_at" db:"created_at"`
+}
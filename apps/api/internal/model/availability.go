@@ -0,0 +1,83 @@
+package model
+
+import "time"
+
+// TimeOfDay is a part of the day a patient prefers appointments in.
+type TimeOfDay string
+
+const (
+	TimeOfDayMorning   TimeOfDay = "morning"   // before 12:00
+	TimeOfDayAfternoon TimeOfDay = "afternoon" // 12:00 - 17:00
+	TimeOfDayEvening   TimeOfDay = "evening"   // from 17:00
+)
+
+// TimeOfDayAt returns the part of the day t falls in, in t's location.
+func TimeOfDayAt(t time.Time) TimeOfDay {
+	switch h := t.Hour(); {
+	case h < 12:
+		return TimeOfDayMorning
+	case h < 17:
+		return TimeOfDayAfternoon
+	default:
+		return TimeOfDayEvening
+	}
+}
+
+// Reasons a slot ranks higher in an availability search.
+const (
+	RankReasonUsualTherapist     = "usual_therapist"
+	RankReasonSeenBefore         = "seen_before"
+	RankReasonPreferredTherapist = "preferred_therapist"
+	RankReasonPreferredTime      = "preferred_time_of_day"
+	RankReasonPreferredDay       = "preferred_day"
+)
+
+// AvailabilitySearchRequest represents the request body for searching free
+// slots across therapists and days. Filters narrow the candidates; preferences
+// only affect the ranking.
+type AvailabilitySearchRequest struct {
+	Duration  int    `json:"duration" validate:"required,min=15,max=240"`
+	StartDate string `json:"start_date" validate:"omitempty,datetime=2006-01-02"` // defaults to today
+	Days      int    `json:"days" validate:"omitempty,min=1,max=60"`              // defaults to 14
+
+	// Filters
+	Specialties  []string `json:"specialties" validate:"omitempty,max=10,dive,min=1,max=100"`
+	TherapistIDs []string `json:"therapist_ids" validate:"omitempty,max=50,dive,uuid"`
+	ResourceIDs  []string `json:"resource_ids" validate:"omitempty,max=10,dive,uuid"`
+	DaysOfWeek   []int    `json:"days_of_week" validate:"omitempty,max=7,dive,min=0,max=6"`
+	EarliestTime string   `json:"earliest_time" validate:"omitempty,datetime=15:04"`
+	LatestTime   string   `json:"latest_time" validate:"omitempty,datetime=15:04"` // latest end time
+
+	// Preferences
+	PatientID             string   `json:"patient_id" validate:"omitempty,uuid"`
+	PreferredTherapistIDs []string `json:"preferred_therapist_ids" validate:"omitempty,max=10,dive,uuid"`
+	PreferredTimeOfDay    string   `json:"preferred_time_of_day" validate:"omitempty,oneof=morning afternoon evening"`
+	PreferredDaysOfWeek   []int    `json:"preferred_days_of_week" validate:"omitempty,max=7,dive,min=0,max=6"`
+
+	Limit int `json:"limit" validate:"omitempty,min=1,max=100"` // defaults to 20
+}
+
+// TherapistVisitCount is how often a patient has been booked with a therapist.
+type TherapistVisitCount struct {
+	TherapistID string    `json:"therapist_id"`
+	Visits      int       `json:"visits"`
+	LastVisit   time.Time `json:"last_visit"`
+}
+
+// RankedSlot is a free slot with its search score and the preferences it meets.
+type RankedSlot struct {
+	AvailabilitySlot
+	Score   int      `json:"score"`
+	Reasons []string `json:"reasons"`
+}
+
+// AvailabilitySearchResult lists the best free slots found by a search, best
+// first, along with the earliest matching slot.
+type AvailabilitySearchResult struct {
+	Slots              []RankedSlot `json:"slots"`
+	Earliest           *RankedSlot  `json:"earliest,omitempty"`
+	UsualTherapistID   string       `json:"usual_therapist_id,omitempty"`
+	TherapistsSearched int          `json:"therapists_searched"`
+	From               time.Time    `json:"from"`
+	To                 time.Time    `json:"to"`
+}
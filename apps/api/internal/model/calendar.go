@@ -0,0 +1,43 @@
+package model
+
+import "time"
+
+// CalendarFeedKind is whose appointments a calendar feed publishes.
+type CalendarFeedKind string
+
+const (
+	CalendarFeedTherapist CalendarFeedKind = "therapist"
+	CalendarFeedPatient   CalendarFeedKind = "patient"
+)
+
+// CalendarFeed is a secret-token iCalendar subscription. Anyone holding the
+// token can read the feed, so only its hash is stored and a feed can be
+// revoked at any time.
+type CalendarFeed struct {
+	ID             string           `json:"id" db:"id"`
+	ClinicID       string           `json:"clinic_id" db:"clinic_id"`
+	Kind           CalendarFeedKind `json:"kind" db:"kind"`
+	OwnerID        string           `json:"owner_id" db:"owner_id"` // therapist user ID or patient ID
+	TokenHash      string           `json:"-" db:"token_hash"`
+	RevokedAt      *time.Time       `json:"revoked_at,omitempty" db:"revoked_at"`
+	RevokedBy      *string          `json:"revoked_by,omitempty" db:"revoked_by"`
+	CreatedBy      string           `json:"created_by" db:"created_by"`
+	LastAccessedAt *time.Time       `json:"last_accessed_at,omitempty" db:"last_accessed_at"`
+	CreatedAt      time.Time        `json:"created_at" db:"created_at"`
+
+	// Token is set only when the feed is issued; it cannot be read back.
+	Token string `json:"-" db:"-"`
+}
+
+// IsActive reports whether the feed has not been revoked.
+func (f *CalendarFeed) IsActive() bool {
+	return f.RevokedAt == nil
+}
+
+// Path returns the feed's URL path. It is only known when the feed is issued.
+func (f *CalendarFeed) Path() string {
+	if f.Token == "" {
+		return ""
+	}
+	return "/ical/" + string(f.Kind) + "/" + f.Token + ".ics"
+}
@@ -497,15 +497,21 @@ type QuickROMRecord struct {
 	RecordedAt  time.Time `json:"recorded_at"`
 }
 
-// QuickScheduleRequest represents a quick scheduling request.
+// QuickScheduleRequest represents a quick scheduling request. The visit day is
+// either Date or Offset, a count of days, weeks or months from today at the
+// clinic such as "+3 days" or "+1 week".
 type QuickScheduleRequest struct {
-	PatientID   string    `json:"patient_id"`
-	ClinicID    string    `json:"clinic_id"`
-	TherapistID string    `json:"therapist_id"`
-	Date        string    `json:"date"`        // ISO date
-	TimeSlot    string    `json:"time_slot"`   // HH:MM
-	Duration    int       `json:"duration"`    // minutes
-	Notes       string    `json:"notes,omitempty"`
+	PatientID         string `json:"patient_id"`
+	ClinicID          string `json:"clinic_id"`
+	TherapistID       string `json:"therapist_id"`
+	UserID            string `json:"user_id"`
+	Date              string `json:"date,omitempty"`      // ISO date
+	Offset            string `json:"offset,omitempty"`    // relative to today, e.g. "+1 week"
+	TimeSlot          string `json:"time_slot,omitempty"` // HH:MM; defaults to the time of the patient's last visit
+	Duration          int    `json:"duration"`            // minutes
+	AppointmentTypeID string `json:"appointment_type_id,omitempty"`
+	Snap              bool   `json:"snap"` // book the nearest free slot when the requested one is taken
+	Notes             string `json:"notes,omitempty"`
 }
 
 // PainDelta represents the change in pain level.
@@ -0,0 +1,115 @@
+package model
+
+import "time"
+
+// ConsentType represents what a patient is consenting to.
+type ConsentType string
+
+const (
+	ConsentTypeTreatment      ConsentType = "treatment"
+	ConsentTypeDataProcessing ConsentType = "data_processing"
+	ConsentTypeMarketingSMS   ConsentType = "marketing_sms"
+	ConsentTypeMarketingZalo  ConsentType = "marketing_zalo"
+	ConsentTypePhotoUse       ConsentType = "photo_use"
+)
+
+// AllConsentTypes lists every supported consent type in display order.
+var AllConsentTypes = []ConsentType{
+	ConsentTypeTreatment,
+	ConsentTypeDataProcessing,
+	ConsentTypeMarketingSMS,
+	ConsentTypeMarketingZalo,
+	ConsentTypePhotoUse,
+}
+
+// IsValid checks if the consent type is supported.
+func (t ConsentType) IsValid() bool {
+	for _, ct := range AllConsentTypes {
+		if t == ct {
+			return true
+		}
+	}
+	return false
+}
+
+// ConsentStatus represents the state of a patient's consent.
+type ConsentStatus string
+
+const (
+	ConsentStatusActive     ConsentStatus = "active"
+	ConsentStatusWithdrawn  ConsentStatus = "withdrawn"
+	ConsentStatusSuperseded ConsentStatus = "superseded"
+)
+
+// ConsentTemplate represents a versioned, bilingual consent text.
+// Published versions are immutable; edits create a new version.
+type ConsentTemplate struct {
+	ID          string      `json:"id" db:"id"`
+	ClinicID    *string     `json:"clinic_id,omitempty" db:"clinic_id"`
+	ConsentType ConsentType `json:"consent_type" db:"consent_type"`
+	Version     int         `json:"version" db:"version"`
+	IsCurrent   bool        `json:"is_current" db:"is_current"`
+	Title       string      `json:"title" db:"title"`
+	TitleVi     string      `json:"title_vi" db:"title_vi"`
+	Body        string      `json:"body" db:"body"`
+	BodyVi      string      `json:"body_vi" db:"body_vi"`
+	CreatedAt   time.Time   `json:"created_at" db:"created_at"`
+	CreatedBy   *string     `json:"created_by,omitempty" db:"created_by"`
+}
+
+// PatientConsent represents a patient's signed consent to one template version.
+type PatientConsent struct {
+	ID                 string            `json:"id" db:"id"`
+	ClinicID           string            `json:"clinic_id" db:"clinic_id"`
+	PatientID          string            `json:"patient_id" db:"patient_id"`
+	TemplateID         string            `json:"template_id" db:"template_id"`
+	ConsentType        ConsentType       `json:"consent_type" db:"consent_type"`
+	TemplateVersion    int               `json:"template_version" db:"template_version"`
+	Status             ConsentStatus     `json:"status" db:"status"`
+	Signature          SignatureResponse `json:"signature" db:"signature"`
+	SignedByName       string            `json:"signed_by_name,omitempty" db:"signed_by_name"`
+	SignerRelationship string            `json:"signer_relationship,omitempty" db:"signer_relationship"`
+	GrantedAt          time.Time         `json:"granted_at" db:"granted_at"`
+	RecordedBy         string            `json:"recorded_by" db:"recorded_by"`
+	WithdrawnAt        *time.Time        `json:"withdrawn_at,omitempty" db:"withdrawn_at"`
+	WithdrawnBy        *string           `json:"withdrawn_by,omitempty" db:"withdrawn_by"`
+	WithdrawalReason   string            `json:"withdrawal_reason,omitempty" db:"withdrawal_reason"`
+	CreatedAt          time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time         `json:"updated_at" db:"updated_at"`
+
+	// Joined fields
+	Title   string `json:"title,omitempty" db:"-"`
+	TitleVi string `json:"title_vi,omitempty" db:"-"`
+}
+
+// ConsentSummary reports a patient's standing for one consent type.
+type ConsentSummary struct {
+	ConsentType    ConsentType     `json:"consent_type"`
+	Active         bool            `json:"active"`
+	CurrentVersion int             `json:"current_version"`
+	NeedsRenewal   bool            `json:"needs_renewal"`
+	Consent        *PatientConsent `json:"consent,omitempty"`
+}
+
+// CreateConsentTemplateRequest represents the request body for publishing a new consent text version.
+type CreateConsentTemplateRequest struct {
+	ConsentType string `json:"consent_type" validate:"required,oneof=treatment data_processing marketing_sms marketing_zalo photo_use"`
+	Title       string `json:"title" validate:"required,max=255"`
+	TitleVi     string `json:"title_vi" validate:"required,max=255"`
+	Body        string `json:"body" validate:"required"`
+	BodyVi      string `json:"body_vi" validate:"required"`
+}
+
+// RecordConsentRequest represents the request body for capturing a patient's consent.
+type RecordConsentRequest struct {
+	ConsentType        string             `json:"consent_type" validate:"required,oneof=treatment data_processing marketing_sms marketing_zalo photo_use"`
+	TemplateID         string             `json:"template_id" validate:"omitempty,uuid"`
+	Signature          *SignatureResponse `json:"signature" validate:"required"`
+	SignedByName       string             `json:"signed_by_name" validate:"max=200"`
+	SignerRelationship string             `json:"signer_relationship" validate:"max=100"`
+}
+
+// WithdrawConsentRequest represents the request body for withdrawing consent.
+type WithdrawConsentRequest struct {
+	Reason string `json:"reason" validate:"max=500"`
+}
@@ -84,6 +84,9 @@ type Exercise struct {
 	CreatedAt       time.Time          `json:"created_at" db:"created_at"`
 	UpdatedAt       time.Time          `json:"updated_at" db:"updated_at"`
 	CreatedBy       *string            `json:"created_by,omitempty" db:"created_by"`
+
+	// Media holds uploaded photos and clips, loaded for single exercises
+	Media []ExerciseMedia `json:"media,omitempty" db:"-"`
 }
 
 // ExercisePrescription represents an exercise prescribed to a patient.
@@ -98,6 +101,7 @@ type ExercisePrescription struct {
 	Reps               int                `json:"reps" db:"reps"`
 	HoldSeconds        int                `json:"hold_seconds" db:"hold_seconds"`
 	Frequency          string             `json:"frequency" db:"frequency"`
+	FrequencySchedule  *FrequencySchedule `json:"frequency_schedule,omitempty" db:"frequency_schedule"`
 	DurationWeeks      int                `json:"duration_weeks" db:"duration_weeks"`
 	CustomInstructions string             `json:"custom_instructions,omitempty" db:"custom_instructions"`
 	Notes              string             `json:"notes,omitempty" db:"notes"`
@@ -113,24 +117,26 @@ type ExercisePrescription struct {
 
 // HomeExerciseProgram represents a collection of exercises for a patient.
 type HomeExerciseProgram struct {
-	ID            string    `json:"id" db:"id"`
-	PatientID     string    `json:"patient_id" db:"patient_id"`
-	ClinicID      string    `json:"clinic_id" db:"clinic_id"`
-	CreatedBy     string    `json:"created_by" db:"created_by"`
-	Name          string    `json:"name" db:"name"`
-	NameVi        string    `json:"name_vi,omitempty" db:"name_vi"`
-	Description   string    `json:"description,omitempty" db:"description"`
-	DescriptionVi string    `json:"description_vi,omitempty" db:"description_vi"`
-	Frequency     string    `json:"frequency" db:"frequency"`
-	DurationWeeks int       `json:"duration_weeks" db:"duration_weeks"`
-	StartDate     time.Time `json:"start_date" db:"start_date"`
-	EndDate       *time.Time `json:"end_date,omitempty" db:"end_date"`
-	IsActive      bool      `json:"is_active" db:"is_active"`
-	CreatedAt     time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+	ID                string             `json:"id" db:"id"`
+	PatientID         string             `json:"patient_id" db:"patient_id"`
+	ClinicID          string             `json:"clinic_id" db:"clinic_id"`
+	CreatedBy         string             `json:"created_by" db:"created_by"`
+	Name              string             `json:"name" db:"name"`
+	NameVi            string             `json:"name_vi,omitempty" db:"name_vi"`
+	Description       string             `json:"description,omitempty" db:"description"`
+	DescriptionVi     string             `json:"description_vi,omitempty" db:"description_vi"`
+	Frequency         string             `json:"frequency" db:"frequency"`
+	FrequencySchedule *FrequencySchedule `json:"frequency_schedule,omitempty" db:"frequency_schedule"`
+	DurationWeeks     int                `json:"duration_weeks" db:"duration_weeks"`
+	StartDate         time.Time          `json:"start_date" db:"start_date"`
+	EndDate           *time.Time         `json:"end_date,omitempty" db:"end_date"`
+	IsActive          bool               `json:"is_active" db:"is_active"`
+	CreatedAt         time.Time          `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time          `json:"updated_at" db:"updated_at"`
 
 	// Joined fields
 	Exercises []ExercisePrescription `json:"exercises,omitempty" db:"-"`
+	Phases    []ProgramPhase         `json:"phases,omitempty" db:"-"` // for programs created from a protocol
 }
 
 // ExerciseComplianceLog tracks patient exercise completion.
@@ -249,11 +255,14 @@ type PrescribeExerciseRequest struct {
 	Sets               int    `json:"sets" validate:"min=1,max=20"`
 	Reps               int    `json:"reps" validate:"min=1,max=100"`
 	HoldSeconds        int    `json:"hold_seconds" validate:"min=0,max=300"`
-	Frequency          string `json:"frequency" validate:"required,max=50"`
+	Frequency          string `json:"frequency" validate:"required_without=FrequencySchedule,max=50"`
 	DurationWeeks      int    `json:"duration_weeks" validate:"min=1,max=52"`
 	CustomInstructions string `json:"custom_instructions" validate:"max=2000"`
 	Notes              string `json:"notes" validate:"max=1000"`
 	StartDate          string `json:"start_date" validate:"omitempty,datetime=2006-01-02"`
+
+	// FrequencySchedule takes precedence over Frequency when both are given.
+	FrequencySchedule *FrequencySchedule `json:"frequency_schedule"`
 }
 
 // UpdatePrescriptionRequest represents the request to update a prescription.
@@ -266,6 +275,8 @@ type UpdatePrescriptionRequest struct {
 	CustomInstructions *string `json:"custom_instructions" validate:"omitempty,max=2000"`
 	Notes              *string `json:"notes" validate:"omitempty,max=1000"`
 	Status             *string `json:"status" validate:"omitempty,oneof=active completed paused cancelled"`
+
+	FrequencySchedule *FrequencySchedule `json:"frequency_schedule"`
 }
 
 // CreateProgramRequest represents the request to create a home exercise program.
@@ -274,10 +285,13 @@ type CreateProgramRequest struct {
 	NameVi        string   `json:"name_vi" validate:"max=200"`
 	Description   string   `json:"description" validate:"max=2000"`
 	DescriptionVi string   `json:"description_vi" validate:"max=2000"`
-	Frequency     string   `json:"frequency" validate:"required,max=50"`
+	Frequency     string   `json:"frequency" validate:"required_without=FrequencySchedule,max=50"`
 	DurationWeeks int      `json:"duration_weeks" validate:"min=1,max=52"`
 	ExerciseIDs   []string `json:"exercise_ids" validate:"required,min=1,dive,uuid"`
 	StartDate     string   `json:"start_date" validate:"omitempty,datetime=2006-01-02"`
+
+	// FrequencySchedule takes precedence over Frequency when both are given.
+	FrequencySchedule *FrequencySchedule `json:"frequency_schedule"`
 }
 
 // LogComplianceRequest represents a request to log exercise completion.
@@ -0,0 +1,126 @@
+package model
+
+import "time"
+
+// ExerciseLibraryVersion is the version of the exercise library file format.
+const ExerciseLibraryVersion = 1
+
+// ExerciseLibraryFormat is the file format of an exercise library export.
+type ExerciseLibraryFormat string
+
+const (
+	ExerciseLibraryJSON ExerciseLibraryFormat = "json"
+	ExerciseLibraryCSV  ExerciseLibraryFormat = "csv"
+)
+
+// ExerciseLibrary is a portable set of exercises, used to seed a clinic's
+// library or move custom exercises between clinics.
+type ExerciseLibrary struct {
+	Version    int                   `json:"version"`
+	ExportedAt time.Time             `json:"exported_at"`
+	ClinicID   string                `json:"clinic_id,omitempty"`
+	Exercises  []ExerciseLibraryItem `json:"exercises"`
+}
+
+// ExerciseLibraryItem is an exercise in a library file. Its fields follow
+// CreateExerciseRequest, and are validated by the same rules on import.
+type ExerciseLibraryItem struct {
+	SourceID string `json:"source_id,omitempty"`
+	CreateExerciseRequest
+	Media []ExerciseLibraryMedia `json:"media,omitempty"`
+}
+
+// ExerciseLibraryMedia references an uploaded photo or clip of an exported
+// exercise. On import the file is copied when the media belongs to a clinic
+// of the importing clinic's tenant.
+type ExerciseLibraryMedia struct {
+	ID          string            `json:"id,omitempty"`
+	URL         string            `json:"url,omitempty"`
+	Kind        ExerciseMediaKind `json:"kind,omitempty"`
+	Caption     string            `json:"caption,omitempty"`
+	CaptionVi   string            `json:"caption_vi,omitempty"`
+	FileName    string            `json:"file_name,omitempty"`
+	ContentType string            `json:"content_type,omitempty"`
+	SHA256      string            `json:"sha256,omitempty"`
+}
+
+// ExportExercisesParams holds the query parameters of an exercise export.
+type ExportExercisesParams struct {
+	Format        string `query:"format" validate:"omitempty,oneof=json csv"`
+	IncludeGlobal bool   `query:"include_global"`
+}
+
+// ImportExercisesParams holds the options of an exercise import.
+type ImportExercisesParams struct {
+	Format          string `query:"format" validate:"omitempty,oneof=json csv"`
+	DryRun          bool   `query:"dry_run"`
+	AllowDuplicates bool   `query:"allow_duplicates"`
+}
+
+// ExerciseImportStatus is the outcome of importing one exercise.
+type ExerciseImportStatus string
+
+const (
+	ExerciseImportValid     ExerciseImportStatus = "valid"
+	ExerciseImportCreated   ExerciseImportStatus = "created"
+	ExerciseImportDuplicate ExerciseImportStatus = "duplicate"
+	ExerciseImportInvalid   ExerciseImportStatus = "invalid"
+	ExerciseImportFailed    ExerciseImportStatus = "failed"
+)
+
+// ExerciseImportRow reports the outcome of one exercise in an import. Row is
+// the 1-based position in the file, counting only exercises.
+type ExerciseImportRow struct {
+	Row         int                  `json:"row"`
+	Name        string               `json:"name"`
+	NameVi      string               `json:"name_vi"`
+	Status      ExerciseImportStatus `json:"status"`
+	ExerciseID  string               `json:"exercise_id,omitempty"`
+	DuplicateOf string               `json:"duplicate_of,omitempty"`
+	Errors      map[string]string    `json:"errors,omitempty"`
+	Warnings    []string             `json:"warnings,omitempty"`
+	MediaCopied int                  `json:"media_copied,omitempty"`
+}
+
+// ExerciseImportResult summarizes an import. In a dry run nothing is
+// created, and rows that would be created are reported as valid.
+type ExerciseImportResult struct {
+	DryRun     bool                `json:"dry_run"`
+	Total      int                 `json:"total"`
+	Valid      int                 `json:"valid"`
+	Created    int                 `json:"created"`
+	Duplicates int                 `json:"duplicates"`
+	Invalid    int                 `json:"invalid"`
+	Failed     int                 `json:"failed"`
+	Rows       []ExerciseImportRow `json:"rows"`
+}
+
+// ExercisePublishStatus is the outcome of publishing an exercise to a clinic.
+type ExercisePublishStatus string
+
+const (
+	ExercisePublishCreated   ExercisePublishStatus = "created"
+	ExercisePublishDuplicate ExercisePublishStatus = "duplicate"
+	ExercisePublishFailed    ExercisePublishStatus = "failed"
+)
+
+// ExercisePublishTarget reports the outcome of publishing to one clinic.
+type ExercisePublishTarget struct {
+	ClinicID    string                `json:"clinic_id"`
+	ClinicName  string                `json:"clinic_name"`
+	Status      ExercisePublishStatus `json:"status"`
+	ExerciseID  string                `json:"exercise_id,omitempty"`
+	DuplicateOf string                `json:"duplicate_of,omitempty"`
+	MediaCopied int                   `json:"media_copied,omitempty"`
+	Error       string                `json:"error,omitempty"`
+}
+
+// ExercisePublishResult summarizes copying an exercise to the other clinics
+// of its tenant.
+type ExercisePublishResult struct {
+	ExerciseID string                  `json:"exercise_id"`
+	Created    int                     `json:"created"`
+	Duplicates int                     `json:"duplicates"`
+	Failed     int                     `json:"failed"`
+	Clinics    []ExercisePublishTarget `json:"clinics"`
+}
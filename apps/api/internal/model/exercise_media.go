@@ -0,0 +1,84 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ExerciseMediaKind is whether an exercise media file is a photo or a clip.
+type ExerciseMediaKind string
+
+const (
+	ExerciseMediaImage ExerciseMediaKind = "image"
+	ExerciseMediaVideo ExerciseMediaKind = "video"
+)
+
+// ExerciseMedia is a demonstration photo or short clip uploaded for an
+// exercise. The file lives in the blob store under BlobKey, and a video's
+// thumbnail, when one could be made, under ThumbnailKey.
+type ExerciseMedia struct {
+	ID              string            `json:"id" db:"id"`
+	ExerciseID      string            `json:"exercise_id" db:"exercise_id"`
+	ClinicID        string            `json:"clinic_id" db:"clinic_id"`
+	Kind            ExerciseMediaKind `json:"kind" db:"kind"`
+	Position        int               `json:"position" db:"position"`
+	Caption         string            `json:"caption,omitempty" db:"caption"`
+	CaptionVi       string            `json:"caption_vi,omitempty" db:"caption_vi"`
+	FileName        string            `json:"file_name" db:"file_name"`
+	ContentType     string            `json:"content_type" db:"content_type"`
+	SizeBytes       int64             `json:"size_bytes" db:"size_bytes"`
+	SHA256          string            `json:"sha256" db:"sha256"`
+	BlobKey         string            `json:"-" db:"blob_key"`
+	ThumbnailKey    string            `json:"-" db:"thumbnail_key"`
+	Width           *int              `json:"width,omitempty" db:"width"`
+	Height          *int              `json:"height,omitempty" db:"height"`
+	DurationSeconds *float64          `json:"duration_seconds,omitempty" db:"duration_seconds"`
+	CreatedBy       *string           `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt       time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// HasThumbnail reports whether a thumbnail was stored for the media.
+func (m *ExerciseMedia) HasThumbnail() bool {
+	return m.ThumbnailKey != ""
+}
+
+// URL returns the API path that serves the media file.
+func (m *ExerciseMedia) URL() string {
+	return fmt.Sprintf("/api/v1/exercises/%s/media/%s", m.ExerciseID, m.ID)
+}
+
+// ParseExerciseMediaURL extracts the media ID from a URL returned by
+// ExerciseMedia.URL, with or without a scheme and host.
+func ParseExerciseMediaURL(url string) (string, bool) {
+	url = strings.TrimSpace(url)
+	if i := strings.Index(url, "/api/v1/exercises/"); i >= 0 {
+		url = url[i+len("/api/v1/exercises/"):]
+	} else {
+		return "", false
+	}
+	parts := strings.Split(strings.Trim(url, "/"), "/")
+	if len(parts) != 3 || parts[1] != "media" || parts[2] == "" {
+		return "", false
+	}
+	return parts[2], true
+}
+
+// UploadExerciseMediaRequest represents the form fields sent with an
+// exercise media upload.
+type UploadExerciseMediaRequest struct {
+	Caption   string `form:"caption" validate:"max=500"`
+	CaptionVi string `form:"caption_vi" validate:"max=500"`
+}
+
+// UpdateExerciseMediaRequest represents a change to a media file's captions.
+type UpdateExerciseMediaRequest struct {
+	Caption   *string `json:"caption" validate:"omitempty,max=500"`
+	CaptionVi *string `json:"caption_vi" validate:"omitempty,max=500"`
+}
+
+// ReorderExerciseMediaRequest lists all of an exercise's media in their new order.
+type ReorderExerciseMediaRequest struct {
+	MediaIDs []string `json:"media_ids" validate:"required,min=1,dive,uuid"`
+}
@@ -0,0 +1,81 @@
+package model
+
+// ExerciseFindingType is a kind of examination finding that exercises can
+// address.
+type ExerciseFindingType string
+
+const (
+	FindingROMDeficit ExerciseFindingType = "rom_deficit"
+	FindingStiffness  ExerciseFindingType = "stiffness"
+	FindingWeakness   ExerciseFindingType = "weakness"
+	FindingBalance    ExerciseFindingType = "balance"
+	FindingPosture    ExerciseFindingType = "posture"
+	FindingEndurance  ExerciseFindingType = "endurance"
+	FindingPain       ExerciseFindingType = "pain"
+)
+
+// Body regions that exercise recommendations understand. Free-text regions,
+// such as body diagram labels, are matched to these where possible.
+const (
+	BodyRegionCervicalSpine = "cervical_spine"
+	BodyRegionThoracicSpine = "thoracic_spine"
+	BodyRegionLumbarSpine   = "lumbar_spine"
+	BodyRegionShoulder      = "shoulder"
+	BodyRegionElbow         = "elbow"
+	BodyRegionWristHand     = "wrist_hand"
+	BodyRegionHip           = "hip"
+	BodyRegionKnee          = "knee"
+	BodyRegionAnkleFoot     = "ankle_foot"
+)
+
+// ExerciseFinding is an examination finding, such as a flexion ROM deficit
+// of the lumbar spine.
+type ExerciseFinding struct {
+	Type       ExerciseFindingType `json:"type" validate:"required,oneof=rom_deficit stiffness weakness balance posture endurance pain"`
+	BodyRegion string              `json:"body_region,omitempty" validate:"omitempty,max=50"`
+	Movement   string              `json:"movement,omitempty" validate:"omitempty,max=50"`
+}
+
+// ExerciseRecommendationRequest describes the patient presentation to
+// recommend exercises for. Age and pain level default to the patient's date
+// of birth and last pain record, and a visit checklist adds the body regions
+// and findings recorded in it.
+type ExerciseRecommendationRequest struct {
+	PatientID        string            `json:"patient_id" validate:"omitempty,uuid"`
+	VisitChecklistID string            `json:"visit_checklist_id" validate:"omitempty,uuid"`
+	DiagnosisCode    string            `json:"diagnosis_code" validate:"omitempty,max=20"`
+	BodyRegions      []string          `json:"body_regions" validate:"omitempty,max=10,dive,max=50"`
+	Findings         []ExerciseFinding `json:"findings" validate:"omitempty,max=20,dive"`
+	Age              *int              `json:"age" validate:"omitempty,min=0,max=120"`
+	PainLevel        *int              `json:"pain_level" validate:"omitempty,min=0,max=10"`
+	Limit            int               `json:"limit" validate:"omitempty,min=1,max=50"`
+}
+
+// ExerciseRecommendationProfile is the presentation recommendations were
+// ranked against, after defaults and checklist findings were applied.
+type ExerciseRecommendationProfile struct {
+	DiagnosisCode       string             `json:"diagnosis_code,omitempty"`
+	BodyRegions         []string           `json:"body_regions"`
+	Findings            []ExerciseFinding  `json:"findings"`
+	Age                 *int               `json:"age,omitempty"`
+	PainLevel           *int               `json:"pain_level,omitempty"`
+	MaxDifficulty       ExerciseDifficulty `json:"max_difficulty"`
+	PreferredDifficulty ExerciseDifficulty `json:"preferred_difficulty,omitempty"`
+}
+
+// ExerciseRecommendation is a suggested exercise with the score it was
+// ranked by and the reason for suggesting it.
+type ExerciseRecommendation struct {
+	Exercise          Exercise      `json:"exercise"`
+	Score             int           `json:"score"`
+	Reason            string        `json:"reason"`
+	MatchedGroups     []MuscleGroup `json:"matched_muscle_groups,omitempty"`
+	PrescriptionCount int           `json:"prescription_count,omitempty"`
+}
+
+// ExerciseRecommendationResult holds ranked recommendations and the profile
+// they were ranked against.
+type ExerciseRecommendationResult struct {
+	Profile         ExerciseRecommendationProfile `json:"profile"`
+	Recommendations []ExerciseRecommendation      `json:"recommendations"`
+}
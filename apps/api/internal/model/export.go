@@ -0,0 +1,93 @@
+package model
+
+import "time"
+
+// ExportFormatVersion is the version of the export bundle layout described by the manifest.
+const ExportFormatVersion = 1
+
+// ExportPurpose records why a patient record was exported.
+type ExportPurpose string
+
+const (
+	ExportPurposePatientRequest ExportPurpose = "patient_request"
+	ExportPurposeReferral       ExportPurpose = "referral"
+)
+
+// ExportStatus represents the state of an export job.
+type ExportStatus string
+
+const (
+	ExportStatusPending   ExportStatus = "pending"
+	ExportStatusRunning   ExportStatus = "running"
+	ExportStatusCompleted ExportStatus = "completed"
+	ExportStatusFailed    ExportStatus = "failed"
+)
+
+// DataExport represents a patient record export job and its resulting zip bundle.
+type DataExport struct {
+	ID                 string        `json:"id" db:"id"`
+	ClinicID           string        `json:"clinic_id" db:"clinic_id"`
+	PatientID          string        `json:"patient_id" db:"patient_id"`
+	Purpose            ExportPurpose `json:"purpose" db:"purpose"`
+	Recipient          string        `json:"recipient,omitempty" db:"recipient"`
+	IncludeAttachments bool          `json:"include_attachments" db:"include_attachments"`
+	Status             ExportStatus  `json:"status" db:"status"`
+	BlobKey            string        `json:"-" db:"blob_key"`
+	SizeBytes          int64         `json:"size_bytes,omitempty" db:"size_bytes"`
+	SHA256             string        `json:"sha256,omitempty" db:"sha256"`
+	Error              string        `json:"error,omitempty" db:"error"`
+	CompletedAt        *time.Time    `json:"completed_at,omitempty" db:"completed_at"`
+	ExpiresAt          *time.Time    `json:"expires_at,omitempty" db:"expires_at"`
+	RequestedBy        string        `json:"requested_by" db:"requested_by"`
+	CreatedAt          time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time     `json:"updated_at" db:"updated_at"`
+}
+
+// IsDownloadable reports whether the export bundle can be downloaded at the given time.
+func (e *DataExport) IsDownloadable(at time.Time) bool {
+	if e.Status != ExportStatusCompleted || e.BlobKey == "" {
+		return false
+	}
+	return e.ExpiresAt == nil || at.Before(*e.ExpiresAt)
+}
+
+// ExportManifest describes the contents of an export bundle. It is written as
+// manifest.json at the root of the zip so the recipient can verify every file.
+type ExportManifest struct {
+	FormatVersion int           `json:"format_version"`
+	ExportID      string        `json:"export_id,omitempty"`
+	GeneratedAt   time.Time     `json:"generated_at"`
+	ClinicID      string        `json:"clinic_id"`
+	PatientID     string        `json:"patient_id"`
+	PatientMRN    string        `json:"patient_mrn"`
+	Purpose       ExportPurpose `json:"purpose"`
+	Recipient     string        `json:"recipient,omitempty"`
+	Files         []ExportFile  `json:"files"`
+}
+
+// ExportFile is a manifest entry for one file in the bundle.
+type ExportFile struct {
+	Path        string `json:"path"`
+	ContentType string `json:"content_type"`
+	SizeBytes   int64  `json:"size_bytes"`
+	SHA256      string `json:"sha256"`
+	Description string `json:"description,omitempty"`
+}
+
+// ExportVisitNote is a generated bilingual SOAP note from a completed visit checklist.
+type ExportVisitNote struct {
+	VisitChecklistID string     `json:"visit_checklist_id"`
+	TemplateName     string     `json:"template_name"`
+	TherapistName    string     `json:"therapist_name"`
+	CompletedAt      *time.Time `json:"completed_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	Note             string     `json:"note"`
+	NoteVi           string     `json:"note_vi"`
+}
+
+// CreateExportRequest represents the request body for exporting a patient record.
+type CreateExportRequest struct {
+	Purpose            string `json:"purpose" validate:"required,oneof=patient_request referral"`
+	Recipient          string `json:"recipient" validate:"required_if=Purpose referral,max=255"`
+	IncludeAttachments *bool  `json:"include_attachments"`
+}
@@ -0,0 +1,410 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ExerciseTime is a part of the day an exercise session is meant for.
+type ExerciseTime string
+
+const (
+	ExerciseTimeMorning   ExerciseTime = "morning"
+	ExerciseTimeMidday    ExerciseTime = "midday"
+	ExerciseTimeAfternoon ExerciseTime = "afternoon"
+	ExerciseTimeEvening   ExerciseTime = "evening"
+	ExerciseTimeBedtime   ExerciseTime = "bedtime"
+)
+
+// timesOfDay lists the times of day in order with their labels.
+var timesOfDay = []struct {
+	value  ExerciseTime
+	en, vi string
+}{
+	{ExerciseTimeMorning, "morning", "sáng"},
+	{ExerciseTimeMidday, "midday", "trưa"},
+	{ExerciseTimeAfternoon, "afternoon", "chiều"},
+	{ExerciseTimeEvening, "evening", "tối"},
+	{ExerciseTimeBedtime, "bedtime", "trước khi ngủ"},
+}
+
+// weekdays lists the days of the week from Monday with their labels.
+var weekdays = []struct {
+	value  string
+	en, vi string
+}{
+	{"mon", "Mon", "T2"},
+	{"tue", "Tue", "T3"},
+	{"wed", "Wed", "T4"},
+	{"thu", "Thu", "T5"},
+	{"fri", "Fri", "T6"},
+	{"sat", "Sat", "T7"},
+	{"sun", "Sun", "CN"},
+}
+
+// FrequencySchedule is how often an exercise is done: a number of sessions a
+// day on every day, on a number of days a week, on specific weekdays, or once
+// every few days, optionally at given times of day.
+type FrequencySchedule struct {
+	TimesPerDay  int            `json:"times_per_day" validate:"required,min=1,max=10"`
+	DaysPerWeek  int            `json:"days_per_week,omitempty" validate:"omitempty,min=1,max=7"`
+	IntervalDays int            `json:"interval_days,omitempty" validate:"omitempty,min=2,max=7"`
+	Weekdays     []string       `json:"weekdays,omitempty" validate:"omitempty,max=7,dive,oneof=mon tue wed thu fri sat sun"`
+	TimesOfDay   []ExerciseTime `json:"times_of_day,omitempty" validate:"omitempty,max=5,dive,oneof=morning midday afternoon evening bedtime"`
+}
+
+// Validate checks the rules that span fields.
+func (f *FrequencySchedule) Validate() error {
+	if f.DaysPerWeek > 0 && len(f.Weekdays) > 0 {
+		return errors.New("give either days_per_week or weekdays, not both")
+	}
+	if f.IntervalDays > 0 && (f.DaysPerWeek > 0 || len(f.Weekdays) > 0) {
+		return errors.New("interval_days cannot be combined with days_per_week or weekdays")
+	}
+	if len(f.TimesOfDay) > f.TimesPerDay {
+		return errors.New("times_of_day has more entries than times_per_day")
+	}
+	return nil
+}
+
+// Normalize puts weekdays and times of day in calendar order without
+// duplicates, and drops a days_per_week of 7 or all seven weekdays, which is
+// the default.
+func (f *FrequencySchedule) Normalize() {
+	if f.DaysPerWeek == 7 {
+		f.DaysPerWeek = 0
+	}
+	f.Weekdays = normalizeOrdered(f.Weekdays, func(v string) int {
+		for i, d := range weekdays {
+			if d.value == v {
+				return i
+			}
+		}
+		return len(weekdays)
+	})
+	if len(f.Weekdays) == len(weekdays) {
+		f.Weekdays = nil
+	}
+	times := make([]string, len(f.TimesOfDay))
+	for i, t := range f.TimesOfDay {
+		times[i] = string(t)
+	}
+	times = normalizeOrdered(times, func(v string) int {
+		for i, t := range timesOfDay {
+			if string(t.value) == v {
+				return i
+			}
+		}
+		return len(timesOfDay)
+	})
+	f.TimesOfDay = nil
+	for _, t := range times {
+		f.TimesOfDay = append(f.TimesOfDay, ExerciseTime(t))
+	}
+}
+
+// normalizeOrdered sorts values by rank and removes duplicates. It returns
+// nil for no values.
+func normalizeOrdered(values []string, rank func(string) int) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	sorted := append([]string(nil), values...)
+	sort.SliceStable(sorted, func(i, j int) bool { return rank(sorted[i]) < rank(sorted[j]) })
+	result := sorted[:1]
+	for _, v := range sorted[1:] {
+		if v != result[len(result)-1] {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// DaysPerWeekCount returns how many days a week the exercise is done on
+// average, e.g. 3.5 for every other day.
+func (f FrequencySchedule) DaysPerWeekCount() float64 {
+	switch {
+	case len(f.Weekdays) > 0:
+		return float64(len(f.Weekdays))
+	case f.DaysPerWeek > 0:
+		return float64(f.DaysPerWeek)
+	case f.IntervalDays > 0:
+		return 7 / float64(f.IntervalDays)
+	default:
+		return 7
+	}
+}
+
+// SessionsPerWeek returns how many sessions a week the schedule calls for.
+func (f FrequencySchedule) SessionsPerWeek() float64 {
+	return float64(f.TimesPerDay) * f.DaysPerWeekCount()
+}
+
+// String renders the schedule in English, e.g. "2x/day, 5 days/week".
+func (f FrequencySchedule) String() string {
+	return f.Format("en")
+}
+
+// Format renders the schedule for patients in "vi" or, for any other
+// language, English: "2 lần/ngày, 5 ngày/tuần (sáng, tối)" or
+// "2x/day, 5 days/week (morning, evening)". Once a day on some days of the
+// week is written per week, e.g. "3x/week", and intervals as "every other
+// day" or "every 3 days".
+func (f FrequencySchedule) Format(language string) string {
+	vi := language == "vi"
+	var parts []string
+
+	switch {
+	case f.IntervalDays > 0:
+		if f.TimesPerDay > 1 {
+			if vi {
+				parts = append(parts, fmt.Sprintf("%d lần/ngày", f.TimesPerDay))
+			} else {
+				parts = append(parts, fmt.Sprintf("%dx/day", f.TimesPerDay))
+			}
+		}
+		switch {
+		case vi && f.IntervalDays == 2:
+			parts = append(parts, "cách ngày")
+		case vi:
+			parts = append(parts, fmt.Sprintf("%d ngày/lần", f.IntervalDays))
+		case f.IntervalDays == 2:
+			parts = append(parts, "every other day")
+		default:
+			parts = append(parts, fmt.Sprintf("every %d days", f.IntervalDays))
+		}
+	case f.TimesPerDay == 1 && f.DaysPerWeek > 0 && f.DaysPerWeek < 7 && len(f.Weekdays) == 0:
+		if vi {
+			parts = append(parts, fmt.Sprintf("%d lần/tuần", f.DaysPerWeek))
+		} else {
+			parts = append(parts, fmt.Sprintf("%dx/week", f.DaysPerWeek))
+		}
+	default:
+		if vi {
+			parts = append(parts, fmt.Sprintf("%d lần/ngày", f.TimesPerDay))
+		} else {
+			parts = append(parts, fmt.Sprintf("%dx/day", f.TimesPerDay))
+		}
+		if len(f.Weekdays) > 0 {
+			for _, d := range weekdays {
+				for _, w := range f.Weekdays {
+					if w == d.value {
+						if vi {
+							parts = append(parts, d.vi)
+						} else {
+							parts = append(parts, d.en)
+						}
+					}
+				}
+			}
+		} else if f.DaysPerWeek > 0 && f.DaysPerWeek < 7 {
+			switch {
+			case vi:
+				parts = append(parts, fmt.Sprintf("%d ngày/tuần", f.DaysPerWeek))
+			case f.DaysPerWeek == 1:
+				parts = append(parts, "1 day/week")
+			default:
+				parts = append(parts, fmt.Sprintf("%d days/week", f.DaysPerWeek))
+			}
+		}
+	}
+
+	text := strings.Join(parts, ", ")
+	if len(f.TimesOfDay) > 0 {
+		var labels []string
+		for _, t := range timesOfDay {
+			for _, v := range f.TimesOfDay {
+				if v == t.value {
+					if vi {
+						labels = append(labels, t.vi)
+					} else {
+						labels = append(labels, t.en)
+					}
+				}
+			}
+		}
+		text += " (" + strings.Join(labels, ", ") + ")"
+	}
+	return text
+}
+
+// maxFrequencyText is the longest free-text frequency stored with a schedule.
+const maxFrequencyText = 50
+
+// ResolveFrequency returns the free text and schedule to store for a
+// frequency given as text, as a schedule or as both. A schedule is checked
+// and normalized and, without text, rendered in English as the text, leaving
+// out the times of day if they make it too long. Text alone is parsed into a
+// schedule where possible and otherwise kept without one.
+func ResolveFrequency(text string, schedule *FrequencySchedule) (string, *FrequencySchedule, error) {
+	text = strings.TrimSpace(text)
+	if schedule == nil {
+		if text == "" {
+			return "", nil, errors.New("frequency or frequency_schedule is required")
+		}
+		parsed, _ := ParseFrequency(text)
+		return text, parsed, nil
+	}
+
+	resolved := *schedule
+	if err := resolved.Validate(); err != nil {
+		return "", nil, err
+	}
+	resolved.Normalize()
+	if text == "" {
+		text = resolved.String()
+		if len(text) > maxFrequencyText {
+			short := resolved
+			short.TimesOfDay = nil
+			text = short.String()
+		}
+	}
+	return text, &resolved, nil
+}
+
+// FormatFrequency renders a stored frequency for patients in the given
+// language, falling back to the free text when there is no schedule.
+func FormatFrequency(text string, schedule *FrequencySchedule, language string) string {
+	if schedule == nil {
+		return text
+	}
+	return schedule.Format(language)
+}
+
+var (
+	frequencyCountPattern    = regexp.MustCompile(`^(\d+)\s*(?:x|times?|lần|lan)?\s*(?:/|per|a|an|each|every|mỗi|moi|một|mot)?\s*(day|daily|ngày|ngay|week|weekly|wk|tuần|tuan)$`)
+	frequencyDaysPattern     = regexp.MustCompile(`^(\d+)\s*(?:days?|ngày|ngay)\s*(?:/|per|a|an|each|every|mỗi|moi|một|mot)?\s*(?:week|wk|tuần|tuan)$`)
+	frequencyTimesPattern    = regexp.MustCompile(`\(([^)]*)\)`)
+	frequencyIntervalPattern = regexp.MustCompile(`^(?:every\s*(\d+)\s*days|(\d+)\s*(?:ngày|ngay)\s*/\s*(?:lần|lan))$`)
+	frequencyPhrases         = map[string]FrequencySchedule{
+		"daily": {TimesPerDay: 1}, "every day": {TimesPerDay: 1}, "once daily": {TimesPerDay: 1}, "once a day": {TimesPerDay: 1},
+		"hằng ngày": {TimesPerDay: 1}, "hàng ngày": {TimesPerDay: 1}, "mỗi ngày": {TimesPerDay: 1},
+		"twice daily": {TimesPerDay: 2}, "twice a day": {TimesPerDay: 2}, "bid": {TimesPerDay: 2},
+		"three times daily": {TimesPerDay: 3}, "three times a day": {TimesPerDay: 3}, "tid": {TimesPerDay: 3},
+		"weekly": {TimesPerDay: 1, DaysPerWeek: 1}, "once a week": {TimesPerDay: 1, DaysPerWeek: 1},
+		"hằng tuần": {TimesPerDay: 1, DaysPerWeek: 1}, "hàng tuần": {TimesPerDay: 1, DaysPerWeek: 1},
+		"twice a week": {TimesPerDay: 1, DaysPerWeek: 2}, "twice weekly": {TimesPerDay: 1, DaysPerWeek: 2},
+		"every other day": {TimesPerDay: 1, IntervalDays: 2}, "alternate days": {TimesPerDay: 1, IntervalDays: 2},
+		"cách ngày": {TimesPerDay: 1, IntervalDays: 2}, "cách nhật": {TimesPerDay: 1, IntervalDays: 2},
+	}
+	weekdayWords = map[string]string{
+		"mon": "mon", "monday": "mon", "t2": "mon", "thứ 2": "mon", "thứ hai": "mon",
+		"tue": "tue", "tuesday": "tue", "t3": "tue", "thứ 3": "tue", "thứ ba": "tue",
+		"wed": "wed", "wednesday": "wed", "t4": "wed", "thứ 4": "wed", "thứ tư": "wed",
+		"thu": "thu", "thursday": "thu", "t5": "thu", "thứ 5": "thu", "thứ năm": "thu",
+		"fri": "fri", "friday": "fri", "t6": "fri", "thứ 6": "fri", "thứ sáu": "fri",
+		"sat": "sat", "saturday": "sat", "t7": "sat", "thứ 7": "sat", "thứ bảy": "sat",
+		"sun": "sun", "sunday": "sun", "cn": "sun", "chủ nhật": "sun",
+	}
+	timeOfDayWords = map[string]ExerciseTime{
+		"morning": ExerciseTimeMorning, "sáng": ExerciseTimeMorning, "buổi sáng": ExerciseTimeMorning,
+		"midday": ExerciseTimeMidday, "noon": ExerciseTimeMidday, "trưa": ExerciseTimeMidday, "buổi trưa": ExerciseTimeMidday,
+		"afternoon": ExerciseTimeAfternoon, "chiều": ExerciseTimeAfternoon, "buổi chiều": ExerciseTimeAfternoon,
+		"evening": ExerciseTimeEvening, "tối": ExerciseTimeEvening, "buổi tối": ExerciseTimeEvening,
+		"bedtime": ExerciseTimeBedtime, "before bed": ExerciseTimeBedtime, "trước khi ngủ": ExerciseTimeBedtime,
+	}
+)
+
+// ParseFrequency reads a free-text frequency such as "2x/day", "3 times per
+// week", "daily", "2 lần/ngày, 5 ngày/tuần" or "1x/day, Mon, Wed, Fri
+// (morning)", "every other day" or "cách ngày", including everything Format
+// writes. It reports false for text it does not fully understand, e.g.
+// "as needed".
+func ParseFrequency(text string) (*FrequencySchedule, bool) {
+	s := strings.ToLower(strings.TrimSpace(text))
+	if s == "" {
+		return nil, false
+	}
+
+	var schedule FrequencySchedule
+	if m := frequencyTimesPattern.FindStringSubmatch(s); m != nil {
+		for _, word := range splitFrequencyList(m[1]) {
+			t, ok := timeOfDayWords[word]
+			if !ok {
+				return nil, false
+			}
+			schedule.TimesOfDay = append(schedule.TimesOfDay, t)
+		}
+		s = strings.TrimSpace(frequencyTimesPattern.ReplaceAllString(s, ""))
+	}
+
+	timesPerWeek := 0
+	for _, part := range splitFrequencyList(s) {
+		if phrase, ok := frequencyPhrases[part]; ok {
+			if phrase.TimesPerDay > schedule.TimesPerDay {
+				schedule.TimesPerDay = phrase.TimesPerDay
+			}
+			if phrase.DaysPerWeek > 0 {
+				schedule.DaysPerWeek = phrase.DaysPerWeek
+			}
+			if phrase.IntervalDays > 0 {
+				schedule.IntervalDays = phrase.IntervalDays
+			}
+			continue
+		}
+		if day, ok := weekdayWords[part]; ok {
+			schedule.Weekdays = append(schedule.Weekdays, day)
+			continue
+		}
+		if m := frequencyIntervalPattern.FindStringSubmatch(part); m != nil {
+			schedule.IntervalDays, _ = strconv.Atoi(m[1] + m[2])
+			continue
+		}
+		if m := frequencyDaysPattern.FindStringSubmatch(part); m != nil {
+			schedule.DaysPerWeek, _ = strconv.Atoi(m[1])
+			continue
+		}
+		if m := frequencyCountPattern.FindStringSubmatch(part); m != nil {
+			n, _ := strconv.Atoi(m[1])
+			switch m[2] {
+			case "day", "daily", "ngày", "ngay":
+				schedule.TimesPerDay = n
+			default:
+				timesPerWeek = n
+			}
+			continue
+		}
+		return nil, false
+	}
+
+	if timesPerWeek > 0 {
+		switch {
+		case schedule.TimesPerDay == 0 && timesPerWeek <= 7:
+			// Once a day on that many days
+			schedule.TimesPerDay = 1
+			schedule.DaysPerWeek = timesPerWeek
+		case schedule.TimesPerDay == 0 && timesPerWeek%7 == 0:
+			schedule.TimesPerDay = timesPerWeek / 7
+		default:
+			return nil, false
+		}
+	}
+	if schedule.TimesPerDay == 0 {
+		if len(schedule.Weekdays) == 0 && schedule.DaysPerWeek == 0 && schedule.IntervalDays == 0 {
+			return nil, false
+		}
+		schedule.TimesPerDay = 1
+	}
+	if schedule.TimesPerDay > 10 || schedule.DaysPerWeek > 7 || schedule.IntervalDays == 1 || schedule.IntervalDays > 7 || schedule.Validate() != nil {
+		return nil, false
+	}
+
+	schedule.Normalize()
+	return &schedule, true
+}
+
+// splitFrequencyList splits on commas, semicolons and "and", trimming spaces.
+func splitFrequencyList(s string) []string {
+	s = strings.NewReplacer(";", ",", " and ", ",", " và ", ",", " & ", ",").Replace(s)
+	var parts []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
@@ -0,0 +1,134 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseFrequency(t *testing.T) {
+	tests := []struct {
+		text   string
+		want   *FrequencySchedule
+		wantOK bool
+	}{
+		{"2x/day", &FrequencySchedule{TimesPerDay: 2}, true},
+		{"daily", &FrequencySchedule{TimesPerDay: 1}, true},
+		{"3 times per week", &FrequencySchedule{TimesPerDay: 1, DaysPerWeek: 3}, true},
+		{"2 lần/ngày, 5 ngày/tuần", &FrequencySchedule{TimesPerDay: 2, DaysPerWeek: 5}, true},
+		{"2x/day, Fri, Mon, Wed (evening, morning)", &FrequencySchedule{
+			TimesPerDay: 2,
+			Weekdays:    []string{"mon", "wed", "fri"},
+			TimesOfDay:  []ExerciseTime{ExerciseTimeMorning, ExerciseTimeEvening},
+		}, true},
+		{"14x/week", &FrequencySchedule{TimesPerDay: 2}, true},
+		{"as needed", nil, false},
+		{"every other day", &FrequencySchedule{TimesPerDay: 1, IntervalDays: 2}, true},
+		{"Cách ngày (sáng)", &FrequencySchedule{TimesPerDay: 1, IntervalDays: 2, TimesOfDay: []ExerciseTime{ExerciseTimeMorning}}, true},
+		{"bid", &FrequencySchedule{TimesPerDay: 2}, true},
+		{"every 1 days", nil, false},
+		{"10x/week", nil, false},
+		{"", nil, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ParseFrequency(tt.text)
+		if ok != tt.wantOK || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseFrequency(%q) = %+v, %v; want %+v, %v", tt.text, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestFrequencyFormat(t *testing.T) {
+	tests := []struct {
+		schedule FrequencySchedule
+		wantEn   string
+		wantVi   string
+	}{
+		{FrequencySchedule{TimesPerDay: 2, DaysPerWeek: 5}, "2x/day, 5 days/week", "2 lần/ngày, 5 ngày/tuần"},
+		{FrequencySchedule{TimesPerDay: 1, DaysPerWeek: 3}, "3x/week", "3 lần/tuần"},
+		{FrequencySchedule{TimesPerDay: 1}, "1x/day", "1 lần/ngày"},
+		{
+			FrequencySchedule{TimesPerDay: 2, Weekdays: []string{"mon", "thu"}, TimesOfDay: []ExerciseTime{ExerciseTimeMorning, ExerciseTimeEvening}},
+			"2x/day, Mon, Thu (morning, evening)",
+			"2 lần/ngày, T2, T5 (sáng, tối)",
+		},
+		{FrequencySchedule{TimesPerDay: 1, IntervalDays: 2}, "every other day", "cách ngày"},
+		{FrequencySchedule{TimesPerDay: 2, IntervalDays: 3}, "2x/day, every 3 days", "2 lần/ngày, 3 ngày/lần"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.schedule.Format("en"); got != tt.wantEn {
+			t.Errorf("Format(en) = %q; want %q", got, tt.wantEn)
+		}
+		if got := tt.schedule.Format("vi"); got != tt.wantVi {
+			t.Errorf("Format(vi) = %q; want %q", got, tt.wantVi)
+		}
+
+		// Everything Format writes reads back as the same schedule
+		for _, text := range []string{tt.wantEn, tt.wantVi} {
+			parsed, ok := ParseFrequency(text)
+			if !ok || !reflect.DeepEqual(*parsed, tt.schedule) {
+				t.Errorf("ParseFrequency(%q) = %+v, %v; want %+v", text, parsed, ok, tt.schedule)
+			}
+		}
+	}
+}
+
+func TestSessionsPerWeek(t *testing.T) {
+	tests := []struct {
+		frequency string
+		want      float64
+	}{
+		{"1x/day", 7},
+		{"2x/day", 14},
+		{"3x/week", 3},
+		{"Twice daily", 14},
+		{"2 lần/ngày, 5 ngày/tuần", 10},
+		{"1x/day, Mon, Wed, Fri (morning)", 3},
+		{"every other day", 3.5},
+		{"2x/day, every 7 days", 2},
+	}
+
+	for _, tt := range tests {
+		schedule, ok := ParseFrequency(tt.frequency)
+		if !ok {
+			t.Errorf("ParseFrequency(%q) not understood", tt.frequency)
+			continue
+		}
+		if got := schedule.SessionsPerWeek(); got != tt.want {
+			t.Errorf("SessionsPerWeek(%q) = %v; want %v", tt.frequency, got, tt.want)
+		}
+	}
+}
+
+func TestResolveFrequency(t *testing.T) {
+	text, schedule, err := ResolveFrequency("", &FrequencySchedule{
+		TimesPerDay: 5,
+		Weekdays:    []string{"sun", "mon", "tue", "wed", "thu", "fri"},
+		TimesOfDay:  []ExerciseTime{ExerciseTimeMorning, ExerciseTimeMidday, ExerciseTimeAfternoon, ExerciseTimeEvening, ExerciseTimeBedtime},
+	})
+	if err != nil {
+		t.Fatalf("ResolveFrequency() error = %v", err)
+	}
+	if want := "5x/day, Mon, Tue, Wed, Thu, Fri, Sun"; text != want {
+		t.Errorf("text = %q; want %q", text, want)
+	}
+	if len(schedule.TimesOfDay) != 5 {
+		t.Errorf("times of day = %v; want all five kept in the schedule", schedule.TimesOfDay)
+	}
+
+	text, schedule, err = ResolveFrequency(" as needed ", nil)
+	if err != nil || text != "as needed" || schedule != nil {
+		t.Errorf("ResolveFrequency(as needed) = %q, %+v, %v; want text without schedule", text, schedule, err)
+	}
+
+	if _, _, err := ResolveFrequency("", &FrequencySchedule{TimesPerDay: 1, DaysPerWeek: 3, Weekdays: []string{"mon"}}); err == nil {
+		t.Error("ResolveFrequency() with days_per_week and weekdays: want error")
+	}
+	if _, _, err := ResolveFrequency("", &FrequencySchedule{TimesPerDay: 1, DaysPerWeek: 3, IntervalDays: 2}); err == nil {
+		t.Error("ResolveFrequency() with days_per_week and interval_days: want error")
+	}
+	if _, _, err := ResolveFrequency("", nil); err == nil {
+		t.Error("ResolveFrequency() with nothing: want error")
+	}
+}
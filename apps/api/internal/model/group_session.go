@@ -0,0 +1,216 @@
+package model
+
+import "time"
+
+// GroupSessionStatus represents the status of a group session.
+type GroupSessionStatus string
+
+const (
+	GroupSessionStatusScheduled GroupSessionStatus = "scheduled"
+	GroupSessionStatusCancelled GroupSessionStatus = "cancelled"
+)
+
+// ParticipantStatus represents a patient's place on a group session roster.
+type ParticipantStatus string
+
+const (
+	ParticipantStatusBooked     ParticipantStatus = "booked"
+	ParticipantStatusWaitlisted ParticipantStatus = "waitlisted"
+	ParticipantStatusAttended   ParticipantStatus = "attended"
+	ParticipantStatusNoShow     ParticipantStatus = "no_show"
+	ParticipantStatusCancelled  ParticipantStatus = "cancelled"
+)
+
+// HoldsSeat reports whether a participant in this status takes one of the
+// session's places.
+func (s ParticipantStatus) HoldsSeat() bool {
+	return s == ParticipantStatusBooked || s == ParticipantStatusAttended || s == ParticipantStatusNoShow
+}
+
+// AppointmentStatus returns the status of the participant's own appointment.
+// Waitlisted participants have no appointment.
+func (s ParticipantStatus) AppointmentStatus() AppointmentStatus {
+	switch s {
+	case ParticipantStatusAttended:
+		return AppointmentStatusCompleted
+	case ParticipantStatusNoShow:
+		return AppointmentStatusNoShow
+	case ParticipantStatusCancelled:
+		return AppointmentStatusCancelled
+	default:
+		return AppointmentStatusScheduled
+	}
+}
+
+// GroupSession is a class led by one therapist that several patients attend
+// together, such as a balance or back class. Each booked participant gets an
+// appointment of their own linked to the session; the session itself blocks
+// the therapist's time and its resources.
+type GroupSession struct {
+	ID                 string             `json:"id" db:"id"`
+	ClinicID           string             `json:"clinic_id" db:"clinic_id"`
+	TherapistID        string             `json:"therapist_id" db:"therapist_id"`
+	AppointmentTypeID  *string            `json:"appointment_type_id,omitempty" db:"appointment_type_id"`
+	Type               AppointmentType    `json:"type" db:"type"`
+	Title              string             `json:"title" db:"title"`
+	StartTime          time.Time          `json:"start_time" db:"start_time"`
+	EndTime            time.Time          `json:"end_time" db:"end_time"`
+	Duration           int                `json:"duration" db:"duration"` // minutes
+	Capacity           int                `json:"capacity" db:"capacity"`
+	Room               string             `json:"room,omitempty" db:"room"`
+	ResourceIDs        []string           `json:"resource_ids" db:"resource_ids"`
+	Status             GroupSessionStatus `json:"status" db:"status"`
+	Notes              string             `json:"notes,omitempty" db:"notes"`
+	CancellationReason string             `json:"cancellation_reason,omitempty" db:"cancellation_reason"`
+	CreatedAt          time.Time          `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time          `json:"updated_at" db:"updated_at"`
+	CreatedBy          *string            `json:"created_by,omitempty" db:"created_by"`
+	UpdatedBy          *string            `json:"updated_by,omitempty" db:"updated_by"`
+
+	// Computed from the roster
+	TherapistName string `json:"therapist_name,omitempty" db:"therapist_name"`
+	BookedCount   int    `json:"booked_count" db:"booked_count"`
+	WaitlistCount int    `json:"waitlist_count" db:"waitlist_count"`
+}
+
+// SeatsLeft returns how many more patients can be booked.
+func (s GroupSession) SeatsLeft() int {
+	if left := s.Capacity - s.BookedCount; left > 0 {
+		return left
+	}
+	return 0
+}
+
+// AsAppointment returns the session as a single booking of the therapist and
+// its resources, for conflict and capacity checks against other appointments.
+func (s GroupSession) AsAppointment() Appointment {
+	status := AppointmentStatusScheduled
+	if s.Status == GroupSessionStatusCancelled {
+		status = AppointmentStatusCancelled
+	}
+	id := s.ID
+	return Appointment{
+		ID:             s.ID,
+		ClinicID:       s.ClinicID,
+		TherapistID:    s.TherapistID,
+		StartTime:      s.StartTime,
+		EndTime:        s.EndTime,
+		Duration:       s.Duration,
+		Type:           s.Type,
+		Status:         status,
+		Room:           s.Room,
+		Notes:          s.Title,
+		ResourceIDs:    s.ResourceIDs,
+		GroupSessionID: &id,
+	}
+}
+
+// ParticipantAppointment returns the appointment of a patient booked into the session.
+func (s GroupSession) ParticipantAppointment(id, patientID string, createdBy *string) *Appointment {
+	sessionID := s.ID
+	return &Appointment{
+		ID:             id,
+		ClinicID:       s.ClinicID,
+		PatientID:      patientID,
+		TherapistID:    s.TherapistID,
+		StartTime:      s.StartTime,
+		EndTime:        s.EndTime,
+		Duration:       s.Duration,
+		Type:           s.Type,
+		Status:         AppointmentStatusScheduled,
+		Room:           s.Room,
+		Notes:          s.Title,
+		GroupSessionID: &sessionID,
+		CreatedBy:      createdBy,
+		UpdatedBy:      createdBy,
+	}
+}
+
+// GroupParticipant is a patient on a group session roster.
+type GroupParticipant struct {
+	ID               string            `json:"id" db:"id"`
+	SessionID        string            `json:"session_id" db:"session_id"`
+	PatientID        string            `json:"patient_id" db:"patient_id"`
+	AppointmentID    *string           `json:"appointment_id,omitempty" db:"appointment_id"`
+	Status           ParticipantStatus `json:"status" db:"status"`
+	WaitlistPosition *int              `json:"waitlist_position,omitempty" db:"waitlist_position"`
+	Notes            string            `json:"notes,omitempty" db:"notes"`
+	CreatedAt        time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time         `json:"updated_at" db:"updated_at"`
+	CreatedBy        *string           `json:"created_by,omitempty" db:"created_by"`
+	UpdatedBy        *string           `json:"updated_by,omitempty" db:"updated_by"`
+
+	PatientName string `json:"patient_name" db:"patient_name"`
+	PatientMRN  string `json:"patient_mrn" db:"patient_mrn"`
+}
+
+// GroupSessionWithRoster is a group session with its participants, booked
+// first and then the waitlist in order.
+type GroupSessionWithRoster struct {
+	GroupSession
+	Participants []GroupParticipant `json:"participants"`
+}
+
+// GroupSessionSearchParams filters the list of group sessions.
+type GroupSessionSearchParams struct {
+	ClinicID         string
+	TherapistID      string
+	From             time.Time
+	To               time.Time
+	IncludeCancelled bool
+}
+
+// CreateGroupSessionRequest represents the request body for scheduling a
+// group session by appointment type ID or code. Duration and capacity default
+// to those of a configured appointment type, which must allow groups.
+type CreateGroupSessionRequest struct {
+	TherapistID       string   `json:"therapist_id" validate:"required,uuid"`
+	AppointmentTypeID string   `json:"appointment_type_id" validate:"omitempty,uuid"`
+	Type              string   `json:"type" validate:"required_without=AppointmentTypeID,omitempty,max=50"`
+	Title             string   `json:"title" validate:"required,max=200"`
+	StartTime         string   `json:"start_time" validate:"required,datetime=2006-01-02T15:04:05Z07:00"`
+	Duration          int      `json:"duration" validate:"required_without=AppointmentTypeID,omitempty,min=15,max=240"`
+	Capacity          int      `json:"capacity" validate:"required_without=AppointmentTypeID,omitempty,min=2,max=50"`
+	Room              string   `json:"room" validate:"max=100"`
+	ResourceIDs       []string `json:"resource_ids" validate:"omitempty,max=10,dive,uuid"`
+	Notes             string   `json:"notes" validate:"max=1000"`
+}
+
+// UpdateGroupSessionRequest represents the request body for changing a group
+// session. Moving the session moves every participant's appointment with it;
+// raising the capacity books patients from the waitlist.
+type UpdateGroupSessionRequest struct {
+	TherapistID *string `json:"therapist_id" validate:"omitempty,uuid"`
+	Title       *string `json:"title" validate:"omitempty,min=1,max=200"`
+	StartTime   *string `json:"start_time" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Duration    *int    `json:"duration" validate:"omitempty,min=15,max=240"`
+	Capacity    *int    `json:"capacity" validate:"omitempty,min=2,max=50"`
+	Room        *string `json:"room" validate:"omitempty,max=100"`
+	Notes       *string `json:"notes" validate:"omitempty,max=1000"`
+
+	// ResourceIDs replaces the required resources when present; an empty
+	// list removes them all.
+	ResourceIDs []string `json:"resource_ids" validate:"omitempty,max=10,dive,uuid"`
+}
+
+// CancelGroupSessionRequest represents the request body for cancelling a
+// group session and every participant's appointment.
+type CancelGroupSessionRequest struct {
+	Reason string `json:"reason" validate:"max=500"`
+}
+
+// AddParticipantRequest represents the request body for adding a patient to
+// a group session. A full session puts the patient on the waitlist unless
+// the waitlist is declined.
+type AddParticipantRequest struct {
+	PatientID       string `json:"patient_id" validate:"required,uuid"`
+	Notes           string `json:"notes" validate:"max=1000"`
+	DeclineWaitlist bool   `json:"decline_waitlist"`
+}
+
+// UpdateParticipantRequest represents the request body for recording a
+// participant's attendance, removing them, or changing their notes.
+type UpdateParticipantRequest struct {
+	Status *string `json:"status" validate:"omitempty,oneof=booked attended no_show cancelled"`
+	Notes  *string `json:"notes" validate:"omitempty,max=1000"`
+}
@@ -0,0 +1,91 @@
+package model
+
+import "time"
+
+// KioskDevice is a self check-in kiosk. Its token is a credential that only
+// permits kiosk check-in for the device's clinic, so only its hash is stored
+// and a device can be revoked at any time.
+type KioskDevice struct {
+	ID         string     `json:"id" db:"id"`
+	ClinicID   string     `json:"clinic_id" db:"clinic_id"`
+	Name       string     `json:"name" db:"name"`
+	TokenHash  string     `json:"-" db:"token_hash"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	RevokedBy  *string    `json:"revoked_by,omitempty" db:"revoked_by"`
+	CreatedBy  string     `json:"created_by" db:"created_by"`
+	LastSeenAt *time.Time `json:"last_seen_at,omitempty" db:"last_seen_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+
+	// Token is set only when the device is registered; it cannot be read back.
+	Token string `json:"-" db:"-"`
+}
+
+// IsActive reports whether the device has not been revoked.
+func (d *KioskDevice) IsActive() bool {
+	return d.RevokedAt == nil
+}
+
+// RegisterKioskRequest represents the request to register a kiosk device.
+type RegisterKioskRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+// CheckInToken is a signed, short-lived code a patient scans at a kiosk to
+// check in for an appointment, usually shown as a QR code in reminders.
+type CheckInToken struct {
+	AppointmentID string    `json:"appointment_id"`
+	Token         string    `json:"token"`
+	OpensAt       time.Time `json:"opens_at"`   // check-in is accepted from this time
+	ExpiresAt     time.Time `json:"expires_at"` // the token is rejected after this time
+}
+
+// KioskLookupRequest represents a scanned check-in code.
+type KioskLookupRequest struct {
+	Token string `json:"token" validate:"required,max=200"`
+}
+
+// KioskCheckInRequest represents a patient checking in at a kiosk after
+// reviewing their details.
+type KioskCheckInRequest struct {
+	Token                 string `json:"token" validate:"required,max=200"`
+	DemographicsConfirmed bool   `json:"demographics_confirmed"`
+	InsuranceConfirmed    bool   `json:"insurance_confirmed"`
+	Notes                 string `json:"notes" validate:"max=1000"` // corrections for the front desk
+}
+
+// KioskCheckIn is what a kiosk shows a patient for review before and after
+// checking in.
+type KioskCheckIn struct {
+	AppointmentID string           `json:"appointment_id"`
+	StartTime     time.Time        `json:"start_time"`
+	EndTime       time.Time        `json:"end_time"`
+	Type          AppointmentType  `json:"type"`
+	TherapistName string           `json:"therapist_name"`
+	Room          string           `json:"room,omitempty"`
+	Patient       KioskPatient     `json:"patient"`
+	Insurance     []KioskInsurance `json:"insurance"`
+	CheckedInAt   *time.Time       `json:"checked_in_at,omitempty"`
+}
+
+// KioskPatient is the demographic information a patient confirms at a kiosk.
+type KioskPatient struct {
+	FirstName   string    `json:"first_name"`
+	LastName    string    `json:"last_name"`
+	FirstNameVi string    `json:"first_name_vi,omitempty"`
+	LastNameVi  string    `json:"last_name_vi,omitempty"`
+	DateOfBirth time.Time `json:"date_of_birth"`
+	Phone       string    `json:"phone,omitempty"`
+	Email       string    `json:"email,omitempty"`
+	Address     string    `json:"address,omitempty"`
+	AddressVi   string    `json:"address_vi,omitempty"`
+}
+
+// KioskInsurance is an insurance policy as shown at a kiosk, with the policy
+// number masked.
+type KioskInsurance struct {
+	Provider     string     `json:"provider"`
+	PolicyNumber string     `json:"policy_number"` // last four characters only
+	ValidTo      *time.Time `json:"valid_to,omitempty"`
+	IsPrimary    bool       `json:"is_primary"`
+	Valid        bool       `json:"valid"` // active and within its validity period on the appointment day
+}
@@ -179,6 +179,7 @@ type PatientDashboard struct {
 	NextAppointment      *time.Time           `json:"next_appointment,omitempty"`
 	InsuranceInfo        []PatientInsurance   `json:"insurance_info,omitempty"`
 	RecentNotes          []PatientNote        `json:"recent_notes,omitempty"`
+	Attendance           *PatientAttendance   `json:"attendance,omitempty"`
 }
 
 // PatientInsurance represents insurance information for a patient.
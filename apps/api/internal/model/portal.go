@@ -0,0 +1,54 @@
+package model
+
+// PortalPolicy holds the clinic rules that govern patient self-service.
+type PortalPolicy struct {
+	CancelNoticeHours     int  `json:"cancel_notice_hours"`
+	RescheduleNoticeHours int  `json:"reschedule_notice_hours"`
+	AllowReschedule       bool `json:"allow_reschedule"`
+	MaxAdvanceDays        int  `json:"max_advance_days"`
+}
+
+// DefaultPortalPolicy returns the policy used when a clinic has not configured one.
+func DefaultPortalPolicy() PortalPolicy {
+	return PortalPolicy{
+		CancelNoticeHours:     24,
+		RescheduleNoticeHours: 24,
+		AllowReschedule:       true,
+		MaxAdvanceDays:        60,
+	}
+}
+
+// PortalCancelRequest represents a patient's request to cancel their appointment.
+type PortalCancelRequest struct {
+	Reason string `json:"reason" validate:"max=500"`
+}
+
+// PortalRescheduleRequest represents a patient's request to move their appointment.
+type PortalRescheduleRequest struct {
+	StartTime string `json:"start_time" validate:"required"`
+}
+
+// PortalExercisePlan represents a patient's active home exercise program.
+type PortalExercisePlan struct {
+	Programs      []HomeExerciseProgram  `json:"programs"`
+	Prescriptions []ExercisePrescription `json:"prescriptions"`
+}
+
+// PortalProgress represents a patient's pain and ROM trends.
+type PortalProgress struct {
+	Pain []QuickPainRecord `json:"pain"`
+	ROM  []QuickROMRecord  `json:"rom"`
+}
+
+// PortalActor identifies who is using the patient portal. A caregiver acting
+// for a patient carries the proxy grant that authorizes them; a patient acting
+// for themselves has no grant.
+type PortalActor struct {
+	UserID string
+	Grant  *ProxyGrant
+}
+
+// IsProxy reports whether the actor is a caregiver acting through a grant.
+func (a PortalActor) IsProxy() bool {
+	return a.Grant != nil
+}
@@ -0,0 +1,214 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ProgressionParameter is a prescription parameter whose changes are tracked.
+type ProgressionParameter string
+
+const (
+	ProgressionParamSets          ProgressionParameter = "sets"
+	ProgressionParamReps          ProgressionParameter = "reps"
+	ProgressionParamHoldSeconds   ProgressionParameter = "hold_seconds"
+	ProgressionParamDurationWeeks ProgressionParameter = "duration_weeks" // manual changes only
+)
+
+// Limit returns the highest value a prescription allows for the parameter.
+func (p ProgressionParameter) Limit() int {
+	switch p {
+	case ProgressionParamSets:
+		return 20
+	case ProgressionParamReps:
+		return 100
+	case ProgressionParamHoldSeconds:
+		return 300
+	case ProgressionParamDurationWeeks:
+		return 52
+	}
+	return 0
+}
+
+// Value returns the parameter's value on a prescription.
+func (p ProgressionParameter) Value(prescription *ExercisePrescription) int {
+	switch p {
+	case ProgressionParamSets:
+		return prescription.Sets
+	case ProgressionParamReps:
+		return prescription.Reps
+	case ProgressionParamHoldSeconds:
+		return prescription.HoldSeconds
+	case ProgressionParamDurationWeeks:
+		return prescription.DurationWeeks
+	}
+	return 0
+}
+
+// ComplianceDifficulty is how hard a patient found an exercise, as logged.
+type ComplianceDifficulty string
+
+const (
+	ComplianceDifficultyEasy     ComplianceDifficulty = "easy"
+	ComplianceDifficultyModerate ComplianceDifficulty = "moderate"
+	ComplianceDifficultyHard     ComplianceDifficulty = "hard"
+)
+
+// Rank orders difficulties from easy (1) to hard (3); unknown values rank 0.
+func (d ComplianceDifficulty) Rank() int {
+	switch d {
+	case ComplianceDifficultyEasy:
+		return 1
+	case ComplianceDifficultyModerate:
+		return 2
+	case ComplianceDifficultyHard:
+		return 3
+	}
+	return 0
+}
+
+// PrescriptionChangeSource is what made a prescription change.
+type PrescriptionChangeSource string
+
+const (
+	PrescriptionChangeRule   PrescriptionChangeSource = "rule"
+	PrescriptionChangeManual PrescriptionChangeSource = "manual"
+)
+
+// PrescriptionChangeStatus is the state of a prescription change.
+type PrescriptionChangeStatus string
+
+const (
+	PrescriptionChangeProposed PrescriptionChangeStatus = "proposed"
+	PrescriptionChangeApplied  PrescriptionChangeStatus = "applied"
+	PrescriptionChangeRejected PrescriptionChangeStatus = "rejected"
+)
+
+// ProgressionPolicy holds the clinic rules for exercise progression, stored in
+// the clinic settings under exercise_progression.
+type ProgressionPolicy struct {
+	// AutoApply applies progressions as soon as a rule's conditions are met
+	// instead of waiting for a therapist to approve them.
+	AutoApply bool `json:"auto_apply"`
+}
+
+// DefaultProgressionPolicy returns the policy used when a clinic has not configured one.
+func DefaultProgressionPolicy() ProgressionPolicy {
+	return ProgressionPolicy{AutoApply: false}
+}
+
+// ProgressionRule progresses a prescription parameter by Step every
+// IntervalDays while the patient's compliance logs in that window meet its
+// conditions, e.g. +2 reps weekly while pain stays at 3 or below and the
+// exercise feels easy.
+type ProgressionRule struct {
+	ID             string               `json:"id" db:"id"`
+	ClinicID       string               `json:"clinic_id" db:"clinic_id"`
+	PrescriptionID string               `json:"prescription_id" db:"prescription_id"`
+	Parameter      ProgressionParameter `json:"parameter" db:"parameter"`
+	Step           int                  `json:"step" db:"step"`
+	MaxValue       *int                 `json:"max_value,omitempty" db:"max_value"`
+	IntervalDays   int                  `json:"interval_days" db:"interval_days"`
+	// MinSessions is how many logs in the window must meet the conditions.
+	MinSessions   int                  `json:"min_sessions" db:"min_sessions"`
+	MaxPain       *int                 `json:"max_pain,omitempty" db:"max_pain"`
+	MaxDifficulty ComplianceDifficulty `json:"max_difficulty,omitempty" db:"max_difficulty"`
+	IsActive      bool                 `json:"is_active" db:"is_active"`
+	// WindowStart is where the window of logs for the next evaluation begins.
+	WindowStart time.Time `json:"window_start" db:"window_start"`
+	CreatedBy   *string   `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+
+	// Description is the rule in words, for display
+	Description string `json:"description" db:"-"`
+}
+
+// Ceiling returns the highest value the rule progresses to.
+func (r ProgressionRule) Ceiling() int {
+	limit := r.Parameter.Limit()
+	if r.MaxValue != nil && *r.MaxValue < limit {
+		return *r.MaxValue
+	}
+	return limit
+}
+
+// Interval returns how long each evaluation window is.
+func (r ProgressionRule) Interval() time.Duration {
+	return time.Duration(r.IntervalDays) * 24 * time.Hour
+}
+
+// Describe returns the rule in words, e.g. "+2 reps every 7 days (up to 20)
+// while pain is at most 3 and difficulty is at most easy, over at least 3
+// sessions".
+func (r ProgressionRule) Describe() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "+%d %s every %d days (up to %d)", r.Step, strings.ReplaceAll(string(r.Parameter), "_", " "), r.IntervalDays, r.Ceiling())
+
+	var conditions []string
+	if r.MaxPain != nil {
+		conditions = append(conditions, fmt.Sprintf("pain is at most %d", *r.MaxPain))
+	}
+	if r.MaxDifficulty != "" {
+		conditions = append(conditions, fmt.Sprintf("difficulty is at most %s", r.MaxDifficulty))
+	}
+	if len(conditions) > 0 {
+		b.WriteString(" while " + strings.Join(conditions, " and "))
+	}
+	fmt.Fprintf(&b, ", over at least %d sessions", r.MinSessions)
+	return b.String()
+}
+
+// PrescriptionChange is an entry in a prescription's parameter history: a
+// manual edit, or a progression proposed by a rule and then applied
+// (automatically or by a therapist) or rejected.
+type PrescriptionChange struct {
+	ID             string                   `json:"id" db:"id"`
+	ClinicID       string                   `json:"clinic_id" db:"clinic_id"`
+	PrescriptionID string                   `json:"prescription_id" db:"prescription_id"`
+	PatientID      string                   `json:"patient_id" db:"patient_id"`
+	RuleID         *string                  `json:"rule_id,omitempty" db:"rule_id"`
+	Source         PrescriptionChangeSource `json:"source" db:"source"`
+	Parameter      ProgressionParameter     `json:"parameter" db:"parameter"`
+	FromValue      int                      `json:"from_value" db:"from_value"`
+	ToValue        int                      `json:"to_value" db:"to_value"`
+	Status         PrescriptionChangeStatus `json:"status" db:"status"`
+	Reason         string                   `json:"reason,omitempty" db:"reason"`
+	DecisionNote   string                   `json:"decision_note,omitempty" db:"decision_note"`
+	ProposedAt     time.Time                `json:"proposed_at" db:"proposed_at"`
+	DecidedAt      *time.Time               `json:"decided_at,omitempty" db:"decided_at"`
+	DecidedBy      *string                  `json:"decided_by,omitempty" db:"decided_by"`
+}
+
+// CreateProgressionRuleRequest represents the request to add a progression
+// rule to a prescription.
+type CreateProgressionRuleRequest struct {
+	Parameter     string `json:"parameter" validate:"required,oneof=sets reps hold_seconds"`
+	Step          int    `json:"step" validate:"required,min=1,max=60"`
+	MaxValue      *int   `json:"max_value" validate:"omitempty,min=1,max=300"`
+	IntervalDays  int    `json:"interval_days" validate:"required,min=1,max=90"`
+	MinSessions   int    `json:"min_sessions" validate:"omitempty,min=1,max=100"`
+	MaxPain       *int   `json:"max_pain" validate:"omitempty,min=0,max=10"`
+	MaxDifficulty string `json:"max_difficulty" validate:"omitempty,oneof=easy moderate hard"`
+}
+
+// UpdateProgressionRuleRequest represents the request to change a progression
+// rule. Setting clear_max_pain or clear_max_difficulty removes that condition.
+type UpdateProgressionRuleRequest struct {
+	Step               *int    `json:"step" validate:"omitempty,min=1,max=60"`
+	MaxValue           *int    `json:"max_value" validate:"omitempty,min=1,max=300"`
+	IntervalDays       *int    `json:"interval_days" validate:"omitempty,min=1,max=90"`
+	MinSessions        *int    `json:"min_sessions" validate:"omitempty,min=1,max=100"`
+	MaxPain            *int    `json:"max_pain" validate:"omitempty,min=0,max=10"`
+	ClearMaxPain       bool    `json:"clear_max_pain"`
+	MaxDifficulty      *string `json:"max_difficulty" validate:"omitempty,oneof=easy moderate hard"`
+	ClearMaxDifficulty bool    `json:"clear_max_difficulty"`
+	IsActive           *bool   `json:"is_active"`
+}
+
+// DecideProgressionRequest represents a therapist's approval or rejection of
+// a proposed progression.
+type DecideProgressionRequest struct {
+	Note string `json:"note" validate:"max=500"`
+}
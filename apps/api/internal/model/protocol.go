@@ -0,0 +1,131 @@
+package model
+
+import "time"
+
+// ProgramPhaseStatus represents the state of a program phase.
+type ProgramPhaseStatus string
+
+const (
+	ProgramPhaseUpcoming  ProgramPhaseStatus = "upcoming"
+	ProgramPhaseActive    ProgramPhaseStatus = "active"
+	ProgramPhaseCompleted ProgramPhaseStatus = "completed"
+)
+
+// ExerciseProtocol is a clinic's reusable, phased exercise program, e.g. for
+// ACL reconstruction or frozen shoulder, that is instantiated into a
+// HomeExerciseProgram for each patient.
+type ExerciseProtocol struct {
+	ID            string          `json:"id" db:"id"`
+	ClinicID      string          `json:"clinic_id" db:"clinic_id"`
+	Name          string          `json:"name" db:"name"`
+	NameVi        string          `json:"name_vi,omitempty" db:"name_vi"`
+	Description   string          `json:"description,omitempty" db:"description"`
+	DescriptionVi string          `json:"description_vi,omitempty" db:"description_vi"`
+	Frequency     string          `json:"frequency" db:"frequency"`
+	Phases        []ProtocolPhase `json:"phases" db:"phases"`
+	Version       int             `json:"version" db:"version"`
+	IsActive      bool            `json:"is_active" db:"is_active"`
+	CreatedBy     *string         `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt     time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// DurationWeeks returns the total length of the protocol's phases.
+func (p ExerciseProtocol) DurationWeeks() int {
+	total := 0
+	for _, phase := range p.Phases {
+		total += phase.DurationWeeks
+	}
+	return total
+}
+
+// ProtocolPhase is one phase of a protocol.
+type ProtocolPhase struct {
+	Name          string             `json:"name" validate:"required,max=200"`
+	NameVi        string             `json:"name_vi,omitempty" validate:"max=200"`
+	DurationWeeks int                `json:"duration_weeks" validate:"min=1,max=52"`
+	Exercises     []ProtocolExercise `json:"exercises" validate:"required,min=1,max=30,dive"`
+}
+
+// ProtocolExercise is an exercise of a protocol phase with its default
+// parameters. An empty Frequency uses the protocol's.
+type ProtocolExercise struct {
+	ExerciseID         string `json:"exercise_id" validate:"required,uuid"`
+	Sets               int    `json:"sets" validate:"min=1,max=20"`
+	Reps               int    `json:"reps" validate:"min=1,max=100"`
+	HoldSeconds        int    `json:"hold_seconds" validate:"min=0,max=300"`
+	Frequency          string `json:"frequency,omitempty" validate:"max=50"`
+	CustomInstructions string `json:"custom_instructions,omitempty" validate:"max=2000"`
+}
+
+// ProgramPhase is a phase of a patient's program, copied from the protocol
+// when the program was created. Its prescriptions are created when it starts
+// and completed when it ends.
+type ProgramPhase struct {
+	ID              string             `json:"id" db:"id"`
+	ProgramID       string             `json:"program_id" db:"program_id"`
+	ClinicID        string             `json:"clinic_id" db:"clinic_id"`
+	ProtocolID      *string            `json:"protocol_id,omitempty" db:"protocol_id"`
+	ProtocolVersion int                `json:"protocol_version" db:"protocol_version"`
+	PhaseNumber     int                `json:"phase_number" db:"phase_number"`
+	Name            string             `json:"name" db:"name"`
+	NameVi          string             `json:"name_vi,omitempty" db:"name_vi"`
+	StartDate       time.Time          `json:"start_date" db:"start_date"`
+	EndDate         time.Time          `json:"end_date" db:"end_date"` // first day after the phase
+	Exercises       []ProtocolExercise `json:"exercises" db:"exercises"`
+	PrescriptionIDs []string           `json:"prescription_ids" db:"prescription_ids"`
+	Status          ProgramPhaseStatus `json:"status" db:"status"`
+	ActivatedAt     *time.Time         `json:"activated_at,omitempty" db:"activated_at"`
+	CompletedAt     *time.Time         `json:"completed_at,omitempty" db:"completed_at"`
+}
+
+// CreateProtocolRequest represents the request to create a protocol.
+type CreateProtocolRequest struct {
+	Name          string          `json:"name" validate:"required,max=200"`
+	NameVi        string          `json:"name_vi" validate:"max=200"`
+	Description   string          `json:"description" validate:"max=2000"`
+	DescriptionVi string          `json:"description_vi" validate:"max=2000"`
+	Frequency     string          `json:"frequency" validate:"required,max=50"`
+	Phases        []ProtocolPhase `json:"phases" validate:"required,min=1,max=12,dive"`
+}
+
+// UpdateProtocolRequest represents the request to update a protocol.
+// Programs already created from it are not changed.
+type UpdateProtocolRequest struct {
+	Name          *string         `json:"name" validate:"omitempty,max=200"`
+	NameVi        *string         `json:"name_vi" validate:"omitempty,max=200"`
+	Description   *string         `json:"description" validate:"omitempty,max=2000"`
+	DescriptionVi *string         `json:"description_vi" validate:"omitempty,max=2000"`
+	Frequency     *string         `json:"frequency" validate:"omitempty,max=50"`
+	Phases        []ProtocolPhase `json:"phases" validate:"omitempty,min=1,max=12,dive"`
+	IsActive      *bool           `json:"is_active"`
+}
+
+// InstantiateProtocolRequest represents the request to create a patient's
+// home exercise program from a protocol.
+type InstantiateProtocolRequest struct {
+	ProtocolID string                  `json:"protocol_id" validate:"required,uuid"`
+	StartDate  string                  `json:"start_date" validate:"required,datetime=2006-01-02"`
+	Name       string                  `json:"name" validate:"max=200"`
+	NameVi     string                  `json:"name_vi" validate:"max=200"`
+	Frequency  string                  `json:"frequency" validate:"max=50"`
+	Phases     []ProtocolPhaseOverride `json:"phases" validate:"omitempty,dive"`
+}
+
+// ProtocolPhaseOverride adjusts one phase, numbered from 1, for a patient.
+type ProtocolPhaseOverride struct {
+	Phase         int                        `json:"phase" validate:"required,min=1"`
+	DurationWeeks *int                       `json:"duration_weeks" validate:"omitempty,min=1,max=52"`
+	Exercises     []ProtocolExerciseOverride `json:"exercises" validate:"omitempty,dive"`
+}
+
+// ProtocolExerciseOverride adjusts or leaves out one exercise of a phase.
+type ProtocolExerciseOverride struct {
+	ExerciseID         string  `json:"exercise_id" validate:"required,uuid"`
+	Sets               *int    `json:"sets" validate:"omitempty,min=1,max=20"`
+	Reps               *int    `json:"reps" validate:"omitempty,min=1,max=100"`
+	HoldSeconds        *int    `json:"hold_seconds" validate:"omitempty,min=0,max=300"`
+	Frequency          *string `json:"frequency" validate:"omitempty,max=50"`
+	CustomInstructions *string `json:"custom_instructions" validate:"omitempty,max=2000"`
+	Exclude            bool    `json:"exclude"`
+}
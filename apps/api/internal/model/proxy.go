@@ -0,0 +1,65 @@
+package model
+
+import "time"
+
+// ProxyScope represents an action a caregiver may take on a patient's behalf.
+type ProxyScope string
+
+const (
+	ProxyScopeViewProfile        ProxyScope = "view_profile"
+	ProxyScopeViewAppointments   ProxyScope = "view_appointments"
+	ProxyScopeManageAppointments ProxyScope = "manage_appointments"
+	ProxyScopeViewExercises      ProxyScope = "view_exercises"
+	ProxyScopeLogExercises       ProxyScope = "log_exercises"
+	ProxyScopeViewProgress       ProxyScope = "view_progress"
+	ProxyScopeMessageClinic      ProxyScope = "message_clinic"
+)
+
+// ProxyGrant represents a caregiver's permission to act on behalf of a patient.
+type ProxyGrant struct {
+	ID               string       `json:"id" db:"id"`
+	ClinicID         string       `json:"clinic_id" db:"clinic_id"`
+	PatientID        string       `json:"patient_id" db:"patient_id"`
+	ProxyUserID      string       `json:"proxy_user_id" db:"proxy_user_id"`
+	ProxyName        string       `json:"proxy_name" db:"proxy_name"`
+	Relationship     string       `json:"relationship,omitempty" db:"relationship"`
+	Phone            string       `json:"phone,omitempty" db:"phone"`
+	Scopes           []ProxyScope `json:"scopes" db:"scopes"`
+	StartsAt         time.Time    `json:"starts_at" db:"starts_at"`
+	ExpiresAt        time.Time    `json:"expires_at" db:"expires_at"`
+	RevokedAt        *time.Time   `json:"revoked_at,omitempty" db:"revoked_at"`
+	RevokedBy        *string      `json:"revoked_by,omitempty" db:"revoked_by"`
+	GrantedBy        string       `json:"granted_by" db:"granted_by"`
+	GrantedByPatient bool         `json:"granted_by_patient" db:"granted_by_patient"`
+	CreatedAt        time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time    `json:"updated_at" db:"updated_at"`
+
+	// Joined fields
+	PatientName string `json:"patient_name,omitempty" db:"-"`
+}
+
+// IsActiveAt reports whether the grant is in effect at the given time.
+func (g *ProxyGrant) IsActiveAt(t time.Time) bool {
+	return g.RevokedAt == nil && !t.Before(g.StartsAt) && t.Before(g.ExpiresAt)
+}
+
+// HasScope reports whether the grant permits the given action.
+func (g *ProxyGrant) HasScope(scope ProxyScope) bool {
+	for _, s := range g.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateProxyGrantRequest represents the request body for granting proxy access.
+type CreateProxyGrantRequest struct {
+	ProxyUserID  string   `json:"proxy_user_id" validate:"required,uuid"`
+	ProxyName    string   `json:"proxy_name" validate:"required,min=1,max=200"`
+	Relationship string   `json:"relationship" validate:"max=100"`
+	Phone        string   `json:"phone" validate:"omitempty,max=20"`
+	Scopes       []string `json:"scopes" validate:"required,min=1,dive,oneof=view_profile view_appointments manage_appointments view_exercises log_exercises view_progress message_clinic"`
+	StartsAt     string   `json:"starts_at"`
+	ExpiresAt    string   `json:"expires_at" validate:"required"`
+}
@@ -0,0 +1,104 @@
+package model
+
+import "time"
+
+// MaxSeriesOccurrences caps how many appointments a single recurring series
+// may create (one year of weekday sessions).
+const MaxSeriesOccurrences = 260
+
+// OccurrenceStatus describes what will happen to one occurrence of a series.
+type OccurrenceStatus string
+
+const (
+	OccurrenceStatusOK       OccurrenceStatus = "ok"
+	OccurrenceStatusConflict OccurrenceStatus = "conflict"
+	OccurrenceStatusMoved    OccurrenceStatus = "moved"
+	OccurrenceStatusSkipped  OccurrenceStatus = "skipped"
+)
+
+// RecurrenceSeries is the record behind a recurring appointment series. Its ID
+// is the recurrence_id shared by every appointment in the series.
+type RecurrenceSeries struct {
+	ID          string          `json:"id" db:"id"`
+	ClinicID    string          `json:"clinic_id" db:"clinic_id"`
+	PatientID   string          `json:"patient_id" db:"patient_id"`
+	TherapistID string          `json:"therapist_id" db:"therapist_id"`
+	RRule       string          `json:"rrule" db:"rrule"`
+	DTStart     time.Time       `json:"dtstart" db:"dtstart"`
+	Timezone    string          `json:"timezone" db:"timezone"` // UTC offset ("+07:00") or IANA name
+	Duration    int             `json:"duration" db:"duration"`
+	Type        AppointmentType `json:"type" db:"type"`
+	Room        string          `json:"room,omitempty" db:"room"`
+	Notes       string          `json:"notes,omitempty" db:"notes"`
+	ResourceIDs []string        `json:"resource_ids" db:"resource_ids"`
+	ExDates     []time.Time     `json:"exdates" db:"exdates"`
+	CreatedBy   *string         `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt   time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// SeriesEditScope selects which occurrences of a series an edit applies to.
+type SeriesEditScope string
+
+const (
+	SeriesEditThis      SeriesEditScope = "this"
+	SeriesEditFollowing SeriesEditScope = "following"
+	SeriesEditAll       SeriesEditScope = "all"
+)
+
+// SeriesOccurrence is one occurrence of a series in a preview or edit result.
+type SeriesOccurrence struct {
+	AppointmentID string           `json:"appointment_id,omitempty"`
+	OriginalStart time.Time        `json:"original_start"`
+	StartTime     time.Time        `json:"start_time"`
+	EndTime       time.Time        `json:"end_time"`
+	Status        OccurrenceStatus `json:"status"`
+	Conflicts     []ConflictInfo   `json:"conflicts,omitempty"`
+}
+
+// SeriesPreview lists every occurrence a series would create and the conflicts
+// found for each one.
+type SeriesPreview struct {
+	RRule         string             `json:"rrule"`
+	Occurrences   []SeriesOccurrence `json:"occurrences"`
+	ConflictCount int                `json:"conflict_count"`
+}
+
+// SeriesResult is the outcome of creating a recurring series.
+type SeriesResult struct {
+	Series       *RecurrenceSeries        `json:"series"`
+	Appointments []AppointmentWithDetails `json:"appointments"`
+	Skipped      []SeriesOccurrence       `json:"skipped"`
+}
+
+// OccurrenceOverride moves or skips one occurrence of a series being created.
+// OriginalStart identifies the occurrence as returned in the preview.
+type OccurrenceOverride struct {
+	OriginalStart string `json:"original_start" validate:"required,datetime=2006-01-02T15:04:05Z07:00"`
+	StartTime     string `json:"start_time" validate:"required_without=Skip,omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Skip          bool   `json:"skip"`
+}
+
+// UpdateSeriesRequest edits one appointment of a series, it and the following
+// occurrences, or every open occurrence of the series.
+type UpdateSeriesRequest struct {
+	UpdateAppointmentRequest
+	Scope string `json:"scope" validate:"required,oneof=this following all"`
+}
+
+// SeriesEdit is a set of changes to recurring series that is applied in one
+// transaction.
+type SeriesEdit struct {
+	Series *RecurrenceSeries // updated in place
+	Split  *RecurrenceSeries // new series created by a this-and-following edit, or nil
+	Moved  []*Appointment    // appointments whose time or therapist changed
+	Other  []*Appointment    // appointments with other changes only
+}
+
+// SeriesUpdateResult is the outcome of editing a recurring series.
+type SeriesUpdateResult struct {
+	Scope        SeriesEditScope          `json:"scope"`
+	Series       *RecurrenceSeries        `json:"series,omitempty"`
+	Appointments []AppointmentWithDetails `json:"appointments"`
+	NotMoved     []SeriesOccurrence       `json:"not_moved"`
+}
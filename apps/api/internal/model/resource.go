@@ -0,0 +1,111 @@
+package model
+
+import (
+	"sort"
+	"time"
+)
+
+// ResourceType distinguishes treatment rooms from equipment.
+type ResourceType string
+
+const (
+	ResourceTypeRoom      ResourceType = "room"
+	ResourceTypeEquipment ResourceType = "equipment"
+)
+
+// Resource is a bookable room or piece of equipment of a clinic. Capacity is
+// how many appointments may use it at the same time.
+type Resource struct {
+	ID          string       `json:"id" db:"id"`
+	ClinicID    string       `json:"clinic_id" db:"clinic_id"`
+	Name        string       `json:"name" db:"name"`
+	NameVi      string       `json:"name_vi,omitempty" db:"name_vi"`
+	Type        ResourceType `json:"resource_type" db:"resource_type"`
+	Capacity    int          `json:"capacity" db:"capacity"`
+	Description string       `json:"description,omitempty" db:"description"`
+	IsActive    bool         `json:"is_active" db:"is_active"`
+	CreatedAt   time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at" db:"updated_at"`
+}
+
+// Conflicts returns the bookings that leave no capacity for another
+// appointment using the resource between start and end, or nil if the
+// resource is free. Bookings that do not use the resource or do not overlap
+// the period are ignored.
+func (r Resource) Conflicts(bookings []Appointment, start, end time.Time) []Appointment {
+	type edge struct {
+		at    time.Time
+		delta int
+	}
+
+	var overlapping []Appointment
+	var edges []edge
+	for _, b := range bookings {
+		if !b.UsesResource(r.ID) || !b.StartTime.Before(end) || !b.EndTime.After(start) {
+			continue
+		}
+		overlapping = append(overlapping, b)
+		from, to := b.StartTime, b.EndTime
+		if from.Before(start) {
+			from = start
+		}
+		if to.After(end) {
+			to = end
+		}
+		edges = append(edges, edge{from, 1}, edge{to, -1})
+	}
+
+	// Ends sort before starts at the same instant, so back-to-back bookings
+	// do not count as concurrent
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].at.Equal(edges[j].at) {
+			return edges[i].delta < edges[j].delta
+		}
+		return edges[i].at.Before(edges[j].at)
+	})
+
+	inUse, peak := 0, 0
+	for _, e := range edges {
+		inUse += e.delta
+		if inUse > peak {
+			peak = inUse
+		}
+	}
+
+	if peak < r.Capacity {
+		return nil
+	}
+	return overlapping
+}
+
+// ResourceDaySchedule is one resource's bookings on a day.
+type ResourceDaySchedule struct {
+	Resource      Resource                 `json:"resource"`
+	Appointments  []AppointmentWithDetails `json:"appointments"`
+	BookedMinutes int                      `json:"booked_minutes"`
+}
+
+// ResourceDayView lists the bookings of a clinic's resources on a day.
+type ResourceDayView struct {
+	Date      string                `json:"date"`
+	Resources []ResourceDaySchedule `json:"resources"`
+}
+
+// CreateResourceRequest represents the request body for adding a room or
+// piece of equipment. Capacity defaults to 1.
+type CreateResourceRequest struct {
+	Name        string `json:"name" validate:"required,max=200"`
+	NameVi      string `json:"name_vi" validate:"max=200"`
+	Type        string `json:"resource_type" validate:"required,oneof=room equipment"`
+	Capacity    int    `json:"capacity" validate:"omitempty,min=1,max=50"`
+	Description string `json:"description" validate:"max=1000"`
+}
+
+// UpdateResourceRequest represents the request body for changing a resource.
+type UpdateResourceRequest struct {
+	Name        *string `json:"name" validate:"omitempty,min=1,max=200"`
+	NameVi      *string `json:"name_vi" validate:"omitempty,max=200"`
+	Capacity    *int    `json:"capacity" validate:"omitempty,min=1,max=50"`
+	Description *string `json:"description" validate:"omitempty,max=1000"`
+	IsActive    *bool   `json:"is_active"`
+}
@@ -0,0 +1,106 @@
+package model
+
+import "time"
+
+// ExceptionType classifies a one-time change to a therapist's schedule.
+type ExceptionType string
+
+const (
+	ExceptionTypeVacation   ExceptionType = "vacation"
+	ExceptionTypeSick       ExceptionType = "sick"
+	ExceptionTypeTraining   ExceptionType = "training"
+	ExceptionTypeHoliday    ExceptionType = "holiday"
+	ExceptionTypeExtraShift ExceptionType = "extra_shift"
+	ExceptionTypeOther      ExceptionType = "other"
+)
+
+// IsAvailable reports whether the exception adds working time rather than
+// blocking it. Only extra shifts add time.
+func (t ExceptionType) IsAvailable() bool {
+	return t == ExceptionTypeExtraShift
+}
+
+// CreateScheduleRequest represents the request body for adding a weekly
+// working period to a therapist's schedule.
+type CreateScheduleRequest struct {
+	DayOfWeek     *int    `json:"day_of_week" validate:"required,min=0,max=6"`
+	StartTime     string  `json:"start_time" validate:"required,datetime=15:04"`
+	EndTime       string  `json:"end_time" validate:"required,datetime=15:04"`
+	EffectiveFrom string  `json:"effective_from" validate:"required,datetime=2006-01-02"`
+	EffectiveTo   *string `json:"effective_to" validate:"omitempty,datetime=2006-01-02"`
+}
+
+// UpdateScheduleRequest represents the request body for changing a weekly
+// working period. Ongoing clears the effective-to date.
+type UpdateScheduleRequest struct {
+	DayOfWeek     *int    `json:"day_of_week" validate:"omitempty,min=0,max=6"`
+	StartTime     *string `json:"start_time" validate:"omitempty,datetime=15:04"`
+	EndTime       *string `json:"end_time" validate:"omitempty,datetime=15:04"`
+	EffectiveFrom *string `json:"effective_from" validate:"omitempty,datetime=2006-01-02"`
+	EffectiveTo   *string `json:"effective_to" validate:"omitempty,datetime=2006-01-02"`
+	Ongoing       bool    `json:"ongoing"`
+	IsActive      *bool   `json:"is_active"`
+}
+
+// CreateScheduleExceptionRequest represents the request body for recording
+// leave, training, a holiday or an extra shift. Without start and end times
+// the exception covers the whole day.
+//
+// A blocking exception that overlaps booked appointments is rejected unless
+// AcknowledgeAffected is set; the affected appointments are returned either way.
+type CreateScheduleExceptionRequest struct {
+	Date                string  `json:"date" validate:"required,datetime=2006-01-02"`
+	StartTime           *string `json:"start_time" validate:"required_with=EndTime,omitempty,datetime=15:04"`
+	EndTime             *string `json:"end_time" validate:"required_with=StartTime,omitempty,datetime=15:04"`
+	ExceptionType       string  `json:"exception_type" validate:"required,oneof=vacation sick training holiday extra_shift other"`
+	Reason              string  `json:"reason" validate:"max=500"`
+	Notes               string  `json:"notes" validate:"max=1000"`
+	AcknowledgeAffected bool    `json:"acknowledge_affected"`
+}
+
+// UpdateScheduleExceptionRequest represents the request body for changing a
+// schedule exception. AllDay clears the start and end times.
+type UpdateScheduleExceptionRequest struct {
+	Date                *string `json:"date" validate:"omitempty,datetime=2006-01-02"`
+	StartTime           *string `json:"start_time" validate:"omitempty,datetime=15:04"`
+	EndTime             *string `json:"end_time" validate:"omitempty,datetime=15:04"`
+	AllDay              bool    `json:"all_day"`
+	ExceptionType       *string `json:"exception_type" validate:"omitempty,oneof=vacation sick training holiday extra_shift other"`
+	Reason              *string `json:"reason" validate:"omitempty,max=500"`
+	Notes               *string `json:"notes" validate:"omitempty,max=1000"`
+	AcknowledgeAffected bool    `json:"acknowledge_affected"`
+}
+
+// ScheduleExceptionResult is a saved schedule exception together with the
+// booked appointments that now fall outside the therapist's working time.
+type ScheduleExceptionResult struct {
+	Exception            *ScheduleException       `json:"exception"`
+	AffectedAppointments []AppointmentWithDetails `json:"affected_appointments"`
+}
+
+// Window returns the time range the exception covers on its date, with clock
+// times read in loc. An exception without start and end times covers the
+// whole day.
+func (e ScheduleException) Window(loc *time.Location) TimeRange {
+	day := time.Date(e.Date.Year(), e.Date.Month(), e.Date.Day(), 0, 0, 0, 0, loc)
+	if e.StartTime == nil || e.EndTime == nil {
+		return TimeRange{Start: day, End: day.AddDate(0, 0, 1)}
+	}
+	return TimeRange{Start: ClockTimeOn(day, *e.StartTime), End: ClockTimeOn(day, *e.EndTime)}
+}
+
+// AppliesOn reports whether the working period is active on day's date.
+func (s TherapistSchedule) AppliesOn(day time.Time) bool {
+	date := day.Format("2006-01-02")
+	return s.IsActive &&
+		s.DayOfWeek == int(day.Weekday()) &&
+		s.EffectiveFrom.Format("2006-01-02") <= date &&
+		(s.EffectiveTo == nil || s.EffectiveTo.Format("2006-01-02") >= date)
+}
+
+// ClockTimeOn returns the instant at an "HH:MM" clock time on day's date in
+// day's location. An unparsable clock time yields midnight.
+func ClockTimeOn(day time.Time, clock string) time.Time {
+	t, _ := time.Parse("15:04", clock)
+	return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, day.Location())
+}
@@ -0,0 +1,136 @@
+package model
+
+import "time"
+
+// ClinicEventType names a change published to a clinic's live status board.
+type ClinicEventType string
+
+const (
+	EventAppointmentCreated   ClinicEventType = "appointment.created"
+	EventAppointmentUpdated   ClinicEventType = "appointment.updated"
+	EventAppointmentCheckedIn ClinicEventType = "appointment.checked_in"
+	EventAppointmentStarted   ClinicEventType = "appointment.started"
+	EventAppointmentCompleted ClinicEventType = "appointment.completed"
+	EventAppointmentCancelled ClinicEventType = "appointment.cancelled"
+	EventAppointmentNoShow    ClinicEventType = "appointment.no_show"
+	EventAppointmentDeleted   ClinicEventType = "appointment.deleted"
+	EventChecklistStarted     ClinicEventType = "checklist.started"
+	EventChecklistCompleted   ClinicEventType = "checklist.completed"
+
+	// EventGroupSessionChanged and EventScheduleChanged report changes to
+	// several appointments at once, such as a moved class or a new series;
+	// the board should reload its snapshot.
+	EventGroupSessionChanged ClinicEventType = "group_session.changed"
+	EventScheduleChanged     ClinicEventType = "schedule.changed"
+)
+
+// ClinicEvent is a change published to a clinic's live status board. Entry
+// carries the appointment's current board state for appointment events when
+// the appointment still exists.
+type ClinicEvent struct {
+	ID             string            `json:"id"`
+	ClinicID       string            `json:"clinic_id"`
+	Type           ClinicEventType   `json:"type"`
+	AppointmentID  string            `json:"appointment_id,omitempty"`
+	PatientID      string            `json:"patient_id,omitempty"`
+	TherapistID    string            `json:"therapist_id,omitempty"`
+	ChecklistID    string            `json:"checklist_id,omitempty"`
+	GroupSessionID string            `json:"group_session_id,omitempty"`
+	ActorID        string            `json:"actor_id,omitempty"`
+	Entry          *StatusBoardEntry `json:"entry,omitempty"`
+	OccurredAt     time.Time         `json:"occurred_at"`
+}
+
+// CheckInMethod is how a patient checked in.
+type CheckInMethod string
+
+const (
+	CheckInFrontDesk CheckInMethod = "front_desk"
+	CheckInKiosk     CheckInMethod = "kiosk"
+)
+
+// AppointmentCheckIn records a patient's arrival for an appointment. The
+// confirmations are only collected when patients check themselves in.
+type AppointmentCheckIn struct {
+	AppointmentID         string        `json:"appointment_id" db:"appointment_id"`
+	ClinicID              string        `json:"clinic_id" db:"clinic_id"`
+	Method                CheckInMethod `json:"method" db:"method"`
+	CheckedInAt           time.Time     `json:"checked_in_at" db:"checked_in_at"`
+	CheckedInBy           *string       `json:"checked_in_by,omitempty" db:"checked_in_by"`
+	KioskID               *string       `json:"kiosk_id,omitempty" db:"kiosk_id"`
+	DemographicsConfirmed *bool         `json:"demographics_confirmed,omitempty" db:"demographics_confirmed"`
+	InsuranceConfirmed    *bool         `json:"insurance_confirmed,omitempty" db:"insurance_confirmed"`
+	PatientNotes          string        `json:"patient_notes,omitempty" db:"patient_notes"`
+}
+
+// StatusBoardStage is where a patient is in a visit.
+type StatusBoardStage string
+
+const (
+	StageExpected  StatusBoardStage = "expected"
+	StageArrived   StatusBoardStage = "arrived"
+	StageInSession StatusBoardStage = "in_session"
+	StageDone      StatusBoardStage = "done"
+	StageCancelled StatusBoardStage = "cancelled"
+	StageNoShow    StatusBoardStage = "no_show"
+)
+
+// StatusBoardEntry is one appointment on the clinic status board.
+type StatusBoardEntry struct {
+	AppointmentID   string            `json:"appointment_id" db:"appointment_id"`
+	PatientID       string            `json:"patient_id" db:"patient_id"`
+	PatientName     string            `json:"patient_name" db:"patient_name"`
+	PatientMRN      string            `json:"patient_mrn" db:"patient_mrn"`
+	TherapistID     string            `json:"therapist_id" db:"therapist_id"`
+	TherapistName   string            `json:"therapist_name" db:"therapist_name"`
+	GroupSessionID  *string           `json:"group_session_id,omitempty" db:"group_session_id"`
+	Type            AppointmentType   `json:"type" db:"type"`
+	Room            string            `json:"room,omitempty" db:"room"`
+	StartTime       time.Time         `json:"start_time" db:"start_time"`
+	EndTime         time.Time         `json:"end_time" db:"end_time"`
+	Status          AppointmentStatus `json:"status" db:"status"`
+	CheckedInAt     *time.Time        `json:"checked_in_at,omitempty" db:"checked_in_at"`
+	CheckInMethod   *CheckInMethod    `json:"check_in_method,omitempty" db:"check_in_method"`
+	NeedsFrontDesk  bool              `json:"needs_front_desk" db:"needs_front_desk"`           // the patient reported changes or unconfirmed details at the kiosk
+	ChecklistStatus *ChecklistStatus  `json:"checklist_status,omitempty" db:"checklist_status"` // the patient's latest visit checklist on the appointment's day
+	Stage           StatusBoardStage  `json:"stage" db:"-"`
+}
+
+// SetStage derives the entry's stage from its status and check-in.
+func (e *StatusBoardEntry) SetStage() {
+	switch e.Status {
+	case AppointmentStatusCancelled:
+		e.Stage = StageCancelled
+	case AppointmentStatusNoShow:
+		e.Stage = StageNoShow
+	case AppointmentStatusCompleted:
+		e.Stage = StageDone
+	case AppointmentStatusInProgress:
+		e.Stage = StageInSession
+	default:
+		if e.CheckedInAt != nil {
+			e.Stage = StageArrived
+		} else {
+			e.Stage = StageExpected
+		}
+	}
+}
+
+// StatusBoard is a snapshot of a clinic's appointments for one day, used to
+// draw the board and to resync after missed events.
+type StatusBoard struct {
+	ClinicID    string                   `json:"clinic_id"`
+	Date        string                   `json:"date"`
+	Timezone    string                   `json:"timezone"`
+	Entries     []StatusBoardEntry       `json:"entries"`
+	Counts      map[StatusBoardStage]int `json:"counts"`
+	GeneratedAt time.Time                `json:"generated_at"`
+}
+
+// StreamTicket is a short-lived credential for opening the status board event
+// stream. Browsers' EventSource cannot send an Authorization header, so the
+// ticket is passed in the query string instead of the user's access token.
+type StreamTicket struct {
+	Ticket    string    `json:"ticket"`
+	ExpiresAt time.Time `json:"expires_at"` // the stream must be opened before this time
+}
@@ -0,0 +1,137 @@
+package model
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+)
+
+// TimelineEventType represents the kind of entry in a patient timeline.
+type TimelineEventType string
+
+const (
+	TimelineEventAppointment    TimelineEventType = "appointment"
+	TimelineEventVisitChecklist TimelineEventType = "visit_checklist"
+	TimelineEventPainRecord     TimelineEventType = "pain_record"
+	TimelineEventROMRecord      TimelineEventType = "rom_record"
+	TimelineEventPrescription   TimelineEventType = "prescription"
+	TimelineEventComplianceLog  TimelineEventType = "compliance_log"
+	TimelineEventStatusChange   TimelineEventType = "status_change"
+)
+
+// AllTimelineEventTypes lists every timeline event type in display order.
+var AllTimelineEventTypes = []TimelineEventType{
+	TimelineEventAppointment,
+	TimelineEventVisitChecklist,
+	TimelineEventPainRecord,
+	TimelineEventROMRecord,
+	TimelineEventPrescription,
+	TimelineEventComplianceLog,
+	TimelineEventStatusChange,
+}
+
+// IsValid reports whether the event type is known.
+func (t TimelineEventType) IsValid() bool {
+	for _, known := range AllTimelineEventTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// TimelineEvent represents a single entry in a patient's clinical timeline.
+type TimelineEvent struct {
+	ID          string                 `json:"id"`
+	Type        TimelineEventType      `json:"type"`
+	ReferenceID string                 `json:"reference_id"`
+	OccurredAt  time.Time              `json:"occurred_at"`
+	ActorID     *string                `json:"actor_id,omitempty"`
+	ActorName   string                 `json:"actor_name,omitempty"`
+	Title       string                 `json:"title"`
+	TitleVi     string                 `json:"title_vi"`
+	Summary     string                 `json:"summary,omitempty"`
+	SummaryVi   string                 `json:"summary_vi,omitempty"`
+	Details     map[string]interface{} `json:"details,omitempty"`
+}
+
+// TimelineParams represents query parameters for a patient timeline.
+type TimelineParams struct {
+	ClinicID  string
+	PatientID string
+	Types     []TimelineEventType
+	From      *time.Time
+	To        *time.Time
+	Cursor    *TimelineCursor
+	PerPage   int
+}
+
+// NewTimelineParams creates TimelineParams with default values.
+func NewTimelineParams() TimelineParams {
+	return TimelineParams{
+		PerPage: 20,
+	}
+}
+
+// Limit returns the number of events per page.
+func (p TimelineParams) Limit() int {
+	if p.PerPage <= 0 {
+		return 20
+	}
+	if p.PerPage > 100 {
+		return 100
+	}
+	return p.PerPage
+}
+
+// EventTypes returns the requested event types, or all types if none were given.
+func (p TimelineParams) EventTypes() []TimelineEventType {
+	if len(p.Types) == 0 {
+		return AllTimelineEventTypes
+	}
+	return p.Types
+}
+
+// TimelineCursor marks the position of the last event returned in a page.
+// Events are ordered newest first by (occurred_at, id).
+type TimelineCursor struct {
+	OccurredAt time.Time
+	ID         string
+}
+
+// ErrInvalidTimelineCursor is returned when a cursor cannot be decoded.
+var ErrInvalidTimelineCursor = errors.New("invalid timeline cursor")
+
+// Encode returns the opaque string form of the cursor.
+func (c TimelineCursor) Encode() string {
+	raw := c.OccurredAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeTimelineCursor parses a cursor produced by TimelineCursor.Encode.
+func DecodeTimelineCursor(s string) (*TimelineCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidTimelineCursor
+	}
+
+	parts := strings.SplitN(string(raw), "|", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return nil, ErrInvalidTimelineCursor
+	}
+
+	occurredAt, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return nil, ErrInvalidTimelineCursor
+	}
+
+	return &TimelineCursor{OccurredAt: occurredAt, ID: parts[1]}, nil
+}
+
+// TimelinePage represents one page of a patient timeline.
+type TimelinePage struct {
+	Data       []TimelineEvent `json:"data"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+	HasMore    bool            `json:"has_more"`
+}
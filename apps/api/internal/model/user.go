@@ -121,6 +121,9 @@ type Settings struct {
 	} `json:"working_hours"`
 }
 
+// DefaultClinicTimezone is the time zone of clinics that have not set one.
+const DefaultClinicTimezone = "Asia/Ho_Chi_Minh"
+
 // Clinic represents a physical clinic location within a tenant.
 type Clinic struct {
 	ID        string    `json:"id" db:"id"`
@@ -129,6 +132,7 @@ type Clinic struct {
 	Address   string    `json:"address" db:"address"`
 	Phone     string    `json:"phone" db:"phone"`
 	Email     string    `json:"email" db:"email"`
+	Timezone  string    `json:"timezone" db:"timezone"` // IANA name; scheduling days and hours are in this zone
 	Active    bool      `json:"active" db:"active"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
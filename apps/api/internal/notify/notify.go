@@ -0,0 +1,53 @@
+// Package notify delivers messages to patients, such as reminders to do their
+// home exercises. Callers check the patient's consent before notifying.
+package notify
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Kind identifies what a message is about.
+type Kind string
+
+const (
+	KindExerciseNudge Kind = "exercise_nudge"
+)
+
+// Message is a bilingual message to a patient. The channel picks the
+// language from the patient's preference.
+type Message struct {
+	ClinicID  string
+	PatientID string
+	Kind      Kind
+	Title     string
+	TitleVi   string
+	Body      string
+	BodyVi    string
+}
+
+// Notifier sends messages to patients.
+type Notifier interface {
+	Notify(ctx context.Context, msg Message) error
+}
+
+// LogNotifier records messages in the log instead of sending them. It is used
+// until a delivery channel such as SMS or Zalo is configured.
+type LogNotifier struct{}
+
+// NewLogNotifier creates a notifier that only logs.
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+// Notify logs the message.
+func (n *LogNotifier) Notify(ctx context.Context, msg Message) error {
+	log.Info().
+		Str("clinic_id", msg.ClinicID).
+		Str("patient_id", msg.PatientID).
+		Str("kind", string(msg.Kind)).
+		Str("title", msg.Title).
+		Msg("patient notification")
+	return nil
+}
@@ -0,0 +1,444 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+)
+
+// AdherenceRepository defines the interface for the data behind exercise
+// adherence analytics and low adherence alerts.
+type AdherenceRepository interface {
+	ListActiveClinics(ctx context.Context, from, to time.Time) ([]string, error)
+	ListPrescriptions(ctx context.Context, clinicID, patientID string, from, to time.Time) ([]model.ExercisePrescription, error)
+	ListComplianceLogs(ctx context.Context, clinicID, patientID string, from, to time.Time) ([]model.ExerciseComplianceLog, error)
+	GetPatientNames(ctx context.Context, clinicID string, patientIDs []string) (map[string]string, error)
+	RecordAlerts(ctx context.Context, clinicID string, weekStart time.Time, alerts []model.AdherenceAlert) error
+	ListAlerts(ctx context.Context, params model.AdherenceAlertParams) ([]model.AdherenceAlert, error)
+	AcknowledgeAlert(ctx context.Context, clinicID, id, userID string, at time.Time) (*model.AdherenceAlert, error)
+	MarkNudged(ctx context.Context, id string, at time.Time) error
+}
+
+// postgresAdherenceRepo implements AdherenceRepository with PostgreSQL.
+type postgresAdherenceRepo struct {
+	db *DB
+}
+
+// NewAdherenceRepository creates a new PostgreSQL adherence repository.
+func NewAdherenceRepository(db *DB) AdherenceRepository {
+	return &postgresAdherenceRepo{db: db}
+}
+
+// adherenceAlertColumns lists the columns read by scanAdherenceAlert.
+const adherenceAlertColumns = `
+	a.id, a.clinic_id, a.patient_id,
+	COALESCE(p.first_name || ' ' || p.last_name, '') AS patient_name,
+	a.week_start, a.window_start, a.window_end, a.expected_sessions,
+	a.completed_sessions, a.adherence, a.previous_adherence, a.threshold,
+	a.therapist_ids, a.status, a.nudged_at, a.acknowledged_at,
+	a.acknowledged_by, a.created_at, a.updated_at`
+
+// ListActiveClinics retrieves the clinics with prescriptions running at some
+// point in [from, to).
+func (r *postgresAdherenceRepo) ListActiveClinics(ctx context.Context, from, to time.Time) ([]string, error) {
+	query := `
+		SELECT DISTINCT clinic_id
+		FROM exercise_prescriptions
+		WHERE status IN ('active', 'completed')
+			AND start_date < $2
+			AND (end_date IS NULL OR end_date > $1)`
+
+	rows, err := r.db.QueryContext(ctx, query, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list clinics with prescriptions: %w", err)
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan clinic id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate clinics: %w", err)
+	}
+
+	return ids, nil
+}
+
+// ListPrescriptions retrieves the active and completed prescriptions running
+// at some point in [from, to), with their exercise names, for one patient or
+// for the whole clinic when patientID is empty. Paused and cancelled
+// prescriptions are not expected to be done.
+func (r *postgresAdherenceRepo) ListPrescriptions(ctx context.Context, clinicID, patientID string, from, to time.Time) ([]model.ExercisePrescription, error) {
+	query := `
+		SELECT
+			p.id, p.patient_id, p.exercise_id, p.clinic_id, p.prescribed_by,
+			p.frequency, p.frequency_schedule, p.status, p.start_date, p.end_date,
+			e.name, COALESCE(e.name_vi, '')
+		FROM exercise_prescriptions p
+		JOIN exercises e ON e.id = p.exercise_id
+		WHERE p.clinic_id = $1
+			AND ($2::uuid IS NULL OR p.patient_id = $2)
+			AND p.status IN ('active', 'completed')
+			AND p.start_date < $4
+			AND (p.end_date IS NULL OR p.end_date > $3)
+		ORDER BY p.patient_id, p.start_date, p.id`
+
+	rows, err := r.db.QueryContext(ctx, query, clinicID, NullableStringValue(patientID), from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list prescriptions: %w", err)
+	}
+	defer rows.Close()
+
+	prescriptions := []model.ExercisePrescription{}
+	for rows.Next() {
+		var p model.ExercisePrescription
+		var endDate sql.NullTime
+		var schedule []byte
+		exercise := &model.Exercise{}
+
+		err := rows.Scan(
+			&p.ID,
+			&p.PatientID,
+			&p.ExerciseID,
+			&p.ClinicID,
+			&p.PrescribedBy,
+			&p.Frequency,
+			&schedule,
+			&p.Status,
+			&p.StartDate,
+			&endDate,
+			&exercise.Name,
+			&exercise.NameVi,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan prescription: %w", err)
+		}
+
+		p.EndDate = TimePtrFromNull(endDate)
+		if p.FrequencySchedule, err = decodeFrequencySchedule(schedule); err != nil {
+			return nil, err
+		}
+		exercise.ID = p.ExerciseID
+		p.Exercise = exercise
+		prescriptions = append(prescriptions, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate prescriptions: %w", err)
+	}
+
+	return prescriptions, nil
+}
+
+// ListComplianceLogs retrieves the compliance logs completed in [from, to)
+// for one patient or for the whole clinic when patientID is empty, oldest
+// first.
+func (r *postgresAdherenceRepo) ListComplianceLogs(ctx context.Context, clinicID, patientID string, from, to time.Time) ([]model.ExerciseComplianceLog, error) {
+	query := `
+		SELECT l.id, l.prescription_id, l.patient_id, l.completed_at, l.pain_level
+		FROM exercise_compliance_logs l
+		JOIN exercise_prescriptions p ON p.id = l.prescription_id
+		WHERE p.clinic_id = $1
+			AND ($2::uuid IS NULL OR l.patient_id = $2)
+			AND l.completed_at >= $3 AND l.completed_at < $4
+		ORDER BY l.completed_at`
+
+	rows, err := r.db.QueryContext(ctx, query, clinicID, NullableStringValue(patientID), from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list compliance logs: %w", err)
+	}
+	defer rows.Close()
+
+	logs := []model.ExerciseComplianceLog{}
+	for rows.Next() {
+		var l model.ExerciseComplianceLog
+		var painLevel sql.NullInt64
+
+		if err := rows.Scan(&l.ID, &l.PrescriptionID, &l.PatientID, &l.CompletedAt, &painLevel); err != nil {
+			return nil, fmt.Errorf("failed to scan compliance log: %w", err)
+		}
+
+		if painLevel.Valid {
+			pl := int(painLevel.Int64)
+			l.PainLevel = &pl
+		}
+		logs = append(logs, l)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate compliance logs: %w", err)
+	}
+
+	return logs, nil
+}
+
+// GetPatientNames returns the display names of the clinic's patients by ID.
+func (r *postgresAdherenceRepo) GetPatientNames(ctx context.Context, clinicID string, patientIDs []string) (map[string]string, error) {
+	query := `
+		SELECT id, first_name || ' ' || last_name
+		FROM patients
+		WHERE clinic_id = $1 AND id = ANY($2)`
+
+	rows, err := r.db.QueryContext(ctx, query, clinicID, pq.Array(patientIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get patient names: %w", err)
+	}
+	defer rows.Close()
+
+	names := make(map[string]string, len(patientIDs))
+	for rows.Next() {
+		var id, name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, fmt.Errorf("failed to scan patient name: %w", err)
+		}
+		names[id] = name
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate patient names: %w", err)
+	}
+
+	return names, nil
+}
+
+// RecordAlerts saves the clinic's low adherence alerts for a week. Alerts
+// already raised that week are refreshed unless they were acknowledged, and
+// open alerts of patients no longer listed are resolved. The alerts' ID,
+// status and NudgedAt are filled in from the stored rows.
+func (r *postgresAdherenceRepo) RecordAlerts(ctx context.Context, clinicID string, weekStart time.Time, alerts []model.AdherenceAlert) error {
+	return r.db.WithTx(ctx, func(tx *Tx) error {
+		query := `
+			INSERT INTO exercise_adherence_alerts (
+				id, clinic_id, patient_id, week_start, window_start, window_end,
+				expected_sessions, completed_sessions, adherence, previous_adherence,
+				threshold, therapist_ids
+			) VALUES (
+				$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+			)
+			ON CONFLICT (patient_id, week_start) DO UPDATE SET
+				window_start = EXCLUDED.window_start,
+				window_end = EXCLUDED.window_end,
+				expected_sessions = EXCLUDED.expected_sessions,
+				completed_sessions = EXCLUDED.completed_sessions,
+				adherence = EXCLUDED.adherence,
+				previous_adherence = EXCLUDED.previous_adherence,
+				threshold = EXCLUDED.threshold,
+				therapist_ids = EXCLUDED.therapist_ids,
+				status = CASE
+					WHEN exercise_adherence_alerts.status = 'acknowledged' THEN 'acknowledged'
+					ELSE 'open'
+				END
+			RETURNING id, status, nudged_at, created_at, updated_at`
+
+		patientIDs := make([]string, len(alerts))
+		for i := range alerts {
+			a := &alerts[i]
+			var nudgedAt sql.NullTime
+			err := tx.QueryRowContext(ctx, query,
+				a.ID,
+				clinicID,
+				a.PatientID,
+				weekStart,
+				a.WindowStart,
+				a.WindowEnd,
+				a.ExpectedSessions,
+				a.CompletedSessions,
+				a.Adherence,
+				a.PreviousAdherence,
+				a.Threshold,
+				pq.Array(a.TherapistIDs),
+			).Scan(&a.ID, &a.Status, &nudgedAt, &a.CreatedAt, &a.UpdatedAt)
+			if err != nil {
+				if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+					return fmt.Errorf("%w: patient does not exist", ErrInvalidInput)
+				}
+				return fmt.Errorf("failed to record adherence alert: %w", err)
+			}
+			a.ClinicID = clinicID
+			a.WeekStart = weekStart
+			a.NudgedAt = TimePtrFromNull(nudgedAt)
+			patientIDs[i] = a.PatientID
+		}
+
+		_, err := tx.ExecContext(ctx, `
+			UPDATE exercise_adherence_alerts
+			SET status = 'resolved'
+			WHERE clinic_id = $1 AND week_start = $2 AND status = 'open'
+				AND NOT (patient_id = ANY($3))`,
+			clinicID, weekStart, pq.Array(patientIDs),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to resolve adherence alerts: %w", err)
+		}
+		return nil
+	})
+}
+
+// ListAlerts retrieves the clinic's adherence alerts, newest week first and
+// lowest adherence first within a week.
+func (r *postgresAdherenceRepo) ListAlerts(ctx context.Context, params model.AdherenceAlertParams) ([]model.AdherenceAlert, error) {
+	query := `
+		SELECT ` + adherenceAlertColumns + `
+		FROM exercise_adherence_alerts a
+		LEFT JOIN patients p ON p.id = a.patient_id
+		WHERE a.clinic_id = $1
+			AND ($2::uuid IS NULL OR $2 = ANY(a.therapist_ids))
+			AND ($3::text IS NULL OR a.status = $3)
+		ORDER BY a.week_start DESC, a.adherence, a.created_at
+		LIMIT 200`
+
+	rows, err := r.db.QueryContext(ctx, query,
+		params.ClinicID,
+		NullableStringValue(params.TherapistID),
+		NullableStringValue(params.Status),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list adherence alerts: %w", err)
+	}
+	defer rows.Close()
+
+	alerts := []model.AdherenceAlert{}
+	for rows.Next() {
+		alert, err := scanAdherenceAlert(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan adherence alert: %w", err)
+		}
+		alerts = append(alerts, *alert)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate adherence alerts: %w", err)
+	}
+
+	return alerts, nil
+}
+
+// AcknowledgeAlert marks one of the clinic's alerts as seen by a therapist.
+func (r *postgresAdherenceRepo) AcknowledgeAlert(ctx context.Context, clinicID, id, userID string, at time.Time) (*model.AdherenceAlert, error) {
+	query := `
+		WITH a AS (
+			UPDATE exercise_adherence_alerts
+			SET status = 'acknowledged', acknowledged_at = $3, acknowledged_by = $4
+			WHERE id = $1 AND clinic_id = $2
+			RETURNING *
+		)
+		SELECT ` + adherenceAlertColumns + `
+		FROM a
+		LEFT JOIN patients p ON p.id = a.patient_id`
+
+	alert, err := scanAdherenceAlert(r.db.QueryRowContext(ctx, query, id, clinicID, at, NullableStringValue(userID)))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to acknowledge adherence alert: %w", err)
+	}
+	return alert, nil
+}
+
+// MarkNudged records that the alerted patient was sent a reminder.
+func (r *postgresAdherenceRepo) MarkNudged(ctx context.Context, id string, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE exercise_adherence_alerts SET nudged_at = $2 WHERE id = $1`,
+		id, at,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to mark adherence alert nudged: %w", err)
+	}
+	return nil
+}
+
+// scanAdherenceAlert scans an adherence alert row.
+func scanAdherenceAlert(row rowScanner) (*model.AdherenceAlert, error) {
+	var a model.AdherenceAlert
+	var previous sql.NullFloat64
+	var nudgedAt, acknowledgedAt sql.NullTime
+	var acknowledgedBy sql.NullString
+
+	err := row.Scan(
+		&a.ID,
+		&a.ClinicID,
+		&a.PatientID,
+		&a.PatientName,
+		&a.WeekStart,
+		&a.WindowStart,
+		&a.WindowEnd,
+		&a.ExpectedSessions,
+		&a.CompletedSessions,
+		&a.Adherence,
+		&previous,
+		&a.Threshold,
+		pq.Array(&a.TherapistIDs),
+		&a.Status,
+		&nudgedAt,
+		&acknowledgedAt,
+		&acknowledgedBy,
+		&a.CreatedAt,
+		&a.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if previous.Valid {
+		a.PreviousAdherence = &previous.Float64
+	}
+	if a.TherapistIDs == nil {
+		a.TherapistIDs = []string{}
+	}
+	a.NudgedAt = TimePtrFromNull(nudgedAt)
+	a.AcknowledgedAt = TimePtrFromNull(acknowledgedAt)
+	a.AcknowledgedBy = StringPtrFromNull(acknowledgedBy)
+
+	return &a, nil
+}
+
+// =============================================================================
+// MOCK IMPLEMENTATION
+// =============================================================================
+
+// mockAdherenceRepo provides a mock implementation for development.
+type mockAdherenceRepo struct{}
+
+func (r *mockAdherenceRepo) ListActiveClinics(ctx context.Context, from, to time.Time) ([]string, error) {
+	return []string{}, nil
+}
+
+func (r *mockAdherenceRepo) ListPrescriptions(ctx context.Context, clinicID, patientID string, from, to time.Time) ([]model.ExercisePrescription, error) {
+	return []model.ExercisePrescription{}, nil
+}
+
+func (r *mockAdherenceRepo) ListComplianceLogs(ctx context.Context, clinicID, patientID string, from, to time.Time) ([]model.ExerciseComplianceLog, error) {
+	return []model.ExerciseComplianceLog{}, nil
+}
+
+func (r *mockAdherenceRepo) GetPatientNames(ctx context.Context, clinicID string, patientIDs []string) (map[string]string, error) {
+	return map[string]string{}, nil
+}
+
+func (r *mockAdherenceRepo) RecordAlerts(ctx context.Context, clinicID string, weekStart time.Time, alerts []model.AdherenceAlert) error {
+	return nil
+}
+
+func (r *mockAdherenceRepo) ListAlerts(ctx context.Context, params model.AdherenceAlertParams) ([]model.AdherenceAlert, error) {
+	return []model.AdherenceAlert{}, nil
+}
+
+func (r *mockAdherenceRepo) AcknowledgeAlert(ctx context.Context, clinicID, id, userID string, at time.Time) (*model.AdherenceAlert, error) {
+	return nil, ErrNotFound
+}
+
+func (r *mockAdherenceRepo) MarkNudged(ctx context.Context, id string, at time.Time) error {
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -23,12 +24,23 @@ type AppointmentRepository interface {
 	GetByDateRange(ctx context.Context, clinicID string, start, end time.Time) ([]model.AppointmentWithDetails, error)
 	GetByPatient(ctx context.Context, clinicID, patientID string, limit int) ([]model.AppointmentWithDetails, error)
 	GetByTherapist(ctx context.Context, clinicID, therapistID string, start, end time.Time) ([]model.AppointmentWithDetails, error)
+	GetCalendar(ctx context.Context, clinicID string, kind model.CalendarFeedKind, ownerID string, start, end time.Time) ([]model.AppointmentWithDetails, error)
 	GetDaySchedule(ctx context.Context, clinicID string, date time.Time) (*model.DaySchedule, error)
 	FindConflicts(ctx context.Context, clinicID, therapistID string, start, end time.Time, excludeID string) ([]model.Appointment, error)
+	FindResourceBookings(ctx context.Context, clinicID string, resourceIDs []string, start, end time.Time, excludeIDs []string) ([]model.Appointment, error)
 	GetTherapistSchedule(ctx context.Context, clinicID, therapistID string) ([]model.TherapistSchedule, error)
 	GetScheduleExceptions(ctx context.Context, clinicID, therapistID string, start, end time.Time) ([]model.ScheduleException, error)
-	GetAvailableSlots(ctx context.Context, clinicID, therapistID string, date time.Time, duration int) ([]model.AvailabilitySlot, error)
+	GetAvailableSlots(ctx context.Context, clinicID, therapistID string, date time.Time, duration int, resourceIDs []string) ([]model.AvailabilitySlot, error)
+	FindAvailableSlots(ctx context.Context, clinicID string, therapistIDs []string, from, to time.Time, duration int, resourceIDs []string) ([]model.AvailabilitySlot, error)
+	GetTherapistHistory(ctx context.Context, clinicID, patientID string, since time.Time) ([]model.TherapistVisitCount, error)
+	HasCompletedEvaluation(ctx context.Context, clinicID, patientID string) (bool, error)
+	GetAttendance(ctx context.Context, clinicID string, patientIDs []string, since time.Time, lateNotice time.Duration) (map[string]model.PatientAttendance, error)
+	MarkNoShows(ctx context.Context, defaultGrace time.Duration) ([]model.Appointment, error)
 	CancelByRecurrenceID(ctx context.Context, clinicID, recurrenceID string, reason string, fromDate time.Time) error
+	CreateSeries(ctx context.Context, series *model.RecurrenceSeries, appointments []*model.Appointment) error
+	GetSeries(ctx context.Context, clinicID, id string) (*model.RecurrenceSeries, error)
+	ApplySeriesEdit(ctx context.Context, edit *model.SeriesEdit) error
+	ListBySeries(ctx context.Context, clinicID, seriesID string) ([]model.AppointmentWithDetails, error)
 	CountByClinic(ctx context.Context, clinicID string) (int64, error)
 	GetTherapists(ctx context.Context, clinicID string) ([]model.Therapist, error)
 }
@@ -43,18 +55,36 @@ func NewAppointmentRepository(db *DB) AppointmentRepository {
 	return &postgresAppointmentRepo{db: db}
 }
 
-// Create inserts a new appointment record.
+// Create inserts a new appointment record. An appointment that requires
+// resources is inserted in a transaction that checks their capacity first.
 func (r *postgresAppointmentRepo) Create(ctx context.Context, appointment *model.Appointment) error {
+	if len(appointment.ResourceIDs) == 0 {
+		return insertAppointment(ctx, r.db, appointment)
+	}
+
+	return r.db.WithTx(ctx, func(tx *Tx) error {
+		if err := checkResourceCapacity(ctx, tx, appointment.ClinicID, []*model.Appointment{appointment}); err != nil {
+			return err
+		}
+		return insertAppointment(ctx, tx, appointment)
+	})
+}
+
+// insertAppointment inserts an appointment using the given connection or transaction.
+func insertAppointment(ctx context.Context, q Querier, appointment *model.Appointment) error {
+	var typeID sql.NullString
 	query := `
 		INSERT INTO appointments (
 			id, clinic_id, patient_id, therapist_id, start_time, end_time,
-			duration, type, status, room, notes, recurrence_id, created_by, updated_by
+			duration, type, status, room, notes, recurrence_id, resource_ids, group_session_id,
+			appointment_type_id, created_by, updated_by
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $13
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $14, $15,
+			(SELECT id FROM appointment_types WHERE clinic_id = $2 AND code = $8), $13, $13
 		)
-		RETURNING created_at, updated_at`
+		RETURNING appointment_type_id, created_at, updated_at`
 
-	err := r.db.QueryRowContext(ctx, query,
+	err := q.QueryRowContext(ctx, query,
 		appointment.ID,
 		appointment.ClinicID,
 		appointment.PatientID,
@@ -68,7 +98,9 @@ func (r *postgresAppointmentRepo) Create(ctx context.Context, appointment *model
 		NullableStringValue(appointment.Notes),
 		NullableString(appointment.RecurrenceID),
 		NullableString(appointment.CreatedBy),
-	).Scan(&appointment.CreatedAt, &appointment.UpdatedAt)
+		pq.Array(nonNilStrings(appointment.ResourceIDs)),
+		NullableString(appointment.GroupSessionID),
+	).Scan(&typeID, &appointment.CreatedAt, &appointment.UpdatedAt)
 
 	if err != nil {
 		if pqErr, ok := err.(*pq.Error); ok {
@@ -81,6 +113,7 @@ func (r *postgresAppointmentRepo) Create(ctx context.Context, appointment *model
 		}
 		return fmt.Errorf("failed to create appointment: %w", err)
 	}
+	appointment.AppointmentTypeID = StringPtrFromNull(typeID)
 
 	return nil
 }
@@ -91,7 +124,7 @@ func (r *postgresAppointmentRepo) GetByID(ctx context.Context, clinicID, id stri
 		SELECT
 			a.id, a.clinic_id, a.patient_id, a.therapist_id, a.start_time, a.end_time,
 			a.duration, a.type, a.status, a.room, a.notes, a.cancellation_reason,
-			a.recurrence_id, a.created_at, a.updated_at, a.created_by, a.updated_by,
+			a.recurrence_id, a.resource_ids, a.group_session_id, a.appointment_type_id, a.created_at, a.updated_at, a.created_by, a.updated_by,
 			COALESCE(p.first_name || ' ' || p.last_name, '') as patient_name,
 			COALESCE(p.mrn, '') as patient_mrn,
 			COALESCE(p.phone, '') as patient_phone,
@@ -108,7 +141,7 @@ func (r *postgresAppointmentRepo) GetByID(ctx context.Context, clinicID, id stri
 func (r *postgresAppointmentRepo) scanAppointmentWithDetails(row *sql.Row) (*model.AppointmentWithDetails, error) {
 	var a model.AppointmentWithDetails
 	var room, notes, cancellationReason sql.NullString
-	var recurrenceID, createdBy, updatedBy sql.NullString
+	var recurrenceID, groupSessionID, appointmentTypeID, createdBy, updatedBy sql.NullString
 
 	err := row.Scan(
 		&a.ID,
@@ -124,6 +157,9 @@ func (r *postgresAppointmentRepo) scanAppointmentWithDetails(row *sql.Row) (*mod
 		&notes,
 		&cancellationReason,
 		&recurrenceID,
+		pq.Array(&a.ResourceIDs),
+		&groupSessionID,
+		&appointmentTypeID,
 		&a.CreatedAt,
 		&a.UpdatedAt,
 		&createdBy,
@@ -145,14 +181,31 @@ func (r *postgresAppointmentRepo) scanAppointmentWithDetails(row *sql.Row) (*mod
 	a.Notes = StringFromNull(notes)
 	a.CancellationReason = StringFromNull(cancellationReason)
 	a.RecurrenceID = StringPtrFromNull(recurrenceID)
+	a.GroupSessionID = StringPtrFromNull(groupSessionID)
+	a.AppointmentTypeID = StringPtrFromNull(appointmentTypeID)
 	a.CreatedBy = StringPtrFromNull(createdBy)
 	a.UpdatedBy = StringPtrFromNull(updatedBy)
 
 	return &a, nil
 }
 
-// Update updates an existing appointment record.
+// Update updates an existing appointment record. An appointment that requires
+// resources is updated in a transaction that checks their capacity first.
 func (r *postgresAppointmentRepo) Update(ctx context.Context, appointment *model.Appointment) error {
+	if len(appointment.ResourceIDs) == 0 || !holdsResources(appointment.Status) {
+		return updateAppointment(ctx, r.db, appointment)
+	}
+
+	return r.db.WithTx(ctx, func(tx *Tx) error {
+		if err := checkResourceCapacity(ctx, tx, appointment.ClinicID, []*model.Appointment{appointment}); err != nil {
+			return err
+		}
+		return updateAppointment(ctx, tx, appointment)
+	})
+}
+
+// updateAppointment updates an appointment using the given connection or transaction.
+func updateAppointment(ctx context.Context, q Querier, appointment *model.Appointment) error {
 	query := `
 		UPDATE appointments SET
 			therapist_id = $1,
@@ -164,11 +217,14 @@ func (r *postgresAppointmentRepo) Update(ctx context.Context, appointment *model
 			room = $7,
 			notes = $8,
 			cancellation_reason = $9,
-			updated_by = $10
+			updated_by = $10,
+			recurrence_id = $13,
+			resource_ids = $14,
+			appointment_type_id = (SELECT id FROM appointment_types WHERE clinic_id = $12 AND code = $5)
 		WHERE id = $11 AND clinic_id = $12
-		RETURNING updated_at`
+		RETURNING appointment_type_id, updated_at`
 
-	result := r.db.QueryRowContext(ctx, query,
+	result := q.QueryRowContext(ctx, query,
 		appointment.TherapistID,
 		appointment.StartTime,
 		appointment.EndTime,
@@ -181,14 +237,18 @@ func (r *postgresAppointmentRepo) Update(ctx context.Context, appointment *model
 		NullableString(appointment.UpdatedBy),
 		appointment.ID,
 		appointment.ClinicID,
+		NullableString(appointment.RecurrenceID),
+		pq.Array(nonNilStrings(appointment.ResourceIDs)),
 	)
 
-	if err := result.Scan(&appointment.UpdatedAt); err != nil {
+	var typeID sql.NullString
+	if err := result.Scan(&typeID, &appointment.UpdatedAt); err != nil {
 		if err == sql.ErrNoRows {
 			return ErrNotFound
 		}
 		return fmt.Errorf("failed to update appointment: %w", err)
 	}
+	appointment.AppointmentTypeID = StringPtrFromNull(typeID)
 
 	return nil
 }
@@ -296,7 +356,7 @@ func (r *postgresAppointmentRepo) List(ctx context.Context, params model.Appoint
 		SELECT
 			a.id, a.clinic_id, a.patient_id, a.therapist_id, a.start_time, a.end_time,
 			a.duration, a.type, a.status, a.room, a.notes, a.cancellation_reason,
-			a.recurrence_id, a.created_at, a.updated_at, a.created_by, a.updated_by,
+			a.recurrence_id, a.resource_ids, a.group_session_id, a.appointment_type_id, a.created_at, a.updated_at, a.created_by, a.updated_by,
 			COALESCE(p.first_name || ' ' || p.last_name, '') as patient_name,
 			COALESCE(p.mrn, '') as patient_mrn,
 			COALESCE(p.phone, '') as patient_phone,
@@ -337,7 +397,7 @@ func (r *postgresAppointmentRepo) List(ctx context.Context, params model.Appoint
 func (r *postgresAppointmentRepo) scanAppointmentWithDetailsRows(rows *sql.Rows) (*model.AppointmentWithDetails, error) {
 	var a model.AppointmentWithDetails
 	var room, notes, cancellationReason sql.NullString
-	var recurrenceID, createdBy, updatedBy sql.NullString
+	var recurrenceID, groupSessionID, appointmentTypeID, createdBy, updatedBy sql.NullString
 
 	err := rows.Scan(
 		&a.ID,
@@ -353,6 +413,9 @@ func (r *postgresAppointmentRepo) scanAppointmentWithDetailsRows(rows *sql.Rows)
 		&notes,
 		&cancellationReason,
 		&recurrenceID,
+		pq.Array(&a.ResourceIDs),
+		&groupSessionID,
+		&appointmentTypeID,
 		&a.CreatedAt,
 		&a.UpdatedAt,
 		&createdBy,
@@ -371,6 +434,8 @@ func (r *postgresAppointmentRepo) scanAppointmentWithDetailsRows(rows *sql.Rows)
 	a.Notes = StringFromNull(notes)
 	a.CancellationReason = StringFromNull(cancellationReason)
 	a.RecurrenceID = StringPtrFromNull(recurrenceID)
+	a.GroupSessionID = StringPtrFromNull(groupSessionID)
+	a.AppointmentTypeID = StringPtrFromNull(appointmentTypeID)
 	a.CreatedBy = StringPtrFromNull(createdBy)
 	a.UpdatedBy = StringPtrFromNull(updatedBy)
 
@@ -383,7 +448,7 @@ func (r *postgresAppointmentRepo) GetByDateRange(ctx context.Context, clinicID s
 		SELECT
 			a.id, a.clinic_id, a.patient_id, a.therapist_id, a.start_time, a.end_time,
 			a.duration, a.type, a.status, a.room, a.notes, a.cancellation_reason,
-			a.recurrence_id, a.created_at, a.updated_at, a.created_by, a.updated_by,
+			a.recurrence_id, a.resource_ids, a.group_session_id, a.appointment_type_id, a.created_at, a.updated_at, a.created_by, a.updated_by,
 			COALESCE(p.first_name || ' ' || p.last_name, '') as patient_name,
 			COALESCE(p.mrn, '') as patient_mrn,
 			COALESCE(p.phone, '') as patient_phone,
@@ -425,7 +490,7 @@ func (r *postgresAppointmentRepo) GetByPatient(ctx context.Context, clinicID, pa
 		SELECT
 			a.id, a.clinic_id, a.patient_id, a.therapist_id, a.start_time, a.end_time,
 			a.duration, a.type, a.status, a.room, a.notes, a.cancellation_reason,
-			a.recurrence_id, a.created_at, a.updated_at, a.created_by, a.updated_by,
+			a.recurrence_id, a.resource_ids, a.group_session_id, a.appointment_type_id, a.created_at, a.updated_at, a.created_by, a.updated_by,
 			COALESCE(p.first_name || ' ' || p.last_name, '') as patient_name,
 			COALESCE(p.mrn, '') as patient_mrn,
 			COALESCE(p.phone, '') as patient_phone,
@@ -461,7 +526,7 @@ func (r *postgresAppointmentRepo) GetByTherapist(ctx context.Context, clinicID,
 		SELECT
 			a.id, a.clinic_id, a.patient_id, a.therapist_id, a.start_time, a.end_time,
 			a.duration, a.type, a.status, a.room, a.notes, a.cancellation_reason,
-			a.recurrence_id, a.created_at, a.updated_at, a.created_by, a.updated_by,
+			a.recurrence_id, a.resource_ids, a.group_session_id, a.appointment_type_id, a.created_at, a.updated_at, a.created_by, a.updated_by,
 			COALESCE(p.first_name || ' ' || p.last_name, '') as patient_name,
 			COALESCE(p.mrn, '') as patient_mrn,
 			COALESCE(p.phone, '') as patient_phone,
@@ -494,6 +559,53 @@ func (r *postgresAppointmentRepo) GetByTherapist(ctx context.Context, clinicID,
 	return appointments, nil
 }
 
+// GetCalendar retrieves the appointments a calendar feed publishes: a
+// therapist's or a patient's appointments starting within the range,
+// including cancelled ones so subscribers see them cancelled. A therapist's
+// group sessions are published once as sessions, so their participants'
+// appointments are left out of therapist calendars.
+func (r *postgresAppointmentRepo) GetCalendar(ctx context.Context, clinicID string, kind model.CalendarFeedKind, ownerID string, start, end time.Time) ([]model.AppointmentWithDetails, error) {
+	ownerFilter := "a.patient_id = $2"
+	if kind == model.CalendarFeedTherapist {
+		ownerFilter = "a.therapist_id = $2 AND a.group_session_id IS NULL"
+	}
+
+	query := `
+		SELECT
+			a.id, a.clinic_id, a.patient_id, a.therapist_id, a.start_time, a.end_time,
+			a.duration, a.type, a.status, a.room, a.notes, a.cancellation_reason,
+			a.recurrence_id, a.resource_ids, a.group_session_id, a.appointment_type_id, a.created_at, a.updated_at, a.created_by, a.updated_by,
+			COALESCE(p.first_name || ' ' || p.last_name, '') as patient_name,
+			COALESCE(p.mrn, '') as patient_mrn,
+			COALESCE(p.phone, '') as patient_phone,
+			COALESCE(u.first_name || ' ' || u.last_name, '') as therapist_name
+		FROM appointments a
+		LEFT JOIN patients p ON a.patient_id = p.id
+		LEFT JOIN users u ON a.therapist_id = u.id
+		WHERE a.clinic_id = $1
+			AND ` + ownerFilter + `
+			AND a.start_time >= $3
+			AND a.start_time < $4
+		ORDER BY a.start_time ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, clinicID, ownerID, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get calendar appointments: %w", err)
+	}
+	defer rows.Close()
+
+	appointments := make([]model.AppointmentWithDetails, 0)
+	for rows.Next() {
+		a, err := r.scanAppointmentWithDetailsRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		appointments = append(appointments, *a)
+	}
+
+	return appointments, rows.Err()
+}
+
 // GetDaySchedule retrieves all appointments for a specific day.
 func (r *postgresAppointmentRepo) GetDaySchedule(ctx context.Context, clinicID string, date time.Time) (*model.DaySchedule, error) {
 	// Start of day
@@ -514,20 +626,25 @@ func (r *postgresAppointmentRepo) GetDaySchedule(ctx context.Context, clinicID s
 
 // FindConflicts finds appointments that overlap with the given time range.
 func (r *postgresAppointmentRepo) FindConflicts(ctx context.Context, clinicID, therapistID string, start, end time.Time, excludeID string) ([]model.Appointment, error) {
+	return findConflicts(ctx, r.db, clinicID, therapistID, start, end, excludeID)
+}
+
+// findConflicts finds a therapist's active appointments overlapping the given
+// time range using the given connection or transaction. Group sessions count
+// once, as the session itself, rather than once per participant.
+func findConflicts(ctx context.Context, q Querier, clinicID, therapistID string, start, end time.Time, excludeID string) ([]model.Appointment, error) {
 	query := `
-		SELECT
-			id, clinic_id, patient_id, therapist_id, start_time, end_time,
-			duration, type, status, room, notes, cancellation_reason,
-			recurrence_id, created_at, updated_at, created_by, updated_by
+		SELECT ` + appointmentColumns + `
 		FROM appointments
 		WHERE clinic_id = $1
 			AND therapist_id = $2
+			AND group_session_id IS NULL
 			AND status NOT IN ('cancelled', 'no_show')
 			AND start_time < $4
 			AND end_time > $3
 			AND ($5 = '' OR id != $5)`
 
-	rows, err := r.db.QueryContext(ctx, query, clinicID, therapistID, start, end, excludeID)
+	rows, err := q.QueryContext(ctx, query, clinicID, therapistID, start, end, excludeID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find conflicts: %w", err)
 	}
@@ -535,54 +652,147 @@ func (r *postgresAppointmentRepo) FindConflicts(ctx context.Context, clinicID, t
 
 	conflicts := make([]model.Appointment, 0)
 	for rows.Next() {
-		var a model.Appointment
-		var room, notes, cancellationReason sql.NullString
-		var recurrenceID, createdBy, updatedBy sql.NullString
-
-		err := rows.Scan(
-			&a.ID,
-			&a.ClinicID,
-			&a.PatientID,
-			&a.TherapistID,
-			&a.StartTime,
-			&a.EndTime,
-			&a.Duration,
-			&a.Type,
-			&a.Status,
-			&room,
-			&notes,
-			&cancellationReason,
-			&recurrenceID,
-			&a.CreatedAt,
-			&a.UpdatedAt,
-			&createdBy,
-			&updatedBy,
-		)
+		a, err := scanAppointment(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan conflict: %w", err)
 		}
+		conflicts = append(conflicts, *a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to find conflicts: %w", err)
+	}
 
-		a.Room = StringFromNull(room)
-		a.Notes = StringFromNull(notes)
-		a.CancellationReason = StringFromNull(cancellationReason)
-		a.RecurrenceID = StringPtrFromNull(recurrenceID)
-		a.CreatedBy = StringPtrFromNull(createdBy)
-		a.UpdatedBy = StringPtrFromNull(updatedBy)
-
-		conflicts = append(conflicts, a)
+	sessions, err := findSessionConflicts(ctx, q, clinicID, therapistID, start, end, excludeID)
+	if err != nil {
+		return nil, err
+	}
+	for _, gs := range sessions {
+		conflicts = append(conflicts, gs.AsAppointment())
 	}
 
 	return conflicts, nil
 }
 
-// GetTherapistSchedule retrieves the regular working schedule for a therapist.
+// FindResourceBookings finds active appointments that require any of the
+// given resources and overlap the given time range.
+func (r *postgresAppointmentRepo) FindResourceBookings(ctx context.Context, clinicID string, resourceIDs []string, start, end time.Time, excludeIDs []string) ([]model.Appointment, error) {
+	return findResourceBookings(ctx, r.db, clinicID, resourceIDs, start, end, excludeIDs)
+}
+
+// findResourceBookings finds active appointments requiring any of the given
+// resources within the time range using the given connection or transaction.
+func findResourceBookings(ctx context.Context, q Querier, clinicID string, resourceIDs []string, start, end time.Time, excludeIDs []string) ([]model.Appointment, error) {
+	if len(resourceIDs) == 0 {
+		return []model.Appointment{}, nil
+	}
+
+	query := `
+		SELECT ` + appointmentColumns + `
+		FROM appointments
+		WHERE clinic_id = $1
+			AND resource_ids && $2::uuid[]
+			AND status NOT IN ('cancelled', 'no_show')
+			AND start_time < $4
+			AND end_time > $3
+			AND NOT (id = ANY($5::uuid[]))
+		ORDER BY start_time`
+
+	rows, err := q.QueryContext(ctx, query, clinicID, pq.Array(resourceIDs), start, end, pq.Array(nonNilStrings(excludeIDs)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find resource bookings: %w", err)
+	}
+	defer rows.Close()
+
+	bookings := make([]model.Appointment, 0)
+	for rows.Next() {
+		a, err := scanAppointment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan resource booking: %w", err)
+		}
+		bookings = append(bookings, *a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to find resource bookings: %w", err)
+	}
+
+	sessions, err := findSessionResourceBookings(ctx, q, clinicID, resourceIDs, start, end, excludeIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, gs := range sessions {
+		bookings = append(bookings, gs.AsAppointment())
+	}
+
+	return bookings, nil
+}
+
+// appointmentColumns lists the columns read by scanAppointment.
+const appointmentColumns = `
+	id, clinic_id, patient_id, therapist_id, start_time, end_time,
+	duration, type, status, room, notes, cancellation_reason,
+	recurrence_id, resource_ids, group_session_id, appointment_type_id,
+	created_at, updated_at, created_by, updated_by`
+
+// scanAppointment scans a row selected with appointmentColumns.
+func scanAppointment(row rowScanner) (*model.Appointment, error) {
+	var a model.Appointment
+	var room, notes, cancellationReason sql.NullString
+	var recurrenceID, groupSessionID, appointmentTypeID, createdBy, updatedBy sql.NullString
+
+	err := row.Scan(
+		&a.ID,
+		&a.ClinicID,
+		&a.PatientID,
+		&a.TherapistID,
+		&a.StartTime,
+		&a.EndTime,
+		&a.Duration,
+		&a.Type,
+		&a.Status,
+		&room,
+		&notes,
+		&cancellationReason,
+		&recurrenceID,
+		pq.Array(&a.ResourceIDs),
+		&groupSessionID,
+		&appointmentTypeID,
+		&a.CreatedAt,
+		&a.UpdatedAt,
+		&createdBy,
+		&updatedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	a.Room = StringFromNull(room)
+	a.Notes = StringFromNull(notes)
+	a.CancellationReason = StringFromNull(cancellationReason)
+	a.RecurrenceID = StringPtrFromNull(recurrenceID)
+	a.GroupSessionID = StringPtrFromNull(groupSessionID)
+	a.AppointmentTypeID = StringPtrFromNull(appointmentTypeID)
+	a.CreatedBy = StringPtrFromNull(createdBy)
+	a.UpdatedBy = StringPtrFromNull(updatedBy)
+
+	return &a, nil
+}
+
+// nonNilStrings returns ss, or an empty list for nil, for NOT NULL array columns.
+func nonNilStrings(ss []string) []string {
+	if ss == nil {
+		return []string{}
+	}
+	return ss
+}
+
+// GetTherapistSchedule retrieves the current and upcoming working schedule for a therapist.
 func (r *postgresAppointmentRepo) GetTherapistSchedule(ctx context.Context, clinicID, therapistID string) ([]model.TherapistSchedule, error) {
 	query := `
-		SELECT id, clinic_id, therapist_id, day_of_week, start_time, end_time,
-			is_active, created_at, updated_at
+		SELECT ` + therapistScheduleColumns + `
 		FROM therapist_schedules
 		WHERE clinic_id = $1 AND therapist_id = $2 AND is_active = true
-		ORDER BY day_of_week, start_time`
+			AND (effective_to IS NULL OR effective_to >= CURRENT_DATE)
+		ORDER BY day_of_week, start_time, effective_from`
 
 	rows, err := r.db.QueryContext(ctx, query, clinicID, therapistID)
 	if err != nil {
@@ -592,22 +802,11 @@ func (r *postgresAppointmentRepo) GetTherapistSchedule(ctx context.Context, clin
 
 	schedules := make([]model.TherapistSchedule, 0)
 	for rows.Next() {
-		var s model.TherapistSchedule
-		err := rows.Scan(
-			&s.ID,
-			&s.ClinicID,
-			&s.TherapistID,
-			&s.DayOfWeek,
-			&s.StartTime,
-			&s.EndTime,
-			&s.IsActive,
-			&s.CreatedAt,
-			&s.UpdatedAt,
-		)
+		s, err := scanTherapistSchedule(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan therapist schedule: %w", err)
 		}
-		schedules = append(schedules, s)
+		schedules = append(schedules, *s)
 	}
 
 	return schedules, nil
@@ -615,113 +814,205 @@ func (r *postgresAppointmentRepo) GetTherapistSchedule(ctx context.Context, clin
 
 // GetScheduleExceptions retrieves schedule exceptions for a therapist within a date range.
 func (r *postgresAppointmentRepo) GetScheduleExceptions(ctx context.Context, clinicID, therapistID string, start, end time.Time) ([]model.ScheduleException, error) {
+	return listScheduleExceptions(ctx, r.db, clinicID, therapistID, start, end)
+}
+
+// GetAvailableSlots calculates available time slots for a therapist on a given date.
+// Slots are offered only while every required resource has capacity left.
+func (r *postgresAppointmentRepo) GetAvailableSlots(ctx context.Context, clinicID, therapistID string, date time.Time, duration int, resourceIDs []string) ([]model.AvailabilitySlot, error) {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	return r.FindAvailableSlots(ctx, clinicID, []string{therapistID}, day, day.AddDate(0, 0, 1), duration, resourceIDs)
+}
+
+// FindAvailableSlots calculates the available slots of several therapists on
+// every day in [from, to), where from is the start of a day. Schedules,
+// exceptions, booked appointments and resource bookings are each loaded with
+// one query covering every therapist and day. Slots are ordered by day, then
+// by therapist in the given order, then by time.
+func (r *postgresAppointmentRepo) FindAvailableSlots(ctx context.Context, clinicID string, therapistIDs []string, from, to time.Time, duration int, resourceIDs []string) ([]model.AvailabilitySlot, error) {
+	slots := make([]model.AvailabilitySlot, 0)
+	if len(therapistIDs) == 0 || !from.Before(to) {
+		return slots, nil
+	}
+
+	schedules, err := r.activeSchedules(ctx, clinicID, therapistIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	exceptions, err := r.exceptionsByTherapist(ctx, clinicID, therapistIDs, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	booked, err := r.bookedByTherapist(ctx, clinicID, therapistIDs, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	// Load the required resources and their bookings for the whole range
+	resources, err := getActiveResources(ctx, r.db, clinicID, resourceIDs)
+	if err != nil {
+		return nil, err
+	}
+	resourceBookings, err := findResourceBookings(ctx, r.db, clinicID, resourceIDs, from, to, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		date := day.Format(dateOnly)
+		for _, therapistID := range therapistIDs {
+			var dayExceptions []model.ScheduleException
+			for _, e := range exceptions[therapistID] {
+				if e.Date.Format(dateOnly) == date {
+					dayExceptions = append(dayExceptions, e)
+				}
+			}
+			periods := workingPeriodsOn(day, schedules[therapistID], dayExceptions)
+			slots = append(slots, freeSlots(therapistID, periods, booked[therapistID], resources, resourceBookings, duration, now)...)
+		}
+	}
+
+	return slots, nil
+}
+
+// activeSchedules loads the active weekly working periods of the therapists.
+func (r *postgresAppointmentRepo) activeSchedules(ctx context.Context, clinicID string, therapistIDs []string) (map[string][]model.TherapistSchedule, error) {
 	query := `
-		SELECT id, clinic_id, therapist_id, date, start_time, end_time,
-			is_available, reason, created_at, updated_at
-		FROM schedule_exceptions
-		WHERE clinic_id = $1 AND therapist_id = $2 AND date >= $3 AND date < $4
-		ORDER BY date, start_time`
+		SELECT ` + therapistScheduleColumns + `
+		FROM therapist_schedules
+		WHERE clinic_id = $1 AND therapist_id = ANY($2::uuid[]) AND is_active = true
+		ORDER BY therapist_id, day_of_week, start_time`
 
-	rows, err := r.db.QueryContext(ctx, query, clinicID, therapistID, start, end)
+	rows, err := r.db.QueryContext(ctx, query, clinicID, pq.Array(therapistIDs))
 	if err != nil {
-		return nil, fmt.Errorf("failed to get schedule exceptions: %w", err)
+		return nil, fmt.Errorf("failed to get therapist schedules: %w", err)
 	}
 	defer rows.Close()
 
-	exceptions := make([]model.ScheduleException, 0)
+	schedules := make(map[string][]model.TherapistSchedule, len(therapistIDs))
 	for rows.Next() {
-		var e model.ScheduleException
-		var startTime, endTime, reason sql.NullString
-
-		err := rows.Scan(
-			&e.ID,
-			&e.ClinicID,
-			&e.TherapistID,
-			&e.Date,
-			&startTime,
-			&endTime,
-			&e.IsAvailable,
-			&reason,
-			&e.CreatedAt,
-			&e.UpdatedAt,
-		)
+		s, err := scanTherapistSchedule(rows)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan schedule exception: %w", err)
+			return nil, fmt.Errorf("failed to scan therapist schedule: %w", err)
 		}
-
-		e.StartTime = StringPtrFromNull(startTime)
-		e.EndTime = StringPtrFromNull(endTime)
-		e.Reason = StringFromNull(reason)
-
-		exceptions = append(exceptions, e)
+		schedules[s.TherapistID] = append(schedules[s.TherapistID], *s)
 	}
 
-	return exceptions, nil
+	return schedules, rows.Err()
 }
 
-// GetAvailableSlots calculates available time slots for a therapist on a given date.
-func (r *postgresAppointmentRepo) GetAvailableSlots(ctx context.Context, clinicID, therapistID string, date time.Time, duration int) ([]model.AvailabilitySlot, error) {
-	// Get the day of week
-	dayOfWeek := int(date.Weekday())
-
-	// Get therapist's schedule for this day
-	scheduleQuery := `
-		SELECT start_time, end_time
-		FROM therapist_schedules
-		WHERE clinic_id = $1 AND therapist_id = $2 AND day_of_week = $3 AND is_active = true`
+// exceptionsByTherapist loads the therapists' schedule exceptions dated within [from, to).
+func (r *postgresAppointmentRepo) exceptionsByTherapist(ctx context.Context, clinicID string, therapistIDs []string, from, to time.Time) (map[string][]model.ScheduleException, error) {
+	query := `
+		SELECT ` + scheduleExceptionColumns + `
+		FROM schedule_exceptions
+		WHERE clinic_id = $1 AND therapist_id = ANY($2::uuid[])
+			AND exception_date >= $3 AND exception_date < $4
+		ORDER BY therapist_id, exception_date, start_time NULLS FIRST`
 
-	rows, err := r.db.QueryContext(ctx, scheduleQuery, clinicID, therapistID, dayOfWeek)
+	rows, err := r.db.QueryContext(ctx, query, clinicID, pq.Array(therapistIDs), from.Format(dateOnly), to.Format(dateOnly))
 	if err != nil {
-		return nil, fmt.Errorf("failed to get therapist schedule: %w", err)
+		return nil, fmt.Errorf("failed to get schedule exceptions: %w", err)
 	}
 	defer rows.Close()
 
-	var workingPeriods []model.TimeRange
+	exceptions := make(map[string][]model.ScheduleException, len(therapistIDs))
 	for rows.Next() {
-		var startStr, endStr string
-		if err := rows.Scan(&startStr, &endStr); err != nil {
-			return nil, fmt.Errorf("failed to scan schedule: %w", err)
+		e, err := scanScheduleException(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan schedule exception: %w", err)
 		}
+		exceptions[e.TherapistID] = append(exceptions[e.TherapistID], *e)
+	}
 
-		startTime, _ := time.Parse("15:04", startStr)
-		endTime, _ := time.Parse("15:04", endStr)
+	return exceptions, rows.Err()
+}
 
-		start := time.Date(date.Year(), date.Month(), date.Day(), startTime.Hour(), startTime.Minute(), 0, 0, date.Location())
-		end := time.Date(date.Year(), date.Month(), date.Day(), endTime.Hour(), endTime.Minute(), 0, 0, date.Location())
+// bookedByTherapist loads the time ranges taken by the therapists' active
+// appointments and group sessions overlapping [from, to).
+func (r *postgresAppointmentRepo) bookedByTherapist(ctx context.Context, clinicID string, therapistIDs []string, from, to time.Time) (map[string][]model.TimeRange, error) {
+	query := `
+		SELECT therapist_id, start_time, end_time
+		FROM appointments
+		WHERE clinic_id = $1
+			AND therapist_id = ANY($2::uuid[])
+			AND group_session_id IS NULL
+			AND status NOT IN ('cancelled', 'no_show')
+			AND start_time < $4
+			AND end_time > $3
+		UNION ALL
+		SELECT therapist_id, start_time, end_time
+		FROM group_sessions
+		WHERE clinic_id = $1
+			AND therapist_id = ANY($2::uuid[])
+			AND status = 'scheduled'
+			AND start_time < $4
+			AND end_time > $3
+		ORDER BY start_time`
 
-		workingPeriods = append(workingPeriods, model.TimeRange{Start: start, End: end})
+	rows, err := r.db.QueryContext(ctx, query, clinicID, pq.Array(therapistIDs), from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get booked appointments: %w", err)
 	}
+	defer rows.Close()
 
-	// If no schedule found, use default working hours (8:00 - 17:00)
-	if len(workingPeriods) == 0 {
-		start := time.Date(date.Year(), date.Month(), date.Day(), 8, 0, 0, 0, date.Location())
-		end := time.Date(date.Year(), date.Month(), date.Day(), 17, 0, 0, 0, date.Location())
-		workingPeriods = append(workingPeriods, model.TimeRange{Start: start, End: end})
+	booked := make(map[string][]model.TimeRange, len(therapistIDs))
+	for rows.Next() {
+		var therapistID string
+		var tr model.TimeRange
+		if err := rows.Scan(&therapistID, &tr.Start, &tr.End); err != nil {
+			return nil, fmt.Errorf("failed to scan booked appointment: %w", err)
+		}
+		booked[therapistID] = append(booked[therapistID], tr)
 	}
 
-	// Get existing appointments for the day
-	startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
-	endOfDay := startOfDay.AddDate(0, 0, 1)
+	return booked, rows.Err()
+}
 
-	existingAppointments, err := r.GetByTherapist(ctx, clinicID, therapistID, startOfDay, endOfDay)
-	if err != nil {
-		return nil, err
+// workingPeriodsOn returns the times a therapist works on a day: the weekly
+// schedule in effect on that date, minus leave and other blocking exceptions,
+// plus extra shifts. A therapist with no active weekly schedule at all works
+// the default hours (8:00 - 17:00).
+func workingPeriodsOn(day time.Time, schedules []model.TherapistSchedule, exceptions []model.ScheduleException) []model.TimeRange {
+	var periods []model.TimeRange
+	for _, s := range schedules {
+		if s.AppliesOn(day) {
+			periods = append(periods, model.TimeRange{
+				Start: model.ClockTimeOn(day, s.StartTime),
+				End:   model.ClockTimeOn(day, s.EndTime),
+			})
+		}
 	}
 
-	// Build list of booked time ranges
-	var bookedPeriods []model.TimeRange
-	for _, appt := range existingAppointments {
-		bookedPeriods = append(bookedPeriods, model.TimeRange{Start: appt.StartTime, End: appt.EndTime})
+	if len(schedules) == 0 {
+		periods = append(periods, model.TimeRange{
+			Start: time.Date(day.Year(), day.Month(), day.Day(), 8, 0, 0, 0, day.Location()),
+			End:   time.Date(day.Year(), day.Month(), day.Day(), 17, 0, 0, 0, day.Location()),
+		})
 	}
 
-	// Calculate available slots
+	return applyScheduleExceptions(periods, exceptions, day.Location())
+}
+
+// freeSlots lists the slots of the given duration, in 15-minute increments,
+// that fit in the working periods without overlapping a booked period, start
+// after now and leave capacity on every required resource.
+func freeSlots(therapistID string, workingPeriods, bookedPeriods []model.TimeRange, resources []model.Resource, resourceBookings []model.Appointment, duration int, now time.Time) []model.AvailabilitySlot {
 	slots := make([]model.AvailabilitySlot, 0)
 	slotIncrement := 15 * time.Minute // 15-minute increments
+	length := time.Duration(duration) * time.Minute
 
 	for _, period := range workingPeriods {
-		current := period.Start
-		for current.Add(time.Duration(duration) * time.Minute).Before(period.End) || current.Add(time.Duration(duration)*time.Minute).Equal(period.End) {
-			slotEnd := current.Add(time.Duration(duration) * time.Minute)
+		for current := period.Start; !current.Add(length).After(period.End); current = current.Add(slotIncrement) {
+			slotEnd := current.Add(length)
+
+			// Don't show slots in the past
+			if current.Before(now) {
+				continue
+			}
 
 			// Check if this slot overlaps with any booked period
 			isAvailable := true
@@ -732,9 +1023,14 @@ func (r *postgresAppointmentRepo) GetAvailableSlots(ctx context.Context, clinicI
 				}
 			}
 
-			// Don't show slots in the past
-			if current.Before(time.Now()) {
-				isAvailable = false
+			// Every required resource must have capacity left
+			for _, res := range resources {
+				if !isAvailable {
+					break
+				}
+				if res.Conflicts(resourceBookings, current, slotEnd) != nil {
+					isAvailable = false
+				}
 			}
 
 			if isAvailable {
@@ -745,12 +1041,57 @@ func (r *postgresAppointmentRepo) GetAvailableSlots(ctx context.Context, clinicI
 					Duration:    duration,
 				})
 			}
+		}
+	}
+
+	return slots
+}
 
-			current = current.Add(slotIncrement)
+// applyScheduleExceptions removes the time blocked by exceptions from the
+// working periods and adds extra shifts. Extra shifts are applied last, so an
+// extra shift on a day of leave still counts as working time. The result is
+// sorted and has no overlapping periods.
+func applyScheduleExceptions(periods []model.TimeRange, exceptions []model.ScheduleException, loc *time.Location) []model.TimeRange {
+	for _, e := range exceptions {
+		if e.IsAvailable {
+			continue
 		}
+		block := e.Window(loc)
+		var remaining []model.TimeRange
+		for _, p := range periods {
+			if !p.Start.Before(block.End) || !p.End.After(block.Start) {
+				remaining = append(remaining, p)
+				continue
+			}
+			if p.Start.Before(block.Start) {
+				remaining = append(remaining, model.TimeRange{Start: p.Start, End: block.Start})
+			}
+			if p.End.After(block.End) {
+				remaining = append(remaining, model.TimeRange{Start: block.End, End: p.End})
+			}
+		}
+		periods = remaining
 	}
 
-	return slots, nil
+	for _, e := range exceptions {
+		if e.IsAvailable && e.StartTime != nil && e.EndTime != nil {
+			periods = append(periods, e.Window(loc))
+		}
+	}
+
+	sort.Slice(periods, func(i, j int) bool { return periods[i].Start.Before(periods[j].Start) })
+	merged := make([]model.TimeRange, 0, len(periods))
+	for _, p := range periods {
+		if n := len(merged); n > 0 && !p.Start.After(merged[n-1].End) {
+			if p.End.After(merged[n-1].End) {
+				merged[n-1].End = p.End
+			}
+			continue
+		}
+		merged = append(merged, p)
+	}
+
+	return merged
 }
 
 // CancelByRecurrenceID cancels all appointments in a recurring series from a given date.
@@ -768,6 +1109,294 @@ func (r *postgresAppointmentRepo) CancelByRecurrenceID(ctx context.Context, clin
 	return nil
 }
 
+// CreateSeries inserts a recurring series and its appointments in one
+// transaction. The therapist's schedule is locked for the duration of the
+// transaction and conflicts are checked again, so a series is either created
+// in full or not at all.
+func (r *postgresAppointmentRepo) CreateSeries(ctx context.Context, series *model.RecurrenceSeries, appointments []*model.Appointment) error {
+	if len(appointments) == 0 {
+		return fmt.Errorf("%w: series has no occurrences", ErrInvalidInput)
+	}
+
+	return r.db.WithTx(ctx, func(tx *Tx) error {
+		if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, series.TherapistID); err != nil {
+			return fmt.Errorf("failed to lock therapist schedule: %w", err)
+		}
+
+		first, last := appointments[0], appointments[len(appointments)-1]
+		existing, err := findConflicts(ctx, tx, series.ClinicID, series.TherapistID, first.StartTime, last.EndTime, "")
+		if err != nil {
+			return err
+		}
+		for _, a := range appointments {
+			for _, e := range existing {
+				if a.StartTime.Before(e.EndTime) && a.EndTime.After(e.StartTime) {
+					return fmt.Errorf("%w: occurrence at %s overlaps an existing appointment", ErrConflict, a.StartTime.Format(time.RFC3339))
+				}
+			}
+		}
+
+		if err := checkResourceCapacity(ctx, tx, series.ClinicID, appointments); err != nil {
+			return err
+		}
+
+		if err := insertSeries(ctx, tx, series); err != nil {
+			return err
+		}
+		for _, a := range appointments {
+			if err := insertAppointment(ctx, tx, a); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// insertSeries inserts a recurrence series record.
+func insertSeries(ctx context.Context, q Querier, series *model.RecurrenceSeries) error {
+	query := `
+		INSERT INTO appointment_series (
+			id, clinic_id, patient_id, therapist_id, rrule, dtstart, timezone,
+			duration, type, room, notes, exdates, created_by, resource_ids
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
+		)
+		RETURNING created_at, updated_at`
+
+	err := q.QueryRowContext(ctx, query,
+		series.ID,
+		series.ClinicID,
+		series.PatientID,
+		series.TherapistID,
+		series.RRule,
+		series.DTStart,
+		series.Timezone,
+		series.Duration,
+		series.Type,
+		NullableStringValue(series.Room),
+		NullableStringValue(series.Notes),
+		pq.Array(formatExDates(series.ExDates)),
+		NullableString(series.CreatedBy),
+		pq.Array(nonNilStrings(series.ResourceIDs)),
+	).Scan(&series.CreatedAt, &series.UpdatedAt)
+
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			return fmt.Errorf("%w: invalid patient or therapist ID", ErrInvalidInput)
+		}
+		return fmt.Errorf("failed to create appointment series: %w", err)
+	}
+
+	return nil
+}
+
+// ApplySeriesEdit applies an edit to one or two series and their appointments
+// in one transaction. Moved appointments are checked for conflicts again after
+// all changes are written, with the affected therapists' schedules locked, so
+// a concurrent booking makes the whole edit fail rather than part of it.
+func (r *postgresAppointmentRepo) ApplySeriesEdit(ctx context.Context, edit *model.SeriesEdit) error {
+	return r.db.WithTx(ctx, func(tx *Tx) error {
+		therapists := make(map[string]bool)
+		for _, a := range edit.Moved {
+			therapists[a.TherapistID] = true
+		}
+		ids := make([]string, 0, len(therapists))
+		for id := range therapists {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids) // consistent lock order
+		for _, id := range ids {
+			if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, id); err != nil {
+				return fmt.Errorf("failed to lock therapist schedule: %w", err)
+			}
+		}
+
+		if err := updateSeries(ctx, tx, edit.Series); err != nil {
+			return err
+		}
+		if edit.Split != nil {
+			if err := insertSeries(ctx, tx, edit.Split); err != nil {
+				return err
+			}
+		}
+
+		for _, list := range [][]*model.Appointment{edit.Moved, edit.Other} {
+			for _, a := range list {
+				if err := updateAppointment(ctx, tx, a); err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := checkResourceCapacity(ctx, tx, edit.Series.ClinicID, append(append([]*model.Appointment{}, edit.Moved...), edit.Other...)); err != nil {
+			return err
+		}
+
+		for _, a := range edit.Moved {
+			conflicts, err := findConflicts(ctx, tx, a.ClinicID, a.TherapistID, a.StartTime, a.EndTime, a.ID)
+			if err != nil {
+				return err
+			}
+			if len(conflicts) > 0 {
+				return fmt.Errorf("%w: occurrence at %s overlaps an existing appointment", ErrConflict, a.StartTime.Format(time.RFC3339))
+			}
+		}
+
+		return nil
+	})
+}
+
+// updateSeries updates a recurrence series record.
+func updateSeries(ctx context.Context, q Querier, series *model.RecurrenceSeries) error {
+	query := `
+		UPDATE appointment_series SET
+			therapist_id = $1,
+			rrule = $2,
+			dtstart = $3,
+			timezone = $4,
+			duration = $5,
+			type = $6,
+			room = $7,
+			notes = $8,
+			exdates = $9,
+			resource_ids = $12
+		WHERE id = $10 AND clinic_id = $11
+		RETURNING updated_at`
+
+	err := q.QueryRowContext(ctx, query,
+		series.TherapistID,
+		series.RRule,
+		series.DTStart,
+		series.Timezone,
+		series.Duration,
+		series.Type,
+		NullableStringValue(series.Room),
+		NullableStringValue(series.Notes),
+		pq.Array(formatExDates(series.ExDates)),
+		series.ID,
+		series.ClinicID,
+		pq.Array(nonNilStrings(series.ResourceIDs)),
+	).Scan(&series.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update appointment series: %w", err)
+	}
+
+	return nil
+}
+
+// GetSeries retrieves a recurrence series by ID.
+func (r *postgresAppointmentRepo) GetSeries(ctx context.Context, clinicID, id string) (*model.RecurrenceSeries, error) {
+	query := `
+		SELECT id, clinic_id, patient_id, therapist_id, rrule, dtstart, timezone,
+			duration, type, room, notes, exdates, resource_ids, created_by, created_at, updated_at
+		FROM appointment_series
+		WHERE id = $1 AND clinic_id = $2`
+
+	var s model.RecurrenceSeries
+	var room, notes, createdBy sql.NullString
+	var exdates pq.StringArray
+
+	err := r.db.QueryRowContext(ctx, query, id, clinicID).Scan(
+		&s.ID,
+		&s.ClinicID,
+		&s.PatientID,
+		&s.TherapistID,
+		&s.RRule,
+		&s.DTStart,
+		&s.Timezone,
+		&s.Duration,
+		&s.Type,
+		&room,
+		&notes,
+		&exdates,
+		pq.Array(&s.ResourceIDs),
+		&createdBy,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get appointment series: %w", err)
+	}
+
+	s.Room = StringFromNull(room)
+	s.Notes = StringFromNull(notes)
+	s.CreatedBy = StringPtrFromNull(createdBy)
+	s.ExDates, err = parseExDates(exdates)
+	if err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// ListBySeries retrieves every appointment of a recurring series in start order.
+func (r *postgresAppointmentRepo) ListBySeries(ctx context.Context, clinicID, seriesID string) ([]model.AppointmentWithDetails, error) {
+	query := `
+		SELECT
+			a.id, a.clinic_id, a.patient_id, a.therapist_id, a.start_time, a.end_time,
+			a.duration, a.type, a.status, a.room, a.notes, a.cancellation_reason,
+			a.recurrence_id, a.resource_ids, a.group_session_id, a.appointment_type_id, a.created_at, a.updated_at, a.created_by, a.updated_by,
+			COALESCE(p.first_name || ' ' || p.last_name, '') as patient_name,
+			COALESCE(p.mrn, '') as patient_mrn,
+			COALESCE(p.phone, '') as patient_phone,
+			COALESCE(u.first_name || ' ' || u.last_name, '') as therapist_name
+		FROM appointments a
+		LEFT JOIN patients p ON a.patient_id = p.id
+		LEFT JOIN users u ON a.therapist_id = u.id
+		WHERE a.clinic_id = $1 AND a.recurrence_id = $2
+		ORDER BY a.start_time ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, clinicID, seriesID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list series appointments: %w", err)
+	}
+	defer rows.Close()
+
+	appointments := make([]model.AppointmentWithDetails, 0)
+	for rows.Next() {
+		a, err := r.scanAppointmentWithDetailsRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		appointments = append(appointments, *a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating series appointments: %w", err)
+	}
+
+	return appointments, nil
+}
+
+// formatExDates encodes excluded occurrences for the exdates column.
+func formatExDates(exdates []time.Time) []string {
+	out := make([]string, len(exdates))
+	for i, t := range exdates {
+		out[i] = t.UTC().Format(time.RFC3339)
+	}
+	return out
+}
+
+// parseExDates decodes the exdates column.
+func parseExDates(values []string) ([]time.Time, error) {
+	out := make([]time.Time, 0, len(values))
+	for _, v := range values {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse series exdate %q: %w", v, err)
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
 // CountByClinic returns the total number of appointments in a clinic.
 func (r *postgresAppointmentRepo) CountByClinic(ctx context.Context, clinicID string) (int64, error) {
 	query := `SELECT COUNT(*) FROM appointments WHERE clinic_id = $1`
@@ -781,7 +1410,7 @@ func (r *postgresAppointmentRepo) CountByClinic(ctx context.Context, clinicID st
 // GetTherapists retrieves all active therapists for a clinic.
 func (r *postgresAppointmentRepo) GetTherapists(ctx context.Context, clinicID string) ([]model.Therapist, error) {
 	query := `
-		SELECT id, first_name, last_name, email
+		SELECT id, first_name, last_name, email, COALESCE(specializations, '{}')
 		FROM users
 		WHERE clinic_id = $1 AND active = true AND 'therapist' = ANY(roles)
 		ORDER BY last_name, first_name`
@@ -799,7 +1428,7 @@ func (r *postgresAppointmentRepo) GetTherapists(ctx context.Context, clinicID st
 		var t model.Therapist
 		var email sql.NullString
 
-		err := rows.Scan(&t.ID, &t.FirstName, &t.LastName, &email)
+		err := rows.Scan(&t.ID, &t.FirstName, &t.LastName, &email, pq.Array(&t.Specializations))
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan therapist: %w", err)
 		}
@@ -814,10 +1443,154 @@ func (r *postgresAppointmentRepo) GetTherapists(ctx context.Context, clinicID st
 	return therapists, nil
 }
 
+// GetTherapistHistory counts a patient's appointments with each therapist
+// since the given time, leaving out cancellations and no-shows. The therapist
+// seen most often, and most recently among equals, comes first.
+func (r *postgresAppointmentRepo) GetTherapistHistory(ctx context.Context, clinicID, patientID string, since time.Time) ([]model.TherapistVisitCount, error) {
+	query := `
+		SELECT therapist_id, COUNT(*), MAX(start_time)
+		FROM appointments
+		WHERE clinic_id = $1
+			AND patient_id = $2
+			AND start_time >= $3
+			AND status NOT IN ('cancelled', 'no_show')
+		GROUP BY therapist_id
+		ORDER BY COUNT(*) DESC, MAX(start_time) DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, clinicID, patientID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get therapist history: %w", err)
+	}
+	defer rows.Close()
+
+	history := make([]model.TherapistVisitCount, 0)
+	for rows.Next() {
+		var v model.TherapistVisitCount
+		if err := rows.Scan(&v.TherapistID, &v.Visits, &v.LastVisit); err != nil {
+			return nil, fmt.Errorf("failed to scan therapist history: %w", err)
+		}
+		history = append(history, v)
+	}
+
+	return history, rows.Err()
+}
+
+// HasCompletedEvaluation reports whether the patient has completed an
+// evaluation appointment at the clinic: one whose type is marked as an
+// evaluation, or an assessment where the clinic has no such type configured.
+func (r *postgresAppointmentRepo) HasCompletedEvaluation(ctx context.Context, clinicID, patientID string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM appointments a
+			LEFT JOIN appointment_types t ON a.appointment_type_id = t.id
+			WHERE a.clinic_id = $1
+				AND a.patient_id = $2
+				AND a.status = 'completed'
+				AND (t.is_evaluation OR (t.id IS NULL AND a.type = 'assessment'))
+		)`
+
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, query, clinicID, patientID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check evaluation: %w", err)
+	}
+	return exists, nil
+}
+
+// GetAttendance counts the no-shows and late cancellations of the given
+// patients for appointments starting at or after since. A cancellation is
+// late when it was made less than lateNotice before the appointment; places
+// in a group session the clinic cancelled do not count.
+func (r *postgresAppointmentRepo) GetAttendance(ctx context.Context, clinicID string, patientIDs []string, since time.Time, lateNotice time.Duration) (map[string]model.PatientAttendance, error) {
+	attendance := make(map[string]model.PatientAttendance, len(patientIDs))
+	if len(patientIDs) == 0 {
+		return attendance, nil
+	}
+
+	query := `
+		SELECT
+			a.patient_id,
+			COUNT(*) FILTER (WHERE a.status = 'no_show'),
+			COUNT(*) FILTER (
+				WHERE a.status = 'cancelled'
+					AND a.cancelled_at > a.start_time - make_interval(secs => $4)
+					AND (gs.id IS NULL OR gs.status <> 'cancelled')
+			),
+			MAX(a.start_time) FILTER (WHERE a.status = 'no_show')
+		FROM appointments a
+		LEFT JOIN group_sessions gs ON a.group_session_id = gs.id
+		WHERE a.clinic_id = $1
+			AND a.patient_id = ANY($2)
+			AND a.start_time >= $3
+			AND a.status IN ('no_show', 'cancelled')
+		GROUP BY a.patient_id`
+
+	rows, err := r.db.QueryContext(ctx, query, clinicID, pq.Array(patientIDs), since, lateNotice.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attendance: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var a model.PatientAttendance
+		var lastNoShow sql.NullTime
+		if err := rows.Scan(&a.PatientID, &a.NoShowCount, &a.LateCancelCount, &lastNoShow); err != nil {
+			return nil, fmt.Errorf("failed to scan attendance: %w", err)
+		}
+		if lastNoShow.Valid {
+			a.LastNoShowAt = &lastNoShow.Time
+		}
+		attendance[a.PatientID] = a
+	}
+
+	return attendance, rows.Err()
+}
+
+// MarkNoShows marks scheduled and confirmed appointments as no-shows once
+// their clinic's grace period after the start has passed without a check-in,
+// and returns the appointments it marked. An appointment whose treatment
+// session has been checked in, started or completed is never marked: the
+// patient arrived even if the appointment status was not moved on. Clinics
+// without a configured grace period use defaultGrace. Group places are left
+// to the roster, where attendance is taken.
+func (r *postgresAppointmentRepo) MarkNoShows(ctx context.Context, defaultGrace time.Duration) ([]model.Appointment, error) {
+	query := `
+		UPDATE appointments a
+		SET status = 'no_show'
+		FROM clinics c
+		WHERE c.id = a.clinic_id
+			AND a.status IN ('scheduled', 'confirmed')
+			AND a.group_session_id IS NULL
+			AND a.start_time < NOW() - make_interval(mins => COALESCE((c.settings->'no_show_policy'->>'grace_period_minutes')::int, $1))
+			AND NOT EXISTS (SELECT 1 FROM appointment_check_ins ci WHERE ci.appointment_id = a.id)
+			AND NOT EXISTS (
+				SELECT 1 FROM treatment_sessions ts
+				WHERE ts.appointment_id = a.id
+					AND ts.status IN ('checked_in', 'in_progress', 'completed')
+			)
+		RETURNING a.id, a.clinic_id, a.patient_id, a.therapist_id, a.status`
+
+	rows, err := r.db.QueryContext(ctx, query, int(defaultGrace.Minutes()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark no-shows: %w", err)
+	}
+	defer rows.Close()
+
+	marked := make([]model.Appointment, 0)
+	for rows.Next() {
+		var a model.Appointment
+		if err := rows.Scan(&a.ID, &a.ClinicID, &a.PatientID, &a.TherapistID, &a.Status); err != nil {
+			return nil, fmt.Errorf("failed to scan no-show: %w", err)
+		}
+		marked = append(marked, a)
+	}
+	return marked, rows.Err()
+}
+
 // getTherapistsBasic is a fallback for getting therapists without role filtering.
 func (r *postgresAppointmentRepo) getTherapistsBasic(ctx context.Context, clinicID string) ([]model.Therapist, error) {
 	query := `
-		SELECT id, first_name, last_name, email
+		SELECT id, first_name, last_name, email, COALESCE(specializations, '{}')
 		FROM users
 		WHERE clinic_id = $1 AND active = true
 		ORDER BY last_name, first_name`
@@ -833,7 +1606,7 @@ func (r *postgresAppointmentRepo) getTherapistsBasic(ctx context.Context, clinic
 		var t model.Therapist
 		var email sql.NullString
 
-		err := rows.Scan(&t.ID, &t.FirstName, &t.LastName, &email)
+		err := rows.Scan(&t.ID, &t.FirstName, &t.LastName, &email, pq.Array(&t.Specializations))
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan therapist: %w", err)
 		}
@@ -885,6 +1658,10 @@ func (r *mockAppointmentRepo) GetByTherapist(ctx context.Context, clinicID, ther
 	return []model.AppointmentWithDetails{}, nil
 }
 
+func (r *mockAppointmentRepo) GetCalendar(ctx context.Context, clinicID string, kind model.CalendarFeedKind, ownerID string, start, end time.Time) ([]model.AppointmentWithDetails, error) {
+	return []model.AppointmentWithDetails{}, nil
+}
+
 func (r *mockAppointmentRepo) GetDaySchedule(ctx context.Context, clinicID string, date time.Time) (*model.DaySchedule, error) {
 	return &model.DaySchedule{
 		Date:         date.Format("2006-01-02"),
@@ -897,6 +1674,10 @@ func (r *mockAppointmentRepo) FindConflicts(ctx context.Context, clinicID, thera
 	return []model.Appointment{}, nil
 }
 
+func (r *mockAppointmentRepo) FindResourceBookings(ctx context.Context, clinicID string, resourceIDs []string, start, end time.Time, excludeIDs []string) ([]model.Appointment, error) {
+	return []model.Appointment{}, nil
+}
+
 func (r *mockAppointmentRepo) GetTherapistSchedule(ctx context.Context, clinicID, therapistID string) ([]model.TherapistSchedule, error) {
 	return []model.TherapistSchedule{}, nil
 }
@@ -905,14 +1686,56 @@ func (r *mockAppointmentRepo) GetScheduleExceptions(ctx context.Context, clinicI
 	return []model.ScheduleException{}, nil
 }
 
-func (r *mockAppointmentRepo) GetAvailableSlots(ctx context.Context, clinicID, therapistID string, date time.Time, duration int) ([]model.AvailabilitySlot, error) {
+func (r *mockAppointmentRepo) GetAvailableSlots(ctx context.Context, clinicID, therapistID string, date time.Time, duration int, resourceIDs []string) ([]model.AvailabilitySlot, error) {
 	return []model.AvailabilitySlot{}, nil
 }
 
+func (r *mockAppointmentRepo) FindAvailableSlots(ctx context.Context, clinicID string, therapistIDs []string, from, to time.Time, duration int, resourceIDs []string) ([]model.AvailabilitySlot, error) {
+	return []model.AvailabilitySlot{}, nil
+}
+
+func (r *mockAppointmentRepo) GetTherapistHistory(ctx context.Context, clinicID, patientID string, since time.Time) ([]model.TherapistVisitCount, error) {
+	return []model.TherapistVisitCount{}, nil
+}
+
+func (r *mockAppointmentRepo) HasCompletedEvaluation(ctx context.Context, clinicID, patientID string) (bool, error) {
+	return false, nil
+}
+
+func (r *mockAppointmentRepo) GetAttendance(ctx context.Context, clinicID string, patientIDs []string, since time.Time, lateNotice time.Duration) (map[string]model.PatientAttendance, error) {
+	return map[string]model.PatientAttendance{}, nil
+}
+
+func (r *mockAppointmentRepo) MarkNoShows(ctx context.Context, defaultGrace time.Duration) ([]model.Appointment, error) {
+	return []model.Appointment{}, nil
+}
+
 func (r *mockAppointmentRepo) CancelByRecurrenceID(ctx context.Context, clinicID, recurrenceID string, reason string, fromDate time.Time) error {
 	return nil
 }
 
+func (r *mockAppointmentRepo) CreateSeries(ctx context.Context, series *model.RecurrenceSeries, appointments []*model.Appointment) error {
+	series.CreatedAt = time.Now()
+	series.UpdatedAt = time.Now()
+	for _, a := range appointments {
+		a.CreatedAt = series.CreatedAt
+		a.UpdatedAt = series.CreatedAt
+	}
+	return nil
+}
+
+func (r *mockAppointmentRepo) GetSeries(ctx context.Context, clinicID, id string) (*model.RecurrenceSeries, error) {
+	return nil, ErrNotFound
+}
+
+func (r *mockAppointmentRepo) ApplySeriesEdit(ctx context.Context, edit *model.SeriesEdit) error {
+	return nil
+}
+
+func (r *mockAppointmentRepo) ListBySeries(ctx context.Context, clinicID, seriesID string) ([]model.AppointmentWithDetails, error) {
+	return []model.AppointmentWithDetails{}, nil
+}
+
 func (r *mockAppointmentRepo) CountByClinic(ctx context.Context, clinicID string) (int64, error) {
 	return 0, nil
 }
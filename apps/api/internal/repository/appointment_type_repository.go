@@ -0,0 +1,263 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+)
+
+// AppointmentTypeRepository defines the interface for a clinic's configured
+// appointment types.
+type AppointmentTypeRepository interface {
+	List(ctx context.Context, clinicID string, activeOnly bool) ([]model.AppointmentTypeConfig, error)
+	GetByID(ctx context.Context, clinicID, id string) (*model.AppointmentTypeConfig, error)
+	GetByCode(ctx context.Context, clinicID, code string) (*model.AppointmentTypeConfig, error)
+	Create(ctx context.Context, t *model.AppointmentTypeConfig) error
+	Update(ctx context.Context, t *model.AppointmentTypeConfig) error
+	Delete(ctx context.Context, clinicID, id string) error
+}
+
+// postgresAppointmentTypeRepo implements AppointmentTypeRepository with PostgreSQL.
+type postgresAppointmentTypeRepo struct {
+	db *DB
+}
+
+// NewAppointmentTypeRepository creates a new PostgreSQL appointment type repository.
+func NewAppointmentTypeRepository(db *DB) AppointmentTypeRepository {
+	return &postgresAppointmentTypeRepo{db: db}
+}
+
+// appointmentTypeColumns lists the columns read by scanAppointmentType.
+const appointmentTypeColumns = `
+	id, clinic_id, name, name_vi, code, description, description_vi,
+	default_duration_minutes, color, icon, requires_evaluation, is_evaluation,
+	allows_group, COALESCE(max_group_size, 1), COALESCE(default_billing_codes, '{}'),
+	is_active, sort_order, created_at, updated_at`
+
+// List retrieves a clinic's appointment types in display order.
+func (r *postgresAppointmentTypeRepo) List(ctx context.Context, clinicID string, activeOnly bool) ([]model.AppointmentTypeConfig, error) {
+	query := `
+		SELECT ` + appointmentTypeColumns + `
+		FROM appointment_types
+		WHERE clinic_id = $1 AND (NOT $2 OR is_active = TRUE)
+		ORDER BY sort_order, name`
+
+	rows, err := r.db.QueryContext(ctx, query, clinicID, activeOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list appointment types: %w", err)
+	}
+	defer rows.Close()
+
+	types := make([]model.AppointmentTypeConfig, 0)
+	for rows.Next() {
+		t, err := scanAppointmentType(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan appointment type: %w", err)
+		}
+		types = append(types, *t)
+	}
+
+	return types, rows.Err()
+}
+
+// GetByID retrieves an appointment type by ID.
+func (r *postgresAppointmentTypeRepo) GetByID(ctx context.Context, clinicID, id string) (*model.AppointmentTypeConfig, error) {
+	query := `SELECT ` + appointmentTypeColumns + ` FROM appointment_types WHERE id = $1 AND clinic_id = $2`
+
+	t, err := scanAppointmentType(r.db.QueryRowContext(ctx, query, id, clinicID))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get appointment type: %w", err)
+	}
+	return t, nil
+}
+
+// GetByCode retrieves a clinic's appointment type by its code.
+func (r *postgresAppointmentTypeRepo) GetByCode(ctx context.Context, clinicID, code string) (*model.AppointmentTypeConfig, error) {
+	query := `SELECT ` + appointmentTypeColumns + ` FROM appointment_types WHERE clinic_id = $1 AND code = $2`
+
+	t, err := scanAppointmentType(r.db.QueryRowContext(ctx, query, clinicID, code))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get appointment type: %w", err)
+	}
+	return t, nil
+}
+
+// Create inserts an appointment type.
+func (r *postgresAppointmentTypeRepo) Create(ctx context.Context, t *model.AppointmentTypeConfig) error {
+	query := `
+		INSERT INTO appointment_types (
+			id, clinic_id, name, name_vi, code, description, description_vi,
+			default_duration_minutes, color, icon, requires_evaluation, is_evaluation,
+			allows_group, max_group_size, default_billing_codes, is_active, sort_order
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+		RETURNING created_at, updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		t.ID,
+		t.ClinicID,
+		t.Name,
+		NullableStringValue(t.NameVi),
+		t.Code,
+		NullableStringValue(t.Description),
+		NullableStringValue(t.DescriptionVi),
+		t.DefaultDuration,
+		NullableStringValue(t.Color),
+		NullableStringValue(t.Icon),
+		t.RequiresEvaluation,
+		t.IsEvaluation,
+		t.AllowsGroup,
+		t.MaxGroupSize,
+		pq.Array(nonNilStrings(t.DefaultBillingCodes)),
+		t.IsActive,
+		t.SortOrder,
+	).Scan(&t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return ErrAlreadyExists
+		}
+		return fmt.Errorf("failed to create appointment type: %w", err)
+	}
+
+	return nil
+}
+
+// Update updates an appointment type. The code is not changed.
+func (r *postgresAppointmentTypeRepo) Update(ctx context.Context, t *model.AppointmentTypeConfig) error {
+	query := `
+		UPDATE appointment_types SET
+			name = $1, name_vi = $2, description = $3, description_vi = $4,
+			default_duration_minutes = $5, color = $6, icon = $7,
+			requires_evaluation = $8, is_evaluation = $9, allows_group = $10,
+			max_group_size = $11, default_billing_codes = $12, is_active = $13,
+			sort_order = $14
+		WHERE id = $15 AND clinic_id = $16
+		RETURNING updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		t.Name,
+		NullableStringValue(t.NameVi),
+		NullableStringValue(t.Description),
+		NullableStringValue(t.DescriptionVi),
+		t.DefaultDuration,
+		NullableStringValue(t.Color),
+		NullableStringValue(t.Icon),
+		t.RequiresEvaluation,
+		t.IsEvaluation,
+		t.AllowsGroup,
+		t.MaxGroupSize,
+		pq.Array(nonNilStrings(t.DefaultBillingCodes)),
+		t.IsActive,
+		t.SortOrder,
+		t.ID,
+		t.ClinicID,
+	).Scan(&t.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update appointment type: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes an appointment type that no appointment or group session uses.
+func (r *postgresAppointmentTypeRepo) Delete(ctx context.Context, clinicID, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM appointment_types WHERE id = $1 AND clinic_id = $2`, id, clinicID)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			return fmt.Errorf("%w: appointment type is in use; deactivate it instead", ErrInvalidInput)
+		}
+		return fmt.Errorf("failed to delete appointment type: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// scanAppointmentType scans a row selected with appointmentTypeColumns.
+func scanAppointmentType(row rowScanner) (*model.AppointmentTypeConfig, error) {
+	var t model.AppointmentTypeConfig
+	var nameVi, description, descriptionVi, color, icon sql.NullString
+
+	err := row.Scan(
+		&t.ID,
+		&t.ClinicID,
+		&t.Name,
+		&nameVi,
+		&t.Code,
+		&description,
+		&descriptionVi,
+		&t.DefaultDuration,
+		&color,
+		&icon,
+		&t.RequiresEvaluation,
+		&t.IsEvaluation,
+		&t.AllowsGroup,
+		&t.MaxGroupSize,
+		pq.Array(&t.DefaultBillingCodes),
+		&t.IsActive,
+		&t.SortOrder,
+		&t.CreatedAt,
+		&t.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	t.NameVi = StringFromNull(nameVi)
+	t.Description = StringFromNull(description)
+	t.DescriptionVi = StringFromNull(descriptionVi)
+	t.Color = StringFromNull(color)
+	t.Icon = StringFromNull(icon)
+
+	return &t, nil
+}
+
+// mockAppointmentTypeRepo provides a mock implementation for development. It
+// has no configured types, so the built-in types apply.
+type mockAppointmentTypeRepo struct{}
+
+func (r *mockAppointmentTypeRepo) List(ctx context.Context, clinicID string, activeOnly bool) ([]model.AppointmentTypeConfig, error) {
+	return []model.AppointmentTypeConfig{}, nil
+}
+
+func (r *mockAppointmentTypeRepo) GetByID(ctx context.Context, clinicID, id string) (*model.AppointmentTypeConfig, error) {
+	return nil, ErrNotFound
+}
+
+func (r *mockAppointmentTypeRepo) GetByCode(ctx context.Context, clinicID, code string) (*model.AppointmentTypeConfig, error) {
+	return nil, ErrNotFound
+}
+
+func (r *mockAppointmentTypeRepo) Create(ctx context.Context, t *model.AppointmentTypeConfig) error {
+	t.CreatedAt = time.Now()
+	t.UpdatedAt = t.CreatedAt
+	return nil
+}
+
+func (r *mockAppointmentTypeRepo) Update(ctx context.Context, t *model.AppointmentTypeConfig) error {
+	return ErrNotFound
+}
+
+func (r *mockAppointmentTypeRepo) Delete(ctx context.Context, clinicID, id string) error {
+	return ErrNotFound
+}
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+)
+
+// AttachmentRepository defines the interface for patient attachment metadata access.
+type AttachmentRepository interface {
+	Create(ctx context.Context, attachment *model.PatientAttachment) error
+	GetByID(ctx context.Context, clinicID, id string) (*model.PatientAttachment, error)
+	ListByPatient(ctx context.Context, clinicID, patientID string) ([]model.PatientAttachment, error)
+	Delete(ctx context.Context, clinicID, id string, at time.Time) error
+}
+
+// postgresAttachmentRepo implements AttachmentRepository with PostgreSQL.
+type postgresAttachmentRepo struct {
+	db *DB
+}
+
+// NewAttachmentRepository creates a new PostgreSQL attachment repository.
+func NewAttachmentRepository(db *DB) AttachmentRepository {
+	return &postgresAttachmentRepo{db: db}
+}
+
+// attachmentColumns lists the columns read by scanAttachment.
+const attachmentColumns = `
+	id, clinic_id, patient_id, category, file_name, content_type, size_bytes,
+	sha256, blob_key, description, uploaded_by, created_at, deleted_at`
+
+// Create inserts attachment metadata. The blob must already be stored.
+func (r *postgresAttachmentRepo) Create(ctx context.Context, attachment *model.PatientAttachment) error {
+	query := `
+		INSERT INTO patient_attachments (
+			id, clinic_id, patient_id, category, file_name, content_type,
+			size_bytes, sha256, blob_key, description, uploaded_by
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+		)
+		RETURNING created_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		attachment.ID,
+		attachment.ClinicID,
+		attachment.PatientID,
+		attachment.Category,
+		attachment.FileName,
+		attachment.ContentType,
+		attachment.SizeBytes,
+		attachment.SHA256,
+		attachment.BlobKey,
+		NullableStringValue(attachment.Description),
+		NullableString(attachment.UploadedBy),
+	).Scan(&attachment.CreatedAt)
+
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			return fmt.Errorf("%w: patient or clinic does not exist", ErrInvalidInput)
+		}
+		return fmt.Errorf("failed to create attachment: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves an attachment that has not been deleted.
+func (r *postgresAttachmentRepo) GetByID(ctx context.Context, clinicID, id string) (*model.PatientAttachment, error) {
+	query := `
+		SELECT ` + attachmentColumns + `
+		FROM patient_attachments
+		WHERE id = $1 AND clinic_id = $2 AND deleted_at IS NULL`
+
+	attachment, err := scanAttachment(r.db.QueryRowContext(ctx, query, id, clinicID))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attachment: %w", err)
+	}
+	return attachment, nil
+}
+
+// ListByPatient retrieves a patient's attachments, newest first.
+func (r *postgresAttachmentRepo) ListByPatient(ctx context.Context, clinicID, patientID string) ([]model.PatientAttachment, error) {
+	query := `
+		SELECT ` + attachmentColumns + `
+		FROM patient_attachments
+		WHERE patient_id = $1 AND clinic_id = $2 AND deleted_at IS NULL
+		ORDER BY created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, patientID, clinicID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list attachments: %w", err)
+	}
+	defer rows.Close()
+
+	attachments := []model.PatientAttachment{}
+	for rows.Next() {
+		attachment, err := scanAttachment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan attachment: %w", err)
+		}
+		attachments = append(attachments, *attachment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate attachments: %w", err)
+	}
+
+	return attachments, nil
+}
+
+// Delete soft-deletes an attachment.
+func (r *postgresAttachmentRepo) Delete(ctx context.Context, clinicID, id string, at time.Time) error {
+	query := `
+		UPDATE patient_attachments
+		SET deleted_at = $3
+		WHERE id = $1 AND clinic_id = $2 AND deleted_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, id, clinicID, at)
+	if err != nil {
+		return fmt.Errorf("failed to delete attachment: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// scanAttachment scans a row selected with attachmentColumns.
+func scanAttachment(row rowScanner) (*model.PatientAttachment, error) {
+	var a model.PatientAttachment
+	var description, uploadedBy sql.NullString
+	var deletedAt sql.NullTime
+
+	err := row.Scan(
+		&a.ID,
+		&a.ClinicID,
+		&a.PatientID,
+		&a.Category,
+		&a.FileName,
+		&a.ContentType,
+		&a.SizeBytes,
+		&a.SHA256,
+		&a.BlobKey,
+		&description,
+		&uploadedBy,
+		&a.CreatedAt,
+		&deletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	a.Description = StringFromNull(description)
+	a.UploadedBy = StringPtrFromNull(uploadedBy)
+	a.DeletedAt = TimePtrFromNull(deletedAt)
+
+	return &a, nil
+}
+
+// mockAttachmentRepo provides a mock implementation for development.
+type mockAttachmentRepo struct{}
+
+func (r *mockAttachmentRepo) Create(ctx context.Context, attachment *model.PatientAttachment) error {
+	attachment.CreatedAt = time.Now()
+	return nil
+}
+
+func (r *mockAttachmentRepo) GetByID(ctx context.Context, clinicID, id string) (*model.PatientAttachment, error) {
+	return nil, ErrNotFound
+}
+
+func (r *mockAttachmentRepo) ListByPatient(ctx context.Context, clinicID, patientID string) ([]model.PatientAttachment, error) {
+	return []model.PatientAttachment{}, nil
+}
+
+func (r *mockAttachmentRepo) Delete(ctx context.Context, clinicID, id string, at time.Time) error {
+	return ErrNotFound
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+)
+
+// AuditRepository defines the interface for audit trail data access.
+type AuditRepository interface {
+	Create(ctx context.Context, entry *model.AuditLog) error
+}
+
+// postgresAuditRepo implements AuditRepository with PostgreSQL.
+type postgresAuditRepo struct {
+	db *DB
+}
+
+// NewAuditRepository creates a new PostgreSQL audit repository.
+func NewAuditRepository(db *DB) AuditRepository {
+	return &postgresAuditRepo{db: db}
+}
+
+// Create appends an entry to the audit trail.
+func (r *postgresAuditRepo) Create(ctx context.Context, entry *model.AuditLog) error {
+	query := `
+		INSERT INTO audit_logs (
+			id, clinic_id, actor_id, patient_id, proxy_grant_id, action,
+			resource_type, resource_id, status_code, metadata, ip_address
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+		)
+		RETURNING created_at`
+
+	metadata := entry.Metadata
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit metadata: %w", err)
+	}
+
+	err = r.db.QueryRowContext(ctx, query,
+		entry.ID,
+		NullableString(entry.ClinicID),
+		entry.ActorID,
+		NullableString(entry.PatientID),
+		NullableString(entry.ProxyGrantID),
+		entry.Action,
+		NullableStringValue(entry.ResourceType),
+		NullableStringValue(entry.ResourceID),
+		entry.StatusCode,
+		metadataJSON,
+		NullableStringValue(entry.IPAddress),
+	).Scan(&entry.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create audit log: %w", err)
+	}
+
+	return nil
+}
+
+// mockAuditRepo writes audit entries to the application log in mock mode.
+type mockAuditRepo struct{}
+
+func (r *mockAuditRepo) Create(ctx context.Context, entry *model.AuditLog) error {
+	log.Info().
+		Str("actor_id", entry.ActorID).
+		Str("action", entry.Action).
+		Str("resource_id", entry.ResourceID).
+		Msg("audit")
+	return nil
+}
@@ -0,0 +1,239 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+)
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("failed to load %s: %v", name, err)
+	}
+	return loc
+}
+
+func TestWorkingPeriodsOnDayBoundaries(t *testing.T) {
+	weekly := func(day time.Weekday, start, end string) model.TherapistSchedule {
+		return model.TherapistSchedule{
+			DayOfWeek:     int(day),
+			StartTime:     start,
+			EndTime:       end,
+			EffectiveFrom: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			IsActive:      true,
+		}
+	}
+
+	tests := []struct {
+		name      string
+		tz        string
+		date      string
+		schedules []model.TherapistSchedule
+		wantStart string // RFC3339 at the clinic
+		wantEnd   string
+		wantHours float64
+	}{
+		{
+			name:      "default hours in Ho Chi Minh City",
+			tz:        "Asia/Ho_Chi_Minh",
+			date:      "2026-03-08",
+			wantStart: "2026-03-08T08:00:00+07:00",
+			wantEnd:   "2026-03-08T17:00:00+07:00",
+			wantHours: 9,
+		},
+		{
+			name:      "default hours on the day New York springs forward",
+			tz:        "America/New_York",
+			date:      "2026-03-08",
+			wantStart: "2026-03-08T08:00:00-04:00",
+			wantEnd:   "2026-03-08T17:00:00-04:00",
+			wantHours: 9,
+		},
+		{
+			name:      "default hours on the day New York falls back",
+			tz:        "America/New_York",
+			date:      "2026-11-01",
+			wantStart: "2026-11-01T08:00:00-05:00",
+			wantEnd:   "2026-11-01T17:00:00-05:00",
+			wantHours: 9,
+		},
+		{
+			name:      "weekly schedule spanning the spring forward gap",
+			tz:        "America/New_York",
+			date:      "2026-03-08",
+			schedules: []model.TherapistSchedule{weekly(time.Sunday, "01:00", "05:00")},
+			wantStart: "2026-03-08T01:00:00-05:00",
+			wantEnd:   "2026-03-08T05:00:00-04:00",
+			wantHours: 3,
+		},
+		{
+			name:      "weekly schedule spanning the repeated hour",
+			tz:        "America/New_York",
+			date:      "2026-11-01",
+			schedules: []model.TherapistSchedule{weekly(time.Sunday, "00:30", "03:00")},
+			wantStart: "2026-11-01T00:30:00-04:00",
+			wantEnd:   "2026-11-01T03:00:00-05:00",
+			wantHours: 3.5,
+		},
+		{
+			name:      "late shift ending at local midnight in London",
+			tz:        "Europe/London",
+			date:      "2026-03-29",
+			schedules: []model.TherapistSchedule{weekly(time.Sunday, "18:00", "23:59")},
+			wantStart: "2026-03-29T18:00:00+01:00",
+			wantEnd:   "2026-03-29T23:59:00+01:00",
+			wantHours: 5 + 59.0/60,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc := mustLoadLocation(t, tt.tz)
+			day, err := time.ParseInLocation(dateOnly, tt.date, loc)
+			if err != nil {
+				t.Fatalf("invalid date: %v", err)
+			}
+
+			periods := workingPeriodsOn(day, tt.schedules, nil)
+			if len(periods) != 1 {
+				t.Fatalf("Expected 1 working period, got %d", len(periods))
+			}
+			p := periods[0]
+			if got := p.Start.Format(time.RFC3339); got != tt.wantStart {
+				t.Errorf("Expected start %s, got %s", tt.wantStart, got)
+			}
+			if got := p.End.Format(time.RFC3339); got != tt.wantEnd {
+				t.Errorf("Expected end %s, got %s", tt.wantEnd, got)
+			}
+			if got := p.End.Sub(p.Start).Hours(); got != tt.wantHours {
+				t.Errorf("Expected %.2f working hours, got %.2f", tt.wantHours, got)
+			}
+		})
+	}
+}
+
+func TestWorkingPeriodsOnFullDayException(t *testing.T) {
+	tests := []struct {
+		name string
+		tz   string
+		date string
+	}{
+		{"Ho Chi Minh City", "Asia/Ho_Chi_Minh", "2026-03-08"},
+		{"23-hour day in New York", "America/New_York", "2026-03-08"},
+		{"25-hour day in New York", "America/New_York", "2026-11-01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc := mustLoadLocation(t, tt.tz)
+			day, _ := time.ParseInLocation(dateOnly, tt.date, loc)
+			// Exception dates are scanned from a DATE column as UTC midnight
+			date, _ := time.Parse(dateOnly, tt.date)
+			exceptions := []model.ScheduleException{{Date: date, Type: model.ExceptionTypeVacation}}
+
+			if periods := workingPeriodsOn(day, nil, exceptions); len(periods) != 0 {
+				t.Errorf("Expected no working periods on a day off, got %v", periods)
+			}
+		})
+	}
+}
+
+func TestFreeSlotsAcrossDays(t *testing.T) {
+	tests := []struct {
+		name      string
+		tz        string
+		from      string
+		days      int
+		wantFirst []string // first slot of each day, RFC3339 at the clinic
+	}{
+		{
+			name: "Ho Chi Minh City",
+			tz:   "Asia/Ho_Chi_Minh",
+			from: "2026-03-07",
+			days: 3,
+			wantFirst: []string{
+				"2026-03-07T08:00:00+07:00",
+				"2026-03-08T08:00:00+07:00",
+				"2026-03-09T08:00:00+07:00",
+			},
+		},
+		{
+			name: "New York across spring forward",
+			tz:   "America/New_York",
+			from: "2026-03-07",
+			days: 3,
+			wantFirst: []string{
+				"2026-03-07T08:00:00-05:00",
+				"2026-03-08T08:00:00-04:00",
+				"2026-03-09T08:00:00-04:00",
+			},
+		},
+		{
+			name: "New York across fall back",
+			tz:   "America/New_York",
+			from: "2026-10-31",
+			days: 3,
+			wantFirst: []string{
+				"2026-10-31T08:00:00-04:00",
+				"2026-11-01T08:00:00-05:00",
+				"2026-11-02T08:00:00-05:00",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc := mustLoadLocation(t, tt.tz)
+			from, _ := time.ParseInLocation(dateOnly, tt.from, loc)
+			to := from.AddDate(0, 0, tt.days)
+
+			var got []string
+			for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+				periods := workingPeriodsOn(day, nil, nil)
+				slots := freeSlots("t1", periods, nil, nil, nil, 30, time.Time{})
+				// 8:00 - 17:00 in 15-minute steps, the last starting at 16:30
+				if len(slots) != 35 {
+					t.Errorf("Expected 35 slots on %s, got %d", day.Format(dateOnly), len(slots))
+				}
+				if len(slots) > 0 {
+					got = append(got, slots[0].StartTime.Format(time.RFC3339))
+				}
+			}
+
+			if len(got) != len(tt.wantFirst) {
+				t.Fatalf("Expected %d days, got %d", len(tt.wantFirst), len(got))
+			}
+			for i := range got {
+				if got[i] != tt.wantFirst[i] {
+					t.Errorf("Day %d: expected first slot %s, got %s", i, tt.wantFirst[i], got[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFreeSlotsBookedAcrossZones(t *testing.T) {
+	loc := mustLoadLocation(t, "America/New_York")
+	day := time.Date(2026, 3, 8, 0, 0, 0, 0, loc)
+	periods := workingPeriodsOn(day, nil, nil)
+
+	// A booking stored in UTC covering 09:00 - 10:00 New York time
+	booked := []model.TimeRange{{
+		Start: time.Date(2026, 3, 8, 13, 0, 0, 0, time.UTC),
+		End:   time.Date(2026, 3, 8, 14, 0, 0, 0, time.UTC),
+	}}
+
+	slots := freeSlots("t1", periods, booked, nil, nil, 60, time.Time{})
+	for _, s := range slots {
+		clock := s.StartTime.In(loc).Format("15:04")
+		if clock > "08:00" && clock < "10:00" {
+			t.Errorf("Expected no slot overlapping the booking, got one at %s", clock)
+		}
+	}
+	if len(slots) == 0 || slots[0].StartTime.Format("15:04") != "08:00" {
+		t.Errorf("Expected the day to start with an 08:00 slot")
+	}
+}
@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+)
+
+// CalendarFeedRepository defines the interface for iCalendar feed token data access.
+type CalendarFeedRepository interface {
+	Issue(ctx context.Context, feed *model.CalendarFeed) error
+	GetByTokenHash(ctx context.Context, kind model.CalendarFeedKind, tokenHash string) (*model.CalendarFeed, error)
+	ListByOwner(ctx context.Context, clinicID string, kind model.CalendarFeedKind, ownerID string) ([]model.CalendarFeed, error)
+	Revoke(ctx context.Context, clinicID string, kind model.CalendarFeedKind, ownerID, id, revokedBy string, at time.Time) error
+	Touch(ctx context.Context, id string, at time.Time) error
+}
+
+// postgresCalendarFeedRepo implements CalendarFeedRepository with PostgreSQL.
+type postgresCalendarFeedRepo struct {
+	db *DB
+}
+
+// NewCalendarFeedRepository creates a new PostgreSQL calendar feed repository.
+func NewCalendarFeedRepository(db *DB) CalendarFeedRepository {
+	return &postgresCalendarFeedRepo{db: db}
+}
+
+// calendarFeedColumns lists the columns read by scanCalendarFeed.
+const calendarFeedColumns = `
+	id, clinic_id, kind, owner_id, token_hash, revoked_at, revoked_by,
+	created_by, last_accessed_at, created_at`
+
+// Issue inserts a new feed, revoking the owner's current feed in the same
+// transaction so that an owner has at most one working feed URL.
+func (r *postgresCalendarFeedRepo) Issue(ctx context.Context, feed *model.CalendarFeed) error {
+	return r.db.WithTx(ctx, func(tx *Tx) error {
+		revoke := `
+			UPDATE calendar_feeds
+			SET revoked_at = NOW(), revoked_by = $4
+			WHERE clinic_id = $1 AND kind = $2 AND owner_id = $3 AND revoked_at IS NULL`
+
+		if _, err := tx.ExecContext(ctx, revoke, feed.ClinicID, feed.Kind, feed.OwnerID, feed.CreatedBy); err != nil {
+			return fmt.Errorf("failed to revoke previous calendar feed: %w", err)
+		}
+
+		insert := `
+			INSERT INTO calendar_feeds (
+				id, clinic_id, kind, owner_id, token_hash, created_by
+			) VALUES (
+				$1, $2, $3, $4, $5, $6
+			)
+			RETURNING created_at`
+
+		err := tx.QueryRowContext(ctx, insert,
+			feed.ID,
+			feed.ClinicID,
+			feed.Kind,
+			feed.OwnerID,
+			feed.TokenHash,
+			feed.CreatedBy,
+		).Scan(&feed.CreatedAt)
+		if err != nil {
+			if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+				return fmt.Errorf("%w: clinic does not exist", ErrInvalidInput)
+			}
+			return fmt.Errorf("failed to create calendar feed: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetByTokenHash retrieves a feed of the given kind by the hash of its token,
+// whether or not it has been revoked.
+func (r *postgresCalendarFeedRepo) GetByTokenHash(ctx context.Context, kind model.CalendarFeedKind, tokenHash string) (*model.CalendarFeed, error) {
+	query := `
+		SELECT ` + calendarFeedColumns + `
+		FROM calendar_feeds
+		WHERE kind = $1 AND token_hash = $2`
+
+	feed, err := scanCalendarFeed(r.db.QueryRowContext(ctx, query, kind, tokenHash))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get calendar feed: %w", err)
+	}
+	return feed, nil
+}
+
+// ListByOwner retrieves an owner's feeds, newest first, including revoked ones.
+func (r *postgresCalendarFeedRepo) ListByOwner(ctx context.Context, clinicID string, kind model.CalendarFeedKind, ownerID string) ([]model.CalendarFeed, error) {
+	query := `
+		SELECT ` + calendarFeedColumns + `
+		FROM calendar_feeds
+		WHERE clinic_id = $1 AND kind = $2 AND owner_id = $3
+		ORDER BY created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, clinicID, kind, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list calendar feeds: %w", err)
+	}
+	defer rows.Close()
+
+	feeds := []model.CalendarFeed{}
+	for rows.Next() {
+		feed, err := scanCalendarFeed(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan calendar feed: %w", err)
+		}
+		feeds = append(feeds, *feed)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate calendar feeds: %w", err)
+	}
+
+	return feeds, nil
+}
+
+// Revoke marks one of an owner's feeds as revoked.
+func (r *postgresCalendarFeedRepo) Revoke(ctx context.Context, clinicID string, kind model.CalendarFeedKind, ownerID, id, revokedBy string, at time.Time) error {
+	query := `
+		UPDATE calendar_feeds
+		SET revoked_at = $5, revoked_by = $6
+		WHERE id = $1 AND clinic_id = $2 AND kind = $3 AND owner_id = $4 AND revoked_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, id, clinicID, kind, ownerID, at, revokedBy)
+	if err != nil {
+		return fmt.Errorf("failed to revoke calendar feed: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// Touch records when a feed was last fetched.
+func (r *postgresCalendarFeedRepo) Touch(ctx context.Context, id string, at time.Time) error {
+	query := `UPDATE calendar_feeds SET last_accessed_at = $2 WHERE id = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, id, at); err != nil {
+		return fmt.Errorf("failed to update calendar feed access time: %w", err)
+	}
+	return nil
+}
+
+// scanCalendarFeed scans a calendar feed row.
+func scanCalendarFeed(row rowScanner) (*model.CalendarFeed, error) {
+	var f model.CalendarFeed
+	var revokedBy sql.NullString
+	var revokedAt, lastAccessedAt sql.NullTime
+
+	err := row.Scan(
+		&f.ID,
+		&f.ClinicID,
+		&f.Kind,
+		&f.OwnerID,
+		&f.TokenHash,
+		&revokedAt,
+		&revokedBy,
+		&f.CreatedBy,
+		&lastAccessedAt,
+		&f.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if revokedAt.Valid {
+		f.RevokedAt = &revokedAt.Time
+	}
+	if revokedBy.Valid {
+		f.RevokedBy = &revokedBy.String
+	}
+	if lastAccessedAt.Valid {
+		f.LastAccessedAt = &lastAccessedAt.Time
+	}
+
+	return &f, nil
+}
+
+// =============================================================================
+// MOCK IMPLEMENTATION
+// =============================================================================
+
+// mockCalendarFeedRepo provides a mock implementation for development.
+type mockCalendarFeedRepo struct{}
+
+func (r *mockCalendarFeedRepo) Issue(ctx context.Context, feed *model.CalendarFeed) error {
+	feed.CreatedAt = time.Now()
+	return nil
+}
+
+func (r *mockCalendarFeedRepo) GetByTokenHash(ctx context.Context, kind model.CalendarFeedKind, tokenHash string) (*model.CalendarFeed, error) {
+	return nil, ErrNotFound
+}
+
+func (r *mockCalendarFeedRepo) ListByOwner(ctx context.Context, clinicID string, kind model.CalendarFeedKind, ownerID string) ([]model.CalendarFeed, error) {
+	return []model.CalendarFeed{}, nil
+}
+
+func (r *mockCalendarFeedRepo) Revoke(ctx context.Context, clinicID string, kind model.CalendarFeedKind, ownerID, id, revokedBy string, at time.Time) error {
+	return ErrNotFound
+}
+
+func (r *mockCalendarFeedRepo) Touch(ctx context.Context, id string, at time.Time) error {
+	return nil
+}
@@ -0,0 +1,425 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+)
+
+// ConsentRepository defines the interface for consent data access.
+type ConsentRepository interface {
+	CreateTemplate(ctx context.Context, tmpl *model.ConsentTemplate) error
+	GetTemplate(ctx context.Context, id string) (*model.ConsentTemplate, error)
+	GetCurrentTemplate(ctx context.Context, clinicID string, consentType model.ConsentType) (*model.ConsentTemplate, error)
+	ListCurrentTemplates(ctx context.Context, clinicID string) ([]model.ConsentTemplate, error)
+	CreateConsent(ctx context.Context, consent *model.PatientConsent) error
+	GetConsent(ctx context.Context, clinicID, id string) (*model.PatientConsent, error)
+	GetActiveConsent(ctx context.Context, clinicID, patientID string, consentType model.ConsentType) (*model.PatientConsent, error)
+	ListByPatient(ctx context.Context, clinicID, patientID string) ([]model.PatientConsent, error)
+	Withdraw(ctx context.Context, clinicID, id, withdrawnBy, reason string, at time.Time) error
+}
+
+// postgresConsentRepo implements ConsentRepository with PostgreSQL.
+type postgresConsentRepo struct {
+	db *DB
+}
+
+// NewConsentRepository creates a new PostgreSQL consent repository.
+func NewConsentRepository(db *DB) ConsentRepository {
+	return &postgresConsentRepo{db: db}
+}
+
+// consentTemplateColumns lists the columns read by scanConsentTemplate.
+const consentTemplateColumns = `
+	id, clinic_id, consent_type, version, is_current,
+	title, title_vi, body, body_vi, created_at, created_by`
+
+// patientConsentColumns lists the columns read by scanPatientConsent.
+const patientConsentColumns = `
+	pc.id, pc.clinic_id, pc.patient_id, pc.template_id, pc.consent_type, pc.template_version,
+	pc.status, pc.signature, pc.signed_by_name, pc.signer_relationship, pc.granted_at,
+	pc.recorded_by, pc.withdrawn_at, pc.withdrawn_by, pc.withdrawal_reason,
+	pc.created_at, pc.updated_at, ct.title, ct.title_vi`
+
+// CreateTemplate publishes a new version of a consent text. The version number
+// is assigned here and the previous version for the same scope stops being current.
+func (r *postgresConsentRepo) CreateTemplate(ctx context.Context, tmpl *model.ConsentTemplate) error {
+	return r.db.WithTx(ctx, func(tx *Tx) error {
+		// Serialize version assignment for this consent type
+		if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('consent_templates:' || $1))`, string(tmpl.ConsentType)); err != nil {
+			return fmt.Errorf("failed to lock consent templates: %w", err)
+		}
+
+		err := tx.QueryRowContext(ctx, `
+			SELECT COALESCE(MAX(version), 0) + 1
+			FROM consent_templates
+			WHERE clinic_id IS NOT DISTINCT FROM $1 AND consent_type = $2`,
+			NullableString(tmpl.ClinicID), tmpl.ConsentType,
+		).Scan(&tmpl.Version)
+		if err != nil {
+			return fmt.Errorf("failed to get next consent version: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE consent_templates SET is_current = FALSE
+			WHERE clinic_id IS NOT DISTINCT FROM $1 AND consent_type = $2 AND is_current = TRUE`,
+			NullableString(tmpl.ClinicID), tmpl.ConsentType,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to retire previous consent version: %w", err)
+		}
+
+		tmpl.IsCurrent = true
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO consent_templates (
+				id, clinic_id, consent_type, version, is_current,
+				title, title_vi, body, body_vi, created_by
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			RETURNING created_at`,
+			tmpl.ID,
+			NullableString(tmpl.ClinicID),
+			tmpl.ConsentType,
+			tmpl.Version,
+			tmpl.IsCurrent,
+			tmpl.Title,
+			tmpl.TitleVi,
+			tmpl.Body,
+			tmpl.BodyVi,
+			NullableString(tmpl.CreatedBy),
+		).Scan(&tmpl.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create consent template: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// GetTemplate retrieves a consent template by ID.
+func (r *postgresConsentRepo) GetTemplate(ctx context.Context, id string) (*model.ConsentTemplate, error) {
+	query := `SELECT ` + consentTemplateColumns + ` FROM consent_templates WHERE id = $1`
+
+	tmpl, err := scanConsentTemplate(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get consent template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// GetCurrentTemplate retrieves the current text for a consent type, preferring
+// the clinic's own version over the global one.
+func (r *postgresConsentRepo) GetCurrentTemplate(ctx context.Context, clinicID string, consentType model.ConsentType) (*model.ConsentTemplate, error) {
+	query := `
+		SELECT ` + consentTemplateColumns + `
+		FROM consent_templates
+		WHERE consent_type = $2 AND is_current = TRUE
+			AND (clinic_id = $1 OR clinic_id IS NULL)
+		ORDER BY clinic_id NULLS LAST
+		LIMIT 1`
+
+	tmpl, err := scanConsentTemplate(r.db.QueryRowContext(ctx, query, clinicID, consentType))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current consent template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// ListCurrentTemplates retrieves the current text for every consent type available to a clinic.
+func (r *postgresConsentRepo) ListCurrentTemplates(ctx context.Context, clinicID string) ([]model.ConsentTemplate, error) {
+	query := `
+		SELECT DISTINCT ON (consent_type) ` + consentTemplateColumns + `
+		FROM consent_templates
+		WHERE is_current = TRUE AND (clinic_id = $1 OR clinic_id IS NULL)
+		ORDER BY consent_type, clinic_id NULLS LAST`
+
+	rows, err := r.db.QueryContext(ctx, query, clinicID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list consent templates: %w", err)
+	}
+	defer rows.Close()
+
+	templates := []model.ConsentTemplate{}
+	for rows.Next() {
+		tmpl, err := scanConsentTemplate(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan consent template: %w", err)
+		}
+		templates = append(templates, *tmpl)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate consent templates: %w", err)
+	}
+
+	return templates, nil
+}
+
+// CreateConsent records a signed consent. Any active consent of the same type
+// for the patient is marked superseded in the same transaction.
+func (r *postgresConsentRepo) CreateConsent(ctx context.Context, consent *model.PatientConsent) error {
+	signatureJSON, err := json.Marshal(consent.Signature)
+	if err != nil {
+		return fmt.Errorf("failed to marshal signature: %w", err)
+	}
+
+	return r.db.WithTx(ctx, func(tx *Tx) error {
+		_, err := tx.ExecContext(ctx, `
+			UPDATE patient_consents SET status = 'superseded'
+			WHERE patient_id = $1 AND consent_type = $2 AND status = 'active'`,
+			consent.PatientID, consent.ConsentType,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to supersede previous consent: %w", err)
+		}
+
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO patient_consents (
+				id, clinic_id, patient_id, template_id, consent_type, template_version,
+				status, signature, signed_by_name, signer_relationship, granted_at, recorded_by
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			RETURNING created_at, updated_at`,
+			consent.ID,
+			consent.ClinicID,
+			consent.PatientID,
+			consent.TemplateID,
+			consent.ConsentType,
+			consent.TemplateVersion,
+			consent.Status,
+			signatureJSON,
+			NullableStringValue(consent.SignedByName),
+			NullableStringValue(consent.SignerRelationship),
+			consent.GrantedAt,
+			consent.RecordedBy,
+		).Scan(&consent.CreatedAt, &consent.UpdatedAt)
+		if err != nil {
+			if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+				return fmt.Errorf("%w: patient or consent template does not exist", ErrInvalidInput)
+			}
+			return fmt.Errorf("failed to create patient consent: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// GetConsent retrieves a patient consent by ID.
+func (r *postgresConsentRepo) GetConsent(ctx context.Context, clinicID, id string) (*model.PatientConsent, error) {
+	query := `
+		SELECT ` + patientConsentColumns + `
+		FROM patient_consents pc
+		JOIN consent_templates ct ON pc.template_id = ct.id
+		WHERE pc.id = $1 AND pc.clinic_id = $2`
+
+	consent, err := scanPatientConsent(r.db.QueryRowContext(ctx, query, id, clinicID))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get patient consent: %w", err)
+	}
+	return consent, nil
+}
+
+// GetActiveConsent retrieves the patient's active consent of a type.
+func (r *postgresConsentRepo) GetActiveConsent(ctx context.Context, clinicID, patientID string, consentType model.ConsentType) (*model.PatientConsent, error) {
+	query := `
+		SELECT ` + patientConsentColumns + `
+		FROM patient_consents pc
+		JOIN consent_templates ct ON pc.template_id = ct.id
+		WHERE pc.patient_id = $1 AND pc.clinic_id = $2
+			AND pc.consent_type = $3 AND pc.status = 'active'`
+
+	consent, err := scanPatientConsent(r.db.QueryRowContext(ctx, query, patientID, clinicID, consentType))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active consent: %w", err)
+	}
+	return consent, nil
+}
+
+// ListByPatient retrieves a patient's full consent history, newest first.
+func (r *postgresConsentRepo) ListByPatient(ctx context.Context, clinicID, patientID string) ([]model.PatientConsent, error) {
+	query := `
+		SELECT ` + patientConsentColumns + `
+		FROM patient_consents pc
+		JOIN consent_templates ct ON pc.template_id = ct.id
+		WHERE pc.patient_id = $1 AND pc.clinic_id = $2
+		ORDER BY pc.granted_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, patientID, clinicID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list patient consents: %w", err)
+	}
+	defer rows.Close()
+
+	consents := []model.PatientConsent{}
+	for rows.Next() {
+		consent, err := scanPatientConsent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan patient consent: %w", err)
+		}
+		consents = append(consents, *consent)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate patient consents: %w", err)
+	}
+
+	return consents, nil
+}
+
+// Withdraw marks an active consent as withdrawn.
+func (r *postgresConsentRepo) Withdraw(ctx context.Context, clinicID, id, withdrawnBy, reason string, at time.Time) error {
+	query := `
+		UPDATE patient_consents
+		SET status = 'withdrawn', withdrawn_at = $3, withdrawn_by = $4, withdrawal_reason = $5
+		WHERE id = $1 AND clinic_id = $2 AND status = 'active'`
+
+	result, err := r.db.ExecContext(ctx, query, id, clinicID, at, withdrawnBy, NullableStringValue(reason))
+	if err != nil {
+		return fmt.Errorf("failed to withdraw consent: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// scanConsentTemplate scans a row selected with consentTemplateColumns.
+func scanConsentTemplate(row rowScanner) (*model.ConsentTemplate, error) {
+	var t model.ConsentTemplate
+	var clinicID, createdBy sql.NullString
+
+	err := row.Scan(
+		&t.ID,
+		&clinicID,
+		&t.ConsentType,
+		&t.Version,
+		&t.IsCurrent,
+		&t.Title,
+		&t.TitleVi,
+		&t.Body,
+		&t.BodyVi,
+		&t.CreatedAt,
+		&createdBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	t.ClinicID = StringPtrFromNull(clinicID)
+	t.CreatedBy = StringPtrFromNull(createdBy)
+
+	return &t, nil
+}
+
+// scanPatientConsent scans a row selected with patientConsentColumns.
+func scanPatientConsent(row rowScanner) (*model.PatientConsent, error) {
+	var c model.PatientConsent
+	var signature []byte
+	var signedByName, signerRelationship, withdrawnBy, withdrawalReason sql.NullString
+	var withdrawnAt sql.NullTime
+
+	err := row.Scan(
+		&c.ID,
+		&c.ClinicID,
+		&c.PatientID,
+		&c.TemplateID,
+		&c.ConsentType,
+		&c.TemplateVersion,
+		&c.Status,
+		&signature,
+		&signedByName,
+		&signerRelationship,
+		&c.GrantedAt,
+		&c.RecordedBy,
+		&withdrawnAt,
+		&withdrawnBy,
+		&withdrawalReason,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+		&c.Title,
+		&c.TitleVi,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(signature) > 0 {
+		if err := json.Unmarshal(signature, &c.Signature); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal signature: %w", err)
+		}
+	}
+	c.SignedByName = StringFromNull(signedByName)
+	c.SignerRelationship = StringFromNull(signerRelationship)
+	c.WithdrawnAt = TimePtrFromNull(withdrawnAt)
+	c.WithdrawnBy = StringPtrFromNull(withdrawnBy)
+	c.WithdrawalReason = StringFromNull(withdrawalReason)
+
+	return &c, nil
+}
+
+// mockConsentRepo provides a mock implementation for development.
+// Every consent is reported as active so that gated features remain usable.
+type mockConsentRepo struct{}
+
+func (r *mockConsentRepo) CreateTemplate(ctx context.Context, tmpl *model.ConsentTemplate) error {
+	return nil
+}
+
+func (r *mockConsentRepo) GetTemplate(ctx context.Context, id string) (*model.ConsentTemplate, error) {
+	return nil, ErrNotFound
+}
+
+func (r *mockConsentRepo) GetCurrentTemplate(ctx context.Context, clinicID string, consentType model.ConsentType) (*model.ConsentTemplate, error) {
+	return nil, ErrNotFound
+}
+
+func (r *mockConsentRepo) ListCurrentTemplates(ctx context.Context, clinicID string) ([]model.ConsentTemplate, error) {
+	return []model.ConsentTemplate{}, nil
+}
+
+func (r *mockConsentRepo) CreateConsent(ctx context.Context, consent *model.PatientConsent) error {
+	return nil
+}
+
+func (r *mockConsentRepo) GetConsent(ctx context.Context, clinicID, id string) (*model.PatientConsent, error) {
+	return nil, ErrNotFound
+}
+
+func (r *mockConsentRepo) GetActiveConsent(ctx context.Context, clinicID, patientID string, consentType model.ConsentType) (*model.PatientConsent, error) {
+	return &model.PatientConsent{
+		ClinicID:    clinicID,
+		PatientID:   patientID,
+		ConsentType: consentType,
+		Status:      model.ConsentStatusActive,
+	}, nil
+}
+
+func (r *mockConsentRepo) ListByPatient(ctx context.Context, clinicID, patientID string) ([]model.PatientConsent, error) {
+	return []model.PatientConsent{}, nil
+}
+
+func (r *mockConsentRepo) Withdraw(ctx context.Context, clinicID, id, withdrawnBy, reason string, at time.Time) error {
+	return ErrNotFound
+}
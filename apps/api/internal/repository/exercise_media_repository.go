@@ -0,0 +1,339 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+)
+
+// ExerciseMediaRepository defines the interface for exercise media metadata access.
+type ExerciseMediaRepository interface {
+	Create(ctx context.Context, media *model.ExerciseMedia) error
+	GetByID(ctx context.Context, exerciseID, id string) (*model.ExerciseMedia, error)
+	FindByID(ctx context.Context, id string) (*model.ExerciseMedia, error)
+	ListByExercise(ctx context.Context, exerciseID string) ([]model.ExerciseMedia, error)
+	ListByExercises(ctx context.Context, exerciseIDs []string) ([]model.ExerciseMedia, error)
+	UpdateCaptions(ctx context.Context, media *model.ExerciseMedia) error
+	Reorder(ctx context.Context, exerciseID string, ids []string) error
+	Delete(ctx context.Context, exerciseID, id string) error
+}
+
+// postgresExerciseMediaRepo implements ExerciseMediaRepository with PostgreSQL.
+type postgresExerciseMediaRepo struct {
+	db *DB
+}
+
+// NewExerciseMediaRepository creates a new PostgreSQL exercise media repository.
+func NewExerciseMediaRepository(db *DB) ExerciseMediaRepository {
+	return &postgresExerciseMediaRepo{db: db}
+}
+
+// exerciseMediaColumns lists the columns read by scanExerciseMedia.
+const exerciseMediaColumns = `
+	id, exercise_id, clinic_id, kind, position, caption, caption_vi,
+	file_name, content_type, size_bytes, sha256, blob_key, thumbnail_key,
+	width, height, duration_seconds, created_by, created_at, updated_at`
+
+// Create inserts media metadata after the exercise's other media. The blobs
+// must already be stored.
+func (r *postgresExerciseMediaRepo) Create(ctx context.Context, media *model.ExerciseMedia) error {
+	query := `
+		INSERT INTO exercise_media (
+			id, exercise_id, clinic_id, kind, position, caption, caption_vi,
+			file_name, content_type, size_bytes, sha256, blob_key, thumbnail_key,
+			width, height, duration_seconds, created_by
+		) VALUES (
+			$1, $2, $3, $4,
+			(SELECT COALESCE(MAX(position), 0) + 1 FROM exercise_media WHERE exercise_id = $2),
+			$5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16
+		)
+		RETURNING position, created_at, updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		media.ID,
+		media.ExerciseID,
+		media.ClinicID,
+		media.Kind,
+		NullableStringValue(media.Caption),
+		NullableStringValue(media.CaptionVi),
+		media.FileName,
+		media.ContentType,
+		media.SizeBytes,
+		media.SHA256,
+		media.BlobKey,
+		NullableStringValue(media.ThumbnailKey),
+		media.Width,
+		media.Height,
+		media.DurationSeconds,
+		NullableString(media.CreatedBy),
+	).Scan(&media.Position, &media.CreatedAt, &media.UpdatedAt)
+
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			return fmt.Errorf("%w: exercise or clinic does not exist", ErrInvalidInput)
+		}
+		return fmt.Errorf("failed to create exercise media: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves one of an exercise's media.
+func (r *postgresExerciseMediaRepo) GetByID(ctx context.Context, exerciseID, id string) (*model.ExerciseMedia, error) {
+	query := `
+		SELECT ` + exerciseMediaColumns + `
+		FROM exercise_media
+		WHERE id = $1 AND exercise_id = $2`
+
+	media, err := scanExerciseMedia(r.db.QueryRowContext(ctx, query, id, exerciseID))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exercise media: %w", err)
+	}
+	return media, nil
+}
+
+// FindByID retrieves media by ID whatever exercise it belongs to, for
+// resolving references in imported exercise libraries.
+func (r *postgresExerciseMediaRepo) FindByID(ctx context.Context, id string) (*model.ExerciseMedia, error) {
+	query := `
+		SELECT ` + exerciseMediaColumns + `
+		FROM exercise_media
+		WHERE id = $1`
+
+	media, err := scanExerciseMedia(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exercise media: %w", err)
+	}
+	return media, nil
+}
+
+// ListByExercise retrieves an exercise's media in display order.
+func (r *postgresExerciseMediaRepo) ListByExercise(ctx context.Context, exerciseID string) ([]model.ExerciseMedia, error) {
+	query := `
+		SELECT ` + exerciseMediaColumns + `
+		FROM exercise_media
+		WHERE exercise_id = $1
+		ORDER BY position, created_at`
+
+	return r.list(ctx, query, exerciseID)
+}
+
+// ListByExercises retrieves the media of several exercises, each in display
+// order.
+func (r *postgresExerciseMediaRepo) ListByExercises(ctx context.Context, exerciseIDs []string) ([]model.ExerciseMedia, error) {
+	if len(exerciseIDs) == 0 {
+		return []model.ExerciseMedia{}, nil
+	}
+
+	query := `
+		SELECT ` + exerciseMediaColumns + `
+		FROM exercise_media
+		WHERE exercise_id = ANY($1)
+		ORDER BY exercise_id, position, created_at`
+
+	return r.list(ctx, query, pq.Array(exerciseIDs))
+}
+
+// UpdateCaptions updates a media file's captions.
+func (r *postgresExerciseMediaRepo) UpdateCaptions(ctx context.Context, media *model.ExerciseMedia) error {
+	query := `
+		UPDATE exercise_media SET
+			caption = $1,
+			caption_vi = $2
+		WHERE id = $3 AND exercise_id = $4
+		RETURNING updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		NullableStringValue(media.Caption),
+		NullableStringValue(media.CaptionVi),
+		media.ID,
+		media.ExerciseID,
+	).Scan(&media.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update exercise media: %w", err)
+	}
+	return nil
+}
+
+// Reorder numbers an exercise's media in the order of ids, which must list
+// all of them.
+func (r *postgresExerciseMediaRepo) Reorder(ctx context.Context, exerciseID string, ids []string) error {
+	return r.db.WithTx(ctx, func(tx *Tx) error {
+		var count int
+		err := tx.QueryRowContext(ctx,
+			`SELECT COUNT(*) FROM exercise_media WHERE exercise_id = $1`, exerciseID,
+		).Scan(&count)
+		if err != nil {
+			return fmt.Errorf("failed to count exercise media: %w", err)
+		}
+		if count != len(ids) {
+			return fmt.Errorf("%w: media_ids must list all %d media of the exercise", ErrInvalidInput, count)
+		}
+
+		now := time.Now()
+		for i, id := range ids {
+			result, err := tx.ExecContext(ctx, `
+				UPDATE exercise_media SET position = $1, updated_at = $2
+				WHERE id = $3 AND exercise_id = $4`,
+				i+1, now, id, exerciseID)
+			if err != nil {
+				return fmt.Errorf("failed to reorder exercise media: %w", err)
+			}
+			n, err := result.RowsAffected()
+			if err != nil {
+				return fmt.Errorf("failed to get rows affected: %w", err)
+			}
+			if n == 0 {
+				return fmt.Errorf("%w: media %s does not belong to the exercise", ErrInvalidInput, id)
+			}
+		}
+		return nil
+	})
+}
+
+// Delete removes one of an exercise's media.
+func (r *postgresExerciseMediaRepo) Delete(ctx context.Context, exerciseID, id string) error {
+	query := `DELETE FROM exercise_media WHERE id = $1 AND exercise_id = $2`
+
+	result, err := r.db.ExecContext(ctx, query, id, exerciseID)
+	if err != nil {
+		return fmt.Errorf("failed to delete exercise media: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// list runs a query returning exerciseMediaColumns.
+func (r *postgresExerciseMediaRepo) list(ctx context.Context, query string, args ...interface{}) ([]model.ExerciseMedia, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list exercise media: %w", err)
+	}
+	defer rows.Close()
+
+	items := []model.ExerciseMedia{}
+	for rows.Next() {
+		media, err := scanExerciseMedia(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan exercise media: %w", err)
+		}
+		items = append(items, *media)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate exercise media: %w", err)
+	}
+
+	return items, nil
+}
+
+// scanExerciseMedia scans a row selected with exerciseMediaColumns.
+func scanExerciseMedia(row rowScanner) (*model.ExerciseMedia, error) {
+	var m model.ExerciseMedia
+	var caption, captionVi, thumbnailKey, createdBy sql.NullString
+	var width, height sql.NullInt64
+	var duration sql.NullFloat64
+
+	err := row.Scan(
+		&m.ID,
+		&m.ExerciseID,
+		&m.ClinicID,
+		&m.Kind,
+		&m.Position,
+		&caption,
+		&captionVi,
+		&m.FileName,
+		&m.ContentType,
+		&m.SizeBytes,
+		&m.SHA256,
+		&m.BlobKey,
+		&thumbnailKey,
+		&width,
+		&height,
+		&duration,
+		&createdBy,
+		&m.CreatedAt,
+		&m.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	m.Caption = StringFromNull(caption)
+	m.CaptionVi = StringFromNull(captionVi)
+	m.ThumbnailKey = StringFromNull(thumbnailKey)
+	m.CreatedBy = StringPtrFromNull(createdBy)
+	if width.Valid {
+		w := int(width.Int64)
+		m.Width = &w
+	}
+	if height.Valid {
+		h := int(height.Int64)
+		m.Height = &h
+	}
+	if duration.Valid {
+		m.DurationSeconds = &duration.Float64
+	}
+
+	return &m, nil
+}
+
+// mockExerciseMediaRepo provides a mock implementation for development.
+type mockExerciseMediaRepo struct{}
+
+func (r *mockExerciseMediaRepo) Create(ctx context.Context, media *model.ExerciseMedia) error {
+	media.Position = 1
+	media.CreatedAt = time.Now()
+	media.UpdatedAt = media.CreatedAt
+	return nil
+}
+
+func (r *mockExerciseMediaRepo) GetByID(ctx context.Context, exerciseID, id string) (*model.ExerciseMedia, error) {
+	return nil, ErrNotFound
+}
+
+func (r *mockExerciseMediaRepo) FindByID(ctx context.Context, id string) (*model.ExerciseMedia, error) {
+	return nil, ErrNotFound
+}
+
+func (r *mockExerciseMediaRepo) ListByExercises(ctx context.Context, exerciseIDs []string) ([]model.ExerciseMedia, error) {
+	return []model.ExerciseMedia{}, nil
+}
+
+func (r *mockExerciseMediaRepo) ListByExercise(ctx context.Context, exerciseID string) ([]model.ExerciseMedia, error) {
+	return []model.ExerciseMedia{}, nil
+}
+
+func (r *mockExerciseMediaRepo) UpdateCaptions(ctx context.Context, media *model.ExerciseMedia) error {
+	return ErrNotFound
+}
+
+func (r *mockExerciseMediaRepo) Reorder(ctx context.Context, exerciseID string, ids []string) error {
+	return nil
+}
+
+func (r *mockExerciseMediaRepo) Delete(ctx context.Context, exerciseID, id string) error {
+	return ErrNotFound
+}
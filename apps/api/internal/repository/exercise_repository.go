@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -22,6 +23,13 @@ type ExerciseRepository interface {
 	List(ctx context.Context, params model.ExerciseSearchParams) ([]model.Exercise, int64, error)
 	Search(ctx context.Context, clinicID, query string, limit int) ([]model.Exercise, error)
 
+	// Library import, export and sharing
+	ListLibrary(ctx context.Context, clinicID string, includeGlobal bool) ([]model.Exercise, error)
+	FindByNames(ctx context.Context, clinicID string, names []string) ([]model.Exercise, error)
+
+	// Prescribing history for recommendations
+	CountPrescriptionsByDiagnosis(ctx context.Context, clinicID, diagnosisCode string) (map[string]int, error)
+
 	// Prescriptions
 	CreatePrescription(ctx context.Context, prescription *model.ExercisePrescription) error
 	GetPrescriptionByID(ctx context.Context, id string) (*model.ExercisePrescription, error)
@@ -35,6 +43,10 @@ type ExerciseRepository interface {
 	UpdateProgram(ctx context.Context, program *model.HomeExerciseProgram) error
 	ListPatientPrograms(ctx context.Context, patientID string) ([]model.HomeExerciseProgram, error)
 
+	// Frequency schedules for prescriptions and programs stored as free text
+	ListUnscheduledFrequencies(ctx context.Context) ([]string, error)
+	SetFrequencySchedule(ctx context.Context, frequency string, schedule *model.FrequencySchedule) (int64, error)
+
 	// Compliance tracking
 	LogCompliance(ctx context.Context, log *model.ExerciseComplianceLog) error
 	GetComplianceLogs(ctx context.Context, prescriptionID string, limit int) ([]model.ExerciseComplianceLog, error)
@@ -492,19 +504,142 @@ func (r *postgresExerciseRepo) Search(ctx context.Context, clinicID, query strin
 	return exercises, nil
 }
 
+// ListLibrary returns the clinic's active exercises, and optionally the
+// global library, ordered by name.
+func (r *postgresExerciseRepo) ListLibrary(ctx context.Context, clinicID string, includeGlobal bool) ([]model.Exercise, error) {
+	query := `
+		SELECT
+			id, clinic_id, name, name_vi, description, description_vi,
+			instructions, instructions_vi, category, difficulty,
+			equipment, muscle_groups, image_url, video_url, thumbnail_url,
+			default_sets, default_reps, default_hold_secs, default_duration_mins,
+			precautions, precautions_vi, is_global, is_active,
+			created_at, updated_at, created_by
+		FROM exercises
+		WHERE (clinic_id = $1 OR ($2 AND (is_global = true OR clinic_id IS NULL)))
+			AND is_active = true
+		ORDER BY name ASC, id`
+
+	return r.listExercises(ctx, query, clinicID, includeGlobal)
+}
+
+// FindByNames returns the active exercises visible to the clinic whose
+// English or Vietnamese name matches one of names, ignoring case.
+func (r *postgresExerciseRepo) FindByNames(ctx context.Context, clinicID string, names []string) ([]model.Exercise, error) {
+	if len(names) == 0 {
+		return []model.Exercise{}, nil
+	}
+
+	lowered := make([]string, len(names))
+	for i, name := range names {
+		lowered[i] = strings.ToLower(strings.TrimSpace(name))
+	}
+
+	query := `
+		SELECT
+			id, clinic_id, name, name_vi, description, description_vi,
+			instructions, instructions_vi, category, difficulty,
+			equipment, muscle_groups, image_url, video_url, thumbnail_url,
+			default_sets, default_reps, default_hold_secs, default_duration_mins,
+			precautions, precautions_vi, is_global, is_active,
+			created_at, updated_at, created_by
+		FROM exercises
+		WHERE (is_global = true OR clinic_id = $1 OR clinic_id IS NULL)
+			AND is_active = true
+			AND (LOWER(TRIM(name)) = ANY($2) OR LOWER(TRIM(name_vi)) = ANY($2))
+		ORDER BY (clinic_id = $1) DESC, name ASC`
+
+	return r.listExercises(ctx, query, clinicID, pq.Array(lowered))
+}
+
+// CountPrescriptionsByDiagnosis counts how often the clinic has prescribed
+// each exercise to patients assessed with the diagnosis code, or one of its
+// subcodes, keyed by exercise ID.
+func (r *postgresExerciseRepo) CountPrescriptionsByDiagnosis(ctx context.Context, clinicID, diagnosisCode string) (map[string]int, error) {
+	query := `
+		SELECT p.exercise_id, COUNT(*)
+		FROM exercise_prescriptions p
+		WHERE p.clinic_id = $1
+			AND EXISTS (
+				SELECT 1
+				FROM assessments a
+				JOIN diagnoses d ON d.id = a.primary_diagnosis_id
+				WHERE a.patient_id = p.patient_id
+					AND a.clinic_id = $1
+					AND (UPPER(d.code) = $2 OR UPPER(d.code) LIKE $2 || '.%')
+			)
+		GROUP BY p.exercise_id`
+
+	rows, err := r.db.QueryContext(ctx, query, clinicID, strings.ToUpper(strings.TrimSpace(diagnosisCode)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to count prescriptions by diagnosis: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var exerciseID string
+		var count int
+		if err := rows.Scan(&exerciseID, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan prescription count: %w", err)
+		}
+		counts[exerciseID] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating prescription counts: %w", err)
+	}
+
+	return counts, nil
+}
+
+// listExercises runs a query returning full exercise rows.
+func (r *postgresExerciseRepo) listExercises(ctx context.Context, query string, args ...interface{}) ([]model.Exercise, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list exercises: %w", err)
+	}
+	defer rows.Close()
+
+	exercises := make([]model.Exercise, 0)
+	for rows.Next() {
+		e, err := r.scanExerciseRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		exercises = append(exercises, *e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating exercises: %w", err)
+	}
+
+	return exercises, nil
+}
+
 // CreatePrescription creates a new exercise prescription.
 func (r *postgresExerciseRepo) CreatePrescription(ctx context.Context, prescription *model.ExercisePrescription) error {
+	return insertPrescription(ctx, r.db, prescription)
+}
+
+// insertPrescription inserts a prescription with q, which may be a transaction.
+func insertPrescription(ctx context.Context, q Querier, prescription *model.ExercisePrescription) error {
+	schedule, err := encodeFrequencySchedule(prescription.FrequencySchedule)
+	if err != nil {
+		return err
+	}
+
 	query := `
 		INSERT INTO exercise_prescriptions (
 			id, patient_id, exercise_id, clinic_id, prescribed_by, program_id,
-			sets, reps, hold_seconds, frequency, duration_weeks,
+			sets, reps, hold_seconds, frequency, frequency_schedule, duration_weeks,
 			custom_instructions, notes, status, start_date, end_date
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17
 		)
 		RETURNING created_at, updated_at`
 
-	err := r.db.QueryRowContext(ctx, query,
+	err = q.QueryRowContext(ctx, query,
 		prescription.ID,
 		prescription.PatientID,
 		prescription.ExerciseID,
@@ -515,6 +650,7 @@ func (r *postgresExerciseRepo) CreatePrescription(ctx context.Context, prescript
 		prescription.Reps,
 		prescription.HoldSeconds,
 		prescription.Frequency,
+		schedule,
 		prescription.DurationWeeks,
 		NullableStringValue(prescription.CustomInstructions),
 		NullableStringValue(prescription.Notes),
@@ -535,7 +671,7 @@ func (r *postgresExerciseRepo) GetPrescriptionByID(ctx context.Context, id strin
 	query := `
 		SELECT
 			p.id, p.patient_id, p.exercise_id, p.clinic_id, p.prescribed_by, p.program_id,
-			p.sets, p.reps, p.hold_seconds, p.frequency, p.duration_weeks,
+			p.sets, p.reps, p.hold_seconds, p.frequency, p.frequency_schedule, p.duration_weeks,
 			p.custom_instructions, p.notes, p.status, p.start_date, p.end_date,
 			p.created_at, p.updated_at,
 			e.id, e.name, e.name_vi, e.description, e.description_vi,
@@ -549,6 +685,7 @@ func (r *postgresExerciseRepo) GetPrescriptionByID(ctx context.Context, id strin
 	var e model.Exercise
 	var programID, customInstructions, notes sql.NullString
 	var endDate sql.NullTime
+	var schedule []byte
 	var imageURL, videoURL sql.NullString
 	var equipment []string
 	var muscleGroups []string
@@ -564,6 +701,7 @@ func (r *postgresExerciseRepo) GetPrescriptionByID(ctx context.Context, id strin
 		&p.Reps,
 		&p.HoldSeconds,
 		&p.Frequency,
+		&schedule,
 		&p.DurationWeeks,
 		&customInstructions,
 		&notes,
@@ -598,6 +736,9 @@ func (r *postgresExerciseRepo) GetPrescriptionByID(ctx context.Context, id strin
 	p.CustomInstructions = StringFromNull(customInstructions)
 	p.Notes = StringFromNull(notes)
 	p.EndDate = TimePtrFromNull(endDate)
+	if p.FrequencySchedule, err = decodeFrequencySchedule(schedule); err != nil {
+		return nil, err
+	}
 
 	e.Equipment = equipment
 	e.MuscleGroups = make([]model.MuscleGroup, len(muscleGroups))
@@ -614,18 +755,24 @@ func (r *postgresExerciseRepo) GetPrescriptionByID(ctx context.Context, id strin
 
 // UpdatePrescription updates an existing prescription.
 func (r *postgresExerciseRepo) UpdatePrescription(ctx context.Context, prescription *model.ExercisePrescription) error {
+	schedule, err := encodeFrequencySchedule(prescription.FrequencySchedule)
+	if err != nil {
+		return err
+	}
+
 	query := `
 		UPDATE exercise_prescriptions SET
 			sets = $1,
 			reps = $2,
 			hold_seconds = $3,
 			frequency = $4,
-			duration_weeks = $5,
-			custom_instructions = $6,
-			notes = $7,
-			status = $8,
-			end_date = $9
-		WHERE id = $10
+			frequency_schedule = $5,
+			duration_weeks = $6,
+			custom_instructions = $7,
+			notes = $8,
+			status = $9,
+			end_date = $10
+		WHERE id = $11
 		RETURNING updated_at`
 
 	result := r.db.QueryRowContext(ctx, query,
@@ -633,6 +780,7 @@ func (r *postgresExerciseRepo) UpdatePrescription(ctx context.Context, prescript
 		prescription.Reps,
 		prescription.HoldSeconds,
 		prescription.Frequency,
+		schedule,
 		prescription.DurationWeeks,
 		NullableStringValue(prescription.CustomInstructions),
 		NullableStringValue(prescription.Notes),
@@ -677,7 +825,7 @@ func (r *postgresExerciseRepo) ListPatientPrescriptions(ctx context.Context, pat
 	query := `
 		SELECT
 			p.id, p.patient_id, p.exercise_id, p.clinic_id, p.prescribed_by, p.program_id,
-			p.sets, p.reps, p.hold_seconds, p.frequency, p.duration_weeks,
+			p.sets, p.reps, p.hold_seconds, p.frequency, p.frequency_schedule, p.duration_weeks,
 			p.custom_instructions, p.notes, p.status, p.start_date, p.end_date,
 			p.created_at, p.updated_at,
 			e.id, e.name, e.name_vi, e.description, e.description_vi,
@@ -705,6 +853,7 @@ func (r *postgresExerciseRepo) ListPatientPrescriptions(ctx context.Context, pat
 		var e model.Exercise
 		var programID, customInstructions, notes sql.NullString
 		var endDate sql.NullTime
+		var schedule []byte
 		var imageURL, videoURL sql.NullString
 		var equipment []string
 		var muscleGroups []string
@@ -720,6 +869,7 @@ func (r *postgresExerciseRepo) ListPatientPrescriptions(ctx context.Context, pat
 			&p.Reps,
 			&p.HoldSeconds,
 			&p.Frequency,
+			&schedule,
 			&p.DurationWeeks,
 			&customInstructions,
 			&notes,
@@ -750,6 +900,9 @@ func (r *postgresExerciseRepo) ListPatientPrescriptions(ctx context.Context, pat
 		p.CustomInstructions = StringFromNull(customInstructions)
 		p.Notes = StringFromNull(notes)
 		p.EndDate = TimePtrFromNull(endDate)
+		if p.FrequencySchedule, err = decodeFrequencySchedule(schedule); err != nil {
+			return nil, err
+		}
 
 		e.Equipment = equipment
 		e.MuscleGroups = make([]model.MuscleGroup, len(muscleGroups))
@@ -768,17 +921,27 @@ func (r *postgresExerciseRepo) ListPatientPrescriptions(ctx context.Context, pat
 
 // CreateProgram creates a new home exercise program.
 func (r *postgresExerciseRepo) CreateProgram(ctx context.Context, program *model.HomeExerciseProgram) error {
+	return insertProgram(ctx, r.db, program)
+}
+
+// insertProgram inserts a program with q, which may be a transaction.
+func insertProgram(ctx context.Context, q Querier, program *model.HomeExerciseProgram) error {
+	schedule, err := encodeFrequencySchedule(program.FrequencySchedule)
+	if err != nil {
+		return err
+	}
+
 	query := `
 		INSERT INTO home_exercise_programs (
 			id, patient_id, clinic_id, created_by, name, name_vi,
-			description, description_vi, frequency, duration_weeks,
+			description, description_vi, frequency, frequency_schedule, duration_weeks,
 			start_date, end_date, is_active
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
 		)
 		RETURNING created_at, updated_at`
 
-	err := r.db.QueryRowContext(ctx, query,
+	err = q.QueryRowContext(ctx, query,
 		program.ID,
 		program.PatientID,
 		program.ClinicID,
@@ -788,6 +951,7 @@ func (r *postgresExerciseRepo) CreateProgram(ctx context.Context, program *model
 		NullableStringValue(program.Description),
 		NullableStringValue(program.DescriptionVi),
 		program.Frequency,
+		schedule,
 		program.DurationWeeks,
 		program.StartDate,
 		program.EndDate,
@@ -806,7 +970,7 @@ func (r *postgresExerciseRepo) GetProgramByID(ctx context.Context, id string) (*
 	query := `
 		SELECT
 			id, patient_id, clinic_id, created_by, name, name_vi,
-			description, description_vi, frequency, duration_weeks,
+			description, description_vi, frequency, frequency_schedule, duration_weeks,
 			start_date, end_date, is_active, created_at, updated_at
 		FROM home_exercise_programs
 		WHERE id = $1`
@@ -814,6 +978,7 @@ func (r *postgresExerciseRepo) GetProgramByID(ctx context.Context, id string) (*
 	var p model.HomeExerciseProgram
 	var nameVi, description, descriptionVi sql.NullString
 	var endDate sql.NullTime
+	var schedule []byte
 
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&p.ID,
@@ -825,6 +990,7 @@ func (r *postgresExerciseRepo) GetProgramByID(ctx context.Context, id string) (*
 		&description,
 		&descriptionVi,
 		&p.Frequency,
+		&schedule,
 		&p.DurationWeeks,
 		&p.StartDate,
 		&endDate,
@@ -844,6 +1010,9 @@ func (r *postgresExerciseRepo) GetProgramByID(ctx context.Context, id string) (*
 	p.Description = StringFromNull(description)
 	p.DescriptionVi = StringFromNull(descriptionVi)
 	p.EndDate = TimePtrFromNull(endDate)
+	if p.FrequencySchedule, err = decodeFrequencySchedule(schedule); err != nil {
+		return nil, err
+	}
 
 	// Get associated prescriptions
 	prescriptions, err := r.listProgramPrescriptions(ctx, id)
@@ -861,7 +1030,7 @@ func (r *postgresExerciseRepo) listProgramPrescriptions(ctx context.Context, pro
 	query := `
 		SELECT
 			p.id, p.patient_id, p.exercise_id, p.clinic_id, p.prescribed_by, p.program_id,
-			p.sets, p.reps, p.hold_seconds, p.frequency, p.duration_weeks,
+			p.sets, p.reps, p.hold_seconds, p.frequency, p.frequency_schedule, p.duration_weeks,
 			p.custom_instructions, p.notes, p.status, p.start_date, p.end_date,
 			p.created_at, p.updated_at,
 			e.id, e.name, e.name_vi, e.category, e.difficulty
@@ -882,6 +1051,7 @@ func (r *postgresExerciseRepo) listProgramPrescriptions(ctx context.Context, pro
 		var e model.Exercise
 		var pID, customInstructions, notes sql.NullString
 		var endDate sql.NullTime
+		var schedule []byte
 
 		err := rows.Scan(
 			&p.ID,
@@ -894,6 +1064,7 @@ func (r *postgresExerciseRepo) listProgramPrescriptions(ctx context.Context, pro
 			&p.Reps,
 			&p.HoldSeconds,
 			&p.Frequency,
+			&schedule,
 			&p.DurationWeeks,
 			&customInstructions,
 			&notes,
@@ -916,6 +1087,9 @@ func (r *postgresExerciseRepo) listProgramPrescriptions(ctx context.Context, pro
 		p.CustomInstructions = StringFromNull(customInstructions)
 		p.Notes = StringFromNull(notes)
 		p.EndDate = TimePtrFromNull(endDate)
+		if p.FrequencySchedule, err = decodeFrequencySchedule(schedule); err != nil {
+			return nil, err
+		}
 		p.Exercise = &e
 		prescriptions = append(prescriptions, p)
 	}
@@ -925,6 +1099,11 @@ func (r *postgresExerciseRepo) listProgramPrescriptions(ctx context.Context, pro
 
 // UpdateProgram updates an existing program.
 func (r *postgresExerciseRepo) UpdateProgram(ctx context.Context, program *model.HomeExerciseProgram) error {
+	schedule, err := encodeFrequencySchedule(program.FrequencySchedule)
+	if err != nil {
+		return err
+	}
+
 	query := `
 		UPDATE home_exercise_programs SET
 			name = $1,
@@ -932,10 +1111,11 @@ func (r *postgresExerciseRepo) UpdateProgram(ctx context.Context, program *model
 			description = $3,
 			description_vi = $4,
 			frequency = $5,
-			duration_weeks = $6,
-			end_date = $7,
-			is_active = $8
-		WHERE id = $9
+			frequency_schedule = $6,
+			duration_weeks = $7,
+			end_date = $8,
+			is_active = $9
+		WHERE id = $10
 		RETURNING updated_at`
 
 	result := r.db.QueryRowContext(ctx, query,
@@ -944,6 +1124,7 @@ func (r *postgresExerciseRepo) UpdateProgram(ctx context.Context, program *model
 		NullableStringValue(program.Description),
 		NullableStringValue(program.DescriptionVi),
 		program.Frequency,
+		schedule,
 		program.DurationWeeks,
 		program.EndDate,
 		program.IsActive,
@@ -965,7 +1146,7 @@ func (r *postgresExerciseRepo) ListPatientPrograms(ctx context.Context, patientI
 	query := `
 		SELECT
 			id, patient_id, clinic_id, created_by, name, name_vi,
-			description, description_vi, frequency, duration_weeks,
+			description, description_vi, frequency, frequency_schedule, duration_weeks,
 			start_date, end_date, is_active, created_at, updated_at
 		FROM home_exercise_programs
 		WHERE patient_id = $1
@@ -982,6 +1163,7 @@ func (r *postgresExerciseRepo) ListPatientPrograms(ctx context.Context, patientI
 		var p model.HomeExerciseProgram
 		var nameVi, description, descriptionVi sql.NullString
 		var endDate sql.NullTime
+		var schedule []byte
 
 		err := rows.Scan(
 			&p.ID,
@@ -993,6 +1175,7 @@ func (r *postgresExerciseRepo) ListPatientPrograms(ctx context.Context, patientI
 			&description,
 			&descriptionVi,
 			&p.Frequency,
+			&schedule,
 			&p.DurationWeeks,
 			&p.StartDate,
 			&endDate,
@@ -1008,6 +1191,9 @@ func (r *postgresExerciseRepo) ListPatientPrograms(ctx context.Context, patientI
 		p.Description = StringFromNull(description)
 		p.DescriptionVi = StringFromNull(descriptionVi)
 		p.EndDate = TimePtrFromNull(endDate)
+		if p.FrequencySchedule, err = decodeFrequencySchedule(schedule); err != nil {
+			return nil, err
+		}
 
 		programs = append(programs, p)
 	}
@@ -1015,6 +1201,94 @@ func (r *postgresExerciseRepo) ListPatientPrograms(ctx context.Context, patientI
 	return programs, nil
 }
 
+// ListUnscheduledFrequencies lists the distinct free-text frequencies of
+// prescriptions and programs that have no schedule.
+func (r *postgresExerciseRepo) ListUnscheduledFrequencies(ctx context.Context) ([]string, error) {
+	query := `
+		SELECT frequency FROM exercise_prescriptions WHERE frequency_schedule IS NULL
+		UNION
+		SELECT frequency FROM home_exercise_programs WHERE frequency_schedule IS NULL`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list frequencies: %w", err)
+	}
+	defer rows.Close()
+
+	frequencies := []string{}
+	for rows.Next() {
+		var frequency string
+		if err := rows.Scan(&frequency); err != nil {
+			return nil, fmt.Errorf("failed to scan frequency: %w", err)
+		}
+		frequencies = append(frequencies, frequency)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate frequencies: %w", err)
+	}
+
+	return frequencies, nil
+}
+
+// SetFrequencySchedule sets the schedule of the prescriptions and programs
+// with the given free-text frequency and no schedule, and returns how many
+// were updated.
+func (r *postgresExerciseRepo) SetFrequencySchedule(ctx context.Context, frequency string, schedule *model.FrequencySchedule) (int64, error) {
+	encoded, err := encodeFrequencySchedule(schedule)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	err = r.db.WithTx(ctx, func(tx *Tx) error {
+		for _, table := range []string{"exercise_prescriptions", "home_exercise_programs"} {
+			result, err := tx.ExecContext(ctx, `
+				UPDATE `+table+` SET frequency_schedule = $1
+				WHERE frequency = $2 AND frequency_schedule IS NULL`,
+				encoded, frequency)
+			if err != nil {
+				return fmt.Errorf("failed to set frequency schedule: %w", err)
+			}
+			n, err := result.RowsAffected()
+			if err != nil {
+				return fmt.Errorf("failed to get rows affected: %w", err)
+			}
+			total += n
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// encodeFrequencySchedule encodes a schedule for a JSONB column, or NULL for
+// none.
+func encodeFrequencySchedule(schedule *model.FrequencySchedule) (interface{}, error) {
+	if schedule == nil {
+		return nil, nil
+	}
+	encoded, err := json.Marshal(schedule)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode frequency schedule: %w", err)
+	}
+	return encoded, nil
+}
+
+// decodeFrequencySchedule decodes a schedule read from a JSONB column.
+func decodeFrequencySchedule(data []byte) (*model.FrequencySchedule, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var schedule model.FrequencySchedule
+	if err := json.Unmarshal(data, &schedule); err != nil {
+		return nil, fmt.Errorf("failed to parse frequency schedule: %w", err)
+	}
+	return &schedule, nil
+}
+
 // LogCompliance logs an exercise completion.
 func (r *postgresExerciseRepo) LogCompliance(ctx context.Context, complianceLog *model.ExerciseComplianceLog) error {
 	query := `
@@ -1184,6 +1458,18 @@ func (r *mockExerciseRepo) Search(ctx context.Context, clinicID, query string, l
 	return []model.Exercise{}, nil
 }
 
+func (r *mockExerciseRepo) ListLibrary(ctx context.Context, clinicID string, includeGlobal bool) ([]model.Exercise, error) {
+	return []model.Exercise{}, nil
+}
+
+func (r *mockExerciseRepo) FindByNames(ctx context.Context, clinicID string, names []string) ([]model.Exercise, error) {
+	return []model.Exercise{}, nil
+}
+
+func (r *mockExerciseRepo) CountPrescriptionsByDiagnosis(ctx context.Context, clinicID, diagnosisCode string) (map[string]int, error) {
+	return map[string]int{}, nil
+}
+
 func (r *mockExerciseRepo) CreatePrescription(ctx context.Context, prescription *model.ExercisePrescription) error {
 	return nil
 }
@@ -1220,6 +1506,14 @@ func (r *mockExerciseRepo) ListPatientPrograms(ctx context.Context, patientID st
 	return []model.HomeExerciseProgram{}, nil
 }
 
+func (r *mockExerciseRepo) ListUnscheduledFrequencies(ctx context.Context) ([]string, error) {
+	return []string{}, nil
+}
+
+func (r *mockExerciseRepo) SetFrequencySchedule(ctx context.Context, frequency string, schedule *model.FrequencySchedule) (int64, error) {
+	return 0, nil
+}
+
 func (r *mockExerciseRepo) LogCompliance(ctx context.Context, log *model.ExerciseComplianceLog) error {
 	return nil
 }
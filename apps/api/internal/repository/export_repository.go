@@ -0,0 +1,476 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+)
+
+// ExportRepository defines the interface for export jobs and the full-history
+// reads that go into a patient export bundle.
+type ExportRepository interface {
+	CreateJob(ctx context.Context, job *model.DataExport) error
+	GetJob(ctx context.Context, clinicID, id string) (*model.DataExport, error)
+	UpdateJob(ctx context.Context, job *model.DataExport) error
+	ListJobsByPatient(ctx context.Context, clinicID, patientID string) ([]model.DataExport, error)
+
+	// ClaimJob marks the oldest pending job running and returns it, or
+	// ErrNotFound when none is waiting. A job is claimed by one worker only,
+	// even with several API instances.
+	ClaimJob(ctx context.Context) (*model.DataExport, error)
+	// FailStaleJobs marks jobs still running since before the given time as
+	// failed, and returns them.
+	FailStaleJobs(ctx context.Context, before time.Time, reason string) ([]model.DataExport, error)
+
+	// Record readers return the complete history, oldest first.
+	ListAppointments(ctx context.Context, clinicID, patientID string) ([]model.AppointmentWithDetails, error)
+	ListVisitNotes(ctx context.Context, clinicID, patientID string) ([]model.ExportVisitNote, error)
+	ListPainRecords(ctx context.Context, clinicID, patientID string) ([]model.QuickPainRecord, error)
+	ListROMRecords(ctx context.Context, clinicID, patientID string) ([]model.QuickROMRecord, error)
+}
+
+// postgresExportRepo implements ExportRepository with PostgreSQL.
+type postgresExportRepo struct {
+	db *DB
+}
+
+// NewExportRepository creates a new PostgreSQL export repository.
+func NewExportRepository(db *DB) ExportRepository {
+	return &postgresExportRepo{db: db}
+}
+
+// exportColumns lists the columns read by scanExport.
+const exportColumns = `
+	id, clinic_id, patient_id, purpose, recipient, include_attachments, status,
+	blob_key, size_bytes, sha256, error, completed_at, expires_at,
+	requested_by, created_at, updated_at`
+
+// CreateJob inserts a new export job.
+func (r *postgresExportRepo) CreateJob(ctx context.Context, job *model.DataExport) error {
+	query := `
+		INSERT INTO data_exports (
+			id, clinic_id, patient_id, purpose, recipient, include_attachments, status, requested_by
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8
+		)
+		RETURNING created_at, updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		job.ID,
+		job.ClinicID,
+		job.PatientID,
+		job.Purpose,
+		NullableStringValue(job.Recipient),
+		job.IncludeAttachments,
+		job.Status,
+		job.RequestedBy,
+	).Scan(&job.CreatedAt, &job.UpdatedAt)
+
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			return fmt.Errorf("%w: patient or clinic does not exist", ErrInvalidInput)
+		}
+		return fmt.Errorf("failed to create export job: %w", err)
+	}
+
+	return nil
+}
+
+// GetJob retrieves an export job by ID.
+func (r *postgresExportRepo) GetJob(ctx context.Context, clinicID, id string) (*model.DataExport, error) {
+	query := `
+		SELECT ` + exportColumns + `
+		FROM data_exports
+		WHERE id = $1 AND clinic_id = $2`
+
+	job, err := scanExport(r.db.QueryRowContext(ctx, query, id, clinicID))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get export job: %w", err)
+	}
+	return job, nil
+}
+
+// UpdateJob saves the status and result of an export job.
+func (r *postgresExportRepo) UpdateJob(ctx context.Context, job *model.DataExport) error {
+	query := `
+		UPDATE data_exports SET
+			status = $3,
+			blob_key = $4,
+			size_bytes = $5,
+			sha256 = $6,
+			error = $7,
+			completed_at = $8,
+			expires_at = $9
+		WHERE id = $1 AND clinic_id = $2
+		RETURNING updated_at`
+
+	var size sql.NullInt64
+	if job.SizeBytes > 0 {
+		size = sql.NullInt64{Int64: job.SizeBytes, Valid: true}
+	}
+
+	err := r.db.QueryRowContext(ctx, query,
+		job.ID,
+		job.ClinicID,
+		job.Status,
+		NullableStringValue(job.BlobKey),
+		size,
+		NullableStringValue(job.SHA256),
+		NullableStringValue(job.Error),
+		NullableTime(job.CompletedAt),
+		NullableTime(job.ExpiresAt),
+	).Scan(&job.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update export job: %w", err)
+	}
+
+	return nil
+}
+
+// ListJobsByPatient retrieves a patient's export jobs, newest first.
+func (r *postgresExportRepo) ListJobsByPatient(ctx context.Context, clinicID, patientID string) ([]model.DataExport, error) {
+	query := `
+		SELECT ` + exportColumns + `
+		FROM data_exports
+		WHERE patient_id = $1 AND clinic_id = $2
+		ORDER BY created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, patientID, clinicID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list export jobs: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := []model.DataExport{}
+	for rows.Next() {
+		job, err := scanExport(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan export job: %w", err)
+		}
+		jobs = append(jobs, *job)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate export jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// ClaimJob marks the oldest pending job running and returns it.
+func (r *postgresExportRepo) ClaimJob(ctx context.Context) (*model.DataExport, error) {
+	query := `
+		UPDATE data_exports SET status = $1
+		WHERE id = (
+			SELECT id FROM data_exports
+			WHERE status = $2
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + exportColumns
+
+	job, err := scanExport(r.db.QueryRowContext(ctx, query, model.ExportStatusRunning, model.ExportStatusPending))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim export job: %w", err)
+	}
+	return job, nil
+}
+
+// FailStaleJobs marks jobs running since before the given time as failed.
+func (r *postgresExportRepo) FailStaleJobs(ctx context.Context, before time.Time, reason string) ([]model.DataExport, error) {
+	query := `
+		UPDATE data_exports SET status = $1, error = $2
+		WHERE status = $3 AND updated_at < $4
+		RETURNING ` + exportColumns
+
+	rows, err := r.db.QueryContext(ctx, query, model.ExportStatusFailed, reason, model.ExportStatusRunning, before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fail stale export jobs: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := []model.DataExport{}
+	for rows.Next() {
+		job, err := scanExport(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan export job: %w", err)
+		}
+		jobs = append(jobs, *job)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate export jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// ListAppointments retrieves every appointment for a patient.
+func (r *postgresExportRepo) ListAppointments(ctx context.Context, clinicID, patientID string) ([]model.AppointmentWithDetails, error) {
+	query := `
+		SELECT
+			a.id, a.clinic_id, a.patient_id, a.therapist_id, a.start_time, a.end_time,
+			a.duration, a.type, a.status, a.room, a.notes, a.cancellation_reason,
+			a.recurrence_id, a.created_at, a.updated_at, a.created_by, a.updated_by,
+			COALESCE(p.first_name || ' ' || p.last_name, '') as patient_name,
+			COALESCE(p.mrn, '') as patient_mrn,
+			COALESCE(p.phone, '') as patient_phone,
+			COALESCE(u.first_name || ' ' || u.last_name, '') as therapist_name
+		FROM appointments a
+		LEFT JOIN patients p ON a.patient_id = p.id
+		LEFT JOIN users u ON a.therapist_id = u.id
+		WHERE a.clinic_id = $1 AND a.patient_id = $2
+		ORDER BY a.start_time`
+
+	rows, err := r.db.QueryContext(ctx, query, clinicID, patientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list appointments for export: %w", err)
+	}
+	defer rows.Close()
+
+	scanner := &postgresAppointmentRepo{db: r.db}
+	appointments := make([]model.AppointmentWithDetails, 0)
+	for rows.Next() {
+		a, err := scanner.scanAppointmentWithDetailsRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		appointments = append(appointments, *a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate appointments: %w", err)
+	}
+
+	return appointments, nil
+}
+
+// ListVisitNotes retrieves every generated SOAP note for a patient.
+func (r *postgresExportRepo) ListVisitNotes(ctx context.Context, clinicID, patientID string) ([]model.ExportVisitNote, error) {
+	query := `
+		SELECT
+			vc.id,
+			COALESCE(ct.name, ''),
+			COALESCE(u.first_name || ' ' || u.last_name, ''),
+			vc.completed_at,
+			vc.created_at,
+			COALESCE(vc.generated_note, ''),
+			COALESCE(vc.generated_note_vi, '')
+		FROM visit_checklists vc
+		LEFT JOIN checklist_templates ct ON vc.template_id = ct.id
+		LEFT JOIN users u ON vc.therapist_id = u.id
+		WHERE vc.clinic_id = $1 AND vc.patient_id = $2
+			AND (vc.generated_note IS NOT NULL OR vc.generated_note_vi IS NOT NULL)
+		ORDER BY COALESCE(vc.completed_at, vc.created_at)`
+
+	rows, err := r.db.QueryContext(ctx, query, clinicID, patientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list visit notes for export: %w", err)
+	}
+	defer rows.Close()
+
+	notes := []model.ExportVisitNote{}
+	for rows.Next() {
+		var n model.ExportVisitNote
+		var completedAt sql.NullTime
+		if err := rows.Scan(
+			&n.VisitChecklistID, &n.TemplateName, &n.TherapistName,
+			&completedAt, &n.CreatedAt, &n.Note, &n.NoteVi,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan visit note: %w", err)
+		}
+		n.CompletedAt = TimePtrFromNull(completedAt)
+		notes = append(notes, n)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate visit notes: %w", err)
+	}
+
+	return notes, nil
+}
+
+// ListPainRecords retrieves every pain score recorded for a patient.
+func (r *postgresExportRepo) ListPainRecords(ctx context.Context, clinicID, patientID string) ([]model.QuickPainRecord, error) {
+	query := `
+		SELECT patient_id, clinic_id, therapist_id, level,
+			   location, body_region, notes, context, recorded_at
+		FROM quick_pain_records
+		WHERE clinic_id = $1 AND patient_id = $2
+		ORDER BY recorded_at`
+
+	rows, err := r.db.QueryContext(ctx, query, clinicID, patientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pain records for export: %w", err)
+	}
+	defer rows.Close()
+
+	records := []model.QuickPainRecord{}
+	for rows.Next() {
+		var record model.QuickPainRecord
+		var location, bodyRegion, notes, painContext sql.NullString
+		if err := rows.Scan(
+			&record.PatientID, &record.ClinicID, &record.TherapistID, &record.Level,
+			&location, &bodyRegion, &notes, &painContext, &record.RecordedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan pain record: %w", err)
+		}
+		record.Location = StringFromNull(location)
+		record.BodyRegion = StringFromNull(bodyRegion)
+		record.Notes = StringFromNull(notes)
+		record.Context = StringFromNull(painContext)
+		records = append(records, record)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate pain records: %w", err)
+	}
+
+	return records, nil
+}
+
+// ListROMRecords retrieves every range-of-motion measurement for a patient.
+func (r *postgresExportRepo) ListROMRecords(ctx context.Context, clinicID, patientID string) ([]model.QuickROMRecord, error) {
+	query := `
+		SELECT patient_id, clinic_id, therapist_id, joint, movement,
+			   side, active_rom, passive_rom, is_painful, notes, recorded_at
+		FROM quick_rom_records
+		WHERE clinic_id = $1 AND patient_id = $2
+		ORDER BY recorded_at`
+
+	rows, err := r.db.QueryContext(ctx, query, clinicID, patientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ROM records for export: %w", err)
+	}
+	defer rows.Close()
+
+	records := []model.QuickROMRecord{}
+	for rows.Next() {
+		var record model.QuickROMRecord
+		var side, notes sql.NullString
+		var activeROM, passiveROM sql.NullFloat64
+		if err := rows.Scan(
+			&record.PatientID, &record.ClinicID, &record.TherapistID, &record.Joint, &record.Movement,
+			&side, &activeROM, &passiveROM, &record.IsPainful, &notes, &record.RecordedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan ROM record: %w", err)
+		}
+		record.Side = StringFromNull(side)
+		record.Notes = StringFromNull(notes)
+		if activeROM.Valid {
+			record.ActiveROM = &activeROM.Float64
+		}
+		if passiveROM.Valid {
+			record.PassiveROM = &passiveROM.Float64
+		}
+		records = append(records, record)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate ROM records: %w", err)
+	}
+
+	return records, nil
+}
+
+// scanExport scans a row selected with exportColumns.
+func scanExport(row rowScanner) (*model.DataExport, error) {
+	var e model.DataExport
+	var recipient, blobKey, sha, errMsg sql.NullString
+	var size sql.NullInt64
+	var completedAt, expiresAt sql.NullTime
+
+	err := row.Scan(
+		&e.ID,
+		&e.ClinicID,
+		&e.PatientID,
+		&e.Purpose,
+		&recipient,
+		&e.IncludeAttachments,
+		&e.Status,
+		&blobKey,
+		&size,
+		&sha,
+		&errMsg,
+		&completedAt,
+		&expiresAt,
+		&e.RequestedBy,
+		&e.CreatedAt,
+		&e.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	e.Recipient = StringFromNull(recipient)
+	e.BlobKey = StringFromNull(blobKey)
+	e.SizeBytes = size.Int64
+	e.SHA256 = StringFromNull(sha)
+	e.Error = StringFromNull(errMsg)
+	e.CompletedAt = TimePtrFromNull(completedAt)
+	e.ExpiresAt = TimePtrFromNull(expiresAt)
+
+	return &e, nil
+}
+
+// mockExportRepo provides a mock implementation for development.
+type mockExportRepo struct{}
+
+func (r *mockExportRepo) CreateJob(ctx context.Context, job *model.DataExport) error {
+	job.CreatedAt = time.Now()
+	job.UpdatedAt = job.CreatedAt
+	return nil
+}
+
+func (r *mockExportRepo) GetJob(ctx context.Context, clinicID, id string) (*model.DataExport, error) {
+	return nil, ErrNotFound
+}
+
+func (r *mockExportRepo) UpdateJob(ctx context.Context, job *model.DataExport) error {
+	job.UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *mockExportRepo) ListJobsByPatient(ctx context.Context, clinicID, patientID string) ([]model.DataExport, error) {
+	return []model.DataExport{}, nil
+}
+
+func (r *mockExportRepo) ClaimJob(ctx context.Context) (*model.DataExport, error) {
+	return nil, ErrNotFound
+}
+
+func (r *mockExportRepo) FailStaleJobs(ctx context.Context, before time.Time, reason string) ([]model.DataExport, error) {
+	return []model.DataExport{}, nil
+}
+
+func (r *mockExportRepo) ListAppointments(ctx context.Context, clinicID, patientID string) ([]model.AppointmentWithDetails, error) {
+	return []model.AppointmentWithDetails{}, nil
+}
+
+func (r *mockExportRepo) ListVisitNotes(ctx context.Context, clinicID, patientID string) ([]model.ExportVisitNote, error) {
+	return []model.ExportVisitNote{}, nil
+}
+
+func (r *mockExportRepo) ListPainRecords(ctx context.Context, clinicID, patientID string) ([]model.QuickPainRecord, error) {
+	return []model.QuickPainRecord{}, nil
+}
+
+func (r *mockExportRepo) ListROMRecords(ctx context.Context, clinicID, patientID string) ([]model.QuickROMRecord, error) {
+	return []model.QuickROMRecord{}, nil
+}
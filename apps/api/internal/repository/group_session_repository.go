@@ -0,0 +1,828 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+)
+
+// GroupSessionRepository defines the interface for group sessions and their rosters.
+type GroupSessionRepository interface {
+	List(ctx context.Context, params model.GroupSessionSearchParams) ([]model.GroupSession, error)
+	GetByID(ctx context.Context, clinicID, id string) (*model.GroupSession, error)
+	ListParticipants(ctx context.Context, sessionID string) ([]model.GroupParticipant, error)
+	GetParticipant(ctx context.Context, sessionID, id string) (*model.GroupParticipant, error)
+	Create(ctx context.Context, session *model.GroupSession) error
+	Update(ctx context.Context, session *model.GroupSession) error
+	Cancel(ctx context.Context, session *model.GroupSession) error
+	AddParticipant(ctx context.Context, session *model.GroupSession, participant *model.GroupParticipant, allowWaitlist bool) error
+	UpdateParticipant(ctx context.Context, session *model.GroupSession, participant *model.GroupParticipant) error
+	CancelParticipantByAppointment(ctx context.Context, clinicID, appointmentID, reason string, updatedBy *string) error
+}
+
+// postgresGroupSessionRepo implements GroupSessionRepository with PostgreSQL.
+type postgresGroupSessionRepo struct {
+	db *DB
+}
+
+// NewGroupSessionRepository creates a new PostgreSQL group session repository.
+func NewGroupSessionRepository(db *DB) GroupSessionRepository {
+	return &postgresGroupSessionRepo{db: db}
+}
+
+// groupSessionSelect selects the columns read by scanGroupSession, including
+// the therapist's name and the roster counts.
+const groupSessionSelect = `
+	SELECT
+		gs.id, gs.clinic_id, gs.therapist_id, gs.appointment_type_id, gs.type, gs.title,
+		gs.start_time, gs.end_time, gs.duration, gs.capacity, gs.room, gs.resource_ids,
+		gs.status, gs.notes, gs.cancellation_reason,
+		gs.created_at, gs.updated_at, gs.created_by, gs.updated_by,
+		COALESCE(u.first_name || ' ' || u.last_name, '') AS therapist_name,
+		(SELECT COUNT(*) FROM group_session_participants gp
+			WHERE gp.session_id = gs.id AND gp.status IN ('booked', 'attended', 'no_show')) AS booked_count,
+		(SELECT COUNT(*) FROM group_session_participants gp
+			WHERE gp.session_id = gs.id AND gp.status = 'waitlisted') AS waitlist_count
+	FROM group_sessions gs
+	LEFT JOIN users u ON gs.therapist_id = u.id`
+
+// participantSelect selects the columns read by scanParticipant.
+const participantSelect = `
+	SELECT
+		gp.id, gp.session_id, gp.patient_id, gp.appointment_id, gp.status,
+		gp.waitlist_position, gp.notes, gp.created_at, gp.updated_at, gp.created_by, gp.updated_by,
+		COALESCE(p.first_name || ' ' || p.last_name, '') AS patient_name,
+		COALESCE(p.mrn, '') AS patient_mrn
+	FROM group_session_participants gp
+	LEFT JOIN patients p ON gp.patient_id = p.id`
+
+// List retrieves a clinic's group sessions overlapping the requested range.
+func (r *postgresGroupSessionRepo) List(ctx context.Context, params model.GroupSessionSearchParams) ([]model.GroupSession, error) {
+	query := groupSessionSelect + `
+		WHERE gs.clinic_id = $1
+			AND ($2 = '' OR gs.therapist_id::text = $2)
+			AND gs.start_time < $4
+			AND gs.end_time > $3
+			AND ($5 OR gs.status = 'scheduled')
+		ORDER BY gs.start_time, gs.title`
+
+	return querySessions(ctx, r.db, query, params.ClinicID, params.TherapistID, params.From, params.To, params.IncludeCancelled)
+}
+
+// GetByID retrieves a group session by ID.
+func (r *postgresGroupSessionRepo) GetByID(ctx context.Context, clinicID, id string) (*model.GroupSession, error) {
+	query := groupSessionSelect + ` WHERE gs.id = $1 AND gs.clinic_id = $2`
+
+	gs, err := scanGroupSession(r.db.QueryRowContext(ctx, query, id, clinicID))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group session: %w", err)
+	}
+	return gs, nil
+}
+
+// ListParticipants retrieves a session's roster: booked participants first,
+// then the waitlist in order, then cancellations.
+func (r *postgresGroupSessionRepo) ListParticipants(ctx context.Context, sessionID string) ([]model.GroupParticipant, error) {
+	query := participantSelect + `
+		WHERE gp.session_id = $1
+		ORDER BY
+			CASE gp.status WHEN 'waitlisted' THEN 1 WHEN 'cancelled' THEN 2 ELSE 0 END,
+			gp.waitlist_position NULLS FIRST,
+			patient_name`
+
+	rows, err := r.db.QueryContext(ctx, query, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list participants: %w", err)
+	}
+	defer rows.Close()
+
+	participants := make([]model.GroupParticipant, 0)
+	for rows.Next() {
+		p, err := scanParticipant(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan participant: %w", err)
+		}
+		participants = append(participants, *p)
+	}
+
+	return participants, rows.Err()
+}
+
+// GetParticipant retrieves a participant of a session.
+func (r *postgresGroupSessionRepo) GetParticipant(ctx context.Context, sessionID, id string) (*model.GroupParticipant, error) {
+	query := participantSelect + ` WHERE gp.id = $1 AND gp.session_id = $2`
+
+	p, err := scanParticipant(r.db.QueryRowContext(ctx, query, id, sessionID))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get participant: %w", err)
+	}
+	return p, nil
+}
+
+// Create inserts a group session, checking the capacity of its resources in
+// the same transaction.
+func (r *postgresGroupSessionRepo) Create(ctx context.Context, session *model.GroupSession) error {
+	return r.db.WithTx(ctx, func(tx *Tx) error {
+		booking := session.AsAppointment()
+		if err := checkResourceCapacity(ctx, tx, session.ClinicID, []*model.Appointment{&booking}); err != nil {
+			return err
+		}
+
+		query := `
+			INSERT INTO group_sessions (
+				id, clinic_id, therapist_id, appointment_type_id, type, title,
+				start_time, end_time, duration, capacity, room, resource_ids,
+				status, notes, created_by, updated_by
+			) VALUES (
+				$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $15
+			)
+			RETURNING created_at, updated_at`
+
+		err := tx.QueryRowContext(ctx, query,
+			session.ID,
+			session.ClinicID,
+			session.TherapistID,
+			NullableString(session.AppointmentTypeID),
+			session.Type,
+			session.Title,
+			session.StartTime,
+			session.EndTime,
+			session.Duration,
+			session.Capacity,
+			NullableStringValue(session.Room),
+			pq.Array(nonNilStrings(session.ResourceIDs)),
+			session.Status,
+			NullableStringValue(session.Notes),
+			NullableString(session.CreatedBy),
+		).Scan(&session.CreatedAt, &session.UpdatedAt)
+		if err != nil {
+			if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+				return fmt.Errorf("%w: invalid therapist or appointment type ID", ErrInvalidInput)
+			}
+			return fmt.Errorf("failed to create group session: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// Update updates a group session and moves the open appointments of its
+// participants with it. A capacity below the number of booked participants is
+// rejected; a higher one books patients from the waitlist.
+func (r *postgresGroupSessionRepo) Update(ctx context.Context, session *model.GroupSession) error {
+	return r.db.WithTx(ctx, func(tx *Tx) error {
+		current, err := lockSession(ctx, tx, session.ClinicID, session.ID)
+		if err != nil {
+			return err
+		}
+		if session.Capacity < current.BookedCount {
+			return fmt.Errorf("%w: capacity is below the %d booked participants", ErrInvalidInput, current.BookedCount)
+		}
+
+		booking := session.AsAppointment()
+		if err := checkResourceCapacity(ctx, tx, session.ClinicID, []*model.Appointment{&booking}); err != nil {
+			return err
+		}
+
+		query := `
+			UPDATE group_sessions SET
+				therapist_id = $1, title = $2, start_time = $3, end_time = $4,
+				duration = $5, capacity = $6, room = $7, resource_ids = $8,
+				notes = $9, updated_by = $10
+			WHERE id = $11 AND clinic_id = $12
+			RETURNING updated_at`
+
+		err = tx.QueryRowContext(ctx, query,
+			session.TherapistID,
+			session.Title,
+			session.StartTime,
+			session.EndTime,
+			session.Duration,
+			session.Capacity,
+			NullableStringValue(session.Room),
+			pq.Array(nonNilStrings(session.ResourceIDs)),
+			NullableStringValue(session.Notes),
+			NullableString(session.UpdatedBy),
+			session.ID,
+			session.ClinicID,
+		).Scan(&session.UpdatedAt)
+		if err == sql.ErrNoRows {
+			return ErrNotFound
+		}
+		if err != nil {
+			if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+				return fmt.Errorf("%w: invalid therapist ID", ErrInvalidInput)
+			}
+			return fmt.Errorf("failed to update group session: %w", err)
+		}
+
+		// Open appointments take the same time, therapist and room as a
+		// participant booked into the session now
+		moved := session.ParticipantAppointment("", "", session.UpdatedBy)
+		moveQuery := `
+			UPDATE appointments SET
+				therapist_id = $1, start_time = $2, end_time = $3, duration = $4,
+				room = $5, notes = $6, updated_by = $7
+			WHERE group_session_id = $8 AND clinic_id = $9
+				AND status IN ('scheduled', 'confirmed')`
+
+		_, err = tx.ExecContext(ctx, moveQuery,
+			moved.TherapistID,
+			moved.StartTime,
+			moved.EndTime,
+			moved.Duration,
+			NullableStringValue(moved.Room),
+			moved.Notes,
+			NullableString(moved.UpdatedBy),
+			session.ID,
+			session.ClinicID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to move participant appointments: %w", err)
+		}
+
+		session.BookedCount = current.BookedCount
+		if err := promoteWaitlist(ctx, tx, session, session.UpdatedBy); err != nil {
+			return err
+		}
+		return renumberWaitlist(ctx, tx, session.ID)
+	})
+}
+
+// Cancel cancels a group session, the open appointments of its participants
+// and its waitlist.
+func (r *postgresGroupSessionRepo) Cancel(ctx context.Context, session *model.GroupSession) error {
+	return r.db.WithTx(ctx, func(tx *Tx) error {
+		query := `
+			UPDATE group_sessions SET
+				status = 'cancelled', cancellation_reason = $1, updated_by = $2
+			WHERE id = $3 AND clinic_id = $4 AND status = 'scheduled'
+			RETURNING updated_at`
+
+		err := tx.QueryRowContext(ctx, query,
+			NullableStringValue(session.CancellationReason),
+			NullableString(session.UpdatedBy),
+			session.ID,
+			session.ClinicID,
+		).Scan(&session.UpdatedAt)
+		if err == sql.ErrNoRows {
+			return ErrNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to cancel group session: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE appointments SET
+				status = 'cancelled', cancellation_reason = $1, updated_by = $2
+			WHERE group_session_id = $3 AND status IN ('scheduled', 'confirmed')`,
+			NullableStringValue(session.CancellationReason),
+			NullableString(session.UpdatedBy),
+			session.ID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to cancel participant appointments: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE group_session_participants SET
+				status = 'cancelled', waitlist_position = NULL, updated_by = $1
+			WHERE session_id = $2 AND status IN ('booked', 'waitlisted')`,
+			NullableString(session.UpdatedBy),
+			session.ID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to cancel participants: %w", err)
+		}
+
+		session.Status = model.GroupSessionStatusCancelled
+		return nil
+	})
+}
+
+// AddParticipant adds a patient to a session. The patient is booked, with an
+// appointment of their own, while there are places left; after that they join
+// the end of the waitlist, or the request fails with ErrConflict when the
+// waitlist is not allowed. A patient who cancelled earlier can be added again.
+func (r *postgresGroupSessionRepo) AddParticipant(ctx context.Context, session *model.GroupSession, participant *model.GroupParticipant, allowWaitlist bool) error {
+	return r.db.WithTx(ctx, func(tx *Tx) error {
+		current, err := lockSession(ctx, tx, session.ClinicID, session.ID)
+		if err != nil {
+			return err
+		}
+		if current.Status != model.GroupSessionStatusScheduled {
+			return fmt.Errorf("%w: group session is cancelled", ErrInvalidInput)
+		}
+
+		var existing model.ParticipantStatus
+		err = tx.QueryRowContext(ctx,
+			`SELECT status FROM group_session_participants WHERE session_id = $1 AND patient_id = $2`,
+			current.ID, participant.PatientID,
+		).Scan(&existing)
+		if err != nil && err != sql.ErrNoRows {
+			return fmt.Errorf("failed to check participant: %w", err)
+		}
+		if err == nil && existing != model.ParticipantStatusCancelled {
+			return ErrAlreadyExists
+		}
+
+		participant.SessionID = current.ID
+		participant.AppointmentID = nil
+		participant.Status, participant.WaitlistPosition, err = placeParticipant(current, allowWaitlist)
+		if err != nil {
+			return err
+		}
+		if participant.Status == model.ParticipantStatusBooked {
+			appointment := current.ParticipantAppointment(uuid.New().String(), participant.PatientID, participant.CreatedBy)
+			if err := insertAppointment(ctx, tx, appointment); err != nil {
+				return err
+			}
+			participant.AppointmentID = &appointment.ID
+		}
+
+		query := `
+			INSERT INTO group_session_participants (
+				id, session_id, patient_id, appointment_id, status,
+				waitlist_position, notes, created_by, updated_by
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8)
+			ON CONFLICT (session_id, patient_id) DO UPDATE SET
+				appointment_id = EXCLUDED.appointment_id,
+				status = EXCLUDED.status,
+				waitlist_position = EXCLUDED.waitlist_position,
+				notes = EXCLUDED.notes,
+				updated_by = EXCLUDED.updated_by
+			RETURNING id, created_at, updated_at`
+
+		err = tx.QueryRowContext(ctx, query,
+			participant.ID,
+			participant.SessionID,
+			participant.PatientID,
+			NullableString(participant.AppointmentID),
+			participant.Status,
+			nullableInt(participant.WaitlistPosition),
+			NullableStringValue(participant.Notes),
+			NullableString(participant.CreatedBy),
+		).Scan(&participant.ID, &participant.CreatedAt, &participant.UpdatedAt)
+		if err != nil {
+			if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+				return fmt.Errorf("%w: invalid patient ID", ErrInvalidInput)
+			}
+			return fmt.Errorf("failed to add participant: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// UpdateParticipant moves a participant to participant.Status and saves their
+// notes, keeping their appointment and the waitlist in step. Booking from the
+// waitlist or after a cancellation needs a free place; a cancelled place goes
+// to the first patient on the waitlist.
+func (r *postgresGroupSessionRepo) UpdateParticipant(ctx context.Context, session *model.GroupSession, participant *model.GroupParticipant) error {
+	return r.db.WithTx(ctx, func(tx *Tx) error {
+		current, err := lockSession(ctx, tx, session.ClinicID, session.ID)
+		if err != nil {
+			return err
+		}
+
+		var prev model.ParticipantStatus
+		var appointmentID sql.NullString
+		err = tx.QueryRowContext(ctx,
+			`SELECT status, appointment_id FROM group_session_participants WHERE id = $1 AND session_id = $2`,
+			participant.ID, current.ID,
+		).Scan(&prev, &appointmentID)
+		if err == sql.ErrNoRows {
+			return ErrNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get participant: %w", err)
+		}
+		participant.AppointmentID = StringPtrFromNull(appointmentID)
+
+		return changeParticipant(ctx, tx, current, participant, prev, "")
+	})
+}
+
+// CancelParticipantByAppointment cancels the participant whose appointment is
+// given, as when the appointment itself is cancelled, and offers the place to
+// the waitlist.
+func (r *postgresGroupSessionRepo) CancelParticipantByAppointment(ctx context.Context, clinicID, appointmentID, reason string, updatedBy *string) error {
+	return r.db.WithTx(ctx, func(tx *Tx) error {
+		var sessionID string
+		err := tx.QueryRowContext(ctx, `
+			SELECT gp.session_id
+			FROM group_session_participants gp
+			JOIN group_sessions gs ON gp.session_id = gs.id
+			WHERE gp.appointment_id = $1 AND gs.clinic_id = $2`,
+			appointmentID, clinicID,
+		).Scan(&sessionID)
+		if err == sql.ErrNoRows {
+			return ErrNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to find participant: %w", err)
+		}
+
+		current, err := lockSession(ctx, tx, clinicID, sessionID)
+		if err != nil {
+			return err
+		}
+
+		p, err := scanParticipant(tx.QueryRowContext(ctx, participantSelect+` WHERE gp.appointment_id = $1`, appointmentID))
+		if err != nil {
+			return fmt.Errorf("failed to get participant: %w", err)
+		}
+		if p.Status == model.ParticipantStatusCancelled {
+			return nil
+		}
+
+		prev := p.Status
+		p.Status = model.ParticipantStatusCancelled
+		p.UpdatedBy = updatedBy
+		return changeParticipant(ctx, tx, current, p, prev, reason)
+	})
+}
+
+// lockSession loads a group session with its roster counts and locks it for
+// the rest of the transaction. Every change to a roster takes this lock first,
+// so the counts stay accurate until the transaction ends.
+func lockSession(ctx context.Context, tx *Tx, clinicID, id string) (*model.GroupSession, error) {
+	query := groupSessionSelect + ` WHERE gs.id = $1 AND gs.clinic_id = $2 FOR UPDATE OF gs`
+
+	gs, err := scanGroupSession(tx.QueryRowContext(ctx, query, id, clinicID))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock group session: %w", err)
+	}
+	return gs, nil
+}
+
+// changeParticipant moves a participant of the locked session from prev to
+// participant.Status, updates their appointment to match and writes the
+// participant. A place given up goes to the waitlist.
+func changeParticipant(ctx context.Context, tx *Tx, session *model.GroupSession, participant *model.GroupParticipant, prev model.ParticipantStatus, reason string) error {
+	next := participant.Status
+	if next != prev {
+		switch {
+		case next == model.ParticipantStatusWaitlisted:
+			return fmt.Errorf("%w: participants join the waitlist only when the session is full", ErrInvalidInput)
+		case next == model.ParticipantStatusBooked && !prev.HoldsSeat():
+			if session.Status != model.GroupSessionStatusScheduled {
+				return fmt.Errorf("%w: group session is cancelled", ErrInvalidInput)
+			}
+			if session.SeatsLeft() == 0 {
+				return fmt.Errorf("%w: group session is full", ErrConflict)
+			}
+			appointment := session.ParticipantAppointment(uuid.New().String(), participant.PatientID, participant.UpdatedBy)
+			if err := insertAppointment(ctx, tx, appointment); err != nil {
+				return err
+			}
+			participant.AppointmentID = &appointment.ID
+			session.BookedCount++
+		case next.HoldsSeat() && !prev.HoldsSeat():
+			return fmt.Errorf("%w: only booked participants can be marked attended or no-show", ErrInvalidInput)
+		case prev.HoldsSeat() && participant.AppointmentID != nil:
+			if err := setAppointmentStatus(ctx, tx, *participant.AppointmentID, next.AppointmentStatus(), reason, participant.UpdatedBy); err != nil {
+				return err
+			}
+		}
+	}
+	if next != model.ParticipantStatusWaitlisted {
+		participant.WaitlistPosition = nil
+	}
+
+	query := `
+		UPDATE group_session_participants SET
+			appointment_id = $1, status = $2, waitlist_position = $3,
+			notes = $4, updated_by = $5
+		WHERE id = $6
+		RETURNING updated_at`
+
+	err := tx.QueryRowContext(ctx, query,
+		NullableString(participant.AppointmentID),
+		next,
+		nullableInt(participant.WaitlistPosition),
+		NullableStringValue(participant.Notes),
+		NullableString(participant.UpdatedBy),
+		participant.ID,
+	).Scan(&participant.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update participant: %w", err)
+	}
+
+	if prev.HoldsSeat() && !next.HoldsSeat() {
+		session.BookedCount--
+		if err := promoteWaitlist(ctx, tx, session, participant.UpdatedBy); err != nil {
+			return err
+		}
+	}
+	return renumberWaitlist(ctx, tx, session.ID)
+}
+
+// placeParticipant decides where a new participant of the locked session goes:
+// booked while there are places left, then at the end of the waitlist, or
+// nowhere when the waitlist is not allowed.
+func placeParticipant(session *model.GroupSession, allowWaitlist bool) (model.ParticipantStatus, *int, error) {
+	switch {
+	case session.SeatsLeft() > 0:
+		return model.ParticipantStatusBooked, nil, nil
+	case allowWaitlist:
+		position := session.WaitlistCount + 1
+		return model.ParticipantStatusWaitlisted, &position, nil
+	default:
+		return "", nil, fmt.Errorf("%w: group session is full", ErrConflict)
+	}
+}
+
+// waitlistPromotions returns how many patients can be booked from the
+// waitlist of the locked session. Sessions that have started or been
+// cancelled keep their waitlist as it is.
+func waitlistPromotions(session *model.GroupSession, now time.Time) int {
+	if session.Status != model.GroupSessionStatusScheduled || !session.StartTime.After(now) {
+		return 0
+	}
+	return session.SeatsLeft()
+}
+
+// promoteWaitlist books patients from the front of the waitlist into the
+// places left in the locked session.
+func promoteWaitlist(ctx context.Context, tx *Tx, session *model.GroupSession, updatedBy *string) error {
+	seats := waitlistPromotions(session, time.Now())
+	if seats == 0 {
+		return nil
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, patient_id
+		FROM group_session_participants
+		WHERE session_id = $1 AND status = 'waitlisted'
+		ORDER BY waitlist_position, created_at
+		LIMIT $2`,
+		session.ID, seats,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to get waitlist: %w", err)
+	}
+	type waiting struct{ id, patientID string }
+	var promoted []waiting
+	for rows.Next() {
+		var w waiting
+		if err := rows.Scan(&w.id, &w.patientID); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan waitlist: %w", err)
+		}
+		promoted = append(promoted, w)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read waitlist: %w", err)
+	}
+
+	for _, w := range promoted {
+		appointment := session.ParticipantAppointment(uuid.New().String(), w.patientID, updatedBy)
+		if err := insertAppointment(ctx, tx, appointment); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, `
+			UPDATE group_session_participants SET
+				status = 'booked', appointment_id = $1, waitlist_position = NULL, updated_by = $2
+			WHERE id = $3`,
+			appointment.ID, NullableString(updatedBy), w.id,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to book from waitlist: %w", err)
+		}
+		session.BookedCount++
+	}
+
+	return nil
+}
+
+// renumberWaitlist closes the gaps left in a session's waitlist positions.
+func renumberWaitlist(ctx context.Context, tx *Tx, sessionID string) error {
+	query := `
+		UPDATE group_session_participants gp SET waitlist_position = w.position
+		FROM (
+			SELECT id, ROW_NUMBER() OVER (ORDER BY waitlist_position, created_at) AS position
+			FROM group_session_participants
+			WHERE session_id = $1 AND status = 'waitlisted'
+		) w
+		WHERE gp.id = w.id AND gp.waitlist_position IS DISTINCT FROM w.position`
+
+	if _, err := tx.ExecContext(ctx, query, sessionID); err != nil {
+		return fmt.Errorf("failed to renumber waitlist: %w", err)
+	}
+	return nil
+}
+
+// setAppointmentStatus sets the status of a participant's appointment.
+func setAppointmentStatus(ctx context.Context, q Querier, appointmentID string, status model.AppointmentStatus, reason string, updatedBy *string) error {
+	query := `
+		UPDATE appointments SET
+			status = $1,
+			cancellation_reason = CASE WHEN $1 = 'cancelled' THEN $2 ELSE cancellation_reason END,
+			updated_by = $3
+		WHERE id = $4`
+
+	if _, err := q.ExecContext(ctx, query, status, NullableStringValue(reason), NullableString(updatedBy), appointmentID); err != nil {
+		return fmt.Errorf("failed to update participant appointment: %w", err)
+	}
+	return nil
+}
+
+// findSessionConflicts finds a therapist's scheduled group sessions
+// overlapping the given time range using the given connection or transaction.
+func findSessionConflicts(ctx context.Context, q Querier, clinicID, therapistID string, start, end time.Time, excludeID string) ([]model.GroupSession, error) {
+	query := groupSessionSelect + `
+		WHERE gs.clinic_id = $1
+			AND gs.therapist_id = $2
+			AND gs.status = 'scheduled'
+			AND gs.start_time < $4
+			AND gs.end_time > $3
+			AND ($5 = '' OR gs.id::text != $5)`
+
+	return querySessions(ctx, q, query, clinicID, therapistID, start, end, excludeID)
+}
+
+// findSessionResourceBookings finds scheduled group sessions requiring any of
+// the given resources within the time range using the given connection or
+// transaction.
+func findSessionResourceBookings(ctx context.Context, q Querier, clinicID string, resourceIDs []string, start, end time.Time, excludeIDs []string) ([]model.GroupSession, error) {
+	query := groupSessionSelect + `
+		WHERE gs.clinic_id = $1
+			AND gs.resource_ids && $2::uuid[]
+			AND gs.status = 'scheduled'
+			AND gs.start_time < $4
+			AND gs.end_time > $3
+			AND NOT (gs.id = ANY($5::uuid[]))`
+
+	return querySessions(ctx, q, query, clinicID, pq.Array(resourceIDs), start, end, pq.Array(nonNilStrings(excludeIDs)))
+}
+
+// querySessions runs a query selecting groupSessionSelect rows.
+func querySessions(ctx context.Context, q Querier, query string, args ...interface{}) ([]model.GroupSession, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query group sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := make([]model.GroupSession, 0)
+	for rows.Next() {
+		gs, err := scanGroupSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan group session: %w", err)
+		}
+		sessions = append(sessions, *gs)
+	}
+
+	return sessions, rows.Err()
+}
+
+// scanGroupSession scans a row selected with groupSessionSelect.
+func scanGroupSession(row rowScanner) (*model.GroupSession, error) {
+	var gs model.GroupSession
+	var appointmentTypeID, room, notes, cancellationReason sql.NullString
+	var createdBy, updatedBy sql.NullString
+
+	err := row.Scan(
+		&gs.ID,
+		&gs.ClinicID,
+		&gs.TherapistID,
+		&appointmentTypeID,
+		&gs.Type,
+		&gs.Title,
+		&gs.StartTime,
+		&gs.EndTime,
+		&gs.Duration,
+		&gs.Capacity,
+		&room,
+		pq.Array(&gs.ResourceIDs),
+		&gs.Status,
+		&notes,
+		&cancellationReason,
+		&gs.CreatedAt,
+		&gs.UpdatedAt,
+		&createdBy,
+		&updatedBy,
+		&gs.TherapistName,
+		&gs.BookedCount,
+		&gs.WaitlistCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	gs.AppointmentTypeID = StringPtrFromNull(appointmentTypeID)
+	gs.Room = StringFromNull(room)
+	gs.Notes = StringFromNull(notes)
+	gs.CancellationReason = StringFromNull(cancellationReason)
+	gs.CreatedBy = StringPtrFromNull(createdBy)
+	gs.UpdatedBy = StringPtrFromNull(updatedBy)
+
+	return &gs, nil
+}
+
+// scanParticipant scans a row selected with participantSelect.
+func scanParticipant(row rowScanner) (*model.GroupParticipant, error) {
+	var p model.GroupParticipant
+	var appointmentID, notes, createdBy, updatedBy sql.NullString
+	var waitlistPosition sql.NullInt64
+
+	err := row.Scan(
+		&p.ID,
+		&p.SessionID,
+		&p.PatientID,
+		&appointmentID,
+		&p.Status,
+		&waitlistPosition,
+		&notes,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+		&createdBy,
+		&updatedBy,
+		&p.PatientName,
+		&p.PatientMRN,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	p.AppointmentID = StringPtrFromNull(appointmentID)
+	if waitlistPosition.Valid {
+		position := int(waitlistPosition.Int64)
+		p.WaitlistPosition = &position
+	}
+	p.Notes = StringFromNull(notes)
+	p.CreatedBy = StringPtrFromNull(createdBy)
+	p.UpdatedBy = StringPtrFromNull(updatedBy)
+
+	return &p, nil
+}
+
+// nullableInt converts an optional int to a nullable query argument.
+func nullableInt(i *int) interface{} {
+	if i == nil {
+		return nil
+	}
+	return *i
+}
+
+// mockGroupSessionRepo provides a mock implementation for development.
+type mockGroupSessionRepo struct{}
+
+func (r *mockGroupSessionRepo) List(ctx context.Context, params model.GroupSessionSearchParams) ([]model.GroupSession, error) {
+	return []model.GroupSession{}, nil
+}
+
+func (r *mockGroupSessionRepo) GetByID(ctx context.Context, clinicID, id string) (*model.GroupSession, error) {
+	return nil, ErrNotFound
+}
+
+func (r *mockGroupSessionRepo) ListParticipants(ctx context.Context, sessionID string) ([]model.GroupParticipant, error) {
+	return []model.GroupParticipant{}, nil
+}
+
+func (r *mockGroupSessionRepo) GetParticipant(ctx context.Context, sessionID, id string) (*model.GroupParticipant, error) {
+	return nil, ErrNotFound
+}
+
+func (r *mockGroupSessionRepo) Create(ctx context.Context, session *model.GroupSession) error {
+	session.CreatedAt = time.Now()
+	session.UpdatedAt = session.CreatedAt
+	return nil
+}
+
+func (r *mockGroupSessionRepo) Update(ctx context.Context, session *model.GroupSession) error {
+	return ErrNotFound
+}
+
+func (r *mockGroupSessionRepo) Cancel(ctx context.Context, session *model.GroupSession) error {
+	return ErrNotFound
+}
+
+func (r *mockGroupSessionRepo) AddParticipant(ctx context.Context, session *model.GroupSession, participant *model.GroupParticipant, allowWaitlist bool) error {
+	return ErrNotFound
+}
+
+func (r *mockGroupSessionRepo) UpdateParticipant(ctx context.Context, session *model.GroupSession, participant *model.GroupParticipant) error {
+	return ErrNotFound
+}
+
+func (r *mockGroupSessionRepo) CancelParticipantByAppointment(ctx context.Context, clinicID, appointmentID, reason string, updatedBy *string) error {
+	return ErrNotFound
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+)
+
+func TestPlaceParticipant(t *testing.T) {
+	tests := []struct {
+		name          string
+		booked        int
+		waitlisted    int
+		allowWaitlist bool
+		wantStatus    model.ParticipantStatus
+		wantPosition  int // 0 for none
+		wantErr       error
+	}{
+		{"places left", 3, 0, true, model.ParticipantStatusBooked, 0, nil},
+		{"full session joins the waitlist", 4, 0, true, model.ParticipantStatusWaitlisted, 1, nil},
+		{"full session joins the end of the waitlist", 4, 2, true, model.ParticipantStatusWaitlisted, 3, nil},
+		{"full session without the waitlist", 4, 2, false, "", 0, ErrConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session := &model.GroupSession{Capacity: 4, BookedCount: tt.booked, WaitlistCount: tt.waitlisted}
+
+			status, position, err := placeParticipant(session, tt.allowWaitlist)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", status, tt.wantStatus)
+			}
+			switch {
+			case tt.wantPosition == 0 && position != nil:
+				t.Errorf("position = %d, want none", *position)
+			case tt.wantPosition != 0 && (position == nil || *position != tt.wantPosition):
+				t.Errorf("position = %v, want %d", position, tt.wantPosition)
+			}
+		})
+	}
+}
+
+func TestWaitlistPromotions(t *testing.T) {
+	now := time.Date(2026, 3, 20, 9, 0, 0, 0, time.UTC)
+	upcoming := now.Add(24 * time.Hour)
+
+	tests := []struct {
+		name     string
+		session  model.GroupSession
+		wantSeat int
+	}{
+		{
+			name:     "full session",
+			session:  model.GroupSession{Status: model.GroupSessionStatusScheduled, StartTime: upcoming, Capacity: 4, BookedCount: 4},
+			wantSeat: 0,
+		},
+		{
+			// changeParticipant gives the place back before promoting
+			name:     "a booked participant cancels",
+			session:  model.GroupSession{Status: model.GroupSessionStatusScheduled, StartTime: upcoming, Capacity: 4, BookedCount: 3},
+			wantSeat: 1,
+		},
+		{
+			name:     "capacity raised from 4 to 6",
+			session:  model.GroupSession{Status: model.GroupSessionStatusScheduled, StartTime: upcoming, Capacity: 6, BookedCount: 4},
+			wantSeat: 2,
+		},
+		{
+			name:     "session has started",
+			session:  model.GroupSession{Status: model.GroupSessionStatusScheduled, StartTime: now, Capacity: 6, BookedCount: 4},
+			wantSeat: 0,
+		},
+		{
+			name:     "session is cancelled",
+			session:  model.GroupSession{Status: model.GroupSessionStatusCancelled, StartTime: upcoming, Capacity: 6, BookedCount: 0},
+			wantSeat: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := waitlistPromotions(&tt.session, now); got != tt.wantSeat {
+				t.Errorf("waitlistPromotions = %d, want %d", got, tt.wantSeat)
+			}
+		})
+	}
+}
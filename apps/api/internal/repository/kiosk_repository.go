@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+)
+
+// KioskRepository defines the interface for kiosk device data access.
+type KioskRepository interface {
+	Create(ctx context.Context, device *model.KioskDevice) error
+	GetByTokenHash(ctx context.Context, tokenHash string) (*model.KioskDevice, error)
+	List(ctx context.Context, clinicID string) ([]model.KioskDevice, error)
+	Revoke(ctx context.Context, clinicID, id, revokedBy string, at time.Time) error
+	Touch(ctx context.Context, id string, at time.Time) error
+}
+
+// postgresKioskRepo implements KioskRepository with PostgreSQL.
+type postgresKioskRepo struct {
+	db *DB
+}
+
+// NewKioskRepository creates a new PostgreSQL kiosk device repository.
+func NewKioskRepository(db *DB) KioskRepository {
+	return &postgresKioskRepo{db: db}
+}
+
+// kioskDeviceColumns lists the columns read by scanKioskDevice.
+const kioskDeviceColumns = `
+	id, clinic_id, name, token_hash, revoked_at, revoked_by,
+	created_by, last_seen_at, created_at`
+
+// Create inserts a new kiosk device.
+func (r *postgresKioskRepo) Create(ctx context.Context, device *model.KioskDevice) error {
+	query := `
+		INSERT INTO kiosk_devices (
+			id, clinic_id, name, token_hash, created_by
+		) VALUES (
+			$1, $2, $3, $4, $5
+		)
+		RETURNING created_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		device.ID,
+		device.ClinicID,
+		device.Name,
+		device.TokenHash,
+		device.CreatedBy,
+	).Scan(&device.CreatedAt)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			return fmt.Errorf("%w: clinic does not exist", ErrInvalidInput)
+		}
+		return fmt.Errorf("failed to create kiosk device: %w", err)
+	}
+	return nil
+}
+
+// GetByTokenHash retrieves a device by the hash of its token, whether or not
+// it has been revoked.
+func (r *postgresKioskRepo) GetByTokenHash(ctx context.Context, tokenHash string) (*model.KioskDevice, error) {
+	query := `
+		SELECT ` + kioskDeviceColumns + `
+		FROM kiosk_devices
+		WHERE token_hash = $1`
+
+	device, err := scanKioskDevice(r.db.QueryRowContext(ctx, query, tokenHash))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kiosk device: %w", err)
+	}
+	return device, nil
+}
+
+// List retrieves a clinic's devices, newest first, including revoked ones.
+func (r *postgresKioskRepo) List(ctx context.Context, clinicID string) ([]model.KioskDevice, error) {
+	query := `
+		SELECT ` + kioskDeviceColumns + `
+		FROM kiosk_devices
+		WHERE clinic_id = $1
+		ORDER BY created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, clinicID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list kiosk devices: %w", err)
+	}
+	defer rows.Close()
+
+	devices := []model.KioskDevice{}
+	for rows.Next() {
+		device, err := scanKioskDevice(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan kiosk device: %w", err)
+		}
+		devices = append(devices, *device)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate kiosk devices: %w", err)
+	}
+
+	return devices, nil
+}
+
+// Revoke marks one of a clinic's devices as revoked.
+func (r *postgresKioskRepo) Revoke(ctx context.Context, clinicID, id, revokedBy string, at time.Time) error {
+	query := `
+		UPDATE kiosk_devices
+		SET revoked_at = $3, revoked_by = $4
+		WHERE id = $1 AND clinic_id = $2 AND revoked_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, id, clinicID, at, revokedBy)
+	if err != nil {
+		return fmt.Errorf("failed to revoke kiosk device: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// Touch records when a device was last used.
+func (r *postgresKioskRepo) Touch(ctx context.Context, id string, at time.Time) error {
+	query := `UPDATE kiosk_devices SET last_seen_at = $2 WHERE id = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, id, at); err != nil {
+		return fmt.Errorf("failed to update kiosk device last seen time: %w", err)
+	}
+	return nil
+}
+
+// scanKioskDevice scans a kiosk device row.
+func scanKioskDevice(row rowScanner) (*model.KioskDevice, error) {
+	var d model.KioskDevice
+	var revokedBy sql.NullString
+	var revokedAt, lastSeenAt sql.NullTime
+
+	err := row.Scan(
+		&d.ID,
+		&d.ClinicID,
+		&d.Name,
+		&d.TokenHash,
+		&revokedAt,
+		&revokedBy,
+		&d.CreatedBy,
+		&lastSeenAt,
+		&d.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if revokedAt.Valid {
+		d.RevokedAt = &revokedAt.Time
+	}
+	d.RevokedBy = StringPtrFromNull(revokedBy)
+	if lastSeenAt.Valid {
+		d.LastSeenAt = &lastSeenAt.Time
+	}
+
+	return &d, nil
+}
+
+// =============================================================================
+// MOCK IMPLEMENTATION
+// =============================================================================
+
+// mockKioskRepo provides a mock implementation for development.
+type mockKioskRepo struct{}
+
+func (r *mockKioskRepo) Create(ctx context.Context, device *model.KioskDevice) error {
+	device.CreatedAt = time.Now()
+	return nil
+}
+
+func (r *mockKioskRepo) GetByTokenHash(ctx context.Context, tokenHash string) (*model.KioskDevice, error) {
+	return nil, ErrNotFound
+}
+
+func (r *mockKioskRepo) List(ctx context.Context, clinicID string) ([]model.KioskDevice, error) {
+	return []model.KioskDevice{}, nil
+}
+
+func (r *mockKioskRepo) Revoke(ctx context.Context, clinicID, id, revokedBy string, at time.Time) error {
+	return ErrNotFound
+}
+
+func (r *mockKioskRepo) Touch(ctx context.Context, id string, at time.Time) error {
+	return nil
+}
@@ -19,6 +19,7 @@ type PatientRepository interface {
 	Create(ctx context.Context, patient *model.Patient) error
 	GetByID(ctx context.Context, clinicID, id string) (*model.Patient, error)
 	GetByMRN(ctx context.Context, clinicID, mrn string) (*model.Patient, error)
+	GetByKeycloakID(ctx context.Context, keycloakID string) (*model.Patient, error)
 	Update(ctx context.Context, patient *model.Patient) error
 	Delete(ctx context.Context, clinicID, id string) error
 	List(ctx context.Context, params model.PatientSearchParams) ([]model.Patient, int64, error)
@@ -123,6 +124,20 @@ func (r *postgresPatientRepo) GetByMRN(ctx context.Context, clinicID, mrn string
 	return r.scanPatient(r.db.QueryRowContext(ctx, query, mrn, clinicID))
 }
 
+// GetByKeycloakID retrieves the patient linked to a portal account.
+func (r *postgresPatientRepo) GetByKeycloakID(ctx context.Context, keycloakID string) (*model.Patient, error) {
+	query := `
+		SELECT
+			id, clinic_id, mrn, first_name, last_name, first_name_vi, last_name_vi,
+			date_of_birth, gender, phone, email, address, address_vi,
+			language_preference, emergency_contact, medical_alerts, notes,
+			is_active, created_at, updated_at, created_by, updated_by
+		FROM patients
+		WHERE keycloak_id::text = $1 AND is_active = true`
+
+	return r.scanPatient(r.db.QueryRowContext(ctx, query, keycloakID))
+}
+
 // scanPatient scans a patient row into a Patient struct.
 func (r *postgresPatientRepo) scanPatient(row *sql.Row) (*model.Patient, error) {
 	var p model.Patient
@@ -718,6 +733,32 @@ func (r *postgresPatientRepo) GetDashboard(ctx context.Context, clinicID, patien
 		dashboard.InsuranceInfo = insurance
 	}
 
+	// Get the most recent generated visit notes
+	notesQuery := `
+		SELECT vc.id, COALESCE(t.template_type::text, ''), vc.generated_note,
+			COALESCE(vc.completed_at, vc.updated_at), COALESCE(vc.therapist_id::text, '')
+		FROM visit_checklists vc
+		LEFT JOIN checklist_templates t ON vc.template_id = t.id
+		WHERE vc.patient_id = $1 AND vc.clinic_id = $2 AND vc.generated_note IS NOT NULL
+		ORDER BY COALESCE(vc.completed_at, vc.updated_at) DESC
+		LIMIT 5`
+
+	rows, err := r.db.QueryContext(ctx, notesQuery, patientID, clinicID)
+	if err != nil {
+		log.Warn().Err(err).Str("patient_id", patientID).Msg("failed to get recent notes")
+		return dashboard, nil
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var note model.PatientNote
+		if err := rows.Scan(&note.ID, &note.Type, &note.Summary, &note.CreatedAt, &note.CreatedBy); err != nil {
+			log.Warn().Err(err).Str("patient_id", patientID).Msg("failed to scan recent note")
+			break
+		}
+		dashboard.RecentNotes = append(dashboard.RecentNotes, note)
+	}
+
 	return dashboard, nil
 }
 
@@ -184,6 +184,14 @@ func NullableStringValue(s string) sql.NullString {
 	return sql.NullString{String: s, Valid: true}
 }
 
+// NullableBool returns a sql.NullBool from a bool pointer.
+func NullableBool(b *bool) sql.NullBool {
+	if b == nil {
+		return sql.NullBool{}
+	}
+	return sql.NullBool{Bool: *b, Valid: true}
+}
+
 // StringFromNull returns a string from sql.NullString.
 func StringFromNull(ns sql.NullString) string {
 	if ns.Valid {
@@ -192,6 +200,14 @@ func StringFromNull(ns sql.NullString) string {
 	return ""
 }
 
+// BoolPtrFromNull returns a bool pointer from sql.NullBool.
+func BoolPtrFromNull(nb sql.NullBool) *bool {
+	if nb.Valid {
+		return &nb.Bool
+	}
+	return nil
+}
+
 // StringPtrFromNull returns a string pointer from sql.NullString.
 func StringPtrFromNull(ns sql.NullString) *string {
 	if ns.Valid {
@@ -0,0 +1,485 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+)
+
+// ProtocolRepository defines the interface for exercise protocol templates and
+// the phases of programs created from them.
+type ProtocolRepository interface {
+	Create(ctx context.Context, protocol *model.ExerciseProtocol) error
+	GetByID(ctx context.Context, clinicID, id string) (*model.ExerciseProtocol, error)
+	List(ctx context.Context, clinicID string, includeInactive bool) ([]model.ExerciseProtocol, error)
+	Update(ctx context.Context, protocol *model.ExerciseProtocol) error
+	CreateProgram(ctx context.Context, program *model.HomeExerciseProgram, phases []model.ProgramPhase) error
+	ListProgramPhases(ctx context.Context, programID string) ([]model.ProgramPhase, error)
+	ListProgramsToAdvance(ctx context.Context, through time.Time) ([]string, error)
+	AdvanceProgram(ctx context.Context, advance *ProgramAdvance) error
+}
+
+// ProgramAdvance moves a program created from a protocol through its phases.
+type ProgramAdvance struct {
+	ProgramID string
+	// Complete lists phases that have ended; their open prescriptions are
+	// completed along with them.
+	Complete []string
+	// Activate is the phase that has started, if any, and Prescriptions are
+	// created for it.
+	Activate      string
+	Prescriptions []model.ExercisePrescription
+	// Finish deactivates the program after its last phase.
+	Finish bool
+	At     time.Time
+}
+
+// postgresProtocolRepo implements ProtocolRepository with PostgreSQL.
+type postgresProtocolRepo struct {
+	db *DB
+}
+
+// NewProtocolRepository creates a new PostgreSQL protocol repository.
+func NewProtocolRepository(db *DB) ProtocolRepository {
+	return &postgresProtocolRepo{db: db}
+}
+
+// protocolColumns lists the columns read by scanProtocol.
+const protocolColumns = `
+	id, clinic_id, name, name_vi, description, description_vi, frequency,
+	phases, version, is_active, created_by, created_at, updated_at`
+
+// programPhaseColumns lists the columns read by scanProgramPhase.
+const programPhaseColumns = `
+	id, program_id, clinic_id, protocol_id, protocol_version, phase_number,
+	name, name_vi, start_date, end_date, exercises, prescription_ids, status,
+	activated_at, completed_at`
+
+// Create inserts a new protocol.
+func (r *postgresProtocolRepo) Create(ctx context.Context, protocol *model.ExerciseProtocol) error {
+	phases, err := json.Marshal(protocol.Phases)
+	if err != nil {
+		return fmt.Errorf("failed to encode protocol phases: %w", err)
+	}
+
+	query := `
+		INSERT INTO exercise_protocols (
+			id, clinic_id, name, name_vi, description, description_vi,
+			frequency, phases, is_active, created_by
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+		)
+		RETURNING version, created_at, updated_at`
+
+	err = r.db.QueryRowContext(ctx, query,
+		protocol.ID,
+		protocol.ClinicID,
+		protocol.Name,
+		NullableStringValue(protocol.NameVi),
+		NullableStringValue(protocol.Description),
+		NullableStringValue(protocol.DescriptionVi),
+		protocol.Frequency,
+		phases,
+		protocol.IsActive,
+		NullableString(protocol.CreatedBy),
+	).Scan(&protocol.Version, &protocol.CreatedAt, &protocol.UpdatedAt)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			return fmt.Errorf("%w: clinic or user does not exist", ErrInvalidInput)
+		}
+		return fmt.Errorf("failed to create protocol: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves one of a clinic's protocols.
+func (r *postgresProtocolRepo) GetByID(ctx context.Context, clinicID, id string) (*model.ExerciseProtocol, error) {
+	query := `
+		SELECT ` + protocolColumns + `
+		FROM exercise_protocols
+		WHERE id = $1 AND clinic_id = $2`
+
+	protocol, err := scanProtocol(r.db.QueryRowContext(ctx, query, id, clinicID))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get protocol: %w", err)
+	}
+	return protocol, nil
+}
+
+// List retrieves a clinic's protocols by name.
+func (r *postgresProtocolRepo) List(ctx context.Context, clinicID string, includeInactive bool) ([]model.ExerciseProtocol, error) {
+	query := `
+		SELECT ` + protocolColumns + `
+		FROM exercise_protocols
+		WHERE clinic_id = $1 AND (is_active OR $2)
+		ORDER BY name`
+
+	rows, err := r.db.QueryContext(ctx, query, clinicID, includeInactive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list protocols: %w", err)
+	}
+	defer rows.Close()
+
+	protocols := []model.ExerciseProtocol{}
+	for rows.Next() {
+		protocol, err := scanProtocol(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan protocol: %w", err)
+		}
+		protocols = append(protocols, *protocol)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate protocols: %w", err)
+	}
+
+	return protocols, nil
+}
+
+// Update saves a protocol as its next version.
+func (r *postgresProtocolRepo) Update(ctx context.Context, protocol *model.ExerciseProtocol) error {
+	phases, err := json.Marshal(protocol.Phases)
+	if err != nil {
+		return fmt.Errorf("failed to encode protocol phases: %w", err)
+	}
+
+	query := `
+		UPDATE exercise_protocols SET
+			name = $3,
+			name_vi = $4,
+			description = $5,
+			description_vi = $6,
+			frequency = $7,
+			phases = $8,
+			is_active = $9,
+			version = version + 1
+		WHERE id = $1 AND clinic_id = $2
+		RETURNING version, updated_at`
+
+	err = r.db.QueryRowContext(ctx, query,
+		protocol.ID,
+		protocol.ClinicID,
+		protocol.Name,
+		NullableStringValue(protocol.NameVi),
+		NullableStringValue(protocol.Description),
+		NullableStringValue(protocol.DescriptionVi),
+		protocol.Frequency,
+		phases,
+		protocol.IsActive,
+	).Scan(&protocol.Version, &protocol.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update protocol: %w", err)
+	}
+	return nil
+}
+
+// CreateProgram inserts a program and its phases in one transaction.
+func (r *postgresProtocolRepo) CreateProgram(ctx context.Context, program *model.HomeExerciseProgram, phases []model.ProgramPhase) error {
+	return r.db.WithTx(ctx, func(tx *Tx) error {
+		if err := insertProgram(ctx, tx, program); err != nil {
+			return err
+		}
+
+		query := `
+			INSERT INTO home_exercise_program_phases (
+				id, program_id, clinic_id, protocol_id, protocol_version, phase_number,
+				name, name_vi, start_date, end_date, exercises, status
+			) VALUES (
+				$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+			)`
+
+		for _, phase := range phases {
+			exercises, err := json.Marshal(phase.Exercises)
+			if err != nil {
+				return fmt.Errorf("failed to encode phase exercises: %w", err)
+			}
+
+			_, err = tx.ExecContext(ctx, query,
+				phase.ID,
+				phase.ProgramID,
+				phase.ClinicID,
+				NullableString(phase.ProtocolID),
+				phase.ProtocolVersion,
+				phase.PhaseNumber,
+				phase.Name,
+				NullableStringValue(phase.NameVi),
+				phase.StartDate,
+				phase.EndDate,
+				exercises,
+				phase.Status,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to create program phase: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// ListProgramPhases retrieves a program's phases in order.
+func (r *postgresProtocolRepo) ListProgramPhases(ctx context.Context, programID string) ([]model.ProgramPhase, error) {
+	query := `
+		SELECT ` + programPhaseColumns + `
+		FROM home_exercise_program_phases
+		WHERE program_id = $1
+		ORDER BY phase_number`
+
+	rows, err := r.db.QueryContext(ctx, query, programID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list program phases: %w", err)
+	}
+	defer rows.Close()
+
+	phases := []model.ProgramPhase{}
+	for rows.Next() {
+		phase, err := scanProgramPhase(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan program phase: %w", err)
+		}
+		phases = append(phases, *phase)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate program phases: %w", err)
+	}
+
+	return phases, nil
+}
+
+// ListProgramsToAdvance retrieves the programs with a phase starting or
+// ending on or before the date of through. Callers pass a date at least as
+// late as today in every clinic and check each program in its clinic's time
+// zone.
+func (r *postgresProtocolRepo) ListProgramsToAdvance(ctx context.Context, through time.Time) ([]string, error) {
+	query := `
+		SELECT DISTINCT program_id
+		FROM home_exercise_program_phases
+		WHERE (status = 'upcoming' AND start_date <= $1::date)
+			OR (status = 'active' AND end_date <= $1::date)`
+
+	rows, err := r.db.QueryContext(ctx, query, through.Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list programs to advance: %w", err)
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan program id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate programs to advance: %w", err)
+	}
+
+	return ids, nil
+}
+
+// AdvanceProgram applies a step of a program through its phases in one
+// transaction. Returns ErrConflict if the phase to activate was already
+// started, e.g. by another instance.
+func (r *postgresProtocolRepo) AdvanceProgram(ctx context.Context, advance *ProgramAdvance) error {
+	return r.db.WithTx(ctx, func(tx *Tx) error {
+		for _, phaseID := range advance.Complete {
+			var prescriptionIDs []string
+			err := tx.QueryRowContext(ctx, `
+				UPDATE home_exercise_program_phases
+				SET status = 'completed', completed_at = $3
+				WHERE id = $1 AND program_id = $2 AND status <> 'completed'
+				RETURNING prescription_ids`,
+				phaseID, advance.ProgramID, advance.At,
+			).Scan(pq.Array(&prescriptionIDs))
+			if err == sql.ErrNoRows {
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("failed to complete program phase: %w", err)
+			}
+
+			_, err = tx.ExecContext(ctx, `
+				UPDATE exercise_prescriptions
+				SET status = 'completed'
+				WHERE id = ANY($1) AND status IN ('active', 'paused')`,
+				pq.Array(prescriptionIDs),
+			)
+			if err != nil {
+				return fmt.Errorf("failed to complete phase prescriptions: %w", err)
+			}
+		}
+
+		if advance.Activate != "" {
+			ids := make([]string, len(advance.Prescriptions))
+			for i := range advance.Prescriptions {
+				if err := insertPrescription(ctx, tx, &advance.Prescriptions[i]); err != nil {
+					return err
+				}
+				ids[i] = advance.Prescriptions[i].ID
+			}
+
+			result, err := tx.ExecContext(ctx, `
+				UPDATE home_exercise_program_phases
+				SET status = 'active', activated_at = $3, prescription_ids = $4
+				WHERE id = $1 AND program_id = $2 AND status = 'upcoming'`,
+				advance.Activate, advance.ProgramID, advance.At, pq.Array(ids),
+			)
+			if err != nil {
+				return fmt.Errorf("failed to activate program phase: %w", err)
+			}
+
+			rowsAffected, err := result.RowsAffected()
+			if err != nil {
+				return fmt.Errorf("failed to get rows affected: %w", err)
+			}
+			if rowsAffected == 0 {
+				return ErrConflict
+			}
+		}
+
+		if advance.Finish {
+			_, err := tx.ExecContext(ctx, `
+				UPDATE home_exercise_programs SET is_active = false WHERE id = $1`,
+				advance.ProgramID,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to finish program: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// scanProtocol scans a protocol row.
+func scanProtocol(row rowScanner) (*model.ExerciseProtocol, error) {
+	var p model.ExerciseProtocol
+	var nameVi, description, descriptionVi, createdBy sql.NullString
+	var phases []byte
+
+	err := row.Scan(
+		&p.ID,
+		&p.ClinicID,
+		&p.Name,
+		&nameVi,
+		&description,
+		&descriptionVi,
+		&p.Frequency,
+		&phases,
+		&p.Version,
+		&p.IsActive,
+		&createdBy,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	p.NameVi = StringFromNull(nameVi)
+	p.Description = StringFromNull(description)
+	p.DescriptionVi = StringFromNull(descriptionVi)
+	p.CreatedBy = StringPtrFromNull(createdBy)
+	if err := json.Unmarshal(phases, &p.Phases); err != nil {
+		return nil, fmt.Errorf("failed to parse protocol phases: %w", err)
+	}
+
+	return &p, nil
+}
+
+// scanProgramPhase scans a program phase row.
+func scanProgramPhase(row rowScanner) (*model.ProgramPhase, error) {
+	var p model.ProgramPhase
+	var protocolID, nameVi sql.NullString
+	var activatedAt, completedAt sql.NullTime
+	var exercises []byte
+
+	err := row.Scan(
+		&p.ID,
+		&p.ProgramID,
+		&p.ClinicID,
+		&protocolID,
+		&p.ProtocolVersion,
+		&p.PhaseNumber,
+		&p.Name,
+		&nameVi,
+		&p.StartDate,
+		&p.EndDate,
+		&exercises,
+		pq.Array(&p.PrescriptionIDs),
+		&p.Status,
+		&activatedAt,
+		&completedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	p.ProtocolID = StringPtrFromNull(protocolID)
+	p.NameVi = StringFromNull(nameVi)
+	p.ActivatedAt = TimePtrFromNull(activatedAt)
+	p.CompletedAt = TimePtrFromNull(completedAt)
+	if p.PrescriptionIDs == nil {
+		p.PrescriptionIDs = []string{}
+	}
+	if err := json.Unmarshal(exercises, &p.Exercises); err != nil {
+		return nil, fmt.Errorf("failed to parse phase exercises: %w", err)
+	}
+
+	return &p, nil
+}
+
+// =============================================================================
+// MOCK IMPLEMENTATION
+// =============================================================================
+
+// mockProtocolRepo provides a mock implementation for development.
+type mockProtocolRepo struct{}
+
+func (r *mockProtocolRepo) Create(ctx context.Context, protocol *model.ExerciseProtocol) error {
+	protocol.Version = 1
+	protocol.CreatedAt = time.Now()
+	protocol.UpdatedAt = protocol.CreatedAt
+	return nil
+}
+
+func (r *mockProtocolRepo) GetByID(ctx context.Context, clinicID, id string) (*model.ExerciseProtocol, error) {
+	return nil, ErrNotFound
+}
+
+func (r *mockProtocolRepo) List(ctx context.Context, clinicID string, includeInactive bool) ([]model.ExerciseProtocol, error) {
+	return []model.ExerciseProtocol{}, nil
+}
+
+func (r *mockProtocolRepo) Update(ctx context.Context, protocol *model.ExerciseProtocol) error {
+	return ErrNotFound
+}
+
+func (r *mockProtocolRepo) CreateProgram(ctx context.Context, program *model.HomeExerciseProgram, phases []model.ProgramPhase) error {
+	return nil
+}
+
+func (r *mockProtocolRepo) ListProgramPhases(ctx context.Context, programID string) ([]model.ProgramPhase, error) {
+	return []model.ProgramPhase{}, nil
+}
+
+func (r *mockProtocolRepo) ListProgramsToAdvance(ctx context.Context, through time.Time) ([]string, error) {
+	return []string{}, nil
+}
+
+func (r *mockProtocolRepo) AdvanceProgram(ctx context.Context, advance *ProgramAdvance) error {
+	return nil
+}
@@ -0,0 +1,271 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+)
+
+// ProxyRepository defines the interface for caregiver proxy grant data access.
+type ProxyRepository interface {
+	Create(ctx context.Context, grant *model.ProxyGrant) error
+	GetByID(ctx context.Context, clinicID, id string) (*model.ProxyGrant, error)
+	GetActive(ctx context.Context, proxyUserID, patientID string, at time.Time) (*model.ProxyGrant, error)
+	ListByPatient(ctx context.Context, clinicID, patientID string) ([]model.ProxyGrant, error)
+	ListActiveByProxyUser(ctx context.Context, proxyUserID string, at time.Time) ([]model.ProxyGrant, error)
+	Revoke(ctx context.Context, clinicID, id, revokedBy string, at time.Time) error
+}
+
+// postgresProxyRepo implements ProxyRepository with PostgreSQL.
+type postgresProxyRepo struct {
+	db *DB
+}
+
+// NewProxyRepository creates a new PostgreSQL proxy grant repository.
+func NewProxyRepository(db *DB) ProxyRepository {
+	return &postgresProxyRepo{db: db}
+}
+
+// proxyGrantColumns lists the columns read by scanProxyGrant.
+const proxyGrantColumns = `
+	g.id, g.clinic_id, g.patient_id, g.proxy_user_id, g.proxy_name, g.relationship, g.phone,
+	g.scopes, g.starts_at, g.expires_at, g.revoked_at, g.revoked_by,
+	g.granted_by, g.granted_by_patient, g.created_at, g.updated_at,
+	COALESCE(p.first_name || ' ' || p.last_name, '') AS patient_name`
+
+// Create inserts a new proxy grant.
+func (r *postgresProxyRepo) Create(ctx context.Context, grant *model.ProxyGrant) error {
+	query := `
+		INSERT INTO patient_proxy_grants (
+			id, clinic_id, patient_id, proxy_user_id, proxy_name, relationship, phone,
+			scopes, starts_at, expires_at, granted_by, granted_by_patient
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+		)
+		RETURNING created_at, updated_at`
+
+	scopes := make([]string, len(grant.Scopes))
+	for i, s := range grant.Scopes {
+		scopes[i] = string(s)
+	}
+
+	err := r.db.QueryRowContext(ctx, query,
+		grant.ID,
+		grant.ClinicID,
+		grant.PatientID,
+		grant.ProxyUserID,
+		grant.ProxyName,
+		NullableStringValue(grant.Relationship),
+		NullableStringValue(grant.Phone),
+		pq.Array(scopes),
+		grant.StartsAt,
+		grant.ExpiresAt,
+		grant.GrantedBy,
+		grant.GrantedByPatient,
+	).Scan(&grant.CreatedAt, &grant.UpdatedAt)
+
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			return fmt.Errorf("%w: patient or clinic does not exist", ErrInvalidInput)
+		}
+		return fmt.Errorf("failed to create proxy grant: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a proxy grant by ID.
+func (r *postgresProxyRepo) GetByID(ctx context.Context, clinicID, id string) (*model.ProxyGrant, error) {
+	query := `
+		SELECT ` + proxyGrantColumns + `
+		FROM patient_proxy_grants g
+		LEFT JOIN patients p ON g.patient_id = p.id
+		WHERE g.id = $1 AND g.clinic_id = $2`
+
+	grant, err := scanProxyGrant(r.db.QueryRowContext(ctx, query, id, clinicID))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get proxy grant: %w", err)
+	}
+	return grant, nil
+}
+
+// GetActive retrieves the grant that lets a caregiver act for a patient at the given time.
+// When several grants overlap, the one expiring last is returned.
+func (r *postgresProxyRepo) GetActive(ctx context.Context, proxyUserID, patientID string, at time.Time) (*model.ProxyGrant, error) {
+	query := `
+		SELECT ` + proxyGrantColumns + `
+		FROM patient_proxy_grants g
+		JOIN patients p ON g.patient_id = p.id AND p.is_active = true
+		WHERE g.proxy_user_id::text = $1
+			AND g.patient_id = $2
+			AND g.revoked_at IS NULL
+			AND g.starts_at <= $3
+			AND g.expires_at > $3
+		ORDER BY g.expires_at DESC
+		LIMIT 1`
+
+	grant, err := scanProxyGrant(r.db.QueryRowContext(ctx, query, proxyUserID, patientID, at))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active proxy grant: %w", err)
+	}
+	return grant, nil
+}
+
+// ListByPatient retrieves all grants for a patient, including expired and revoked ones.
+func (r *postgresProxyRepo) ListByPatient(ctx context.Context, clinicID, patientID string) ([]model.ProxyGrant, error) {
+	query := `
+		SELECT ` + proxyGrantColumns + `
+		FROM patient_proxy_grants g
+		LEFT JOIN patients p ON g.patient_id = p.id
+		WHERE g.patient_id = $1 AND g.clinic_id = $2
+		ORDER BY g.created_at DESC`
+
+	return r.queryGrants(ctx, query, patientID, clinicID)
+}
+
+// ListActiveByProxyUser retrieves the grants a caregiver currently holds.
+func (r *postgresProxyRepo) ListActiveByProxyUser(ctx context.Context, proxyUserID string, at time.Time) ([]model.ProxyGrant, error) {
+	query := `
+		SELECT ` + proxyGrantColumns + `
+		FROM patient_proxy_grants g
+		JOIN patients p ON g.patient_id = p.id AND p.is_active = true
+		WHERE g.proxy_user_id::text = $1
+			AND g.revoked_at IS NULL
+			AND g.starts_at <= $2
+			AND g.expires_at > $2
+		ORDER BY p.last_name, p.first_name`
+
+	return r.queryGrants(ctx, query, proxyUserID, at)
+}
+
+// Revoke marks a grant as revoked.
+func (r *postgresProxyRepo) Revoke(ctx context.Context, clinicID, id, revokedBy string, at time.Time) error {
+	query := `
+		UPDATE patient_proxy_grants
+		SET revoked_at = $3, revoked_by = $4
+		WHERE id = $1 AND clinic_id = $2 AND revoked_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, id, clinicID, at, revokedBy)
+	if err != nil {
+		return fmt.Errorf("failed to revoke proxy grant: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// queryGrants runs a grant query and scans every row.
+func (r *postgresProxyRepo) queryGrants(ctx context.Context, query string, args ...interface{}) ([]model.ProxyGrant, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list proxy grants: %w", err)
+	}
+	defer rows.Close()
+
+	grants := []model.ProxyGrant{}
+	for rows.Next() {
+		grant, err := scanProxyGrant(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan proxy grant: %w", err)
+		}
+		grants = append(grants, *grant)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate proxy grants: %w", err)
+	}
+
+	return grants, nil
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanProxyGrant scans a row selected with proxyGrantColumns.
+func scanProxyGrant(row rowScanner) (*model.ProxyGrant, error) {
+	var g model.ProxyGrant
+	var relationship, phone, revokedBy sql.NullString
+	var revokedAt sql.NullTime
+	var scopes []string
+
+	err := row.Scan(
+		&g.ID,
+		&g.ClinicID,
+		&g.PatientID,
+		&g.ProxyUserID,
+		&g.ProxyName,
+		&relationship,
+		&phone,
+		pq.Array(&scopes),
+		&g.StartsAt,
+		&g.ExpiresAt,
+		&revokedAt,
+		&revokedBy,
+		&g.GrantedBy,
+		&g.GrantedByPatient,
+		&g.CreatedAt,
+		&g.UpdatedAt,
+		&g.PatientName,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	g.Relationship = StringFromNull(relationship)
+	g.Phone = StringFromNull(phone)
+	g.RevokedAt = TimePtrFromNull(revokedAt)
+	g.RevokedBy = StringPtrFromNull(revokedBy)
+	g.Scopes = make([]model.ProxyScope, len(scopes))
+	for i, s := range scopes {
+		g.Scopes[i] = model.ProxyScope(s)
+	}
+
+	return &g, nil
+}
+
+// mockProxyRepo provides a mock implementation for development.
+type mockProxyRepo struct{}
+
+func (r *mockProxyRepo) Create(ctx context.Context, grant *model.ProxyGrant) error {
+	return nil
+}
+
+func (r *mockProxyRepo) GetByID(ctx context.Context, clinicID, id string) (*model.ProxyGrant, error) {
+	return nil, ErrNotFound
+}
+
+func (r *mockProxyRepo) GetActive(ctx context.Context, proxyUserID, patientID string, at time.Time) (*model.ProxyGrant, error) {
+	return nil, ErrNotFound
+}
+
+func (r *mockProxyRepo) ListByPatient(ctx context.Context, clinicID, patientID string) ([]model.ProxyGrant, error) {
+	return []model.ProxyGrant{}, nil
+}
+
+func (r *mockProxyRepo) ListActiveByProxyUser(ctx context.Context, proxyUserID string, at time.Time) ([]model.ProxyGrant, error) {
+	return []model.ProxyGrant{}, nil
+}
+
+func (r *mockProxyRepo) Revoke(ctx context.Context, clinicID, id, revokedBy string, at time.Time) error {
+	return ErrNotFound
+}
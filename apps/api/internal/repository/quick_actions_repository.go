@@ -4,7 +4,6 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"time"
 
 	"github.com/google/uuid"
 
@@ -25,10 +24,6 @@ type QuickActionsRepository interface {
 	CreateROMRecord(ctx context.Context, record model.QuickROMRecord) (string, error)
 	GetLastROMRecord(ctx context.Context, patientID, joint, movement, side string) (*model.QuickROMRecord, error)
 	GetROMHistory(ctx context.Context, patientID, joint string, limit int) ([]model.QuickROMRecord, error)
-
-	// Appointments
-	CreateAppointment(ctx context.Context, req model.QuickScheduleRequest) (string, error)
-	CheckTimeSlotAvailable(ctx context.Context, clinicID, therapistID, date, timeSlot string, duration int) (bool, error)
 }
 
 // quickActionsRepo implements QuickActionsRepository.
@@ -281,51 +276,6 @@ func (r *quickActionsRepo) GetROMHistory(ctx context.Context, patientID, joint s
 	return records, nil
 }
 
-// CreateAppointment creates a new appointment.
-func (r *quickActionsRepo) CreateAppointment(ctx context.Context, req model.QuickScheduleRequest) (string, error) {
-	id := uuid.New().String()
-
-	query := `
-		INSERT INTO appointments (
-			id, patient_id, clinic_id, therapist_id, appointment_date,
-			start_time, duration_minutes, status, notes, created_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, 'scheduled', $8, $9)
-	`
-
-	_, err := r.db.ExecContext(ctx, query,
-		id, req.PatientID, req.ClinicID, req.TherapistID, req.Date,
-		req.TimeSlot, req.Duration, req.Notes, time.Now(),
-	)
-	if err != nil {
-		return "", fmt.Errorf("failed to create appointment: %w", err)
-	}
-
-	return id, nil
-}
-
-// CheckTimeSlotAvailable checks if a time slot is available.
-func (r *quickActionsRepo) CheckTimeSlotAvailable(ctx context.Context, clinicID, therapistID, date, timeSlot string, duration int) (bool, error) {
-	query := `
-		SELECT COUNT(*)
-		FROM appointments
-		WHERE clinic_id = $1 AND therapist_id = $2 AND appointment_date = $3
-		  AND status NOT IN ('cancelled', 'no_show')
-		  AND (
-			(start_time <= $4 AND start_time + (duration_minutes || ' minutes')::interval > $4::time)
-			OR
-			(start_time < ($4::time + ($5 || ' minutes')::interval) AND start_time >= $4::time)
-		  )
-	`
-
-	var count int
-	err := r.db.QueryRowContext(ctx, query, clinicID, therapistID, date, timeSlot, duration).Scan(&count)
-	if err != nil {
-		return false, fmt.Errorf("failed to check time slot: %w", err)
-	}
-
-	return count == 0, nil
-}
-
 // =============================================================================
 // MOCK IMPLEMENTATION
 // =============================================================================
@@ -387,11 +337,3 @@ func (r *mockQuickActionsRepo) GetROMHistory(ctx context.Context, patientID, joi
 	}
 	return result, nil
 }
-
-func (r *mockQuickActionsRepo) CreateAppointment(ctx context.Context, req model.QuickScheduleRequest) (string, error) {
-	return uuid.New().String(), nil
-}
-
-func (r *mockQuickActionsRepo) CheckTimeSlotAvailable(ctx context.Context, clinicID, therapistID, date, timeSlot string, duration int) (bool, error) {
-	return true, nil
-}
@@ -2,10 +2,19 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
 	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
 
 	"github.com/tqvdang/physioflow/apps/api/internal/config"
+	"github.com/tqvdang/physioflow/apps/api/internal/events"
 	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/notify"
+	"github.com/tqvdang/physioflow/apps/api/internal/storage"
+	"github.com/tqvdang/physioflow/apps/api/pkg/redis"
 )
 
 // Common errors
@@ -13,6 +22,7 @@ var (
 	ErrNotFound      = errors.New("record not found")
 	ErrAlreadyExists = errors.New("record already exists")
 	ErrInvalidInput  = errors.New("invalid input")
+	ErrConflict      = errors.New("scheduling conflict")
 )
 
 // Repository provides access to the data store.
@@ -26,7 +36,27 @@ type Repository struct {
 	visitChecklist    VisitChecklistRepository
 	quickActions      QuickActionsRepository
 	appointment       AppointmentRepository
+	schedule          ScheduleRepository
+	resource          ResourceRepository
+	appointmentType   AppointmentTypeRepository
+	groupSession      GroupSessionRepository
 	exercise          ExerciseRepository
+	exerciseMedia     ExerciseMediaRepository
+	timeline          TimelineRepository
+	proxy             ProxyRepository
+	audit             AuditRepository
+	consent           ConsentRepository
+	attachment        AttachmentRepository
+	export            ExportRepository
+	calendarFeed      CalendarFeedRepository
+	statusBoard       StatusBoardRepository
+	kiosk             KioskRepository
+	progression       ProgressionRepository
+	protocol          ProtocolRepository
+	adherence         AdherenceRepository
+	blobs             storage.BlobStore
+	events            events.Bus
+	notifier          notify.Notifier
 }
 
 // New creates a new Repository instance without database connection.
@@ -41,7 +71,27 @@ func New(cfg *config.Config) *Repository {
 		visitChecklist:    nil,
 		quickActions:      &mockQuickActionsRepo{},
 		appointment:       &mockAppointmentRepo{},
+		schedule:          &mockScheduleRepo{},
+		resource:          &mockResourceRepo{},
+		appointmentType:   &mockAppointmentTypeRepo{},
+		groupSession:      &mockGroupSessionRepo{},
 		exercise:          NewMockExerciseRepository(),
+		exerciseMedia:     &mockExerciseMediaRepo{},
+		timeline:          &mockTimelineRepo{},
+		proxy:             &mockProxyRepo{},
+		audit:             &mockAuditRepo{},
+		consent:           &mockConsentRepo{},
+		attachment:        &mockAttachmentRepo{},
+		export:            &mockExportRepo{},
+		calendarFeed:      &mockCalendarFeedRepo{},
+		statusBoard:       &mockStatusBoardRepo{},
+		kiosk:             &mockKioskRepo{},
+		progression:       &mockProgressionRepo{},
+		protocol:          &mockProtocolRepo{},
+		adherence:         &mockAdherenceRepo{},
+		blobs:             newBlobStore(cfg),
+		events:            newEventBus(cfg),
+		notifier:          notify.NewLogNotifier(),
 	}
 }
 
@@ -57,8 +107,69 @@ func NewWithDB(cfg *config.Config, db *DB) *Repository {
 		visitChecklist:    newVisitChecklistRepo(cfg, db),
 		quickActions:      newQuickActionsRepo(cfg, db),
 		appointment:       NewAppointmentRepository(db),
+		schedule:          NewScheduleRepository(db),
+		resource:          NewResourceRepository(db),
+		appointmentType:   NewAppointmentTypeRepository(db),
+		groupSession:      NewGroupSessionRepository(db),
 		exercise:          NewExerciseRepository(db),
+		exerciseMedia:     NewExerciseMediaRepository(db),
+		timeline:          NewTimelineRepository(db),
+		proxy:             NewProxyRepository(db),
+		audit:             NewAuditRepository(db),
+		consent:           NewConsentRepository(db),
+		attachment:        NewAttachmentRepository(db),
+		export:            NewExportRepository(db),
+		calendarFeed:      NewCalendarFeedRepository(db),
+		statusBoard:       NewStatusBoardRepository(db),
+		kiosk:             NewKioskRepository(db),
+		progression:       NewProgressionRepository(db),
+		protocol:          NewProtocolRepository(db),
+		adherence:         NewAdherenceRepository(db),
+		blobs:             newBlobStore(cfg),
+		events:            newEventBus(cfg),
+		notifier:          notify.NewLogNotifier(),
+	}
+}
+
+// newBlobStore keeps blobs in the configured S3-compatible store, so that
+// every API instance sees them, and otherwise on the local filesystem.
+func newBlobStore(cfg *config.Config) storage.BlobStore {
+	if cfg.Storage.S3Endpoint == "" {
+		return storage.NewLocalStore(cfg.Storage.Path)
+	}
+
+	store, err := storage.NewS3Store(storage.S3Options{
+		Endpoint:  cfg.Storage.S3Endpoint,
+		Region:    cfg.Storage.S3Region,
+		Bucket:    cfg.Storage.S3Bucket,
+		AccessKey: cfg.Storage.S3AccessKey,
+		SecretKey: cfg.Storage.S3SecretKey,
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("invalid S3 configuration, blobs stay on the local filesystem")
+		return storage.NewLocalStore(cfg.Storage.Path)
 	}
+	return store
+}
+
+// newEventBus shares clinic events through Redis pub/sub when enabled, so that
+// every API instance sees them, and otherwise keeps them in process.
+func newEventBus(cfg *config.Config) events.Bus {
+	if !cfg.Redis.PubSub {
+		return events.NewMemoryBus()
+	}
+
+	opts, err := redis.ParseURL(cfg.Redis.URL, cfg.Redis.Password, cfg.Redis.DB)
+	if err != nil {
+		log.Error().Err(err).Msg("invalid redis configuration, clinic events stay in process")
+		return events.NewMemoryBus()
+	}
+	return events.NewRedisBus(opts)
+}
+
+// Config returns the application configuration.
+func (r *Repository) Config() *config.Config {
+	return r.cfg
 }
 
 // DB returns the database connection.
@@ -101,11 +212,111 @@ func (r *Repository) Appointment() AppointmentRepository {
 	return r.appointment
 }
 
+// Schedule returns the therapist schedule repository.
+func (r *Repository) Schedule() ScheduleRepository {
+	return r.schedule
+}
+
+// Resource returns the clinic room and equipment repository.
+func (r *Repository) Resource() ResourceRepository {
+	return r.resource
+}
+
+// AppointmentType returns the appointment type repository.
+func (r *Repository) AppointmentType() AppointmentTypeRepository {
+	return r.appointmentType
+}
+
+// GroupSession returns the group session repository.
+func (r *Repository) GroupSession() GroupSessionRepository {
+	return r.groupSession
+}
+
 // Exercise returns the exercise repository.
 func (r *Repository) Exercise() ExerciseRepository {
 	return r.exercise
 }
 
+// ExerciseMedia returns the exercise media repository.
+func (r *Repository) ExerciseMedia() ExerciseMediaRepository {
+	return r.exerciseMedia
+}
+
+// Timeline returns the patient timeline repository.
+func (r *Repository) Timeline() TimelineRepository {
+	return r.timeline
+}
+
+// Proxy returns the caregiver proxy grant repository.
+func (r *Repository) Proxy() ProxyRepository {
+	return r.proxy
+}
+
+// Audit returns the audit trail repository.
+func (r *Repository) Audit() AuditRepository {
+	return r.audit
+}
+
+// Consent returns the consent repository.
+func (r *Repository) Consent() ConsentRepository {
+	return r.consent
+}
+
+// Attachment returns the patient attachment repository.
+func (r *Repository) Attachment() AttachmentRepository {
+	return r.attachment
+}
+
+// Export returns the data export repository.
+func (r *Repository) Export() ExportRepository {
+	return r.export
+}
+
+// CalendarFeed returns the iCalendar feed token repository.
+func (r *Repository) CalendarFeed() CalendarFeedRepository {
+	return r.calendarFeed
+}
+
+// StatusBoard returns the check-in and status board repository.
+func (r *Repository) StatusBoard() StatusBoardRepository {
+	return r.statusBoard
+}
+
+// Kiosk returns the kiosk device repository.
+func (r *Repository) Kiosk() KioskRepository {
+	return r.kiosk
+}
+
+// Progression returns the exercise progression repository.
+func (r *Repository) Progression() ProgressionRepository {
+	return r.progression
+}
+
+// Protocol returns the exercise protocol repository.
+func (r *Repository) Protocol() ProtocolRepository {
+	return r.protocol
+}
+
+// Adherence returns the exercise adherence repository.
+func (r *Repository) Adherence() AdherenceRepository {
+	return r.adherence
+}
+
+// Blobs returns the blob store used for attachments and export bundles.
+func (r *Repository) Blobs() storage.BlobStore {
+	return r.blobs
+}
+
+// Events returns the bus clinic events are published on.
+func (r *Repository) Events() events.Bus {
+	return r.events
+}
+
+// Notifier returns the channel used to send messages to patients.
+func (r *Repository) Notifier() notify.Notifier {
+	return r.notifier
+}
+
 // CheckDatabase verifies database connectivity.
 func (r *Repository) CheckDatabase() error {
 	if r.db == nil {
@@ -116,12 +327,16 @@ func (r *Repository) CheckDatabase() error {
 
 // CheckRedis verifies Redis connectivity.
 func (r *Repository) CheckRedis() error {
-	// TODO: Implement actual Redis ping
-	return nil
+	bus, ok := r.events.(*events.RedisBus)
+	if !ok {
+		return nil // Redis is not in use
+	}
+	return bus.Ping(context.Background())
 }
 
 // Close closes all repository connections.
 func (r *Repository) Close() error {
+	r.events.Close()
 	if r.db != nil {
 		return r.db.Close()
 	}
@@ -137,6 +352,12 @@ type UserRepository interface {
 // ClinicRepository defines the interface for clinic data access.
 type ClinicRepository interface {
 	GetPrefix(ctx context.Context, clinicID string) (string, error)
+	GetPortalPolicy(ctx context.Context, clinicID string) (*model.PortalPolicy, error)
+	GetNoShowPolicy(ctx context.Context, clinicID string) (*model.NoShowPolicy, error)
+	GetProgressionPolicy(ctx context.Context, clinicID string) (*model.ProgressionPolicy, error)
+	GetAdherencePolicy(ctx context.Context, clinicID string) (*model.AdherencePolicy, error)
+	GetTimezone(ctx context.Context, clinicID string) (string, error)
+	ListTenantClinics(ctx context.Context, clinicID string) ([]model.Clinic, error)
 }
 
 // userRepo implements UserRepository.
@@ -190,6 +411,126 @@ func (r *clinicRepo) GetPrefix(ctx context.Context, clinicID string) (string, er
 	return prefix, nil
 }
 
+// GetPortalPolicy returns the clinic's patient self-service policy, falling back
+// to defaults for any values that are not configured.
+func (r *clinicRepo) GetPortalPolicy(ctx context.Context, clinicID string) (*model.PortalPolicy, error) {
+	policy := model.DefaultPortalPolicy()
+	if r.db == nil {
+		return &policy, nil
+	}
+
+	query := `
+		SELECT settings->'patient_portal'
+		FROM clinics
+		WHERE id = $1`
+
+	var raw []byte
+	if err := r.db.QueryRowContext(ctx, query, clinicID).Scan(&raw); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get portal policy: %w", err)
+	}
+
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &policy); err != nil {
+			return nil, fmt.Errorf("failed to parse portal policy: %w", err)
+		}
+	}
+
+	return &policy, nil
+}
+
+// GetNoShowPolicy returns the clinic's no-show policy, falling back to defaults
+// for any values that are not configured.
+func (r *clinicRepo) GetNoShowPolicy(ctx context.Context, clinicID string) (*model.NoShowPolicy, error) {
+	policy := model.DefaultNoShowPolicy()
+	if r.db == nil {
+		return &policy, nil
+	}
+
+	query := `
+		SELECT settings->'no_show_policy'
+		FROM clinics
+		WHERE id = $1`
+
+	var raw []byte
+	if err := r.db.QueryRowContext(ctx, query, clinicID).Scan(&raw); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get no-show policy: %w", err)
+	}
+
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &policy); err != nil {
+			return nil, fmt.Errorf("failed to parse no-show policy: %w", err)
+		}
+	}
+
+	return &policy, nil
+}
+
+// GetProgressionPolicy returns the clinic's exercise progression policy,
+// falling back to defaults for any values that are not configured.
+func (r *clinicRepo) GetProgressionPolicy(ctx context.Context, clinicID string) (*model.ProgressionPolicy, error) {
+	policy := model.DefaultProgressionPolicy()
+	if r.db == nil {
+		return &policy, nil
+	}
+
+	query := `
+		SELECT settings->'exercise_progression'
+		FROM clinics
+		WHERE id = $1`
+
+	var raw []byte
+	if err := r.db.QueryRowContext(ctx, query, clinicID).Scan(&raw); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get progression policy: %w", err)
+	}
+
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &policy); err != nil {
+			return nil, fmt.Errorf("failed to parse progression policy: %w", err)
+		}
+	}
+
+	return &policy, nil
+}
+
+// GetAdherencePolicy returns the clinic's exercise adherence alert policy,
+// falling back to defaults for any values that are not configured.
+func (r *clinicRepo) GetAdherencePolicy(ctx context.Context, clinicID string) (*model.AdherencePolicy, error) {
+	policy := model.DefaultAdherencePolicy()
+	if r.db == nil {
+		return &policy, nil
+	}
+
+	query := `
+		SELECT settings->'exercise_adherence'
+		FROM clinics
+		WHERE id = $1`
+
+	var raw []byte
+	if err := r.db.QueryRowContext(ctx, query, clinicID).Scan(&raw); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get adherence policy: %w", err)
+	}
+
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &policy); err != nil {
+			return nil, fmt.Errorf("failed to parse adherence policy: %w", err)
+		}
+	}
+
+	return &policy, nil
+}
+
 // mockPatientRepo provides a mock implementation for development.
 type mockPatientRepo struct{}
 
@@ -205,6 +546,10 @@ func (r *mockPatientRepo) GetByMRN(ctx context.Context, clinicID, mrn string) (*
 	return nil, ErrNotFound
 }
 
+func (r *mockPatientRepo) GetByKeycloakID(ctx context.Context, keycloakID string) (*model.Patient, error) {
+	return nil, ErrNotFound
+}
+
 func (r *mockPatientRepo) Update(ctx context.Context, patient *model.Patient) error {
 	return nil
 }
@@ -241,9 +586,95 @@ func (r *mockPatientRepo) CountByClinic(ctx context.Context, clinicID string) (i
 	return 0, nil
 }
 
+// GetTimezone returns the IANA name of the time zone the clinic operates in.
+func (r *clinicRepo) GetTimezone(ctx context.Context, clinicID string) (string, error) {
+	if r.db == nil {
+		return model.DefaultClinicTimezone, nil
+	}
+
+	query := `
+		SELECT timezone
+		FROM clinics
+		WHERE id = $1`
+
+	var tz string
+	if err := r.db.QueryRowContext(ctx, query, clinicID).Scan(&tz); err != nil {
+		if err == sql.ErrNoRows {
+			return "", ErrNotFound
+		}
+		return "", fmt.Errorf("failed to get clinic timezone: %w", err)
+	}
+
+	return tz, nil
+}
+
+// ListTenantClinics returns the active clinics of the tenant the clinic
+// belongs to, including the clinic itself.
+func (r *clinicRepo) ListTenantClinics(ctx context.Context, clinicID string) ([]model.Clinic, error) {
+	if r.db == nil {
+		return []model.Clinic{{ID: clinicID, Active: true}}, nil
+	}
+
+	query := `
+		SELECT id, organization_id, name, timezone, is_active, created_at, updated_at
+		FROM clinics
+		WHERE organization_id = (SELECT organization_id FROM clinics WHERE id = $1)
+			AND is_active = true
+		ORDER BY name, id`
+
+	rows, err := r.db.QueryContext(ctx, query, clinicID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenant clinics: %w", err)
+	}
+	defer rows.Close()
+
+	clinics := []model.Clinic{}
+	for rows.Next() {
+		var c model.Clinic
+		if err := rows.Scan(&c.ID, &c.TenantID, &c.Name, &c.Timezone, &c.Active, &c.CreatedAt, &c.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan clinic: %w", err)
+		}
+		clinics = append(clinics, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate clinics: %w", err)
+	}
+
+	return clinics, nil
+}
+
 // mockClinicRepo provides a mock implementation for development.
 type mockClinicRepo struct{}
 
 func (r *mockClinicRepo) GetPrefix(ctx context.Context, clinicID string) (string, error) {
 	return "PF", nil
 }
+
+func (r *mockClinicRepo) GetPortalPolicy(ctx context.Context, clinicID string) (*model.PortalPolicy, error) {
+	policy := model.DefaultPortalPolicy()
+	return &policy, nil
+}
+
+func (r *mockClinicRepo) GetNoShowPolicy(ctx context.Context, clinicID string) (*model.NoShowPolicy, error) {
+	policy := model.DefaultNoShowPolicy()
+	return &policy, nil
+}
+
+func (r *mockClinicRepo) GetProgressionPolicy(ctx context.Context, clinicID string) (*model.ProgressionPolicy, error) {
+	policy := model.DefaultProgressionPolicy()
+	return &policy, nil
+}
+
+func (r *mockClinicRepo) GetAdherencePolicy(ctx context.Context, clinicID string) (*model.AdherencePolicy, error) {
+	policy := model.DefaultAdherencePolicy()
+	return &policy, nil
+}
+
+func (r *mockClinicRepo) GetTimezone(ctx context.Context, clinicID string) (string, error) {
+	return model.DefaultClinicTimezone, nil
+}
+
+func (r *mockClinicRepo) ListTenantClinics(ctx context.Context, clinicID string) ([]model.Clinic, error) {
+	return []model.Clinic{{ID: clinicID, Active: true}}, nil
+}
@@ -0,0 +1,347 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+)
+
+// ResourceRepository defines the interface for clinic rooms and equipment.
+type ResourceRepository interface {
+	List(ctx context.Context, clinicID string, resourceType model.ResourceType, activeOnly bool) ([]model.Resource, error)
+	GetByID(ctx context.Context, clinicID, id string) (*model.Resource, error)
+	GetByIDs(ctx context.Context, clinicID string, ids []string) ([]model.Resource, error)
+	Create(ctx context.Context, resource *model.Resource) error
+	Update(ctx context.Context, resource *model.Resource) error
+	Delete(ctx context.Context, clinicID, id string) error
+}
+
+// postgresResourceRepo implements ResourceRepository with PostgreSQL.
+type postgresResourceRepo struct {
+	db *DB
+}
+
+// NewResourceRepository creates a new PostgreSQL resource repository.
+func NewResourceRepository(db *DB) ResourceRepository {
+	return &postgresResourceRepo{db: db}
+}
+
+// resourceColumns lists the columns read by scanResource.
+const resourceColumns = `
+	id, clinic_id, name, name_vi, resource_type, capacity, description,
+	is_active, created_at, updated_at`
+
+// List retrieves a clinic's resources, optionally of one type.
+func (r *postgresResourceRepo) List(ctx context.Context, clinicID string, resourceType model.ResourceType, activeOnly bool) ([]model.Resource, error) {
+	query := `
+		SELECT ` + resourceColumns + `
+		FROM clinic_resources
+		WHERE clinic_id = $1
+			AND ($2 = '' OR resource_type = $2)
+			AND (NOT $3 OR is_active = TRUE)
+		ORDER BY resource_type, name`
+
+	rows, err := r.db.QueryContext(ctx, query, clinicID, string(resourceType), activeOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list resources: %w", err)
+	}
+	defer rows.Close()
+
+	resources := make([]model.Resource, 0)
+	for rows.Next() {
+		res, err := scanResource(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan resource: %w", err)
+		}
+		resources = append(resources, *res)
+	}
+
+	return resources, rows.Err()
+}
+
+// GetByID retrieves a resource by ID.
+func (r *postgresResourceRepo) GetByID(ctx context.Context, clinicID, id string) (*model.Resource, error) {
+	query := `SELECT ` + resourceColumns + ` FROM clinic_resources WHERE id = $1 AND clinic_id = $2`
+
+	res, err := scanResource(r.db.QueryRowContext(ctx, query, id, clinicID))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get resource: %w", err)
+	}
+	return res, nil
+}
+
+// GetByIDs retrieves the active resources with the given IDs. Unknown or
+// inactive IDs are reported as invalid input.
+func (r *postgresResourceRepo) GetByIDs(ctx context.Context, clinicID string, ids []string) ([]model.Resource, error) {
+	return getActiveResources(ctx, r.db, clinicID, ids)
+}
+
+// Create inserts a resource.
+func (r *postgresResourceRepo) Create(ctx context.Context, resource *model.Resource) error {
+	query := `
+		INSERT INTO clinic_resources (
+			id, clinic_id, name, name_vi, resource_type, capacity, description, is_active
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING created_at, updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		resource.ID,
+		resource.ClinicID,
+		resource.Name,
+		NullableStringValue(resource.NameVi),
+		resource.Type,
+		resource.Capacity,
+		NullableStringValue(resource.Description),
+		resource.IsActive,
+	).Scan(&resource.CreatedAt, &resource.UpdatedAt)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return ErrAlreadyExists
+		}
+		return fmt.Errorf("failed to create resource: %w", err)
+	}
+
+	return nil
+}
+
+// Update updates a resource.
+func (r *postgresResourceRepo) Update(ctx context.Context, resource *model.Resource) error {
+	query := `
+		UPDATE clinic_resources SET
+			name = $1, name_vi = $2, capacity = $3, description = $4,
+			is_active = $5, updated_at = NOW()
+		WHERE id = $6 AND clinic_id = $7
+		RETURNING updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		resource.Name,
+		NullableStringValue(resource.NameVi),
+		resource.Capacity,
+		NullableStringValue(resource.Description),
+		resource.IsActive,
+		resource.ID,
+		resource.ClinicID,
+	).Scan(&resource.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return ErrAlreadyExists
+		}
+		return fmt.Errorf("failed to update resource: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes a resource.
+func (r *postgresResourceRepo) Delete(ctx context.Context, clinicID, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM clinic_resources WHERE id = $1 AND clinic_id = $2`, id, clinicID)
+	if err != nil {
+		return fmt.Errorf("failed to delete resource: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// getActiveResources loads the active resources with the given IDs using the
+// given connection or transaction.
+func getActiveResources(ctx context.Context, q Querier, clinicID string, ids []string) ([]model.Resource, error) {
+	if len(ids) == 0 {
+		return []model.Resource{}, nil
+	}
+
+	query := `
+		SELECT ` + resourceColumns + `
+		FROM clinic_resources
+		WHERE clinic_id = $1 AND id = ANY($2) AND is_active = TRUE
+		ORDER BY name`
+
+	rows, err := q.QueryContext(ctx, query, clinicID, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get resources: %w", err)
+	}
+	defer rows.Close()
+
+	resources := make([]model.Resource, 0, len(ids))
+	for rows.Next() {
+		res, err := scanResource(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan resource: %w", err)
+		}
+		resources = append(resources, *res)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read resources: %w", err)
+	}
+
+	if len(resources) != len(uniqueStrings(ids)) {
+		return nil, fmt.Errorf("%w: unknown or inactive resource", ErrInvalidInput)
+	}
+
+	return resources, nil
+}
+
+// checkResourceCapacity locks the resources required by the appointments and
+// verifies that none of them is booked beyond its capacity. It runs inside the
+// transaction that writes the appointments, which are excluded from the
+// existing bookings and checked against each other.
+func checkResourceCapacity(ctx context.Context, q Querier, clinicID string, appointments []*model.Appointment) error {
+	var ids []string
+	var spanStart, spanEnd time.Time
+	exclude := make([]string, 0, len(appointments))
+	for _, a := range appointments {
+		exclude = append(exclude, a.ID)
+		if len(a.ResourceIDs) == 0 || !holdsResources(a.Status) {
+			continue
+		}
+		ids = append(ids, a.ResourceIDs...)
+		if spanStart.IsZero() || a.StartTime.Before(spanStart) {
+			spanStart = a.StartTime
+		}
+		if a.EndTime.After(spanEnd) {
+			spanEnd = a.EndTime
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	ids = uniqueStrings(ids)
+	sort.Strings(ids) // consistent lock order
+	for _, id := range ids {
+		if _, err := q.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('resource:' || $1))`, id); err != nil {
+			return fmt.Errorf("failed to lock resource: %w", err)
+		}
+	}
+
+	resources, err := getActiveResources(ctx, q, clinicID, ids)
+	if err != nil {
+		return err
+	}
+
+	bookings, err := findResourceBookings(ctx, q, clinicID, ids, spanStart, spanEnd, exclude)
+	if err != nil {
+		return err
+	}
+	for _, a := range appointments {
+		if holdsResources(a.Status) {
+			bookings = append(bookings, *a)
+		}
+	}
+
+	for _, a := range appointments {
+		if !holdsResources(a.Status) {
+			continue
+		}
+		others := make([]model.Appointment, 0, len(bookings))
+		for _, b := range bookings {
+			if b.ID != a.ID {
+				others = append(others, b)
+			}
+		}
+		for _, res := range resources {
+			if a.UsesResource(res.ID) && res.Conflicts(others, a.StartTime, a.EndTime) != nil {
+				return fmt.Errorf("%w: %s is fully booked at %s", ErrConflict, res.Name, a.StartTime.Format(time.RFC3339))
+			}
+		}
+	}
+
+	return nil
+}
+
+// holdsResources reports whether an appointment in the given status occupies
+// its resources.
+func holdsResources(status model.AppointmentStatus) bool {
+	return status != model.AppointmentStatusCancelled && status != model.AppointmentStatusNoShow
+}
+
+// uniqueStrings returns the distinct values of ss in their original order.
+func uniqueStrings(ss []string) []string {
+	seen := make(map[string]bool, len(ss))
+	out := make([]string, 0, len(ss))
+	for _, s := range ss {
+		if !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// scanResource scans a row selected with resourceColumns.
+func scanResource(row rowScanner) (*model.Resource, error) {
+	var res model.Resource
+	var nameVi, description sql.NullString
+
+	err := row.Scan(
+		&res.ID,
+		&res.ClinicID,
+		&res.Name,
+		&nameVi,
+		&res.Type,
+		&res.Capacity,
+		&description,
+		&res.IsActive,
+		&res.CreatedAt,
+		&res.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	res.NameVi = StringFromNull(nameVi)
+	res.Description = StringFromNull(description)
+
+	return &res, nil
+}
+
+// mockResourceRepo provides a mock implementation for development.
+type mockResourceRepo struct{}
+
+func (r *mockResourceRepo) List(ctx context.Context, clinicID string, resourceType model.ResourceType, activeOnly bool) ([]model.Resource, error) {
+	return []model.Resource{}, nil
+}
+
+func (r *mockResourceRepo) GetByID(ctx context.Context, clinicID, id string) (*model.Resource, error) {
+	return nil, ErrNotFound
+}
+
+func (r *mockResourceRepo) GetByIDs(ctx context.Context, clinicID string, ids []string) ([]model.Resource, error) {
+	if len(ids) == 0 {
+		return []model.Resource{}, nil
+	}
+	return nil, fmt.Errorf("%w: unknown or inactive resource", ErrInvalidInput)
+}
+
+func (r *mockResourceRepo) Create(ctx context.Context, resource *model.Resource) error {
+	resource.CreatedAt = time.Now()
+	resource.UpdatedAt = resource.CreatedAt
+	return nil
+}
+
+func (r *mockResourceRepo) Update(ctx context.Context, resource *model.Resource) error {
+	return ErrNotFound
+}
+
+func (r *mockResourceRepo) Delete(ctx context.Context, clinicID, id string) error {
+	return ErrNotFound
+}
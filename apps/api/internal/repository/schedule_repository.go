@@ -0,0 +1,419 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+)
+
+// ScheduleRepository defines the interface for managing therapist working
+// hours and schedule exceptions.
+type ScheduleRepository interface {
+	ListSchedules(ctx context.Context, clinicID, therapistID string) ([]model.TherapistSchedule, error)
+	GetSchedule(ctx context.Context, clinicID, id string) (*model.TherapistSchedule, error)
+	CreateSchedule(ctx context.Context, s *model.TherapistSchedule) error
+	UpdateSchedule(ctx context.Context, s *model.TherapistSchedule) error
+	DeleteSchedule(ctx context.Context, clinicID, id string) error
+	ListExceptions(ctx context.Context, clinicID, therapistID string, start, end time.Time) ([]model.ScheduleException, error)
+	GetException(ctx context.Context, clinicID, id string) (*model.ScheduleException, error)
+	CreateException(ctx context.Context, e *model.ScheduleException) error
+	UpdateException(ctx context.Context, e *model.ScheduleException) error
+	DeleteException(ctx context.Context, clinicID, id string) error
+}
+
+// postgresScheduleRepo implements ScheduleRepository with PostgreSQL.
+type postgresScheduleRepo struct {
+	db *DB
+}
+
+// NewScheduleRepository creates a new PostgreSQL schedule repository.
+func NewScheduleRepository(db *DB) ScheduleRepository {
+	return &postgresScheduleRepo{db: db}
+}
+
+// therapistScheduleColumns lists the columns read by scanTherapistSchedule.
+const therapistScheduleColumns = `
+	id, clinic_id, therapist_id, day_of_week,
+	to_char(start_time, 'HH24:MI'), to_char(end_time, 'HH24:MI'),
+	effective_from, effective_to, is_active, created_at, updated_at, created_by`
+
+// scheduleExceptionColumns lists the columns read by scanScheduleException.
+const scheduleExceptionColumns = `
+	id, clinic_id, therapist_id, exception_date,
+	to_char(start_time, 'HH24:MI'), to_char(end_time, 'HH24:MI'),
+	exception_type, is_available, reason, notes, created_at, updated_at, created_by`
+
+// dateOnly is the layout used to pass DATE values to PostgreSQL, so that the
+// calendar day is not shifted by the connection's time zone.
+const dateOnly = "2006-01-02"
+
+// ListSchedules retrieves every weekly working period of a therapist,
+// including inactive and expired ones.
+func (r *postgresScheduleRepo) ListSchedules(ctx context.Context, clinicID, therapistID string) ([]model.TherapistSchedule, error) {
+	query := `
+		SELECT ` + therapistScheduleColumns + `
+		FROM therapist_schedules
+		WHERE clinic_id = $1 AND therapist_id = $2
+		ORDER BY day_of_week, start_time, effective_from`
+
+	rows, err := r.db.QueryContext(ctx, query, clinicID, therapistID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list therapist schedules: %w", err)
+	}
+	defer rows.Close()
+
+	schedules := make([]model.TherapistSchedule, 0)
+	for rows.Next() {
+		s, err := scanTherapistSchedule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan therapist schedule: %w", err)
+		}
+		schedules = append(schedules, *s)
+	}
+
+	return schedules, rows.Err()
+}
+
+// GetSchedule retrieves a weekly working period by ID.
+func (r *postgresScheduleRepo) GetSchedule(ctx context.Context, clinicID, id string) (*model.TherapistSchedule, error) {
+	query := `SELECT ` + therapistScheduleColumns + ` FROM therapist_schedules WHERE id = $1 AND clinic_id = $2`
+
+	s, err := scanTherapistSchedule(r.db.QueryRowContext(ctx, query, id, clinicID))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get therapist schedule: %w", err)
+	}
+	return s, nil
+}
+
+// CreateSchedule inserts a weekly working period.
+func (r *postgresScheduleRepo) CreateSchedule(ctx context.Context, s *model.TherapistSchedule) error {
+	query := `
+		INSERT INTO therapist_schedules (
+			id, clinic_id, therapist_id, day_of_week, start_time, end_time,
+			effective_from, effective_to, is_active, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING created_at, updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		s.ID,
+		s.ClinicID,
+		s.TherapistID,
+		s.DayOfWeek,
+		s.StartTime,
+		s.EndTime,
+		s.EffectiveFrom.Format(dateOnly),
+		nullableDate(s.EffectiveTo),
+		s.IsActive,
+		NullableString(s.CreatedBy),
+	).Scan(&s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			return fmt.Errorf("%w: therapist does not exist", ErrInvalidInput)
+		}
+		return fmt.Errorf("failed to create therapist schedule: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateSchedule updates a weekly working period.
+func (r *postgresScheduleRepo) UpdateSchedule(ctx context.Context, s *model.TherapistSchedule) error {
+	query := `
+		UPDATE therapist_schedules SET
+			day_of_week = $1, start_time = $2, end_time = $3,
+			effective_from = $4, effective_to = $5, is_active = $6, updated_at = NOW()
+		WHERE id = $7 AND clinic_id = $8
+		RETURNING updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		s.DayOfWeek,
+		s.StartTime,
+		s.EndTime,
+		s.EffectiveFrom.Format(dateOnly),
+		nullableDate(s.EffectiveTo),
+		s.IsActive,
+		s.ID,
+		s.ClinicID,
+	).Scan(&s.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update therapist schedule: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteSchedule removes a weekly working period. Ending a period with an
+// effective-to date keeps its history instead.
+func (r *postgresScheduleRepo) DeleteSchedule(ctx context.Context, clinicID, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM therapist_schedules WHERE id = $1 AND clinic_id = $2`, id, clinicID)
+	if err != nil {
+		return fmt.Errorf("failed to delete therapist schedule: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// ListExceptions retrieves a therapist's schedule exceptions dated within
+// [start, end).
+func (r *postgresScheduleRepo) ListExceptions(ctx context.Context, clinicID, therapistID string, start, end time.Time) ([]model.ScheduleException, error) {
+	return listScheduleExceptions(ctx, r.db, clinicID, therapistID, start, end)
+}
+
+// GetException retrieves a schedule exception by ID.
+func (r *postgresScheduleRepo) GetException(ctx context.Context, clinicID, id string) (*model.ScheduleException, error) {
+	query := `SELECT ` + scheduleExceptionColumns + ` FROM schedule_exceptions WHERE id = $1 AND clinic_id = $2`
+
+	e, err := scanScheduleException(r.db.QueryRowContext(ctx, query, id, clinicID))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schedule exception: %w", err)
+	}
+	return e, nil
+}
+
+// CreateException inserts a schedule exception.
+func (r *postgresScheduleRepo) CreateException(ctx context.Context, e *model.ScheduleException) error {
+	query := `
+		INSERT INTO schedule_exceptions (
+			id, clinic_id, therapist_id, exception_date, start_time, end_time,
+			exception_type, is_available, reason, notes, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING created_at, updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		e.ID,
+		e.ClinicID,
+		e.TherapistID,
+		e.Date.Format(dateOnly),
+		NullableString(e.StartTime),
+		NullableString(e.EndTime),
+		e.Type,
+		e.IsAvailable,
+		NullableStringValue(e.Reason),
+		NullableStringValue(e.Notes),
+		NullableString(e.CreatedBy),
+	).Scan(&e.CreatedAt, &e.UpdatedAt)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			return fmt.Errorf("%w: therapist does not exist", ErrInvalidInput)
+		}
+		return fmt.Errorf("failed to create schedule exception: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateException updates a schedule exception.
+func (r *postgresScheduleRepo) UpdateException(ctx context.Context, e *model.ScheduleException) error {
+	query := `
+		UPDATE schedule_exceptions SET
+			exception_date = $1, start_time = $2, end_time = $3, exception_type = $4,
+			is_available = $5, reason = $6, notes = $7, updated_at = NOW()
+		WHERE id = $8 AND clinic_id = $9
+		RETURNING updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		e.Date.Format(dateOnly),
+		NullableString(e.StartTime),
+		NullableString(e.EndTime),
+		e.Type,
+		e.IsAvailable,
+		NullableStringValue(e.Reason),
+		NullableStringValue(e.Notes),
+		e.ID,
+		e.ClinicID,
+	).Scan(&e.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update schedule exception: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteException removes a schedule exception.
+func (r *postgresScheduleRepo) DeleteException(ctx context.Context, clinicID, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM schedule_exceptions WHERE id = $1 AND clinic_id = $2`, id, clinicID)
+	if err != nil {
+		return fmt.Errorf("failed to delete schedule exception: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// listScheduleExceptions retrieves a therapist's schedule exceptions dated
+// within [start, end).
+func listScheduleExceptions(ctx context.Context, q Querier, clinicID, therapistID string, start, end time.Time) ([]model.ScheduleException, error) {
+	query := `
+		SELECT ` + scheduleExceptionColumns + `
+		FROM schedule_exceptions
+		WHERE clinic_id = $1 AND therapist_id = $2
+			AND exception_date >= $3 AND exception_date < $4
+		ORDER BY exception_date, start_time NULLS FIRST`
+
+	rows, err := q.QueryContext(ctx, query, clinicID, therapistID, start.Format(dateOnly), end.Format(dateOnly))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schedule exceptions: %w", err)
+	}
+	defer rows.Close()
+
+	exceptions := make([]model.ScheduleException, 0)
+	for rows.Next() {
+		e, err := scanScheduleException(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan schedule exception: %w", err)
+		}
+		exceptions = append(exceptions, *e)
+	}
+
+	return exceptions, rows.Err()
+}
+
+// scanTherapistSchedule scans a row selected with therapistScheduleColumns.
+func scanTherapistSchedule(row rowScanner) (*model.TherapistSchedule, error) {
+	var s model.TherapistSchedule
+	var effectiveTo sql.NullTime
+	var createdBy sql.NullString
+
+	err := row.Scan(
+		&s.ID,
+		&s.ClinicID,
+		&s.TherapistID,
+		&s.DayOfWeek,
+		&s.StartTime,
+		&s.EndTime,
+		&s.EffectiveFrom,
+		&effectiveTo,
+		&s.IsActive,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+		&createdBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	s.EffectiveTo = TimePtrFromNull(effectiveTo)
+	s.CreatedBy = StringPtrFromNull(createdBy)
+
+	return &s, nil
+}
+
+// scanScheduleException scans a row selected with scheduleExceptionColumns.
+func scanScheduleException(row rowScanner) (*model.ScheduleException, error) {
+	var e model.ScheduleException
+	var startTime, endTime, reason, notes, createdBy sql.NullString
+
+	err := row.Scan(
+		&e.ID,
+		&e.ClinicID,
+		&e.TherapistID,
+		&e.Date,
+		&startTime,
+		&endTime,
+		&e.Type,
+		&e.IsAvailable,
+		&reason,
+		&notes,
+		&e.CreatedAt,
+		&e.UpdatedAt,
+		&createdBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	e.StartTime = StringPtrFromNull(startTime)
+	e.EndTime = StringPtrFromNull(endTime)
+	e.Reason = StringFromNull(reason)
+	e.Notes = StringFromNull(notes)
+	e.CreatedBy = StringPtrFromNull(createdBy)
+
+	return &e, nil
+}
+
+// nullableDate converts an optional date to a DATE parameter.
+func nullableDate(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.Format(dateOnly)
+}
+
+// mockScheduleRepo provides a mock implementation for development.
+type mockScheduleRepo struct{}
+
+func (r *mockScheduleRepo) ListSchedules(ctx context.Context, clinicID, therapistID string) ([]model.TherapistSchedule, error) {
+	return []model.TherapistSchedule{}, nil
+}
+
+func (r *mockScheduleRepo) GetSchedule(ctx context.Context, clinicID, id string) (*model.TherapistSchedule, error) {
+	return nil, ErrNotFound
+}
+
+func (r *mockScheduleRepo) CreateSchedule(ctx context.Context, s *model.TherapistSchedule) error {
+	s.CreatedAt = time.Now()
+	s.UpdatedAt = s.CreatedAt
+	return nil
+}
+
+func (r *mockScheduleRepo) UpdateSchedule(ctx context.Context, s *model.TherapistSchedule) error {
+	return ErrNotFound
+}
+
+func (r *mockScheduleRepo) DeleteSchedule(ctx context.Context, clinicID, id string) error {
+	return ErrNotFound
+}
+
+func (r *mockScheduleRepo) ListExceptions(ctx context.Context, clinicID, therapistID string, start, end time.Time) ([]model.ScheduleException, error) {
+	return []model.ScheduleException{}, nil
+}
+
+func (r *mockScheduleRepo) GetException(ctx context.Context, clinicID, id string) (*model.ScheduleException, error) {
+	return nil, ErrNotFound
+}
+
+func (r *mockScheduleRepo) CreateException(ctx context.Context, e *model.ScheduleException) error {
+	e.CreatedAt = time.Now()
+	e.UpdatedAt = e.CreatedAt
+	return nil
+}
+
+func (r *mockScheduleRepo) UpdateException(ctx context.Context, e *model.ScheduleException) error {
+	return ErrNotFound
+}
+
+func (r *mockScheduleRepo) DeleteException(ctx context.Context, clinicID, id string) error {
+	return ErrNotFound
+}
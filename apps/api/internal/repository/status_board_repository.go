@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+)
+
+// StatusBoardRepository defines the interface for check-in and status board data access.
+type StatusBoardRepository interface {
+	CheckIn(ctx context.Context, checkIn *model.AppointmentCheckIn) error
+	GetEntry(ctx context.Context, clinicID, appointmentID string) (*model.StatusBoardEntry, error)
+	ListEntries(ctx context.Context, clinicID string, start, end time.Time) ([]model.StatusBoardEntry, error)
+}
+
+// postgresStatusBoardRepo implements StatusBoardRepository with PostgreSQL.
+type postgresStatusBoardRepo struct {
+	db *DB
+}
+
+// NewStatusBoardRepository creates a new PostgreSQL status board repository.
+func NewStatusBoardRepository(db *DB) StatusBoardRepository {
+	return &postgresStatusBoardRepo{db: db}
+}
+
+// statusBoardQuery selects board entries. The checklist status is that of the
+// patient's most recent visit checklist on the appointment's day in the
+// clinic's time zone.
+const statusBoardQuery = `
+	SELECT
+		a.id, a.patient_id,
+		COALESCE(p.first_name || ' ' || p.last_name, '') AS patient_name,
+		COALESCE(p.mrn, '') AS patient_mrn,
+		a.therapist_id,
+		COALESCE(u.first_name || ' ' || u.last_name, '') AS therapist_name,
+		a.group_session_id, a.type, COALESCE(a.room, ''), a.start_time, a.end_time, a.status,
+		ci.checked_in_at, ci.method,
+		COALESCE(ci.demographics_confirmed = false OR ci.insurance_confirmed = false OR ci.patient_notes <> '', false) AS needs_front_desk,
+		vc.status::text
+	FROM appointments a
+	JOIN clinics c ON c.id = a.clinic_id
+	LEFT JOIN patients p ON p.id = a.patient_id
+	LEFT JOIN users u ON u.id = a.therapist_id
+	LEFT JOIN appointment_check_ins ci ON ci.appointment_id = a.id
+	LEFT JOIN LATERAL (
+		SELECT v.status
+		FROM visit_checklists v
+		WHERE v.patient_id = a.patient_id
+			AND v.clinic_id = a.clinic_id
+			AND (v.created_at AT TIME ZONE c.timezone)::date = (a.start_time AT TIME ZONE c.timezone)::date
+		ORDER BY v.created_at DESC
+		LIMIT 1
+	) vc ON true`
+
+// CheckIn records a patient's arrival. Checking in again keeps the first
+// check-in, which is read back into checkIn.
+func (r *postgresStatusBoardRepo) CheckIn(ctx context.Context, checkIn *model.AppointmentCheckIn) error {
+	query := `
+		INSERT INTO appointment_check_ins (
+			appointment_id, clinic_id, method, checked_in_at, checked_in_by,
+			kiosk_id, demographics_confirmed, insurance_confirmed, patient_notes
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9
+		)
+		ON CONFLICT (appointment_id) DO UPDATE SET appointment_id = EXCLUDED.appointment_id
+		RETURNING method, checked_in_at, checked_in_by, kiosk_id, demographics_confirmed, insurance_confirmed, COALESCE(patient_notes, '')`
+
+	var checkedInBy, kioskID sql.NullString
+	var demographicsConfirmed, insuranceConfirmed sql.NullBool
+	err := r.db.QueryRowContext(ctx, query,
+		checkIn.AppointmentID,
+		checkIn.ClinicID,
+		checkIn.Method,
+		checkIn.CheckedInAt,
+		NullableString(checkIn.CheckedInBy),
+		NullableString(checkIn.KioskID),
+		NullableBool(checkIn.DemographicsConfirmed),
+		NullableBool(checkIn.InsuranceConfirmed),
+		NullableStringValue(checkIn.PatientNotes),
+	).Scan(
+		&checkIn.Method, &checkIn.CheckedInAt, &checkedInBy,
+		&kioskID, &demographicsConfirmed, &insuranceConfirmed, &checkIn.PatientNotes,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to check in: %w", err)
+	}
+	checkIn.CheckedInBy = StringPtrFromNull(checkedInBy)
+	checkIn.KioskID = StringPtrFromNull(kioskID)
+	checkIn.DemographicsConfirmed = BoolPtrFromNull(demographicsConfirmed)
+	checkIn.InsuranceConfirmed = BoolPtrFromNull(insuranceConfirmed)
+	return nil
+}
+
+// GetEntry retrieves one appointment's board entry.
+func (r *postgresStatusBoardRepo) GetEntry(ctx context.Context, clinicID, appointmentID string) (*model.StatusBoardEntry, error) {
+	query := statusBoardQuery + `
+	WHERE a.clinic_id = $1 AND a.id = $2`
+
+	rows, err := r.db.QueryContext(ctx, query, clinicID, appointmentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status board entry: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to get status board entry: %w", err)
+		}
+		return nil, ErrNotFound
+	}
+	return scanStatusBoardEntry(rows)
+}
+
+// ListEntries retrieves the board entries of appointments starting in [start, end).
+func (r *postgresStatusBoardRepo) ListEntries(ctx context.Context, clinicID string, start, end time.Time) ([]model.StatusBoardEntry, error) {
+	query := statusBoardQuery + `
+	WHERE a.clinic_id = $1
+		AND a.start_time >= $2
+		AND a.start_time < $3
+	ORDER BY a.start_time ASC, patient_name ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, clinicID, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list status board entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]model.StatusBoardEntry, 0)
+	for rows.Next() {
+		e, err := scanStatusBoardEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *e)
+	}
+	return entries, rows.Err()
+}
+
+func scanStatusBoardEntry(rows *sql.Rows) (*model.StatusBoardEntry, error) {
+	var e model.StatusBoardEntry
+	var groupSessionID, method, checklistStatus sql.NullString
+	var checkedInAt sql.NullTime
+
+	err := rows.Scan(
+		&e.AppointmentID, &e.PatientID, &e.PatientName, &e.PatientMRN,
+		&e.TherapistID, &e.TherapistName,
+		&groupSessionID, &e.Type, &e.Room, &e.StartTime, &e.EndTime, &e.Status,
+		&checkedInAt, &method, &e.NeedsFrontDesk,
+		&checklistStatus,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan status board entry: %w", err)
+	}
+
+	e.GroupSessionID = StringPtrFromNull(groupSessionID)
+	if checkedInAt.Valid {
+		e.CheckedInAt = &checkedInAt.Time
+	}
+	if method.Valid {
+		m := model.CheckInMethod(method.String)
+		e.CheckInMethod = &m
+	}
+	if checklistStatus.Valid {
+		s := model.ChecklistStatus(checklistStatus.String)
+		e.ChecklistStatus = &s
+	}
+	e.SetStage()
+	return &e, nil
+}
+
+// mockStatusBoardRepo provides a mock implementation for development.
+type mockStatusBoardRepo struct{}
+
+func (r *mockStatusBoardRepo) CheckIn(ctx context.Context, checkIn *model.AppointmentCheckIn) error {
+	return nil
+}
+
+func (r *mockStatusBoardRepo) GetEntry(ctx context.Context, clinicID, appointmentID string) (*model.StatusBoardEntry, error) {
+	return nil, ErrNotFound
+}
+
+func (r *mockStatusBoardRepo) ListEntries(ctx context.Context, clinicID string, start, end time.Time) ([]model.StatusBoardEntry, error) {
+	return []model.StatusBoardEntry{}, nil
+}
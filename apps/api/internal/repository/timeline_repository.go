@@ -0,0 +1,233 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+)
+
+// TimelineRepository defines the interface for patient timeline data access.
+type TimelineRepository interface {
+	ListEvents(ctx context.Context, params model.TimelineParams, limit int) ([]model.TimelineEvent, error)
+}
+
+// postgresTimelineRepo implements TimelineRepository with PostgreSQL.
+type postgresTimelineRepo struct {
+	db *DB
+}
+
+// NewTimelineRepository creates a new PostgreSQL timeline repository.
+func NewTimelineRepository(db *DB) TimelineRepository {
+	return &postgresTimelineRepo{db: db}
+}
+
+// timelineSources maps each event type to the SELECT that produces it.
+// Every branch returns the same columns so they can be combined with UNION ALL:
+// event_id, event_type, reference_id, occurred_at, actor_id, actor_name, details.
+// $1 is the patient ID and $2 the clinic ID.
+var timelineSources = map[model.TimelineEventType]string{
+	model.TimelineEventAppointment: `
+		SELECT a.id::text AS event_id, 'appointment' AS event_type, a.id::text AS reference_id,
+			a.start_time AS occurred_at, a.therapist_id::text AS actor_id,
+			COALESCE(u.first_name || ' ' || u.last_name, '') AS actor_name,
+			json_build_object(
+				'type', a.type, 'status', a.status, 'duration', a.duration,
+				'end_time', a.end_time, 'room', a.room
+			) AS details
+		FROM appointments a
+		LEFT JOIN users u ON a.therapist_id = u.id
+		WHERE a.patient_id = $1 AND a.clinic_id = $2`,
+
+	model.TimelineEventVisitChecklist: `
+		SELECT vc.id::text, 'visit_checklist', vc.id::text,
+			COALESCE(vc.completed_at, vc.started_at, vc.created_at), vc.therapist_id::text,
+			COALESCE(u.first_name || ' ' || u.last_name, ''),
+			json_build_object(
+				'status', vc.status, 'template_type', t.template_type,
+				'template_name', t.name, 'template_name_vi', t.name_vi,
+				'note', vc.generated_note, 'note_vi', vc.generated_note_vi
+			)
+		FROM visit_checklists vc
+		LEFT JOIN checklist_templates t ON vc.template_id = t.id
+		LEFT JOIN users u ON vc.therapist_id = u.id
+		WHERE vc.patient_id = $1 AND vc.clinic_id = $2`,
+
+	model.TimelineEventPainRecord: `
+		SELECT pr.id::text, 'pain_record', pr.id::text,
+			pr.recorded_at, pr.therapist_id::text,
+			COALESCE(u.first_name || ' ' || u.last_name, ''),
+			json_build_object(
+				'level', pr.level, 'location', pr.location,
+				'body_region', pr.body_region, 'context', pr.context
+			)
+		FROM quick_pain_records pr
+		LEFT JOIN users u ON pr.therapist_id = u.id
+		WHERE pr.patient_id = $1 AND pr.clinic_id = $2`,
+
+	model.TimelineEventROMRecord: `
+		SELECT rr.id::text, 'rom_record', rr.id::text,
+			rr.recorded_at, rr.therapist_id::text,
+			COALESCE(u.first_name || ' ' || u.last_name, ''),
+			json_build_object(
+				'joint', rr.joint, 'movement', rr.movement, 'side', rr.side,
+				'active_rom', rr.active_rom, 'passive_rom', rr.passive_rom,
+				'is_painful', rr.is_painful
+			)
+		FROM quick_rom_records rr
+		LEFT JOIN users u ON rr.therapist_id = u.id
+		WHERE rr.patient_id = $1 AND rr.clinic_id = $2`,
+
+	model.TimelineEventPrescription: `
+		SELECT ep.id::text, 'prescription', ep.id::text,
+			ep.created_at, ep.prescribed_by::text,
+			COALESCE(u.first_name || ' ' || u.last_name, ''),
+			json_build_object(
+				'exercise_name', e.name, 'exercise_name_vi', e.name_vi,
+				'sets', ep.sets, 'reps', ep.reps, 'hold_seconds', ep.hold_seconds,
+				'frequency', ep.frequency, 'duration_weeks', ep.duration_weeks,
+				'status', ep.status
+			)
+		FROM exercise_prescriptions ep
+		LEFT JOIN exercises e ON ep.exercise_id = e.id
+		LEFT JOIN users u ON ep.prescribed_by = u.id
+		WHERE ep.patient_id = $1 AND ep.clinic_id = $2`,
+
+	model.TimelineEventComplianceLog: `
+		SELECT cl.id::text, 'compliance_log', cl.prescription_id::text,
+			cl.completed_at, NULL::text, '',
+			json_build_object(
+				'exercise_name', e.name, 'exercise_name_vi', e.name_vi,
+				'sets_completed', cl.sets_completed, 'reps_completed', cl.reps_completed,
+				'pain_level', cl.pain_level, 'difficulty', cl.difficulty
+			)
+		FROM exercise_compliance_logs cl
+		JOIN exercise_prescriptions ep ON cl.prescription_id = ep.id
+		LEFT JOIN exercises e ON ep.exercise_id = e.id
+		WHERE cl.patient_id = $1 AND ep.clinic_id = $2`,
+
+	// Status changes are derived from the terminal states of appointments and
+	// prescriptions, stamped with the time the record was last updated.
+	model.TimelineEventStatusChange: `
+		SELECT a.id::text || ':status', 'status_change', a.id::text,
+			a.updated_at, a.updated_by::text,
+			COALESCE(u.first_name || ' ' || u.last_name, ''),
+			json_build_object(
+				'entity', 'appointment', 'status', a.status,
+				'reason', a.cancellation_reason, 'appointment_time', a.start_time
+			)
+		FROM appointments a
+		LEFT JOIN users u ON a.updated_by = u.id
+		WHERE a.patient_id = $1 AND a.clinic_id = $2
+			AND a.status IN ('cancelled', 'no_show')
+		UNION ALL
+		SELECT ep.id::text || ':status', 'status_change', ep.id::text,
+			ep.updated_at, NULL::text, '',
+			json_build_object(
+				'entity', 'prescription', 'status', ep.status,
+				'exercise_name', e.name, 'exercise_name_vi', e.name_vi
+			)
+		FROM exercise_prescriptions ep
+		LEFT JOIN exercises e ON ep.exercise_id = e.id
+		WHERE ep.patient_id = $1 AND ep.clinic_id = $2
+			AND ep.status IN ('completed', 'paused', 'cancelled')`,
+}
+
+// ListEvents returns up to limit timeline events, newest first.
+func (r *postgresTimelineRepo) ListEvents(ctx context.Context, params model.TimelineParams, limit int) ([]model.TimelineEvent, error) {
+	branches := make([]string, 0, len(model.AllTimelineEventTypes))
+	for _, t := range params.EventTypes() {
+		if src, ok := timelineSources[t]; ok {
+			branches = append(branches, src)
+		}
+	}
+	if len(branches) == 0 {
+		return []model.TimelineEvent{}, nil
+	}
+
+	conditions := []string{}
+	args := []interface{}{params.PatientID, params.ClinicID}
+	argIdx := 3
+
+	if params.From != nil {
+		conditions = append(conditions, fmt.Sprintf("occurred_at >= $%d", argIdx))
+		args = append(args, *params.From)
+		argIdx++
+	}
+
+	if params.To != nil {
+		conditions = append(conditions, fmt.Sprintf("occurred_at < $%d", argIdx))
+		args = append(args, *params.To)
+		argIdx++
+	}
+
+	if params.Cursor != nil {
+		conditions = append(conditions, fmt.Sprintf("(occurred_at, event_id) < ($%d, $%d)", argIdx, argIdx+1))
+		args = append(args, params.Cursor.OccurredAt, params.Cursor.ID)
+		argIdx += 2
+	}
+
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT event_id, event_type, reference_id, occurred_at, actor_id, actor_name, details
+		FROM (%s) AS timeline
+		%s
+		ORDER BY occurred_at DESC, event_id DESC
+		LIMIT $%d`,
+		strings.Join(branches, "\nUNION ALL\n"), whereClause, argIdx)
+	args = append(args, limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list timeline events: %w", err)
+	}
+	defer rows.Close()
+
+	events := make([]model.TimelineEvent, 0)
+	for rows.Next() {
+		var ev model.TimelineEvent
+		var actorID sql.NullString
+		var details []byte
+
+		if err := rows.Scan(
+			&ev.ID,
+			&ev.Type,
+			&ev.ReferenceID,
+			&ev.OccurredAt,
+			&actorID,
+			&ev.ActorName,
+			&details,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan timeline event: %w", err)
+		}
+
+		ev.ActorID = StringPtrFromNull(actorID)
+		if len(details) > 0 {
+			if err := json.Unmarshal(details, &ev.Details); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal timeline details: %w", err)
+			}
+		}
+
+		events = append(events, ev)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate timeline events: %w", err)
+	}
+
+	return events, nil
+}
+
+// mockTimelineRepo provides a mock implementation for development.
+type mockTimelineRepo struct{}
+
+func (r *mockTimelineRepo) ListEvents(ctx context.Context, params model.TimelineParams, limit int) ([]model.TimelineEvent, error) {
+	return []model.TimelineEvent{}, nil
+}
@@ -0,0 +1,559 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/notify"
+	"github.com/tqvdang/physioflow/apps/api/internal/repository"
+)
+
+// painTrendMinLogs is how many pain ratings a trend needs, and
+// painTrendStableSlope how much pain may change per week while still
+// counting as stable.
+const (
+	painTrendMinLogs     = 3
+	painTrendStableSlope = 0.5
+)
+
+// AdherenceService defines the interface for exercise adherence analytics
+// and low adherence alerts.
+type AdherenceService interface {
+	GetPatientAdherence(ctx context.Context, clinicID, patientID string, weeks int) (*model.PatientAdherence, error)
+	ListLowAdherence(ctx context.Context, clinicID string, threshold *float64) ([]model.LowAdherencePatient, error)
+	ListAlerts(ctx context.Context, params model.AdherenceAlertParams) ([]model.AdherenceAlert, error)
+	AcknowledgeAlert(ctx context.Context, clinicID, id, userID string) (*model.AdherenceAlert, error)
+	CheckAlerts(ctx context.Context) (int, error)
+}
+
+// adherenceService implements AdherenceService.
+type adherenceService struct {
+	repo        repository.AdherenceRepository
+	patientRepo repository.PatientRepository
+	clinicRepo  repository.ClinicRepository
+	consent     ConsentService
+	notifier    notify.Notifier
+}
+
+// NewAdherenceService creates a new adherence service.
+func NewAdherenceService(repo repository.AdherenceRepository, patientRepo repository.PatientRepository, clinicRepo repository.ClinicRepository, consent ConsentService, notifier notify.Notifier) AdherenceService {
+	return &adherenceService{
+		repo:        repo,
+		patientRepo: patientRepo,
+		clinicRepo:  clinicRepo,
+		consent:     consent,
+		notifier:    notifier,
+	}
+}
+
+// adherenceRange is a period adherence is measured over, [start, end).
+type adherenceRange struct {
+	start, end time.Time
+}
+
+// GetPatientAdherence returns a patient's adherence over the past weeks, one
+// window per week ending with yesterday in the clinic's time zone, with the
+// pain trend of each exercise.
+func (s *adherenceService) GetPatientAdherence(ctx context.Context, clinicID, patientID string, weeks int) (*model.PatientAdherence, error) {
+	if weeks < 1 || weeks > 26 {
+		return nil, fmt.Errorf("%w: weeks must be between 1 and 26", repository.ErrInvalidInput)
+	}
+	if _, err := s.patientRepo.GetByID(ctx, clinicID, patientID); err != nil {
+		return nil, err
+	}
+
+	loc, err := clinicLocation(ctx, s.clinicRepo, clinicID)
+	if err != nil {
+		return nil, err
+	}
+	ranges := weeklyRanges(todayIn(loc), weeks)
+	from, to := ranges[0].start, ranges[len(ranges)-1].end
+
+	prescriptions, err := s.repo.ListPrescriptions(ctx, clinicID, patientID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	logs, err := s.repo.ListComplianceLogs(ctx, clinicID, patientID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	result := measureAdherence(prescriptions, logs, ranges, loc)
+	result.PatientID = patientID
+	return result, nil
+}
+
+// ListLowAdherence returns the clinic's patients whose adherence over the
+// past seven days is below threshold, or the clinic's threshold when nil,
+// lowest first.
+func (s *adherenceService) ListLowAdherence(ctx context.Context, clinicID string, threshold *float64) ([]model.LowAdherencePatient, error) {
+	policy, err := s.clinicRepo.GetAdherencePolicy(ctx, clinicID)
+	if err != nil {
+		return nil, err
+	}
+	if threshold != nil {
+		if *threshold <= 0 || *threshold > 1 {
+			return nil, fmt.Errorf("%w: threshold must be above 0 and at most 1", repository.ErrInvalidInput)
+		}
+		policy.Threshold = *threshold
+	}
+
+	loc, err := clinicLocation(ctx, s.clinicRepo, clinicID)
+	if err != nil {
+		return nil, err
+	}
+	return s.lowAdherence(ctx, clinicID, policy, todayIn(loc), loc)
+}
+
+// ListAlerts returns the clinic's adherence alerts.
+func (s *adherenceService) ListAlerts(ctx context.Context, params model.AdherenceAlertParams) ([]model.AdherenceAlert, error) {
+	if params.TherapistID != "" {
+		if _, err := uuid.Parse(params.TherapistID); err != nil {
+			return nil, fmt.Errorf("%w: invalid therapist id", repository.ErrInvalidInput)
+		}
+	}
+	return s.repo.ListAlerts(ctx, params)
+}
+
+// AcknowledgeAlert marks an alert as seen so it leaves the dashboard.
+func (s *adherenceService) AcknowledgeAlert(ctx context.Context, clinicID, id, userID string) (*model.AdherenceAlert, error) {
+	alert, err := s.repo.AcknowledgeAlert(ctx, clinicID, id, userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("alert_id", id).
+		Str("patient_id", alert.PatientID).
+		Str("acknowledged_by", userID).
+		Msg("adherence alert acknowledged")
+
+	return alert, nil
+}
+
+// CheckAlerts refreshes the current week's low adherence alerts of every
+// clinic with running prescriptions, nudging newly alerted patients when the
+// clinic asks for it, and returns how many alerts are open or acknowledged.
+func (s *adherenceService) CheckAlerts(ctx context.Context) (int, error) {
+	// Wide enough to cover the past week in every time zone
+	now := time.Now()
+	clinicIDs, err := s.repo.ListActiveClinics(ctx, now.AddDate(0, 0, -9), now.AddDate(0, 0, 1))
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for _, clinicID := range clinicIDs {
+		count, err := s.checkClinic(ctx, clinicID)
+		if err != nil {
+			log.Warn().Err(err).Str("clinic_id", clinicID).Msg("failed to check exercise adherence")
+			continue
+		}
+		total += count
+	}
+	return total, nil
+}
+
+// checkClinic records one clinic's alerts for the current week.
+func (s *adherenceService) checkClinic(ctx context.Context, clinicID string) (int, error) {
+	policy, err := s.clinicRepo.GetAdherencePolicy(ctx, clinicID)
+	if err != nil {
+		return 0, err
+	}
+	loc, err := clinicLocation(ctx, s.clinicRepo, clinicID)
+	if err != nil {
+		return 0, err
+	}
+
+	today := todayIn(loc)
+	patients, err := s.lowAdherence(ctx, clinicID, policy, today, loc)
+	if err != nil {
+		return 0, err
+	}
+
+	alerts := make([]model.AdherenceAlert, len(patients))
+	for i, p := range patients {
+		alerts[i] = model.AdherenceAlert{
+			ID:                uuid.New().String(),
+			ClinicID:          clinicID,
+			PatientID:         p.PatientID,
+			PatientName:       p.PatientName,
+			WindowStart:       p.WindowStart,
+			WindowEnd:         p.WindowEnd,
+			ExpectedSessions:  p.ExpectedSessions,
+			CompletedSessions: p.CompletedSessions,
+			Adherence:         p.Adherence,
+			PreviousAdherence: p.PreviousAdherence,
+			Threshold:         policy.Threshold,
+			TherapistIDs:      p.TherapistIDs,
+		}
+	}
+
+	// Weeks start on Monday; the date is stored without a time zone
+	monday := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+	weekStart := time.Date(monday.Year(), monday.Month(), monday.Day(), 0, 0, 0, 0, time.UTC)
+	if err := s.repo.RecordAlerts(ctx, clinicID, weekStart, alerts); err != nil {
+		return 0, err
+	}
+
+	if policy.NudgePatients {
+		for _, alert := range alerts {
+			if alert.Status == model.AdherenceAlertOpen && alert.NudgedAt == nil {
+				s.nudge(ctx, alert)
+			}
+		}
+	}
+
+	return len(alerts), nil
+}
+
+// nudge reminds an alerted patient to do their exercises. Failures are
+// logged; the patient is nudged again on the next run.
+func (s *adherenceService) nudge(ctx context.Context, alert model.AdherenceAlert) {
+	err := s.consent.RequireConsent(ctx, alert.ClinicID, alert.PatientID, model.ConsentTypeDataProcessing)
+	if errors.Is(err, ErrConsentRequired) {
+		log.Debug().Str("patient_id", alert.PatientID).Msg("no consent to nudge patient about exercises")
+		return
+	}
+	if err != nil {
+		log.Warn().Err(err).Str("patient_id", alert.PatientID).Msg("failed to check consent for exercise nudge")
+		return
+	}
+
+	msg := notify.Message{
+		ClinicID:  alert.ClinicID,
+		PatientID: alert.PatientID,
+		Kind:      notify.KindExerciseNudge,
+		Title:     "Time for your exercises",
+		TitleVi:   "Đến giờ tập bài tập",
+		Body: fmt.Sprintf("You have done %d of your %s exercise sessions this week. Keeping up with them helps your recovery.",
+			alert.CompletedSessions, formatSessions(alert.ExpectedSessions)),
+		BodyVi: fmt.Sprintf("Tuần này bạn đã tập %d trên %s buổi. Tập đều đặn giúp bạn hồi phục nhanh hơn.",
+			alert.CompletedSessions, formatSessions(alert.ExpectedSessions)),
+	}
+	if err := s.notifier.Notify(ctx, msg); err != nil {
+		log.Warn().Err(err).Str("patient_id", alert.PatientID).Msg("failed to nudge patient about exercises")
+		return
+	}
+
+	if err := s.repo.MarkNudged(ctx, alert.ID, time.Now()); err != nil {
+		log.Warn().Err(err).Str("alert_id", alert.ID).Msg("failed to record exercise nudge")
+		return
+	}
+
+	log.Info().
+		Str("alert_id", alert.ID).
+		Str("patient_id", alert.PatientID).
+		Msg("patient nudged about exercises")
+}
+
+// lowAdherence measures every patient of the clinic over the seven days
+// before today and the seven before that, and returns those below the
+// policy's threshold.
+func (s *adherenceService) lowAdherence(ctx context.Context, clinicID string, policy *model.AdherencePolicy, today time.Time, loc *time.Location) ([]model.LowAdherencePatient, error) {
+	ranges := weeklyRanges(today, 2)
+	from, to := ranges[0].start, ranges[1].end
+
+	prescriptions, err := s.repo.ListPrescriptions(ctx, clinicID, "", from, to)
+	if err != nil {
+		return nil, err
+	}
+	logs, err := s.repo.ListComplianceLogs(ctx, clinicID, "", from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	byPatient := map[string][]model.ExercisePrescription{}
+	for _, p := range prescriptions {
+		byPatient[p.PatientID] = append(byPatient[p.PatientID], p)
+	}
+	logsByPatient := map[string][]model.ExerciseComplianceLog{}
+	for _, l := range logs {
+		logsByPatient[l.PatientID] = append(logsByPatient[l.PatientID], l)
+	}
+
+	low := []model.LowAdherencePatient{}
+	for patientID, patientPrescriptions := range byPatient {
+		measured := measureAdherence(patientPrescriptions, logsByPatient[patientID], ranges, loc)
+		current := measured.Windows[1]
+		if current.Adherence == nil || current.ExpectedSessions < policy.MinExpectedSessions || *current.Adherence >= policy.Threshold {
+			continue
+		}
+
+		therapists := []string{}
+		seen := map[string]bool{}
+		for _, p := range patientPrescriptions {
+			if p.PrescribedBy != "" && !seen[p.PrescribedBy] {
+				seen[p.PrescribedBy] = true
+				therapists = append(therapists, p.PrescribedBy)
+			}
+		}
+		sort.Strings(therapists)
+
+		low = append(low, model.LowAdherencePatient{
+			PatientID:         patientID,
+			WindowStart:       current.Start,
+			WindowEnd:         current.End,
+			ExpectedSessions:  current.ExpectedSessions,
+			CompletedSessions: current.CompletedSessions,
+			Adherence:         *current.Adherence,
+			PreviousAdherence: measured.Windows[0].Adherence,
+			TherapistIDs:      therapists,
+		})
+	}
+
+	if len(low) > 0 {
+		ids := make([]string, len(low))
+		for i := range low {
+			ids[i] = low[i].PatientID
+		}
+		names, err := s.repo.GetPatientNames(ctx, clinicID, ids)
+		if err != nil {
+			return nil, err
+		}
+		for i := range low {
+			low[i].PatientName = names[low[i].PatientID]
+		}
+	}
+
+	sort.Slice(low, func(i, j int) bool {
+		if low[i].Adherence != low[j].Adherence {
+			return low[i].Adherence < low[j].Adherence
+		}
+		return low[i].PatientName < low[j].PatientName
+	})
+	return low, nil
+}
+
+// weeklyRanges returns the given number of consecutive seven day ranges
+// ending at the start of today, oldest first. Today is left out because it
+// is not over yet.
+func weeklyRanges(today time.Time, weeks int) []adherenceRange {
+	ranges := make([]adherenceRange, weeks)
+	for i := range ranges {
+		end := today.AddDate(0, 0, -7*(weeks-1-i))
+		ranges[i] = adherenceRange{start: end.AddDate(0, 0, -7), end: end}
+	}
+	return ranges
+}
+
+// adherenceTally accumulates sessions for a window. Credited sessions are
+// completed sessions capped at the number expected for each prescription, so
+// that overdoing one exercise does not hide skipping another.
+type adherenceTally struct {
+	expected  float64
+	credited  float64
+	completed int
+	painSum   int
+	painCount int
+}
+
+func (t *adherenceTally) add(o adherenceTally) {
+	t.expected += o.expected
+	t.credited += o.credited
+	t.completed += o.completed
+	t.painSum += o.painSum
+	t.painCount += o.painCount
+}
+
+func (t adherenceTally) window(start, end time.Time) model.AdherenceWindow {
+	w := model.AdherenceWindow{
+		Start:             start,
+		End:               end,
+		ExpectedSessions:  roundTo(t.expected, 2),
+		CompletedSessions: t.completed,
+	}
+	if t.expected > 0 {
+		rate := roundTo(t.credited/t.expected, 3)
+		w.Adherence = &rate
+	}
+	if t.painCount > 0 {
+		avg := roundTo(float64(t.painSum)/float64(t.painCount), 2)
+		w.AvgPain = &avg
+	}
+	return w
+}
+
+// measureAdherence compares the compliance logs with the sessions each
+// prescription's frequency schedule called for in every range, from the day
+// the prescription started until the day it ended. Prescriptions without a
+// schedule, whose free-text frequency was not understood, count their
+// sessions but expect none.
+func measureAdherence(prescriptions []model.ExercisePrescription, logs []model.ExerciseComplianceLog, ranges []adherenceRange, loc *time.Location) *model.PatientAdherence {
+	logsByPrescription := map[string][]model.ExerciseComplianceLog{}
+	for _, l := range logs {
+		logsByPrescription[l.PrescriptionID] = append(logsByPrescription[l.PrescriptionID], l)
+	}
+
+	from, to := ranges[0].start, ranges[len(ranges)-1].end
+	totals := make([]adherenceTally, len(ranges))
+	exercises := make([]model.ExerciseAdherence, 0, len(prescriptions))
+	for _, p := range prescriptions {
+		var perWeek float64
+		known := false
+		if p.FrequencySchedule != nil {
+			perWeek, known = p.FrequencySchedule.SessionsPerWeek(), true
+		}
+		active := adherenceRange{start: dayIn(p.StartDate, loc), end: to}
+		if p.EndDate != nil {
+			if end := dayIn(*p.EndDate, loc); end.Before(active.end) {
+				active.end = end
+			}
+		}
+
+		ex := model.ExerciseAdherence{
+			PrescriptionID:    p.ID,
+			ExerciseID:        p.ExerciseID,
+			Frequency:         p.Frequency,
+			FrequencySchedule: p.FrequencySchedule,
+			Windows:           make([]model.AdherenceWindow, len(ranges)),
+		}
+		if p.Exercise != nil {
+			ex.ExerciseName = p.Exercise.Name
+			ex.ExerciseNameVi = p.Exercise.NameVi
+		}
+		if known {
+			ex.SessionsPerWeek = &perWeek
+		}
+
+		prescriptionLogs := logsByPrescription[p.ID]
+		var overall adherenceTally
+		for i, r := range ranges {
+			var t adherenceTally
+			if known {
+				start, end := r.start, r.end
+				if active.start.After(start) {
+					start = active.start
+				}
+				if active.end.Before(end) {
+					end = active.end
+				}
+				if end.After(start) {
+					t.expected = perWeek * float64(daysBetween(start, end)) / 7
+				}
+			}
+			for _, l := range prescriptionLogs {
+				if l.CompletedAt.Before(r.start) || !l.CompletedAt.Before(r.end) {
+					continue
+				}
+				t.completed++
+				if l.PainLevel != nil {
+					t.painSum += *l.PainLevel
+					t.painCount++
+				}
+			}
+			t.credited = math.Min(float64(t.completed), t.expected)
+
+			ex.Windows[i] = t.window(r.start, r.end)
+			totals[i].add(t)
+			overall.add(t)
+		}
+		ex.Overall = overall.window(from, to)
+		ex.PainTrend = painTrend(prescriptionLogs)
+		exercises = append(exercises, ex)
+	}
+
+	result := &model.PatientAdherence{
+		From:       from,
+		To:         to,
+		WindowDays: daysBetween(ranges[0].start, ranges[0].end),
+		Windows:    make([]model.AdherenceWindow, len(ranges)),
+		Exercises:  exercises,
+	}
+	var overall adherenceTally
+	for i, r := range ranges {
+		result.Windows[i] = totals[i].window(r.start, r.end)
+		overall.add(totals[i])
+	}
+	result.Overall = overall.window(from, to)
+	return result
+}
+
+// painTrend fits a line through the pain levels of logs, oldest first, and
+// reports its slope per week.
+func painTrend(logs []model.ExerciseComplianceLog) model.PainTrend {
+	var rated []model.ExerciseComplianceLog
+	for _, l := range logs {
+		if l.PainLevel != nil {
+			rated = append(rated, l)
+		}
+	}
+
+	trend := model.PainTrend{Direction: model.PainTrendInsufficient, RatedLogs: len(rated)}
+	if len(rated) == 0 {
+		return trend
+	}
+	trend.FirstPain = rated[0].PainLevel
+	trend.LastPain = rated[len(rated)-1].PainLevel
+	if len(rated) < painTrendMinLogs {
+		return trend
+	}
+
+	origin := rated[0].CompletedAt
+	var sumX, sumY, sumXY, sumXX float64
+	for _, l := range rated {
+		x := l.CompletedAt.Sub(origin).Hours() / (24 * 7)
+		y := float64(*l.PainLevel)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	n := float64(len(rated))
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		// All ratings at the same moment
+		return trend
+	}
+
+	slope := roundTo((n*sumXY-sumX*sumY)/denominator, 2)
+	trend.SlopePerWeek = &slope
+	switch {
+	case slope <= -painTrendStableSlope:
+		trend.Direction = model.PainTrendImproving
+	case slope >= painTrendStableSlope:
+		trend.Direction = model.PainTrendWorsening
+	default:
+		trend.Direction = model.PainTrendStable
+	}
+	return trend
+}
+
+// formatSessions writes a number of expected sessions without needless decimals.
+func formatSessions(n float64) string {
+	return strconv.FormatFloat(math.Round(n*10)/10, 'f', -1, 64)
+}
+
+// roundTo rounds v to the given number of decimal places.
+func roundTo(v float64, places int) float64 {
+	p := math.Pow(10, float64(places))
+	return math.Round(v*p) / p
+}
+
+// RunAdherenceJob refreshes low adherence alerts every interval until the
+// context is done.
+func RunAdherenceJob(ctx context.Context, adherence AdherenceService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := adherence.CheckAlerts(ctx); err != nil {
+			log.Error().Err(err).Msg("adherence job failed")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+)
+
+func TestMeasureAdherence(t *testing.T) {
+	loc := time.UTC
+	intPtr := func(v int) *int { return &v }
+	at := func(day int) time.Time { return time.Date(2026, 10, day, 8, 0, 0, 0, loc) }
+
+	// Two weeks ending on the morning of October 15
+	ranges := weeklyRanges(time.Date(2026, 10, 15, 0, 0, 0, 0, loc), 2)
+	prescriptions := []model.ExercisePrescription{
+		// Daily throughout
+		{ID: "a", Frequency: "1x/day", FrequencySchedule: &model.FrequencySchedule{TimesPerDay: 1}, StartDate: time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)},
+		// 2x/week, starting halfway through the second week
+		{ID: "b", Frequency: "2x/week", FrequencySchedule: &model.FrequencySchedule{TimesPerDay: 1, DaysPerWeek: 2}, StartDate: time.Date(2026, 10, 11, 0, 0, 0, 0, time.UTC)},
+	}
+	logs := []model.ExerciseComplianceLog{
+		{PrescriptionID: "a", CompletedAt: at(2), PainLevel: intPtr(6)},
+		{PrescriptionID: "a", CompletedAt: at(4), PainLevel: intPtr(5)},
+		{PrescriptionID: "a", CompletedAt: at(9), PainLevel: intPtr(3)},
+		{PrescriptionID: "a", CompletedAt: at(13), PainLevel: intPtr(2)},
+		// Three sessions of b count only for the sessions expected
+		{PrescriptionID: "b", CompletedAt: at(11)},
+		{PrescriptionID: "b", CompletedAt: at(12)},
+		{PrescriptionID: "b", CompletedAt: at(13)},
+		// Today is not measured yet
+		{PrescriptionID: "a", CompletedAt: at(15)},
+	}
+
+	got := measureAdherence(prescriptions, logs, ranges, loc)
+
+	first, second := got.Windows[0], got.Windows[1]
+	if first.ExpectedSessions != 7 || first.CompletedSessions != 2 || *first.Adherence != 0.286 {
+		t.Errorf("first window = %v expected, %d completed, %v; want 7, 2, 0.286",
+			first.ExpectedSessions, first.CompletedSessions, *first.Adherence)
+	}
+	// a: 2 of 7, b: 4 days at 2/week = 8/7 expected, fully credited
+	if second.ExpectedSessions != 8.14 || second.CompletedSessions != 5 || *second.Adherence != 0.386 {
+		t.Errorf("second window = %v expected, %d completed, %v; want 8.14, 5, 0.386",
+			second.ExpectedSessions, second.CompletedSessions, *second.Adherence)
+	}
+
+	trend := got.Exercises[0].PainTrend
+	if trend.Direction != model.PainTrendImproving || trend.RatedLogs != 4 || *trend.FirstPain != 6 || *trend.LastPain != 2 {
+		t.Errorf("pain trend = %+v; want improving over 4 logs from 6 to 2", trend)
+	}
+	if got.Exercises[1].PainTrend.Direction != model.PainTrendInsufficient {
+		t.Errorf("pain trend without ratings = %s; want %s", got.Exercises[1].PainTrend.Direction, model.PainTrendInsufficient)
+	}
+}
@@ -0,0 +1,929 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/repository"
+	"github.com/tqvdang/physioflow/apps/api/pkg/rrule"
+)
+
+// SeriesConflictError is returned when occurrences of a new series overlap
+// existing appointments. Preview lists every occurrence so the caller can move
+// or skip the conflicting ones, or accept them, and submit again.
+type SeriesConflictError struct {
+	Preview *model.SeriesPreview
+}
+
+func (e *SeriesConflictError) Error() string {
+	return fmt.Sprintf("scheduling conflict: %d of %d occurrences overlap existing appointments",
+		e.Preview.ConflictCount, len(e.Preview.Occurrences))
+}
+
+// Unwrap lets callers match the error with repository.ErrConflict.
+func (e *SeriesConflictError) Unwrap() error {
+	return repository.ErrConflict
+}
+
+// legacyRRules maps the fixed recurrence patterns onto recurrence rules.
+var legacyRRules = map[model.RecurrencePattern]string{
+	model.RecurrenceDaily:    "FREQ=DAILY",
+	model.RecurrenceWeekly:   "FREQ=WEEKLY",
+	model.RecurrenceBiweekly: "FREQ=WEEKLY;INTERVAL=2",
+	model.RecurrenceMonthly:  "FREQ=MONTHLY",
+}
+
+// defaultRecurrenceCount is the number of follow-up occurrences created for a
+// legacy recurrence pattern without a count or end date.
+const defaultRecurrenceCount = 12
+
+// seriesPlan is an expanded series with its occurrences checked for conflicts.
+type seriesPlan struct {
+	series  *model.RecurrenceSeries
+	preview *model.SeriesPreview
+}
+
+// PreviewSeries expands a recurring appointment request and reports the
+// conflicts of every occurrence without creating anything.
+func (s *appointmentService) PreviewSeries(ctx context.Context, clinicID string, req *model.CreateAppointmentRequest) (*model.SeriesPreview, error) {
+	plan, err := s.planSeries(ctx, clinicID, req)
+	if err != nil {
+		return nil, err
+	}
+	return plan.preview, nil
+}
+
+// CreateSeries creates a recurring series and all of its appointments in one
+// transaction. If any occurrence conflicts, nothing is created and a
+// SeriesConflictError carrying the preview is returned, unless the request
+// accepts conflicts, in which case conflicting occurrences are skipped and
+// recorded as excluded dates of the series.
+func (s *appointmentService) CreateSeries(ctx context.Context, clinicID, userID string, req *model.CreateAppointmentRequest) (*model.SeriesResult, error) {
+	plan, err := s.planSeries(ctx, clinicID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if plan.preview.ConflictCount > 0 && !req.AcceptConflicts {
+		return nil, &SeriesConflictError{Preview: plan.preview}
+	}
+
+	series := plan.series
+	series.CreatedBy = staffActor(userID)
+
+	skipped := make([]model.SeriesOccurrence, 0)
+	appointments := make([]*model.Appointment, 0, len(plan.preview.Occurrences))
+	for _, occ := range plan.preview.Occurrences {
+		if occ.Status == model.OccurrenceStatusSkipped || occ.Status == model.OccurrenceStatusConflict {
+			occ.Status = model.OccurrenceStatusSkipped
+			skipped = append(skipped, occ)
+			series.ExDates = append(series.ExDates, occ.OriginalStart)
+			continue
+		}
+
+		appointments = append(appointments, &model.Appointment{
+			ID:           uuid.New().String(),
+			ClinicID:     clinicID,
+			PatientID:    series.PatientID,
+			TherapistID:  series.TherapistID,
+			StartTime:    occ.StartTime,
+			EndTime:      occ.EndTime,
+			Duration:     series.Duration,
+			Type:         series.Type,
+			Status:       model.AppointmentStatusScheduled,
+			Room:         series.Room,
+			Notes:        series.Notes,
+			ResourceIDs:  series.ResourceIDs,
+			RecurrenceID: &series.ID,
+			CreatedBy:    staffActor(userID),
+			UpdatedBy:    staffActor(userID),
+		})
+	}
+
+	if len(appointments) == 0 {
+		return nil, fmt.Errorf("%w: every occurrence of the series was skipped", repository.ErrInvalidInput)
+	}
+	sort.Slice(appointments, func(i, j int) bool {
+		return appointments[i].StartTime.Before(appointments[j].StartTime)
+	})
+	sortTimes(series.ExDates)
+
+	if err := s.repo.CreateSeries(ctx, series, appointments); err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("recurrence_id", series.ID).
+		Str("rrule", series.RRule).
+		Str("patient_id", series.PatientID).
+		Str("therapist_id", series.TherapistID).
+		Int("created_count", len(appointments)).
+		Int("skipped_count", len(skipped)).
+		Str("clinic_id", clinicID).
+		Str("created_by", userID).
+		Msg("recurring appointment series created")
+
+	s.events.Publish(ctx, model.ClinicEvent{ClinicID: clinicID, Type: model.EventScheduleChanged, PatientID: series.PatientID, TherapistID: series.TherapistID, ActorID: userID})
+
+	created, err := s.repo.ListBySeries(ctx, clinicID, series.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.SeriesResult{
+		Series:       series,
+		Appointments: created,
+		Skipped:      skipped,
+	}, nil
+}
+
+// GetSeries retrieves a recurring series with its appointments.
+func (s *appointmentService) GetSeries(ctx context.Context, clinicID, id string) (*model.SeriesResult, error) {
+	series, err := s.repo.GetSeries(ctx, clinicID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	appointments, err := s.repo.ListBySeries(ctx, clinicID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.SeriesResult{
+		Series:       series,
+		Appointments: appointments,
+		Skipped:      []model.SeriesOccurrence{},
+	}, nil
+}
+
+// planSeries expands the request's recurrence rule, applies the requested
+// overrides and checks every remaining occurrence for conflicts with existing
+// appointments and with the other occurrences of the series.
+func (s *appointmentService) planSeries(ctx context.Context, clinicID string, req *model.CreateAppointmentRequest) (*seriesPlan, error) {
+	if !req.IsRecurring() {
+		return nil, fmt.Errorf("%w: rrule or recurrence_pattern is required", repository.ErrInvalidInput)
+	}
+	if err := s.applyCreateType(ctx, clinicID, req); err != nil {
+		return nil, err
+	}
+
+	dtstart, err := time.Parse(time.RFC3339, req.StartTime)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid start_time format", repository.ErrInvalidInput)
+	}
+	// Occurrences keep their wall-clock time at the clinic across daylight
+	// saving changes
+	loc, err := clinicLocation(ctx, s.clinicRepo, clinicID)
+	if err != nil {
+		return nil, err
+	}
+	dtstart = dtstart.In(loc)
+
+	rule, maxCount, err := seriesRule(req)
+	if err != nil {
+		return nil, err
+	}
+	if !rule.Matches(dtstart) {
+		return nil, fmt.Errorf("%w: start_time is not an occurrence of the rrule; start the series on one of its days", repository.ErrInvalidInput)
+	}
+
+	exdates, err := parseSeriesExDates(req.ExDates, dtstart)
+	if err != nil {
+		return nil, err
+	}
+
+	starts, err := rule.Expand(dtstart, exdates, model.MaxSeriesOccurrences)
+	if errors.Is(err, rrule.ErrTooManyOccurrences) {
+		return nil, fmt.Errorf("%w: a series may not have more than %d occurrences", repository.ErrInvalidInput, model.MaxSeriesOccurrences)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", repository.ErrInvalidInput, err)
+	}
+	if maxCount > 0 && len(starts) > maxCount {
+		// A legacy count and end date were both given; stop at whichever comes first
+		starts = starts[:maxCount]
+		rule.SetCount(maxCount)
+	}
+
+	overrides, err := parseOverrides(req.Overrides, starts)
+	if err != nil {
+		return nil, err
+	}
+
+	length := time.Duration(req.Duration) * time.Minute
+	occurrences := make([]model.SeriesOccurrence, len(starts))
+	for i, start := range starts {
+		occ := model.SeriesOccurrence{
+			OriginalStart: start,
+			StartTime:     start,
+			EndTime:       start.Add(length),
+			Status:        model.OccurrenceStatusOK,
+		}
+		if o, ok := overrides[start.Unix()]; ok {
+			switch {
+			case o.Skip:
+				occ.Status = model.OccurrenceStatusSkipped
+			default:
+				moved, _ := time.Parse(time.RFC3339, o.StartTime)
+				occ.StartTime, occ.EndTime = moved, moved.Add(length)
+				occ.Status = model.OccurrenceStatusMoved
+			}
+		}
+		occurrences[i] = occ
+	}
+
+	resourceIDs := uniqueIDs(req.ResourceIDs)
+	room, err := defaultRoom(ctx, s.resourceRepo, clinicID, req.Room, resourceIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	conflictCount, err := s.checkSeriesConflicts(ctx, clinicID, req.TherapistID, resourceIDs, occurrences)
+	if err != nil {
+		return nil, err
+	}
+
+	series := &model.RecurrenceSeries{
+		ID:          uuid.New().String(),
+		ClinicID:    clinicID,
+		PatientID:   req.PatientID,
+		TherapistID: req.TherapistID,
+		RRule:       rule.String(),
+		DTStart:     dtstart,
+		Timezone:    loc.String(),
+		Duration:    req.Duration,
+		Type:        model.AppointmentType(req.Type),
+		Room:        room,
+		Notes:       req.Notes,
+		ResourceIDs: resourceIDs,
+		ExDates:     exdates,
+	}
+
+	return &seriesPlan{
+		series: series,
+		preview: &model.SeriesPreview{
+			RRule:         series.RRule,
+			Occurrences:   occurrences,
+			ConflictCount: conflictCount,
+		},
+	}, nil
+}
+
+// checkSeriesConflicts marks occurrences that overlap an existing appointment
+// of the therapist or another occurrence of the same series, or that find a
+// required resource fully booked. Existing appointments and resource bookings
+// are loaded with one query each covering the whole series.
+func (s *appointmentService) checkSeriesConflicts(ctx context.Context, clinicID, therapistID string, resourceIDs []string, occurrences []model.SeriesOccurrence) (int, error) {
+	var active []int
+	var spanStart, spanEnd time.Time
+	for i, occ := range occurrences {
+		if occ.Status == model.OccurrenceStatusSkipped {
+			continue
+		}
+		active = append(active, i)
+		if spanStart.IsZero() || occ.StartTime.Before(spanStart) {
+			spanStart = occ.StartTime
+		}
+		if occ.EndTime.After(spanEnd) {
+			spanEnd = occ.EndTime
+		}
+	}
+	if len(active) == 0 {
+		return 0, nil
+	}
+
+	existing, err := s.repo.FindConflicts(ctx, clinicID, therapistID, spanStart, spanEnd, "")
+	if err != nil {
+		return 0, fmt.Errorf("failed to check conflicts: %w", err)
+	}
+
+	resources, bookings, err := s.resourceBookings(ctx, clinicID, resourceIDs, spanStart, spanEnd, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for n, i := range active {
+		occ := &occurrences[i]
+		for _, e := range existing {
+			if occ.StartTime.Before(e.EndTime) && occ.EndTime.After(e.StartTime) {
+				occ.Conflicts = append(occ.Conflicts, overlapConflict(e))
+			}
+		}
+		for _, j := range active[:n] {
+			other := occurrences[j]
+			if occ.StartTime.Before(other.EndTime) && occ.EndTime.After(other.StartTime) {
+				occ.Conflicts = append(occ.Conflicts, model.ConflictInfo{
+					ConflictType: "overlap",
+					Message: fmt.Sprintf("Overlaps with another occurrence of this series from %s to %s",
+						other.StartTime.Format("2006-01-02 15:04"), other.EndTime.Format("15:04")),
+				})
+			}
+		}
+		occ.Conflicts = append(occ.Conflicts, resourceConflicts(resources, bookings, occ.StartTime, occ.EndTime)...)
+		if len(occ.Conflicts) > 0 {
+			occ.Status = model.OccurrenceStatusConflict
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// seriesRule returns the recurrence rule of a request. Legacy patterns are
+// mapped onto an equivalent rule; when they give both a count and an end date,
+// maxCount is the number of occurrences the series is capped at.
+func seriesRule(req *model.CreateAppointmentRequest) (rule *rrule.Rule, maxCount int, err error) {
+	if req.RRule != "" {
+		rule, err = rrule.Parse(req.RRule)
+		if err != nil {
+			return nil, 0, fmt.Errorf("%w: invalid rrule: %v", repository.ErrInvalidInput, err)
+		}
+		return rule, 0, nil
+	}
+
+	base, ok := legacyRRules[model.RecurrencePattern(req.RecurrencePattern)]
+	if !ok {
+		return nil, 0, fmt.Errorf("%w: unsupported recurrence_pattern %q", repository.ErrInvalidInput, req.RecurrencePattern)
+	}
+
+	// recurrence_count counts the occurrences after the first one
+	count := defaultRecurrenceCount + 1
+	if req.RecurrenceCount != nil {
+		count = *req.RecurrenceCount + 1
+	}
+
+	value := fmt.Sprintf("%s;COUNT=%d", base, count)
+	if req.RecurrenceEndDate != nil {
+		value = base + ";UNTIL=" + strings.ReplaceAll(*req.RecurrenceEndDate, "-", "")
+		if req.RecurrenceCount != nil {
+			maxCount = count
+		}
+	}
+
+	rule, err = rrule.Parse(value)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: invalid recurrence: %v", repository.ErrInvalidInput, err)
+	}
+	return rule, maxCount, nil
+}
+
+// parseSeriesExDates parses excluded occurrences. A bare date excludes the
+// occurrence at the series' start time on that day.
+func parseSeriesExDates(values []string, dtstart time.Time) ([]time.Time, error) {
+	exdates := make([]time.Time, 0, len(values))
+	hh, mm, ss := dtstart.Clock()
+	for _, v := range values {
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			exdates = append(exdates, t)
+			continue
+		}
+		d, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid exdate %q", repository.ErrInvalidInput, v)
+		}
+		exdates = append(exdates, time.Date(d.Year(), d.Month(), d.Day(), hh, mm, ss, 0, dtstart.Location()))
+	}
+	return exdates, nil
+}
+
+// parseOverrides indexes overrides by the occurrence they apply to and rejects
+// overrides that do not match an occurrence of the series.
+func parseOverrides(overrides []model.OccurrenceOverride, starts []time.Time) (map[int64]model.OccurrenceOverride, error) {
+	occurrences := make(map[int64]bool, len(starts))
+	for _, t := range starts {
+		occurrences[t.Unix()] = true
+	}
+
+	byStart := make(map[int64]model.OccurrenceOverride, len(overrides))
+	for _, o := range overrides {
+		original, err := time.Parse(time.RFC3339, o.OriginalStart)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid override original_start %q", repository.ErrInvalidInput, o.OriginalStart)
+		}
+		if !occurrences[original.Unix()] {
+			return nil, fmt.Errorf("%w: override %s does not match an occurrence of the series", repository.ErrInvalidInput, o.OriginalStart)
+		}
+		if !o.Skip {
+			if _, err := time.Parse(time.RFC3339, o.StartTime); err != nil {
+				return nil, fmt.Errorf("%w: invalid override start_time %q", repository.ErrInvalidInput, o.StartTime)
+			}
+		}
+		byStart[original.Unix()] = o
+	}
+	return byStart, nil
+}
+
+// overlapConflict describes an overlap with an existing appointment.
+func overlapConflict(a model.Appointment) model.ConflictInfo {
+	booking := "appointment"
+	if a.GroupSessionID != nil {
+		booking = "group session"
+	}
+	return model.ConflictInfo{
+		ConflictType: "overlap",
+		Message:      fmt.Sprintf("Overlaps with existing %s from %s to %s", booking, a.StartTime.Format("15:04"), a.EndTime.Format("15:04")),
+		Appointment:  &a,
+	}
+}
+
+func sortTimes(ts []time.Time) {
+	sort.Slice(ts, func(i, j int) bool { return ts[i].Before(ts[j]) })
+}
+
+// UpdateSeries edits an appointment of a recurring series. With scope "this"
+// only the appointment changes. With "following" the series is split at the
+// appointment: the original series ends before it and a new series takes over
+// it and every later occurrence. With "all" every open occurrence and the
+// series itself change. A new start time moves each occurrence by the same
+// number of days and the same change of clock time. Occurrences that would
+// conflict at their new time are left unchanged and reported in NotMoved.
+func (s *appointmentService) UpdateSeries(ctx context.Context, clinicID, id, userID string, req *model.UpdateSeriesRequest) (*model.SeriesUpdateResult, error) {
+	scope := model.SeriesEditScope(req.Scope)
+	if scope == model.SeriesEditThis {
+		return s.updateOccurrence(ctx, clinicID, id, userID, req)
+	}
+
+	existing, err := s.repo.GetByID(ctx, clinicID, id)
+	if err != nil {
+		return nil, err
+	}
+	if existing.RecurrenceID == nil {
+		return nil, fmt.Errorf("%w: appointment is not part of a recurring series", repository.ErrInvalidInput)
+	}
+	if req.Status != nil {
+		return nil, fmt.Errorf("%w: status can only be changed for a single appointment", repository.ErrInvalidInput)
+	}
+	if req.Type != nil {
+		if _, err := s.checkTypeChange(ctx, clinicID, &existing.Appointment, &req.UpdateAppointmentRequest); err != nil {
+			return nil, err
+		}
+	}
+
+	series, err := s.repo.GetSeries(ctx, clinicID, *existing.RecurrenceID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, fmt.Errorf("%w: series has no recurrence rule; edit its appointments individually", repository.ErrInvalidInput)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Work out the series records
+	splitAt := existing.StartTime
+	plan, err := planSeriesEdit(series, scope, splitAt, &req.UpdateAppointmentRequest, clinicID, userID)
+	if err != nil {
+		return nil, err
+	}
+	shift := plan.shift
+
+	appointments, err := s.repo.ListBySeries(ctx, clinicID, series.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Select the occurrences the edit applies to and apply it to copies
+	var targets, updated []model.Appointment
+	var following []model.Appointment
+	for _, a := range appointments {
+		inScope := scope == model.SeriesEditAll || !a.StartTime.Before(splitAt)
+		if scope == model.SeriesEditFollowing && inScope {
+			following = append(following, a.Appointment)
+		}
+		if a.ID == existing.ID || (inScope && isOpenAppointment(a.Status)) {
+			targets = append(targets, a.Appointment)
+			updated = append(updated, applySeriesChanges(a.Appointment, &req.UpdateAppointmentRequest, shift, userID))
+		}
+	}
+
+	reschedules := req.StartTime != nil || req.Duration != nil || req.TherapistID != nil || req.ResourceIDs != nil
+	moving := make(map[string]bool, len(targets))
+	notMoved := make(map[string][]model.ConflictInfo)
+	if reschedules {
+		for _, a := range targets {
+			moving[a.ID] = true
+		}
+		for {
+			conflicts, err := s.movingConflicts(ctx, clinicID, updated, moving)
+			if err != nil {
+				return nil, err
+			}
+			if len(conflicts) == 0 {
+				break
+			}
+			for id, c := range conflicts {
+				delete(moving, id)
+				notMoved[id] = append(notMoved[id], c...)
+			}
+		}
+		if c, ok := notMoved[existing.ID]; ok {
+			return nil, fmt.Errorf("%w: %s", repository.ErrConflict, c[0].Message)
+		}
+	}
+
+	edit := &model.SeriesEdit{Series: series, Split: plan.split}
+	target := plan.target
+
+	// Collect the appointment changes
+	changed := make(map[string]bool, len(targets))
+	var notMovedOccurrences []model.SeriesOccurrence
+	for i, a := range targets {
+		next := updated[i]
+		if reschedules && !moving[a.ID] {
+			notMovedOccurrences = append(notMovedOccurrences, model.SeriesOccurrence{
+				AppointmentID: a.ID,
+				OriginalStart: a.StartTime,
+				StartTime:     next.StartTime,
+				EndTime:       next.EndTime,
+				Status:        model.OccurrenceStatusConflict,
+				Conflicts:     notMoved[a.ID],
+			})
+			next = a
+		}
+		next.RecurrenceID = &target.ID
+		changed[a.ID] = true
+		if moving[a.ID] {
+			edit.Moved = append(edit.Moved, &next)
+		} else {
+			edit.Other = append(edit.Other, &next)
+		}
+	}
+	for _, a := range following {
+		if !changed[a.ID] {
+			a := a
+			a.RecurrenceID = &target.ID
+			a.UpdatedBy = staffActor(userID)
+			edit.Other = append(edit.Other, &a)
+		}
+	}
+
+	if err := s.repo.ApplySeriesEdit(ctx, edit); err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("appointment_id", id).
+		Str("recurrence_id", series.ID).
+		Str("scope", string(scope)).
+		Bool("split", edit.Split != nil).
+		Int("moved_count", len(edit.Moved)).
+		Int("not_moved_count", len(notMovedOccurrences)).
+		Str("clinic_id", clinicID).
+		Str("updated_by", userID).
+		Msg("appointment series updated")
+
+	s.events.Publish(ctx, model.ClinicEvent{ClinicID: clinicID, Type: model.EventScheduleChanged, PatientID: series.PatientID, TherapistID: series.TherapistID, ActorID: userID})
+
+	result, err := s.repo.ListBySeries(ctx, clinicID, target.ID)
+	if err != nil {
+		return nil, err
+	}
+	edited := make([]model.AppointmentWithDetails, 0, len(targets))
+	for _, a := range result {
+		if changed[a.ID] {
+			edited = append(edited, a)
+		}
+	}
+	if notMovedOccurrences == nil {
+		notMovedOccurrences = []model.SeriesOccurrence{}
+	}
+
+	return &model.SeriesUpdateResult{
+		Scope:        scope,
+		Series:       target,
+		Appointments: edited,
+		NotMoved:     notMovedOccurrences,
+	}, nil
+}
+
+// seriesEditPlan is how an edit of several occurrences changes the series
+// records.
+type seriesEditPlan struct {
+	shift  func(time.Time) time.Time // moves an occurrence to its new time
+	split  *model.RecurrenceSeries   // the series taking over at the edited appointment, if split
+	target *model.RecurrenceSeries   // the series the edited appointments belong to
+}
+
+// planSeriesEdit applies an edit of the occurrences from splitAt on
+// ("following") or of every occurrence ("all") to the series record. Editing
+// all occurrences, or following ones from the first, changes the series
+// itself. Otherwise the series ends before splitAt and a new series with the
+// edit applied takes over from the occurrence at splitAt; a COUNT-bounded rule
+// is re-counted so that both series together keep the original occurrences. A
+// new start time moves the rule's weekdays by the same number of days; rules
+// with numbered weekdays or days of the month cannot be moved.
+func planSeriesEdit(series *model.RecurrenceSeries, scope model.SeriesEditScope, splitAt time.Time, req *model.UpdateAppointmentRequest, clinicID, userID string) (*seriesEditPlan, error) {
+	rule, err := rrule.Parse(series.RRule)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse series rule: %w", err)
+	}
+	loc := seriesLocation(series.Timezone)
+
+	shift, dayDelta, err := seriesShift(splitAt, req.StartTime, loc)
+	if err != nil {
+		return nil, err
+	}
+	newRule := rule.Clone()
+	if err := newRule.ShiftDays(dayDelta); err != nil {
+		return nil, fmt.Errorf("%w: %v; move occurrences individually", repository.ErrInvalidInput, err)
+	}
+
+	occurrences, err := rule.Expand(series.DTStart.In(loc), nil, 2*model.MaxSeriesOccurrences)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand series rule: %w", err)
+	}
+	before := 0
+	for before < len(occurrences) && occurrences[before].Before(splitAt) {
+		before++
+	}
+
+	plan := &seriesEditPlan{shift: shift, target: series}
+	switch {
+	case scope == model.SeriesEditAll || before == 0:
+		if req.StartTime != nil {
+			newRule.SetCount(len(occurrences))
+		}
+		series.RRule = newRule.String()
+		series.DTStart = shift(series.DTStart)
+		series.ExDates = shiftTimes(series.ExDates, shift)
+		applySeriesTemplate(series, req)
+	case before == len(occurrences):
+		// The appointment was moved past the end of the rule; only it and
+		// any later extras change, the rule itself stays as it is
+	default:
+		split := &model.RecurrenceSeries{
+			ID:          uuid.New().String(),
+			ClinicID:    clinicID,
+			PatientID:   series.PatientID,
+			TherapistID: series.TherapistID,
+			DTStart:     shift(occurrences[before]),
+			Timezone:    series.Timezone,
+			Duration:    series.Duration,
+			Type:        series.Type,
+			Room:        series.Room,
+			Notes:       series.Notes,
+			ResourceIDs: series.ResourceIDs,
+			ExDates:     []time.Time{},
+			CreatedBy:   staffActor(userID),
+		}
+		newRule.SetCount(len(occurrences) - before)
+		split.RRule = newRule.String()
+		applySeriesTemplate(split, req)
+
+		kept := make([]time.Time, 0, len(series.ExDates))
+		for _, t := range series.ExDates {
+			if t.Before(splitAt) {
+				kept = append(kept, t)
+			} else {
+				split.ExDates = append(split.ExDates, shift(t))
+			}
+		}
+		rule.SetUntil(splitAt)
+		series.RRule = rule.String()
+		series.ExDates = kept
+
+		plan.split = split
+		plan.target = split
+	}
+
+	return plan, nil
+}
+
+// updateOccurrence edits a single appointment, leaving the rest of its series as is.
+func (s *appointmentService) updateOccurrence(ctx context.Context, clinicID, id, userID string, req *model.UpdateSeriesRequest) (*model.SeriesUpdateResult, error) {
+	updated, err := s.Update(ctx, clinicID, id, userID, &req.UpdateAppointmentRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.SeriesUpdateResult{
+		Scope:        model.SeriesEditThis,
+		Appointments: []model.AppointmentWithDetails{*updated},
+		NotMoved:     []model.SeriesOccurrence{},
+	}
+	if updated.RecurrenceID != nil {
+		series, err := s.repo.GetSeries(ctx, clinicID, *updated.RecurrenceID)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return nil, err
+		}
+		result.Series = series
+	}
+	return result, nil
+}
+
+// movingConflicts checks the new positions of the appointments being moved
+// against the rest of each therapist's schedule, against the bookings of the
+// resources they require and against each other, and returns the conflicts by
+// appointment ID. Appointments that are not moving count at their current
+// position.
+func (s *appointmentService) movingConflicts(ctx context.Context, clinicID string, updated []model.Appointment, moving map[string]bool) (map[string][]model.ConflictInfo, error) {
+	type span struct{ start, end time.Time }
+	spans := make(map[string]*span)
+	for _, a := range updated {
+		if !moving[a.ID] {
+			continue
+		}
+		sp, ok := spans[a.TherapistID]
+		if !ok {
+			spans[a.TherapistID] = &span{a.StartTime, a.EndTime}
+			continue
+		}
+		if a.StartTime.Before(sp.start) {
+			sp.start = a.StartTime
+		}
+		if a.EndTime.After(sp.end) {
+			sp.end = a.EndTime
+		}
+	}
+
+	existing := make(map[string][]model.Appointment, len(spans))
+	for therapistID, sp := range spans {
+		found, err := s.repo.FindConflicts(ctx, clinicID, therapistID, sp.start, sp.end, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to check conflicts: %w", err)
+		}
+		for _, e := range found {
+			if !moving[e.ID] {
+				existing[therapistID] = append(existing[therapistID], e)
+			}
+		}
+	}
+
+	resources, bookings, err := s.movingResourceBookings(ctx, clinicID, updated, moving)
+	if err != nil {
+		return nil, err
+	}
+
+	conflicts := make(map[string][]model.ConflictInfo)
+	for i, a := range updated {
+		if !moving[a.ID] {
+			continue
+		}
+		for _, e := range existing[a.TherapistID] {
+			if a.StartTime.Before(e.EndTime) && a.EndTime.After(e.StartTime) {
+				conflicts[a.ID] = append(conflicts[a.ID], overlapConflict(e))
+			}
+		}
+		for j, other := range updated {
+			if j == i || !moving[other.ID] || other.TherapistID != a.TherapistID {
+				continue
+			}
+			if a.StartTime.Before(other.EndTime) && a.EndTime.After(other.StartTime) {
+				conflicts[a.ID] = append(conflicts[a.ID], model.ConflictInfo{
+					ConflictType: "overlap",
+					Message: fmt.Sprintf("Overlaps with another occurrence of this series from %s to %s",
+						other.StartTime.Format("2006-01-02 15:04"), other.EndTime.Format("15:04")),
+				})
+			}
+		}
+		if len(a.ResourceIDs) > 0 {
+			others := make([]model.Appointment, 0, len(bookings)+len(updated))
+			others = append(others, bookings...)
+			for j, other := range updated {
+				if j != i && moving[other.ID] {
+					others = append(others, other)
+				}
+			}
+			var required []model.Resource
+			for _, res := range resources {
+				if a.UsesResource(res.ID) {
+					required = append(required, res)
+				}
+			}
+			conflicts[a.ID] = append(conflicts[a.ID], resourceConflicts(required, others, a.StartTime, a.EndTime)...)
+		}
+		if len(conflicts[a.ID]) == 0 {
+			delete(conflicts, a.ID)
+		}
+	}
+
+	return conflicts, nil
+}
+
+// movingResourceBookings loads the resources required by the appointments
+// being moved and their bookings over the span of the move, leaving out the
+// moving appointments themselves.
+func (s *appointmentService) movingResourceBookings(ctx context.Context, clinicID string, updated []model.Appointment, moving map[string]bool) ([]model.Resource, []model.Appointment, error) {
+	var ids, exclude []string
+	var spanStart, spanEnd time.Time
+	for _, a := range updated {
+		if !moving[a.ID] {
+			continue
+		}
+		exclude = append(exclude, a.ID)
+		if len(a.ResourceIDs) == 0 {
+			continue
+		}
+		ids = append(ids, a.ResourceIDs...)
+		if spanStart.IsZero() || a.StartTime.Before(spanStart) {
+			spanStart = a.StartTime
+		}
+		if a.EndTime.After(spanEnd) {
+			spanEnd = a.EndTime
+		}
+	}
+
+	return s.resourceBookings(ctx, clinicID, uniqueIDs(ids), spanStart, spanEnd, exclude)
+}
+
+// applySeriesChanges returns a copy of an occurrence with the requested changes.
+func applySeriesChanges(a model.Appointment, req *model.UpdateAppointmentRequest, shift func(time.Time) time.Time, userID string) model.Appointment {
+	if req.TherapistID != nil {
+		a.TherapistID = *req.TherapistID
+	}
+	if req.Duration != nil {
+		a.Duration = *req.Duration
+	}
+	a.StartTime = shift(a.StartTime)
+	a.EndTime = a.StartTime.Add(time.Duration(a.Duration) * time.Minute)
+	if req.Type != nil {
+		a.Type = model.AppointmentType(*req.Type)
+	}
+	if req.Room != nil {
+		a.Room = *req.Room
+	}
+	if req.Notes != nil {
+		a.Notes = *req.Notes
+	}
+	if req.ResourceIDs != nil {
+		a.ResourceIDs = uniqueIDs(req.ResourceIDs)
+	}
+	a.UpdatedBy = staffActor(userID)
+	return a
+}
+
+// applySeriesTemplate applies the requested changes to a series record.
+func applySeriesTemplate(series *model.RecurrenceSeries, req *model.UpdateAppointmentRequest) {
+	if req.TherapistID != nil {
+		series.TherapistID = *req.TherapistID
+	}
+	if req.Duration != nil {
+		series.Duration = *req.Duration
+	}
+	if req.Type != nil {
+		series.Type = model.AppointmentType(*req.Type)
+	}
+	if req.Room != nil {
+		series.Room = *req.Room
+	}
+	if req.Notes != nil {
+		series.Notes = *req.Notes
+	}
+	if req.ResourceIDs != nil {
+		series.ResourceIDs = uniqueIDs(req.ResourceIDs)
+	}
+}
+
+// seriesShift returns a function that moves an occurrence by the same number
+// of days and the same change of clock time as moving from to the new start,
+// in the series' time zone, along with the number of days moved.
+func seriesShift(from time.Time, to *string, loc *time.Location) (func(time.Time) time.Time, int, error) {
+	if to == nil {
+		return func(t time.Time) time.Time { return t }, 0, nil
+	}
+
+	target, err := time.Parse(time.RFC3339, *to)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: invalid start_time format", repository.ErrInvalidInput)
+	}
+
+	f, t := from.In(loc), target.In(loc)
+	fy, fm, fd := f.Date()
+	ty, tm, td := t.Date()
+	days := int(time.Date(ty, tm, td, 0, 0, 0, 0, time.UTC).Sub(time.Date(fy, fm, fd, 0, 0, 0, 0, time.UTC)).Hours() / 24)
+	minutes := (t.Hour()*60 + t.Minute()) - (f.Hour()*60 + f.Minute())
+
+	return func(x time.Time) time.Time {
+		l := x.In(loc)
+		y, m, d := l.Date()
+		return time.Date(y, m, d+days, l.Hour(), l.Minute()+minutes, l.Second(), 0, loc)
+	}, days, nil
+}
+
+// seriesLocation returns the time zone a series repeats in.
+func seriesLocation(tz string) *time.Location {
+	if t, err := time.Parse("-07:00", tz); err == nil {
+		_, offset := t.Zone()
+		return time.FixedZone(tz, offset)
+	}
+	if loc, err := time.LoadLocation(tz); err == nil {
+		return loc
+	}
+	return time.UTC
+}
+
+// shiftTimes applies shift to every time in ts.
+func shiftTimes(ts []time.Time, shift func(time.Time) time.Time) []time.Time {
+	out := make([]time.Time, len(ts))
+	for i, t := range ts {
+		out[i] = shift(t)
+	}
+	return out
+}
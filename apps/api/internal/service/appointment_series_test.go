@@ -0,0 +1,170 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/repository"
+	"github.com/tqvdang/physioflow/apps/api/pkg/rrule"
+)
+
+// seriesStarts expands a series record the way the service does.
+func seriesStarts(t *testing.T, series *model.RecurrenceSeries) []time.Time {
+	t.Helper()
+	rule, err := rrule.Parse(series.RRule)
+	if err != nil {
+		t.Fatalf("rrule.Parse(%q) error = %v", series.RRule, err)
+	}
+	starts, err := rule.Expand(series.DTStart, series.ExDates, 100)
+	if err != nil {
+		t.Fatalf("Expand(%q) error = %v", series.RRule, err)
+	}
+	return starts
+}
+
+func TestPlanSeriesEdit(t *testing.T) {
+	at := func(d, hh int) time.Time { return time.Date(2026, 10, d, hh, 0, 0, 0, time.UTC) }
+	strPtr := func(s string) *string { return &s }
+	// Six Mondays from October 5, with October 12 and 26 excluded
+	newSeries := func(rule string) *model.RecurrenceSeries {
+		return &model.RecurrenceSeries{
+			ID:       "series-1",
+			RRule:    rule,
+			DTStart:  at(5, 9),
+			Timezone: "UTC",
+			Notes:    "original",
+			ExDates:  []time.Time{at(12, 9), at(26, 9)},
+		}
+	}
+
+	t.Run("split mid-series", func(t *testing.T) {
+		series := newSeries("FREQ=WEEKLY;BYDAY=MO;COUNT=6")
+		req := &model.UpdateAppointmentRequest{Notes: strPtr("changed")}
+
+		plan, err := planSeriesEdit(series, model.SeriesEditFollowing, at(19, 9), req, "clinic-1", "user-1")
+		if err != nil {
+			t.Fatalf("planSeriesEdit() error = %v", err)
+		}
+		if plan.split == nil || plan.target != plan.split {
+			t.Fatalf("planSeriesEdit() did not split the series")
+		}
+
+		if want := "FREQ=WEEKLY;BYDAY=MO;UNTIL=20261019T085959Z"; series.RRule != want {
+			t.Errorf("original rule = %q; want %q", series.RRule, want)
+		}
+		if series.Notes != "original" || plan.split.Notes != "changed" {
+			t.Errorf("notes = %q, %q; want the edit only on the split", series.Notes, plan.split.Notes)
+		}
+		if want := "FREQ=WEEKLY;BYDAY=MO;COUNT=4"; plan.split.RRule != want {
+			t.Errorf("split rule = %q; want %q", plan.split.RRule, want)
+		}
+		if !plan.split.DTStart.Equal(at(19, 9)) {
+			t.Errorf("split dtstart = %v; want %v", plan.split.DTStart, at(19, 9))
+		}
+
+		// The two series together keep the original occurrences
+		if got := seriesStarts(t, series); !equalTimeSlices(got, []time.Time{at(5, 9)}) {
+			t.Errorf("original occurrences = %v", got)
+		}
+		want := []time.Time{at(19, 9), time.Date(2026, 11, 2, 9, 0, 0, 0, time.UTC), time.Date(2026, 11, 9, 9, 0, 0, 0, time.UTC)}
+		if got := seriesStarts(t, plan.split); !equalTimeSlices(got, want) {
+			t.Errorf("split occurrences = %v; want %v", got, want)
+		}
+	})
+
+	t.Run("moved split is re-counted", func(t *testing.T) {
+		series := newSeries("FREQ=WEEKLY;BYDAY=MO;COUNT=6")
+		// From Monday 9:00 to Tuesday 10:00
+		req := &model.UpdateAppointmentRequest{StartTime: strPtr("2026-10-20T10:00:00Z")}
+
+		plan, err := planSeriesEdit(series, model.SeriesEditFollowing, at(19, 9), req, "clinic-1", "user-1")
+		if err != nil {
+			t.Fatalf("planSeriesEdit() error = %v", err)
+		}
+		if want := "FREQ=WEEKLY;BYDAY=TU;COUNT=4"; plan.split.RRule != want {
+			t.Errorf("split rule = %q; want %q", plan.split.RRule, want)
+		}
+		if !plan.split.DTStart.Equal(at(20, 10)) {
+			t.Errorf("split dtstart = %v; want %v", plan.split.DTStart, at(20, 10))
+		}
+		if len(plan.split.ExDates) != 1 || !plan.split.ExDates[0].Equal(at(27, 10)) {
+			t.Errorf("split exdates = %v; want the October 26 exclusion moved to October 27 10:00", plan.split.ExDates)
+		}
+		if len(series.ExDates) != 1 || !series.ExDates[0].Equal(at(12, 9)) {
+			t.Errorf("original exdates = %v; want only October 12", series.ExDates)
+		}
+		want := []time.Time{at(20, 10), time.Date(2026, 11, 3, 10, 0, 0, 0, time.UTC), time.Date(2026, 11, 10, 10, 0, 0, 0, time.UTC)}
+		if got := seriesStarts(t, plan.split); !equalTimeSlices(got, want) {
+			t.Errorf("split occurrences = %v; want %v", got, want)
+		}
+		if !plan.shift(at(26, 9)).Equal(at(27, 10)) {
+			t.Errorf("shift(October 26 9:00) = %v; want October 27 10:00", plan.shift(at(26, 9)))
+		}
+	})
+
+	t.Run("all occurrences", func(t *testing.T) {
+		series := newSeries("FREQ=WEEKLY;BYDAY=MO;UNTIL=20261109")
+		req := &model.UpdateAppointmentRequest{StartTime: strPtr("2026-10-20T09:00:00Z")}
+
+		plan, err := planSeriesEdit(series, model.SeriesEditAll, at(19, 9), req, "clinic-1", "user-1")
+		if err != nil {
+			t.Fatalf("planSeriesEdit() error = %v", err)
+		}
+		if plan.split != nil || plan.target != series {
+			t.Fatalf("planSeriesEdit() split the series; want it edited in place")
+		}
+		if want := "FREQ=WEEKLY;BYDAY=TU;COUNT=6"; series.RRule != want {
+			t.Errorf("rule = %q; want %q", series.RRule, want)
+		}
+		if !series.DTStart.Equal(at(6, 9)) {
+			t.Errorf("dtstart = %v; want October 6", series.DTStart)
+		}
+	})
+
+	t.Run("following from the first occurrence", func(t *testing.T) {
+		series := newSeries("FREQ=WEEKLY;BYDAY=MO;COUNT=6")
+		req := &model.UpdateAppointmentRequest{Notes: strPtr("changed")}
+
+		plan, err := planSeriesEdit(series, model.SeriesEditFollowing, at(5, 9), req, "clinic-1", "user-1")
+		if err != nil {
+			t.Fatalf("planSeriesEdit() error = %v", err)
+		}
+		if plan.split != nil || series.Notes != "changed" || series.RRule != "FREQ=WEEKLY;BYDAY=MO;COUNT=6" {
+			t.Errorf("planSeriesEdit() = split %v, notes %q, rule %q; want the series edited in place", plan.split, series.Notes, series.RRule)
+		}
+	})
+
+	t.Run("rules that cannot move to another day", func(t *testing.T) {
+		for _, rule := range []string{"FREQ=MONTHLY;BYDAY=1MO;COUNT=6", "FREQ=MONTHLY;BYMONTHDAY=5;COUNT=6"} {
+			series := newSeries(rule)
+			req := &model.UpdateAppointmentRequest{StartTime: strPtr("2026-11-03T09:00:00Z")}
+			_, err := planSeriesEdit(series, model.SeriesEditFollowing, time.Date(2026, 11, 2, 9, 0, 0, 0, time.UTC), req, "clinic-1", "user-1")
+			if !errors.Is(err, repository.ErrInvalidInput) {
+				t.Errorf("planSeriesEdit(%q) moved a day error = %v; want ErrInvalidInput", rule, err)
+			}
+			if series.RRule != rule {
+				t.Errorf("planSeriesEdit(%q) changed the rule to %q", rule, series.RRule)
+			}
+
+			// Changing only the time of day is allowed
+			req = &model.UpdateAppointmentRequest{StartTime: strPtr("2026-11-02T11:00:00Z")}
+			if _, err := planSeriesEdit(newSeries(rule), model.SeriesEditFollowing, time.Date(2026, 11, 2, 9, 0, 0, 0, time.UTC), req, "clinic-1", "user-1"); err != nil {
+				t.Errorf("planSeriesEdit(%q) moved the time error = %v", rule, err)
+			}
+		}
+	})
+}
+
+func equalTimeSlices(a, b []time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+	return true
+}
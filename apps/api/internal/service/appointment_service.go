@@ -24,24 +24,51 @@ type AppointmentService interface {
 	GetByPatient(ctx context.Context, clinicID, patientID string, limit int) ([]model.AppointmentWithDetails, error)
 	GetByTherapist(ctx context.Context, clinicID, therapistID string, start, end time.Time) ([]model.AppointmentWithDetails, error)
 	GetDaySchedule(ctx context.Context, clinicID string, date time.Time) (*model.DaySchedule, error)
-	GetAvailableSlots(ctx context.Context, clinicID, therapistID string, date time.Time, duration int) ([]model.AvailabilitySlot, error)
+	GetAvailableSlots(ctx context.Context, clinicID, therapistID string, date time.Time, duration int, resourceIDs []string) ([]model.AvailabilitySlot, error)
+	SearchAvailability(ctx context.Context, clinicID string, req *model.AvailabilitySearchRequest) (*model.AvailabilitySearchResult, error)
 	Reschedule(ctx context.Context, clinicID, id, userID string, newStartTime time.Time) (*model.AppointmentWithDetails, error)
 	GetTherapists(ctx context.Context, clinicID string) ([]model.Therapist, error)
-	CheckConflicts(ctx context.Context, clinicID, therapistID string, start, end time.Time, excludeID string) ([]model.ConflictInfo, error)
+	CheckConflicts(ctx context.Context, clinicID, therapistID string, resourceIDs []string, start, end time.Time, excludeID string) ([]model.ConflictInfo, error)
+	PreviewSeries(ctx context.Context, clinicID string, req *model.CreateAppointmentRequest) (*model.SeriesPreview, error)
+	CreateSeries(ctx context.Context, clinicID, userID string, req *model.CreateAppointmentRequest) (*model.SeriesResult, error)
+	GetSeries(ctx context.Context, clinicID, id string) (*model.SeriesResult, error)
+	UpdateSeries(ctx context.Context, clinicID, id, userID string, req *model.UpdateSeriesRequest) (*model.SeriesUpdateResult, error)
 }
 
 // appointmentService implements AppointmentService.
 type appointmentService struct {
-	repo repository.AppointmentRepository
+	repo         repository.AppointmentRepository
+	resourceRepo repository.ResourceRepository
+	groupRepo    repository.GroupSessionRepository
+	typeRepo     repository.AppointmentTypeRepository
+	clinicRepo   repository.ClinicRepository
+	attendance   AttendanceService
+	events       EventPublisher
 }
 
 // NewAppointmentService creates a new appointment service.
-func NewAppointmentService(repo repository.AppointmentRepository) AppointmentService {
-	return &appointmentService{repo: repo}
+func NewAppointmentService(repo repository.AppointmentRepository, resourceRepo repository.ResourceRepository, groupRepo repository.GroupSessionRepository, typeRepo repository.AppointmentTypeRepository, clinicRepo repository.ClinicRepository, attendance AttendanceService, events EventPublisher) AppointmentService {
+	return &appointmentService{repo: repo, resourceRepo: resourceRepo, groupRepo: groupRepo, typeRepo: typeRepo, clinicRepo: clinicRepo, attendance: attendance, events: events}
 }
 
-// Create creates a new appointment with conflict checking and optional recurrence.
+// Create creates a new appointment with conflict checking. Recurring requests
+// create a whole series and return its first appointment.
 func (s *appointmentService) Create(ctx context.Context, clinicID, userID string, req *model.CreateAppointmentRequest) (*model.AppointmentWithDetails, error) {
+	if req.IsRecurring() {
+		result, err := s.CreateSeries(ctx, clinicID, userID, req)
+		if err != nil {
+			return nil, err
+		}
+		if len(result.Appointments) == 0 {
+			return nil, repository.ErrNotFound
+		}
+		return &result.Appointments[0], nil
+	}
+
+	if err := s.applyCreateType(ctx, clinicID, req); err != nil {
+		return nil, err
+	}
+
 	// Parse start time
 	startTime, err := time.Parse(time.RFC3339, req.StartTime)
 	if err != nil {
@@ -51,8 +78,14 @@ func (s *appointmentService) Create(ctx context.Context, clinicID, userID string
 	// Calculate end time
 	endTime := startTime.Add(time.Duration(req.Duration) * time.Minute)
 
+	resourceIDs := uniqueIDs(req.ResourceIDs)
+	room, err := defaultRoom(ctx, s.resourceRepo, clinicID, req.Room, resourceIDs)
+	if err != nil {
+		return nil, err
+	}
+
 	// Check for conflicts
-	conflicts, err := s.CheckConflicts(ctx, clinicID, req.TherapistID, startTime, endTime, "")
+	conflicts, err := s.CheckConflicts(ctx, clinicID, req.TherapistID, resourceIDs, startTime, endTime, "")
 	if err != nil {
 		return nil, fmt.Errorf("failed to check conflicts: %w", err)
 	}
@@ -61,34 +94,22 @@ func (s *appointmentService) Create(ctx context.Context, clinicID, userID string
 		return nil, fmt.Errorf("scheduling conflict: %s", conflicts[0].Message)
 	}
 
-	// Handle recurrence
-	recurrencePattern := model.RecurrencePattern(req.RecurrencePattern)
-	if recurrencePattern == "" {
-		recurrencePattern = model.RecurrenceNone
-	}
-
-	var recurrenceID *string
-	if recurrencePattern != model.RecurrenceNone {
-		id := uuid.New().String()
-		recurrenceID = &id
-	}
-
 	// Create the appointment
 	appointment := &model.Appointment{
-		ID:           uuid.New().String(),
-		ClinicID:     clinicID,
-		PatientID:    req.PatientID,
-		TherapistID:  req.TherapistID,
-		StartTime:    startTime,
-		EndTime:      endTime,
-		Duration:     req.Duration,
-		Type:         model.AppointmentType(req.Type),
-		Status:       model.AppointmentStatusScheduled,
-		Room:         req.Room,
-		Notes:        req.Notes,
-		RecurrenceID: recurrenceID,
-		CreatedBy:    &userID,
-		UpdatedBy:    &userID,
+		ID:          uuid.New().String(),
+		ClinicID:    clinicID,
+		PatientID:   req.PatientID,
+		TherapistID: req.TherapistID,
+		StartTime:   startTime,
+		EndTime:     endTime,
+		Duration:    req.Duration,
+		Type:        model.AppointmentType(req.Type),
+		Status:      model.AppointmentStatusScheduled,
+		Room:        room,
+		Notes:       req.Notes,
+		ResourceIDs: resourceIDs,
+		CreatedBy:   &userID,
+		UpdatedBy:   &userID,
 	}
 
 	if err := s.repo.Create(ctx, appointment); err != nil {
@@ -104,129 +125,80 @@ func (s *appointmentService) Create(ctx context.Context, clinicID, userID string
 		Str("created_by", userID).
 		Msg("appointment created")
 
-	// If recurring, create future appointments
-	if recurrencePattern != model.RecurrenceNone && recurrenceID != nil {
-		err := s.createRecurringAppointments(ctx, clinicID, userID, appointment, recurrencePattern, req.RecurrenceEndDate, req.RecurrenceCount)
-		if err != nil {
-			log.Warn().Err(err).Str("appointment_id", appointment.ID).Msg("failed to create some recurring appointments")
-		}
-	}
+	s.publish(ctx, model.EventAppointmentCreated, appointment, userID)
 
 	// Get the full appointment with details
-	return s.repo.GetByID(ctx, clinicID, appointment.ID)
+	return s.GetByID(ctx, clinicID, appointment.ID)
 }
 
-// createRecurringAppointments creates future appointments for a recurring series.
-func (s *appointmentService) createRecurringAppointments(
-	ctx context.Context,
-	clinicID, userID string,
-	original *model.Appointment,
-	pattern model.RecurrencePattern,
-	endDateStr *string,
-	maxCount *int,
-) error {
-	// Determine the recurrence interval
-	var interval time.Duration
-	switch pattern {
-	case model.RecurrenceDaily:
-		interval = 24 * time.Hour
-	case model.RecurrenceWeekly:
-		interval = 7 * 24 * time.Hour
-	case model.RecurrenceBiweekly:
-		interval = 14 * 24 * time.Hour
-	case model.RecurrenceMonthly:
-		// Handled specially below
-		interval = 0
-	default:
-		return nil
+// applyCreateType resolves the requested appointment type, records its code
+// on the request, defaults the duration from it and enforces its evaluation
+// requirement.
+func (s *appointmentService) applyCreateType(ctx context.Context, clinicID string, req *model.CreateAppointmentRequest) error {
+	t, err := resolveAppointmentType(ctx, s.typeRepo, clinicID, req.AppointmentTypeID, req.Type)
+	if err != nil {
+		return err
 	}
-
-	// Determine end condition
-	var endDate time.Time
-	count := 12 // Default: 12 occurrences (3 months for weekly)
-
-	if endDateStr != nil {
-		parsed, err := time.Parse("2006-01-02", *endDateStr)
-		if err == nil {
-			endDate = parsed
-		}
+	if err := checkEvaluation(ctx, s.repo, clinicID, req.PatientID, t); err != nil {
+		return err
 	}
 
-	if maxCount != nil && *maxCount > 0 {
-		count = *maxCount
+	req.Type = t.Code
+	if req.Duration == 0 {
+		req.Duration = t.DefaultDuration
 	}
+	return nil
+}
 
-	created := 0
-	currentStart := original.StartTime
-
-	for created < count {
-		// Calculate next occurrence
-		if pattern == model.RecurrenceMonthly {
-			currentStart = currentStart.AddDate(0, 1, 0)
-		} else {
-			currentStart = currentStart.Add(interval)
-		}
-
-		// Check end date condition
-		if !endDate.IsZero() && currentStart.After(endDate) {
-			break
-		}
-
-		currentEnd := currentStart.Add(time.Duration(original.Duration) * time.Minute)
-
-		// Check for conflicts
-		conflicts, err := s.repo.FindConflicts(ctx, clinicID, original.TherapistID, currentStart, currentEnd, "")
-		if err != nil {
-			log.Warn().Err(err).Time("start_time", currentStart).Msg("failed to check conflicts for recurring appointment")
-			continue
-		}
-
-		if len(conflicts) > 0 {
-			log.Info().
-				Time("start_time", currentStart).
-				Str("therapist_id", original.TherapistID).
-				Msg("skipping recurring appointment due to conflict")
-			// Don't count this one, try next slot
-			continue
-		}
+// checkTypeChange resolves the type an update changes an appointment to and
+// enforces its evaluation requirement for the appointment's patient.
+func (s *appointmentService) checkTypeChange(ctx context.Context, clinicID string, a *model.Appointment, req *model.UpdateAppointmentRequest) (*model.AppointmentTypeConfig, error) {
+	t, err := resolveAppointmentType(ctx, s.typeRepo, clinicID, "", *req.Type)
+	if err != nil {
+		return nil, err
+	}
+	if string(a.Type) == t.Code {
+		return t, nil
+	}
+	if err := checkEvaluation(ctx, s.repo, clinicID, a.PatientID, t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
 
-		// Create the recurring appointment
-		appt := &model.Appointment{
-			ID:           uuid.New().String(),
-			ClinicID:     clinicID,
-			PatientID:    original.PatientID,
-			TherapistID:  original.TherapistID,
-			StartTime:    currentStart,
-			EndTime:      currentEnd,
-			Duration:     original.Duration,
-			Type:         original.Type,
-			Status:       model.AppointmentStatusScheduled,
-			Room:         original.Room,
-			Notes:        original.Notes,
-			RecurrenceID: original.RecurrenceID,
-			CreatedBy:    &userID,
-			UpdatedBy:    &userID,
-		}
+// GetByID retrieves an appointment by ID.
+func (s *appointmentService) GetByID(ctx context.Context, clinicID, id string) (*model.AppointmentWithDetails, error) {
+	appointment, err := s.repo.GetByID(ctx, clinicID, id)
+	if err != nil {
+		return nil, err
+	}
 
-		if err := s.repo.Create(ctx, appt); err != nil {
-			log.Warn().Err(err).Time("start_time", currentStart).Msg("failed to create recurring appointment")
-			continue
-		}
+	appointments := []model.AppointmentWithDetails{*appointment}
+	s.withAttendance(ctx, clinicID, appointments)
+	return &appointments[0], nil
+}
 
-		created++
+// withAttendance adds each patient's no-show counters to the appointments.
+// The appointments are still returned if the counters cannot be loaded.
+func (s *appointmentService) withAttendance(ctx context.Context, clinicID string, appointments []model.AppointmentWithDetails) {
+	if len(appointments) == 0 {
+		return
 	}
 
-	log.Info().
-		Int("created_count", created).
-		Str("recurrence_id", *original.RecurrenceID).
-		Msg("recurring appointments created")
-
-	return nil
-}
+	patientIDs := make([]string, len(appointments))
+	for i, a := range appointments {
+		patientIDs[i] = a.PatientID
+	}
+	attendance, err := s.attendance.GetAttendance(ctx, clinicID, patientIDs)
+	if err != nil {
+		log.Warn().Err(err).Str("clinic_id", clinicID).Msg("failed to load patient attendance")
+		return
+	}
 
-// GetByID retrieves an appointment by ID.
-func (s *appointmentService) GetByID(ctx context.Context, clinicID, id string) (*model.AppointmentWithDetails, error) {
-	return s.repo.GetByID(ctx, clinicID, id)
+	for i := range appointments {
+		a := attendance[appointments[i].PatientID]
+		appointments[i].PatientAttendance = &a
+	}
 }
 
 // Update updates an existing appointment.
@@ -237,8 +209,15 @@ func (s *appointmentService) Update(ctx context.Context, clinicID, id, userID st
 		return nil, err
 	}
 
+	if existing.GroupSessionID != nil {
+		if err := checkGroupAppointmentUpdate(req); err != nil {
+			return nil, err
+		}
+	}
+
 	// Build updated appointment
 	appointment := &existing.Appointment
+	previousStatus := appointment.Status
 
 	if req.TherapistID != nil {
 		appointment.TherapistID = *req.TherapistID
@@ -268,7 +247,11 @@ func (s *appointmentService) Update(ctx context.Context, clinicID, id, userID st
 	}
 
 	if req.Type != nil {
-		appointment.Type = model.AppointmentType(*req.Type)
+		t, err := s.checkTypeChange(ctx, clinicID, appointment, req)
+		if err != nil {
+			return nil, err
+		}
+		appointment.Type = model.AppointmentType(t.Code)
 	}
 
 	if req.Status != nil {
@@ -283,11 +266,15 @@ func (s *appointmentService) Update(ctx context.Context, clinicID, id, userID st
 		appointment.Notes = *req.Notes
 	}
 
-	appointment.UpdatedBy = &userID
+	if req.ResourceIDs != nil {
+		appointment.ResourceIDs = uniqueIDs(req.ResourceIDs)
+	}
+
+	appointment.UpdatedBy = staffActor(userID)
 
-	// Check for conflicts if time or therapist changed
-	if req.StartTime != nil || req.Duration != nil || req.TherapistID != nil {
-		conflicts, err := s.CheckConflicts(ctx, clinicID, appointment.TherapistID, appointment.StartTime, appointment.EndTime, id)
+	// Check for conflicts if time, therapist or resources changed
+	if req.StartTime != nil || req.Duration != nil || req.TherapistID != nil || req.ResourceIDs != nil {
+		conflicts, err := s.CheckConflicts(ctx, clinicID, appointment.TherapistID, appointment.ResourceIDs, appointment.StartTime, appointment.EndTime, id)
 		if err != nil {
 			return nil, fmt.Errorf("failed to check conflicts: %w", err)
 		}
@@ -308,7 +295,9 @@ func (s *appointmentService) Update(ctx context.Context, clinicID, id, userID st
 		Str("updated_by", userID).
 		Msg("appointment updated")
 
-	return s.repo.GetByID(ctx, clinicID, id)
+	s.publish(ctx, appointmentEventType(previousStatus, appointment.Status), appointment, userID)
+
+	return s.GetByID(ctx, clinicID, id)
 }
 
 // Cancel cancels an appointment.
@@ -327,11 +316,31 @@ func (s *appointmentService) Cancel(ctx context.Context, clinicID, id, userID st
 		return fmt.Errorf("cannot cancel a completed appointment")
 	}
 
+	// A group class place is given up through the roster, which offers it to
+	// the waitlist
+	if existing.GroupSessionID != nil {
+		if err := s.groupRepo.CancelParticipantByAppointment(ctx, clinicID, id, req.Reason, staffActor(userID)); err != nil {
+			return err
+		}
+
+		log.Info().
+			Str("appointment_id", id).
+			Str("group_session_id", *existing.GroupSessionID).
+			Str("clinic_id", clinicID).
+			Str("cancelled_by", userID).
+			Str("reason", req.Reason).
+			Msg("group appointment cancelled")
+
+		s.publish(ctx, model.EventAppointmentCancelled, &existing.Appointment, userID)
+
+		return nil
+	}
+
 	// Update appointment status
 	appointment := &existing.Appointment
 	appointment.Status = model.AppointmentStatusCancelled
 	appointment.CancellationReason = req.Reason
-	appointment.UpdatedBy = &userID
+	appointment.UpdatedBy = staffActor(userID)
 
 	if err := s.repo.Update(ctx, appointment); err != nil {
 		return err
@@ -344,10 +353,14 @@ func (s *appointmentService) Cancel(ctx context.Context, clinicID, id, userID st
 		Str("reason", req.Reason).
 		Msg("appointment cancelled")
 
+	s.publish(ctx, model.EventAppointmentCancelled, appointment, userID)
+
 	// Cancel future recurring appointments if requested
 	if req.CancelSeries && existing.RecurrenceID != nil {
 		if err := s.repo.CancelByRecurrenceID(ctx, clinicID, *existing.RecurrenceID, req.Reason, existing.StartTime); err != nil {
 			log.Warn().Err(err).Str("recurrence_id", *existing.RecurrenceID).Msg("failed to cancel recurring appointments")
+		} else {
+			s.events.Publish(ctx, model.ClinicEvent{ClinicID: clinicID, Type: model.EventScheduleChanged, ActorID: userID})
 		}
 	}
 
@@ -365,15 +378,33 @@ func (s *appointmentService) Delete(ctx context.Context, clinicID, id string) er
 		Str("clinic_id", clinicID).
 		Msg("appointment deleted")
 
+	s.events.Publish(ctx, model.ClinicEvent{ClinicID: clinicID, Type: model.EventAppointmentDeleted, AppointmentID: id})
+
 	return nil
 }
 
 // List returns a paginated list of appointments.
 func (s *appointmentService) List(ctx context.Context, params model.AppointmentSearchParams) (*model.AppointmentListResponse, error) {
+	loc, err := clinicLocation(ctx, s.clinicRepo, params.ClinicID)
+	if err != nil {
+		return nil, err
+	}
+	// Date filters name calendar days at the clinic
+	if params.StartDate != nil {
+		start := dayIn(*params.StartDate, loc)
+		params.StartDate = &start
+	}
+	if params.EndDate != nil {
+		end := dayIn(*params.EndDate, loc)
+		params.EndDate = &end
+	}
+
 	appointments, total, err := s.repo.List(ctx, params)
 	if err != nil {
 		return nil, err
 	}
+	appointmentsIn(appointments, loc)
+	s.withAttendance(ctx, params.ClinicID, appointments)
 
 	perPage := params.Limit()
 	totalPages := int(total) / perPage
@@ -392,7 +423,13 @@ func (s *appointmentService) List(ctx context.Context, params model.AppointmentS
 
 // GetByDateRange retrieves appointments within a date range.
 func (s *appointmentService) GetByDateRange(ctx context.Context, clinicID string, start, end time.Time) ([]model.AppointmentWithDetails, error) {
-	return s.repo.GetByDateRange(ctx, clinicID, start, end)
+	appointments, err := s.repo.GetByDateRange(ctx, clinicID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	s.withAttendance(ctx, clinicID, appointments)
+	return appointments, nil
 }
 
 // GetByPatient retrieves appointments for a patient.
@@ -402,17 +439,45 @@ func (s *appointmentService) GetByPatient(ctx context.Context, clinicID, patient
 
 // GetByTherapist retrieves appointments for a therapist within a date range.
 func (s *appointmentService) GetByTherapist(ctx context.Context, clinicID, therapistID string, start, end time.Time) ([]model.AppointmentWithDetails, error) {
-	return s.repo.GetByTherapist(ctx, clinicID, therapistID, start, end)
+	appointments, err := s.repo.GetByTherapist(ctx, clinicID, therapistID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	s.withAttendance(ctx, clinicID, appointments)
+	return appointments, nil
 }
 
-// GetDaySchedule retrieves all appointments for a specific day.
+// GetDaySchedule retrieves all appointments for a specific day. The day is
+// the calendar date of date at the clinic, and times are returned in the
+// clinic's time zone.
 func (s *appointmentService) GetDaySchedule(ctx context.Context, clinicID string, date time.Time) (*model.DaySchedule, error) {
-	return s.repo.GetDaySchedule(ctx, clinicID, date)
+	loc, err := clinicLocation(ctx, s.clinicRepo, clinicID)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule, err := s.repo.GetDaySchedule(ctx, clinicID, dayIn(date, loc))
+	if err != nil {
+		return nil, err
+	}
+	schedule.Timezone = loc.String()
+	appointmentsIn(schedule.Appointments, loc)
+
+	s.withAttendance(ctx, clinicID, schedule.Appointments)
+	return schedule, nil
 }
 
 // GetAvailableSlots retrieves available time slots for a therapist on a given date.
-func (s *appointmentService) GetAvailableSlots(ctx context.Context, clinicID, therapistID string, date time.Time, duration int) ([]model.AvailabilitySlot, error) {
-	slots, err := s.repo.GetAvailableSlots(ctx, clinicID, therapistID, date, duration)
+// Only slots in which every required resource is free are returned. Working
+// hours are read on the clinic's wall clock for that date.
+func (s *appointmentService) GetAvailableSlots(ctx context.Context, clinicID, therapistID string, date time.Time, duration int, resourceIDs []string) ([]model.AvailabilitySlot, error) {
+	loc, err := clinicLocation(ctx, s.clinicRepo, clinicID)
+	if err != nil {
+		return nil, err
+	}
+
+	slots, err := s.repo.GetAvailableSlots(ctx, clinicID, therapistID, dayIn(date, loc), duration, uniqueIDs(resourceIDs))
 	if err != nil {
 		return nil, err
 	}
@@ -446,12 +511,15 @@ func (s *appointmentService) Reschedule(ctx context.Context, clinicID, id, userI
 	if existing.Status == model.AppointmentStatusCompleted {
 		return nil, fmt.Errorf("cannot reschedule a completed appointment")
 	}
+	if existing.GroupSessionID != nil {
+		return nil, fmt.Errorf("%w: the appointment belongs to group session %s; move the session instead", repository.ErrInvalidInput, *existing.GroupSessionID)
+	}
 
 	// Calculate new end time
 	newEndTime := newStartTime.Add(time.Duration(existing.Duration) * time.Minute)
 
 	// Check for conflicts
-	conflicts, err := s.CheckConflicts(ctx, clinicID, existing.TherapistID, newStartTime, newEndTime, id)
+	conflicts, err := s.CheckConflicts(ctx, clinicID, existing.TherapistID, existing.ResourceIDs, newStartTime, newEndTime, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check conflicts: %w", err)
 	}
@@ -474,8 +542,9 @@ func (s *appointmentService) GetTherapists(ctx context.Context, clinicID string)
 	return s.repo.GetTherapists(ctx, clinicID)
 }
 
-// CheckConflicts checks for scheduling conflicts.
-func (s *appointmentService) CheckConflicts(ctx context.Context, clinicID, therapistID string, start, end time.Time, excludeID string) ([]model.ConflictInfo, error) {
+// CheckConflicts checks for scheduling conflicts with the therapist's other
+// appointments and with bookings of the required resources.
+func (s *appointmentService) CheckConflicts(ctx context.Context, clinicID, therapistID string, resourceIDs []string, start, end time.Time, excludeID string) ([]model.ConflictInfo, error) {
 	conflicts, err := s.repo.FindConflicts(ctx, clinicID, therapistID, start, end, excludeID)
 	if err != nil {
 		return nil, err
@@ -483,12 +552,154 @@ func (s *appointmentService) CheckConflicts(ctx context.Context, clinicID, thera
 
 	var result []model.ConflictInfo
 	for _, c := range conflicts {
-		result = append(result, model.ConflictInfo{
-			ConflictType: "overlap",
-			Message:      fmt.Sprintf("Overlaps with existing appointment from %s to %s", c.StartTime.Format("15:04"), c.EndTime.Format("15:04")),
-			Appointment:  &c,
-		})
+		result = append(result, overlapConflict(c))
 	}
 
+	var exclude []string
+	if excludeID != "" {
+		exclude = []string{excludeID}
+	}
+	resources, bookings, err := s.resourceBookings(ctx, clinicID, resourceIDs, start, end, exclude)
+	if err != nil {
+		return nil, err
+	}
+	result = append(result, resourceConflicts(resources, bookings, start, end)...)
+
 	return result, nil
 }
+
+// resourceBookings loads the required resources and their bookings between
+// start and end, leaving out the excluded appointments. Unknown or inactive
+// resources are reported as invalid input.
+func (s *appointmentService) resourceBookings(ctx context.Context, clinicID string, resourceIDs []string, start, end time.Time, exclude []string) ([]model.Resource, []model.Appointment, error) {
+	if len(resourceIDs) == 0 {
+		return nil, nil, nil
+	}
+
+	resources, err := s.resourceRepo.GetByIDs(ctx, clinicID, resourceIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	bookings, err := s.repo.FindResourceBookings(ctx, clinicID, resourceIDs, start, end, exclude)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return resources, bookings, nil
+}
+
+// resourceConflicts reports each resource that has no capacity left between
+// start and end.
+func resourceConflicts(resources []model.Resource, bookings []model.Appointment, start, end time.Time) []model.ConflictInfo {
+	var conflicts []model.ConflictInfo
+	for _, res := range resources {
+		booked := res.Conflicts(bookings, start, end)
+		if booked == nil {
+			continue
+		}
+		first := booked[0]
+		conflicts = append(conflicts, model.ConflictInfo{
+			ConflictType: "resource",
+			Message: fmt.Sprintf("%s is fully booked (capacity %d) from %s to %s",
+				res.Name, res.Capacity, first.StartTime.Format("15:04"), first.EndTime.Format("15:04")),
+			Appointment: &first,
+			ResourceID:  res.ID,
+		})
+	}
+	return conflicts
+}
+
+// checkGroupAppointmentUpdate rejects changes to a group class appointment
+// that belong to its session: the time, therapist and resources are the
+// session's, and attendance and cancellation are kept on the roster.
+func checkGroupAppointmentUpdate(req *model.UpdateAppointmentRequest) error {
+	if req.TherapistID != nil || req.StartTime != nil || req.Duration != nil || req.ResourceIDs != nil {
+		return fmt.Errorf("%w: the appointment belongs to a group session; change the session instead", repository.ErrInvalidInput)
+	}
+	if req.Status != nil {
+		switch model.AppointmentStatus(*req.Status) {
+		case model.AppointmentStatusCompleted, model.AppointmentStatusNoShow, model.AppointmentStatusCancelled:
+			return fmt.Errorf("%w: record attendance and cancellations of a group session on its roster", repository.ErrInvalidInput)
+		}
+	}
+	return nil
+}
+
+// defaultRoom returns the room to record on an appointment: the given room,
+// or else the name of the first required resource that is a room.
+func defaultRoom(ctx context.Context, resourceRepo repository.ResourceRepository, clinicID, room string, resourceIDs []string) (string, error) {
+	if room != "" || len(resourceIDs) == 0 {
+		return room, nil
+	}
+
+	resources, err := resourceRepo.GetByIDs(ctx, clinicID, resourceIDs)
+	if err != nil {
+		return "", err
+	}
+	for _, res := range resources {
+		if res.Type == model.ResourceTypeRoom {
+			return res.Name, nil
+		}
+	}
+	return "", nil
+}
+
+// uniqueIDs returns the distinct IDs in their original order.
+func uniqueIDs(ids []string) []string {
+	if ids == nil {
+		return nil
+	}
+	seen := make(map[string]bool, len(ids))
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// publish sends a change to an appointment to the clinic's status board.
+func (s *appointmentService) publish(ctx context.Context, eventType model.ClinicEventType, a *model.Appointment, userID string) {
+	event := model.ClinicEvent{
+		ClinicID:      a.ClinicID,
+		Type:          eventType,
+		AppointmentID: a.ID,
+		PatientID:     a.PatientID,
+		TherapistID:   a.TherapistID,
+		ActorID:       userID,
+	}
+	if a.GroupSessionID != nil {
+		event.GroupSessionID = *a.GroupSessionID
+	}
+	s.events.Publish(ctx, event)
+}
+
+// appointmentEventType names an appointment update by the status it moved to.
+func appointmentEventType(from, to model.AppointmentStatus) model.ClinicEventType {
+	if from == to {
+		return model.EventAppointmentUpdated
+	}
+	switch to {
+	case model.AppointmentStatusInProgress:
+		return model.EventAppointmentStarted
+	case model.AppointmentStatusCompleted:
+		return model.EventAppointmentCompleted
+	case model.AppointmentStatusCancelled:
+		return model.EventAppointmentCancelled
+	case model.AppointmentStatusNoShow:
+		return model.EventAppointmentNoShow
+	}
+	return model.EventAppointmentUpdated
+}
+
+// staffActor returns the user to record as the author of a change. Changes made
+// through the patient portal pass an empty user ID and are not attributed to staff.
+func staffActor(userID string) *string {
+	if userID == "" {
+		return nil
+	}
+	return &userID
+}
@@ -0,0 +1,274 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/repository"
+)
+
+// appointmentTypeCodePattern is the form of appointment type codes, which
+// appointments record in their type.
+var appointmentTypeCodePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// AppointmentTypeService defines the interface for a clinic's appointment types.
+type AppointmentTypeService interface {
+	List(ctx context.Context, clinicID string, activeOnly bool) ([]model.AppointmentTypeConfig, error)
+	Get(ctx context.Context, clinicID, id string) (*model.AppointmentTypeConfig, error)
+	Create(ctx context.Context, clinicID, userID string, req *model.CreateAppointmentTypeRequest) (*model.AppointmentTypeConfig, error)
+	Update(ctx context.Context, clinicID, id, userID string, req *model.UpdateAppointmentTypeRequest) (*model.AppointmentTypeConfig, error)
+	Delete(ctx context.Context, clinicID, id, userID string) error
+}
+
+// appointmentTypeService implements AppointmentTypeService.
+type appointmentTypeService struct {
+	repo repository.AppointmentTypeRepository
+}
+
+// NewAppointmentTypeService creates a new appointment type service.
+func NewAppointmentTypeService(repo repository.AppointmentTypeRepository) AppointmentTypeService {
+	return &appointmentTypeService{repo: repo}
+}
+
+// List returns a clinic's appointment types. A clinic that has not configured
+// any gets the built-in types, which have no ID.
+func (s *appointmentTypeService) List(ctx context.Context, clinicID string, activeOnly bool) ([]model.AppointmentTypeConfig, error) {
+	types, err := s.repo.List(ctx, clinicID, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(types) == 0 {
+		defaults := model.DefaultAppointmentTypes()
+		for i := range defaults {
+			defaults[i].ClinicID = clinicID
+		}
+		return defaults, nil
+	}
+	if !activeOnly {
+		return types, nil
+	}
+
+	active := make([]model.AppointmentTypeConfig, 0, len(types))
+	for _, t := range types {
+		if t.IsActive {
+			active = append(active, t)
+		}
+	}
+	return active, nil
+}
+
+// Get returns an appointment type by ID.
+func (s *appointmentTypeService) Get(ctx context.Context, clinicID, id string) (*model.AppointmentTypeConfig, error) {
+	return s.repo.GetByID(ctx, clinicID, id)
+}
+
+// Create adds an appointment type to a clinic.
+func (s *appointmentTypeService) Create(ctx context.Context, clinicID, userID string, req *model.CreateAppointmentTypeRequest) (*model.AppointmentTypeConfig, error) {
+	code := strings.ToLower(strings.TrimSpace(req.Code))
+	if !appointmentTypeCodePattern.MatchString(code) {
+		return nil, fmt.Errorf("%w: code must start with a letter and contain only lowercase letters, digits and underscores", repository.ErrInvalidInput)
+	}
+
+	t := &model.AppointmentTypeConfig{
+		ID:                  uuid.New().String(),
+		ClinicID:            clinicID,
+		Name:                req.Name,
+		NameVi:              req.NameVi,
+		Code:                code,
+		Description:         req.Description,
+		DescriptionVi:       req.DescriptionVi,
+		DefaultDuration:     req.DefaultDuration,
+		Color:               req.Color,
+		Icon:                req.Icon,
+		RequiresEvaluation:  req.RequiresEvaluation,
+		IsEvaluation:        req.IsEvaluation,
+		AllowsGroup:         req.AllowsGroup,
+		MaxGroupSize:        req.MaxGroupSize,
+		DefaultBillingCodes: req.DefaultBillingCodes,
+		IsActive:            true,
+		SortOrder:           req.SortOrder,
+	}
+	if err := checkAppointmentTypeRules(t); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, t); err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("appointment_type_id", t.ID).
+		Str("code", t.Code).
+		Str("clinic_id", clinicID).
+		Str("created_by", userID).
+		Msg("appointment type created")
+
+	return t, nil
+}
+
+// Update changes an appointment type. Its code stays the same so existing
+// appointments keep their type.
+func (s *appointmentTypeService) Update(ctx context.Context, clinicID, id, userID string, req *model.UpdateAppointmentTypeRequest) (*model.AppointmentTypeConfig, error) {
+	t, err := s.repo.GetByID(ctx, clinicID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		t.Name = *req.Name
+	}
+	if req.NameVi != nil {
+		t.NameVi = *req.NameVi
+	}
+	if req.Description != nil {
+		t.Description = *req.Description
+	}
+	if req.DescriptionVi != nil {
+		t.DescriptionVi = *req.DescriptionVi
+	}
+	if req.DefaultDuration != nil {
+		t.DefaultDuration = *req.DefaultDuration
+	}
+	if req.Color != nil {
+		t.Color = *req.Color
+	}
+	if req.Icon != nil {
+		t.Icon = *req.Icon
+	}
+	if req.RequiresEvaluation != nil {
+		t.RequiresEvaluation = *req.RequiresEvaluation
+	}
+	if req.IsEvaluation != nil {
+		t.IsEvaluation = *req.IsEvaluation
+	}
+	if req.AllowsGroup != nil {
+		t.AllowsGroup = *req.AllowsGroup
+	}
+	if req.MaxGroupSize != nil {
+		t.MaxGroupSize = *req.MaxGroupSize
+	}
+	if req.DefaultBillingCodes != nil {
+		t.DefaultBillingCodes = req.DefaultBillingCodes
+	}
+	if req.IsActive != nil {
+		t.IsActive = *req.IsActive
+	}
+	if req.SortOrder != nil {
+		t.SortOrder = *req.SortOrder
+	}
+	if err := checkAppointmentTypeRules(t); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, t); err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("appointment_type_id", id).
+		Str("clinic_id", clinicID).
+		Str("updated_by", userID).
+		Msg("appointment type updated")
+
+	return t, nil
+}
+
+// Delete removes an appointment type that has never been booked. Types in use
+// are deactivated instead.
+func (s *appointmentTypeService) Delete(ctx context.Context, clinicID, id, userID string) error {
+	if err := s.repo.Delete(ctx, clinicID, id); err != nil {
+		return err
+	}
+
+	log.Info().
+		Str("appointment_type_id", id).
+		Str("clinic_id", clinicID).
+		Str("deleted_by", userID).
+		Msg("appointment type deleted")
+
+	return nil
+}
+
+// checkAppointmentTypeRules validates the combination of an appointment
+// type's settings and normalizes its group size.
+func checkAppointmentTypeRules(t *model.AppointmentTypeConfig) error {
+	if t.RequiresEvaluation && t.IsEvaluation {
+		return fmt.Errorf("%w: an evaluation type cannot itself require an evaluation", repository.ErrInvalidInput)
+	}
+	if !t.AllowsGroup {
+		t.MaxGroupSize = 1
+		return nil
+	}
+	if t.MaxGroupSize < 2 {
+		return fmt.Errorf("%w: group types need a max_group_size of at least 2", repository.ErrInvalidInput)
+	}
+	return nil
+}
+
+// resolveAppointmentType finds the active appointment type with the given ID,
+// or else the given code. A clinic that has not configured any types uses the
+// built-in ones.
+func resolveAppointmentType(ctx context.Context, repo repository.AppointmentTypeRepository, clinicID, id, code string) (*model.AppointmentTypeConfig, error) {
+	var t *model.AppointmentTypeConfig
+	var err error
+	if id != "" {
+		t, err = repo.GetByID(ctx, clinicID, id)
+	} else {
+		t, err = repo.GetByCode(ctx, clinicID, code)
+		if errors.Is(err, repository.ErrNotFound) {
+			return builtinAppointmentType(ctx, repo, clinicID, code)
+		}
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, fmt.Errorf("%w: unknown appointment type", repository.ErrInvalidInput)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !t.IsActive {
+		return nil, fmt.Errorf("%w: appointment type %s is inactive", repository.ErrInvalidInput, t.Name)
+	}
+	return t, nil
+}
+
+// builtinAppointmentType returns the built-in type with the given code for a
+// clinic that has no configured types.
+func builtinAppointmentType(ctx context.Context, repo repository.AppointmentTypeRepository, clinicID, code string) (*model.AppointmentTypeConfig, error) {
+	configured, err := repo.List(ctx, clinicID, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(configured) == 0 {
+		for _, t := range model.DefaultAppointmentTypes() {
+			if t.Code == code {
+				t.ClinicID = clinicID
+				return &t, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("%w: unknown appointment type %q", repository.ErrInvalidInput, code)
+}
+
+// checkEvaluation enforces an appointment type's requires_evaluation: the
+// patient must have completed an initial evaluation first.
+func checkEvaluation(ctx context.Context, repo repository.AppointmentRepository, clinicID, patientID string, t *model.AppointmentTypeConfig) error {
+	if !t.RequiresEvaluation {
+		return nil
+	}
+
+	evaluated, err := repo.HasCompletedEvaluation(ctx, clinicID, patientID)
+	if err != nil {
+		return err
+	}
+	if !evaluated {
+		return fmt.Errorf("%w: %s requires a completed initial evaluation", repository.ErrInvalidInput, t.Name)
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/repository"
+	"github.com/tqvdang/physioflow/apps/api/internal/storage"
+)
+
+// MaxAttachmentSize is the largest file accepted as a patient attachment.
+const MaxAttachmentSize = 25 << 20
+
+// AttachmentService defines the interface for patient file attachments.
+type AttachmentService interface {
+	Upload(ctx context.Context, clinicID, patientID, userID string, req *model.CreateAttachmentRequest, fileName, contentType string, content io.Reader) (*model.PatientAttachment, error)
+	List(ctx context.Context, clinicID, patientID string) ([]model.PatientAttachment, error)
+	Open(ctx context.Context, clinicID, patientID, id, userID string) (io.ReadCloser, *model.PatientAttachment, error)
+	Delete(ctx context.Context, clinicID, patientID, id, userID string) error
+}
+
+// attachmentService implements AttachmentService.
+type attachmentService struct {
+	repo        repository.AttachmentRepository
+	patientRepo repository.PatientRepository
+	auditRepo   repository.AuditRepository
+	blobs       storage.BlobStore
+	consents    ConsentService
+}
+
+// NewAttachmentService creates a new attachment service.
+func NewAttachmentService(repo repository.AttachmentRepository, patientRepo repository.PatientRepository, auditRepo repository.AuditRepository, blobs storage.BlobStore, consents ConsentService) AttachmentService {
+	return &attachmentService{
+		repo:        repo,
+		patientRepo: patientRepo,
+		auditRepo:   auditRepo,
+		blobs:       blobs,
+		consents:    consents,
+	}
+}
+
+// Upload stores a file and records it against the patient. Clinical photos
+// require the patient's photo_use consent.
+func (s *attachmentService) Upload(ctx context.Context, clinicID, patientID, userID string, req *model.CreateAttachmentRequest, fileName, contentType string, content io.Reader) (*model.PatientAttachment, error) {
+	if _, err := s.patientRepo.GetByID(ctx, clinicID, patientID); err != nil {
+		return nil, err
+	}
+
+	category := model.AttachmentCategory(req.Category)
+	if category == model.AttachmentCategoryPhoto {
+		if err := s.consents.RequireConsent(ctx, clinicID, patientID, model.ConsentTypePhotoUse); err != nil {
+			return nil, err
+		}
+	}
+
+	fileName = path.Base(strings.ReplaceAll(strings.TrimSpace(fileName), "\\", "/"))
+	if fileName == "" || fileName == "." || fileName == "/" {
+		return nil, fmt.Errorf("%w: file name is required", repository.ErrInvalidInput)
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	attachment := &model.PatientAttachment{
+		ID:          uuid.New().String(),
+		ClinicID:    clinicID,
+		PatientID:   patientID,
+		Category:    category,
+		FileName:    fileName,
+		ContentType: contentType,
+		Description: strings.TrimSpace(req.Description),
+		UploadedBy:  &userID,
+	}
+	attachment.BlobKey = fmt.Sprintf("attachments/%s/%s/%s", clinicID, patientID, attachment.ID)
+
+	info, err := s.blobs.Put(ctx, attachment.BlobKey, io.LimitReader(content, MaxAttachmentSize+1), contentType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store attachment: %w", err)
+	}
+	if info.Size > MaxAttachmentSize {
+		s.removeBlob(ctx, attachment.BlobKey)
+		return nil, fmt.Errorf("%w: file exceeds %d MB", repository.ErrInvalidInput, MaxAttachmentSize>>20)
+	}
+	attachment.SizeBytes = info.Size
+	attachment.SHA256 = info.SHA256
+
+	if err := s.repo.Create(ctx, attachment); err != nil {
+		s.removeBlob(ctx, attachment.BlobKey)
+		return nil, err
+	}
+
+	recordAudit(ctx, s.auditRepo, &model.AuditLog{
+		ClinicID:     &clinicID,
+		ActorID:      userID,
+		PatientID:    &patientID,
+		Action:       "attachment.upload",
+		ResourceType: "patient_attachment",
+		ResourceID:   attachment.ID,
+		Metadata: map[string]interface{}{
+			"category":   attachment.Category,
+			"size_bytes": attachment.SizeBytes,
+		},
+	})
+
+	log.Info().
+		Str("attachment_id", attachment.ID).
+		Str("patient_id", patientID).
+		Str("category", string(category)).
+		Int64("size_bytes", attachment.SizeBytes).
+		Msg("patient attachment uploaded")
+
+	return attachment, nil
+}
+
+// List returns the patient's attachments.
+func (s *attachmentService) List(ctx context.Context, clinicID, patientID string) ([]model.PatientAttachment, error) {
+	return s.repo.ListByPatient(ctx, clinicID, patientID)
+}
+
+// Open returns the attachment content. The caller must close the reader.
+func (s *attachmentService) Open(ctx context.Context, clinicID, patientID, id, userID string) (io.ReadCloser, *model.PatientAttachment, error) {
+	attachment, err := s.repo.GetByID(ctx, clinicID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if attachment.PatientID != patientID {
+		return nil, nil, repository.ErrNotFound
+	}
+
+	content, _, err := s.blobs.Get(ctx, attachment.BlobKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open attachment %s: %w", attachment.ID, err)
+	}
+
+	recordAudit(ctx, s.auditRepo, &model.AuditLog{
+		ClinicID:     &clinicID,
+		ActorID:      userID,
+		PatientID:    &patientID,
+		Action:       "attachment.download",
+		ResourceType: "patient_attachment",
+		ResourceID:   attachment.ID,
+	})
+
+	return content, attachment, nil
+}
+
+// Delete removes an attachment and its stored file.
+func (s *attachmentService) Delete(ctx context.Context, clinicID, patientID, id, userID string) error {
+	attachment, err := s.repo.GetByID(ctx, clinicID, id)
+	if err != nil {
+		return err
+	}
+	if attachment.PatientID != patientID {
+		return repository.ErrNotFound
+	}
+
+	if err := s.repo.Delete(ctx, clinicID, id, time.Now()); err != nil {
+		return err
+	}
+	s.removeBlob(ctx, attachment.BlobKey)
+
+	recordAudit(ctx, s.auditRepo, &model.AuditLog{
+		ClinicID:     &clinicID,
+		ActorID:      userID,
+		PatientID:    &patientID,
+		Action:       "attachment.delete",
+		ResourceType: "patient_attachment",
+		ResourceID:   id,
+	})
+
+	return nil
+}
+
+// removeBlob deletes a stored file, logging rather than failing on error.
+func (s *attachmentService) removeBlob(ctx context.Context, key string) {
+	if err := s.blobs.Delete(ctx, key); err != nil {
+		log.Error().Err(err).Str("blob_key", key).Msg("failed to delete attachment blob")
+	}
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/repository"
+)
+
+// AttendanceService defines the interface for no-show tracking and the
+// clinic's no-show policy.
+type AttendanceService interface {
+	GetPolicy(ctx context.Context, clinicID string) (*model.NoShowPolicy, error)
+	GetPatientAttendance(ctx context.Context, clinicID, patientID string) (*model.PatientAttendance, error)
+	GetAttendance(ctx context.Context, clinicID string, patientIDs []string) (map[string]model.PatientAttendance, error)
+	CheckSelfBooking(ctx context.Context, clinicID, patientID string) error
+	MarkNoShows(ctx context.Context) (int64, error)
+}
+
+// attendanceService implements AttendanceService.
+type attendanceService struct {
+	repo       repository.AppointmentRepository
+	clinicRepo repository.ClinicRepository
+	events     EventPublisher
+}
+
+// NewAttendanceService creates a new attendance service.
+func NewAttendanceService(repo repository.AppointmentRepository, clinicRepo repository.ClinicRepository, events EventPublisher) AttendanceService {
+	return &attendanceService{repo: repo, clinicRepo: clinicRepo, events: events}
+}
+
+// GetPolicy returns the clinic's no-show policy.
+func (s *attendanceService) GetPolicy(ctx context.Context, clinicID string) (*model.NoShowPolicy, error) {
+	policy, err := s.clinicRepo.GetNoShowPolicy(ctx, clinicID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get no-show policy: %w", err)
+	}
+	return policy, nil
+}
+
+// GetPatientAttendance returns a patient's counters and standing.
+func (s *attendanceService) GetPatientAttendance(ctx context.Context, clinicID, patientID string) (*model.PatientAttendance, error) {
+	attendance, err := s.GetAttendance(ctx, clinicID, []string{patientID})
+	if err != nil {
+		return nil, err
+	}
+	a := attendance[patientID]
+	return &a, nil
+}
+
+// GetAttendance returns the counters and standing of each of the patients,
+// including those who have never missed an appointment.
+func (s *attendanceService) GetAttendance(ctx context.Context, clinicID string, patientIDs []string) (map[string]model.PatientAttendance, error) {
+	policy, err := s.GetPolicy(ctx, clinicID)
+	if err != nil {
+		return nil, err
+	}
+
+	var since time.Time
+	var sincePtr *time.Time
+	if policy.LookbackDays > 0 {
+		since = time.Now().AddDate(0, 0, -policy.LookbackDays)
+		sincePtr = &since
+	}
+	lateNotice := time.Duration(policy.LateCancelNoticeHours) * time.Hour
+
+	patientIDs = uniqueIDs(patientIDs)
+	counts, err := s.repo.GetAttendance(ctx, clinicID, patientIDs, since, lateNotice)
+	if err != nil {
+		return nil, err
+	}
+
+	attendance := make(map[string]model.PatientAttendance, len(patientIDs))
+	for _, id := range patientIDs {
+		a := counts[id]
+		a.PatientID = id
+		a.Standing = policy.StandingFor(a.NoShowCount)
+		a.Since = sincePtr
+		attendance[id] = a
+	}
+	return attendance, nil
+}
+
+// CheckSelfBooking enforces the no-show policy on a patient booking through
+// the portal. Patients who have only been warned may still book.
+func (s *attendanceService) CheckSelfBooking(ctx context.Context, clinicID, patientID string) error {
+	attendance, err := s.GetPatientAttendance(ctx, clinicID, patientID)
+	if err != nil {
+		return err
+	}
+
+	switch attendance.Standing {
+	case model.AttendanceStandingBlocked:
+		return fmt.Errorf("%w: online booking is unavailable after %d missed appointments; please contact the clinic", ErrPolicyViolation, attendance.NoShowCount)
+	case model.AttendanceStandingDepositRequired:
+		return fmt.Errorf("%w: a deposit is required after %d missed appointments; please contact the clinic to book", ErrPolicyViolation, attendance.NoShowCount)
+	case model.AttendanceStandingWarned:
+		log.Info().
+			Str("patient_id", patientID).
+			Str("clinic_id", clinicID).
+			Int("no_show_count", attendance.NoShowCount).
+			Msg("self-booking by patient with repeated no-shows")
+	}
+	return nil
+}
+
+// MarkNoShows marks appointments nobody checked in for as no-shows once
+// their clinic's grace period has passed.
+func (s *attendanceService) MarkNoShows(ctx context.Context) (int64, error) {
+	grace := time.Duration(model.DefaultNoShowPolicy().GracePeriodMinutes) * time.Minute
+	marked, err := s.repo.MarkNoShows(ctx, grace)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(marked) > 0 {
+		log.Info().Int("count", len(marked)).Msg("appointments marked as no-show")
+	}
+	for _, a := range marked {
+		s.events.Publish(ctx, model.ClinicEvent{
+			ClinicID:      a.ClinicID,
+			Type:          model.EventAppointmentNoShow,
+			AppointmentID: a.ID,
+			PatientID:     a.PatientID,
+			TherapistID:   a.TherapistID,
+		})
+	}
+	return int64(len(marked)), nil
+}
+
+// RunNoShowJob marks no-shows every interval until the context is done.
+func RunNoShowJob(ctx context.Context, attendance AttendanceService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := attendance.MarkNoShows(ctx); err != nil {
+			log.Error().Err(err).Msg("no-show job failed")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/repository"
+)
+
+// attendanceStore returns fixed counters and records the window asked for.
+type attendanceStore struct {
+	repository.AppointmentRepository
+	counts     map[string]model.PatientAttendance
+	since      time.Time
+	lateNotice time.Duration
+}
+
+func (r *attendanceStore) GetAttendance(ctx context.Context, clinicID string, patientIDs []string, since time.Time, lateNotice time.Duration) (map[string]model.PatientAttendance, error) {
+	r.since, r.lateNotice = since, lateNotice
+	return r.counts, nil
+}
+
+// policyClinic is a clinic with a fixed no-show policy.
+type policyClinic struct {
+	repository.ClinicRepository
+	policy model.NoShowPolicy
+}
+
+func (r *policyClinic) GetNoShowPolicy(ctx context.Context, clinicID string) (*model.NoShowPolicy, error) {
+	p := r.policy
+	return &p, nil
+}
+
+func TestGetAttendancePolicyWindows(t *testing.T) {
+	tests := []struct {
+		name           string
+		policy         model.NoShowPolicy
+		wantLateNotice time.Duration
+		wantLookback   int // days; 0 counts the whole history
+	}{
+		{"default policy", model.DefaultNoShowPolicy(), 24 * time.Hour, 365},
+		{"two days notice over 90 days", model.NoShowPolicy{LateCancelNoticeHours: 48, LookbackDays: 90}, 48 * time.Hour, 90},
+		{"whole history", model.NoShowPolicy{LateCancelNoticeHours: 12}, 12 * time.Hour, 0},
+		{"no notice required", model.NoShowPolicy{LookbackDays: 30}, 0, 30},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &attendanceStore{}
+			s := &attendanceService{repo: store, clinicRepo: &policyClinic{policy: tt.policy}}
+
+			before := time.Now()
+			got, err := s.GetAttendance(context.Background(), "clinic-1", []string{"patient-1"})
+			if err != nil {
+				t.Fatalf("GetAttendance: %v", err)
+			}
+
+			if store.lateNotice != tt.wantLateNotice {
+				t.Errorf("late cancellation notice = %v, want %v", store.lateNotice, tt.wantLateNotice)
+			}
+			a := got["patient-1"]
+			if tt.wantLookback == 0 {
+				if !store.since.IsZero() || a.Since != nil {
+					t.Errorf("since = %v (reported %v), want the whole history", store.since, a.Since)
+				}
+				return
+			}
+			earliest := before.AddDate(0, 0, -tt.wantLookback)
+			if store.since.Before(earliest) || store.since.After(time.Now().AddDate(0, 0, -tt.wantLookback)) {
+				t.Errorf("since = %v, want %d days back", store.since, tt.wantLookback)
+			}
+			if a.Since == nil || !a.Since.Equal(store.since) {
+				t.Errorf("reported since = %v, want %v", a.Since, store.since)
+			}
+		})
+	}
+}
+
+func TestGetAttendanceStanding(t *testing.T) {
+	store := &attendanceStore{counts: map[string]model.PatientAttendance{
+		"patient-1": {PatientID: "patient-1", NoShowCount: 3, LateCancelCount: 2},
+		"patient-2": {PatientID: "patient-2", LateCancelCount: 5},
+	}}
+	policy := model.NoShowPolicy{LateCancelNoticeHours: 24, Threshold: 3, Action: model.NoShowActionBlock}
+	s := &attendanceService{repo: store, clinicRepo: &policyClinic{policy: policy}}
+
+	got, err := s.GetAttendance(context.Background(), "clinic-1", []string{"patient-1", "patient-2", "patient-3", "patient-1"})
+	if err != nil {
+		t.Fatalf("GetAttendance: %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("got %d patients, want 3", len(got))
+	}
+
+	// Late cancellations are counted but only no-shows set the standing
+	want := map[string]model.PatientAttendance{
+		"patient-1": {PatientID: "patient-1", NoShowCount: 3, LateCancelCount: 2, Standing: model.AttendanceStandingBlocked},
+		"patient-2": {PatientID: "patient-2", LateCancelCount: 5, Standing: model.AttendanceStandingGood},
+		"patient-3": {PatientID: "patient-3", Standing: model.AttendanceStandingGood},
+	}
+	for id, w := range want {
+		if a := got[id]; a.NoShowCount != w.NoShowCount || a.LateCancelCount != w.LateCancelCount || a.Standing != w.Standing || a.PatientID != id {
+			t.Errorf("%s = %+v, want %+v", id, a, w)
+		}
+	}
+}
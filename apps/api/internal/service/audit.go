@@ -0,0 +1,22 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/repository"
+)
+
+// recordAudit appends an entry to the audit trail, logging rather than failing
+// when the write fails so that the audited operation itself still succeeds.
+func recordAudit(ctx context.Context, repo repository.AuditRepository, entry *model.AuditLog) {
+	if entry.ID == "" {
+		entry.ID = uuid.New().String()
+	}
+	if err := repo.Create(ctx, entry); err != nil {
+		log.Error().Err(err).Str("action", entry.Action).Msg("failed to write audit log")
+	}
+}
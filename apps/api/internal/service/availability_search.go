@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/repository"
+)
+
+const (
+	defaultSearchDays  = 14
+	defaultSearchLimit = 20
+
+	// maxSlotsPerTherapistDay keeps one therapist's free morning from filling
+	// the whole result with back-to-back slots.
+	maxSlotsPerTherapistDay = 3
+
+	// continuityLookback is how far back a patient's appointments count
+	// towards finding their usual therapist.
+	continuityLookback = 365 * 24 * time.Hour
+)
+
+// Ranking weights for availability search. Every slot starts from
+// scoreBase and loses scorePerDayLater for each day after the first day
+// searched, so the usual therapist a few days later still ranks above
+// another therapist today, but not a fortnight later.
+const (
+	scoreBase               = 100
+	scorePerDayLater        = 3
+	scoreUsualTherapist     = 40
+	scoreSeenBefore         = 15
+	scorePreferredTherapist = 30
+	scorePreferredTime      = 25
+	scorePreferredDay       = 10
+)
+
+// SearchAvailability finds free slots across the clinic's therapists and a
+// range of days. Therapists are narrowed by the requested IDs and
+// specialties, slots by weekday and clock time; the remaining slots are ranked
+// by continuity of care with the patient's past therapists, the patient's
+// preferences and how soon they are.
+func (s *appointmentService) SearchAvailability(ctx context.Context, clinicID string, req *model.AvailabilitySearchRequest) (*model.AvailabilitySearchResult, error) {
+	loc, err := clinicLocation(ctx, s.clinicRepo, clinicID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Days, weekdays and clock times are the clinic's
+	now := time.Now()
+	from := todayIn(loc)
+	if req.StartDate != "" {
+		start, err := time.ParseInLocation("2006-01-02", req.StartDate, loc)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid start_date format", repository.ErrInvalidInput)
+		}
+		if start.After(from) {
+			from = start
+		}
+	}
+	days := req.Days
+	if days == 0 {
+		days = defaultSearchDays
+	}
+	to := from.AddDate(0, 0, days)
+
+	earliest, latest := normalizeClock(req.EarliestTime), normalizeClock(req.LatestTime)
+	if earliest != "" && latest != "" && earliest >= latest {
+		return nil, fmt.Errorf("%w: earliest_time must be before latest_time", repository.ErrInvalidInput)
+	}
+
+	therapists, err := s.candidateTherapists(ctx, clinicID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.AvailabilitySearchResult{
+		Slots:              []model.RankedSlot{},
+		TherapistsSearched: len(therapists),
+		From:               from,
+		To:                 to,
+	}
+	if len(therapists) == 0 {
+		return result, nil
+	}
+
+	visits := make(map[string]int)
+	if req.PatientID != "" {
+		history, err := s.repo.GetTherapistHistory(ctx, clinicID, req.PatientID, now.Add(-continuityLookback))
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range history {
+			visits[v.TherapistID] = v.Visits
+		}
+		if len(history) > 0 {
+			result.UsualTherapistID = history[0].TherapistID
+		}
+	}
+
+	ids := make([]string, len(therapists))
+	names := make(map[string]string, len(therapists))
+	for i, t := range therapists {
+		ids[i] = t.ID
+		names[t.ID] = t.FullName
+	}
+
+	slots, err := s.repo.FindAvailableSlots(ctx, clinicID, ids, from, to, req.Duration, uniqueIDs(req.ResourceIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	onDays := intSet(req.DaysOfWeek)
+	preferredDays := intSet(req.PreferredDaysOfWeek)
+	preferredTherapists := make(map[string]bool, len(req.PreferredTherapistIDs))
+	for _, id := range req.PreferredTherapistIDs {
+		preferredTherapists[id] = true
+	}
+
+	ranked := make([]model.RankedSlot, 0, len(slots))
+	for _, slot := range slots {
+		if len(onDays) > 0 && !onDays[int(slot.StartTime.Weekday())] {
+			continue
+		}
+		if earliest != "" && slot.StartTime.Format("15:04") < earliest {
+			continue
+		}
+		if latest != "" && slot.EndTime.Format("15:04") > latest {
+			continue
+		}
+
+		slot.TherapistName = names[slot.TherapistID]
+		rs := model.RankedSlot{AvailabilitySlot: slot, Reasons: []string{}}
+		daysLater := daysBetween(from, slot.StartTime)
+		rs.Score = scoreBase - scorePerDayLater*daysLater
+
+		switch {
+		case slot.TherapistID == result.UsualTherapistID:
+			rs.Score += scoreUsualTherapist
+			rs.Reasons = append(rs.Reasons, model.RankReasonUsualTherapist)
+		case visits[slot.TherapistID] > 0:
+			rs.Score += scoreSeenBefore
+			rs.Reasons = append(rs.Reasons, model.RankReasonSeenBefore)
+		}
+		if preferredTherapists[slot.TherapistID] {
+			rs.Score += scorePreferredTherapist
+			rs.Reasons = append(rs.Reasons, model.RankReasonPreferredTherapist)
+		}
+		if req.PreferredTimeOfDay != "" && model.TimeOfDayAt(slot.StartTime) == model.TimeOfDay(req.PreferredTimeOfDay) {
+			rs.Score += scorePreferredTime
+			rs.Reasons = append(rs.Reasons, model.RankReasonPreferredTime)
+		}
+		if preferredDays[int(slot.StartTime.Weekday())] {
+			rs.Score += scorePreferredDay
+			rs.Reasons = append(rs.Reasons, model.RankReasonPreferredDay)
+		}
+
+		if result.Earliest == nil || slot.StartTime.Before(result.Earliest.StartTime) {
+			earliestSlot := rs
+			result.Earliest = &earliestSlot
+		}
+		ranked = append(ranked, rs)
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if !a.StartTime.Equal(b.StartTime) {
+			return a.StartTime.Before(b.StartTime)
+		}
+		if a.TherapistName != b.TherapistName {
+			return a.TherapistName < b.TherapistName
+		}
+		return a.TherapistID < b.TherapistID
+	})
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultSearchLimit
+	}
+	perTherapistDay := make(map[string]int)
+	for _, rs := range ranked {
+		if len(result.Slots) == limit {
+			break
+		}
+		key := rs.TherapistID + "/" + rs.StartTime.Format("2006-01-02")
+		if perTherapistDay[key] == maxSlotsPerTherapistDay {
+			continue
+		}
+		perTherapistDay[key]++
+		result.Slots = append(result.Slots, rs)
+	}
+
+	return result, nil
+}
+
+// candidateTherapists returns the clinic's active therapists matching the
+// requested IDs and specialties.
+func (s *appointmentService) candidateTherapists(ctx context.Context, clinicID string, req *model.AvailabilitySearchRequest) ([]model.Therapist, error) {
+	therapists, err := s.repo.GetTherapists(ctx, clinicID)
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[string]bool, len(req.TherapistIDs))
+	for _, id := range req.TherapistIDs {
+		wanted[id] = true
+	}
+
+	candidates := make([]model.Therapist, 0, len(therapists))
+	for _, t := range therapists {
+		if len(wanted) > 0 && !wanted[t.ID] {
+			continue
+		}
+		if !t.HasSpecializations(req.Specialties) {
+			continue
+		}
+		candidates = append(candidates, t)
+	}
+
+	return candidates, nil
+}
+
+// intSet returns the values as a set.
+func intSet(values []int) map[int]bool {
+	set := make(map[int]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
@@ -0,0 +1,359 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/repository"
+	"github.com/tqvdang/physioflow/apps/api/pkg/ical"
+)
+
+// Calendar feed settings
+const (
+	calendarProdID      = "-//PhysioFlow//Appointments//EN"
+	calendarUIDDomain   = "physioflow"
+	calendarFeedPast    = 30 * 24 * time.Hour  // how far back a feed reaches
+	calendarFeedFuture  = 365 * 24 * time.Hour // how far ahead a feed reaches
+	calendarFeedRefresh = time.Hour
+	calendarTokenBytes  = 32
+)
+
+// CalendarService defines the interface for iCalendar feeds and per-appointment
+// .ics files.
+type CalendarService interface {
+	ListFeeds(ctx context.Context, clinicID string, kind model.CalendarFeedKind, ownerID string) ([]model.CalendarFeed, error)
+	IssueFeed(ctx context.Context, clinicID string, kind model.CalendarFeedKind, ownerID, createdBy string) (*model.CalendarFeed, error)
+	RevokeFeed(ctx context.Context, clinicID string, kind model.CalendarFeedKind, ownerID, id, revokedBy string) error
+	RenderFeed(ctx context.Context, kind model.CalendarFeedKind, token string) ([]byte, error)
+	AppointmentICS(ctx context.Context, clinicID, appointmentID string) ([]byte, error)
+	RenderAppointment(ctx context.Context, appointment *model.AppointmentWithDetails) ([]byte, error)
+}
+
+// calendarService implements CalendarService.
+type calendarService struct {
+	repo            repository.CalendarFeedRepository
+	appointmentRepo repository.AppointmentRepository
+	groupRepo       repository.GroupSessionRepository
+	patientRepo     repository.PatientRepository
+	clinicRepo      repository.ClinicRepository
+	types           AppointmentTypeService
+}
+
+// NewCalendarService creates a new calendar service.
+func NewCalendarService(repo repository.CalendarFeedRepository, appointmentRepo repository.AppointmentRepository, groupRepo repository.GroupSessionRepository, patientRepo repository.PatientRepository, clinicRepo repository.ClinicRepository, types AppointmentTypeService) CalendarService {
+	return &calendarService{
+		repo:            repo,
+		appointmentRepo: appointmentRepo,
+		groupRepo:       groupRepo,
+		patientRepo:     patientRepo,
+		clinicRepo:      clinicRepo,
+		types:           types,
+	}
+}
+
+// ListFeeds returns an owner's feeds, including revoked ones.
+func (s *calendarService) ListFeeds(ctx context.Context, clinicID string, kind model.CalendarFeedKind, ownerID string) ([]model.CalendarFeed, error) {
+	return s.repo.ListByOwner(ctx, clinicID, kind, ownerID)
+}
+
+// IssueFeed creates a feed with a new secret token, revoking the owner's
+// previous feed. The returned feed carries the token, which is not stored and
+// cannot be retrieved again.
+func (s *calendarService) IssueFeed(ctx context.Context, clinicID string, kind model.CalendarFeedKind, ownerID, createdBy string) (*model.CalendarFeed, error) {
+	if kind == model.CalendarFeedPatient {
+		if _, err := s.patientRepo.GetByID(ctx, clinicID, ownerID); err != nil {
+			return nil, err
+		}
+	}
+
+	token, err := newCalendarToken()
+	if err != nil {
+		return nil, err
+	}
+
+	feed := &model.CalendarFeed{
+		ID:        uuid.New().String(),
+		ClinicID:  clinicID,
+		Kind:      kind,
+		OwnerID:   ownerID,
+		TokenHash: hashToken(token),
+		CreatedBy: createdBy,
+		Token:     token,
+	}
+	if err := s.repo.Issue(ctx, feed); err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("feed_id", feed.ID).
+		Str("kind", string(kind)).
+		Str("owner_id", ownerID).
+		Str("created_by", createdBy).
+		Msg("calendar feed issued")
+
+	return feed, nil
+}
+
+// RevokeFeed stops a feed from being served.
+func (s *calendarService) RevokeFeed(ctx context.Context, clinicID string, kind model.CalendarFeedKind, ownerID, id, revokedBy string) error {
+	if err := s.repo.Revoke(ctx, clinicID, kind, ownerID, id, revokedBy, time.Now()); err != nil {
+		return err
+	}
+
+	log.Info().
+		Str("feed_id", id).
+		Str("kind", string(kind)).
+		Str("owner_id", ownerID).
+		Str("revoked_by", revokedBy).
+		Msg("calendar feed revoked")
+
+	return nil
+}
+
+// RenderFeed renders the calendar behind a feed token. Unknown and revoked
+// tokens are both reported as not found.
+func (s *calendarService) RenderFeed(ctx context.Context, kind model.CalendarFeedKind, token string) ([]byte, error) {
+	if token == "" {
+		return nil, repository.ErrNotFound
+	}
+	feed, err := s.repo.GetByTokenHash(ctx, kind, hashToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if !feed.IsActive() {
+		return nil, repository.ErrNotFound
+	}
+
+	loc, err := clinicLocation(ctx, s.clinicRepo, feed.ClinicID)
+	if err != nil {
+		return nil, err
+	}
+	labels := s.typeLabels(ctx, feed.ClinicID)
+
+	now := time.Now()
+	from, to := now.Add(-calendarFeedPast), now.Add(calendarFeedFuture)
+	appointments, err := s.appointmentRepo.GetCalendar(ctx, feed.ClinicID, kind, feed.OwnerID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	cal := &ical.Calendar{
+		ProdID:          calendarProdID,
+		Method:          ical.MethodPublish,
+		Location:        loc,
+		RefreshInterval: calendarFeedRefresh,
+		Events:          make([]ical.Event, 0, len(appointments)),
+	}
+
+	if kind == model.CalendarFeedTherapist {
+		cal.Name = "PhysioFlow schedule"
+		sessions, err := s.groupRepo.List(ctx, model.GroupSessionSearchParams{
+			ClinicID:         feed.ClinicID,
+			TherapistID:      feed.OwnerID,
+			From:             from,
+			To:               to,
+			IncludeCancelled: true,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range appointments {
+			cal.Events = append(cal.Events, therapistEvent(a, labels, now))
+		}
+		for _, gs := range sessions {
+			cal.Events = append(cal.Events, groupSessionEvent(gs, now))
+		}
+	} else {
+		cal.Name = "PhysioFlow appointments"
+		for _, a := range appointments {
+			cal.Events = append(cal.Events, patientEvent(a, labels, now))
+		}
+	}
+
+	if err := s.repo.Touch(ctx, feed.ID, now); err != nil {
+		log.Warn().Err(err).Str("feed_id", feed.ID).Msg("failed to record calendar feed access")
+	}
+
+	return cal.Bytes(), nil
+}
+
+// AppointmentICS renders one appointment as a patient-facing .ics file, for
+// attaching to reminder messages.
+func (s *calendarService) AppointmentICS(ctx context.Context, clinicID, appointmentID string) ([]byte, error) {
+	appointment, err := s.appointmentRepo.GetByID(ctx, clinicID, appointmentID)
+	if err != nil {
+		return nil, err
+	}
+	return s.RenderAppointment(ctx, appointment)
+}
+
+// RenderAppointment renders an appointment the caller has already loaded as a
+// patient-facing .ics file. Its UID matches the patient feed, so importing
+// the file and subscribing to the feed do not produce duplicates.
+func (s *calendarService) RenderAppointment(ctx context.Context, appointment *model.AppointmentWithDetails) ([]byte, error) {
+	loc, err := clinicLocation(ctx, s.clinicRepo, appointment.ClinicID)
+	if err != nil {
+		return nil, err
+	}
+
+	cal := &ical.Calendar{
+		ProdID:   calendarProdID,
+		Method:   ical.MethodPublish,
+		Location: loc,
+		Events:   []ical.Event{patientEvent(*appointment, s.typeLabels(ctx, appointment.ClinicID), time.Now())},
+	}
+	return cal.Bytes(), nil
+}
+
+// typeLabels maps the clinic's appointment type codes to bilingual labels.
+func (s *calendarService) typeLabels(ctx context.Context, clinicID string) map[string]string {
+	labels := make(map[string]string)
+	types, err := s.types.List(ctx, clinicID, false)
+	if err != nil {
+		log.Warn().Err(err).Str("clinic_id", clinicID).Msg("failed to load appointment types for calendar")
+		return labels
+	}
+	for _, t := range types {
+		label := t.Name
+		if t.NameVi != "" && t.NameVi != t.Name {
+			label += " / " + t.NameVi
+		}
+		labels[t.Code] = label
+	}
+	return labels
+}
+
+// therapistEvent describes an appointment for the therapist's calendar.
+// Calendars are synced to third-party services, so patients appear by
+// initials and MRN rather than by name, and clinical notes are left out.
+func therapistEvent(a model.AppointmentWithDetails, labels map[string]string, stamp time.Time) ical.Event {
+	summary := typeLabel(a.Type, labels)
+	if who := patientInitials(a.PatientName); who != "" {
+		summary += " - " + who
+		if a.PatientMRN != "" {
+			summary += " (" + a.PatientMRN + ")"
+		}
+	}
+
+	e := appointmentEvent(a, stamp)
+	e.Summary = summary
+	return e
+}
+
+// patientEvent describes an appointment for the patient's calendar.
+func patientEvent(a model.AppointmentWithDetails, labels map[string]string, stamp time.Time) ical.Event {
+	summary := typeLabel(a.Type, labels)
+	if a.TherapistName != "" {
+		summary += " - " + a.TherapistName
+	}
+
+	e := appointmentEvent(a, stamp)
+	e.Summary = summary
+	if a.Status == model.AppointmentStatusCancelled && a.CancellationReason != "" {
+		e.Description = "Cancelled: " + a.CancellationReason
+	}
+	return e
+}
+
+// appointmentEvent fills the parts of an appointment's event shared by every
+// audience.
+func appointmentEvent(a model.AppointmentWithDetails, stamp time.Time) ical.Event {
+	status := ical.StatusConfirmed
+	if a.Status == model.AppointmentStatusCancelled {
+		status = ical.StatusCancelled
+	}
+
+	return ical.Event{
+		UID:          calendarUID("appointment", a.ID),
+		Sequence:     calendarSequence(a.CreatedAt, a.UpdatedAt),
+		Stamp:        stamp,
+		Created:      a.CreatedAt,
+		LastModified: a.UpdatedAt,
+		Start:        a.StartTime,
+		End:          a.EndTime,
+		Location:     a.Room,
+		Status:       status,
+	}
+}
+
+// groupSessionEvent describes a group session for the therapist's calendar.
+func groupSessionEvent(gs model.GroupSession, stamp time.Time) ical.Event {
+	status := ical.StatusConfirmed
+	if gs.Status == model.GroupSessionStatusCancelled {
+		status = ical.StatusCancelled
+	}
+
+	return ical.Event{
+		UID:          calendarUID("group-session", gs.ID),
+		Sequence:     calendarSequence(gs.CreatedAt, gs.UpdatedAt),
+		Stamp:        stamp,
+		Created:      gs.CreatedAt,
+		LastModified: gs.UpdatedAt,
+		Start:        gs.StartTime,
+		End:          gs.EndTime,
+		Summary:      gs.Title,
+		Description:  fmt.Sprintf("Booked: %d/%d", gs.BookedCount, gs.Capacity),
+		Location:     gs.Room,
+		Status:       status,
+	}
+}
+
+// calendarUID returns the stable UID of a calendar event. It depends only on
+// the record's ID, so rescheduling or cancelling updates the existing event.
+func calendarUID(kind, id string) string {
+	return kind + "-" + id + "@" + calendarUIDDomain
+}
+
+// calendarSequence derives a SEQUENCE that grows with every update from the
+// seconds between a record's creation and its last update.
+func calendarSequence(created, updated time.Time) int {
+	seq := int(updated.Sub(created) / time.Second)
+	if seq < 0 {
+		return 0
+	}
+	return seq
+}
+
+// typeLabel returns the clinic's label for an appointment type code.
+func typeLabel(t model.AppointmentType, labels map[string]string) string {
+	if label, ok := labels[string(t)]; ok {
+		return label
+	}
+	return string(t)
+}
+
+// patientInitials abbreviates a name to its initials, e.g. "Nguyen Van An" to "N.V.A.".
+func patientInitials(name string) string {
+	var b strings.Builder
+	for _, part := range strings.Fields(name) {
+		r := []rune(part)
+		b.WriteString(strings.ToUpper(string(r[0])))
+		b.WriteByte('.')
+	}
+	return b.String()
+}
+
+// newCalendarToken returns a random URL-safe feed token.
+func newCalendarToken() (string, error) {
+	b := make([]byte, calendarTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate calendar token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken returns the stored form of a secret token such as a feed or kiosk token.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
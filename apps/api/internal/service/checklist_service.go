@@ -79,13 +79,15 @@ type GeneratedNote struct {
 type checklistService struct {
 	repo          *repository.Repository
 	soapGenerator *SOAPGenerator
+	events        EventPublisher
 }
 
 // newChecklistService creates a new ChecklistService.
-func newChecklistService(repo *repository.Repository) *checklistService {
+func newChecklistService(repo *repository.Repository, events EventPublisher) *checklistService {
 	return &checklistService{
 		repo:          repo,
 		soapGenerator: NewSOAPGenerator(),
+		events:        events,
 	}
 }
 
@@ -140,6 +142,8 @@ func (s *checklistService) StartChecklist(ctx context.Context, input StartCheckl
 		}
 	}
 
+	s.publish(ctx, model.EventChecklistStarted, checklist, input.TherapistID)
+
 	return checklist, nil
 }
 
@@ -391,9 +395,24 @@ func (s *checklistService) CompleteChecklist(ctx context.Context, id, userID str
 		return nil, err
 	}
 
+	s.publish(ctx, model.EventChecklistCompleted, checklist, userID)
+
 	return checklist, nil
 }
 
+// publish tells the clinic's status board that a patient's visit checklist
+// changed.
+func (s *checklistService) publish(ctx context.Context, eventType model.ClinicEventType, checklist *model.VisitChecklist, userID string) {
+	s.events.Publish(ctx, model.ClinicEvent{
+		ClinicID:    checklist.ClinicID,
+		Type:        eventType,
+		ChecklistID: checklist.ID,
+		PatientID:   checklist.PatientID,
+		TherapistID: checklist.TherapistID,
+		ActorID:     userID,
+	})
+}
+
 // GenerateNote generates a SOAP note from checklist responses.
 func (s *checklistService) GenerateNote(ctx context.Context, checklistID string) (*GeneratedNote, error) {
 	checklist, err := s.repo.VisitChecklist().GetByIDWithResponses(ctx, checklistID)
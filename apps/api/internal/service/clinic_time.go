@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/repository"
+)
+
+// clinicLocation returns the time zone a clinic schedules in. Day boundaries
+// and working hours are computed in this zone so that they follow the
+// clinic's wall clock, including daylight saving changes.
+func clinicLocation(ctx context.Context, clinicRepo repository.ClinicRepository, clinicID string) (*time.Location, error) {
+	tz, err := clinicRepo.GetTimezone(ctx, clinicID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get clinic timezone: %w", err)
+	}
+	return loadClinicLocation(clinicID, tz), nil
+}
+
+// loadClinicLocation loads an IANA time zone, falling back to the default
+// clinic time zone when the name is empty or unknown.
+func loadClinicLocation(clinicID, tz string) *time.Location {
+	if tz != "" {
+		if loc, err := time.LoadLocation(tz); err == nil {
+			return loc
+		}
+		log.Warn().
+			Str("clinic_id", clinicID).
+			Str("timezone", tz).
+			Msg("unknown clinic timezone, using default")
+	}
+
+	loc, err := time.LoadLocation(model.DefaultClinicTimezone)
+	if err != nil {
+		return time.FixedZone(model.DefaultClinicTimezone, 7*60*60)
+	}
+	return loc
+}
+
+// dayIn returns midnight in loc of the calendar date of t as written, so a
+// date parsed as "2006-01-02" in UTC becomes the start of that day at the clinic.
+func dayIn(t time.Time, loc *time.Location) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// todayIn returns the start of the current day in loc.
+func todayIn(loc *time.Location) time.Time {
+	return dayIn(time.Now().In(loc), loc)
+}
+
+// dayBounds returns the start of the calendar date of t in loc and the start
+// of the following day. Days in which clocks change are 23 or 25 hours long.
+func dayBounds(t time.Time, loc *time.Location) (time.Time, time.Time) {
+	start := dayIn(t, loc)
+	return start, start.AddDate(0, 0, 1)
+}
+
+// daysBetween counts the calendar days from the date of from to the date of
+// to, both read in from's location.
+func daysBetween(from, to time.Time) int {
+	fy, fm, fd := from.Date()
+	ty, tm, td := to.In(from.Location()).Date()
+	a := time.Date(fy, fm, fd, 0, 0, 0, 0, time.UTC)
+	b := time.Date(ty, tm, td, 0, 0, 0, 0, time.UTC)
+	return int(b.Sub(a).Hours() / 24)
+}
+
+// appointmentsIn expresses the appointments' times in loc.
+func appointmentsIn(appointments []model.AppointmentWithDetails, loc *time.Location) {
+	for i := range appointments {
+		appointments[i].StartTime = appointments[i].StartTime.In(loc)
+		appointments[i].EndTime = appointments[i].EndTime.In(loc)
+	}
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+)
+
+func TestDayBounds(t *testing.T) {
+	tests := []struct {
+		name      string
+		tz        string
+		date      string // as parsed by the handlers, in UTC
+		wantStart string
+		wantEnd   string
+		wantHours float64
+	}{
+		{
+			name:      "Ho Chi Minh City",
+			tz:        "Asia/Ho_Chi_Minh",
+			date:      "2026-10-18",
+			wantStart: "2026-10-18T00:00:00+07:00",
+			wantEnd:   "2026-10-19T00:00:00+07:00",
+			wantHours: 24,
+		},
+		{
+			name:      "New York spring forward",
+			tz:        "America/New_York",
+			date:      "2026-03-08",
+			wantStart: "2026-03-08T00:00:00-05:00",
+			wantEnd:   "2026-03-09T00:00:00-04:00",
+			wantHours: 23,
+		},
+		{
+			name:      "New York fall back",
+			tz:        "America/New_York",
+			date:      "2026-11-01",
+			wantStart: "2026-11-01T00:00:00-04:00",
+			wantEnd:   "2026-11-02T00:00:00-05:00",
+			wantHours: 25,
+		},
+		{
+			name:      "Sydney, ahead of UTC, falling back",
+			tz:        "Australia/Sydney",
+			date:      "2026-04-05",
+			wantStart: "2026-04-05T00:00:00+11:00",
+			wantEnd:   "2026-04-06T00:00:00+10:00",
+			wantHours: 25,
+		},
+		{
+			name:      "Last day of the year",
+			tz:        "Asia/Ho_Chi_Minh",
+			date:      "2026-12-31",
+			wantStart: "2026-12-31T00:00:00+07:00",
+			wantEnd:   "2027-01-01T00:00:00+07:00",
+			wantHours: 24,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc := loadClinicLocation("c1", tt.tz)
+			date, err := time.Parse("2006-01-02", tt.date)
+			if err != nil {
+				t.Fatalf("invalid date: %v", err)
+			}
+
+			start, end := dayBounds(date, loc)
+			if got := start.Format(time.RFC3339); got != tt.wantStart {
+				t.Errorf("Expected start %s, got %s", tt.wantStart, got)
+			}
+			if got := end.Format(time.RFC3339); got != tt.wantEnd {
+				t.Errorf("Expected end %s, got %s", tt.wantEnd, got)
+			}
+			if got := end.Sub(start).Hours(); got != tt.wantHours {
+				t.Errorf("Expected a %.0f-hour day, got %.0f", tt.wantHours, got)
+			}
+		})
+	}
+}
+
+func TestDayInKeepsCalendarDate(t *testing.T) {
+	loc := loadClinicLocation("c1", "Asia/Ho_Chi_Minh")
+
+	tests := []struct {
+		name string
+		t    time.Time
+		want string
+	}{
+		// 23:30 UTC is already the next morning in Vietnam; reading the
+		// instant at the clinic gives the clinic's date
+		{"late UTC evening read at the clinic", time.Date(2026, 10, 18, 23, 30, 0, 0, time.UTC).In(loc), "2026-10-19"},
+		{"parsed date", time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC), "2026-10-18"},
+		{"early clinic morning", time.Date(2026, 10, 18, 0, 15, 0, 0, loc), "2026-10-18"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := dayIn(tt.t, loc)
+			if got.Format("2006-01-02") != tt.want || got.Hour() != 0 || got.Location() != loc {
+				t.Errorf("Expected midnight of %s at the clinic, got %s", tt.want, got.Format(time.RFC3339))
+			}
+		})
+	}
+}
+
+func TestDaysBetween(t *testing.T) {
+	ny := loadClinicLocation("c1", "America/New_York")
+	hcm := loadClinicLocation("c1", "Asia/Ho_Chi_Minh")
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+		want     int
+	}{
+		{"same day", time.Date(2026, 3, 8, 0, 0, 0, 0, ny), time.Date(2026, 3, 8, 23, 0, 0, 0, ny), 0},
+		{"across spring forward", time.Date(2026, 3, 8, 0, 0, 0, 0, ny), time.Date(2026, 3, 9, 0, 30, 0, 0, ny), 1},
+		{"across fall back", time.Date(2026, 11, 1, 0, 0, 0, 0, ny), time.Date(2026, 11, 2, 0, 0, 0, 0, ny), 1},
+		{"UTC instant on the clinic's next day", time.Date(2026, 10, 18, 0, 0, 0, 0, hcm), time.Date(2026, 10, 18, 18, 0, 0, 0, time.UTC), 1},
+		{"a week", time.Date(2026, 3, 5, 0, 0, 0, 0, ny), time.Date(2026, 3, 12, 8, 0, 0, 0, ny), 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := daysBetween(tt.from, tt.to); got != tt.want {
+				t.Errorf("Expected %d days, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestLoadClinicLocation(t *testing.T) {
+	tests := []struct {
+		name string
+		tz   string
+		want string
+	}{
+		{"configured zone", "America/New_York", "America/New_York"},
+		{"empty falls back", "", model.DefaultClinicTimezone},
+		{"unknown falls back", "Mars/Olympus_Mons", model.DefaultClinicTimezone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := loadClinicLocation("c1", tt.tz).String(); got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/repository"
+)
+
+// ErrConsentRequired is returned when an action needs a consent the patient has not given.
+var ErrConsentRequired = errors.New("active patient consent required")
+
+// ConsentService defines the interface for patient consent management.
+//
+// Outbound paths must call RequireConsent before acting on a patient's data:
+// appointment reminders need data_processing (plus marketing_sms or
+// marketing_zalo for promotional content), clinical photo attachments need
+// photo_use, and data exports need data_processing.
+type ConsentService interface {
+	ListTemplates(ctx context.Context, clinicID string) ([]model.ConsentTemplate, error)
+	GetTemplate(ctx context.Context, id string) (*model.ConsentTemplate, error)
+	PublishTemplate(ctx context.Context, clinicID, userID string, req *model.CreateConsentTemplateRequest) (*model.ConsentTemplate, error)
+	RecordConsent(ctx context.Context, clinicID, patientID, recordedBy string, req *model.RecordConsentRequest) (*model.PatientConsent, error)
+	WithdrawConsent(ctx context.Context, clinicID, patientID, consentID, withdrawnBy string, req *model.WithdrawConsentRequest) error
+	ListPatientConsents(ctx context.Context, clinicID, patientID string) ([]model.PatientConsent, error)
+	GetPatientSummary(ctx context.Context, clinicID, patientID string) ([]model.ConsentSummary, error)
+	RequireConsent(ctx context.Context, clinicID, patientID string, consentType model.ConsentType) error
+}
+
+// consentService implements ConsentService.
+type consentService struct {
+	repo        repository.ConsentRepository
+	patientRepo repository.PatientRepository
+	auditRepo   repository.AuditRepository
+}
+
+// NewConsentService creates a new consent service.
+func NewConsentService(repo repository.ConsentRepository, patientRepo repository.PatientRepository, auditRepo repository.AuditRepository) ConsentService {
+	return &consentService{
+		repo:        repo,
+		patientRepo: patientRepo,
+		auditRepo:   auditRepo,
+	}
+}
+
+// ListTemplates returns the current consent text for every type available to the clinic.
+func (s *consentService) ListTemplates(ctx context.Context, clinicID string) ([]model.ConsentTemplate, error) {
+	return s.repo.ListCurrentTemplates(ctx, clinicID)
+}
+
+// GetTemplate returns a specific consent text version.
+func (s *consentService) GetTemplate(ctx context.Context, id string) (*model.ConsentTemplate, error) {
+	return s.repo.GetTemplate(ctx, id)
+}
+
+// PublishTemplate publishes a new clinic-specific version of a consent text.
+// Patients who signed an earlier version keep their consent but are flagged for renewal.
+func (s *consentService) PublishTemplate(ctx context.Context, clinicID, userID string, req *model.CreateConsentTemplateRequest) (*model.ConsentTemplate, error) {
+	tmpl := &model.ConsentTemplate{
+		ID:          uuid.New().String(),
+		ClinicID:    &clinicID,
+		ConsentType: model.ConsentType(req.ConsentType),
+		Title:       strings.TrimSpace(req.Title),
+		TitleVi:     strings.TrimSpace(req.TitleVi),
+		Body:        strings.TrimSpace(req.Body),
+		BodyVi:      strings.TrimSpace(req.BodyVi),
+		CreatedBy:   &userID,
+	}
+
+	if err := s.repo.CreateTemplate(ctx, tmpl); err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("template_id", tmpl.ID).
+		Str("clinic_id", clinicID).
+		Str("consent_type", string(tmpl.ConsentType)).
+		Int("version", tmpl.Version).
+		Msg("consent template published")
+
+	return tmpl, nil
+}
+
+// RecordConsent captures a patient's signed consent to the current text of a consent type.
+func (s *consentService) RecordConsent(ctx context.Context, clinicID, patientID, recordedBy string, req *model.RecordConsentRequest) (*model.PatientConsent, error) {
+	consentType := model.ConsentType(req.ConsentType)
+
+	if req.Signature == nil || strings.TrimSpace(req.Signature.SignatureData) == "" {
+		return nil, fmt.Errorf("%w: signature is required", repository.ErrInvalidInput)
+	}
+
+	if _, err := s.patientRepo.GetByID(ctx, clinicID, patientID); err != nil {
+		return nil, err
+	}
+
+	current, err := s.repo.GetCurrentTemplate(ctx, clinicID, consentType)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("%w: no consent text is published for %s", repository.ErrInvalidInput, consentType)
+		}
+		return nil, err
+	}
+	if req.TemplateID != "" && req.TemplateID != current.ID {
+		return nil, fmt.Errorf("%w: consent text has been updated, please review the current version", repository.ErrInvalidInput)
+	}
+
+	signature := *req.Signature
+	if signature.SignedAt.IsZero() {
+		signature.SignedAt = time.Now()
+	}
+
+	consent := &model.PatientConsent{
+		ID:                 uuid.New().String(),
+		ClinicID:           clinicID,
+		PatientID:          patientID,
+		TemplateID:         current.ID,
+		ConsentType:        consentType,
+		TemplateVersion:    current.Version,
+		Status:             model.ConsentStatusActive,
+		Signature:          signature,
+		SignedByName:       strings.TrimSpace(req.SignedByName),
+		SignerRelationship: strings.TrimSpace(req.SignerRelationship),
+		GrantedAt:          time.Now(),
+		RecordedBy:         recordedBy,
+		Title:              current.Title,
+		TitleVi:            current.TitleVi,
+	}
+
+	if err := s.repo.CreateConsent(ctx, consent); err != nil {
+		return nil, err
+	}
+
+	recordAudit(ctx, s.auditRepo, &model.AuditLog{
+		ClinicID:     &clinicID,
+		ActorID:      recordedBy,
+		PatientID:    &patientID,
+		Action:       "consent.grant",
+		ResourceType: "patient_consent",
+		ResourceID:   consent.ID,
+		Metadata: map[string]interface{}{
+			"consent_type":     consent.ConsentType,
+			"template_version": consent.TemplateVersion,
+		},
+	})
+
+	log.Info().
+		Str("consent_id", consent.ID).
+		Str("patient_id", patientID).
+		Str("consent_type", string(consentType)).
+		Str("recorded_by", recordedBy).
+		Msg("patient consent recorded")
+
+	return consent, nil
+}
+
+// WithdrawConsent withdraws an active consent. Withdrawal takes effect
+// immediately: later RequireConsent checks for the type fail until the patient
+// consents again. Processing done before withdrawal is unaffected.
+func (s *consentService) WithdrawConsent(ctx context.Context, clinicID, patientID, consentID, withdrawnBy string, req *model.WithdrawConsentRequest) error {
+	consent, err := s.repo.GetConsent(ctx, clinicID, consentID)
+	if err != nil {
+		return err
+	}
+	if consent.PatientID != patientID {
+		return repository.ErrNotFound
+	}
+
+	if err := s.repo.Withdraw(ctx, clinicID, consentID, withdrawnBy, strings.TrimSpace(req.Reason), time.Now()); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.auditRepo, &model.AuditLog{
+		ClinicID:     &clinicID,
+		ActorID:      withdrawnBy,
+		PatientID:    &patientID,
+		Action:       "consent.withdraw",
+		ResourceType: "patient_consent",
+		ResourceID:   consentID,
+		Metadata: map[string]interface{}{
+			"consent_type": consent.ConsentType,
+			"reason":       req.Reason,
+		},
+	})
+
+	log.Info().
+		Str("consent_id", consentID).
+		Str("patient_id", patientID).
+		Str("consent_type", string(consent.ConsentType)).
+		Str("withdrawn_by", withdrawnBy).
+		Msg("patient consent withdrawn")
+
+	return nil
+}
+
+// ListPatientConsents returns the patient's full consent history.
+func (s *consentService) ListPatientConsents(ctx context.Context, clinicID, patientID string) ([]model.PatientConsent, error) {
+	return s.repo.ListByPatient(ctx, clinicID, patientID)
+}
+
+// GetPatientSummary reports, for every consent type, whether the patient has an
+// active consent and whether it was given to an outdated text.
+func (s *consentService) GetPatientSummary(ctx context.Context, clinicID, patientID string) ([]model.ConsentSummary, error) {
+	templates, err := s.repo.ListCurrentTemplates(ctx, clinicID)
+	if err != nil {
+		return nil, err
+	}
+	currentVersions := make(map[model.ConsentType]*model.ConsentTemplate, len(templates))
+	for i := range templates {
+		currentVersions[templates[i].ConsentType] = &templates[i]
+	}
+
+	consents, err := s.repo.ListByPatient(ctx, clinicID, patientID)
+	if err != nil {
+		return nil, err
+	}
+	active := make(map[model.ConsentType]*model.PatientConsent)
+	for i := range consents {
+		if consents[i].Status == model.ConsentStatusActive {
+			active[consents[i].ConsentType] = &consents[i]
+		}
+	}
+
+	summaries := make([]model.ConsentSummary, 0, len(model.AllConsentTypes))
+	for _, ct := range model.AllConsentTypes {
+		summary := model.ConsentSummary{ConsentType: ct}
+		if tmpl, ok := currentVersions[ct]; ok {
+			summary.CurrentVersion = tmpl.Version
+		}
+		if consent, ok := active[ct]; ok {
+			summary.Active = true
+			summary.Consent = consent
+			if tmpl, ok := currentVersions[ct]; ok && tmpl.ID != consent.TemplateID {
+				summary.NeedsRenewal = true
+			}
+		}
+		summaries = append(summaries, summary)
+	}
+
+	return summaries, nil
+}
+
+// RequireConsent returns ErrConsentRequired unless the patient has an active consent of the type.
+func (s *consentService) RequireConsent(ctx context.Context, clinicID, patientID string, consentType model.ConsentType) error {
+	_, err := s.repo.GetActiveConsent(ctx, clinicID, patientID, consentType)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("%w: %s", ErrConsentRequired, consentType)
+		}
+		return fmt.Errorf("failed to check consent: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,649 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/repository"
+	"github.com/tqvdang/physioflow/apps/api/pkg/validator"
+)
+
+const (
+	// MaxExerciseImportSize is the largest exercise library file accepted.
+	MaxExerciseImportSize = 5 << 20
+	// MaxExerciseImportRows is the most exercises accepted in one import.
+	MaxExerciseImportRows = 2000
+)
+
+// exerciseCSVHeader is the header row of exercise library CSV files. List
+// cells hold values separated by exerciseCSVListSeparator.
+var exerciseCSVHeader = []string{
+	"source_id", "name", "name_vi", "description", "description_vi",
+	"instructions", "instructions_vi", "category", "difficulty",
+	"equipment", "muscle_groups", "image_url", "video_url",
+	"default_sets", "default_reps", "default_hold_secs",
+	"precautions", "precautions_vi", "media",
+}
+
+// exerciseCSVListSeparator separates values in the list cells of exercise
+// library CSV files.
+const exerciseCSVListSeparator = "|"
+
+// ExerciseLibraryService defines the interface for importing, exporting and
+// sharing exercise libraries.
+type ExerciseLibraryService interface {
+	Export(ctx context.Context, clinicID string, params model.ExportExercisesParams) ([]byte, error)
+	Import(ctx context.Context, clinicID, userID string, params model.ImportExercisesParams, content io.Reader) (*model.ExerciseImportResult, error)
+	Publish(ctx context.Context, clinicID, userID, exerciseID string) (*model.ExercisePublishResult, error)
+}
+
+// exerciseLibraryService implements ExerciseLibraryService.
+type exerciseLibraryService struct {
+	exerciseRepo repository.ExerciseRepository
+	mediaRepo    repository.ExerciseMediaRepository
+	clinicRepo   repository.ClinicRepository
+	exercises    ExerciseService
+	media        ExerciseMediaService
+}
+
+// NewExerciseLibraryService creates a new exercise library service.
+func NewExerciseLibraryService(exerciseRepo repository.ExerciseRepository, mediaRepo repository.ExerciseMediaRepository, clinicRepo repository.ClinicRepository, exercises ExerciseService, media ExerciseMediaService) ExerciseLibraryService {
+	return &exerciseLibraryService{
+		exerciseRepo: exerciseRepo,
+		mediaRepo:    mediaRepo,
+		clinicRepo:   clinicRepo,
+		exercises:    exercises,
+		media:        media,
+	}
+}
+
+// Export writes the clinic's exercises, and optionally the global library,
+// as JSON or CSV. Uploaded media are included as references.
+func (s *exerciseLibraryService) Export(ctx context.Context, clinicID string, params model.ExportExercisesParams) ([]byte, error) {
+	exercises, err := s.exerciseRepo.ListLibrary(ctx, clinicID, params.IncludeGlobal)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(exercises))
+	for i, e := range exercises {
+		ids[i] = e.ID
+	}
+	media, err := s.mediaRepo.ListByExercises(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byExercise := make(map[string][]model.ExerciseMedia)
+	for _, m := range media {
+		byExercise[m.ExerciseID] = append(byExercise[m.ExerciseID], m)
+	}
+
+	library := model.ExerciseLibrary{
+		Version:    model.ExerciseLibraryVersion,
+		ExportedAt: time.Now().UTC(),
+		ClinicID:   clinicID,
+		Exercises:  make([]model.ExerciseLibraryItem, len(exercises)),
+	}
+	for i, e := range exercises {
+		library.Exercises[i] = exerciseLibraryItem(e, byExercise[e.ID])
+	}
+
+	if model.ExerciseLibraryFormat(params.Format) == model.ExerciseLibraryCSV {
+		return encodeExerciseCSV(library.Exercises)
+	}
+	data, err := json.MarshalIndent(library, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode exercise library: %w", err)
+	}
+	return data, nil
+}
+
+// exerciseLibraryItem converts an exercise and its media to a library item.
+func exerciseLibraryItem(e model.Exercise, media []model.ExerciseMedia) model.ExerciseLibraryItem {
+	item := model.ExerciseLibraryItem{
+		SourceID:              e.ID,
+		CreateExerciseRequest: *exerciseRequest(e),
+	}
+	for _, m := range media {
+		item.Media = append(item.Media, model.ExerciseLibraryMedia{
+			ID:          m.ID,
+			URL:         m.URL(),
+			Kind:        m.Kind,
+			Caption:     m.Caption,
+			CaptionVi:   m.CaptionVi,
+			FileName:    m.FileName,
+			ContentType: m.ContentType,
+			SHA256:      m.SHA256,
+		})
+	}
+	return item
+}
+
+// exerciseRequest converts an exercise to the request that would create it.
+func exerciseRequest(e model.Exercise) *model.CreateExerciseRequest {
+	muscleGroups := make([]string, len(e.MuscleGroups))
+	for i, mg := range e.MuscleGroups {
+		muscleGroups[i] = string(mg)
+	}
+	return &model.CreateExerciseRequest{
+		Name:            e.Name,
+		NameVi:          e.NameVi,
+		Description:     e.Description,
+		DescriptionVi:   e.DescriptionVi,
+		Instructions:    e.Instructions,
+		InstructionsVi:  e.InstructionsVi,
+		Category:        string(e.Category),
+		Difficulty:      string(e.Difficulty),
+		Equipment:       e.Equipment,
+		MuscleGroups:    muscleGroups,
+		ImageURL:        e.ImageURL,
+		VideoURL:        e.VideoURL,
+		DefaultSets:     e.DefaultSets,
+		DefaultReps:     e.DefaultReps,
+		DefaultHoldSecs: e.DefaultHoldSecs,
+		Precautions:     e.Precautions,
+		PrecautionsVi:   e.PrecautionsVi,
+	}
+}
+
+// Import reads a JSON or CSV exercise library and creates its exercises in
+// the clinic's library. Every exercise is validated by the rules for creating
+// one, and exercises whose English or Vietnamese name matches one already in
+// the clinic's or global library, or earlier in the file, are skipped unless
+// duplicates are allowed. Referenced media are copied when they belong to a
+// clinic of the same tenant. A dry run only reports what would happen.
+func (s *exerciseLibraryService) Import(ctx context.Context, clinicID, userID string, params model.ImportExercisesParams, content io.Reader) (*model.ExerciseImportResult, error) {
+	var items []model.ExerciseLibraryItem
+	var parseErrors []map[string]string
+	var err error
+	if model.ExerciseLibraryFormat(params.Format) == model.ExerciseLibraryCSV {
+		items, parseErrors, err = decodeExerciseCSV(content)
+	} else {
+		items, err = decodeExerciseJSON(content)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("%w: the file contains no exercises", repository.ErrInvalidInput)
+	}
+	if len(items) > MaxExerciseImportRows {
+		return nil, fmt.Errorf("%w: at most %d exercises can be imported at once", repository.ErrInvalidInput, MaxExerciseImportRows)
+	}
+
+	result := &model.ExerciseImportResult{
+		DryRun: params.DryRun,
+		Total:  len(items),
+		Rows:   make([]model.ExerciseImportRow, len(items)),
+	}
+
+	names := make([]string, 0, 2*len(items))
+	for i := range items {
+		trimExerciseRequest(&items[i].CreateExerciseRequest)
+		names = append(names, exerciseNameKey(items[i].Name), exerciseNameKey(items[i].NameVi))
+	}
+	existing, err := s.exerciseRepo.FindByNames(ctx, clinicID, names)
+	if err != nil {
+		return nil, err
+	}
+	library := make(map[string]string)
+	for _, e := range existing {
+		for _, name := range []string{e.Name, e.NameVi} {
+			if key := exerciseNameKey(name); key != "" && library[key] == "" {
+				library[key] = e.ID
+			}
+		}
+	}
+
+	tenantClinics, err := s.tenantClinicIDs(ctx, clinicID)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]int)
+	for i, item := range items {
+		row := &result.Rows[i]
+		row.Row = i + 1
+		row.Name = item.Name
+		row.NameVi = item.NameVi
+
+		if i < len(parseErrors) && len(parseErrors[i]) > 0 {
+			row.Errors = parseErrors[i]
+		}
+		if err := validator.Validate(item.CreateExerciseRequest); err != nil {
+			if row.Errors == nil {
+				row.Errors = map[string]string{}
+			}
+			for field, msg := range validator.FormatErrors(err) {
+				row.Errors[field] = msg
+			}
+		}
+		if len(row.Errors) > 0 {
+			row.Status = model.ExerciseImportInvalid
+			result.Invalid++
+			continue
+		}
+
+		duplicateOf := ""
+		for _, name := range []string{item.Name, item.NameVi} {
+			key := exerciseNameKey(name)
+			if id, ok := library[key]; ok && duplicateOf == "" {
+				duplicateOf = id
+			} else if n, ok := seen[key]; ok && duplicateOf == "" {
+				duplicateOf = fmt.Sprintf("row %d", n)
+			}
+		}
+		for _, name := range []string{item.Name, item.NameVi} {
+			if key := exerciseNameKey(name); seen[key] == 0 {
+				seen[key] = row.Row
+			}
+		}
+		if duplicateOf != "" {
+			row.DuplicateOf = duplicateOf
+			if !params.AllowDuplicates {
+				row.Status = model.ExerciseImportDuplicate
+				result.Duplicates++
+				continue
+			}
+			row.Warnings = append(row.Warnings, fmt.Sprintf("an exercise with the same name exists (%s)", duplicateOf))
+		}
+
+		sources, warnings := s.resolveMedia(ctx, tenantClinics, item.Media)
+		row.Warnings = append(row.Warnings, warnings...)
+
+		if params.DryRun {
+			row.Status = model.ExerciseImportValid
+			result.Valid++
+			continue
+		}
+
+		req := item.CreateExerciseRequest
+		exercise, err := s.exercises.CreateExercise(ctx, clinicID, userID, &req)
+		if err != nil {
+			log.Error().Err(err).Int("row", row.Row).Str("name", item.Name).Msg("failed to import exercise")
+			row.Status = model.ExerciseImportFailed
+			row.Errors = map[string]string{"exercise": "Failed to create exercise"}
+			result.Failed++
+			continue
+		}
+		row.Status = model.ExerciseImportCreated
+		row.ExerciseID = exercise.ID
+		result.Valid++
+		result.Created++
+
+		for _, src := range sources {
+			if _, err := s.media.Copy(ctx, src, clinicID, exercise.ID, userID); err != nil {
+				log.Warn().Err(err).Str("media_id", src.ID).Str("exercise_id", exercise.ID).Msg("failed to copy imported exercise media")
+				row.Warnings = append(row.Warnings, fmt.Sprintf("media %s could not be copied", src.ID))
+				continue
+			}
+			row.MediaCopied++
+		}
+	}
+
+	log.Info().
+		Str("clinic_id", clinicID).
+		Str("user_id", userID).
+		Bool("dry_run", params.DryRun).
+		Int("total", result.Total).
+		Int("created", result.Created).
+		Int("duplicates", result.Duplicates).
+		Int("invalid", result.Invalid).
+		Msg("exercise library imported")
+
+	return result, nil
+}
+
+// resolveMedia looks up the media an imported exercise references, keeping
+// those that belong to a clinic of the tenant and applying the file's
+// captions. References that cannot be copied are reported as warnings.
+func (s *exerciseLibraryService) resolveMedia(ctx context.Context, tenantClinics map[string]bool, refs []model.ExerciseLibraryMedia) ([]model.ExerciseMedia, []string) {
+	var sources []model.ExerciseMedia
+	var warnings []string
+	for _, ref := range refs {
+		id := strings.TrimSpace(ref.ID)
+		if id == "" {
+			parsed, ok := model.ParseExerciseMediaURL(ref.URL)
+			if !ok {
+				warnings = append(warnings, fmt.Sprintf("media %q is not an exercise media URL; it will not be copied", ref.URL))
+				continue
+			}
+			id = parsed
+		}
+
+		src, err := s.mediaRepo.FindByID(ctx, id)
+		if err != nil || !tenantClinics[src.ClinicID] {
+			if err != nil && !errors.Is(err, repository.ErrNotFound) {
+				log.Warn().Err(err).Str("media_id", id).Msg("failed to look up imported exercise media")
+			}
+			warnings = append(warnings, fmt.Sprintf("media %s was not found in the tenant's clinics; it will not be copied", id))
+			continue
+		}
+
+		if ref.Caption != "" {
+			src.Caption = ref.Caption
+		}
+		if ref.CaptionVi != "" {
+			src.CaptionVi = ref.CaptionVi
+		}
+		sources = append(sources, *src)
+	}
+	return sources, warnings
+}
+
+// Publish copies one of the clinic's exercises, with its media, to every
+// other active clinic of the same tenant. Clinics that already have an
+// exercise with the same English or Vietnamese name are skipped.
+func (s *exerciseLibraryService) Publish(ctx context.Context, clinicID, userID, exerciseID string) (*model.ExercisePublishResult, error) {
+	exercise, err := s.exerciseRepo.GetByID(ctx, exerciseID)
+	if err != nil {
+		return nil, err
+	}
+	if !exercise.IsActive {
+		return nil, repository.ErrNotFound
+	}
+	if exercise.ClinicID == nil || *exercise.ClinicID != clinicID {
+		if exercise.IsGlobal || exercise.ClinicID == nil {
+			return nil, ErrReadOnlyExercise
+		}
+		return nil, repository.ErrNotFound
+	}
+
+	media, err := s.mediaRepo.ListByExercise(ctx, exerciseID)
+	if err != nil {
+		return nil, err
+	}
+	clinics, err := s.clinicRepo.ListTenantClinics(ctx, clinicID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.ExercisePublishResult{
+		ExerciseID: exerciseID,
+		Clinics:    []model.ExercisePublishTarget{},
+	}
+	for _, clinic := range clinics {
+		if clinic.ID == clinicID {
+			continue
+		}
+		target := model.ExercisePublishTarget{
+			ClinicID:   clinic.ID,
+			ClinicName: clinic.Name,
+		}
+
+		matches, err := s.exerciseRepo.FindByNames(ctx, clinic.ID, []string{exercise.Name, exercise.NameVi})
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range matches {
+			if m.ClinicID != nil && *m.ClinicID == clinic.ID {
+				target.DuplicateOf = m.ID
+				break
+			}
+		}
+		if target.DuplicateOf != "" {
+			target.Status = model.ExercisePublishDuplicate
+			result.Duplicates++
+			result.Clinics = append(result.Clinics, target)
+			continue
+		}
+
+		copied, err := s.exercises.CreateExercise(ctx, clinic.ID, userID, exerciseRequest(*exercise))
+		if err != nil {
+			log.Error().Err(err).Str("exercise_id", exerciseID).Str("target_clinic_id", clinic.ID).Msg("failed to publish exercise")
+			target.Status = model.ExercisePublishFailed
+			target.Error = "Failed to create exercise"
+			result.Failed++
+			result.Clinics = append(result.Clinics, target)
+			continue
+		}
+		target.Status = model.ExercisePublishCreated
+		target.ExerciseID = copied.ID
+		result.Created++
+
+		for _, m := range media {
+			if _, err := s.media.Copy(ctx, m, clinic.ID, copied.ID, userID); err != nil {
+				log.Warn().Err(err).Str("media_id", m.ID).Str("exercise_id", copied.ID).Msg("failed to copy published exercise media")
+				continue
+			}
+			target.MediaCopied++
+		}
+		result.Clinics = append(result.Clinics, target)
+	}
+
+	log.Info().
+		Str("exercise_id", exerciseID).
+		Str("clinic_id", clinicID).
+		Str("user_id", userID).
+		Int("created", result.Created).
+		Int("duplicates", result.Duplicates).
+		Int("failed", result.Failed).
+		Msg("exercise published to tenant library")
+
+	return result, nil
+}
+
+// tenantClinicIDs returns the IDs of the active clinics of the clinic's tenant.
+func (s *exerciseLibraryService) tenantClinicIDs(ctx context.Context, clinicID string) (map[string]bool, error) {
+	clinics, err := s.clinicRepo.ListTenantClinics(ctx, clinicID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string]bool, len(clinics))
+	for _, c := range clinics {
+		ids[c.ID] = true
+	}
+	return ids, nil
+}
+
+// exerciseNameKey normalizes an exercise name for duplicate detection:
+// case, surrounding space and runs of spaces are ignored.
+func exerciseNameKey(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// trimExerciseRequest trims the text fields of an imported exercise.
+func trimExerciseRequest(req *model.CreateExerciseRequest) {
+	for _, f := range []*string{
+		&req.Name, &req.NameVi, &req.Description, &req.DescriptionVi,
+		&req.Instructions, &req.InstructionsVi, &req.Category, &req.Difficulty,
+		&req.ImageURL, &req.VideoURL, &req.Precautions, &req.PrecautionsVi,
+	} {
+		*f = strings.TrimSpace(*f)
+	}
+	req.Category = strings.ToLower(req.Category)
+	req.Difficulty = strings.ToLower(req.Difficulty)
+}
+
+// decodeExerciseJSON reads a library exported as JSON, or a bare array of
+// exercises.
+func decodeExerciseJSON(r io.Reader) ([]model.ExerciseLibraryItem, error) {
+	br := bufio.NewReader(r)
+	if b, err := br.Peek(3); err == nil && bytes.Equal(b, []byte("\ufeff")) {
+		br.Discard(3)
+	}
+	data, err := io.ReadAll(br)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read exercise library: %w", err)
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var items []model.ExerciseLibraryItem
+		if err := json.Unmarshal(trimmed, &items); err != nil {
+			return nil, fmt.Errorf("%w: invalid JSON: %v", repository.ErrInvalidInput, err)
+		}
+		return items, nil
+	}
+
+	var library model.ExerciseLibrary
+	if err := json.Unmarshal(trimmed, &library); err != nil {
+		return nil, fmt.Errorf("%w: invalid JSON: %v", repository.ErrInvalidInput, err)
+	}
+	if library.Version > model.ExerciseLibraryVersion {
+		return nil, fmt.Errorf("%w: unsupported exercise library version %d", repository.ErrInvalidInput, library.Version)
+	}
+	return library.Exercises, nil
+}
+
+// encodeExerciseCSV writes library items as CSV. A UTF-8 byte order mark is
+// prepended so spreadsheet tools display Vietnamese text correctly.
+func encodeExerciseCSV(items []model.ExerciseLibraryItem) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("\ufeff")
+	cw := csv.NewWriter(&buf)
+	if err := cw.Write(exerciseCSVHeader); err != nil {
+		return nil, fmt.Errorf("failed to encode exercise library: %w", err)
+	}
+
+	for _, item := range items {
+		media := make([]string, len(item.Media))
+		for i, m := range item.Media {
+			media[i] = m.URL
+		}
+		err := cw.Write([]string{
+			item.SourceID,
+			item.Name,
+			item.NameVi,
+			item.Description,
+			item.DescriptionVi,
+			item.Instructions,
+			item.InstructionsVi,
+			item.Category,
+			item.Difficulty,
+			strings.Join(item.Equipment, exerciseCSVListSeparator),
+			strings.Join(item.MuscleGroups, exerciseCSVListSeparator),
+			item.ImageURL,
+			item.VideoURL,
+			strconv.Itoa(item.DefaultSets),
+			strconv.Itoa(item.DefaultReps),
+			strconv.Itoa(item.DefaultHoldSecs),
+			item.Precautions,
+			item.PrecautionsVi,
+			strings.Join(media, exerciseCSVListSeparator),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode exercise library: %w", err)
+		}
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return nil, fmt.Errorf("failed to encode exercise library: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeExerciseCSV reads library items from CSV with a header row. Columns
+// are matched by name and may appear in any order; only name is required.
+// Cells that cannot be parsed are reported per row, keyed by column.
+func decodeExerciseCSV(r io.Reader) ([]model.ExerciseLibraryItem, []map[string]string, error) {
+	br := bufio.NewReader(r)
+	if b, err := br.Peek(3); err == nil && bytes.Equal(b, []byte("\ufeff")) {
+		br.Discard(3)
+	}
+
+	cr := csv.NewReader(br)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: invalid CSV: %v", repository.ErrInvalidInput, err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["name"]; !ok {
+		return nil, nil, fmt.Errorf("%w: the CSV header must include a name column", repository.ErrInvalidInput)
+	}
+
+	var items []model.ExerciseLibraryItem
+	var rowErrors []map[string]string
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: invalid CSV: %v", repository.ErrInvalidInput, err)
+		}
+
+		cell := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		errs := map[string]string{}
+		number := func(column string) int {
+			v := cell(column)
+			if v == "" {
+				return 0
+			}
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				errs[column] = "Must be a whole number"
+			}
+			return n
+		}
+
+		item := model.ExerciseLibraryItem{
+			SourceID: cell("source_id"),
+			CreateExerciseRequest: model.CreateExerciseRequest{
+				Name:            cell("name"),
+				NameVi:          cell("name_vi"),
+				Description:     cell("description"),
+				DescriptionVi:   cell("description_vi"),
+				Instructions:    cell("instructions"),
+				InstructionsVi:  cell("instructions_vi"),
+				Category:        cell("category"),
+				Difficulty:      cell("difficulty"),
+				Equipment:       splitCSVList(cell("equipment")),
+				MuscleGroups:    splitCSVList(cell("muscle_groups")),
+				ImageURL:        cell("image_url"),
+				VideoURL:        cell("video_url"),
+				DefaultSets:     number("default_sets"),
+				DefaultReps:     number("default_reps"),
+				DefaultHoldSecs: number("default_hold_secs"),
+				Precautions:     cell("precautions"),
+				PrecautionsVi:   cell("precautions_vi"),
+			},
+		}
+		for _, url := range splitCSVList(cell("media")) {
+			item.Media = append(item.Media, model.ExerciseLibraryMedia{URL: url})
+		}
+
+		items = append(items, item)
+		rowErrors = append(rowErrors, errs)
+	}
+
+	return items, rowErrors, nil
+}
+
+// splitCSVList splits a CSV list cell, dropping empty values. It returns nil
+// for an empty cell.
+func splitCSVList(cell string) []string {
+	var values []string
+	for _, v := range strings.Split(cell, exerciseCSVListSeparator) {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
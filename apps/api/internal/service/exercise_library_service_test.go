@@ -0,0 +1,146 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/repository"
+)
+
+func TestExerciseCSVRoundTrip(t *testing.T) {
+	items := []model.ExerciseLibraryItem{
+		{
+			SourceID: "5d6b5c5e-0d9f-4d57-9c1b-7c2f2e8c0a11",
+			CreateExerciseRequest: model.CreateExerciseRequest{
+				Name:            "Bridge, double leg",
+				NameVi:          "Cầu mông hai chân",
+				Instructions:    "Lie on your back.\nLift your hips.",
+				Category:        "strengthening",
+				Difficulty:      "beginner",
+				Equipment:       []string{"mat"},
+				MuscleGroups:    []string{"glutes", "lower_back"},
+				DefaultSets:     3,
+				DefaultReps:     12,
+				DefaultHoldSecs: 5,
+			},
+			Media: []model.ExerciseLibraryMedia{
+				{URL: "/api/v1/exercises/5d6b5c5e-0d9f-4d57-9c1b-7c2f2e8c0a11/media/0b3c1f0e-7d1a-4bb4-8d55-2f8f0c8c9a01"},
+			},
+		},
+		{
+			CreateExerciseRequest: model.CreateExerciseRequest{
+				Name:         "Chin tuck",
+				NameVi:       "Thu cằm",
+				Category:     "postural",
+				Difficulty:   "beginner",
+				MuscleGroups: []string{"neck"},
+			},
+		},
+	}
+
+	data, err := encodeExerciseCSV(items)
+	if err != nil {
+		t.Fatalf("encodeExerciseCSV() error = %v", err)
+	}
+	if !bytes.HasPrefix(data, []byte("\ufeff")) {
+		t.Error("encodeExerciseCSV() does not start with a byte order mark")
+	}
+
+	got, rowErrors, err := decodeExerciseCSV(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("decodeExerciseCSV() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, items) {
+		t.Errorf("decodeExerciseCSV() = %+v; want %+v", got, items)
+	}
+	for i, errs := range rowErrors {
+		if len(errs) > 0 {
+			t.Errorf("decodeExerciseCSV() row %d errors = %v", i+1, errs)
+		}
+	}
+}
+
+func TestDecodeExerciseCSV(t *testing.T) {
+	t.Run("columns in any order", func(t *testing.T) {
+		csv := "muscle_groups,NAME,default_sets\nhip|glutes,Clamshell,abc\n"
+		items, rowErrors, err := decodeExerciseCSV(strings.NewReader(csv))
+		if err != nil {
+			t.Fatalf("decodeExerciseCSV() error = %v", err)
+		}
+		if len(items) != 1 || items[0].Name != "Clamshell" || !reflect.DeepEqual(items[0].MuscleGroups, []string{"hip", "glutes"}) {
+			t.Errorf("decodeExerciseCSV() = %+v", items)
+		}
+		if rowErrors[0]["default_sets"] == "" {
+			t.Errorf("decodeExerciseCSV() errors = %v; want default_sets error", rowErrors[0])
+		}
+	})
+
+	t.Run("missing name column", func(t *testing.T) {
+		_, _, err := decodeExerciseCSV(strings.NewReader("name_vi,category\nThu cằm,postural\n"))
+		if !errors.Is(err, repository.ErrInvalidInput) {
+			t.Errorf("decodeExerciseCSV() error = %v; want ErrInvalidInput", err)
+		}
+	})
+}
+
+func TestDecodeExerciseJSON(t *testing.T) {
+	tests := []struct {
+		name      string
+		json      string
+		wantNames []string
+		wantErr   bool
+	}{
+		{
+			name:      "export file",
+			json:      `{"version": 1, "exercises": [{"name": "Chin tuck", "media": [{"id": "m1"}]}]}`,
+			wantNames: []string{"Chin tuck"},
+		},
+		{
+			name:      "bare array",
+			json:      "\ufeff [{\"name\": \"Clamshell\"}, {\"name\": \"Bird dog\"}]",
+			wantNames: []string{"Clamshell", "Bird dog"},
+		},
+		{
+			name:    "newer version",
+			json:    `{"version": 2, "exercises": []}`,
+			wantErr: true,
+		},
+		{
+			name:    "malformed",
+			json:    `{"exercises": [`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, err := decodeExerciseJSON(strings.NewReader(tt.json))
+			if tt.wantErr {
+				if !errors.Is(err, repository.ErrInvalidInput) {
+					t.Errorf("decodeExerciseJSON() error = %v; want ErrInvalidInput", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decodeExerciseJSON() error = %v", err)
+			}
+			var names []string
+			for _, item := range items {
+				names = append(names, item.Name)
+			}
+			if !reflect.DeepEqual(names, tt.wantNames) {
+				t.Errorf("decodeExerciseJSON() names = %v; want %v", names, tt.wantNames)
+			}
+		})
+	}
+}
+
+func TestExerciseNameKey(t *testing.T) {
+	if got, want := exerciseNameKey("  Cầu  MÔNG hai chân "), "cầu mông hai chân"; got != want {
+		t.Errorf("exerciseNameKey() = %q; want %q", got, want)
+	}
+}
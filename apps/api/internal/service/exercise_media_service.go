@@ -0,0 +1,449 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/media"
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/repository"
+	"github.com/tqvdang/physioflow/apps/api/internal/storage"
+)
+
+const (
+	// MaxExerciseImageSize is the largest photo accepted for an exercise.
+	MaxExerciseImageSize = 10 << 20
+	// MaxExerciseVideoSize is the largest clip accepted for an exercise.
+	MaxExerciseVideoSize = 100 << 20
+	// MaxExerciseVideoSeconds is the longest clip accepted for an exercise.
+	MaxExerciseVideoSeconds = 300
+
+	// exerciseThumbnailWidth is the width video thumbnails are scaled to.
+	exerciseThumbnailWidth = 480
+)
+
+// ErrReadOnlyExercise is returned when changing the media of an exercise
+// from the global library, or of another clinic.
+var ErrReadOnlyExercise = errors.New("exercise is not one of the clinic's own exercises")
+
+// exerciseMediaTypes maps the content types accepted for exercise media to
+// their kind.
+var exerciseMediaTypes = map[string]model.ExerciseMediaKind{
+	"image/jpeg":      model.ExerciseMediaImage,
+	"image/png":       model.ExerciseMediaImage,
+	"image/gif":       model.ExerciseMediaImage,
+	"image/webp":      model.ExerciseMediaImage,
+	"video/mp4":       model.ExerciseMediaVideo,
+	"video/quicktime": model.ExerciseMediaVideo,
+	"video/webm":      model.ExerciseMediaVideo,
+}
+
+// ExerciseMediaService defines the interface for exercise photos and clips.
+type ExerciseMediaService interface {
+	Upload(ctx context.Context, clinicID, exerciseID, userID string, req *model.UploadExerciseMediaRequest, fileName, contentType string, content io.Reader) (*model.ExerciseMedia, error)
+	List(ctx context.Context, clinicID, exerciseID string) ([]model.ExerciseMedia, error)
+	Open(ctx context.Context, clinicID, exerciseID, id string, thumbnail bool) (io.ReadCloser, *model.ExerciseMedia, error)
+	Update(ctx context.Context, clinicID, exerciseID, id string, req *model.UpdateExerciseMediaRequest) (*model.ExerciseMedia, error)
+	Reorder(ctx context.Context, clinicID, exerciseID string, req *model.ReorderExerciseMediaRequest) ([]model.ExerciseMedia, error)
+	Delete(ctx context.Context, clinicID, exerciseID, id string) error
+
+	// Copy copies media and its files to another exercise, after that
+	// exercise's other media. Callers check that the clinic may read src.
+	Copy(ctx context.Context, src model.ExerciseMedia, clinicID, exerciseID, userID string) (*model.ExerciseMedia, error)
+}
+
+// exerciseMediaService implements ExerciseMediaService.
+type exerciseMediaService struct {
+	repo         repository.ExerciseMediaRepository
+	exerciseRepo repository.ExerciseRepository
+	blobs        storage.BlobStore
+	prober       *media.Prober
+}
+
+// NewExerciseMediaService creates a new exercise media service.
+func NewExerciseMediaService(repo repository.ExerciseMediaRepository, exerciseRepo repository.ExerciseRepository, blobs storage.BlobStore, prober *media.Prober) ExerciseMediaService {
+	return &exerciseMediaService{
+		repo:         repo,
+		exerciseRepo: exerciseRepo,
+		blobs:        blobs,
+		prober:       prober,
+	}
+}
+
+// Upload stores a photo or clip for one of the clinic's exercises, after its
+// other media. Image dimensions and video duration are read from the file,
+// and a video thumbnail is made when ffmpeg is installed.
+func (s *exerciseMediaService) Upload(ctx context.Context, clinicID, exerciseID, userID string, req *model.UploadExerciseMediaRequest, fileName, contentType string, content io.Reader) (*model.ExerciseMedia, error) {
+	if _, err := s.ownExercise(ctx, clinicID, exerciseID); err != nil {
+		return nil, err
+	}
+
+	fileName = path.Base(strings.ReplaceAll(strings.TrimSpace(fileName), "\\", "/"))
+	if fileName == "" || fileName == "." || fileName == "/" {
+		return nil, fmt.Errorf("%w: file name is required", repository.ErrInvalidInput)
+	}
+
+	// Spool to a temp file so the file can be inspected before it is stored
+	tmp, err := os.CreateTemp("", "exercise-media-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	size, err := io.Copy(tmp, io.LimitReader(content, MaxExerciseVideoSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upload: %w", err)
+	}
+
+	contentType, err = sniffContentType(tmp, contentType)
+	if err != nil {
+		return nil, err
+	}
+	kind, ok := exerciseMediaTypes[contentType]
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported media type %s; upload a JPEG, PNG, GIF or WebP photo or an MP4, MOV or WebM clip", repository.ErrInvalidInput, contentType)
+	}
+	limit := int64(MaxExerciseVideoSize)
+	if kind == model.ExerciseMediaImage {
+		limit = MaxExerciseImageSize
+	}
+	if size > limit {
+		return nil, fmt.Errorf("%w: %s exceeds %d MB", repository.ErrInvalidInput, kind, limit>>20)
+	}
+
+	m := &model.ExerciseMedia{
+		ID:          uuid.New().String(),
+		ExerciseID:  exerciseID,
+		ClinicID:    clinicID,
+		Kind:        kind,
+		Caption:     strings.TrimSpace(req.Caption),
+		CaptionVi:   strings.TrimSpace(req.CaptionVi),
+		FileName:    fileName,
+		ContentType: contentType,
+		CreatedBy:   &userID,
+	}
+	m.BlobKey = fmt.Sprintf("exercise-media/%s/%s/%s", clinicID, exerciseID, m.ID)
+
+	var thumbnail []byte
+	switch kind {
+	case model.ExerciseMediaImage:
+		s.readImageInfo(tmp, m)
+	case model.ExerciseMediaVideo:
+		if err := s.readVideoInfo(ctx, tmp.Name(), m); err != nil {
+			return nil, err
+		}
+		thumbnail = s.makeThumbnail(ctx, tmp.Name(), m)
+	}
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to read upload: %w", err)
+	}
+	info, err := s.blobs.Put(ctx, m.BlobKey, tmp, contentType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store exercise media: %w", err)
+	}
+	m.SizeBytes = info.Size
+	m.SHA256 = info.SHA256
+
+	if thumbnail != nil {
+		thumbnailKey := m.BlobKey + "-thumbnail"
+		if _, err := s.blobs.Put(ctx, thumbnailKey, bytes.NewReader(thumbnail), "image/jpeg"); err != nil {
+			log.Warn().Err(err).Str("media_id", m.ID).Msg("failed to store exercise video thumbnail")
+		} else {
+			m.ThumbnailKey = thumbnailKey
+		}
+	}
+
+	if err := s.repo.Create(ctx, m); err != nil {
+		s.removeBlobs(ctx, *m)
+		return nil, err
+	}
+
+	log.Info().
+		Str("media_id", m.ID).
+		Str("exercise_id", exerciseID).
+		Str("kind", string(kind)).
+		Int64("size_bytes", m.SizeBytes).
+		Msg("exercise media uploaded")
+
+	return m, nil
+}
+
+// sniffContentType detects a file's content type from its first bytes,
+// falling back to the declared type for formats the standard library does
+// not recognize, such as QuickTime.
+func sniffContentType(f *os.File, declared string) (string, error) {
+	head := make([]byte, 512)
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", fmt.Errorf("failed to read upload: %w", err)
+	}
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", fmt.Errorf("failed to read upload: %w", err)
+	}
+	if n == 0 {
+		return "", fmt.Errorf("%w: file is empty", repository.ErrInvalidInput)
+	}
+
+	detected := http.DetectContentType(head[:n])
+	if detected == "application/octet-stream" && declared != "" {
+		detected = declared
+	}
+	if i := strings.IndexByte(detected, ';'); i >= 0 {
+		detected = detected[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(detected)), nil
+}
+
+// readImageInfo records an image's dimensions where the standard decoders
+// can read them.
+func (s *exerciseMediaService) readImageInfo(f *os.File, m *model.ExerciseMedia) {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return
+	}
+	info, err := media.ImageInfo(f)
+	if err != nil {
+		log.Debug().Err(err).Str("content_type", m.ContentType).Msg("exercise image dimensions not read")
+		return
+	}
+	m.Width, m.Height = &info.Width, &info.Height
+}
+
+// readVideoInfo records a video's duration and dimensions where they can be
+// read, and rejects clips that are too long.
+func (s *exerciseMediaService) readVideoInfo(ctx context.Context, filePath string, m *model.ExerciseMedia) error {
+	info, err := s.prober.VideoInfo(ctx, filePath)
+	if err != nil {
+		log.Debug().Err(err).Str("content_type", m.ContentType).Msg("exercise video metadata not read")
+		return nil
+	}
+
+	if info.DurationSeconds > MaxExerciseVideoSeconds {
+		return fmt.Errorf("%w: clips can be at most %d minutes long", repository.ErrInvalidInput, MaxExerciseVideoSeconds/60)
+	}
+	if info.DurationSeconds > 0 {
+		duration := math.Round(info.DurationSeconds*100) / 100
+		m.DurationSeconds = &duration
+	}
+	if info.Width > 0 && info.Height > 0 {
+		m.Width, m.Height = &info.Width, &info.Height
+	}
+	return nil
+}
+
+// makeThumbnail renders a frame a second into the clip, or halfway through
+// shorter ones. It returns nil when ffmpeg is not installed or fails.
+func (s *exerciseMediaService) makeThumbnail(ctx context.Context, filePath string, m *model.ExerciseMedia) []byte {
+	if !s.prober.CanThumbnail() {
+		return nil
+	}
+	at := 1.0
+	if m.DurationSeconds != nil && *m.DurationSeconds < 2 {
+		at = *m.DurationSeconds / 2
+	}
+	thumbnail, err := s.prober.Thumbnail(ctx, filePath, at, exerciseThumbnailWidth)
+	if err != nil {
+		log.Warn().Err(err).Str("media_id", m.ID).Msg("failed to make exercise video thumbnail")
+		return nil
+	}
+	return thumbnail
+}
+
+// List returns the media of an exercise the clinic can see, in order.
+func (s *exerciseMediaService) List(ctx context.Context, clinicID, exerciseID string) ([]model.ExerciseMedia, error) {
+	if _, err := s.visibleExercise(ctx, clinicID, exerciseID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListByExercise(ctx, exerciseID)
+}
+
+// Open returns a media file, or a video's thumbnail, of an exercise the
+// clinic can see. The caller must close the reader.
+func (s *exerciseMediaService) Open(ctx context.Context, clinicID, exerciseID, id string, thumbnail bool) (io.ReadCloser, *model.ExerciseMedia, error) {
+	if _, err := s.visibleExercise(ctx, clinicID, exerciseID); err != nil {
+		return nil, nil, err
+	}
+	m, err := s.repo.GetByID(ctx, exerciseID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	key := m.BlobKey
+	if thumbnail {
+		if !m.HasThumbnail() {
+			return nil, nil, repository.ErrNotFound
+		}
+		key = m.ThumbnailKey
+	}
+
+	content, _, err := s.blobs.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil, repository.ErrNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to open exercise media %s: %w", m.ID, err)
+	}
+	return content, m, nil
+}
+
+// Update changes a media file's captions.
+func (s *exerciseMediaService) Update(ctx context.Context, clinicID, exerciseID, id string, req *model.UpdateExerciseMediaRequest) (*model.ExerciseMedia, error) {
+	if _, err := s.ownExercise(ctx, clinicID, exerciseID); err != nil {
+		return nil, err
+	}
+	m, err := s.repo.GetByID(ctx, exerciseID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Caption != nil {
+		m.Caption = strings.TrimSpace(*req.Caption)
+	}
+	if req.CaptionVi != nil {
+		m.CaptionVi = strings.TrimSpace(*req.CaptionVi)
+	}
+	if err := s.repo.UpdateCaptions(ctx, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Reorder puts an exercise's media in the given order.
+func (s *exerciseMediaService) Reorder(ctx context.Context, clinicID, exerciseID string, req *model.ReorderExerciseMediaRequest) ([]model.ExerciseMedia, error) {
+	if _, err := s.ownExercise(ctx, clinicID, exerciseID); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(req.MediaIDs))
+	for _, id := range req.MediaIDs {
+		if seen[id] {
+			return nil, fmt.Errorf("%w: media %s is listed twice", repository.ErrInvalidInput, id)
+		}
+		seen[id] = true
+	}
+
+	if err := s.repo.Reorder(ctx, exerciseID, req.MediaIDs); err != nil {
+		return nil, err
+	}
+	return s.repo.ListByExercise(ctx, exerciseID)
+}
+
+// Delete removes a media file and its thumbnail.
+func (s *exerciseMediaService) Delete(ctx context.Context, clinicID, exerciseID, id string) error {
+	if _, err := s.ownExercise(ctx, clinicID, exerciseID); err != nil {
+		return err
+	}
+	m, err := s.repo.GetByID(ctx, exerciseID, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, exerciseID, id); err != nil {
+		return err
+	}
+	s.removeBlobs(ctx, *m)
+
+	log.Info().
+		Str("media_id", id).
+		Str("exercise_id", exerciseID).
+		Msg("exercise media deleted")
+
+	return nil
+}
+
+// Copy copies media and its files to another exercise.
+func (s *exerciseMediaService) Copy(ctx context.Context, src model.ExerciseMedia, clinicID, exerciseID, userID string) (*model.ExerciseMedia, error) {
+	m := src
+	m.ID = uuid.New().String()
+	m.ExerciseID = exerciseID
+	m.ClinicID = clinicID
+	m.CreatedBy = &userID
+	m.BlobKey = fmt.Sprintf("exercise-media/%s/%s/%s", clinicID, exerciseID, m.ID)
+	m.ThumbnailKey = ""
+
+	if err := s.copyBlob(ctx, src.BlobKey, m.BlobKey, src.ContentType); err != nil {
+		return nil, err
+	}
+	if src.HasThumbnail() {
+		thumbnailKey := m.BlobKey + "-thumbnail"
+		if err := s.copyBlob(ctx, src.ThumbnailKey, thumbnailKey, "image/jpeg"); err != nil {
+			log.Warn().Err(err).Str("media_id", src.ID).Msg("failed to copy exercise video thumbnail")
+		} else {
+			m.ThumbnailKey = thumbnailKey
+		}
+	}
+
+	if err := s.repo.Create(ctx, &m); err != nil {
+		s.removeBlobs(ctx, m)
+		return nil, err
+	}
+	return &m, nil
+}
+
+// copyBlob copies a stored file to a new key.
+func (s *exerciseMediaService) copyBlob(ctx context.Context, from, to, contentType string) error {
+	content, _, err := s.blobs.Get(ctx, from)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return repository.ErrNotFound
+		}
+		return fmt.Errorf("failed to open exercise media: %w", err)
+	}
+	defer content.Close()
+
+	if _, err := s.blobs.Put(ctx, to, content, contentType); err != nil {
+		return fmt.Errorf("failed to store exercise media: %w", err)
+	}
+	return nil
+}
+
+// visibleExercise returns an active exercise from the global library or the
+// clinic's own.
+func (s *exerciseMediaService) visibleExercise(ctx context.Context, clinicID, exerciseID string) (*model.Exercise, error) {
+	exercise, err := s.exerciseRepo.GetByID(ctx, exerciseID)
+	if err != nil {
+		return nil, err
+	}
+	if !exercise.IsActive || (!exercise.IsGlobal && exercise.ClinicID != nil && *exercise.ClinicID != clinicID) {
+		return nil, repository.ErrNotFound
+	}
+	return exercise, nil
+}
+
+// ownExercise returns an active exercise of the clinic's own, whose media
+// the clinic may change.
+func (s *exerciseMediaService) ownExercise(ctx context.Context, clinicID, exerciseID string) (*model.Exercise, error) {
+	exercise, err := s.visibleExercise(ctx, clinicID, exerciseID)
+	if err != nil {
+		return nil, err
+	}
+	if exercise.ClinicID == nil || *exercise.ClinicID != clinicID {
+		return nil, ErrReadOnlyExercise
+	}
+	return exercise, nil
+}
+
+// removeBlobs deletes a media file and its thumbnail, logging rather than
+// failing on error.
+func (s *exerciseMediaService) removeBlobs(ctx context.Context, m model.ExerciseMedia) {
+	for _, key := range []string{m.BlobKey, m.ThumbnailKey} {
+		if key == "" {
+			continue
+		}
+		if err := s.blobs.Delete(ctx, key); err != nil {
+			log.Error().Err(err).Str("blob_key", key).Msg("failed to delete exercise media blob")
+		}
+	}
+}
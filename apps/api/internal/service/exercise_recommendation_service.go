@@ -0,0 +1,810 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/repository"
+)
+
+const (
+	// defaultRecommendationLimit is how many exercises are suggested when the
+	// request does not say.
+	defaultRecommendationLimit = 10
+
+	// Points an exercise scores for each rule it meets. Recommendations are
+	// ranked by the total, so the weights decide which rules matter most.
+	scorePrimaryMuscles      = 3 // works a muscle group central to a body region
+	scoreExtraMuscles        = 1 // per further central group, up to maxExtraMuscles
+	maxExtraMuscles          = 2
+	scoreSupportingMuscles   = 1 // works only supporting or full-body groups
+	scorePrimaryCategory     = 2 // the first-choice category for a finding
+	scoreSecondaryCategory   = 1 // another category that addresses a finding
+	scorePreferredDifficulty = 1 // matches the difficulty suited to age and pain
+	maxHistoryScore          = 3 // one point per two prescriptions for the diagnosis
+)
+
+// regionMuscles lists the muscle groups exercises for a body region work:
+// primary groups are central to it and supporting groups stabilize it.
+type regionMuscles struct {
+	primary    []model.MuscleGroup
+	supporting []model.MuscleGroup
+}
+
+// regionMuscleGroups maps body regions to the muscle groups that exercises for
+// them work.
+var regionMuscleGroups = map[string]regionMuscles{
+	model.BodyRegionCervicalSpine: {
+		primary:    []model.MuscleGroup{model.MuscleGroupNeck},
+		supporting: []model.MuscleGroup{model.MuscleGroupUpperBack, model.MuscleGroupShoulder},
+	},
+	model.BodyRegionThoracicSpine: {
+		primary:    []model.MuscleGroup{model.MuscleGroupUpperBack},
+		supporting: []model.MuscleGroup{model.MuscleGroupChest, model.MuscleGroupCore},
+	},
+	model.BodyRegionLumbarSpine: {
+		primary:    []model.MuscleGroup{model.MuscleGroupLowerBack, model.MuscleGroupCore},
+		supporting: []model.MuscleGroup{model.MuscleGroupGlutes, model.MuscleGroupHip, model.MuscleGroupHamstrings},
+	},
+	model.BodyRegionShoulder: {
+		primary:    []model.MuscleGroup{model.MuscleGroupShoulder},
+		supporting: []model.MuscleGroup{model.MuscleGroupUpperBack, model.MuscleGroupChest},
+	},
+	model.BodyRegionElbow: {
+		primary:    []model.MuscleGroup{model.MuscleGroupElbow},
+		supporting: []model.MuscleGroup{model.MuscleGroupWristForearm, model.MuscleGroupShoulder},
+	},
+	model.BodyRegionWristHand: {
+		primary:    []model.MuscleGroup{model.MuscleGroupWristForearm},
+		supporting: []model.MuscleGroup{model.MuscleGroupElbow},
+	},
+	model.BodyRegionHip: {
+		primary:    []model.MuscleGroup{model.MuscleGroupHip, model.MuscleGroupGlutes},
+		supporting: []model.MuscleGroup{model.MuscleGroupCore, model.MuscleGroupQuadriceps, model.MuscleGroupHamstrings},
+	},
+	model.BodyRegionKnee: {
+		primary:    []model.MuscleGroup{model.MuscleGroupQuadriceps, model.MuscleGroupHamstrings},
+		supporting: []model.MuscleGroup{model.MuscleGroupCalves, model.MuscleGroupHip, model.MuscleGroupGlutes},
+	},
+	model.BodyRegionAnkleFoot: {
+		primary:    []model.MuscleGroup{model.MuscleGroupAnkle, model.MuscleGroupCalves},
+		supporting: []model.MuscleGroup{model.MuscleGroupQuadriceps},
+	},
+}
+
+// bodyRegionAliases matches free-text body regions, in English or
+// Vietnamese, to the regions in regionMuscleGroups. Longer aliases come
+// first so that "cổ tay" (wrist) is not read as "cổ" (neck).
+var bodyRegionAliases = []struct {
+	alias  string
+	region string
+}{
+	{"cổ tay", model.BodyRegionWristHand},
+	{"bàn tay", model.BodyRegionWristHand},
+	{"cổ chân", model.BodyRegionAnkleFoot},
+	{"bàn chân", model.BodyRegionAnkleFoot},
+	{"thắt lưng", model.BodyRegionLumbarSpine},
+	{"lưng dưới", model.BodyRegionLumbarSpine},
+	{"lưng trên", model.BodyRegionThoracicSpine},
+	{"cột sống ngực", model.BodyRegionThoracicSpine},
+	{"khuỷu tay", model.BodyRegionElbow},
+	{"đầu gối", model.BodyRegionKnee},
+	{"gối", model.BodyRegionKnee},
+	{"hông", model.BodyRegionHip},
+	{"háng", model.BodyRegionHip},
+	{"vai", model.BodyRegionShoulder},
+	{"cổ", model.BodyRegionCervicalSpine},
+	{"low back", model.BodyRegionLumbarSpine},
+	{"lower back", model.BodyRegionLumbarSpine},
+	{"lumbar", model.BodyRegionLumbarSpine},
+	{"lumbosacral", model.BodyRegionLumbarSpine},
+	{"upper back", model.BodyRegionThoracicSpine},
+	{"mid back", model.BodyRegionThoracicSpine},
+	{"thoracic", model.BodyRegionThoracicSpine},
+	{"cervical", model.BodyRegionCervicalSpine},
+	{"neck", model.BodyRegionCervicalSpine},
+	{"shoulder", model.BodyRegionShoulder},
+	{"elbow", model.BodyRegionElbow},
+	{"wrist", model.BodyRegionWristHand},
+	{"hand", model.BodyRegionWristHand},
+	{"hip", model.BodyRegionHip},
+	{"knee", model.BodyRegionKnee},
+	{"ankle", model.BodyRegionAnkleFoot},
+	{"foot", model.BodyRegionAnkleFoot},
+	{"feet", model.BodyRegionAnkleFoot},
+}
+
+// findingCategories lists the exercise categories that address each kind of
+// finding, first choice first.
+var findingCategories = map[model.ExerciseFindingType][]model.ExerciseCategory{
+	model.FindingROMDeficit: {model.ExerciseCategoryMobility, model.ExerciseCategoryStretching},
+	model.FindingStiffness:  {model.ExerciseCategoryStretching, model.ExerciseCategoryMobility},
+	model.FindingWeakness:   {model.ExerciseCategoryStrengthening},
+	model.FindingBalance:    {model.ExerciseCategoryBalance},
+	model.FindingPosture:    {model.ExerciseCategoryPostural, model.ExerciseCategoryStrengthening},
+	model.FindingEndurance:  {model.ExerciseCategoryCardiovascular, model.ExerciseCategoryStrengthening},
+	model.FindingPain:       {model.ExerciseCategoryMobility},
+}
+
+// findingLabels describes each kind of finding in recommendation reasons.
+var findingLabels = map[model.ExerciseFindingType]string{
+	model.FindingROMDeficit: "ROM deficit",
+	model.FindingStiffness:  "stiffness",
+	model.FindingWeakness:   "weakness",
+	model.FindingBalance:    "balance deficit",
+	model.FindingPosture:    "postural dysfunction",
+	model.FindingEndurance:  "reduced endurance",
+	model.FindingPain:       "pain",
+}
+
+// findingKeywords classifies checklist items by their label, in English or
+// Vietnamese.
+var findingKeywords = []struct {
+	keyword string
+	finding model.ExerciseFindingType
+}{
+	{"romberg", model.FindingBalance},
+	{"range of motion", model.FindingROMDeficit},
+	{"rom", model.FindingROMDeficit},
+	{"tầm vận động", model.FindingROMDeficit},
+	{"stiff", model.FindingStiffness},
+	{"cứng", model.FindingStiffness},
+	{"weak", model.FindingWeakness},
+	{"strength", model.FindingWeakness},
+	{"sức cơ", model.FindingWeakness},
+	{"yếu", model.FindingWeakness},
+	{"balance", model.FindingBalance},
+	{"thăng bằng", model.FindingBalance},
+	{"posture", model.FindingPosture},
+	{"postural", model.FindingPosture},
+	{"tư thế", model.FindingPosture},
+	{"endurance", model.FindingEndurance},
+	{"sức bền", model.FindingEndurance},
+	{"pain", model.FindingPain},
+	{"đau", model.FindingPain},
+}
+
+// movementKeywords are the movements recognized in checklist labels and
+// options.
+var movementKeywords = []string{
+	"lateral flexion", "side bending", "internal rotation", "external rotation",
+	"dorsiflexion", "plantarflexion", "flexion", "extension", "abduction",
+	"adduction", "rotation", "inversion", "eversion",
+}
+
+// normalChecklistValues are checklist answers that record no finding.
+var normalChecklistValues = map[string]bool{
+	"none": true, "normal": true, "wnl": true, "within normal limits": true,
+	"no": true, "n/a": true, "không": true, "bình thường": true,
+}
+
+// ExerciseRecommendationService defines the interface for exercise
+// recommendations.
+type ExerciseRecommendationService interface {
+	Recommend(ctx context.Context, clinicID string, req *model.ExerciseRecommendationRequest) (*model.ExerciseRecommendationResult, error)
+}
+
+// exerciseRecommendationService implements ExerciseRecommendationService.
+type exerciseRecommendationService struct {
+	exerciseRepo  repository.ExerciseRepository
+	patientRepo   repository.PatientRepository
+	quickActions  repository.QuickActionsRepository
+	templateRepo  repository.ChecklistTemplateRepository
+	checklistRepo repository.VisitChecklistRepository
+}
+
+// NewExerciseRecommendationService creates a new exercise recommendation
+// service. The checklist repositories may be nil when checklists are not
+// available.
+func NewExerciseRecommendationService(exerciseRepo repository.ExerciseRepository, patientRepo repository.PatientRepository, quickActions repository.QuickActionsRepository, templateRepo repository.ChecklistTemplateRepository, checklistRepo repository.VisitChecklistRepository) ExerciseRecommendationService {
+	return &exerciseRecommendationService{
+		exerciseRepo:  exerciseRepo,
+		patientRepo:   patientRepo,
+		quickActions:  quickActions,
+		templateRepo:  templateRepo,
+		checklistRepo: checklistRepo,
+	}
+}
+
+// Recommend suggests exercises from the clinic's and the global library for
+// a diagnosis, body regions and findings, given directly or read from a
+// visit checklist. Exercises are ranked by deterministic rules: the muscle
+// groups they work against the body regions, their category against the
+// findings, their difficulty against the patient's age and pain level, and
+// how often the clinic has prescribed them for the same diagnosis code. Each
+// suggestion explains which rules it met.
+func (s *exerciseRecommendationService) Recommend(ctx context.Context, clinicID string, req *model.ExerciseRecommendationRequest) (*model.ExerciseRecommendationResult, error) {
+	profile := model.ExerciseRecommendationProfile{
+		DiagnosisCode: strings.ToUpper(strings.TrimSpace(req.DiagnosisCode)),
+		BodyRegions:   []string{},
+		Findings:      []model.ExerciseFinding{},
+		Age:           req.Age,
+		PainLevel:     req.PainLevel,
+	}
+
+	for _, r := range req.BodyRegions {
+		region, ok := normalizeBodyRegion(r)
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown body region %q", repository.ErrInvalidInput, r)
+		}
+		profile.BodyRegions = appendUnique(profile.BodyRegions, region)
+	}
+	for _, f := range req.Findings {
+		if f.BodyRegion != "" {
+			region, ok := normalizeBodyRegion(f.BodyRegion)
+			if !ok {
+				return nil, fmt.Errorf("%w: unknown body region %q", repository.ErrInvalidInput, f.BodyRegion)
+			}
+			f.BodyRegion = region
+		}
+		f.Movement = strings.ToLower(strings.TrimSpace(f.Movement))
+		profile.Findings = appendFinding(profile.Findings, f)
+	}
+
+	patientID := req.PatientID
+	if req.VisitChecklistID != "" {
+		checklistPatientID, err := s.applyChecklist(ctx, clinicID, req.VisitChecklistID, &profile)
+		if err != nil {
+			return nil, err
+		}
+		if patientID == "" {
+			patientID = checklistPatientID
+		} else if patientID != checklistPatientID {
+			return nil, fmt.Errorf("%w: the visit checklist belongs to another patient", repository.ErrInvalidInput)
+		}
+	}
+
+	for _, f := range profile.Findings {
+		if f.BodyRegion != "" {
+			profile.BodyRegions = appendUnique(profile.BodyRegions, f.BodyRegion)
+		}
+	}
+	if profile.DiagnosisCode == "" && len(profile.BodyRegions) == 0 && len(profile.Findings) == 0 {
+		return nil, fmt.Errorf("%w: provide a diagnosis code, body regions, findings or a visit checklist with findings", repository.ErrInvalidInput)
+	}
+
+	if patientID != "" {
+		if err := s.applyPatient(ctx, clinicID, patientID, &profile); err != nil {
+			return nil, err
+		}
+	}
+	profile.MaxDifficulty, profile.PreferredDifficulty = difficultyLimits(profile.Age, profile.PainLevel)
+
+	history := map[string]int{}
+	if profile.DiagnosisCode != "" {
+		counts, err := s.exerciseRepo.CountPrescriptionsByDiagnosis(ctx, clinicID, profile.DiagnosisCode)
+		if err != nil {
+			return nil, err
+		}
+		history = counts
+	}
+
+	candidates, err := s.exerciseRepo.ListLibrary(ctx, clinicID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultRecommendationLimit
+	}
+
+	return &model.ExerciseRecommendationResult{
+		Profile:         profile,
+		Recommendations: rankExercises(candidates, profile, history, limit),
+	}, nil
+}
+
+// applyPatient fills in the age and pain level the request left out from the
+// patient's date of birth and last pain record.
+func (s *exerciseRecommendationService) applyPatient(ctx context.Context, clinicID, patientID string, profile *model.ExerciseRecommendationProfile) error {
+	patient, err := s.patientRepo.GetByID(ctx, clinicID, patientID)
+	if err != nil {
+		return err
+	}
+
+	if profile.Age == nil && !patient.DateOfBirth.IsZero() {
+		age := patient.Age()
+		profile.Age = &age
+	}
+	if profile.PainLevel == nil {
+		record, err := s.quickActions.GetLastPainRecord(ctx, patientID)
+		if err != nil {
+			return err
+		}
+		if record != nil {
+			level := record.Level
+			profile.PainLevel = &level
+		}
+	}
+	return nil
+}
+
+// applyChecklist adds the body regions, findings, pain level and diagnosis
+// recorded in a visit checklist to the profile, where the request did not
+// give them, and returns the checklist's patient.
+func (s *exerciseRecommendationService) applyChecklist(ctx context.Context, clinicID, checklistID string, profile *model.ExerciseRecommendationProfile) (string, error) {
+	if s.checklistRepo == nil || s.templateRepo == nil {
+		return "", repository.ErrNotFound
+	}
+
+	checklist, err := s.checklistRepo.GetByIDWithResponses(ctx, checklistID)
+	if err != nil {
+		return "", err
+	}
+	if checklist.ClinicID != clinicID {
+		return "", repository.ErrNotFound
+	}
+	template, err := s.templateRepo.GetByID(ctx, checklist.TemplateID)
+	if err != nil {
+		return "", err
+	}
+	items, err := s.templateRepo.GetItemsByTemplateID(ctx, checklist.TemplateID)
+	if err != nil {
+		return "", err
+	}
+
+	regions, findings, pain := checklistFindings(template, items, checklist.Responses)
+	for _, r := range regions {
+		profile.BodyRegions = appendUnique(profile.BodyRegions, r)
+	}
+	for _, f := range findings {
+		profile.Findings = appendFinding(profile.Findings, f)
+	}
+	if profile.PainLevel == nil && pain != nil {
+		profile.PainLevel = pain
+	}
+	if profile.DiagnosisCode == "" && len(template.ApplicableDiagnoses) == 1 {
+		profile.DiagnosisCode = strings.ToUpper(strings.TrimSpace(template.ApplicableDiagnoses[0]))
+	}
+
+	return checklist.PatientID, nil
+}
+
+// checklistFindings reads body regions, findings and the highest pain level
+// from a checklist's responses. Body diagram labels give body regions, and
+// items are classified as findings by keywords in their labels: a checked
+// checkbox, or an answer other than "none" or "normal", records the finding.
+// A finding's body region comes from its item label, or else the template.
+func checklistFindings(template *model.ChecklistTemplate, items []model.ChecklistItem, responses []model.ChecklistResponse) ([]string, []model.ExerciseFinding, *int) {
+	var regions []string
+	var findings []model.ExerciseFinding
+	var pain *int
+
+	templateRegion := ""
+	if template != nil && template.BodyRegion != nil {
+		if region, ok := normalizeBodyRegion(*template.BodyRegion); ok {
+			templateRegion = region
+			regions = appendUnique(regions, region)
+		}
+	}
+
+	itemsByID := make(map[string]model.ChecklistItem, len(items))
+	for _, item := range items {
+		itemsByID[item.ID] = item
+	}
+
+	for _, resp := range responses {
+		item, ok := itemsByID[resp.ChecklistItemID]
+		if !ok || resp.IsSkipped || len(resp.ResponseValue) == 0 {
+			continue
+		}
+		label := strings.ToLower(item.Label + " " + item.LabelVi)
+
+		if item.ItemType == model.ItemTypeBodyDiagram {
+			var diagram model.BodyDiagramResponse
+			if err := json.Unmarshal(resp.ResponseValue, &diagram); err != nil {
+				continue
+			}
+			for _, p := range diagram.Points {
+				if region, ok := normalizeBodyRegion(p.Label); ok {
+					regions = appendUnique(regions, region)
+				}
+			}
+			continue
+		}
+
+		findingType, ok := findingFromLabel(label)
+		if !ok {
+			continue
+		}
+		region, ok := normalizeBodyRegion(label)
+		if !ok {
+			region = templateRegion
+		}
+
+		var answers []string
+		switch item.ItemType {
+		case model.ItemTypeCheckbox:
+			var v model.CheckboxResponse
+			if json.Unmarshal(resp.ResponseValue, &v) == nil && v.Checked {
+				answers = []string{""}
+			}
+		case model.ItemTypeRadio:
+			var v model.RadioResponse
+			if json.Unmarshal(resp.ResponseValue, &v) == nil && !isNormalAnswer(v.Selected) {
+				answers = []string{v.Selected}
+			}
+		case model.ItemTypeMultiSelect:
+			var v model.MultiSelectResponse
+			if json.Unmarshal(resp.ResponseValue, &v) == nil {
+				for _, selected := range v.Selected {
+					if !isNormalAnswer(selected) {
+						answers = append(answers, selected)
+					}
+				}
+			}
+		case model.ItemTypeScale, model.ItemTypeNumber:
+			if findingType != model.FindingPain {
+				continue
+			}
+			var v model.NumberResponse
+			if json.Unmarshal(resp.ResponseValue, &v) == nil && v.Value >= 0 && v.Value <= 10 {
+				level := int(v.Value + 0.5)
+				if pain == nil || level > *pain {
+					pain = &level
+				}
+			}
+			continue
+		}
+
+		for _, answer := range answers {
+			movement := movementIn(strings.ToLower(answer))
+			if movement == "" {
+				movement = movementIn(label)
+			}
+			findings = appendFinding(findings, model.ExerciseFinding{
+				Type:       findingType,
+				BodyRegion: region,
+				Movement:   movement,
+			})
+		}
+	}
+
+	return regions, findings, pain
+}
+
+// rankExercises scores candidate exercises against the profile and returns
+// the best, highest score first. Exercises above the profile's maximum
+// difficulty, and those that meet no rule about body regions, findings or
+// prescribing history, are left out. Ties are broken by prescribing history
+// and then by name, so the same inputs always give the same order.
+func rankExercises(candidates []model.Exercise, profile model.ExerciseRecommendationProfile, history map[string]int, limit int) []model.ExerciseRecommendation {
+	// Muscle groups the body regions call for, each with the first region
+	// that calls for it
+	var primary, supporting []model.MuscleGroup
+	groupRegion := make(map[model.MuscleGroup]string)
+	for _, region := range profile.BodyRegions {
+		muscles := regionMuscleGroups[region]
+		for _, g := range muscles.primary {
+			if _, ok := groupRegion[g]; !ok {
+				primary = append(primary, g)
+				groupRegion[g] = region
+			}
+		}
+	}
+	for _, region := range profile.BodyRegions {
+		for _, g := range regionMuscleGroups[region].supporting {
+			if _, ok := groupRegion[g]; !ok {
+				supporting = append(supporting, g)
+				groupRegion[g] = region
+			}
+		}
+	}
+
+	maxLevel := difficultyLevel(profile.MaxDifficulty)
+	recommendations := []model.ExerciseRecommendation{}
+	for _, e := range candidates {
+		level := difficultyLevel(e.Difficulty)
+		if level > maxLevel {
+			continue
+		}
+
+		rec := model.ExerciseRecommendation{Exercise: e, PrescriptionCount: history[e.ID]}
+		var reasons []string
+		relevant := false
+
+		// Muscle groups against body regions
+		worked := make(map[model.MuscleGroup]bool, len(e.MuscleGroups))
+		for _, g := range e.MuscleGroups {
+			worked[g] = true
+		}
+		var matchedPrimary, matchedSupporting []model.MuscleGroup
+		for _, g := range primary {
+			if worked[g] {
+				matchedPrimary = append(matchedPrimary, g)
+			}
+		}
+		for _, g := range supporting {
+			if worked[g] {
+				matchedSupporting = append(matchedSupporting, g)
+			}
+		}
+		switch {
+		case len(matchedPrimary) > 0:
+			extra := len(matchedPrimary) - 1
+			if extra > maxExtraMuscles {
+				extra = maxExtraMuscles
+			}
+			rec.Score += scorePrimaryMuscles + extra*scoreExtraMuscles
+			rec.MatchedGroups = matchedPrimary
+			reasons = append(reasons, fmt.Sprintf("works the %s for the %s",
+				joinWords(humanizeGroups(matchedPrimary)), joinWords(humanize(regionsOf(matchedPrimary, groupRegion)))))
+			relevant = true
+		case len(matchedSupporting) > 0:
+			rec.Score += scoreSupportingMuscles
+			rec.MatchedGroups = matchedSupporting
+			reasons = append(reasons, fmt.Sprintf("works the %s, which support the %s",
+				joinWords(humanizeGroups(matchedSupporting)), joinWords(humanize(regionsOf(matchedSupporting, groupRegion)))))
+			relevant = true
+		case worked[model.MuscleGroupFullBody] && len(profile.BodyRegions) > 0:
+			rec.Score += scoreSupportingMuscles
+			rec.MatchedGroups = []model.MuscleGroup{model.MuscleGroupFullBody}
+			reasons = append(reasons, "full-body exercise")
+			relevant = true
+		}
+
+		// Category against findings; the best matching finding counts
+		bestCategory, bestFinding := 0, -1
+		for i, f := range profile.Findings {
+			for rank, c := range findingCategories[f.Type] {
+				if c != e.Category {
+					continue
+				}
+				points := scoreSecondaryCategory
+				if rank == 0 {
+					points = scorePrimaryCategory
+				}
+				if points > bestCategory {
+					bestCategory, bestFinding = points, i
+				}
+			}
+		}
+		if bestFinding >= 0 {
+			rec.Score += bestCategory
+			reasons = append(reasons, fmt.Sprintf("%s exercise for the %s", e.Category, describeFinding(profile.Findings[bestFinding])))
+			relevant = true
+		}
+
+		// Clinic's prescribing history for the diagnosis
+		if count := history[e.ID]; count > 0 {
+			points := count / 2
+			if points > maxHistoryScore {
+				points = maxHistoryScore
+			}
+			rec.Score += points
+			times := strconv.Itoa(count) + " times"
+			if count == 1 {
+				times = "once"
+			}
+			reasons = append(reasons, fmt.Sprintf("prescribed %s at this clinic for %s", times, profile.DiagnosisCode))
+			relevant = true
+		}
+
+		if !relevant {
+			continue
+		}
+
+		// Difficulty against age and pain
+		if profile.PreferredDifficulty != "" && e.Difficulty == profile.PreferredDifficulty {
+			rec.Score += scorePreferredDifficulty
+			reasons = append(reasons, fmt.Sprintf("%s level suits %s", e.Difficulty, describeTolerance(profile)))
+		}
+
+		rec.Reason = capitalize(strings.Join(reasons, "; "))
+		recommendations = append(recommendations, rec)
+	}
+
+	sort.SliceStable(recommendations, func(i, j int) bool {
+		a, b := recommendations[i], recommendations[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.PrescriptionCount != b.PrescriptionCount {
+			return a.PrescriptionCount > b.PrescriptionCount
+		}
+		if a.Exercise.Name != b.Exercise.Name {
+			return a.Exercise.Name < b.Exercise.Name
+		}
+		return a.Exercise.ID < b.Exercise.ID
+	})
+
+	if len(recommendations) > limit {
+		recommendations = recommendations[:limit]
+	}
+	return recommendations
+}
+
+// difficultyLimits returns the hardest difficulty suited to the patient's age
+// and pain level, and the difficulty to prefer, if any. Severe pain (7 or
+// more) or an age of 75 or over limits exercises to beginner level; moderate
+// pain (4 to 6) or an age of 65 or over limits them to intermediate and
+// prefers beginner.
+func difficultyLimits(age, pain *int) (model.ExerciseDifficulty, model.ExerciseDifficulty) {
+	maxLevel := 3
+	preferred := model.ExerciseDifficulty("")
+	limit := func(level int) {
+		if level < maxLevel {
+			maxLevel = level
+		}
+	}
+
+	if pain != nil {
+		switch {
+		case *pain >= 7:
+			limit(1)
+		case *pain >= 4:
+			limit(2)
+			preferred = model.ExerciseDifficultyBeginner
+		}
+	}
+	if age != nil {
+		switch {
+		case *age >= 75:
+			limit(1)
+		case *age >= 65:
+			limit(2)
+			preferred = model.ExerciseDifficultyBeginner
+		}
+	}
+	if maxLevel == 1 {
+		preferred = model.ExerciseDifficultyBeginner
+	}
+
+	levels := []model.ExerciseDifficulty{model.ExerciseDifficultyBeginner, model.ExerciseDifficultyIntermediate, model.ExerciseDifficultyAdvanced}
+	return levels[maxLevel-1], preferred
+}
+
+// difficultyLevel ranks a difficulty from 1 (beginner) to 3 (advanced).
+// Unknown difficulties rank as intermediate.
+func difficultyLevel(d model.ExerciseDifficulty) int {
+	switch d {
+	case model.ExerciseDifficultyBeginner:
+		return 1
+	case model.ExerciseDifficultyAdvanced:
+		return 3
+	}
+	return 2
+}
+
+// normalizeBodyRegion matches free text to a body region: either a region
+// name such as "lumbar_spine", or text containing an alias such as
+// "Low back" or "Thắt lưng".
+func normalizeBodyRegion(text string) (string, bool) {
+	text = strings.ToLower(strings.TrimSpace(text))
+	if _, ok := regionMuscleGroups[strings.ReplaceAll(text, " ", "_")]; ok {
+		return strings.ReplaceAll(text, " ", "_"), true
+	}
+
+	padded := " " + strings.NewReplacer("_", " ", "-", " ", "/", " ", ",", " ", "(", " ", ")", " ").Replace(text) + " "
+	for _, a := range bodyRegionAliases {
+		if strings.Contains(padded, " "+a.alias+" ") {
+			return a.region, true
+		}
+	}
+	return "", false
+}
+
+// findingFromLabel classifies a lower-case checklist label as a finding.
+func findingFromLabel(label string) (model.ExerciseFindingType, bool) {
+	padded := " " + strings.NewReplacer("_", " ", "-", " ", "/", " ", ",", " ", "(", " ", ")", " ", ":", " ").Replace(label) + " "
+	for _, k := range findingKeywords {
+		if strings.Contains(padded, " "+k.keyword) {
+			return k.finding, true
+		}
+	}
+	return "", false
+}
+
+// movementIn returns the first movement named in lower-case text.
+func movementIn(text string) string {
+	text = strings.ReplaceAll(text, "_", " ")
+	for _, m := range movementKeywords {
+		if strings.Contains(text, m) {
+			return m
+		}
+	}
+	return ""
+}
+
+// isNormalAnswer reports whether a checklist answer records no finding.
+func isNormalAnswer(answer string) bool {
+	answer = strings.ToLower(strings.TrimSpace(strings.ReplaceAll(answer, "_", " ")))
+	return answer == "" || normalChecklistValues[answer]
+}
+
+// describeFinding describes a finding in a recommendation reason, such as
+// "flexion ROM deficit of the lumbar spine".
+func describeFinding(f model.ExerciseFinding) string {
+	text := findingLabels[f.Type]
+	if f.Movement != "" {
+		text = f.Movement + " " + text
+	}
+	if f.BodyRegion != "" {
+		text += " of the " + strings.ReplaceAll(f.BodyRegion, "_", " ")
+	}
+	return text
+}
+
+// describeTolerance describes the age and pain level a difficulty suits.
+func describeTolerance(profile model.ExerciseRecommendationProfile) string {
+	var parts []string
+	if profile.PainLevel != nil && *profile.PainLevel >= 4 {
+		parts = append(parts, fmt.Sprintf("pain %d/10", *profile.PainLevel))
+	}
+	if profile.Age != nil && *profile.Age >= 65 {
+		parts = append(parts, fmt.Sprintf("age %d", *profile.Age))
+	}
+	return joinWords(parts)
+}
+
+// appendFinding adds a finding unless the same one is already listed.
+func appendFinding(findings []model.ExerciseFinding, f model.ExerciseFinding) []model.ExerciseFinding {
+	for _, existing := range findings {
+		if existing == f {
+			return findings
+		}
+	}
+	return append(findings, f)
+}
+
+// appendUnique adds a value unless it is already listed.
+func appendUnique(values []string, v string) []string {
+	for _, existing := range values {
+		if existing == v {
+			return values
+		}
+	}
+	return append(values, v)
+}
+
+// regionsOf lists the regions that call for the groups, in order.
+func regionsOf(groups []model.MuscleGroup, groupRegion map[model.MuscleGroup]string) []string {
+	var regions []string
+	for _, g := range groups {
+		regions = appendUnique(regions, groupRegion[g])
+	}
+	return regions
+}
+
+// humanizeGroups converts muscle groups to words.
+func humanizeGroups(groups []model.MuscleGroup) []string {
+	words := make([]string, len(groups))
+	for i, g := range groups {
+		words[i] = string(g)
+	}
+	return humanize(words)
+}
+
+// humanize replaces underscores in identifiers with spaces.
+func humanize(values []string) []string {
+	words := make([]string, len(values))
+	for i, v := range values {
+		words[i] = strings.ReplaceAll(v, "_", " ")
+	}
+	return words
+}
+
+// joinWords joins words as "a", "a and b" or "a, b and c".
+func joinWords(words []string) string {
+	switch len(words) {
+	case 0:
+		return ""
+	case 1:
+		return words[0]
+	}
+	return strings.Join(words[:len(words)-1], ", ") + " and " + words[len(words)-1]
+}
+
+// capitalize upper-cases the first letter of an ASCII sentence.
+func capitalize(s string) string {
+	if s == "" || s[0] < 'a' || s[0] > 'z' {
+		return s
+	}
+	return string(s[0]-'a'+'A') + s[1:]
+}
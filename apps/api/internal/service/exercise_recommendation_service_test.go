@@ -0,0 +1,227 @@
+package service
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+)
+
+func recommendationExercises() []model.Exercise {
+	return []model.Exercise{
+		{ID: "e1", Name: "Cat-camel", Category: model.ExerciseCategoryMobility, Difficulty: model.ExerciseDifficultyBeginner,
+			MuscleGroups: []model.MuscleGroup{model.MuscleGroupLowerBack, model.MuscleGroupCore}},
+		{ID: "e2", Name: "Knee to chest stretch", Category: model.ExerciseCategoryStretching, Difficulty: model.ExerciseDifficultyBeginner,
+			MuscleGroups: []model.MuscleGroup{model.MuscleGroupLowerBack, model.MuscleGroupGlutes}},
+		{ID: "e3", Name: "Dead bug", Category: model.ExerciseCategoryStrengthening, Difficulty: model.ExerciseDifficultyIntermediate,
+			MuscleGroups: []model.MuscleGroup{model.MuscleGroupCore}},
+		{ID: "e4", Name: "Single leg deadlift", Category: model.ExerciseCategoryStrengthening, Difficulty: model.ExerciseDifficultyAdvanced,
+			MuscleGroups: []model.MuscleGroup{model.MuscleGroupHamstrings, model.MuscleGroupGlutes}},
+		{ID: "e5", Name: "Chin tuck", Category: model.ExerciseCategoryPostural, Difficulty: model.ExerciseDifficultyBeginner,
+			MuscleGroups: []model.MuscleGroup{model.MuscleGroupNeck}},
+	}
+}
+
+func recommendationIDs(recs []model.ExerciseRecommendation) []string {
+	ids := []string{}
+	for _, r := range recs {
+		ids = append(ids, r.Exercise.ID)
+	}
+	return ids
+}
+
+func TestRankExercises(t *testing.T) {
+	lumbarFlexion := []model.ExerciseFinding{{Type: model.FindingROMDeficit, BodyRegion: model.BodyRegionLumbarSpine, Movement: "flexion"}}
+	intPtr := func(v int) *int { return &v }
+
+	tests := []struct {
+		name    string
+		profile model.ExerciseRecommendationProfile
+		history map[string]int
+		limit   int
+		wantIDs []string
+	}{
+		{
+			name: "lumbar flexion ROM deficit",
+			profile: model.ExerciseRecommendationProfile{
+				BodyRegions: []string{model.BodyRegionLumbarSpine},
+				Findings:    lumbarFlexion,
+			},
+			limit:   10,
+			wantIDs: []string{"e1", "e2", "e3", "e4"},
+		},
+		{
+			name: "severe pain keeps beginner exercises",
+			profile: model.ExerciseRecommendationProfile{
+				BodyRegions: []string{model.BodyRegionLumbarSpine},
+				Findings:    lumbarFlexion,
+				PainLevel:   intPtr(8),
+			},
+			limit:   10,
+			wantIDs: []string{"e1", "e2"},
+		},
+		{
+			name: "clinic history for the diagnosis",
+			profile: model.ExerciseRecommendationProfile{
+				DiagnosisCode: "M54.5",
+				BodyRegions:   []string{model.BodyRegionLumbarSpine},
+			},
+			history: map[string]int{"e3": 6, "e5": 1},
+			limit:   10,
+			wantIDs: []string{"e3", "e1", "e2", "e4", "e5"},
+		},
+		{
+			name: "limit",
+			profile: model.ExerciseRecommendationProfile{
+				BodyRegions: []string{model.BodyRegionLumbarSpine},
+				Findings:    lumbarFlexion,
+			},
+			limit:   1,
+			wantIDs: []string{"e1"},
+		},
+		{
+			name: "no matching exercises",
+			profile: model.ExerciseRecommendationProfile{
+				BodyRegions: []string{model.BodyRegionAnkleFoot},
+			},
+			limit:   10,
+			wantIDs: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.profile.MaxDifficulty, tt.profile.PreferredDifficulty = difficultyLimits(tt.profile.Age, tt.profile.PainLevel)
+			got := rankExercises(recommendationExercises(), tt.profile, tt.history, tt.limit)
+			if ids := recommendationIDs(got); !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("rankExercises() = %v; want %v", ids, tt.wantIDs)
+			}
+			for _, r := range got {
+				if r.Reason == "" {
+					t.Errorf("rankExercises() %s has no reason", r.Exercise.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestRankExercisesReason(t *testing.T) {
+	pain := 5
+	profile := model.ExerciseRecommendationProfile{
+		DiagnosisCode: "M54.5",
+		BodyRegions:   []string{model.BodyRegionLumbarSpine},
+		Findings:      []model.ExerciseFinding{{Type: model.FindingROMDeficit, BodyRegion: model.BodyRegionLumbarSpine, Movement: "flexion"}},
+		PainLevel:     &pain,
+	}
+	profile.MaxDifficulty, profile.PreferredDifficulty = difficultyLimits(nil, &pain)
+
+	got := rankExercises(recommendationExercises(), profile, map[string]int{"e1": 4}, 1)
+	if len(got) != 1 {
+		t.Fatalf("rankExercises() returned %d recommendations; want 1", len(got))
+	}
+	want := "Works the lower back and core for the lumbar spine; " +
+		"mobility exercise for the flexion ROM deficit of the lumbar spine; " +
+		"prescribed 4 times at this clinic for M54.5; " +
+		"beginner level suits pain 5/10"
+	if got[0].Reason != want {
+		t.Errorf("rankExercises() reason = %q; want %q", got[0].Reason, want)
+	}
+	if got[0].Score != 3+1+2+2+1 {
+		t.Errorf("rankExercises() score = %d; want 9", got[0].Score)
+	}
+}
+
+func TestDifficultyLimits(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+	tests := []struct {
+		name          string
+		age, pain     *int
+		wantMax       model.ExerciseDifficulty
+		wantPreferred model.ExerciseDifficulty
+	}{
+		{"unknown", nil, nil, model.ExerciseDifficultyAdvanced, ""},
+		{"mild pain", intPtr(40), intPtr(2), model.ExerciseDifficultyAdvanced, ""},
+		{"moderate pain", nil, intPtr(5), model.ExerciseDifficultyIntermediate, model.ExerciseDifficultyBeginner},
+		{"severe pain", nil, intPtr(7), model.ExerciseDifficultyBeginner, model.ExerciseDifficultyBeginner},
+		{"older adult", intPtr(68), nil, model.ExerciseDifficultyIntermediate, model.ExerciseDifficultyBeginner},
+		{"elderly", intPtr(80), intPtr(1), model.ExerciseDifficultyBeginner, model.ExerciseDifficultyBeginner},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotMax, gotPreferred := difficultyLimits(tt.age, tt.pain)
+			if gotMax != tt.wantMax || gotPreferred != tt.wantPreferred {
+				t.Errorf("difficultyLimits() = %q, %q; want %q, %q", gotMax, gotPreferred, tt.wantMax, tt.wantPreferred)
+			}
+		})
+	}
+}
+
+func TestNormalizeBodyRegion(t *testing.T) {
+	tests := []struct {
+		text   string
+		want   string
+		wantOK bool
+	}{
+		{"lumbar_spine", model.BodyRegionLumbarSpine, true},
+		{"Lumbar Spine", model.BodyRegionLumbarSpine, true},
+		{"Low back (L4-L5)", model.BodyRegionLumbarSpine, true},
+		{"Thắt lưng", model.BodyRegionLumbarSpine, true},
+		{"Cổ tay phải", model.BodyRegionWristHand, true},
+		{"Cổ", model.BodyRegionCervicalSpine, true},
+		{"Right knee", model.BodyRegionKnee, true},
+		{"Handle", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, ok := normalizeBodyRegion(tt.text)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("normalizeBodyRegion(%q) = %q, %v; want %q, %v", tt.text, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestChecklistFindings(t *testing.T) {
+	lumbar := "lumbar_spine"
+	template := &model.ChecklistTemplate{BodyRegion: &lumbar}
+	items := []model.ChecklistItem{
+		{ID: "i1", ItemType: model.ItemTypeBodyDiagram, Label: "Pain location"},
+		{ID: "i2", ItemType: model.ItemTypeMultiSelect, Label: "Lumbar ROM limitation"},
+		{ID: "i3", ItemType: model.ItemTypeRadio, Label: "Hip strength"},
+		{ID: "i4", ItemType: model.ItemTypeScale, Label: "Pain (NPRS)"},
+		{ID: "i5", ItemType: model.ItemTypeCheckbox, Label: "Romberg test positive"},
+		{ID: "i6", ItemType: model.ItemTypeCheckbox, Label: "Postural dysfunction"},
+	}
+	response := func(itemID string, v any) model.ChecklistResponse {
+		data, _ := json.Marshal(v)
+		return model.ChecklistResponse{ChecklistItemID: itemID, ResponseValue: data}
+	}
+	responses := []model.ChecklistResponse{
+		response("i1", model.BodyDiagramResponse{Points: []model.BodyDiagramPoint{{Label: "Lower back"}, {Label: "Left knee"}}}),
+		response("i2", model.MultiSelectResponse{Selected: []string{"flexion", "none"}}),
+		response("i3", model.RadioResponse{Selected: "normal"}),
+		response("i4", model.ScaleResponse{Value: 6}),
+		response("i5", model.CheckboxResponse{Checked: true}),
+		response("i6", model.CheckboxResponse{Checked: false}),
+	}
+
+	regions, findings, pain := checklistFindings(template, items, responses)
+
+	if want := []string{model.BodyRegionLumbarSpine, model.BodyRegionKnee}; !reflect.DeepEqual(regions, want) {
+		t.Errorf("checklistFindings() regions = %v; want %v", regions, want)
+	}
+	wantFindings := []model.ExerciseFinding{
+		{Type: model.FindingROMDeficit, BodyRegion: model.BodyRegionLumbarSpine, Movement: "flexion"},
+		{Type: model.FindingBalance, BodyRegion: model.BodyRegionLumbarSpine},
+	}
+	if !reflect.DeepEqual(findings, wantFindings) {
+		t.Errorf("checklistFindings() findings = %+v; want %+v", findings, wantFindings)
+	}
+	if pain == nil || *pain != 6 {
+		t.Errorf("checklistFindings() pain = %v; want 6", pain)
+	}
+}
@@ -20,42 +20,49 @@ type ExerciseService interface {
 	CreateExercise(ctx context.Context, clinicID, userID string, req *model.CreateExerciseRequest) (*model.Exercise, error)
 	GetExercise(ctx context.Context, id string) (*model.Exercise, error)
 	UpdateExercise(ctx context.Context, id, userID string, req *model.UpdateExerciseRequest) (*model.Exercise, error)
-	DeleteExercise(ctx context.Context, id string) error
+	DeleteExercise(ctx context.Context, clinicID, id string) error
 	ListExercises(ctx context.Context, params model.ExerciseSearchParams) (*model.ExerciseListResponse, error)
 	SearchExercises(ctx context.Context, clinicID, query string, limit int) ([]model.Exercise, error)
 
 	// Prescriptions
 	PrescribeExercise(ctx context.Context, clinicID, patientID, userID string, req *model.PrescribeExerciseRequest) (*model.ExercisePrescription, error)
-	GetPrescription(ctx context.Context, id string) (*model.ExercisePrescription, error)
-	UpdatePrescription(ctx context.Context, id string, req *model.UpdatePrescriptionRequest) (*model.ExercisePrescription, error)
-	DeletePrescription(ctx context.Context, id string) error
-	GetPatientPrescriptions(ctx context.Context, patientID string, activeOnly bool) ([]model.ExercisePrescription, error)
+	GetPrescription(ctx context.Context, clinicID, patientID, id string) (*model.ExercisePrescription, error)
+	UpdatePrescription(ctx context.Context, clinicID, patientID, id, userID string, req *model.UpdatePrescriptionRequest) (*model.ExercisePrescription, error)
+	DeletePrescription(ctx context.Context, clinicID, patientID, id string) error
+	GetPatientPrescriptions(ctx context.Context, clinicID, patientID string, activeOnly bool) ([]model.ExercisePrescription, error)
 
 	// Home Exercise Programs
 	CreateProgram(ctx context.Context, clinicID, patientID, userID string, req *model.CreateProgramRequest) (*model.HomeExerciseProgram, error)
 	GetProgram(ctx context.Context, id string) (*model.HomeExerciseProgram, error)
 	GetPatientPrograms(ctx context.Context, patientID string) ([]model.HomeExerciseProgram, error)
 
+	// Frequency schedules
+	MigrateFrequencySchedules(ctx context.Context) (int64, error)
+
 	// Compliance tracking
-	LogCompliance(ctx context.Context, prescriptionID, patientID string, req *model.LogComplianceRequest) (*model.ExerciseComplianceLog, error)
+	LogCompliance(ctx context.Context, clinicID, patientID, prescriptionID string, req *model.LogComplianceRequest) (*model.ExerciseComplianceLog, error)
 	GetComplianceLogs(ctx context.Context, prescriptionID string, limit int) ([]model.ExerciseComplianceLog, error)
-	GetPatientComplianceSummary(ctx context.Context, patientID string) (*model.PatientExerciseSummary, error)
+	GetPatientComplianceSummary(ctx context.Context, clinicID, patientID string) (*model.PatientExerciseSummary, error)
 
 	// PDF generation
-	GenerateHandoutPDF(ctx context.Context, patientID, language string) ([]byte, error)
+	GenerateHandoutPDF(ctx context.Context, clinicID, patientID, language string) ([]byte, error)
 }
 
 // exerciseService implements ExerciseService.
 type exerciseService struct {
-	repo        repository.ExerciseRepository
-	patientRepo repository.PatientRepository
+	repo            repository.ExerciseRepository
+	patientRepo     repository.PatientRepository
+	progressionRepo repository.ProgressionRepository
+	media           ExerciseMediaService
 }
 
 // NewExerciseService creates a new exercise service.
-func NewExerciseService(repo repository.ExerciseRepository, patientRepo repository.PatientRepository) ExerciseService {
+func NewExerciseService(repo repository.ExerciseRepository, patientRepo repository.PatientRepository, progressionRepo repository.ProgressionRepository, media ExerciseMediaService) ExerciseService {
 	return &exerciseService{
-		repo:        repo,
-		patientRepo: patientRepo,
+		repo:            repo,
+		patientRepo:     patientRepo,
+		progressionRepo: progressionRepo,
+		media:           media,
 	}
 }
 
@@ -113,9 +120,23 @@ func (s *exerciseService) CreateExercise(ctx context.Context, clinicID, userID s
 	return exercise, nil
 }
 
-// GetExercise retrieves an exercise by ID.
+// GetExercise retrieves an exercise with its uploaded media.
 func (s *exerciseService) GetExercise(ctx context.Context, id string) (*model.Exercise, error) {
-	return s.repo.GetByID(ctx, id)
+	exercise, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if exercise.IsActive && exercise.ClinicID != nil {
+		items, err := s.media.List(ctx, *exercise.ClinicID, id)
+		if err != nil {
+			log.Warn().Err(err).Str("exercise_id", id).Msg("failed to get exercise media")
+		} else {
+			exercise.Media = items
+		}
+	}
+
+	return exercise, nil
 }
 
 // UpdateExercise updates an existing exercise.
@@ -197,8 +218,23 @@ func (s *exerciseService) UpdateExercise(ctx context.Context, id, userID string,
 	return exercise, nil
 }
 
-// DeleteExercise soft-deletes an exercise.
-func (s *exerciseService) DeleteExercise(ctx context.Context, id string) error {
+// DeleteExercise soft-deletes one of the clinic's own exercises. Its media is
+// kept, so restoring the exercise brings it back.
+func (s *exerciseService) DeleteExercise(ctx context.Context, clinicID, id string) error {
+	exercise, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !exercise.IsActive {
+		return repository.ErrNotFound
+	}
+	if exercise.ClinicID == nil || *exercise.ClinicID != clinicID {
+		if exercise.IsGlobal || exercise.ClinicID == nil {
+			return ErrReadOnlyExercise
+		}
+		return repository.ErrNotFound
+	}
+
 	if err := s.repo.Delete(ctx, id); err != nil {
 		return err
 	}
@@ -255,6 +291,11 @@ func (s *exerciseService) PrescribeExercise(ctx context.Context, clinicID, patie
 		startDate = parsed
 	}
 
+	frequency, schedule, err := model.ResolveFrequency(req.Frequency, req.FrequencySchedule)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", repository.ErrInvalidInput, err)
+	}
+
 	// Calculate end date
 	endDate := startDate.AddDate(0, 0, req.DurationWeeks*7)
 
@@ -281,7 +322,8 @@ func (s *exerciseService) PrescribeExercise(ctx context.Context, clinicID, patie
 		Sets:               sets,
 		Reps:               reps,
 		HoldSeconds:        holdSeconds,
-		Frequency:          req.Frequency,
+		Frequency:          frequency,
+		FrequencySchedule:  schedule,
 		DurationWeeks:      req.DurationWeeks,
 		CustomInstructions: strings.TrimSpace(req.CustomInstructions),
 		Notes:              strings.TrimSpace(req.Notes),
@@ -311,17 +353,32 @@ func (s *exerciseService) PrescribeExercise(ctx context.Context, clinicID, patie
 	return prescription, nil
 }
 
-// GetPrescription retrieves a prescription by ID.
-func (s *exerciseService) GetPrescription(ctx context.Context, id string) (*model.ExercisePrescription, error) {
-	return s.repo.GetPrescriptionByID(ctx, id)
+// GetPrescription retrieves a prescription of a clinic's patient.
+func (s *exerciseService) GetPrescription(ctx context.Context, clinicID, patientID, id string) (*model.ExercisePrescription, error) {
+	return getPatientPrescription(ctx, s.repo, clinicID, patientID, id)
+}
+
+// getPatientPrescription loads a prescription, reporting prescriptions of
+// another clinic or patient as not found.
+func getPatientPrescription(ctx context.Context, repo repository.ExerciseRepository, clinicID, patientID, id string) (*model.ExercisePrescription, error) {
+	prescription, err := repo.GetPrescriptionByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if prescription.ClinicID != clinicID || prescription.PatientID != patientID {
+		return nil, repository.ErrNotFound
+	}
+	return prescription, nil
 }
 
-// UpdatePrescription updates an existing prescription.
-func (s *exerciseService) UpdatePrescription(ctx context.Context, id string, req *model.UpdatePrescriptionRequest) (*model.ExercisePrescription, error) {
-	prescription, err := s.repo.GetPrescriptionByID(ctx, id)
+// UpdatePrescription updates an existing prescription. Changes to sets, reps,
+// hold time and duration are kept in the prescription's history.
+func (s *exerciseService) UpdatePrescription(ctx context.Context, clinicID, patientID, id, userID string, req *model.UpdatePrescriptionRequest) (*model.ExercisePrescription, error) {
+	prescription, err := getPatientPrescription(ctx, s.repo, clinicID, patientID, id)
 	if err != nil {
 		return nil, err
 	}
+	before := *prescription
 
 	if req.Sets != nil {
 		prescription.Sets = *req.Sets
@@ -332,8 +389,17 @@ func (s *exerciseService) UpdatePrescription(ctx context.Context, id string, req
 	if req.HoldSeconds != nil {
 		prescription.HoldSeconds = *req.HoldSeconds
 	}
-	if req.Frequency != nil {
-		prescription.Frequency = *req.Frequency
+	if req.Frequency != nil || req.FrequencySchedule != nil {
+		text := ""
+		if req.Frequency != nil {
+			text = *req.Frequency
+		}
+		frequency, schedule, err := model.ResolveFrequency(text, req.FrequencySchedule)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", repository.ErrInvalidInput, err)
+		}
+		prescription.Frequency = frequency
+		prescription.FrequencySchedule = schedule
 	}
 	if req.DurationWeeks != nil {
 		prescription.DurationWeeks = *req.DurationWeeks
@@ -358,6 +424,7 @@ func (s *exerciseService) UpdatePrescription(ctx context.Context, id string, req
 	if err := s.repo.UpdatePrescription(ctx, prescription); err != nil {
 		return nil, err
 	}
+	s.recordManualChanges(ctx, &before, prescription, userID)
 
 	log.Info().
 		Str("prescription_id", prescription.ID).
@@ -366,8 +433,47 @@ func (s *exerciseService) UpdatePrescription(ctx context.Context, id string, req
 	return prescription, nil
 }
 
-// DeletePrescription deletes a prescription.
-func (s *exerciseService) DeletePrescription(ctx context.Context, id string) error {
+// recordManualChanges adds a therapist's edits of the prescription's tracked
+// parameters to its history.
+func (s *exerciseService) recordManualChanges(ctx context.Context, before, after *model.ExercisePrescription, userID string) {
+	now := time.Now()
+	params := []model.ProgressionParameter{
+		model.ProgressionParamSets,
+		model.ProgressionParamReps,
+		model.ProgressionParamHoldSeconds,
+		model.ProgressionParamDurationWeeks,
+	}
+	for _, param := range params {
+		from, to := param.Value(before), param.Value(after)
+		if from == to {
+			continue
+		}
+
+		change := &model.PrescriptionChange{
+			ID:             uuid.New().String(),
+			ClinicID:       after.ClinicID,
+			PrescriptionID: after.ID,
+			PatientID:      after.PatientID,
+			Source:         model.PrescriptionChangeManual,
+			Parameter:      param,
+			FromValue:      from,
+			ToValue:        to,
+			Status:         model.PrescriptionChangeApplied,
+			ProposedAt:     now,
+			DecidedAt:      &now,
+			DecidedBy:      staffActor(userID),
+		}
+		if err := s.progressionRepo.CreateChange(ctx, change); err != nil {
+			log.Warn().Err(err).Str("prescription_id", after.ID).Str("parameter", string(param)).Msg("failed to record prescription change")
+		}
+	}
+}
+
+// DeletePrescription deletes a prescription of a clinic's patient.
+func (s *exerciseService) DeletePrescription(ctx context.Context, clinicID, patientID, id string) error {
+	if _, err := getPatientPrescription(ctx, s.repo, clinicID, patientID, id); err != nil {
+		return err
+	}
 	if err := s.repo.DeletePrescription(ctx, id); err != nil {
 		return err
 	}
@@ -379,8 +485,11 @@ func (s *exerciseService) DeletePrescription(ctx context.Context, id string) err
 	return nil
 }
 
-// GetPatientPrescriptions retrieves all prescriptions for a patient.
-func (s *exerciseService) GetPatientPrescriptions(ctx context.Context, patientID string, activeOnly bool) ([]model.ExercisePrescription, error) {
+// GetPatientPrescriptions retrieves all prescriptions for a clinic's patient.
+func (s *exerciseService) GetPatientPrescriptions(ctx context.Context, clinicID, patientID string, activeOnly bool) ([]model.ExercisePrescription, error) {
+	if _, err := s.patientRepo.GetByID(ctx, clinicID, patientID); err != nil {
+		return nil, err
+	}
 	return s.repo.ListPatientPrescriptions(ctx, patientID, activeOnly)
 }
 
@@ -396,23 +505,29 @@ func (s *exerciseService) CreateProgram(ctx context.Context, clinicID, patientID
 		startDate = parsed
 	}
 
+	frequency, schedule, err := model.ResolveFrequency(req.Frequency, req.FrequencySchedule)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", repository.ErrInvalidInput, err)
+	}
+
 	// Calculate end date
 	endDate := startDate.AddDate(0, 0, req.DurationWeeks*7)
 
 	program := &model.HomeExerciseProgram{
-		ID:            uuid.New().String(),
-		PatientID:     patientID,
-		ClinicID:      clinicID,
-		CreatedBy:     userID,
-		Name:          strings.TrimSpace(req.Name),
-		NameVi:        strings.TrimSpace(req.NameVi),
-		Description:   strings.TrimSpace(req.Description),
-		DescriptionVi: strings.TrimSpace(req.DescriptionVi),
-		Frequency:     req.Frequency,
-		DurationWeeks: req.DurationWeeks,
-		StartDate:     startDate,
-		EndDate:       &endDate,
-		IsActive:      true,
+		ID:                uuid.New().String(),
+		PatientID:         patientID,
+		ClinicID:          clinicID,
+		CreatedBy:         userID,
+		Name:              strings.TrimSpace(req.Name),
+		NameVi:            strings.TrimSpace(req.NameVi),
+		Description:       strings.TrimSpace(req.Description),
+		DescriptionVi:     strings.TrimSpace(req.DescriptionVi),
+		Frequency:         frequency,
+		FrequencySchedule: schedule,
+		DurationWeeks:     req.DurationWeeks,
+		StartDate:         startDate,
+		EndDate:           &endDate,
+		IsActive:          true,
 	}
 
 	if err := s.repo.CreateProgram(ctx, program); err != nil {
@@ -428,20 +543,21 @@ func (s *exerciseService) CreateProgram(ctx context.Context, clinicID, patientID
 		}
 
 		prescription := &model.ExercisePrescription{
-			ID:            uuid.New().String(),
-			PatientID:     patientID,
-			ExerciseID:    exerciseID,
-			ClinicID:      clinicID,
-			PrescribedBy:  userID,
-			ProgramID:     &program.ID,
-			Sets:          exercise.DefaultSets,
-			Reps:          exercise.DefaultReps,
-			HoldSeconds:   exercise.DefaultHoldSecs,
-			Frequency:     req.Frequency,
-			DurationWeeks: req.DurationWeeks,
-			Status:        model.PrescriptionStatusActive,
-			StartDate:     startDate,
-			EndDate:       &endDate,
+			ID:                uuid.New().String(),
+			PatientID:         patientID,
+			ExerciseID:        exerciseID,
+			ClinicID:          clinicID,
+			PrescribedBy:      userID,
+			ProgramID:         &program.ID,
+			Sets:              exercise.DefaultSets,
+			Reps:              exercise.DefaultReps,
+			HoldSeconds:       exercise.DefaultHoldSecs,
+			Frequency:         frequency,
+			FrequencySchedule: schedule,
+			DurationWeeks:     req.DurationWeeks,
+			Status:            model.PrescriptionStatusActive,
+			StartDate:         startDate,
+			EndDate:           &endDate,
 		}
 
 		if err := s.repo.CreatePrescription(ctx, prescription); err != nil {
@@ -473,18 +589,50 @@ func (s *exerciseService) GetPatientPrograms(ctx context.Context, patientID stri
 	return s.repo.ListPatientPrograms(ctx, patientID)
 }
 
-// LogCompliance logs an exercise completion.
-func (s *exerciseService) LogCompliance(ctx context.Context, prescriptionID, patientID string, req *model.LogComplianceRequest) (*model.ExerciseComplianceLog, error) {
-	// Verify prescription exists
-	_, err := s.repo.GetPrescriptionByID(ctx, prescriptionID)
+// MigrateFrequencySchedules parses the free-text frequencies of
+// prescriptions and programs stored without a schedule and stores the
+// schedules of those it understands. It returns how many were updated.
+func (s *exerciseService) MigrateFrequencySchedules(ctx context.Context) (int64, error) {
+	frequencies, err := s.repo.ListUnscheduledFrequencies(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var updated int64
+	unparsed := 0
+	for _, frequency := range frequencies {
+		schedule, ok := model.ParseFrequency(frequency)
+		if !ok {
+			unparsed++
+			continue
+		}
+		n, err := s.repo.SetFrequencySchedule(ctx, frequency, schedule)
+		if err != nil {
+			return updated, err
+		}
+		updated += n
+	}
+
+	log.Info().
+		Int64("updated", updated).
+		Int("unparsed_frequencies", unparsed).
+		Msg("frequency schedules migrated")
+
+	return updated, nil
+}
+
+// LogCompliance logs an exercise completion against a prescription of a
+// clinic's patient.
+func (s *exerciseService) LogCompliance(ctx context.Context, clinicID, patientID, prescriptionID string, req *model.LogComplianceRequest) (*model.ExerciseComplianceLog, error) {
+	prescription, err := getPatientPrescription(ctx, s.repo, clinicID, patientID, prescriptionID)
 	if err != nil {
-		return nil, fmt.Errorf("prescription not found: %w", err)
+		return nil, err
 	}
 
 	complianceLog := &model.ExerciseComplianceLog{
 		ID:             uuid.New().String(),
-		PrescriptionID: prescriptionID,
-		PatientID:      patientID,
+		PrescriptionID: prescription.ID,
+		PatientID:      prescription.PatientID,
 		CompletedAt:    time.Now(),
 		SetsCompleted:  req.SetsCompleted,
 		RepsCompleted:  req.RepsCompleted,
@@ -511,13 +659,21 @@ func (s *exerciseService) GetComplianceLogs(ctx context.Context, prescriptionID
 	return s.repo.GetComplianceLogs(ctx, prescriptionID, limit)
 }
 
-// GetPatientComplianceSummary retrieves a compliance summary for a patient.
-func (s *exerciseService) GetPatientComplianceSummary(ctx context.Context, patientID string) (*model.PatientExerciseSummary, error) {
+// GetPatientComplianceSummary retrieves a compliance summary for a clinic's patient.
+func (s *exerciseService) GetPatientComplianceSummary(ctx context.Context, clinicID, patientID string) (*model.PatientExerciseSummary, error) {
+	if _, err := s.patientRepo.GetByID(ctx, clinicID, patientID); err != nil {
+		return nil, err
+	}
 	return s.repo.GetPatientComplianceSummary(ctx, patientID)
 }
 
-// GenerateHandoutPDF generates a PDF handout of prescribed exercises.
-func (s *exerciseService) GenerateHandoutPDF(ctx context.Context, patientID, language string) ([]byte, error) {
+// GenerateHandoutPDF generates a PDF handout of a clinic's patient's prescribed exercises.
+func (s *exerciseService) GenerateHandoutPDF(ctx context.Context, clinicID, patientID, language string) ([]byte, error) {
+	patient, err := s.patientRepo.GetByID(ctx, clinicID, patientID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Get active prescriptions
 	prescriptions, err := s.repo.ListPatientPrescriptions(ctx, patientID, true)
 	if err != nil {
@@ -542,15 +698,12 @@ func (s *exerciseService) GenerateHandoutPDF(ctx context.Context, patientID, lan
 	}
 
 	// Patient info
-	patient, _ := s.patientRepo.GetByID(ctx, "", patientID)
-	if patient != nil {
-		if language == "vi" {
-			buf.WriteString(fmt.Sprintf("Benh nhan: %s\n", patient.FullNameVi()))
-		} else {
-			buf.WriteString(fmt.Sprintf("Patient: %s\n", patient.FullName()))
-		}
-		buf.WriteString(fmt.Sprintf("MRN: %s\n", patient.MRN))
+	if language == "vi" {
+		buf.WriteString(fmt.Sprintf("Benh nhan: %s\n", patient.FullNameVi()))
+	} else {
+		buf.WriteString(fmt.Sprintf("Patient: %s\n", patient.FullName()))
 	}
+	buf.WriteString(fmt.Sprintf("MRN: %s\n", patient.MRN))
 
 	buf.WriteString(fmt.Sprintf("Date: %s\n\n", time.Now().Format("2006-01-02")))
 	buf.WriteString("----------------------------------------\n\n")
@@ -571,7 +724,7 @@ func (s *exerciseService) GenerateHandoutPDF(ctx context.Context, patientID, lan
 			if p.HoldSeconds > 0 {
 				buf.WriteString(fmt.Sprintf(" | Giu: %d giay", p.HoldSeconds))
 			}
-			buf.WriteString(fmt.Sprintf("\n   Tan suat: %s\n", p.Frequency))
+			buf.WriteString(fmt.Sprintf("\n   Tan suat: %s\n", model.FormatFrequency(p.Frequency, p.FrequencySchedule, language)))
 			if exercise.InstructionsVi != "" {
 				buf.WriteString(fmt.Sprintf("\n   Huong dan:\n   %s\n", exercise.InstructionsVi))
 			}
@@ -588,7 +741,7 @@ func (s *exerciseService) GenerateHandoutPDF(ctx context.Context, patientID, lan
 			if p.HoldSeconds > 0 {
 				buf.WriteString(fmt.Sprintf(" | Hold: %d seconds", p.HoldSeconds))
 			}
-			buf.WriteString(fmt.Sprintf("\n   Frequency: %s\n", p.Frequency))
+			buf.WriteString(fmt.Sprintf("\n   Frequency: %s\n", model.FormatFrequency(p.Frequency, p.FrequencySchedule, language)))
 			if exercise.Instructions != "" {
 				buf.WriteString(fmt.Sprintf("\n   Instructions:\n   %s\n", exercise.Instructions))
 			}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/repository"
+)
+
+// prescriptionStore holds one prescription and records writes to it. Methods
+// the tests do not expect panic through the nil embedded interface.
+type prescriptionStore struct {
+	repository.ExerciseRepository
+	prescription model.ExercisePrescription
+	logs         []*model.ExerciseComplianceLog
+	deleted      []string
+	updated      int
+}
+
+func (r *prescriptionStore) GetPrescriptionByID(ctx context.Context, id string) (*model.ExercisePrescription, error) {
+	if id != r.prescription.ID {
+		return nil, repository.ErrNotFound
+	}
+	p := r.prescription
+	return &p, nil
+}
+
+func (r *prescriptionStore) LogCompliance(ctx context.Context, log *model.ExerciseComplianceLog) error {
+	r.logs = append(r.logs, log)
+	return nil
+}
+
+func (r *prescriptionStore) DeletePrescription(ctx context.Context, id string) error {
+	r.deleted = append(r.deleted, id)
+	return nil
+}
+
+func (r *prescriptionStore) UpdatePrescription(ctx context.Context, p *model.ExercisePrescription) error {
+	r.updated++
+	return nil
+}
+
+func TestPrescriptionScopedToClinicAndPatient(t *testing.T) {
+	ctx := context.Background()
+	sets := 4
+
+	tests := []struct {
+		name                string
+		clinicID, patientID string
+	}{
+		{"other clinic", "clinic-2", "patient-1"},
+		{"other patient", "clinic-1", "patient-2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &prescriptionStore{prescription: model.ExercisePrescription{ID: "rx-1", ClinicID: "clinic-1", PatientID: "patient-1"}}
+			s := &exerciseService{repo: store}
+
+			if _, err := s.GetPrescription(ctx, tt.clinicID, tt.patientID, "rx-1"); !errors.Is(err, repository.ErrNotFound) {
+				t.Errorf("GetPrescription error = %v; want ErrNotFound", err)
+			}
+			if _, err := s.UpdatePrescription(ctx, tt.clinicID, tt.patientID, "rx-1", "user-1", &model.UpdatePrescriptionRequest{Sets: &sets}); !errors.Is(err, repository.ErrNotFound) {
+				t.Errorf("UpdatePrescription error = %v; want ErrNotFound", err)
+			}
+			if err := s.DeletePrescription(ctx, tt.clinicID, tt.patientID, "rx-1"); !errors.Is(err, repository.ErrNotFound) {
+				t.Errorf("DeletePrescription error = %v; want ErrNotFound", err)
+			}
+			if _, err := s.LogCompliance(ctx, tt.clinicID, tt.patientID, "rx-1", &model.LogComplianceRequest{SetsCompleted: 3}); !errors.Is(err, repository.ErrNotFound) {
+				t.Errorf("LogCompliance error = %v; want ErrNotFound", err)
+			}
+
+			if store.updated != 0 || len(store.deleted) != 0 || len(store.logs) != 0 {
+				t.Errorf("wrote to another clinic's prescription: %d updates, %d deletes, %d logs", store.updated, len(store.deleted), len(store.logs))
+			}
+		})
+	}
+}
+
+func TestLogComplianceUsesPrescriptionPatient(t *testing.T) {
+	store := &prescriptionStore{prescription: model.ExercisePrescription{ID: "rx-1", ClinicID: "clinic-1", PatientID: "patient-1"}}
+	s := &exerciseService{repo: store}
+
+	got, err := s.LogCompliance(context.Background(), "clinic-1", "patient-1", "rx-1", &model.LogComplianceRequest{SetsCompleted: 3})
+	if err != nil {
+		t.Fatalf("LogCompliance: %v", err)
+	}
+	if got.PrescriptionID != "rx-1" || got.PatientID != "patient-1" {
+		t.Errorf("log = prescription %q, patient %q; want rx-1, patient-1", got.PrescriptionID, got.PatientID)
+	}
+	if len(store.logs) != 1 {
+		t.Errorf("stored %d logs; want 1", len(store.logs))
+	}
+}
+
+// exerciseStore holds one exercise and records deletes.
+type exerciseStore struct {
+	repository.ExerciseRepository
+	exercise model.Exercise
+	deleted  []string
+}
+
+func (r *exerciseStore) GetByID(ctx context.Context, id string) (*model.Exercise, error) {
+	if id != r.exercise.ID {
+		return nil, repository.ErrNotFound
+	}
+	e := r.exercise
+	return &e, nil
+}
+
+func (r *exerciseStore) Delete(ctx context.Context, id string) error {
+	r.deleted = append(r.deleted, id)
+	return nil
+}
+
+func TestDeleteExerciseOnlyOwnExercises(t *testing.T) {
+	own, other := "clinic-1", "clinic-2"
+
+	tests := []struct {
+		name     string
+		exercise model.Exercise
+		wantErr  error
+	}{
+		{"own exercise", model.Exercise{ID: "ex-1", ClinicID: &own, IsActive: true}, nil},
+		{"global exercise", model.Exercise{ID: "ex-1", IsGlobal: true, IsActive: true}, ErrReadOnlyExercise},
+		{"other clinic's exercise", model.Exercise{ID: "ex-1", ClinicID: &other, IsActive: true}, repository.ErrNotFound},
+		{"already deleted", model.Exercise{ID: "ex-1", ClinicID: &own}, repository.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// No media service: a soft delete must not touch the exercise's media
+			store := &exerciseStore{exercise: tt.exercise}
+			s := &exerciseService{repo: store}
+
+			err := s.DeleteExercise(context.Background(), own, "ex-1")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("DeleteExercise error = %v; want %v", err, tt.wantErr)
+			}
+			if deleted := len(store.deleted) == 1; deleted != (tt.wantErr == nil) {
+				t.Errorf("deleted = %v; want %v", deleted, tt.wantErr == nil)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+)
+
+// bundleWriter writes files into an export zip and records a manifest entry
+// with the size and SHA-256 checksum of each one.
+type bundleWriter struct {
+	zw       *zip.Writer
+	modified time.Time
+	files    []model.ExportFile
+}
+
+// newBundleWriter creates a bundle writer. Every entry is stamped with the
+// given time so that identical records produce identical archives.
+func newBundleWriter(w io.Writer, modified time.Time) *bundleWriter {
+	return &bundleWriter{
+		zw:       zip.NewWriter(w),
+		modified: modified,
+		files:    []model.ExportFile{},
+	}
+}
+
+// add streams a file into the archive.
+func (b *bundleWriter) add(path, contentType, description string, r io.Reader) error {
+	f, err := b.zw.CreateHeader(&zip.FileHeader{
+		Name:     path,
+		Method:   zip.Deflate,
+		Modified: b.modified,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add %s: %w", path, err)
+	}
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(f, hash), r)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	b.files = append(b.files, model.ExportFile{
+		Path:        path,
+		ContentType: contentType,
+		SizeBytes:   size,
+		SHA256:      hex.EncodeToString(hash.Sum(nil)),
+		Description: description,
+	})
+	return nil
+}
+
+// addJSON writes a value as indented JSON.
+func (b *bundleWriter) addJSON(path, description string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", path, err)
+	}
+	return b.add(path, "application/json", description, bytes.NewReader(data))
+}
+
+// addText writes a UTF-8 text file.
+func (b *bundleWriter) addText(path, description, text string) error {
+	return b.add(path, "text/plain; charset=utf-8", description, strings.NewReader(text))
+}
+
+// addCSV writes a CSV file with a header row. A UTF-8 byte order mark is
+// prepended so spreadsheet tools display Vietnamese text correctly.
+func (b *bundleWriter) addCSV(path, description string, header []string, rows [][]string) error {
+	var buf bytes.Buffer
+	buf.WriteString("\ufeff")
+	cw := csv.NewWriter(&buf)
+	if err := cw.Write(header); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", path, err)
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", path, err)
+	}
+	return b.add(path, "text/csv; charset=utf-8", description, &buf)
+}
+
+// close writes manifest.json and finalizes the archive. The manifest lists
+// every other file; it cannot contain its own checksum.
+func (b *bundleWriter) close(manifest *model.ExportManifest) error {
+	manifest.Files = b.files
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	f, err := b.zw.CreateHeader(&zip.FileHeader{
+		Name:     "manifest.json",
+		Method:   zip.Deflate,
+		Modified: b.modified,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add manifest: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	return b.zw.Close()
+}
+
+// painCSVHeader is the header row of pain_history.csv.
+var painCSVHeader = []string{"recorded_at", "level", "location", "body_region", "context", "therapist_id", "notes"}
+
+// painCSVRows converts pain records to CSV rows.
+func painCSVRows(records []model.QuickPainRecord) [][]string {
+	rows := make([][]string, 0, len(records))
+	for _, r := range records {
+		rows = append(rows, []string{
+			r.RecordedAt.UTC().Format(time.RFC3339),
+			strconv.Itoa(r.Level),
+			r.Location,
+			r.BodyRegion,
+			r.Context,
+			r.TherapistID,
+			r.Notes,
+		})
+	}
+	return rows
+}
+
+// romCSVHeader is the header row of rom_history.csv.
+var romCSVHeader = []string{"recorded_at", "joint", "movement", "side", "active_rom", "passive_rom", "is_painful", "therapist_id", "notes"}
+
+// romCSVRows converts ROM records to CSV rows.
+func romCSVRows(records []model.QuickROMRecord) [][]string {
+	rows := make([][]string, 0, len(records))
+	for _, r := range records {
+		rows = append(rows, []string{
+			r.RecordedAt.UTC().Format(time.RFC3339),
+			r.Joint,
+			r.Movement,
+			r.Side,
+			formatOptionalFloat(r.ActiveROM),
+			formatOptionalFloat(r.PassiveROM),
+			strconv.FormatBool(r.IsPainful),
+			r.TherapistID,
+			r.Notes,
+		})
+	}
+	return rows
+}
+
+// formatOptionalFloat formats a measurement, leaving missing values empty.
+func formatOptionalFloat(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*v, 'f', -1, 64)
+}
+
+// visitNoteDate returns the date a visit note belongs to.
+func visitNoteDate(n model.ExportVisitNote) time.Time {
+	if n.CompletedAt != nil {
+		return *n.CompletedAt
+	}
+	return n.CreatedAt
+}
+
+// renderVisitNote prefixes a generated SOAP note with its visit details.
+func renderVisitNote(n model.ExportVisitNote, body string, vi bool) string {
+	var sb strings.Builder
+	if vi {
+		fmt.Fprintf(&sb, "Mẫu: %s\n", n.TemplateName)
+		fmt.Fprintf(&sb, "Chuyên viên: %s\n", n.TherapistName)
+		fmt.Fprintf(&sb, "Ngày: %s\n", visitNoteDate(n).Format("02/01/2006 15:04"))
+	} else {
+		fmt.Fprintf(&sb, "Template: %s\n", n.TemplateName)
+		fmt.Fprintf(&sb, "Therapist: %s\n", n.TherapistName)
+		fmt.Fprintf(&sb, "Date: %s\n", visitNoteDate(n).Format("2006-01-02 15:04"))
+	}
+	sb.WriteString("\n")
+	sb.WriteString(body)
+	sb.WriteString("\n")
+	return sb.String()
+}
@@ -0,0 +1,485 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/repository"
+	"github.com/tqvdang/physioflow/apps/api/internal/storage"
+)
+
+// Data export errors
+var (
+	ErrExportNotReady = errors.New("export is not ready for download")
+	ErrExportExpired  = errors.New("export download link has expired")
+)
+
+const (
+	// exportLinkTTL is how long a finished bundle stays downloadable.
+	exportLinkTTL = 7 * 24 * time.Hour
+	// exportTimeout bounds how long a single bundle may take to build.
+	exportTimeout = 30 * time.Minute
+	// maxConcurrentExports limits how many bundles each instance builds at once.
+	maxConcurrentExports = 2
+	// exportAbandonedAfter is how long a job may stay running before it is
+	// taken to have been lost with the instance building it.
+	exportAbandonedAfter = exportTimeout + 5*time.Minute
+)
+
+// ExportService defines the interface for patient record exports.
+//
+// An export is a zip bundle holding the patient's demographics, insurance,
+// appointments, bilingual SOAP notes, pain and ROM history as CSV,
+// prescriptions and attachments, plus a manifest.json with the SHA-256 of
+// every file. API requests are queued as jobs in the database and built by
+// RunWorker; the CLI writes a bundle directly with ExportTo. Both paths are
+// recorded in the audit trail.
+type ExportService interface {
+	RequestExport(ctx context.Context, clinicID, patientID, requestedBy string, req *model.CreateExportRequest) (*model.DataExport, error)
+	GetExport(ctx context.Context, clinicID, id string) (*model.DataExport, error)
+	ListPatientExports(ctx context.Context, clinicID, patientID string) ([]model.DataExport, error)
+	OpenExport(ctx context.Context, clinicID, id, userID string) (io.ReadCloser, *model.DataExport, error)
+	ExportTo(ctx context.Context, clinicID, patientID, actorID string, req *model.CreateExportRequest, w io.Writer) (*model.ExportManifest, error)
+	RunWorker(ctx context.Context, interval time.Duration)
+}
+
+// exportService implements ExportService.
+type exportService struct {
+	repo           repository.ExportRepository
+	patientRepo    repository.PatientRepository
+	exerciseRepo   repository.ExerciseRepository
+	attachmentRepo repository.AttachmentRepository
+	auditRepo      repository.AuditRepository
+	blobs          storage.BlobStore
+	consents       ConsentService
+	slots          chan struct{}
+	wake           chan struct{}
+}
+
+// NewExportService creates a new data export service.
+func NewExportService(repo *repository.Repository, consents ConsentService) ExportService {
+	return &exportService{
+		repo:           repo.Export(),
+		patientRepo:    repo.Patient(),
+		exerciseRepo:   repo.Exercise(),
+		attachmentRepo: repo.Attachment(),
+		auditRepo:      repo.Audit(),
+		blobs:          repo.Blobs(),
+		consents:       consents,
+		slots:          make(chan struct{}, maxConcurrentExports),
+		wake:           make(chan struct{}, 1),
+	}
+}
+
+// RequestExport queues an export job for the worker to build.
+func (s *exportService) RequestExport(ctx context.Context, clinicID, patientID, requestedBy string, req *model.CreateExportRequest) (*model.DataExport, error) {
+	job, err := s.newJob(ctx, clinicID, patientID, requestedBy, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateJob(ctx, job); err != nil {
+		return nil, err
+	}
+
+	recordAudit(ctx, s.auditRepo, &model.AuditLog{
+		ClinicID:     &clinicID,
+		ActorID:      requestedBy,
+		PatientID:    &patientID,
+		Action:       "export.request",
+		ResourceType: "data_export",
+		ResourceID:   job.ID,
+		Metadata: map[string]interface{}{
+			"purpose":             job.Purpose,
+			"recipient":           job.Recipient,
+			"include_attachments": job.IncludeAttachments,
+		},
+	})
+
+	s.signal()
+
+	return job, nil
+}
+
+// ExportTo writes a patient's export bundle directly to w without queuing a job.
+func (s *exportService) ExportTo(ctx context.Context, clinicID, patientID, actorID string, req *model.CreateExportRequest, w io.Writer) (*model.ExportManifest, error) {
+	job, err := s.newJob(ctx, clinicID, patientID, actorID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := s.writeBundle(ctx, job, w)
+	if err != nil {
+		return nil, err
+	}
+
+	recordAudit(ctx, s.auditRepo, &model.AuditLog{
+		ClinicID:     &clinicID,
+		ActorID:      actorID,
+		PatientID:    &patientID,
+		Action:       "export.direct",
+		ResourceType: "data_export",
+		ResourceID:   job.ID,
+		Metadata: map[string]interface{}{
+			"purpose":             job.Purpose,
+			"recipient":           job.Recipient,
+			"include_attachments": job.IncludeAttachments,
+			"files":               len(manifest.Files),
+		},
+	})
+
+	return manifest, nil
+}
+
+// newJob validates an export request. Patients are always entitled to their
+// own record; sending it to another provider also requires data_processing consent.
+func (s *exportService) newJob(ctx context.Context, clinicID, patientID, requestedBy string, req *model.CreateExportRequest) (*model.DataExport, error) {
+	if _, err := s.patientRepo.GetByID(ctx, clinicID, patientID); err != nil {
+		return nil, err
+	}
+
+	purpose := model.ExportPurpose(req.Purpose)
+	if purpose == model.ExportPurposeReferral {
+		if err := s.consents.RequireConsent(ctx, clinicID, patientID, model.ConsentTypeDataProcessing); err != nil {
+			return nil, err
+		}
+	}
+
+	return &model.DataExport{
+		ID:                 uuid.New().String(),
+		ClinicID:           clinicID,
+		PatientID:          patientID,
+		Purpose:            purpose,
+		Recipient:          req.Recipient,
+		IncludeAttachments: req.IncludeAttachments == nil || *req.IncludeAttachments,
+		Status:             model.ExportStatusPending,
+		RequestedBy:        requestedBy,
+	}, nil
+}
+
+// GetExport returns an export job.
+func (s *exportService) GetExport(ctx context.Context, clinicID, id string) (*model.DataExport, error) {
+	return s.repo.GetJob(ctx, clinicID, id)
+}
+
+// ListPatientExports returns the patient's export jobs.
+func (s *exportService) ListPatientExports(ctx context.Context, clinicID, patientID string) ([]model.DataExport, error) {
+	return s.repo.ListJobsByPatient(ctx, clinicID, patientID)
+}
+
+// OpenExport returns the finished bundle for download. The caller must close the reader.
+func (s *exportService) OpenExport(ctx context.Context, clinicID, id, userID string) (io.ReadCloser, *model.DataExport, error) {
+	job, err := s.repo.GetJob(ctx, clinicID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !job.IsDownloadable(time.Now()) {
+		if job.Status == model.ExportStatusCompleted {
+			return nil, nil, ErrExportExpired
+		}
+		return nil, nil, ErrExportNotReady
+	}
+
+	content, _, err := s.blobs.Get(ctx, job.BlobKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil, ErrExportExpired
+		}
+		return nil, nil, fmt.Errorf("failed to open export bundle: %w", err)
+	}
+
+	recordAudit(ctx, s.auditRepo, &model.AuditLog{
+		ClinicID:     &clinicID,
+		ActorID:      userID,
+		PatientID:    &job.PatientID,
+		Action:       "export.download",
+		ResourceType: "data_export",
+		ResourceID:   job.ID,
+	})
+
+	return content, job, nil
+}
+
+// writeBundle writes the zip bundle for a job to w and returns its manifest.
+func (s *exportService) writeBundle(ctx context.Context, job *model.DataExport, w io.Writer) (*model.ExportManifest, error) {
+	patient, err := s.patientRepo.GetByID(ctx, job.ClinicID, job.PatientID)
+	if err != nil {
+		return nil, err
+	}
+
+	insurance, err := s.patientRepo.GetInsuranceInfo(ctx, patient.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load insurance: %w", err)
+	}
+	appointments, err := s.repo.ListAppointments(ctx, job.ClinicID, patient.ID)
+	if err != nil {
+		return nil, err
+	}
+	notes, err := s.repo.ListVisitNotes(ctx, job.ClinicID, patient.ID)
+	if err != nil {
+		return nil, err
+	}
+	pain, err := s.repo.ListPainRecords(ctx, job.ClinicID, patient.ID)
+	if err != nil {
+		return nil, err
+	}
+	rom, err := s.repo.ListROMRecords(ctx, job.ClinicID, patient.ID)
+	if err != nil {
+		return nil, err
+	}
+	allPrescriptions, err := s.exerciseRepo.ListPatientPrescriptions(ctx, patient.ID, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load prescriptions: %w", err)
+	}
+	prescriptions := make([]model.ExercisePrescription, 0, len(allPrescriptions))
+	for _, p := range allPrescriptions {
+		if p.ClinicID == job.ClinicID {
+			prescriptions = append(prescriptions, p)
+		}
+	}
+
+	generatedAt := time.Now().UTC()
+	b := newBundleWriter(w, generatedAt)
+
+	if err := b.addJSON("patient.json", "Patient demographics", patient); err != nil {
+		return nil, err
+	}
+	if err := b.addJSON("insurance.json", "Insurance information", insurance); err != nil {
+		return nil, err
+	}
+	if err := b.addJSON("appointments.json", "Appointment history", appointments); err != nil {
+		return nil, err
+	}
+
+	for _, n := range notes {
+		base := fmt.Sprintf("notes/%s_%s", visitNoteDate(n).Format("2006-01-02"), n.VisitChecklistID)
+		if n.Note != "" {
+			if err := b.addText(base+"_en.txt", "SOAP note (English)", renderVisitNote(n, n.Note, false)); err != nil {
+				return nil, err
+			}
+		}
+		if n.NoteVi != "" {
+			if err := b.addText(base+"_vi.txt", "SOAP note (Vietnamese)", renderVisitNote(n, n.NoteVi, true)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := b.addCSV("pain_history.csv", "Pain scores (0-10)", painCSVHeader, painCSVRows(pain)); err != nil {
+		return nil, err
+	}
+	if err := b.addCSV("rom_history.csv", "Range of motion measurements (degrees)", romCSVHeader, romCSVRows(rom)); err != nil {
+		return nil, err
+	}
+	if err := b.addJSON("prescriptions.json", "Exercise prescriptions", prescriptions); err != nil {
+		return nil, err
+	}
+
+	if job.IncludeAttachments {
+		attachments, err := s.attachmentRepo.ListByPatient(ctx, job.ClinicID, patient.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range attachments {
+			if err := s.addAttachment(ctx, b, a); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	manifest := &model.ExportManifest{
+		FormatVersion: model.ExportFormatVersion,
+		ExportID:      job.ID,
+		GeneratedAt:   generatedAt,
+		ClinicID:      job.ClinicID,
+		PatientID:     patient.ID,
+		PatientMRN:    patient.MRN,
+		Purpose:       job.Purpose,
+		Recipient:     job.Recipient,
+	}
+	if err := b.close(manifest); err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// addAttachment copies one stored attachment into the bundle.
+func (s *exportService) addAttachment(ctx context.Context, b *bundleWriter, a model.PatientAttachment) error {
+	content, _, err := s.blobs.Get(ctx, a.BlobKey)
+	if err != nil {
+		return fmt.Errorf("failed to open attachment %s: %w", a.ID, err)
+	}
+	defer content.Close()
+
+	path := fmt.Sprintf("attachments/%s/%s_%s", a.Category, a.ID, a.FileName)
+	description := a.Description
+	if description == "" {
+		description = string(a.Category)
+	}
+	return b.add(path, a.ContentType, description, content)
+}
+
+// RunWorker builds queued exports until ctx is cancelled. Jobs are claimed
+// from the database, so they survive restarts and are shared between API
+// instances. Jobs left running by an instance that stopped are marked failed
+// once they have run longer than any build may take.
+func (s *exportService) RunWorker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.failAbandoned(ctx)
+		s.claimJobs(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// signal wakes the worker without waiting for its next poll.
+func (s *exportService) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// claimJobs starts pending jobs while this instance has free build slots.
+func (s *exportService) claimJobs(ctx context.Context) {
+	for {
+		select {
+		case s.slots <- struct{}{}:
+		default:
+			return
+		}
+
+		job, err := s.repo.ClaimJob(ctx)
+		if err != nil {
+			<-s.slots
+			if !errors.Is(err, repository.ErrNotFound) {
+				log.Error().Err(err).Msg("failed to claim export job")
+			}
+			return
+		}
+
+		go func() {
+			defer s.signal()
+			defer func() { <-s.slots }()
+			s.run(job)
+		}()
+	}
+}
+
+// failAbandoned marks jobs whose instance stopped while building them as failed.
+func (s *exportService) failAbandoned(ctx context.Context) {
+	jobs, err := s.repo.FailStaleJobs(ctx, time.Now().Add(-exportAbandonedAfter), "export was interrupted, please request it again")
+	if err != nil {
+		log.Error().Err(err).Msg("failed to check for abandoned exports")
+		return
+	}
+
+	for _, job := range jobs {
+		log.Warn().Str("export_id", job.ID).Msg("abandoned patient export marked failed")
+		recordAudit(ctx, s.auditRepo, &model.AuditLog{
+			ClinicID:     &job.ClinicID,
+			ActorID:      job.RequestedBy,
+			PatientID:    &job.PatientID,
+			Action:       "export.fail",
+			ResourceType: "data_export",
+			ResourceID:   job.ID,
+		})
+	}
+}
+
+// run builds a claimed job's bundle, stores it and marks the job finished.
+// It runs detached from the worker's context; a build lost when the process
+// stops is picked up by failAbandoned.
+func (s *exportService) run(job *model.DataExport) {
+	ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+	defer cancel()
+
+	if err := s.build(ctx, job); err != nil {
+		log.Error().Err(err).Str("export_id", job.ID).Msg("patient export failed")
+		job.Status = model.ExportStatusFailed
+		job.Error = err.Error()
+		if err := s.repo.UpdateJob(ctx, job); err != nil {
+			log.Error().Err(err).Str("export_id", job.ID).Msg("failed to mark export failed")
+		}
+		recordAudit(ctx, s.auditRepo, &model.AuditLog{
+			ClinicID:     &job.ClinicID,
+			ActorID:      job.RequestedBy,
+			PatientID:    &job.PatientID,
+			Action:       "export.fail",
+			ResourceType: "data_export",
+			ResourceID:   job.ID,
+		})
+		return
+	}
+
+	recordAudit(ctx, s.auditRepo, &model.AuditLog{
+		ClinicID:     &job.ClinicID,
+		ActorID:      job.RequestedBy,
+		PatientID:    &job.PatientID,
+		Action:       "export.complete",
+		ResourceType: "data_export",
+		ResourceID:   job.ID,
+		Metadata: map[string]interface{}{
+			"size_bytes": job.SizeBytes,
+			"sha256":     job.SHA256,
+		},
+	})
+
+	log.Info().
+		Str("export_id", job.ID).
+		Str("patient_id", job.PatientID).
+		Int64("size_bytes", job.SizeBytes).
+		Msg("patient export completed")
+}
+
+// build writes the bundle to a temporary file, then moves it to the blob store.
+func (s *exportService) build(ctx context.Context, job *model.DataExport) error {
+	tmp, err := os.CreateTemp("", "physioflow-export-*.zip")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if _, err := s.writeBundle(ctx, job, tmp); err != nil {
+		return err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to rewind bundle: %w", err)
+	}
+
+	key := fmt.Sprintf("exports/%s/%s.zip", job.ClinicID, job.ID)
+	info, err := s.blobs.Put(ctx, key, tmp, "application/zip")
+	if err != nil {
+		return fmt.Errorf("failed to store bundle: %w", err)
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(exportLinkTTL)
+	job.Status = model.ExportStatusCompleted
+	job.BlobKey = info.Key
+	job.SizeBytes = info.Size
+	job.SHA256 = info.SHA256
+	job.CompletedAt = &now
+	job.ExpiresAt = &expiresAt
+
+	return s.repo.UpdateJob(ctx, job)
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/repository"
+)
+
+// exportJobStore records claims and stale-job sweeps.
+type exportJobStore struct {
+	repository.ExportRepository
+	stale       []model.DataExport
+	staleBefore time.Time
+	claims      int
+}
+
+func (r *exportJobStore) ClaimJob(ctx context.Context) (*model.DataExport, error) {
+	r.claims++
+	return nil, repository.ErrNotFound
+}
+
+func (r *exportJobStore) FailStaleJobs(ctx context.Context, before time.Time, reason string) ([]model.DataExport, error) {
+	r.staleBefore = before
+	return r.stale, nil
+}
+
+// auditLogStore records audit entries.
+type auditLogStore struct {
+	entries []*model.AuditLog
+}
+
+func (r *auditLogStore) Create(ctx context.Context, entry *model.AuditLog) error {
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+func TestExportFailAbandoned(t *testing.T) {
+	store := &exportJobStore{stale: []model.DataExport{
+		{ID: "export-1", ClinicID: "clinic-1", PatientID: "patient-1", RequestedBy: "user-1", Status: model.ExportStatusFailed},
+	}}
+	audit := &auditLogStore{}
+	s := &exportService{repo: store, auditRepo: audit}
+
+	start := time.Now()
+	s.failAbandoned(context.Background())
+
+	// A job is only abandoned once it has outlived the build timeout
+	if limit := start.Add(-exportTimeout); !store.staleBefore.Before(limit) {
+		t.Errorf("stale cutoff %v is not before the build timeout %v", store.staleBefore, limit)
+	}
+	if len(audit.entries) != 1 || audit.entries[0].Action != "export.fail" || audit.entries[0].ResourceID != "export-1" {
+		t.Errorf("audit entries = %+v; want one export.fail for export-1", audit.entries)
+	}
+}
+
+func TestExportClaimJobsRespectsSlots(t *testing.T) {
+	store := &exportJobStore{}
+	s := &exportService{repo: store, slots: make(chan struct{}, 1)}
+
+	// Every slot busy: nothing is claimed
+	s.slots <- struct{}{}
+	s.claimJobs(context.Background())
+	if store.claims != 0 {
+		t.Errorf("claimed %d jobs with no free slot; want 0", store.claims)
+	}
+
+	// A free slot but no pending job: the slot is given back
+	<-s.slots
+	s.claimJobs(context.Background())
+	if store.claims != 1 {
+		t.Errorf("claimed %d times; want 1", store.claims)
+	}
+	if len(s.slots) != 0 {
+		t.Errorf("%d slots still held after finding no job", len(s.slots))
+	}
+}
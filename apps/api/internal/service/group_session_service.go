@@ -0,0 +1,416 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/repository"
+)
+
+// defaultGroupSessionDays is how far ahead group sessions are listed when no
+// range is given.
+const defaultGroupSessionDays = 14
+
+// GroupSessionService defines the interface for group classes and their rosters.
+type GroupSessionService interface {
+	List(ctx context.Context, params model.GroupSessionSearchParams) ([]model.GroupSession, error)
+	Get(ctx context.Context, clinicID, id string) (*model.GroupSessionWithRoster, error)
+	Create(ctx context.Context, clinicID, userID string, req *model.CreateGroupSessionRequest) (*model.GroupSessionWithRoster, error)
+	Update(ctx context.Context, clinicID, id, userID string, req *model.UpdateGroupSessionRequest) (*model.GroupSessionWithRoster, error)
+	Cancel(ctx context.Context, clinicID, id, userID string, req *model.CancelGroupSessionRequest) error
+	AddParticipant(ctx context.Context, clinicID, id, userID string, req *model.AddParticipantRequest) (*model.GroupParticipant, error)
+	UpdateParticipant(ctx context.Context, clinicID, id, participantID, userID string, req *model.UpdateParticipantRequest) (*model.GroupParticipant, error)
+}
+
+// groupSessionService implements GroupSessionService.
+type groupSessionService struct {
+	repo            repository.GroupSessionRepository
+	typeRepo        repository.AppointmentTypeRepository
+	appointmentRepo repository.AppointmentRepository
+	resourceRepo    repository.ResourceRepository
+	clinicRepo      repository.ClinicRepository
+	events          EventPublisher
+}
+
+// NewGroupSessionService creates a new group session service.
+func NewGroupSessionService(repo repository.GroupSessionRepository, typeRepo repository.AppointmentTypeRepository, appointmentRepo repository.AppointmentRepository, resourceRepo repository.ResourceRepository, clinicRepo repository.ClinicRepository, events EventPublisher) GroupSessionService {
+	return &groupSessionService{
+		repo:            repo,
+		typeRepo:        typeRepo,
+		appointmentRepo: appointmentRepo,
+		resourceRepo:    resourceRepo,
+		clinicRepo:      clinicRepo,
+		events:          events,
+	}
+}
+
+// List returns a clinic's group sessions, by default those of the next two
+// weeks. From and To are calendar dates at the clinic.
+func (s *groupSessionService) List(ctx context.Context, params model.GroupSessionSearchParams) ([]model.GroupSession, error) {
+	loc, err := clinicLocation(ctx, s.clinicRepo, params.ClinicID)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.From.IsZero() {
+		params.From = todayIn(loc)
+	} else {
+		params.From = dayIn(params.From, loc)
+	}
+	if params.To.IsZero() {
+		params.To = params.From.AddDate(0, 0, defaultGroupSessionDays)
+	} else {
+		params.To = dayIn(params.To, loc)
+	}
+	if !params.From.Before(params.To) {
+		return nil, fmt.Errorf("%w: from must be before to", repository.ErrInvalidInput)
+	}
+
+	sessions, err := s.repo.List(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	for i := range sessions {
+		sessions[i].StartTime = sessions[i].StartTime.In(loc)
+		sessions[i].EndTime = sessions[i].EndTime.In(loc)
+	}
+	return sessions, nil
+}
+
+// Get returns a group session with its roster.
+func (s *groupSessionService) Get(ctx context.Context, clinicID, id string) (*model.GroupSessionWithRoster, error) {
+	session, err := s.repo.GetByID(ctx, clinicID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	participants, err := s.repo.ListParticipants(ctx, session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.GroupSessionWithRoster{GroupSession: *session, Participants: participants}, nil
+}
+
+// Create schedules a group session. Duration and capacity default to those of
+// the appointment type, which must allow groups and caps the capacity.
+func (s *groupSessionService) Create(ctx context.Context, clinicID, userID string, req *model.CreateGroupSessionRequest) (*model.GroupSessionWithRoster, error) {
+	startTime, err := time.Parse(time.RFC3339, req.StartTime)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid start_time format", repository.ErrInvalidInput)
+	}
+
+	session := &model.GroupSession{
+		ID:          uuid.New().String(),
+		ClinicID:    clinicID,
+		TherapistID: req.TherapistID,
+		Type:        model.AppointmentType(req.Type),
+		Title:       req.Title,
+		StartTime:   startTime,
+		Duration:    req.Duration,
+		Capacity:    req.Capacity,
+		ResourceIDs: uniqueIDs(req.ResourceIDs),
+		Status:      model.GroupSessionStatusScheduled,
+		Notes:       req.Notes,
+		CreatedBy:   &userID,
+		UpdatedBy:   &userID,
+	}
+
+	apptType, err := s.groupType(ctx, clinicID, req.AppointmentTypeID, req.Type)
+	if err != nil {
+		return nil, err
+	}
+	session.Type = model.AppointmentType(apptType.Code)
+	if session.Duration == 0 {
+		session.Duration = apptType.DefaultDuration
+	}
+	if apptType.ID != "" {
+		session.AppointmentTypeID = &apptType.ID
+		if session.Capacity == 0 {
+			session.Capacity = apptType.MaxGroupSize
+		}
+		if session.Capacity > apptType.MaxGroupSize {
+			return nil, fmt.Errorf("%w: capacity exceeds the maximum group size of %d for %s", repository.ErrInvalidInput, apptType.MaxGroupSize, apptType.Name)
+		}
+	}
+	if session.Capacity < 2 {
+		return nil, fmt.Errorf("%w: a group session needs a capacity of at least 2", repository.ErrInvalidInput)
+	}
+	session.EndTime = startTime.Add(time.Duration(session.Duration) * time.Minute)
+
+	session.Room, err = defaultRoom(ctx, s.resourceRepo, clinicID, req.Room, session.ResourceIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkTherapistConflicts(ctx, session); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, session); err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("group_session_id", session.ID).
+		Str("therapist_id", session.TherapistID).
+		Time("start_time", session.StartTime).
+		Int("capacity", session.Capacity).
+		Str("clinic_id", clinicID).
+		Str("created_by", userID).
+		Msg("group session created")
+
+	s.publish(ctx, clinicID, session.ID, userID)
+
+	return s.Get(ctx, clinicID, session.ID)
+}
+
+// Update changes a group session. Moving it moves the appointments of its
+// booked participants; a session that has started can no longer be moved.
+func (s *groupSessionService) Update(ctx context.Context, clinicID, id, userID string, req *model.UpdateGroupSessionRequest) (*model.GroupSessionWithRoster, error) {
+	session, err := s.repo.GetByID(ctx, clinicID, id)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status == model.GroupSessionStatusCancelled {
+		return nil, fmt.Errorf("%w: group session is cancelled", repository.ErrInvalidInput)
+	}
+
+	moved := req.TherapistID != nil || req.StartTime != nil || req.Duration != nil
+	if moved && !session.StartTime.After(time.Now()) {
+		return nil, fmt.Errorf("%w: cannot move a group session that has started", repository.ErrInvalidInput)
+	}
+
+	if req.TherapistID != nil {
+		session.TherapistID = *req.TherapistID
+	}
+	if req.StartTime != nil {
+		startTime, err := time.Parse(time.RFC3339, *req.StartTime)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid start_time format", repository.ErrInvalidInput)
+		}
+		session.StartTime = startTime
+	}
+	if req.Duration != nil {
+		session.Duration = *req.Duration
+	}
+	session.EndTime = session.StartTime.Add(time.Duration(session.Duration) * time.Minute)
+	if req.Title != nil {
+		session.Title = *req.Title
+	}
+	if req.Room != nil {
+		session.Room = *req.Room
+	}
+	if req.Notes != nil {
+		session.Notes = *req.Notes
+	}
+	if req.ResourceIDs != nil {
+		session.ResourceIDs = uniqueIDs(req.ResourceIDs)
+	}
+	if req.Capacity != nil {
+		session.Capacity = *req.Capacity
+		if session.AppointmentTypeID != nil {
+			apptType, err := s.groupType(ctx, clinicID, *session.AppointmentTypeID, "")
+			if err != nil {
+				return nil, err
+			}
+			if session.Capacity > apptType.MaxGroupSize {
+				return nil, fmt.Errorf("%w: capacity exceeds the maximum group size of %d for %s", repository.ErrInvalidInput, apptType.MaxGroupSize, apptType.Name)
+			}
+		}
+	}
+	session.UpdatedBy = staffActor(userID)
+
+	if moved {
+		if err := s.checkTherapistConflicts(ctx, session); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.Update(ctx, session); err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("group_session_id", id).
+		Str("clinic_id", clinicID).
+		Str("updated_by", userID).
+		Msg("group session updated")
+
+	s.publish(ctx, clinicID, id, userID)
+
+	return s.Get(ctx, clinicID, id)
+}
+
+// Cancel cancels a group session together with its participants' appointments.
+func (s *groupSessionService) Cancel(ctx context.Context, clinicID, id, userID string, req *model.CancelGroupSessionRequest) error {
+	session, err := s.repo.GetByID(ctx, clinicID, id)
+	if err != nil {
+		return err
+	}
+	if session.Status == model.GroupSessionStatusCancelled {
+		return fmt.Errorf("%w: group session is already cancelled", repository.ErrInvalidInput)
+	}
+
+	session.CancellationReason = req.Reason
+	session.UpdatedBy = staffActor(userID)
+	if err := s.repo.Cancel(ctx, session); err != nil {
+		return err
+	}
+
+	log.Info().
+		Str("group_session_id", id).
+		Str("clinic_id", clinicID).
+		Str("cancelled_by", userID).
+		Str("reason", req.Reason).
+		Int("booked", session.BookedCount).
+		Msg("group session cancelled")
+
+	s.publish(ctx, clinicID, id, userID)
+
+	return nil
+}
+
+// AddParticipant books a patient into a group session, or puts them on its
+// waitlist when it is full.
+func (s *groupSessionService) AddParticipant(ctx context.Context, clinicID, id, userID string, req *model.AddParticipantRequest) (*model.GroupParticipant, error) {
+	session, err := s.repo.GetByID(ctx, clinicID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !session.EndTime.After(time.Now()) {
+		return nil, fmt.Errorf("%w: group session has ended", repository.ErrInvalidInput)
+	}
+	if err := s.checkParticipantEvaluation(ctx, session, req.PatientID); err != nil {
+		return nil, err
+	}
+
+	participant := &model.GroupParticipant{
+		ID:        uuid.New().String(),
+		PatientID: req.PatientID,
+		Notes:     req.Notes,
+		CreatedBy: &userID,
+		UpdatedBy: &userID,
+	}
+	if err := s.repo.AddParticipant(ctx, session, participant, !req.DeclineWaitlist); err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("group_session_id", id).
+		Str("participant_id", participant.ID).
+		Str("patient_id", req.PatientID).
+		Str("status", string(participant.Status)).
+		Str("clinic_id", clinicID).
+		Str("added_by", userID).
+		Msg("group session participant added")
+
+	s.publish(ctx, clinicID, id, userID)
+
+	return s.repo.GetParticipant(ctx, session.ID, participant.ID)
+}
+
+// UpdateParticipant records a participant's attendance, removes them from the
+// session or changes their notes. Attendance can be recorded once the session
+// has started.
+func (s *groupSessionService) UpdateParticipant(ctx context.Context, clinicID, id, participantID, userID string, req *model.UpdateParticipantRequest) (*model.GroupParticipant, error) {
+	session, err := s.repo.GetByID(ctx, clinicID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	participant, err := s.repo.GetParticipant(ctx, session.ID, participantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Status != nil {
+		status := model.ParticipantStatus(*req.Status)
+		if (status == model.ParticipantStatusAttended || status == model.ParticipantStatusNoShow) && session.StartTime.After(time.Now()) {
+			return nil, fmt.Errorf("%w: attendance can be recorded once the session has started", repository.ErrInvalidInput)
+		}
+		participant.Status = status
+	}
+	if req.Notes != nil {
+		participant.Notes = *req.Notes
+	}
+	participant.UpdatedBy = staffActor(userID)
+
+	if err := s.repo.UpdateParticipant(ctx, session, participant); err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("group_session_id", id).
+		Str("participant_id", participantID).
+		Str("status", string(participant.Status)).
+		Str("clinic_id", clinicID).
+		Str("updated_by", userID).
+		Msg("group session participant updated")
+
+	s.publish(ctx, clinicID, id, userID)
+
+	return s.repo.GetParticipant(ctx, session.ID, participantID)
+}
+
+// publish tells the clinic's status board that a session or its roster changed.
+func (s *groupSessionService) publish(ctx context.Context, clinicID, id, userID string) {
+	s.events.Publish(ctx, model.ClinicEvent{
+		ClinicID:       clinicID,
+		Type:           model.EventGroupSessionChanged,
+		GroupSessionID: id,
+		ActorID:        userID,
+	})
+}
+
+// groupType resolves the appointment type of a group session. A type the
+// clinic has configured must allow groups; the built-in types used by clinics
+// without configured types leave the group size to the request.
+func (s *groupSessionService) groupType(ctx context.Context, clinicID, id, code string) (*model.AppointmentTypeConfig, error) {
+	apptType, err := resolveAppointmentType(ctx, s.typeRepo, clinicID, id, code)
+	if err != nil {
+		return nil, err
+	}
+	if apptType.ID != "" && !apptType.AllowsGroup {
+		return nil, fmt.Errorf("%w: appointment type %s does not allow group sessions", repository.ErrInvalidInput, apptType.Name)
+	}
+	return apptType, nil
+}
+
+// checkParticipantEvaluation enforces the evaluation requirement of a
+// session's appointment type for a patient joining it.
+func (s *groupSessionService) checkParticipantEvaluation(ctx context.Context, session *model.GroupSession, patientID string) error {
+	var apptType *model.AppointmentTypeConfig
+	var err error
+	if session.AppointmentTypeID != nil {
+		apptType, err = s.typeRepo.GetByID(ctx, session.ClinicID, *session.AppointmentTypeID)
+	} else {
+		apptType, err = s.typeRepo.GetByCode(ctx, session.ClinicID, string(session.Type))
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return checkEvaluation(ctx, s.appointmentRepo, session.ClinicID, patientID, apptType)
+}
+
+// checkTherapistConflicts rejects a session that overlaps the therapist's
+// other appointments or group sessions.
+func (s *groupSessionService) checkTherapistConflicts(ctx context.Context, session *model.GroupSession) error {
+	conflicts, err := s.appointmentRepo.FindConflicts(ctx, session.ClinicID, session.TherapistID, session.StartTime, session.EndTime, session.ID)
+	if err != nil {
+		return fmt.Errorf("failed to check conflicts: %w", err)
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("%w: %s", repository.ErrConflict, overlapConflict(conflicts[0]).Message)
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/repository"
+)
+
+// groupSessionStore holds one session and records what is written to it.
+type groupSessionStore struct {
+	repository.GroupSessionRepository
+	session       model.GroupSession
+	updates       []model.GroupSession
+	allowWaitlist []bool
+}
+
+func (r *groupSessionStore) GetByID(ctx context.Context, clinicID, id string) (*model.GroupSession, error) {
+	if clinicID != r.session.ClinicID || id != r.session.ID {
+		return nil, repository.ErrNotFound
+	}
+	gs := r.session
+	return &gs, nil
+}
+
+func (r *groupSessionStore) ListParticipants(ctx context.Context, sessionID string) ([]model.GroupParticipant, error) {
+	return nil, nil
+}
+
+func (r *groupSessionStore) GetParticipant(ctx context.Context, sessionID, id string) (*model.GroupParticipant, error) {
+	return &model.GroupParticipant{ID: id, SessionID: sessionID}, nil
+}
+
+func (r *groupSessionStore) Update(ctx context.Context, session *model.GroupSession) error {
+	r.updates = append(r.updates, *session)
+	r.session = *session
+	return nil
+}
+
+func (r *groupSessionStore) AddParticipant(ctx context.Context, session *model.GroupSession, participant *model.GroupParticipant, allowWaitlist bool) error {
+	r.allowWaitlist = append(r.allowWaitlist, allowWaitlist)
+	return nil
+}
+
+// freeCalendar reports no therapist conflicts.
+type freeCalendar struct {
+	repository.AppointmentRepository
+}
+
+func (r *freeCalendar) FindConflicts(ctx context.Context, clinicID, therapistID string, start, end time.Time, excludeID string) ([]model.Appointment, error) {
+	return nil, nil
+}
+
+// noAppointmentTypes is a clinic without configured appointment types.
+type noAppointmentTypes struct {
+	repository.AppointmentTypeRepository
+}
+
+func (r *noAppointmentTypes) GetByCode(ctx context.Context, clinicID, code string) (*model.AppointmentTypeConfig, error) {
+	return nil, repository.ErrNotFound
+}
+
+// discardEvents drops clinic events.
+type discardEvents struct{}
+
+func (discardEvents) Publish(ctx context.Context, event model.ClinicEvent) {}
+
+func newGroupSessionTestService(session model.GroupSession) (*groupSessionService, *groupSessionStore) {
+	store := &groupSessionStore{session: session}
+	return &groupSessionService{repo: store, typeRepo: &noAppointmentTypes{}, appointmentRepo: &freeCalendar{}, events: discardEvents{}}, store
+}
+
+func TestUpdateGroupSessionMovesAppointments(t *testing.T) {
+	start := time.Now().Add(48 * time.Hour).Truncate(time.Minute).UTC()
+	s, store := newGroupSessionTestService(model.GroupSession{
+		ID: "gs-1", ClinicID: "clinic-1", TherapistID: "therapist-1", Title: "Balance class",
+		StartTime: start, EndTime: start.Add(time.Hour), Duration: 60, Capacity: 6,
+		Status: model.GroupSessionStatusScheduled,
+	})
+
+	newStart := start.Add(24 * time.Hour).Format(time.RFC3339)
+	duration := 45
+	therapist := "therapist-2"
+	if _, err := s.Update(context.Background(), "clinic-1", "gs-1", "user-1", &model.UpdateGroupSessionRequest{
+		StartTime: &newStart, Duration: &duration, TherapistID: &therapist,
+	}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if len(store.updates) != 1 {
+		t.Fatalf("saved %d updates; want 1", len(store.updates))
+	}
+
+	// The repository moves every open participant appointment to the
+	// session's participant appointment
+	moved := store.updates[0].ParticipantAppointment("appt-1", "patient-1", nil)
+	wantStart := start.Add(24 * time.Hour)
+	if !moved.StartTime.Equal(wantStart) || !moved.EndTime.Equal(wantStart.Add(45*time.Minute)) {
+		t.Errorf("appointment moved to %v-%v; want %v-%v", moved.StartTime, moved.EndTime, wantStart, wantStart.Add(45*time.Minute))
+	}
+	if moved.Duration != 45 || moved.TherapistID != "therapist-2" || moved.Notes != "Balance class" {
+		t.Errorf("appointment = duration %d, therapist %q, notes %q", moved.Duration, moved.TherapistID, moved.Notes)
+	}
+}
+
+func TestUpdateStartedGroupSession(t *testing.T) {
+	start := time.Now().Add(-10 * time.Minute)
+	session := model.GroupSession{
+		ID: "gs-1", ClinicID: "clinic-1", TherapistID: "therapist-1",
+		StartTime: start, EndTime: start.Add(time.Hour), Duration: 60, Capacity: 4, BookedCount: 4,
+		Status: model.GroupSessionStatusScheduled,
+	}
+
+	// A session that has started keeps its time
+	s, store := newGroupSessionTestService(session)
+	later := time.Now().Add(time.Hour).Format(time.RFC3339)
+	if _, err := s.Update(context.Background(), "clinic-1", "gs-1", "user-1", &model.UpdateGroupSessionRequest{StartTime: &later}); !errors.Is(err, repository.ErrInvalidInput) {
+		t.Errorf("moving a started session err = %v; want ErrInvalidInput", err)
+	}
+	if len(store.updates) != 0 {
+		t.Errorf("saved %d updates for a rejected move", len(store.updates))
+	}
+
+	// but can still take more patients
+	capacity := 6
+	if _, err := s.Update(context.Background(), "clinic-1", "gs-1", "user-1", &model.UpdateGroupSessionRequest{Capacity: &capacity}); err != nil {
+		t.Fatalf("raising capacity: %v", err)
+	}
+	if len(store.updates) != 1 || store.updates[0].Capacity != 6 || !store.updates[0].StartTime.Equal(start) {
+		t.Errorf("updates = %+v; want capacity 6 at the original time", store.updates)
+	}
+}
+
+func TestAddParticipantWaitlistChoice(t *testing.T) {
+	start := time.Now().Add(24 * time.Hour)
+	s, store := newGroupSessionTestService(model.GroupSession{
+		ID: "gs-1", ClinicID: "clinic-1", Type: model.AppointmentType("group_class"),
+		StartTime: start, EndTime: start.Add(time.Hour), Capacity: 4, BookedCount: 4,
+		Status: model.GroupSessionStatusScheduled,
+	})
+
+	for _, decline := range []bool{false, true} {
+		if _, err := s.AddParticipant(context.Background(), "clinic-1", "gs-1", "user-1", &model.AddParticipantRequest{PatientID: "patient-1", DeclineWaitlist: decline}); err != nil {
+			t.Fatalf("AddParticipant: %v", err)
+		}
+	}
+	if len(store.allowWaitlist) != 2 || !store.allowWaitlist[0] || store.allowWaitlist[1] {
+		t.Errorf("allowWaitlist = %v; want [true false]", store.allowWaitlist)
+	}
+}
@@ -0,0 +1,357 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/repository"
+)
+
+// Self check-in settings
+const (
+	kioskTokenBytes       = 32
+	kioskTouchInterval    = time.Minute      // how often a device's last seen time is updated
+	checkInOpensBefore    = 2 * time.Hour    // how long before the start a patient may check in
+	checkInClosesAfter    = 30 * time.Minute // how long after the start a late patient may still check in
+	checkInSignatureBytes = 16               // truncated HMAC, keeping QR codes small
+	checkInSecretBytes    = 32
+)
+
+// ErrInvalidCheckInToken is returned for check-in codes that are malformed,
+// were signed for another clinic, or have expired.
+var ErrInvalidCheckInToken = errors.New("invalid or expired check-in code")
+
+// KioskService defines the interface for kiosk devices and patient self
+// check-in with signed QR codes.
+type KioskService interface {
+	ListDevices(ctx context.Context, clinicID string) ([]model.KioskDevice, error)
+	RegisterDevice(ctx context.Context, clinicID, createdBy string, req *model.RegisterKioskRequest) (*model.KioskDevice, error)
+	RevokeDevice(ctx context.Context, clinicID, id, revokedBy string) error
+	AuthenticateKiosk(ctx context.Context, token string) (*model.KioskDevice, error)
+	IssueCheckInToken(ctx context.Context, clinicID, appointmentID string) (*model.CheckInToken, error)
+	Lookup(ctx context.Context, device *model.KioskDevice, token string) (*model.KioskCheckIn, error)
+	CheckIn(ctx context.Context, device *model.KioskDevice, req *model.KioskCheckInRequest) (*model.KioskCheckIn, error)
+}
+
+// kioskService implements KioskService.
+type kioskService struct {
+	repo            repository.KioskRepository
+	appointmentRepo repository.AppointmentRepository
+	patientRepo     repository.PatientRepository
+	clinicRepo      repository.ClinicRepository
+	statusBoardRepo repository.StatusBoardRepository
+	statusBoard     StatusBoardService
+	secret          []byte
+}
+
+// NewKioskService creates a new kiosk service. Check-in codes are signed with
+// secret; without one a random secret is used, so codes stop working when the
+// process restarts and are not accepted by other instances.
+func NewKioskService(repo repository.KioskRepository, appointmentRepo repository.AppointmentRepository, patientRepo repository.PatientRepository, clinicRepo repository.ClinicRepository, statusBoardRepo repository.StatusBoardRepository, statusBoard StatusBoardService, secret string) KioskService {
+	key := []byte(secret)
+	if secret == "" {
+		log.Warn().Msg("CHECKIN_TOKEN_SECRET is not set, check-in codes are only valid until restart")
+		key = make([]byte, checkInSecretBytes)
+		if _, err := rand.Read(key); err != nil {
+			log.Fatal().Err(err).Msg("failed to generate check-in secret")
+		}
+	}
+
+	return &kioskService{
+		repo:            repo,
+		appointmentRepo: appointmentRepo,
+		patientRepo:     patientRepo,
+		clinicRepo:      clinicRepo,
+		statusBoardRepo: statusBoardRepo,
+		statusBoard:     statusBoard,
+		secret:          key,
+	}
+}
+
+// ListDevices returns a clinic's kiosks, including revoked ones.
+func (s *kioskService) ListDevices(ctx context.Context, clinicID string) ([]model.KioskDevice, error) {
+	return s.repo.List(ctx, clinicID)
+}
+
+// RegisterDevice creates a kiosk with a new secret token. The returned device
+// carries the token, which is not stored and cannot be retrieved again.
+func (s *kioskService) RegisterDevice(ctx context.Context, clinicID, createdBy string, req *model.RegisterKioskRequest) (*model.KioskDevice, error) {
+	b := make([]byte, kioskTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("failed to generate kiosk token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	device := &model.KioskDevice{
+		ID:        uuid.New().String(),
+		ClinicID:  clinicID,
+		Name:      strings.TrimSpace(req.Name),
+		TokenHash: hashToken(token),
+		CreatedBy: createdBy,
+		Token:     token,
+	}
+	if err := s.repo.Create(ctx, device); err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("kiosk_id", device.ID).
+		Str("name", device.Name).
+		Str("clinic_id", clinicID).
+		Str("created_by", createdBy).
+		Msg("kiosk device registered")
+
+	return device, nil
+}
+
+// RevokeDevice stops a kiosk's token from being accepted.
+func (s *kioskService) RevokeDevice(ctx context.Context, clinicID, id, revokedBy string) error {
+	if err := s.repo.Revoke(ctx, clinicID, id, revokedBy, time.Now()); err != nil {
+		return err
+	}
+
+	log.Info().
+		Str("kiosk_id", id).
+		Str("clinic_id", clinicID).
+		Str("revoked_by", revokedBy).
+		Msg("kiosk device revoked")
+
+	return nil
+}
+
+// AuthenticateKiosk returns the active device holding the token, or nil if
+// there is none.
+func (s *kioskService) AuthenticateKiosk(ctx context.Context, token string) (*model.KioskDevice, error) {
+	if token == "" {
+		return nil, nil
+	}
+	device, err := s.repo.GetByTokenHash(ctx, hashToken(token))
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !device.IsActive() {
+		return nil, nil
+	}
+
+	now := time.Now()
+	if device.LastSeenAt == nil || now.Sub(*device.LastSeenAt) > kioskTouchInterval {
+		if err := s.repo.Touch(ctx, device.ID, now); err != nil {
+			log.Warn().Err(err).Str("kiosk_id", device.ID).Msg("failed to record kiosk activity")
+		}
+	}
+	return device, nil
+}
+
+// IssueCheckInToken signs a check-in code for an open appointment. The code
+// is only accepted during the appointment's check-in window.
+func (s *kioskService) IssueCheckInToken(ctx context.Context, clinicID, appointmentID string) (*model.CheckInToken, error) {
+	appointment, err := s.appointmentRepo.GetByID(ctx, clinicID, appointmentID)
+	if err != nil {
+		return nil, err
+	}
+	if !isOpenAppointment(appointment.Status) {
+		return nil, fmt.Errorf("%w: cannot check in to a %s appointment", repository.ErrInvalidInput, appointment.Status)
+	}
+
+	opens, closes := checkInWindow(appointment.StartTime, appointment.EndTime)
+	return &model.CheckInToken{
+		AppointmentID: appointment.ID,
+		Token:         s.signCheckIn(clinicID, appointment.ID, closes),
+		OpensAt:       opens,
+		ExpiresAt:     closes,
+	}, nil
+}
+
+// checkInWindow returns when self check-in opens and closes for an
+// appointment: from checkInOpensBefore the start until checkInClosesAfter the
+// start, or the end of the appointment if that is sooner. Patients arriving
+// later check in at the front desk.
+func checkInWindow(start, end time.Time) (time.Time, time.Time) {
+	closes := start.Add(checkInClosesAfter)
+	if end.Before(closes) {
+		closes = end
+	}
+	return start.Add(-checkInOpensBefore), closes
+}
+
+// Lookup returns the appointment and details a patient reviews at the kiosk
+// before checking in.
+func (s *kioskService) Lookup(ctx context.Context, device *model.KioskDevice, token string) (*model.KioskCheckIn, error) {
+	review, _, err := s.review(ctx, device, token)
+	return review, err
+}
+
+// CheckIn checks the patient in with the confirmations they gave at the
+// kiosk. Unconfirmed details and notes are flagged for the front desk on the
+// status board.
+func (s *kioskService) CheckIn(ctx context.Context, device *model.KioskDevice, req *model.KioskCheckInRequest) (*model.KioskCheckIn, error) {
+	review, appointment, err := s.review(ctx, device, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := s.statusBoard.CheckIn(ctx, &model.AppointmentCheckIn{
+		AppointmentID:         appointment.ID,
+		ClinicID:              device.ClinicID,
+		Method:                model.CheckInKiosk,
+		KioskID:               &device.ID,
+		DemographicsConfirmed: &req.DemographicsConfirmed,
+		InsuranceConfirmed:    &req.InsuranceConfirmed,
+		PatientNotes:          strings.TrimSpace(req.Notes),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	review.CheckedInAt = entry.CheckedInAt
+	return review, nil
+}
+
+// review verifies a check-in code at the kiosk's clinic and loads what the
+// patient reviews.
+func (s *kioskService) review(ctx context.Context, device *model.KioskDevice, token string) (*model.KioskCheckIn, *model.AppointmentWithDetails, error) {
+	appointmentID, err := s.verifyCheckIn(device.ClinicID, token, time.Now())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	appointment, err := s.appointmentRepo.GetByID(ctx, device.ClinicID, appointmentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !isOpenAppointment(appointment.Status) {
+		return nil, nil, fmt.Errorf("%w: this appointment is %s, please see the front desk", repository.ErrInvalidInput, appointment.Status)
+	}
+
+	loc, err := clinicLocation(ctx, s.clinicRepo, device.ClinicID)
+	if err != nil {
+		return nil, nil, err
+	}
+	// The appointment may have moved since the code was issued
+	opens, closes := checkInWindow(appointment.StartTime, appointment.EndTime)
+	now := time.Now()
+	if now.Before(opens) {
+		return nil, nil, fmt.Errorf("%w: check-in opens at %s", repository.ErrInvalidInput, opens.In(loc).Format("15:04 02/01/2006"))
+	}
+	if now.After(closes) {
+		return nil, nil, fmt.Errorf("%w: self check-in closed at %s, please see the front desk", repository.ErrInvalidInput, closes.In(loc).Format("15:04 02/01/2006"))
+	}
+
+	patient, err := s.patientRepo.GetByID(ctx, device.ClinicID, appointment.PatientID)
+	if err != nil {
+		return nil, nil, err
+	}
+	policies, err := s.patientRepo.GetInsuranceInfo(ctx, patient.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	review := &model.KioskCheckIn{
+		AppointmentID: appointment.ID,
+		StartTime:     appointment.StartTime.In(loc),
+		EndTime:       appointment.EndTime.In(loc),
+		Type:          appointment.Type,
+		TherapistName: appointment.TherapistName,
+		Room:          appointment.Room,
+		Patient: model.KioskPatient{
+			FirstName:   patient.FirstName,
+			LastName:    patient.LastName,
+			FirstNameVi: patient.FirstNameVi,
+			LastNameVi:  patient.LastNameVi,
+			DateOfBirth: patient.DateOfBirth,
+			Phone:       patient.Phone,
+			Email:       patient.Email,
+			Address:     patient.Address,
+			AddressVi:   patient.AddressVi,
+		},
+		Insurance: kioskInsurance(policies, dayIn(appointment.StartTime.In(loc), time.UTC)),
+	}
+
+	entry, err := s.statusBoardRepo.GetEntry(ctx, device.ClinicID, appointment.ID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, nil, err
+	}
+	if entry != nil {
+		review.CheckedInAt = entry.CheckedInAt
+	}
+
+	return review, appointment, nil
+}
+
+// kioskInsurance lists a patient's policies for review, masking policy numbers
+// and judging validity on the appointment's date.
+func kioskInsurance(policies []model.PatientInsurance, day time.Time) []model.KioskInsurance {
+	result := make([]model.KioskInsurance, 0, len(policies))
+	for _, p := range policies {
+		validFrom := dayIn(p.ValidFrom, time.UTC)
+		valid := p.IsActive && !day.Before(validFrom)
+		if p.ValidTo != nil && day.After(dayIn(*p.ValidTo, time.UTC)) {
+			valid = false
+		}
+
+		result = append(result, model.KioskInsurance{
+			Provider:     p.Provider,
+			PolicyNumber: maskPolicyNumber(p.PolicyNumber),
+			ValidTo:      p.ValidTo,
+			IsPrimary:    p.IsPrimary,
+			Valid:        valid,
+		})
+	}
+	return result
+}
+
+// maskPolicyNumber keeps the last four characters of a policy number.
+func maskPolicyNumber(number string) string {
+	r := []rune(number)
+	if len(r) <= 4 {
+		return strings.Repeat("*", len(r))
+	}
+	return strings.Repeat("*", len(r)-4) + string(r[len(r)-4:])
+}
+
+// signCheckIn returns a check-in code for an appointment that expires at
+// expires. The clinic is part of the signature, so a code is only accepted
+// by the clinic's own kiosks.
+func (s *kioskService) signCheckIn(clinicID, appointmentID string, expires time.Time) string {
+	payload := appointmentID + "." + strconv.FormatInt(expires.Unix(), 36)
+	return payload + "." + s.checkInSignature(clinicID, payload)
+}
+
+// verifyCheckIn returns the appointment a check-in code was issued for.
+func (s *kioskService) verifyCheckIn(clinicID, token string, now time.Time) (string, error) {
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 3 {
+		return "", ErrInvalidCheckInToken
+	}
+
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(s.checkInSignature(clinicID, payload))) {
+		return "", ErrInvalidCheckInToken
+	}
+	expires, err := strconv.ParseInt(parts[1], 36, 64)
+	if err != nil || now.Unix() > expires {
+		return "", ErrInvalidCheckInToken
+	}
+	return parts[0], nil
+}
+
+func (s *kioskService) checkInSignature(clinicID, payload string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte("check-in:" + clinicID + ":" + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:checkInSignatureBytes])
+}
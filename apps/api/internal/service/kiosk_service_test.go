@@ -0,0 +1,48 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCheckInWindow(t *testing.T) {
+	start := time.Date(2026, 3, 20, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		end        time.Time
+		wantCloses time.Time
+	}{
+		{"long appointment closes after the grace period", start.Add(time.Hour), start.Add(checkInClosesAfter)},
+		{"short appointment closes at its end", start.Add(15 * time.Minute), start.Add(15 * time.Minute)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opens, closes := checkInWindow(start, tt.end)
+			if want := start.Add(-checkInOpensBefore); !opens.Equal(want) {
+				t.Errorf("opens = %v, want %v", opens, want)
+			}
+			if !closes.Equal(tt.wantCloses) {
+				t.Errorf("closes = %v, want %v", closes, tt.wantCloses)
+			}
+		})
+	}
+}
+
+func TestCheckInTokenExpiry(t *testing.T) {
+	s := &kioskService{secret: []byte("test-secret")}
+	closes := time.Date(2026, 3, 20, 9, 30, 0, 0, time.UTC)
+	token := s.signCheckIn("clinic-1", "appt-1", closes)
+
+	if id, err := s.verifyCheckIn("clinic-1", token, closes.Add(-time.Minute)); err != nil || id != "appt-1" {
+		t.Errorf("verifyCheckIn inside the window = %q, %v", id, err)
+	}
+	if _, err := s.verifyCheckIn("clinic-1", token, closes.Add(time.Second)); !errors.Is(err, ErrInvalidCheckInToken) {
+		t.Errorf("verifyCheckIn after the window err = %v, want ErrInvalidCheckInToken", err)
+	}
+	if _, err := s.verifyCheckIn("clinic-2", token, closes.Add(-time.Minute)); !errors.Is(err, ErrInvalidCheckInToken) {
+		t.Errorf("verifyCheckIn at another clinic err = %v, want ErrInvalidCheckInToken", err)
+	}
+}
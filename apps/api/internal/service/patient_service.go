@@ -31,6 +31,7 @@ type PatientService interface {
 type patientService struct {
 	repo       repository.PatientRepository
 	clinicRepo ClinicRepository
+	attendance AttendanceService
 }
 
 // ClinicRepository defines the minimal interface for clinic data access.
@@ -39,10 +40,11 @@ type ClinicRepository interface {
 }
 
 // NewPatientService creates a new patient service.
-func NewPatientService(repo repository.PatientRepository, clinicRepo ClinicRepository) PatientService {
+func NewPatientService(repo repository.PatientRepository, clinicRepo ClinicRepository, attendance AttendanceService) PatientService {
 	return &patientService{
 		repo:       repo,
 		clinicRepo: clinicRepo,
+		attendance: attendance,
 	}
 }
 
@@ -253,7 +255,22 @@ func (s *patientService) Search(ctx context.Context, clinicID, query string, lim
 
 // GetDashboard retrieves aggregated patient dashboard data.
 func (s *patientService) GetDashboard(ctx context.Context, clinicID, patientID string) (*model.PatientDashboard, error) {
-	return s.repo.GetDashboard(ctx, clinicID, patientID)
+	dashboard, err := s.repo.GetDashboard(ctx, clinicID, patientID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range dashboard.RecentNotes {
+		dashboard.RecentNotes[i].Summary = summarizeNote(dashboard.RecentNotes[i].Summary)
+	}
+
+	attendance, err := s.attendance.GetPatientAttendance(ctx, clinicID, patientID)
+	if err != nil {
+		return nil, err
+	}
+	dashboard.Attendance = attendance
+
+	return dashboard, nil
 }
 
 // CheckDuplicates finds potential duplicate patients.
@@ -0,0 +1,541 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/repository"
+)
+
+// Patient portal errors
+var (
+	ErrPatientNotLinked = errors.New("no patient record is linked to this account")
+	ErrPolicyViolation  = errors.New("not permitted by clinic policy")
+)
+
+// PortalService defines the interface for patient self-service operations.
+// Every method acts only on the patient the actor is entitled to: the record
+// linked to their own account, or the patient named in their proxy grant.
+type PortalService interface {
+	GetPatient(ctx context.Context, actor model.PortalActor) (*model.Patient, error)
+	ListAppointments(ctx context.Context, actor model.PortalActor, upcomingOnly bool) ([]model.AppointmentWithDetails, error)
+	CancelAppointment(ctx context.Context, actor model.PortalActor, appointmentID string, req *model.PortalCancelRequest) error
+	RescheduleAppointment(ctx context.Context, actor model.PortalActor, appointmentID string, req *model.PortalRescheduleRequest) (*model.AppointmentWithDetails, error)
+	GetExercisePlan(ctx context.Context, actor model.PortalActor) (*model.PortalExercisePlan, error)
+	LogCompliance(ctx context.Context, actor model.PortalActor, prescriptionID string, req *model.LogComplianceRequest) (*model.ExerciseComplianceLog, error)
+	GetProgress(ctx context.Context, actor model.PortalActor, joint string, limit int) (*model.PortalProgress, error)
+	ListProxyGrants(ctx context.Context, actor model.PortalActor) ([]model.ProxyGrant, error)
+	GrantProxyAccess(ctx context.Context, actor model.PortalActor, req *model.CreateProxyGrantRequest) (*model.ProxyGrant, error)
+	RevokeProxyAccess(ctx context.Context, actor model.PortalActor, grantID string) error
+	ListConsentTemplates(ctx context.Context, actor model.PortalActor) ([]model.ConsentTemplate, error)
+	GetConsentSummary(ctx context.Context, actor model.PortalActor) ([]model.ConsentSummary, error)
+	RecordConsent(ctx context.Context, actor model.PortalActor, req *model.RecordConsentRequest) (*model.PatientConsent, error)
+	WithdrawConsent(ctx context.Context, actor model.PortalActor, consentID string, req *model.WithdrawConsentRequest) error
+	RequestExport(ctx context.Context, actor model.PortalActor) (*model.DataExport, error)
+	ListExports(ctx context.Context, actor model.PortalActor) ([]model.DataExport, error)
+	GetExport(ctx context.Context, actor model.PortalActor, exportID string) (*model.DataExport, error)
+	OpenExport(ctx context.Context, actor model.PortalActor, exportID string) (io.ReadCloser, *model.DataExport, error)
+	ListCalendarFeeds(ctx context.Context, actor model.PortalActor) ([]model.CalendarFeed, error)
+	IssueCalendarFeed(ctx context.Context, actor model.PortalActor) (*model.CalendarFeed, error)
+	RevokeCalendarFeed(ctx context.Context, actor model.PortalActor, feedID string) error
+	AppointmentICS(ctx context.Context, actor model.PortalActor, appointmentID string) ([]byte, error)
+	CheckInToken(ctx context.Context, actor model.PortalActor, appointmentID string) (*model.CheckInToken, error)
+}
+
+// portalService implements PortalService.
+type portalService struct {
+	repo         *repository.Repository
+	appointments AppointmentService
+	attendance   AttendanceService
+	exercises    ExerciseService
+	proxies      ProxyService
+	consents     ConsentService
+	exports      ExportService
+	calendar     CalendarService
+	kiosk        KioskService
+}
+
+// NewPortalService creates a new patient portal service.
+func NewPortalService(repo *repository.Repository, appointments AppointmentService, attendance AttendanceService, exercises ExerciseService, proxies ProxyService, consents ConsentService, exports ExportService, calendar CalendarService, kiosk KioskService) PortalService {
+	return &portalService{
+		repo:         repo,
+		appointments: appointments,
+		attendance:   attendance,
+		exercises:    exercises,
+		proxies:      proxies,
+		consents:     consents,
+		exports:      exports,
+		calendar:     calendar,
+		kiosk:        kiosk,
+	}
+}
+
+// portalAppointmentLimit caps how many appointments the portal returns.
+const portalAppointmentLimit = 100
+
+// GetPatient returns the patient the actor is acting for.
+func (s *portalService) GetPatient(ctx context.Context, actor model.PortalActor) (*model.Patient, error) {
+	if actor.IsProxy() {
+		patient, err := s.repo.Patient().GetByID(ctx, actor.Grant.ClinicID, actor.Grant.PatientID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return nil, ErrPatientNotLinked
+			}
+			return nil, fmt.Errorf("failed to resolve patient: %w", err)
+		}
+		return patient, nil
+	}
+
+	patient, err := s.repo.Patient().GetByKeycloakID(ctx, actor.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrPatientNotLinked
+		}
+		return nil, fmt.Errorf("failed to resolve patient: %w", err)
+	}
+	return patient, nil
+}
+
+// ListAppointments returns the patient's appointments, optionally only upcoming ones.
+func (s *portalService) ListAppointments(ctx context.Context, actor model.PortalActor, upcomingOnly bool) ([]model.AppointmentWithDetails, error) {
+	patient, err := s.GetPatient(ctx, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	appointments, err := s.appointments.GetByPatient(ctx, patient.ClinicID, patient.ID, portalAppointmentLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	if !upcomingOnly {
+		return appointments, nil
+	}
+
+	now := time.Now()
+	upcoming := make([]model.AppointmentWithDetails, 0, len(appointments))
+	for _, a := range appointments {
+		if a.StartTime.After(now) && isOpenAppointment(a.Status) {
+			upcoming = append(upcoming, a)
+		}
+	}
+	return upcoming, nil
+}
+
+// CancelAppointment cancels one of the patient's appointments if the clinic's notice window allows it.
+func (s *portalService) CancelAppointment(ctx context.Context, actor model.PortalActor, appointmentID string, req *model.PortalCancelRequest) error {
+	patient, appointment, err := s.getOwnAppointment(ctx, actor, appointmentID)
+	if err != nil {
+		return err
+	}
+
+	policy, err := s.repo.Clinic().GetPortalPolicy(ctx, patient.ClinicID)
+	if err != nil {
+		return fmt.Errorf("failed to get portal policy: %w", err)
+	}
+
+	if !isOpenAppointment(appointment.Status) {
+		return fmt.Errorf("%w: appointment can no longer be cancelled", ErrPolicyViolation)
+	}
+	if time.Until(appointment.StartTime) < time.Duration(policy.CancelNoticeHours)*time.Hour {
+		return fmt.Errorf("%w: cancellations require at least %d hours notice", ErrPolicyViolation, policy.CancelNoticeHours)
+	}
+
+	cancelReq := &model.CancelAppointmentRequest{Reason: req.Reason}
+	if err := s.appointments.Cancel(ctx, patient.ClinicID, appointment.ID, "", cancelReq); err != nil {
+		return err
+	}
+
+	log.Info().
+		Str("appointment_id", appointment.ID).
+		Str("patient_id", patient.ID).
+		Str("actor_id", actor.UserID).
+		Bool("via_proxy", actor.IsProxy()).
+		Msg("appointment cancelled through portal")
+
+	return nil
+}
+
+// RescheduleAppointment moves one of the patient's appointments within the
+// clinic's portal and no-show policies.
+func (s *portalService) RescheduleAppointment(ctx context.Context, actor model.PortalActor, appointmentID string, req *model.PortalRescheduleRequest) (*model.AppointmentWithDetails, error) {
+	newStart, err := time.Parse(time.RFC3339, req.StartTime)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid start_time format", repository.ErrInvalidInput)
+	}
+
+	patient, appointment, err := s.getOwnAppointment(ctx, actor, appointmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := s.repo.Clinic().GetPortalPolicy(ctx, patient.ClinicID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get portal policy: %w", err)
+	}
+
+	if !policy.AllowReschedule {
+		return nil, fmt.Errorf("%w: please contact the clinic to reschedule", ErrPolicyViolation)
+	}
+	if !isOpenAppointment(appointment.Status) {
+		return nil, fmt.Errorf("%w: appointment can no longer be rescheduled", ErrPolicyViolation)
+	}
+
+	notice := time.Duration(policy.RescheduleNoticeHours) * time.Hour
+	if time.Until(appointment.StartTime) < notice {
+		return nil, fmt.Errorf("%w: rescheduling requires at least %d hours notice", ErrPolicyViolation, policy.RescheduleNoticeHours)
+	}
+	if time.Until(newStart) < notice {
+		return nil, fmt.Errorf("%w: new time must be at least %d hours from now", ErrPolicyViolation, policy.RescheduleNoticeHours)
+	}
+	if policy.MaxAdvanceDays > 0 && newStart.After(time.Now().AddDate(0, 0, policy.MaxAdvanceDays)) {
+		return nil, fmt.Errorf("%w: new time must be within %d days", ErrPolicyViolation, policy.MaxAdvanceDays)
+	}
+	if err := s.attendance.CheckSelfBooking(ctx, patient.ClinicID, patient.ID); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.appointments.Reschedule(ctx, patient.ClinicID, appointment.ID, "", newStart)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("appointment_id", appointment.ID).
+		Str("patient_id", patient.ID).
+		Str("actor_id", actor.UserID).
+		Bool("via_proxy", actor.IsProxy()).
+		Time("new_start_time", newStart).
+		Msg("appointment rescheduled through portal")
+
+	return updated, nil
+}
+
+// GetExercisePlan returns the patient's active home exercise programs and prescriptions.
+func (s *portalService) GetExercisePlan(ctx context.Context, actor model.PortalActor) (*model.PortalExercisePlan, error) {
+	patient, err := s.GetPatient(ctx, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	programs, err := s.exercises.GetPatientPrograms(ctx, patient.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	prescriptions, err := s.exercises.GetPatientPrescriptions(ctx, patient.ClinicID, patient.ID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &model.PortalExercisePlan{
+		Programs:      make([]model.HomeExerciseProgram, 0, len(programs)),
+		Prescriptions: prescriptions,
+	}
+	for _, p := range programs {
+		if p.IsActive {
+			plan.Programs = append(plan.Programs, p)
+		}
+	}
+
+	return plan, nil
+}
+
+// LogCompliance records an exercise session against one of the patient's active prescriptions.
+func (s *portalService) LogCompliance(ctx context.Context, actor model.PortalActor, prescriptionID string, req *model.LogComplianceRequest) (*model.ExerciseComplianceLog, error) {
+	patient, err := s.GetPatient(ctx, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	prescription, err := s.exercises.GetPrescription(ctx, patient.ClinicID, patient.ID, prescriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if prescription.Status != model.PrescriptionStatusActive {
+		return nil, fmt.Errorf("%w: prescription is not active", ErrPolicyViolation)
+	}
+
+	return s.exercises.LogCompliance(ctx, patient.ClinicID, patient.ID, prescriptionID, req)
+}
+
+// GetProgress returns the patient's recent pain and ROM measurements.
+func (s *portalService) GetProgress(ctx context.Context, actor model.PortalActor, joint string, limit int) (*model.PortalProgress, error) {
+	patient, err := s.GetPatient(ctx, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	pain, err := s.repo.QuickActions().GetPainHistory(ctx, patient.ID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pain history: %w", err)
+	}
+
+	rom, err := s.repo.QuickActions().GetROMHistory(ctx, patient.ID, joint, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ROM history: %w", err)
+	}
+
+	return &model.PortalProgress{Pain: pain, ROM: rom}, nil
+}
+
+// ListProxyGrants returns the caregiver grants the patient has issued.
+func (s *portalService) ListProxyGrants(ctx context.Context, actor model.PortalActor) ([]model.ProxyGrant, error) {
+	if actor.IsProxy() {
+		return nil, fmt.Errorf("%w: only the patient can see who has proxy access", ErrPolicyViolation)
+	}
+
+	patient, err := s.GetPatient(ctx, actor)
+	if err != nil {
+		return nil, err
+	}
+	return s.proxies.ListPatientGrants(ctx, patient.ClinicID, patient.ID)
+}
+
+// GrantProxyAccess lets the patient authorize a caregiver. Caregivers cannot
+// delegate their own access further.
+func (s *portalService) GrantProxyAccess(ctx context.Context, actor model.PortalActor, req *model.CreateProxyGrantRequest) (*model.ProxyGrant, error) {
+	if actor.IsProxy() {
+		return nil, fmt.Errorf("%w: only the patient can grant proxy access", ErrPolicyViolation)
+	}
+
+	patient, err := s.GetPatient(ctx, actor)
+	if err != nil {
+		return nil, err
+	}
+	if req.ProxyUserID == actor.UserID {
+		return nil, fmt.Errorf("%w: cannot grant proxy access to yourself", repository.ErrInvalidInput)
+	}
+
+	return s.proxies.GrantAccess(ctx, patient.ClinicID, patient.ID, actor.UserID, true, req)
+}
+
+// RevokeProxyAccess lets the patient withdraw a caregiver's access.
+func (s *portalService) RevokeProxyAccess(ctx context.Context, actor model.PortalActor, grantID string) error {
+	if actor.IsProxy() {
+		return fmt.Errorf("%w: only the patient can revoke proxy access", ErrPolicyViolation)
+	}
+
+	patient, err := s.GetPatient(ctx, actor)
+	if err != nil {
+		return err
+	}
+
+	return s.proxies.RevokeAccess(ctx, patient.ClinicID, patient.ID, grantID, actor.UserID)
+}
+
+// ListConsentTemplates returns the consent texts the patient may sign.
+// Caregivers cannot sign consents, so they are not shown them.
+func (s *portalService) ListConsentTemplates(ctx context.Context, actor model.PortalActor) ([]model.ConsentTemplate, error) {
+	if actor.IsProxy() {
+		return nil, fmt.Errorf("%w: only the patient can give consent", ErrPolicyViolation)
+	}
+
+	patient, err := s.GetPatient(ctx, actor)
+	if err != nil {
+		return nil, err
+	}
+	return s.consents.ListTemplates(ctx, patient.ClinicID)
+}
+
+// GetConsentSummary returns the patient's standing for every consent type.
+// Consent history is not shared with caregivers.
+func (s *portalService) GetConsentSummary(ctx context.Context, actor model.PortalActor) ([]model.ConsentSummary, error) {
+	if actor.IsProxy() {
+		return nil, fmt.Errorf("%w: only the patient can see their consents", ErrPolicyViolation)
+	}
+
+	patient, err := s.GetPatient(ctx, actor)
+	if err != nil {
+		return nil, err
+	}
+	return s.consents.GetPatientSummary(ctx, patient.ClinicID, patient.ID)
+}
+
+// RecordConsent lets the patient sign a consent themselves. Caregivers cannot
+// consent on the patient's behalf through the portal.
+func (s *portalService) RecordConsent(ctx context.Context, actor model.PortalActor, req *model.RecordConsentRequest) (*model.PatientConsent, error) {
+	if actor.IsProxy() {
+		return nil, fmt.Errorf("%w: only the patient can give consent", ErrPolicyViolation)
+	}
+
+	patient, err := s.GetPatient(ctx, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	// Self-service consents are always signed by the patient personally
+	req.SignerRelationship = ""
+	if req.SignedByName == "" {
+		req.SignedByName = patient.FullName()
+	}
+
+	return s.consents.RecordConsent(ctx, patient.ClinicID, patient.ID, actor.UserID, req)
+}
+
+// WithdrawConsent lets the patient withdraw one of their consents.
+func (s *portalService) WithdrawConsent(ctx context.Context, actor model.PortalActor, consentID string, req *model.WithdrawConsentRequest) error {
+	if actor.IsProxy() {
+		return fmt.Errorf("%w: only the patient can withdraw consent", ErrPolicyViolation)
+	}
+
+	patient, err := s.GetPatient(ctx, actor)
+	if err != nil {
+		return err
+	}
+
+	return s.consents.WithdrawConsent(ctx, patient.ClinicID, patient.ID, consentID, actor.UserID, req)
+}
+
+// RequestExport queues an export of the patient's full record. Only the
+// patient can request one; caregiver access does not extend to exports.
+func (s *portalService) RequestExport(ctx context.Context, actor model.PortalActor) (*model.DataExport, error) {
+	if actor.IsProxy() {
+		return nil, fmt.Errorf("%w: only the patient can export their record", ErrPolicyViolation)
+	}
+
+	patient, err := s.GetPatient(ctx, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &model.CreateExportRequest{Purpose: string(model.ExportPurposePatientRequest)}
+	return s.exports.RequestExport(ctx, patient.ClinicID, patient.ID, actor.UserID, req)
+}
+
+// ListExports returns the patient's export jobs.
+func (s *portalService) ListExports(ctx context.Context, actor model.PortalActor) ([]model.DataExport, error) {
+	if actor.IsProxy() {
+		return nil, fmt.Errorf("%w: only the patient can export their record", ErrPolicyViolation)
+	}
+
+	patient, err := s.GetPatient(ctx, actor)
+	if err != nil {
+		return nil, err
+	}
+	return s.exports.ListPatientExports(ctx, patient.ClinicID, patient.ID)
+}
+
+// GetExport returns one of the patient's export jobs.
+func (s *portalService) GetExport(ctx context.Context, actor model.PortalActor, exportID string) (*model.DataExport, error) {
+	_, job, err := s.getOwnExport(ctx, actor, exportID)
+	return job, err
+}
+
+// OpenExport returns one of the patient's finished export bundles for download.
+func (s *portalService) OpenExport(ctx context.Context, actor model.PortalActor, exportID string) (io.ReadCloser, *model.DataExport, error) {
+	patient, _, err := s.getOwnExport(ctx, actor, exportID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.exports.OpenExport(ctx, patient.ClinicID, exportID, actor.UserID)
+}
+
+// getOwnExport loads an export job and confirms it belongs to the actor's patient.
+func (s *portalService) getOwnExport(ctx context.Context, actor model.PortalActor, exportID string) (*model.Patient, *model.DataExport, error) {
+	if actor.IsProxy() {
+		return nil, nil, fmt.Errorf("%w: only the patient can export their record", ErrPolicyViolation)
+	}
+
+	patient, err := s.GetPatient(ctx, actor)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	job, err := s.exports.GetExport(ctx, patient.ClinicID, exportID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if job.PatientID != patient.ID {
+		return nil, nil, repository.ErrNotFound
+	}
+
+	return patient, job, nil
+}
+
+// ListCalendarFeeds returns the patient's calendar feeds.
+func (s *portalService) ListCalendarFeeds(ctx context.Context, actor model.PortalActor) ([]model.CalendarFeed, error) {
+	patient, err := s.ownCalendarPatient(ctx, actor)
+	if err != nil {
+		return nil, err
+	}
+	return s.calendar.ListFeeds(ctx, patient.ClinicID, model.CalendarFeedPatient, patient.ID)
+}
+
+// IssueCalendarFeed issues a new calendar feed URL for the patient, replacing
+// any previous one.
+func (s *portalService) IssueCalendarFeed(ctx context.Context, actor model.PortalActor) (*model.CalendarFeed, error) {
+	patient, err := s.ownCalendarPatient(ctx, actor)
+	if err != nil {
+		return nil, err
+	}
+	return s.calendar.IssueFeed(ctx, patient.ClinicID, model.CalendarFeedPatient, patient.ID, actor.UserID)
+}
+
+// RevokeCalendarFeed revokes one of the patient's calendar feeds.
+func (s *portalService) RevokeCalendarFeed(ctx context.Context, actor model.PortalActor, feedID string) error {
+	patient, err := s.ownCalendarPatient(ctx, actor)
+	if err != nil {
+		return err
+	}
+	return s.calendar.RevokeFeed(ctx, patient.ClinicID, model.CalendarFeedPatient, patient.ID, feedID, actor.UserID)
+}
+
+// AppointmentICS renders one of the patient's appointments as an .ics file.
+func (s *portalService) AppointmentICS(ctx context.Context, actor model.PortalActor, appointmentID string) ([]byte, error) {
+	_, appointment, err := s.getOwnAppointment(ctx, actor, appointmentID)
+	if err != nil {
+		return nil, err
+	}
+	return s.calendar.RenderAppointment(ctx, appointment)
+}
+
+// CheckInToken returns a code for checking in to one of the patient's
+// appointments at a clinic kiosk.
+func (s *portalService) CheckInToken(ctx context.Context, actor model.PortalActor, appointmentID string) (*model.CheckInToken, error) {
+	patient, appointment, err := s.getOwnAppointment(ctx, actor, appointmentID)
+	if err != nil {
+		return nil, err
+	}
+	return s.kiosk.IssueCheckInToken(ctx, patient.ClinicID, appointment.ID)
+}
+
+// ownCalendarPatient resolves the patient for calendar feed management. A feed
+// URL keeps working without a login, so it would outlive a revoked proxy grant;
+// only the patient may manage feeds.
+func (s *portalService) ownCalendarPatient(ctx context.Context, actor model.PortalActor) (*model.Patient, error) {
+	if actor.IsProxy() {
+		return nil, fmt.Errorf("%w: only the patient can manage calendar feeds", ErrPolicyViolation)
+	}
+	return s.GetPatient(ctx, actor)
+}
+
+// getOwnAppointment loads an appointment and confirms it belongs to the actor's patient.
+// Appointments of other patients are reported as not found.
+func (s *portalService) getOwnAppointment(ctx context.Context, actor model.PortalActor, appointmentID string) (*model.Patient, *model.AppointmentWithDetails, error) {
+	patient, err := s.GetPatient(ctx, actor)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	appointment, err := s.appointments.GetByID(ctx, patient.ClinicID, appointmentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if appointment.PatientID != patient.ID {
+		return nil, nil, repository.ErrNotFound
+	}
+
+	return patient, appointment, nil
+}
+
+// isOpenAppointment reports whether an appointment can still be changed.
+func isOpenAppointment(status model.AppointmentStatus) bool {
+	return status == model.AppointmentStatusScheduled || status == model.AppointmentStatusConfirmed
+}
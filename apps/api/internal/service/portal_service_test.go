@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+)
+
+func TestPortalProxyCannotReadPatientOnlyRecords(t *testing.T) {
+	// The checks come before any repository is used
+	s := &portalService{}
+	ctx := context.Background()
+	proxy := model.PortalActor{
+		UserID: "caregiver-1",
+		Grant: &model.ProxyGrant{
+			ID:     "grant-1",
+			Scopes: []model.ProxyScope{model.ProxyScopeViewProfile, model.ProxyScopeViewAppointments, model.ProxyScopeViewProgress},
+		},
+	}
+
+	calls := map[string]func() error{
+		"ListProxyGrants": func() error {
+			_, err := s.ListProxyGrants(ctx, proxy)
+			return err
+		},
+		"ListConsentTemplates": func() error {
+			_, err := s.ListConsentTemplates(ctx, proxy)
+			return err
+		},
+		"GetConsentSummary": func() error {
+			_, err := s.GetConsentSummary(ctx, proxy)
+			return err
+		},
+		"ListExports": func() error {
+			_, err := s.ListExports(ctx, proxy)
+			return err
+		},
+	}
+
+	for name, call := range calls {
+		if err := call(); !errors.Is(err, ErrPolicyViolation) {
+			t.Errorf("%s() as a proxy error = %v; want ErrPolicyViolation", name, err)
+		}
+	}
+}
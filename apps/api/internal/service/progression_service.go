@@ -0,0 +1,552 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/repository"
+)
+
+// ProgressionService defines the interface for exercise progression rules and
+// the history of prescription changes.
+type ProgressionService interface {
+	ListRules(ctx context.Context, clinicID, patientID, prescriptionID string) ([]model.ProgressionRule, error)
+	CreateRule(ctx context.Context, clinicID, patientID, prescriptionID, userID string, req *model.CreateProgressionRuleRequest) (*model.ProgressionRule, error)
+	UpdateRule(ctx context.Context, clinicID, patientID, prescriptionID, id string, req *model.UpdateProgressionRuleRequest) (*model.ProgressionRule, error)
+	DeleteRule(ctx context.Context, clinicID, patientID, prescriptionID, id string) error
+	ListHistory(ctx context.Context, clinicID, patientID, prescriptionID string) ([]model.PrescriptionChange, error)
+	Evaluate(ctx context.Context, clinicID, patientID, prescriptionID string) ([]model.PrescriptionChange, error)
+	EvaluateDue(ctx context.Context) (int, error)
+	ListPending(ctx context.Context, clinicID string) ([]model.PrescriptionChange, error)
+	Approve(ctx context.Context, clinicID, id, userID string, req *model.DecideProgressionRequest) (*model.PrescriptionChange, error)
+	Reject(ctx context.Context, clinicID, id, userID string, req *model.DecideProgressionRequest) (*model.PrescriptionChange, error)
+}
+
+// progressionService implements ProgressionService.
+type progressionService struct {
+	repo         repository.ProgressionRepository
+	exerciseRepo repository.ExerciseRepository
+	clinicRepo   repository.ClinicRepository
+}
+
+// NewProgressionService creates a new progression service.
+func NewProgressionService(repo repository.ProgressionRepository, exerciseRepo repository.ExerciseRepository, clinicRepo repository.ClinicRepository) ProgressionService {
+	return &progressionService{repo: repo, exerciseRepo: exerciseRepo, clinicRepo: clinicRepo}
+}
+
+// clinicProgression is what evaluating rules needs to know about a clinic.
+type clinicProgression struct {
+	policy *model.ProgressionPolicy
+	loc    *time.Location
+}
+
+// ListRules returns a prescription's progression rules.
+func (s *progressionService) ListRules(ctx context.Context, clinicID, patientID, prescriptionID string) ([]model.ProgressionRule, error) {
+	if _, err := s.getPrescription(ctx, clinicID, patientID, prescriptionID); err != nil {
+		return nil, err
+	}
+
+	rules, err := s.repo.ListRules(ctx, clinicID, prescriptionID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range rules {
+		rules[i].Description = rules[i].Describe()
+	}
+	return rules, nil
+}
+
+// CreateRule adds a progression rule to a prescription. Its first window
+// starts now, so earlier logs are not taken into account.
+func (s *progressionService) CreateRule(ctx context.Context, clinicID, patientID, prescriptionID, userID string, req *model.CreateProgressionRuleRequest) (*model.ProgressionRule, error) {
+	prescription, err := s.getPrescription(ctx, clinicID, patientID, prescriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if prescription.Status == model.PrescriptionStatusCompleted || prescription.Status == model.PrescriptionStatusCancelled {
+		return nil, fmt.Errorf("%w: cannot add progression rules to a %s prescription", repository.ErrInvalidInput, prescription.Status)
+	}
+
+	rule := &model.ProgressionRule{
+		ID:             uuid.New().String(),
+		ClinicID:       clinicID,
+		PrescriptionID: prescriptionID,
+		Parameter:      model.ProgressionParameter(req.Parameter),
+		Step:           req.Step,
+		MaxValue:       req.MaxValue,
+		IntervalDays:   req.IntervalDays,
+		MinSessions:    req.MinSessions,
+		MaxPain:        req.MaxPain,
+		MaxDifficulty:  model.ComplianceDifficulty(req.MaxDifficulty),
+		IsActive:       true,
+		WindowStart:    time.Now(),
+		CreatedBy:      staffActor(userID),
+	}
+	if rule.MinSessions == 0 {
+		rule.MinSessions = 1
+	}
+	if err := validateProgressionRule(rule); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateRule(ctx, rule); err != nil {
+		return nil, err
+	}
+	rule.Description = rule.Describe()
+
+	log.Info().
+		Str("rule_id", rule.ID).
+		Str("prescription_id", prescriptionID).
+		Str("rule", rule.Description).
+		Str("created_by", userID).
+		Msg("progression rule created")
+
+	return rule, nil
+}
+
+// UpdateRule changes a progression rule. Changes apply from the next
+// evaluation; the current window is kept.
+func (s *progressionService) UpdateRule(ctx context.Context, clinicID, patientID, prescriptionID, id string, req *model.UpdateProgressionRuleRequest) (*model.ProgressionRule, error) {
+	rule, err := s.getRule(ctx, clinicID, patientID, prescriptionID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Step != nil {
+		rule.Step = *req.Step
+	}
+	if req.MaxValue != nil {
+		rule.MaxValue = req.MaxValue
+	}
+	if req.IntervalDays != nil {
+		rule.IntervalDays = *req.IntervalDays
+	}
+	if req.MinSessions != nil {
+		rule.MinSessions = *req.MinSessions
+	}
+	if req.ClearMaxPain {
+		rule.MaxPain = nil
+	} else if req.MaxPain != nil {
+		rule.MaxPain = req.MaxPain
+	}
+	if req.ClearMaxDifficulty {
+		rule.MaxDifficulty = ""
+	} else if req.MaxDifficulty != nil {
+		rule.MaxDifficulty = model.ComplianceDifficulty(*req.MaxDifficulty)
+	}
+	if req.IsActive != nil {
+		if *req.IsActive && !rule.IsActive {
+			// Logs from while the rule was paused do not count
+			rule.WindowStart = time.Now()
+		}
+		rule.IsActive = *req.IsActive
+	}
+	if err := validateProgressionRule(rule); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateRule(ctx, rule); err != nil {
+		return nil, err
+	}
+	rule.Description = rule.Describe()
+
+	log.Info().
+		Str("rule_id", rule.ID).
+		Str("prescription_id", prescriptionID).
+		Str("rule", rule.Description).
+		Bool("active", rule.IsActive).
+		Msg("progression rule updated")
+
+	return rule, nil
+}
+
+// DeleteRule deletes a progression rule along with any progression of it
+// awaiting approval.
+func (s *progressionService) DeleteRule(ctx context.Context, clinicID, patientID, prescriptionID, id string) error {
+	if _, err := s.getRule(ctx, clinicID, patientID, prescriptionID, id); err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteRule(ctx, clinicID, id); err != nil {
+		return err
+	}
+
+	log.Info().
+		Str("rule_id", id).
+		Str("prescription_id", prescriptionID).
+		Msg("progression rule deleted")
+
+	return nil
+}
+
+// ListHistory returns a prescription's parameter changes, newest first.
+func (s *progressionService) ListHistory(ctx context.Context, clinicID, patientID, prescriptionID string) ([]model.PrescriptionChange, error) {
+	if _, err := s.getPrescription(ctx, clinicID, patientID, prescriptionID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListChanges(ctx, clinicID, prescriptionID)
+}
+
+// Evaluate evaluates a prescription's rules whose window has ended and
+// returns the progressions they made.
+func (s *progressionService) Evaluate(ctx context.Context, clinicID, patientID, prescriptionID string) ([]model.PrescriptionChange, error) {
+	prescription, err := s.getPrescription(ctx, clinicID, patientID, prescriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	rules, err := s.repo.ListDueRules(ctx, prescriptionID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	clinic, err := s.clinicProgression(ctx, clinicID)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []model.PrescriptionChange{}
+	for _, rule := range rules {
+		change, err := s.evaluateRule(ctx, prescription, rule, clinic, now)
+		if err != nil {
+			return changes, err
+		}
+		if change != nil {
+			changes = append(changes, *change)
+		}
+	}
+	return changes, nil
+}
+
+// EvaluateDue evaluates every rule whose window has ended, across clinics,
+// and returns how many progressions were made.
+func (s *progressionService) EvaluateDue(ctx context.Context) (int, error) {
+	now := time.Now()
+	rules, err := s.repo.ListDueRules(ctx, "", now)
+	if err != nil {
+		return 0, err
+	}
+
+	clinics := map[string]*clinicProgression{}
+	var prescription *model.ExercisePrescription
+	made := 0
+	for _, rule := range rules {
+		// Rules come grouped by prescription
+		if prescription == nil || prescription.ID != rule.PrescriptionID {
+			prescription, err = s.exerciseRepo.GetPrescriptionByID(ctx, rule.PrescriptionID)
+			if err != nil {
+				log.Warn().Err(err).Str("prescription_id", rule.PrescriptionID).Msg("failed to load prescription for progression")
+				prescription = nil
+				continue
+			}
+		}
+
+		clinic, ok := clinics[rule.ClinicID]
+		if !ok {
+			clinic, err = s.clinicProgression(ctx, rule.ClinicID)
+			if err != nil {
+				log.Warn().Err(err).Str("clinic_id", rule.ClinicID).Msg("failed to load clinic progression policy")
+				continue
+			}
+			clinics[rule.ClinicID] = clinic
+		}
+
+		change, err := s.evaluateRule(ctx, prescription, rule, clinic, now)
+		if err != nil {
+			log.Warn().Err(err).Str("rule_id", rule.ID).Msg("failed to evaluate progression rule")
+			continue
+		}
+		if change != nil {
+			made++
+		}
+	}
+
+	if made > 0 {
+		log.Info().Int("count", made).Msg("exercise progressions made")
+	}
+	return made, nil
+}
+
+// ListPending returns the clinic's progressions awaiting approval.
+func (s *progressionService) ListPending(ctx context.Context, clinicID string) ([]model.PrescriptionChange, error) {
+	return s.repo.ListPendingChanges(ctx, clinicID)
+}
+
+// Approve applies a proposed progression to its prescription. Fails with
+// repository.ErrConflict if the parameter was changed since the proposal.
+func (s *progressionService) Approve(ctx context.Context, clinicID, id, userID string, req *model.DecideProgressionRequest) (*model.PrescriptionChange, error) {
+	change, err := s.getPendingChange(ctx, clinicID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	change.Status = model.PrescriptionChangeApplied
+	change.DecisionNote = req.Note
+	change.DecidedAt = &now
+	change.DecidedBy = staffActor(userID)
+
+	if err := s.repo.ApplyChange(ctx, change); err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return nil, fmt.Errorf("%w: the prescription was changed after the progression was proposed", err)
+		}
+		return nil, err
+	}
+
+	log.Info().
+		Str("change_id", change.ID).
+		Str("prescription_id", change.PrescriptionID).
+		Str("parameter", string(change.Parameter)).
+		Int("from", change.FromValue).
+		Int("to", change.ToValue).
+		Str("approved_by", userID).
+		Msg("exercise progression approved")
+
+	return change, nil
+}
+
+// Reject declines a proposed progression. The rule carries on with its next
+// window.
+func (s *progressionService) Reject(ctx context.Context, clinicID, id, userID string, req *model.DecideProgressionRequest) (*model.PrescriptionChange, error) {
+	change, err := s.getPendingChange(ctx, clinicID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	change.Status = model.PrescriptionChangeRejected
+	change.DecisionNote = req.Note
+	change.DecidedAt = &now
+	change.DecidedBy = staffActor(userID)
+
+	if err := s.repo.RejectChange(ctx, change); err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("change_id", change.ID).
+		Str("prescription_id", change.PrescriptionID).
+		Str("rejected_by", userID).
+		Msg("exercise progression rejected")
+
+	return change, nil
+}
+
+// evaluateRule checks a due rule against the compliance logs of its window
+// and moves the window on. Returns the progression made, if any, which is
+// applied right away when the clinic's policy says so.
+func (s *progressionService) evaluateRule(ctx context.Context, prescription *model.ExercisePrescription, rule model.ProgressionRule, clinic *clinicProgression, now time.Time) (*model.PrescriptionChange, error) {
+	logs, err := s.repo.ListComplianceLogs(ctx, rule.PrescriptionID, rule.WindowStart, now)
+	if err != nil {
+		return nil, err
+	}
+
+	current := rule.Parameter.Value(prescription)
+	next, reason, ok := progressRule(rule, current, logs, rule.WindowStart.In(clinic.loc), now.In(clinic.loc))
+
+	var change *model.PrescriptionChange
+	if ok {
+		ruleID := rule.ID
+		change = &model.PrescriptionChange{
+			ID:             uuid.New().String(),
+			ClinicID:       rule.ClinicID,
+			PrescriptionID: rule.PrescriptionID,
+			PatientID:      prescription.PatientID,
+			RuleID:         &ruleID,
+			Source:         model.PrescriptionChangeRule,
+			Parameter:      rule.Parameter,
+			FromValue:      current,
+			ToValue:        next,
+			Status:         model.PrescriptionChangeProposed,
+			Reason:         reason,
+			ProposedAt:     now,
+		}
+		if clinic.policy.AutoApply {
+			change.Status = model.PrescriptionChangeApplied
+			change.DecidedAt = &now
+		}
+	}
+
+	if err := s.repo.RecordEvaluation(ctx, &rule, now, change); err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			// Evaluated elsewhere, or the prescription changed under us; the
+			// window stays put and the rule is evaluated again next time
+			log.Debug().Str("rule_id", rule.ID).Msg("progression rule evaluation superseded")
+			return nil, nil
+		}
+		return nil, err
+	}
+	if change == nil {
+		return nil, nil
+	}
+
+	if change.Status == model.PrescriptionChangeApplied {
+		switch rule.Parameter {
+		case model.ProgressionParamSets:
+			prescription.Sets = next
+		case model.ProgressionParamReps:
+			prescription.Reps = next
+		case model.ProgressionParamHoldSeconds:
+			prescription.HoldSeconds = next
+		}
+	}
+
+	log.Info().
+		Str("change_id", change.ID).
+		Str("rule_id", rule.ID).
+		Str("prescription_id", rule.PrescriptionID).
+		Str("parameter", string(rule.Parameter)).
+		Int("from", current).
+		Int("to", next).
+		Str("status", string(change.Status)).
+		Msg("exercise progression made")
+
+	return change, nil
+}
+
+// progressRule decides whether a rule progresses a parameter from current,
+// given the compliance logs of its window [from, to). Every rated log must be
+// within the rule's pain and difficulty limits, and at least MinSessions logs
+// must be rated within them; unrated logs neither count nor block. Returns the
+// new value and what the logs showed.
+func progressRule(rule model.ProgressionRule, current int, logs []model.ExerciseComplianceLog, from, to time.Time) (int, string, bool) {
+	ceiling := rule.Ceiling()
+	if current >= ceiling {
+		return current, "", false
+	}
+
+	qualifying := 0
+	highestPain := -1
+	var hardest model.ComplianceDifficulty
+	for _, l := range logs {
+		difficulty := model.ComplianceDifficulty(l.Difficulty)
+		if l.PainLevel != nil && *l.PainLevel > highestPain {
+			highestPain = *l.PainLevel
+		}
+		if difficulty.Rank() > hardest.Rank() {
+			hardest = difficulty
+		}
+
+		qualifies := true
+		if rule.MaxPain != nil {
+			if l.PainLevel == nil {
+				qualifies = false
+			} else if *l.PainLevel > *rule.MaxPain {
+				return current, "", false
+			}
+		}
+		if rule.MaxDifficulty != "" {
+			if difficulty.Rank() == 0 {
+				qualifies = false
+			} else if difficulty.Rank() > rule.MaxDifficulty.Rank() {
+				return current, "", false
+			}
+		}
+		if qualifies {
+			qualifying++
+		}
+	}
+
+	if qualifying < rule.MinSessions {
+		return current, "", false
+	}
+
+	next := current + rule.Step
+	if next > ceiling {
+		next = ceiling
+	}
+
+	reason := fmt.Sprintf("%d of %d sessions logged from %s to %s met the rule",
+		qualifying, len(logs), from.Format("02/01/2006"), to.Format("02/01/2006"))
+	if highestPain >= 0 {
+		reason += fmt.Sprintf("; highest pain %d", highestPain)
+	}
+	if hardest != "" {
+		reason += fmt.Sprintf("; hardest difficulty %s", hardest)
+	}
+	return next, reason, true
+}
+
+// validateProgressionRule checks a rule's limits against its parameter.
+func validateProgressionRule(rule *model.ProgressionRule) error {
+	limit := rule.Parameter.Limit()
+	if rule.MaxValue != nil && *rule.MaxValue > limit {
+		return fmt.Errorf("%w: max_value cannot exceed %d for %s", repository.ErrInvalidInput, limit, rule.Parameter)
+	}
+	if rule.Step > limit {
+		return fmt.Errorf("%w: step cannot exceed %d for %s", repository.ErrInvalidInput, limit, rule.Parameter)
+	}
+	return nil
+}
+
+// getPrescription loads a prescription of a clinic's patient.
+func (s *progressionService) getPrescription(ctx context.Context, clinicID, patientID, prescriptionID string) (*model.ExercisePrescription, error) {
+	return getPatientPrescription(ctx, s.exerciseRepo, clinicID, patientID, prescriptionID)
+}
+
+// getRule loads one of a prescription's rules.
+func (s *progressionService) getRule(ctx context.Context, clinicID, patientID, prescriptionID, id string) (*model.ProgressionRule, error) {
+	if _, err := s.getPrescription(ctx, clinicID, patientID, prescriptionID); err != nil {
+		return nil, err
+	}
+
+	rule, err := s.repo.GetRule(ctx, clinicID, id)
+	if err != nil {
+		return nil, err
+	}
+	if rule.PrescriptionID != prescriptionID {
+		return nil, repository.ErrNotFound
+	}
+	return rule, nil
+}
+
+// getPendingChange loads a progression awaiting approval.
+func (s *progressionService) getPendingChange(ctx context.Context, clinicID, id string) (*model.PrescriptionChange, error) {
+	change, err := s.repo.GetChange(ctx, clinicID, id)
+	if err != nil {
+		return nil, err
+	}
+	if change.Status != model.PrescriptionChangeProposed {
+		return nil, fmt.Errorf("%w: the progression was already %s", repository.ErrConflict, change.Status)
+	}
+	return change, nil
+}
+
+// clinicProgression loads a clinic's progression policy and time zone.
+func (s *progressionService) clinicProgression(ctx context.Context, clinicID string) (*clinicProgression, error) {
+	policy, err := s.clinicRepo.GetProgressionPolicy(ctx, clinicID)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := clinicLocation(ctx, s.clinicRepo, clinicID)
+	if err != nil {
+		return nil, err
+	}
+	return &clinicProgression{policy: policy, loc: loc}, nil
+}
+
+// RunProgressionJob evaluates due progression rules every interval until the
+// context is done.
+func RunProgressionJob(ctx context.Context, progression ProgressionService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := progression.EvaluateDue(ctx); err != nil {
+			log.Error().Err(err).Msg("progression job failed")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+)
+
+func TestProgressRule(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+	entry := func(pain *int, difficulty string) model.ExerciseComplianceLog {
+		return model.ExerciseComplianceLog{PainLevel: pain, Difficulty: difficulty}
+	}
+
+	// +2 reps weekly while pain <= 3 and difficulty is easy, over 3 sessions
+	rule := model.ProgressionRule{
+		Parameter:     model.ProgressionParamReps,
+		Step:          2,
+		MaxValue:      intPtr(15),
+		IntervalDays:  7,
+		MinSessions:   3,
+		MaxPain:       intPtr(3),
+		MaxDifficulty: model.ComplianceDifficultyEasy,
+	}
+
+	tests := []struct {
+		name    string
+		current int
+		logs    []model.ExerciseComplianceLog
+		want    int
+		wantOK  bool
+	}{
+		{
+			name:    "conditions met",
+			current: 10,
+			logs:    []model.ExerciseComplianceLog{entry(intPtr(1), "easy"), entry(intPtr(3), "easy"), entry(intPtr(2), "easy")},
+			want:    12,
+			wantOK:  true,
+		},
+		{
+			name:    "capped at max value",
+			current: 14,
+			logs:    []model.ExerciseComplianceLog{entry(intPtr(1), "easy"), entry(intPtr(1), "easy"), entry(intPtr(1), "easy")},
+			want:    15,
+			wantOK:  true,
+		},
+		{
+			name:    "already at max value",
+			current: 15,
+			logs:    []model.ExerciseComplianceLog{entry(intPtr(1), "easy"), entry(intPtr(1), "easy"), entry(intPtr(1), "easy")},
+			want:    15,
+		},
+		{
+			name:    "one session too painful",
+			current: 10,
+			logs:    []model.ExerciseComplianceLog{entry(intPtr(1), "easy"), entry(intPtr(4), "easy"), entry(intPtr(1), "easy"), entry(intPtr(1), "easy")},
+			want:    10,
+		},
+		{
+			name:    "one session too hard",
+			current: 10,
+			logs:    []model.ExerciseComplianceLog{entry(intPtr(1), "easy"), entry(intPtr(1), "moderate"), entry(intPtr(1), "easy"), entry(intPtr(1), "easy")},
+			want:    10,
+		},
+		{
+			name:    "unrated sessions do not count",
+			current: 10,
+			logs:    []model.ExerciseComplianceLog{entry(intPtr(1), "easy"), entry(nil, "easy"), entry(intPtr(1), ""), entry(intPtr(1), "easy")},
+			want:    10,
+		},
+		{
+			name:    "unrated sessions do not block",
+			current: 10,
+			logs:    []model.ExerciseComplianceLog{entry(intPtr(1), "easy"), entry(nil, ""), entry(intPtr(1), "easy"), entry(intPtr(1), "easy")},
+			want:    12,
+			wantOK:  true,
+		},
+		{
+			name:    "no logs",
+			current: 10,
+			want:    10,
+		},
+	}
+
+	from := time.Date(2026, 10, 11, 9, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 7)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, reason, ok := progressRule(rule, tt.current, tt.logs, from, to)
+			if ok != tt.wantOK || got != tt.want {
+				t.Fatalf("progressRule() = %d, %v; want %d, %v", got, ok, tt.want, tt.wantOK)
+			}
+			if ok && reason == "" {
+				t.Error("progressRule() gave no reason for the progression")
+			}
+		})
+	}
+}
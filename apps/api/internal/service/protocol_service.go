@@ -0,0 +1,504 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/repository"
+)
+
+// ProtocolService defines the interface for exercise protocol templates and
+// the phased programs created from them.
+type ProtocolService interface {
+	ListProtocols(ctx context.Context, clinicID string, includeInactive bool) ([]model.ExerciseProtocol, error)
+	GetProtocol(ctx context.Context, clinicID, id string) (*model.ExerciseProtocol, error)
+	CreateProtocol(ctx context.Context, clinicID, userID string, req *model.CreateProtocolRequest) (*model.ExerciseProtocol, error)
+	UpdateProtocol(ctx context.Context, clinicID, id string, req *model.UpdateProtocolRequest) (*model.ExerciseProtocol, error)
+	Instantiate(ctx context.Context, clinicID, patientID, userID string, req *model.InstantiateProtocolRequest) (*model.HomeExerciseProgram, error)
+	GetProgram(ctx context.Context, clinicID, patientID, programID string) (*model.HomeExerciseProgram, error)
+	AdvanceDue(ctx context.Context) (int, error)
+}
+
+// protocolService implements ProtocolService.
+type protocolService struct {
+	repo         repository.ProtocolRepository
+	exerciseRepo repository.ExerciseRepository
+	patientRepo  repository.PatientRepository
+	clinicRepo   repository.ClinicRepository
+}
+
+// NewProtocolService creates a new protocol service.
+func NewProtocolService(repo repository.ProtocolRepository, exerciseRepo repository.ExerciseRepository, patientRepo repository.PatientRepository, clinicRepo repository.ClinicRepository) ProtocolService {
+	return &protocolService{repo: repo, exerciseRepo: exerciseRepo, patientRepo: patientRepo, clinicRepo: clinicRepo}
+}
+
+// ListProtocols returns the clinic's protocols.
+func (s *protocolService) ListProtocols(ctx context.Context, clinicID string, includeInactive bool) ([]model.ExerciseProtocol, error) {
+	return s.repo.List(ctx, clinicID, includeInactive)
+}
+
+// GetProtocol returns one of the clinic's protocols.
+func (s *protocolService) GetProtocol(ctx context.Context, clinicID, id string) (*model.ExerciseProtocol, error) {
+	return s.repo.GetByID(ctx, clinicID, id)
+}
+
+// CreateProtocol creates a protocol template.
+func (s *protocolService) CreateProtocol(ctx context.Context, clinicID, userID string, req *model.CreateProtocolRequest) (*model.ExerciseProtocol, error) {
+	protocol := &model.ExerciseProtocol{
+		ID:            uuid.New().String(),
+		ClinicID:      clinicID,
+		Name:          strings.TrimSpace(req.Name),
+		NameVi:        strings.TrimSpace(req.NameVi),
+		Description:   strings.TrimSpace(req.Description),
+		DescriptionVi: strings.TrimSpace(req.DescriptionVi),
+		Frequency:     strings.TrimSpace(req.Frequency),
+		Phases:        req.Phases,
+		IsActive:      true,
+		CreatedBy:     staffActor(userID),
+	}
+
+	if err := s.validatePhases(ctx, clinicID, protocol.Phases); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, protocol); err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("protocol_id", protocol.ID).
+		Str("clinic_id", clinicID).
+		Int("phases", len(protocol.Phases)).
+		Str("created_by", userID).
+		Msg("exercise protocol created")
+
+	return protocol, nil
+}
+
+// UpdateProtocol edits a protocol and bumps its version. Programs already
+// created from it keep their own copy of the phases and are not changed.
+func (s *protocolService) UpdateProtocol(ctx context.Context, clinicID, id string, req *model.UpdateProtocolRequest) (*model.ExerciseProtocol, error) {
+	protocol, err := s.repo.GetByID(ctx, clinicID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		protocol.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.NameVi != nil {
+		protocol.NameVi = strings.TrimSpace(*req.NameVi)
+	}
+	if req.Description != nil {
+		protocol.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.DescriptionVi != nil {
+		protocol.DescriptionVi = strings.TrimSpace(*req.DescriptionVi)
+	}
+	if req.Frequency != nil {
+		protocol.Frequency = strings.TrimSpace(*req.Frequency)
+	}
+	if req.Phases != nil {
+		protocol.Phases = req.Phases
+	}
+	if req.IsActive != nil {
+		protocol.IsActive = *req.IsActive
+	}
+
+	if protocol.Name == "" || protocol.Frequency == "" {
+		return nil, fmt.Errorf("%w: name and frequency are required", repository.ErrInvalidInput)
+	}
+	if req.Phases != nil {
+		if err := s.validatePhases(ctx, clinicID, protocol.Phases); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.Update(ctx, protocol); err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("protocol_id", protocol.ID).
+		Int("version", protocol.Version).
+		Msg("exercise protocol updated")
+
+	return protocol, nil
+}
+
+// Instantiate creates a patient's home exercise program from a protocol,
+// applying the request's overrides. The phases are copied into the program,
+// scheduled back to back from the start date, and the current phase's
+// prescriptions are created straight away.
+func (s *protocolService) Instantiate(ctx context.Context, clinicID, patientID, userID string, req *model.InstantiateProtocolRequest) (*model.HomeExerciseProgram, error) {
+	if _, err := s.patientRepo.GetByID(ctx, clinicID, patientID); err != nil {
+		return nil, err
+	}
+
+	protocol, err := s.repo.GetByID(ctx, clinicID, req.ProtocolID)
+	if err != nil {
+		return nil, err
+	}
+	if !protocol.IsActive {
+		return nil, fmt.Errorf("%w: protocol is inactive", repository.ErrInvalidInput)
+	}
+
+	startDate, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid start date", repository.ErrInvalidInput)
+	}
+
+	phases, err := applyProtocolOverrides(protocol.Phases, req.Phases)
+	if err != nil {
+		return nil, err
+	}
+
+	program := &model.HomeExerciseProgram{
+		ID:            uuid.New().String(),
+		PatientID:     patientID,
+		ClinicID:      clinicID,
+		CreatedBy:     userID,
+		Name:          protocol.Name,
+		NameVi:        protocol.NameVi,
+		Description:   protocol.Description,
+		DescriptionVi: protocol.DescriptionVi,
+		Frequency:     protocol.Frequency,
+		StartDate:     startDate,
+		IsActive:      true,
+	}
+	if name := strings.TrimSpace(req.Name); name != "" {
+		program.Name = name
+	}
+	if nameVi := strings.TrimSpace(req.NameVi); nameVi != "" {
+		program.NameVi = nameVi
+	}
+	if frequency := strings.TrimSpace(req.Frequency); frequency != "" {
+		program.Frequency = frequency
+	}
+	program.FrequencySchedule, _ = model.ParseFrequency(program.Frequency)
+
+	programPhases := make([]model.ProgramPhase, len(phases))
+	phaseStart := startDate
+	for i, phase := range phases {
+		phaseEnd := phaseStart.AddDate(0, 0, phase.DurationWeeks*7)
+		programPhases[i] = model.ProgramPhase{
+			ID:              uuid.New().String(),
+			ProgramID:       program.ID,
+			ClinicID:        clinicID,
+			ProtocolID:      &protocol.ID,
+			ProtocolVersion: protocol.Version,
+			PhaseNumber:     i + 1,
+			Name:            phase.Name,
+			NameVi:          phase.NameVi,
+			StartDate:       phaseStart,
+			EndDate:         phaseEnd,
+			Exercises:       phase.Exercises,
+			PrescriptionIDs: []string{},
+			Status:          model.ProgramPhaseUpcoming,
+		}
+		program.DurationWeeks += phase.DurationWeeks
+		phaseStart = phaseEnd
+	}
+	program.EndDate = &phaseStart
+
+	if err := s.repo.CreateProgram(ctx, program, programPhases); err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("program_id", program.ID).
+		Str("patient_id", patientID).
+		Str("protocol_id", protocol.ID).
+		Int("protocol_version", protocol.Version).
+		Str("created_by", userID).
+		Msg("home exercise program created from protocol")
+
+	loc, err := clinicLocation(ctx, s.clinicRepo, clinicID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.advance(ctx, program, programPhases, loc); err != nil {
+		return nil, err
+	}
+
+	return s.GetProgram(ctx, clinicID, patientID, program.ID)
+}
+
+// GetProgram returns a patient's program with its prescriptions and, for
+// programs created from a protocol, its phases.
+func (s *protocolService) GetProgram(ctx context.Context, clinicID, patientID, programID string) (*model.HomeExerciseProgram, error) {
+	program, err := s.exerciseRepo.GetProgramByID(ctx, programID)
+	if err != nil {
+		return nil, err
+	}
+	if program.ClinicID != clinicID || program.PatientID != patientID {
+		return nil, repository.ErrNotFound
+	}
+
+	phases, err := s.repo.ListProgramPhases(ctx, programID)
+	if err != nil {
+		return nil, err
+	}
+	if len(phases) > 0 {
+		program.Phases = phases
+	}
+	return program, nil
+}
+
+// AdvanceDue moves every program with a phase starting or ending today, in
+// its clinic's time zone, to its next phase, and returns how many programs
+// were advanced.
+func (s *protocolService) AdvanceDue(ctx context.Context) (int, error) {
+	// Tomorrow in UTC is today or later in every time zone
+	ids, err := s.repo.ListProgramsToAdvance(ctx, time.Now().UTC().AddDate(0, 0, 1))
+	if err != nil {
+		return 0, err
+	}
+
+	locations := map[string]*time.Location{}
+	advanced := 0
+	for _, id := range ids {
+		program, err := s.exerciseRepo.GetProgramByID(ctx, id)
+		if err != nil {
+			log.Warn().Err(err).Str("program_id", id).Msg("failed to load program to advance")
+			continue
+		}
+
+		loc, ok := locations[program.ClinicID]
+		if !ok {
+			loc, err = clinicLocation(ctx, s.clinicRepo, program.ClinicID)
+			if err != nil {
+				log.Warn().Err(err).Str("clinic_id", program.ClinicID).Msg("failed to load clinic timezone")
+				continue
+			}
+			locations[program.ClinicID] = loc
+		}
+
+		phases, err := s.repo.ListProgramPhases(ctx, id)
+		if err != nil {
+			log.Warn().Err(err).Str("program_id", id).Msg("failed to load program phases")
+			continue
+		}
+
+		changed, err := s.advance(ctx, program, phases, loc)
+		if err != nil {
+			log.Warn().Err(err).Str("program_id", id).Msg("failed to advance program")
+			continue
+		}
+		if changed {
+			advanced++
+		}
+	}
+
+	if advanced > 0 {
+		log.Info().Int("count", advanced).Msg("exercise programs advanced")
+	}
+	return advanced, nil
+}
+
+// advance brings a program's phases up to date with today at the clinic,
+// creating the prescriptions of a phase that has started. It reports whether
+// anything changed.
+func (s *protocolService) advance(ctx context.Context, program *model.HomeExerciseProgram, phases []model.ProgramPhase, loc *time.Location) (bool, error) {
+	// Phase dates are calendar dates, stored as midnight UTC
+	y, m, d := todayIn(loc).Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+
+	step := planProgramAdvance(phases, today)
+	step.ProgramID = program.ID
+	step.At = time.Now()
+	step.Finish = step.Finish && program.IsActive
+	if len(step.Complete) == 0 && step.Activate == "" && !step.Finish {
+		return false, nil
+	}
+
+	if step.Activate != "" {
+		for _, phase := range phases {
+			if phase.ID == step.Activate {
+				step.Prescriptions = phasePrescriptions(program, phase)
+				break
+			}
+		}
+	}
+
+	err := s.repo.AdvanceProgram(ctx, step)
+	if errors.Is(err, repository.ErrConflict) {
+		// Another instance advanced the program first
+		log.Debug().Str("program_id", program.ID).Msg("program phase already started")
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	log.Info().
+		Str("program_id", program.ID).
+		Int("completed_phases", len(step.Complete)).
+		Str("activated_phase", step.Activate).
+		Bool("finished", step.Finish).
+		Msg("exercise program advanced")
+
+	return true, nil
+}
+
+// planProgramAdvance works out which phases have ended and which has started
+// by today. Phases that were skipped entirely, e.g. because the program
+// started in the past, are completed without ever being activated.
+func planProgramAdvance(phases []model.ProgramPhase, today time.Time) *repository.ProgramAdvance {
+	step := &repository.ProgramAdvance{Finish: len(phases) > 0}
+	for _, phase := range phases {
+		ended := !phase.EndDate.After(today)
+		switch {
+		case ended && phase.Status != model.ProgramPhaseCompleted:
+			step.Complete = append(step.Complete, phase.ID)
+		case !ended && phase.Status == model.ProgramPhaseUpcoming && !phase.StartDate.After(today):
+			step.Activate = phase.ID
+		}
+		if !ended {
+			step.Finish = false
+		}
+	}
+	return step
+}
+
+// phasePrescriptions builds the prescriptions of a program phase, which run
+// for the length of the phase.
+func phasePrescriptions(program *model.HomeExerciseProgram, phase model.ProgramPhase) []model.ExercisePrescription {
+	weeks := daysBetween(phase.StartDate, phase.EndDate) / 7
+	prescriptions := make([]model.ExercisePrescription, len(phase.Exercises))
+	for i, exercise := range phase.Exercises {
+		frequency, schedule := exercise.Frequency, program.FrequencySchedule
+		if frequency == "" {
+			frequency = program.Frequency
+		} else {
+			schedule, _ = model.ParseFrequency(frequency)
+		}
+		endDate := phase.EndDate
+		prescriptions[i] = model.ExercisePrescription{
+			ID:                 uuid.New().String(),
+			PatientID:          program.PatientID,
+			ExerciseID:         exercise.ExerciseID,
+			ClinicID:           program.ClinicID,
+			PrescribedBy:       program.CreatedBy,
+			ProgramID:          &program.ID,
+			Sets:               exercise.Sets,
+			Reps:               exercise.Reps,
+			HoldSeconds:        exercise.HoldSeconds,
+			Frequency:          frequency,
+			FrequencySchedule:  schedule,
+			DurationWeeks:      weeks,
+			CustomInstructions: exercise.CustomInstructions,
+			Status:             model.PrescriptionStatusActive,
+			StartDate:          phase.StartDate,
+			EndDate:            &endDate,
+		}
+	}
+	return prescriptions
+}
+
+// applyProtocolOverrides returns a copy of a protocol's phases with a
+// patient's overrides applied.
+func applyProtocolOverrides(phases []model.ProtocolPhase, overrides []model.ProtocolPhaseOverride) ([]model.ProtocolPhase, error) {
+	result := make([]model.ProtocolPhase, len(phases))
+	for i, phase := range phases {
+		result[i] = phase
+		result[i].Exercises = append([]model.ProtocolExercise(nil), phase.Exercises...)
+	}
+
+	for _, override := range overrides {
+		if override.Phase < 1 || override.Phase > len(result) {
+			return nil, fmt.Errorf("%w: protocol has no phase %d", repository.ErrInvalidInput, override.Phase)
+		}
+		phase := &result[override.Phase-1]
+		if override.DurationWeeks != nil {
+			phase.DurationWeeks = *override.DurationWeeks
+		}
+
+		for _, eo := range override.Exercises {
+			index := -1
+			for j, exercise := range phase.Exercises {
+				if exercise.ExerciseID == eo.ExerciseID {
+					index = j
+					break
+				}
+			}
+			if index < 0 {
+				return nil, fmt.Errorf("%w: phase %d has no exercise %s", repository.ErrInvalidInput, override.Phase, eo.ExerciseID)
+			}
+
+			if eo.Exclude {
+				phase.Exercises = append(phase.Exercises[:index], phase.Exercises[index+1:]...)
+				continue
+			}
+			exercise := &phase.Exercises[index]
+			if eo.Sets != nil {
+				exercise.Sets = *eo.Sets
+			}
+			if eo.Reps != nil {
+				exercise.Reps = *eo.Reps
+			}
+			if eo.HoldSeconds != nil {
+				exercise.HoldSeconds = *eo.HoldSeconds
+			}
+			if eo.Frequency != nil {
+				exercise.Frequency = strings.TrimSpace(*eo.Frequency)
+			}
+			if eo.CustomInstructions != nil {
+				exercise.CustomInstructions = strings.TrimSpace(*eo.CustomInstructions)
+			}
+		}
+
+		if len(phase.Exercises) == 0 {
+			return nil, fmt.Errorf("%w: phase %d has no exercises left", repository.ErrInvalidInput, override.Phase)
+		}
+	}
+	return result, nil
+}
+
+// validatePhases checks that every exercise of a protocol is available to
+// the clinic.
+func (s *protocolService) validatePhases(ctx context.Context, clinicID string, phases []model.ProtocolPhase) error {
+	checked := map[string]bool{}
+	for i, phase := range phases {
+		for _, exercise := range phase.Exercises {
+			if checked[exercise.ExerciseID] {
+				continue
+			}
+			found, err := s.exerciseRepo.GetByID(ctx, exercise.ExerciseID)
+			if errors.Is(err, repository.ErrNotFound) || (err == nil && found.ClinicID != nil && *found.ClinicID != clinicID) {
+				return fmt.Errorf("%w: phase %d: exercise %s does not exist", repository.ErrInvalidInput, i+1, exercise.ExerciseID)
+			}
+			if err != nil {
+				return err
+			}
+			checked[exercise.ExerciseID] = true
+		}
+	}
+	return nil
+}
+
+// RunProtocolJob advances programs created from protocols every interval
+// until the context is done.
+func RunProtocolJob(ctx context.Context, protocols ProtocolService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := protocols.AdvanceDue(ctx); err != nil {
+			log.Error().Err(err).Msg("protocol job failed")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
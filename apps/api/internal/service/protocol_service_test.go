@@ -0,0 +1,77 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+)
+
+func TestPlanProgramAdvance(t *testing.T) {
+	date := func(day int) time.Time { return time.Date(2026, 10, day, 0, 0, 0, 0, time.UTC) }
+	// Two one-week phases from October 5
+	phases := func(first, second model.ProgramPhaseStatus) []model.ProgramPhase {
+		return []model.ProgramPhase{
+			{ID: "p1", StartDate: date(5), EndDate: date(12), Status: first},
+			{ID: "p2", StartDate: date(12), EndDate: date(19), Status: second},
+		}
+	}
+
+	tests := []struct {
+		name         string
+		phases       []model.ProgramPhase
+		today        time.Time
+		wantComplete []string
+		wantActivate string
+		wantFinish   bool
+	}{
+		{
+			name:   "before start",
+			phases: phases(model.ProgramPhaseUpcoming, model.ProgramPhaseUpcoming),
+			today:  date(4),
+		},
+		{
+			name:         "first day",
+			phases:       phases(model.ProgramPhaseUpcoming, model.ProgramPhaseUpcoming),
+			today:        date(5),
+			wantActivate: "p1",
+		},
+		{
+			name:   "mid phase",
+			phases: phases(model.ProgramPhaseActive, model.ProgramPhaseUpcoming),
+			today:  date(11),
+		},
+		{
+			name:         "next phase starts",
+			phases:       phases(model.ProgramPhaseActive, model.ProgramPhaseUpcoming),
+			today:        date(12),
+			wantComplete: []string{"p1"},
+			wantActivate: "p2",
+		},
+		{
+			name:         "program ends",
+			phases:       phases(model.ProgramPhaseCompleted, model.ProgramPhaseActive),
+			today:        date(19),
+			wantComplete: []string{"p2"},
+			wantFinish:   true,
+		},
+		{
+			name:         "started in the past",
+			phases:       phases(model.ProgramPhaseUpcoming, model.ProgramPhaseUpcoming),
+			today:        date(14),
+			wantComplete: []string{"p1"},
+			wantActivate: "p2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := planProgramAdvance(tt.phases, tt.today)
+			if !reflect.DeepEqual(got.Complete, tt.wantComplete) || got.Activate != tt.wantActivate || got.Finish != tt.wantFinish {
+				t.Fatalf("planProgramAdvance() = %v, %q, %v; want %v, %q, %v",
+					got.Complete, got.Activate, got.Finish, tt.wantComplete, tt.wantActivate, tt.wantFinish)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/repository"
+)
+
+// ProxyService defines the interface for caregiver proxy access.
+type ProxyService interface {
+	GrantAccess(ctx context.Context, clinicID, patientID, grantedBy string, byPatient bool, req *model.CreateProxyGrantRequest) (*model.ProxyGrant, error)
+	ListPatientGrants(ctx context.Context, clinicID, patientID string) ([]model.ProxyGrant, error)
+	ListGrantsForProxy(ctx context.Context, proxyUserID string) ([]model.ProxyGrant, error)
+	RevokeAccess(ctx context.Context, clinicID, patientID, grantID, revokedBy string) error
+	ResolveGrant(ctx context.Context, proxyUserID, patientID string) (*model.ProxyGrant, error)
+	RecordAction(ctx context.Context, entry *model.AuditLog) error
+}
+
+// proxyService implements ProxyService.
+type proxyService struct {
+	repo        repository.ProxyRepository
+	patientRepo repository.PatientRepository
+	auditRepo   repository.AuditRepository
+}
+
+// NewProxyService creates a new proxy access service.
+func NewProxyService(repo repository.ProxyRepository, patientRepo repository.PatientRepository, auditRepo repository.AuditRepository) ProxyService {
+	return &proxyService{
+		repo:        repo,
+		patientRepo: patientRepo,
+		auditRepo:   auditRepo,
+	}
+}
+
+// GrantAccess gives a caregiver scoped, time-limited access to a patient.
+func (s *proxyService) GrantAccess(ctx context.Context, clinicID, patientID, grantedBy string, byPatient bool, req *model.CreateProxyGrantRequest) (*model.ProxyGrant, error) {
+	now := time.Now()
+
+	startsAt := now
+	if req.StartsAt != "" {
+		t, err := time.Parse(time.RFC3339, req.StartsAt)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid starts_at format", repository.ErrInvalidInput)
+		}
+		startsAt = t
+	}
+
+	expiresAt, err := time.Parse(time.RFC3339, req.ExpiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid expires_at format", repository.ErrInvalidInput)
+	}
+	if !expiresAt.After(startsAt) || !expiresAt.After(now) {
+		return nil, fmt.Errorf("%w: expires_at must be in the future and after starts_at", repository.ErrInvalidInput)
+	}
+
+	// Ensure the patient exists in this clinic
+	if _, err := s.patientRepo.GetByID(ctx, clinicID, patientID); err != nil {
+		return nil, err
+	}
+
+	scopes := make([]model.ProxyScope, 0, len(req.Scopes))
+	seen := make(map[string]bool, len(req.Scopes))
+	for _, sc := range req.Scopes {
+		if !seen[sc] {
+			seen[sc] = true
+			scopes = append(scopes, model.ProxyScope(sc))
+		}
+	}
+
+	grant := &model.ProxyGrant{
+		ID:               uuid.New().String(),
+		ClinicID:         clinicID,
+		PatientID:        patientID,
+		ProxyUserID:      req.ProxyUserID,
+		ProxyName:        req.ProxyName,
+		Relationship:     req.Relationship,
+		Phone:            normalizePhone(req.Phone),
+		Scopes:           scopes,
+		StartsAt:         startsAt,
+		ExpiresAt:        expiresAt,
+		GrantedBy:        grantedBy,
+		GrantedByPatient: byPatient,
+	}
+
+	if err := s.repo.Create(ctx, grant); err != nil {
+		return nil, err
+	}
+
+	recordAudit(ctx, s.auditRepo, &model.AuditLog{
+		ClinicID:     &clinicID,
+		ActorID:      grantedBy,
+		PatientID:    &patientID,
+		ProxyGrantID: &grant.ID,
+		Action:       "proxy_grant.create",
+		ResourceType: "proxy_grant",
+		ResourceID:   grant.ID,
+		Metadata: map[string]interface{}{
+			"proxy_user_id": grant.ProxyUserID,
+			"scopes":        grant.Scopes,
+			"expires_at":    grant.ExpiresAt,
+		},
+	})
+
+	log.Info().
+		Str("grant_id", grant.ID).
+		Str("patient_id", patientID).
+		Str("proxy_user_id", grant.ProxyUserID).
+		Str("granted_by", grantedBy).
+		Msg("proxy access granted")
+
+	return grant, nil
+}
+
+// ListPatientGrants returns every grant a patient has issued.
+func (s *proxyService) ListPatientGrants(ctx context.Context, clinicID, patientID string) ([]model.ProxyGrant, error) {
+	return s.repo.ListByPatient(ctx, clinicID, patientID)
+}
+
+// ListGrantsForProxy returns the grants a caregiver currently holds.
+func (s *proxyService) ListGrantsForProxy(ctx context.Context, proxyUserID string) ([]model.ProxyGrant, error) {
+	return s.repo.ListActiveByProxyUser(ctx, proxyUserID, time.Now())
+}
+
+// RevokeAccess ends a grant immediately.
+func (s *proxyService) RevokeAccess(ctx context.Context, clinicID, patientID, grantID, revokedBy string) error {
+	grant, err := s.repo.GetByID(ctx, clinicID, grantID)
+	if err != nil {
+		return err
+	}
+	if grant.PatientID != patientID {
+		return repository.ErrNotFound
+	}
+
+	if err := s.repo.Revoke(ctx, clinicID, grantID, revokedBy, time.Now()); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.auditRepo, &model.AuditLog{
+		ClinicID:     &clinicID,
+		ActorID:      revokedBy,
+		PatientID:    &patientID,
+		ProxyGrantID: &grantID,
+		Action:       "proxy_grant.revoke",
+		ResourceType: "proxy_grant",
+		ResourceID:   grantID,
+	})
+
+	log.Info().
+		Str("grant_id", grantID).
+		Str("patient_id", patientID).
+		Str("revoked_by", revokedBy).
+		Msg("proxy access revoked")
+
+	return nil
+}
+
+// ResolveGrant returns the grant that currently lets a caregiver act for a patient,
+// or nil if there is none.
+func (s *proxyService) ResolveGrant(ctx context.Context, proxyUserID, patientID string) (*model.ProxyGrant, error) {
+	grant, err := s.repo.GetActive(ctx, proxyUserID, patientID, time.Now())
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return grant, nil
+}
+
+// RecordAction appends a proxy action to the audit trail.
+func (s *proxyService) RecordAction(ctx context.Context, entry *model.AuditLog) error {
+	if entry.ID == "" {
+		entry.ID = uuid.New().String()
+	}
+	return s.auditRepo.Create(ctx, entry)
+}
@@ -3,6 +3,9 @@ package service
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/tqvdang/physioflow/apps/api/internal/model"
@@ -36,25 +39,59 @@ type ROMDelta struct {
 
 // QuickScheduleResult represents the result of a quick schedule operation.
 type QuickScheduleResult struct {
-	ID          string `json:"id"`
-	PatientID   string `json:"patient_id"`
-	TherapistID string `json:"therapist_id"`
-	Date        string `json:"date"`
-	TimeSlot    string `json:"time_slot"`
-	Duration    int    `json:"duration"`
-	Status      string `json:"status"`
-	Notes       string `json:"notes,omitempty"`
-	CreatedAt   string `json:"created_at"`
+	ID          string    `json:"id"`
+	PatientID   string    `json:"patient_id"`
+	TherapistID string    `json:"therapist_id"`
+	Date        string    `json:"date"`
+	TimeSlot    string    `json:"time_slot"`
+	StartTime   time.Time `json:"start_time"` // Date and TimeSlot at the clinic, with its UTC offset
+	EndTime     time.Time `json:"end_time"`
+	Timezone    string    `json:"timezone"`
+	Duration    int       `json:"duration"`
+	Status      string    `json:"status"`
+	Notes       string    `json:"notes,omitempty"`
+	CreatedAt   string    `json:"created_at"`
+
+	// Snapped is set when the requested slot was taken and the nearest free
+	// slot was booked instead.
+	Snapped            bool      `json:"snapped"`
+	RequestedStartTime time.Time `json:"requested_start_time"`
+}
+
+// Quick scheduling settings
+const (
+	quickScheduleSearchDays   = 3   // days either side of the requested day searched for free slots
+	quickScheduleDayPenalty   = 120 // minutes a slot one day away counts as when ranking
+	quickScheduleAlternatives = 5
+	quickScheduleHistory      = 20 // recent appointments searched for the default time of day
+)
+
+// SlotUnavailableError is returned when the requested quick schedule slot is
+// not free. Alternatives lists the therapist's nearest free slots, best first.
+type SlotUnavailableError struct {
+	Requested    time.Time
+	Alternatives []model.AvailabilitySlot
+}
+
+func (e *SlotUnavailableError) Error() string {
+	return fmt.Sprintf("scheduling conflict: %s is not available", e.Requested.Format("2006-01-02 15:04"))
+}
+
+// Unwrap lets errors.Is match the error against repository.ErrConflict.
+func (e *SlotUnavailableError) Unwrap() error {
+	return repository.ErrConflict
 }
 
 // quickActionsService implements QuickActionsService.
 type quickActionsService struct {
-	repo *repository.Repository
+	repo         *repository.Repository
+	appointments AppointmentService
 }
 
-// newQuickActionsService creates a new QuickActionsService.
-func newQuickActionsService(repo *repository.Repository) *quickActionsService {
-	return &quickActionsService{repo: repo}
+// newQuickActionsService creates a new QuickActionsService. Quick scheduling
+// books through the appointment service.
+func newQuickActionsService(repo *repository.Repository, appointments AppointmentService) *quickActionsService {
+	return &quickActionsService{repo: repo, appointments: appointments}
 }
 
 // RecordPain records a pain measurement and calculates delta.
@@ -167,32 +204,214 @@ func (s *quickActionsService) GetROMHistory(ctx context.Context, patientID, join
 	return s.repo.QuickActions().GetROMHistory(ctx, patientID, joint, limit)
 }
 
-// QuickSchedule creates a quick appointment.
+// QuickSchedule books the patient's next visit through the appointment
+// service, so working hours, schedule exceptions and conflicts are checked as
+// for any other booking. The visit day and time are read on the clinic's wall
+// clock. When the requested slot is not free, the nearest free slots of the
+// same therapist are returned in a SlotUnavailableError, or the nearest one is
+// booked if req.Snap is set.
 func (s *quickActionsService) QuickSchedule(ctx context.Context, req model.QuickScheduleRequest) (*QuickScheduleResult, error) {
-	// Check for scheduling conflicts
-	available, err := s.repo.QuickActions().CheckTimeSlotAvailable(ctx, req.ClinicID, req.TherapistID, req.Date, req.TimeSlot, req.Duration)
+	loc, err := clinicLocation(ctx, s.repo.Clinic(), req.ClinicID)
+	if err != nil {
+		return nil, err
+	}
+
+	day, err := quickScheduleDay(req, loc)
+	if err != nil {
+		return nil, err
+	}
+	if day.Before(todayIn(loc)) {
+		return nil, fmt.Errorf("%w: the visit day is in the past", repository.ErrInvalidInput)
+	}
+
+	timeSlot, err := s.quickScheduleTime(ctx, req, loc)
+	if err != nil {
+		return nil, err
+	}
+	clock, err := time.Parse("15:04", timeSlot)
 	if err != nil {
-		return nil, fmt.Errorf("failed to check availability: %w", err)
+		return nil, fmt.Errorf("%w: time_slot must be HH:MM", repository.ErrInvalidInput)
 	}
-	if !available {
-		return nil, fmt.Errorf("time slot not available")
+	requested := time.Date(day.Year(), day.Month(), day.Day(), clock.Hour(), clock.Minute(), 0, 0, loc)
+
+	slots, err := s.nearbySlots(ctx, req, requested, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	var chosen *model.AvailabilitySlot
+	for i := range slots {
+		if slots[i].StartTime.Equal(requested) {
+			chosen = &slots[i]
+			break
+		}
+	}
+	if chosen == nil {
+		ranked := rankQuickScheduleSlots(slots, requested)
+		if !req.Snap || len(ranked) == 0 {
+			if len(ranked) > quickScheduleAlternatives {
+				ranked = ranked[:quickScheduleAlternatives]
+			}
+			return nil, &SlotUnavailableError{Requested: requested, Alternatives: ranked}
+		}
+		chosen = &ranked[0]
 	}
 
-	// Create the appointment
-	id, err := s.repo.QuickActions().CreateAppointment(ctx, req)
+	createReq := &model.CreateAppointmentRequest{
+		PatientID:         req.PatientID,
+		TherapistID:       req.TherapistID,
+		StartTime:         chosen.StartTime.Format(time.RFC3339),
+		Duration:          req.Duration,
+		Type:              string(model.AppointmentTypeTreatment),
+		AppointmentTypeID: req.AppointmentTypeID,
+		Notes:             req.Notes,
+	}
+	appointment, err := s.appointments.Create(ctx, req.ClinicID, req.UserID, createReq)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create appointment: %w", err)
+		return nil, err
 	}
 
+	start := appointment.StartTime.In(loc)
 	return &QuickScheduleResult{
-		ID:          id,
-		PatientID:   req.PatientID,
-		TherapistID: req.TherapistID,
-		Date:        req.Date,
-		TimeSlot:    req.TimeSlot,
-		Duration:    req.Duration,
-		Status:      "scheduled",
-		Notes:       req.Notes,
-		CreatedAt:   time.Now().Format("2006-01-02T15:04:05Z07:00"),
+		ID:                 appointment.ID,
+		PatientID:          appointment.PatientID,
+		TherapistID:        appointment.TherapistID,
+		Date:               start.Format("2006-01-02"),
+		TimeSlot:           start.Format("15:04"),
+		StartTime:          start,
+		EndTime:            appointment.EndTime.In(loc),
+		Timezone:           loc.String(),
+		Duration:           appointment.Duration,
+		Status:             string(appointment.Status),
+		Notes:              appointment.Notes,
+		Snapped:            !start.Equal(requested),
+		RequestedStartTime: requested,
+		CreatedAt:          appointment.CreatedAt.In(loc).Format("2006-01-02T15:04:05Z07:00"),
 	}, nil
 }
+
+// quickScheduleDay returns the start of the requested visit day at the clinic.
+func quickScheduleDay(req model.QuickScheduleRequest, loc *time.Location) (time.Time, error) {
+	if req.Date != "" {
+		date, err := time.Parse("2006-01-02", req.Date)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%w: date must be YYYY-MM-DD", repository.ErrInvalidInput)
+		}
+		return dayIn(date, loc), nil
+	}
+
+	months, days, err := parseScheduleOffset(req.Offset)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return todayIn(loc).AddDate(0, months, days), nil
+}
+
+// parseScheduleOffset parses a relative visit day such as "+3 days",
+// "+1 week", "2w" or "+1 month" into months and days.
+func parseScheduleOffset(offset string) (months, days int, err error) {
+	invalid := fmt.Errorf("%w: offset must look like \"+3 days\", \"+1 week\" or \"+1 month\"", repository.ErrInvalidInput)
+
+	s := strings.ToLower(strings.TrimSpace(offset))
+	s = strings.TrimPrefix(s, "+")
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	if i == 0 || i > 3 {
+		return 0, 0, invalid
+	}
+	n, _ := strconv.Atoi(s[:i])
+
+	switch strings.TrimSpace(s[i:]) {
+	case "d", "day", "days":
+		return 0, n, nil
+	case "w", "week", "weeks":
+		return 0, 7 * n, nil
+	case "m", "month", "months":
+		return n, 0, nil
+	}
+	return 0, 0, invalid
+}
+
+// quickScheduleTime returns the requested time of day, defaulting to the time
+// of the patient's most recent visit so the next one keeps the same routine.
+func (s *quickActionsService) quickScheduleTime(ctx context.Context, req model.QuickScheduleRequest, loc *time.Location) (string, error) {
+	if req.TimeSlot != "" {
+		return req.TimeSlot, nil
+	}
+
+	recent, err := s.appointments.GetByPatient(ctx, req.ClinicID, req.PatientID, quickScheduleHistory)
+	if err != nil {
+		return "", fmt.Errorf("failed to get previous visits: %w", err)
+	}
+	now := time.Now()
+	for _, a := range recent {
+		if a.StartTime.After(now) || a.Status == model.AppointmentStatusCancelled || a.Status == model.AppointmentStatusNoShow {
+			continue
+		}
+		return a.StartTime.In(loc).Format("15:04"), nil
+	}
+	return "", fmt.Errorf("%w: time_slot is required for a patient without previous visits", repository.ErrInvalidInput)
+}
+
+// nearbySlots returns the therapist's free slots on the days around the
+// requested one, leaving out days before today. The whole range is loaded in
+// one batch.
+func (s *quickActionsService) nearbySlots(ctx context.Context, req model.QuickScheduleRequest, requested time.Time, loc *time.Location) ([]model.AvailabilitySlot, error) {
+	day := dayIn(requested, loc)
+	from := day.AddDate(0, 0, -quickScheduleSearchDays)
+	if today := todayIn(loc); from.Before(today) {
+		from = today
+	}
+	to := day.AddDate(0, 0, quickScheduleSearchDays+1)
+
+	appointments := s.repo.Appointment()
+	slots, err := appointments.FindAvailableSlots(ctx, req.ClinicID, []string{req.TherapistID}, from, to, req.Duration, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get available slots: %w", err)
+	}
+
+	therapists, _ := appointments.GetTherapists(ctx, req.ClinicID)
+	for _, t := range therapists {
+		if t.ID == req.TherapistID {
+			for i := range slots {
+				slots[i].TherapistName = t.FullName
+			}
+			break
+		}
+	}
+	return slots, nil
+}
+
+// rankQuickScheduleSlots orders free slots by how close they are to the
+// requested visit: the difference in time of day plus a penalty for each day
+// away, so the same time on the next day is preferred over a slot hours later
+// on the requested day. Ties go to the earlier slot.
+func rankQuickScheduleSlots(slots []model.AvailabilitySlot, requested time.Time) []model.AvailabilitySlot {
+	loc := requested.Location()
+	score := func(slot model.AvailabilitySlot) int {
+		start := slot.StartTime.In(loc)
+		minutes := abs((start.Hour()*60 + start.Minute()) - (requested.Hour()*60 + requested.Minute()))
+		return minutes + quickScheduleDayPenalty*abs(daysBetween(requested, start))
+	}
+
+	ranked := make([]model.AvailabilitySlot, len(slots))
+	copy(ranked, slots)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		si, sj := score(ranked[i]), score(ranked[j])
+		if si != sj {
+			return si < sj
+		}
+		return ranked[i].StartTime.Before(ranked[j].StartTime)
+	})
+	return ranked
+}
+
+// abs returns the absolute value of n.
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/repository"
+)
+
+// ResourceService defines the interface for managing clinic rooms and equipment.
+type ResourceService interface {
+	List(ctx context.Context, clinicID string, resourceType model.ResourceType, activeOnly bool) ([]model.Resource, error)
+	Get(ctx context.Context, clinicID, id string) (*model.Resource, error)
+	Create(ctx context.Context, clinicID, userID string, req *model.CreateResourceRequest) (*model.Resource, error)
+	Update(ctx context.Context, clinicID, id, userID string, req *model.UpdateResourceRequest) (*model.Resource, error)
+	Delete(ctx context.Context, clinicID, id, userID string) error
+	GetDayView(ctx context.Context, clinicID string, date time.Time, resourceType model.ResourceType) (*model.ResourceDayView, error)
+}
+
+// resourceService implements ResourceService.
+type resourceService struct {
+	repo            repository.ResourceRepository
+	appointmentRepo repository.AppointmentRepository
+	clinicRepo      repository.ClinicRepository
+}
+
+// NewResourceService creates a new resource service.
+func NewResourceService(repo repository.ResourceRepository, appointmentRepo repository.AppointmentRepository, clinicRepo repository.ClinicRepository) ResourceService {
+	return &resourceService{
+		repo:            repo,
+		appointmentRepo: appointmentRepo,
+		clinicRepo:      clinicRepo,
+	}
+}
+
+// List returns a clinic's resources, optionally of one type.
+func (s *resourceService) List(ctx context.Context, clinicID string, resourceType model.ResourceType, activeOnly bool) ([]model.Resource, error) {
+	return s.repo.List(ctx, clinicID, resourceType, activeOnly)
+}
+
+// Get returns a resource by ID.
+func (s *resourceService) Get(ctx context.Context, clinicID, id string) (*model.Resource, error) {
+	return s.repo.GetByID(ctx, clinicID, id)
+}
+
+// Create adds a room or piece of equipment to a clinic.
+func (s *resourceService) Create(ctx context.Context, clinicID, userID string, req *model.CreateResourceRequest) (*model.Resource, error) {
+	capacity := req.Capacity
+	if capacity == 0 {
+		capacity = 1
+	}
+
+	resource := &model.Resource{
+		ID:          uuid.New().String(),
+		ClinicID:    clinicID,
+		Name:        req.Name,
+		NameVi:      req.NameVi,
+		Type:        model.ResourceType(req.Type),
+		Capacity:    capacity,
+		Description: req.Description,
+		IsActive:    true,
+	}
+
+	if err := s.repo.Create(ctx, resource); err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("resource_id", resource.ID).
+		Str("resource_type", string(resource.Type)).
+		Int("capacity", resource.Capacity).
+		Str("clinic_id", clinicID).
+		Str("created_by", userID).
+		Msg("resource created")
+
+	return resource, nil
+}
+
+// Update changes a resource. Lowering the capacity or deactivating a resource
+// does not affect appointments that are already booked.
+func (s *resourceService) Update(ctx context.Context, clinicID, id, userID string, req *model.UpdateResourceRequest) (*model.Resource, error) {
+	resource, err := s.repo.GetByID(ctx, clinicID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		resource.Name = *req.Name
+	}
+	if req.NameVi != nil {
+		resource.NameVi = *req.NameVi
+	}
+	if req.Capacity != nil {
+		resource.Capacity = *req.Capacity
+	}
+	if req.Description != nil {
+		resource.Description = *req.Description
+	}
+	if req.IsActive != nil {
+		resource.IsActive = *req.IsActive
+	}
+
+	if err := s.repo.Update(ctx, resource); err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("resource_id", id).
+		Str("clinic_id", clinicID).
+		Str("updated_by", userID).
+		Msg("resource updated")
+
+	return resource, nil
+}
+
+// Delete removes a resource that has no upcoming bookings. Resources with
+// upcoming bookings must be deactivated instead.
+func (s *resourceService) Delete(ctx context.Context, clinicID, id, userID string) error {
+	if _, err := s.repo.GetByID(ctx, clinicID, id); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	upcoming, err := s.appointmentRepo.FindResourceBookings(ctx, clinicID, []string{id}, now, now.AddDate(100, 0, 0), nil)
+	if err != nil {
+		return err
+	}
+	if len(upcoming) > 0 {
+		return fmt.Errorf("%w: resource has %d upcoming appointment(s); deactivate it instead", repository.ErrInvalidInput, len(upcoming))
+	}
+
+	if err := s.repo.Delete(ctx, clinicID, id); err != nil {
+		return err
+	}
+
+	log.Info().
+		Str("resource_id", id).
+		Str("clinic_id", clinicID).
+		Str("deleted_by", userID).
+		Msg("resource deleted")
+
+	return nil
+}
+
+// GetDayView returns the bookings of each active resource of a clinic on a
+// day, optionally limited to one resource type. Cancelled appointments and
+// no-shows do not occupy a resource and are left out.
+func (s *resourceService) GetDayView(ctx context.Context, clinicID string, date time.Time, resourceType model.ResourceType) (*model.ResourceDayView, error) {
+	resources, err := s.repo.List(ctx, clinicID, resourceType, true)
+	if err != nil {
+		return nil, err
+	}
+
+	loc, err := clinicLocation(ctx, s.clinicRepo, clinicID)
+	if err != nil {
+		return nil, err
+	}
+	start, end := dayBounds(date, loc)
+	appointments, err := s.appointmentRepo.GetByDateRange(ctx, clinicID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	appointmentsIn(appointments, loc)
+
+	view := &model.ResourceDayView{
+		Date:      start.Format("2006-01-02"),
+		Resources: make([]model.ResourceDaySchedule, 0, len(resources)),
+	}
+	for _, res := range resources {
+		day := model.ResourceDaySchedule{
+			Resource:     res,
+			Appointments: make([]model.AppointmentWithDetails, 0),
+		}
+		for _, a := range appointments {
+			if !a.UsesResource(res.ID) || a.Status == model.AppointmentStatusCancelled || a.Status == model.AppointmentStatusNoShow {
+				continue
+			}
+			day.Appointments = append(day.Appointments, a)
+			day.BookedMinutes += a.Duration
+		}
+		view.Resources = append(view.Resources, day)
+	}
+
+	return view, nil
+}
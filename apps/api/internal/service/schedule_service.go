@@ -0,0 +1,402 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/repository"
+)
+
+// maxAppointmentDuration is the longest appointment that can be booked. It
+// bounds how far before a window an overlapping appointment can start.
+const maxAppointmentDuration = 240 * time.Minute
+
+// AffectedAppointmentsError is returned when a schedule exception would leave
+// booked appointments outside the therapist's working time and the change was
+// not acknowledged.
+type AffectedAppointmentsError struct {
+	Appointments []model.AppointmentWithDetails
+}
+
+func (e *AffectedAppointmentsError) Error() string {
+	return fmt.Sprintf("scheduling conflict: exception overlaps %d booked appointment(s)", len(e.Appointments))
+}
+
+// Unwrap lets errors.Is match the error against repository.ErrConflict.
+func (e *AffectedAppointmentsError) Unwrap() error {
+	return repository.ErrConflict
+}
+
+// ScheduleService defines the interface for managing therapist working hours
+// and schedule exceptions.
+type ScheduleService interface {
+	ListSchedules(ctx context.Context, clinicID, therapistID string) ([]model.TherapistSchedule, error)
+	CreateSchedule(ctx context.Context, clinicID, therapistID, userID string, req *model.CreateScheduleRequest) (*model.TherapistSchedule, error)
+	UpdateSchedule(ctx context.Context, clinicID, therapistID, id string, req *model.UpdateScheduleRequest) (*model.TherapistSchedule, error)
+	DeleteSchedule(ctx context.Context, clinicID, therapistID, id string) error
+	ListExceptions(ctx context.Context, clinicID, therapistID string, start, end time.Time) ([]model.ScheduleException, error)
+	CreateException(ctx context.Context, clinicID, therapistID, userID string, req *model.CreateScheduleExceptionRequest) (*model.ScheduleExceptionResult, error)
+	UpdateException(ctx context.Context, clinicID, therapistID, id string, req *model.UpdateScheduleExceptionRequest) (*model.ScheduleExceptionResult, error)
+	DeleteException(ctx context.Context, clinicID, therapistID, id string) error
+}
+
+// scheduleService implements ScheduleService.
+type scheduleService struct {
+	repo            repository.ScheduleRepository
+	appointmentRepo repository.AppointmentRepository
+}
+
+// NewScheduleService creates a new schedule service.
+func NewScheduleService(repo repository.ScheduleRepository, appointmentRepo repository.AppointmentRepository) ScheduleService {
+	return &scheduleService{
+		repo:            repo,
+		appointmentRepo: appointmentRepo,
+	}
+}
+
+// ListSchedules returns every weekly working period of a therapist.
+func (s *scheduleService) ListSchedules(ctx context.Context, clinicID, therapistID string) ([]model.TherapistSchedule, error) {
+	return s.repo.ListSchedules(ctx, clinicID, therapistID)
+}
+
+// CreateSchedule adds a weekly working period to a therapist's schedule.
+func (s *scheduleService) CreateSchedule(ctx context.Context, clinicID, therapistID, userID string, req *model.CreateScheduleRequest) (*model.TherapistSchedule, error) {
+	effectiveFrom, _ := time.Parse("2006-01-02", req.EffectiveFrom)
+
+	schedule := &model.TherapistSchedule{
+		ID:            uuid.New().String(),
+		ClinicID:      clinicID,
+		TherapistID:   therapistID,
+		DayOfWeek:     *req.DayOfWeek,
+		StartTime:     req.StartTime,
+		EndTime:       req.EndTime,
+		EffectiveFrom: effectiveFrom,
+		IsActive:      true,
+		CreatedBy:     &userID,
+	}
+	if req.EffectiveTo != nil {
+		effectiveTo, _ := time.Parse("2006-01-02", *req.EffectiveTo)
+		schedule.EffectiveTo = &effectiveTo
+	}
+
+	if err := s.validateSchedule(ctx, schedule); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateSchedule(ctx, schedule); err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("schedule_id", schedule.ID).
+		Str("therapist_id", therapistID).
+		Int("day_of_week", schedule.DayOfWeek).
+		Msg("therapist schedule created")
+
+	return schedule, nil
+}
+
+// UpdateSchedule changes a weekly working period.
+func (s *scheduleService) UpdateSchedule(ctx context.Context, clinicID, therapistID, id string, req *model.UpdateScheduleRequest) (*model.TherapistSchedule, error) {
+	schedule, err := s.getSchedule(ctx, clinicID, therapistID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.DayOfWeek != nil {
+		schedule.DayOfWeek = *req.DayOfWeek
+	}
+	if req.StartTime != nil {
+		schedule.StartTime = *req.StartTime
+	}
+	if req.EndTime != nil {
+		schedule.EndTime = *req.EndTime
+	}
+	if req.EffectiveFrom != nil {
+		schedule.EffectiveFrom, _ = time.Parse("2006-01-02", *req.EffectiveFrom)
+	}
+	if req.Ongoing {
+		schedule.EffectiveTo = nil
+	} else if req.EffectiveTo != nil {
+		effectiveTo, _ := time.Parse("2006-01-02", *req.EffectiveTo)
+		schedule.EffectiveTo = &effectiveTo
+	}
+	if req.IsActive != nil {
+		schedule.IsActive = *req.IsActive
+	}
+
+	if err := s.validateSchedule(ctx, schedule); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateSchedule(ctx, schedule); err != nil {
+		return nil, err
+	}
+
+	return schedule, nil
+}
+
+// DeleteSchedule removes a weekly working period.
+func (s *scheduleService) DeleteSchedule(ctx context.Context, clinicID, therapistID, id string) error {
+	if _, err := s.getSchedule(ctx, clinicID, therapistID, id); err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteSchedule(ctx, clinicID, id); err != nil {
+		return err
+	}
+
+	log.Info().
+		Str("schedule_id", id).
+		Str("therapist_id", therapistID).
+		Msg("therapist schedule deleted")
+
+	return nil
+}
+
+// ListExceptions returns a therapist's schedule exceptions dated within [start, end).
+func (s *scheduleService) ListExceptions(ctx context.Context, clinicID, therapistID string, start, end time.Time) ([]model.ScheduleException, error) {
+	return s.repo.ListExceptions(ctx, clinicID, therapistID, start, end)
+}
+
+// CreateException records leave, training, a holiday or an extra shift. A
+// blocking exception that overlaps booked appointments is only saved when the
+// caller acknowledges the affected appointments.
+func (s *scheduleService) CreateException(ctx context.Context, clinicID, therapistID, userID string, req *model.CreateScheduleExceptionRequest) (*model.ScheduleExceptionResult, error) {
+	date, _ := time.Parse("2006-01-02", req.Date)
+	exceptionType := model.ExceptionType(req.ExceptionType)
+
+	exception := &model.ScheduleException{
+		ID:          uuid.New().String(),
+		ClinicID:    clinicID,
+		TherapistID: therapistID,
+		Date:        date,
+		StartTime:   req.StartTime,
+		EndTime:     req.EndTime,
+		Type:        exceptionType,
+		IsAvailable: exceptionType.IsAvailable(),
+		Reason:      strings.TrimSpace(req.Reason),
+		Notes:       strings.TrimSpace(req.Notes),
+		CreatedBy:   &userID,
+	}
+
+	affected, err := s.checkException(ctx, exception, req.AcknowledgeAffected)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateException(ctx, exception); err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("exception_id", exception.ID).
+		Str("therapist_id", therapistID).
+		Str("exception_type", string(exception.Type)).
+		Str("date", req.Date).
+		Int("affected_appointments", len(affected)).
+		Msg("schedule exception created")
+
+	return &model.ScheduleExceptionResult{
+		Exception:            exception,
+		AffectedAppointments: affected,
+	}, nil
+}
+
+// UpdateException changes a schedule exception. Appointments affected by the
+// changed exception must be acknowledged as on create.
+func (s *scheduleService) UpdateException(ctx context.Context, clinicID, therapistID, id string, req *model.UpdateScheduleExceptionRequest) (*model.ScheduleExceptionResult, error) {
+	exception, err := s.getException(ctx, clinicID, therapistID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Date != nil {
+		exception.Date, _ = time.Parse("2006-01-02", *req.Date)
+	}
+	if req.AllDay {
+		exception.StartTime = nil
+		exception.EndTime = nil
+	} else {
+		if req.StartTime != nil {
+			exception.StartTime = req.StartTime
+		}
+		if req.EndTime != nil {
+			exception.EndTime = req.EndTime
+		}
+	}
+	if req.ExceptionType != nil {
+		exception.Type = model.ExceptionType(*req.ExceptionType)
+		exception.IsAvailable = exception.Type.IsAvailable()
+	}
+	if req.Reason != nil {
+		exception.Reason = strings.TrimSpace(*req.Reason)
+	}
+	if req.Notes != nil {
+		exception.Notes = strings.TrimSpace(*req.Notes)
+	}
+
+	affected, err := s.checkException(ctx, exception, req.AcknowledgeAffected)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateException(ctx, exception); err != nil {
+		return nil, err
+	}
+
+	return &model.ScheduleExceptionResult{
+		Exception:            exception,
+		AffectedAppointments: affected,
+	}, nil
+}
+
+// DeleteException removes a schedule exception.
+func (s *scheduleService) DeleteException(ctx context.Context, clinicID, therapistID, id string) error {
+	if _, err := s.getException(ctx, clinicID, therapistID, id); err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteException(ctx, clinicID, id); err != nil {
+		return err
+	}
+
+	log.Info().
+		Str("exception_id", id).
+		Str("therapist_id", therapistID).
+		Msg("schedule exception deleted")
+
+	return nil
+}
+
+// getSchedule loads a weekly working period and checks it belongs to the therapist.
+func (s *scheduleService) getSchedule(ctx context.Context, clinicID, therapistID, id string) (*model.TherapistSchedule, error) {
+	schedule, err := s.repo.GetSchedule(ctx, clinicID, id)
+	if err != nil {
+		return nil, err
+	}
+	if schedule.TherapistID != therapistID {
+		return nil, repository.ErrNotFound
+	}
+	return schedule, nil
+}
+
+// getException loads a schedule exception and checks it belongs to the therapist.
+func (s *scheduleService) getException(ctx context.Context, clinicID, therapistID, id string) (*model.ScheduleException, error) {
+	exception, err := s.repo.GetException(ctx, clinicID, id)
+	if err != nil {
+		return nil, err
+	}
+	if exception.TherapistID != therapistID {
+		return nil, repository.ErrNotFound
+	}
+	return exception, nil
+}
+
+// validateSchedule checks a working period's times and dates and that it does
+// not overlap another active period of the same therapist on the same weekday.
+func (s *scheduleService) validateSchedule(ctx context.Context, schedule *model.TherapistSchedule) error {
+	schedule.StartTime = normalizeClock(schedule.StartTime)
+	schedule.EndTime = normalizeClock(schedule.EndTime)
+	if schedule.EndTime <= schedule.StartTime {
+		return fmt.Errorf("%w: end_time must be after start_time", repository.ErrInvalidInput)
+	}
+	if schedule.EffectiveTo != nil && schedule.EffectiveTo.Before(schedule.EffectiveFrom) {
+		return fmt.Errorf("%w: effective_to must not be before effective_from", repository.ErrInvalidInput)
+	}
+	if !schedule.IsActive {
+		return nil
+	}
+
+	existing, err := s.repo.ListSchedules(ctx, schedule.ClinicID, schedule.TherapistID)
+	if err != nil {
+		return err
+	}
+
+	for _, other := range existing {
+		if other.ID == schedule.ID || !other.IsActive || other.DayOfWeek != schedule.DayOfWeek {
+			continue
+		}
+		if other.StartTime >= schedule.EndTime || other.EndTime <= schedule.StartTime {
+			continue
+		}
+		if !effectiveRangesOverlap(schedule, &other) {
+			continue
+		}
+		return fmt.Errorf("%w: overlaps the working period %s-%s effective from %s",
+			repository.ErrInvalidInput, other.StartTime, other.EndTime, other.EffectiveFrom.Format("2006-01-02"))
+	}
+
+	return nil
+}
+
+// effectiveRangesOverlap reports whether two working periods are in effect on
+// at least one common date.
+func effectiveRangesOverlap(a, b *model.TherapistSchedule) bool {
+	if a.EffectiveTo != nil && a.EffectiveTo.Before(b.EffectiveFrom) {
+		return false
+	}
+	if b.EffectiveTo != nil && b.EffectiveTo.Before(a.EffectiveFrom) {
+		return false
+	}
+	return true
+}
+
+// checkException validates an exception and returns the open appointments it
+// leaves outside the therapist's working time. Unless acknowledged, affected
+// appointments are reported as an AffectedAppointmentsError.
+func (s *scheduleService) checkException(ctx context.Context, exception *model.ScheduleException, acknowledged bool) ([]model.AppointmentWithDetails, error) {
+	if (exception.StartTime == nil) != (exception.EndTime == nil) {
+		return nil, fmt.Errorf("%w: start_time and end_time must be given together", repository.ErrInvalidInput)
+	}
+	if exception.StartTime != nil {
+		start, end := normalizeClock(*exception.StartTime), normalizeClock(*exception.EndTime)
+		exception.StartTime, exception.EndTime = &start, &end
+	}
+	if exception.StartTime != nil && *exception.EndTime <= *exception.StartTime {
+		return nil, fmt.Errorf("%w: end_time must be after start_time", repository.ErrInvalidInput)
+	}
+	if exception.IsAvailable && exception.StartTime == nil {
+		return nil, fmt.Errorf("%w: an extra shift needs start_time and end_time", repository.ErrInvalidInput)
+	}
+
+	affected := make([]model.AppointmentWithDetails, 0)
+	if exception.IsAvailable {
+		return affected, nil
+	}
+
+	window := exception.Window(exception.Date.Location())
+	appointments, err := s.appointmentRepo.GetByTherapist(ctx, exception.ClinicID, exception.TherapistID,
+		window.Start.Add(-maxAppointmentDuration), window.End)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range appointments {
+		if isOpenAppointment(a.Status) && a.StartTime.Before(window.End) && a.EndTime.After(window.Start) {
+			affected = append(affected, a)
+		}
+	}
+
+	if len(affected) > 0 && !acknowledged {
+		return nil, &AffectedAppointmentsError{Appointments: affected}
+	}
+
+	return affected, nil
+}
+
+// normalizeClock rewrites a clock time such as "8:00" as "08:00", so that
+// clock times compare correctly as strings.
+func normalizeClock(clock string) string {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return clock
+	}
+	return t.Format("15:04")
+}
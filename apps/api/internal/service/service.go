@@ -1,27 +1,69 @@
 package service
 
 import (
+	"github.com/tqvdang/physioflow/apps/api/internal/media"
 	"github.com/tqvdang/physioflow/apps/api/internal/repository"
 )
 
 // Service provides business logic operations.
 type Service struct {
-	repo         *repository.Repository
-	patient      PatientService
-	checklist    ChecklistService
-	quickActions QuickActionsService
-	appointment  AppointmentService
-	exercise     ExerciseService
+	repo                   *repository.Repository
+	patient                PatientService
+	checklist              ChecklistService
+	quickActions           QuickActionsService
+	appointment            AppointmentService
+	exercise               ExerciseService
+	exerciseMedia          ExerciseMediaService
+	exerciseLibrary        ExerciseLibraryService
+	exerciseRecommendation ExerciseRecommendationService
+	progression            ProgressionService
+	protocol               ProtocolService
+	adherence              AdherenceService
+	timeline               TimelineService
+	portal                 PortalService
+	proxy                  ProxyService
+	consent                ConsentService
+	attachment             AttachmentService
+	export                 ExportService
+	schedule               ScheduleService
+	resource               ResourceService
+	groupSession           GroupSessionService
+	appointmentType        AppointmentTypeService
+	attendance             AttendanceService
+	calendar               CalendarService
+	statusBoard            StatusBoardService
+	kiosk                  KioskService
 }
 
 // New creates a new Service instance.
 func New(repo *repository.Repository) *Service {
 	svc := &Service{repo: repo}
-	svc.patient = NewPatientService(repo.Patient(), repo.Clinic())
-	svc.checklist = newChecklistService(repo)
-	svc.quickActions = newQuickActionsService(repo)
-	svc.appointment = NewAppointmentService(repo.Appointment())
-	svc.exercise = NewExerciseService(repo.Exercise(), repo.Patient())
+	svc.statusBoard = NewStatusBoardService(repo.StatusBoard(), repo.Appointment(), repo.Clinic(), repo.Events(), repo.Config().StatusBoard.StreamSecret)
+	svc.checklist = newChecklistService(repo, svc.statusBoard)
+	svc.attendance = NewAttendanceService(repo.Appointment(), repo.Clinic(), svc.statusBoard)
+	svc.patient = NewPatientService(repo.Patient(), repo.Clinic(), svc.attendance)
+	svc.appointment = NewAppointmentService(repo.Appointment(), repo.Resource(), repo.GroupSession(), repo.AppointmentType(), repo.Clinic(), svc.attendance, svc.statusBoard)
+	svc.quickActions = newQuickActionsService(repo, svc.appointment)
+	svc.schedule = NewScheduleService(repo.Schedule(), repo.Appointment())
+	svc.resource = NewResourceService(repo.Resource(), repo.Appointment(), repo.Clinic())
+	svc.appointmentType = NewAppointmentTypeService(repo.AppointmentType())
+	svc.groupSession = NewGroupSessionService(repo.GroupSession(), repo.AppointmentType(), repo.Appointment(), repo.Resource(), repo.Clinic(), svc.statusBoard)
+	prober := media.NewProber(repo.Config().Storage.FFprobe, repo.Config().Storage.FFmpeg)
+	svc.exerciseMedia = NewExerciseMediaService(repo.ExerciseMedia(), repo.Exercise(), repo.Blobs(), prober)
+	svc.exercise = NewExerciseService(repo.Exercise(), repo.Patient(), repo.Progression(), svc.exerciseMedia)
+	svc.exerciseLibrary = NewExerciseLibraryService(repo.Exercise(), repo.ExerciseMedia(), repo.Clinic(), svc.exercise, svc.exerciseMedia)
+	svc.exerciseRecommendation = NewExerciseRecommendationService(repo.Exercise(), repo.Patient(), repo.QuickActions(), repo.ChecklistTemplate(), repo.VisitChecklist())
+	svc.progression = NewProgressionService(repo.Progression(), repo.Exercise(), repo.Clinic())
+	svc.protocol = NewProtocolService(repo.Protocol(), repo.Exercise(), repo.Patient(), repo.Clinic())
+	svc.timeline = NewTimelineService(repo.Timeline(), repo.Patient())
+	svc.proxy = NewProxyService(repo.Proxy(), repo.Patient(), repo.Audit())
+	svc.consent = NewConsentService(repo.Consent(), repo.Patient(), repo.Audit())
+	svc.adherence = NewAdherenceService(repo.Adherence(), repo.Patient(), repo.Clinic(), svc.consent, repo.Notifier())
+	svc.attachment = NewAttachmentService(repo.Attachment(), repo.Patient(), repo.Audit(), repo.Blobs(), svc.consent)
+	svc.export = NewExportService(repo, svc.consent)
+	svc.calendar = NewCalendarService(repo.CalendarFeed(), repo.Appointment(), repo.GroupSession(), repo.Patient(), repo.Clinic(), svc.appointmentType)
+	svc.kiosk = NewKioskService(repo.Kiosk(), repo.Appointment(), repo.Patient(), repo.Clinic(), repo.StatusBoard(), svc.statusBoard, repo.Config().CheckIn.TokenSecret)
+	svc.portal = NewPortalService(repo, svc.appointment, svc.attendance, svc.exercise, svc.proxy, svc.consent, svc.export, svc.calendar, svc.kiosk)
 	return svc
 }
 
@@ -45,11 +87,111 @@ func (s *Service) Appointment() AppointmentService {
 	return s.appointment
 }
 
+// Schedule returns the therapist schedule service.
+func (s *Service) Schedule() ScheduleService {
+	return s.schedule
+}
+
+// Resource returns the clinic room and equipment service.
+func (s *Service) Resource() ResourceService {
+	return s.resource
+}
+
+// GroupSession returns the group class service.
+func (s *Service) GroupSession() GroupSessionService {
+	return s.groupSession
+}
+
+// AppointmentType returns the clinic appointment type service.
+func (s *Service) AppointmentType() AppointmentTypeService {
+	return s.appointmentType
+}
+
+// Attendance returns the no-show tracking service.
+func (s *Service) Attendance() AttendanceService {
+	return s.attendance
+}
+
+// Calendar returns the iCalendar feed service.
+func (s *Service) Calendar() CalendarService {
+	return s.calendar
+}
+
+// StatusBoard returns the check-in and live status board service.
+func (s *Service) StatusBoard() StatusBoardService {
+	return s.statusBoard
+}
+
+// Kiosk returns the kiosk and self check-in service.
+func (s *Service) Kiosk() KioskService {
+	return s.kiosk
+}
+
 // Exercise returns the exercise service.
 func (s *Service) Exercise() ExerciseService {
 	return s.exercise
 }
 
+// ExerciseMedia returns the exercise photo and clip service.
+func (s *Service) ExerciseMedia() ExerciseMediaService {
+	return s.exerciseMedia
+}
+
+// ExerciseLibrary returns the exercise library import, export and sharing service.
+func (s *Service) ExerciseLibrary() ExerciseLibraryService {
+	return s.exerciseLibrary
+}
+
+// ExerciseRecommendation returns the exercise recommendation service.
+func (s *Service) ExerciseRecommendation() ExerciseRecommendationService {
+	return s.exerciseRecommendation
+}
+
+// Progression returns the exercise progression service.
+func (s *Service) Progression() ProgressionService {
+	return s.progression
+}
+
+// Protocol returns the exercise protocol service.
+func (s *Service) Protocol() ProtocolService {
+	return s.protocol
+}
+
+// Adherence returns the exercise adherence service.
+func (s *Service) Adherence() AdherenceService {
+	return s.adherence
+}
+
+// Timeline returns the patient timeline service.
+func (s *Service) Timeline() TimelineService {
+	return s.timeline
+}
+
+// Portal returns the patient portal service.
+func (s *Service) Portal() PortalService {
+	return s.portal
+}
+
+// Proxy returns the caregiver proxy access service.
+func (s *Service) Proxy() ProxyService {
+	return s.proxy
+}
+
+// Consent returns the patient consent service.
+func (s *Service) Consent() ConsentService {
+	return s.consent
+}
+
+// Attachment returns the patient attachment service.
+func (s *Service) Attachment() AttachmentService {
+	return s.attachment
+}
+
+// Export returns the patient data export service.
+func (s *Service) Export() ExportService {
+	return s.export
+}
+
 // CheckDatabase verifies database connectivity.
 func (s *Service) CheckDatabase() error {
 	return s.repo.CheckDatabase()
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/events"
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/repository"
+)
+
+// Event stream ticket settings
+const (
+	streamTicketTTL      = time.Minute // how long a ticket may be used to open a stream
+	streamSecretBytes    = 32
+	streamSignatureBytes = 16
+)
+
+// ErrInvalidStreamTicket is returned for event stream tickets that are
+// malformed, forged or expired.
+var ErrInvalidStreamTicket = errors.New("invalid or expired stream ticket")
+
+// EventPublisher publishes changes to the clinic's live status board.
+// Publishing is best effort and never fails the change that caused it.
+type EventPublisher interface {
+	Publish(ctx context.Context, event model.ClinicEvent)
+}
+
+// StatusBoardService defines the interface for patient check-in and the
+// real-time clinic status board.
+type StatusBoardService interface {
+	EventPublisher
+	Snapshot(ctx context.Context, clinicID string, date *time.Time) (*model.StatusBoard, error)
+	CheckIn(ctx context.Context, checkIn *model.AppointmentCheckIn) (*model.StatusBoardEntry, error)
+	Subscribe(clinicID string) *events.Subscription
+	IssueStreamTicket(clinicID, userID string) *model.StreamTicket
+	VerifyStreamTicket(ticket string) (clinicID, userID string, err error)
+}
+
+// statusBoardService implements StatusBoardService.
+type statusBoardService struct {
+	repo            repository.StatusBoardRepository
+	appointmentRepo repository.AppointmentRepository
+	clinicRepo      repository.ClinicRepository
+	bus             events.Bus
+	secret          []byte
+}
+
+// NewStatusBoardService creates a new status board service. Event stream
+// tickets are signed with secret; without one a random secret is used, so
+// tickets are only accepted by the instance that issued them.
+func NewStatusBoardService(repo repository.StatusBoardRepository, appointmentRepo repository.AppointmentRepository, clinicRepo repository.ClinicRepository, bus events.Bus, secret string) StatusBoardService {
+	key := []byte(secret)
+	if secret == "" {
+		log.Warn().Msg("STATUS_BOARD_STREAM_SECRET is not set, stream tickets are only accepted by the issuing instance")
+		key = make([]byte, streamSecretBytes)
+		if _, err := rand.Read(key); err != nil {
+			log.Fatal().Err(err).Msg("failed to generate stream ticket secret")
+		}
+	}
+
+	return &statusBoardService{repo: repo, appointmentRepo: appointmentRepo, clinicRepo: clinicRepo, bus: bus, secret: key}
+}
+
+// Snapshot returns the clinic's board for a day, today by default, in the
+// clinic's time zone.
+func (s *statusBoardService) Snapshot(ctx context.Context, clinicID string, date *time.Time) (*model.StatusBoard, error) {
+	loc, err := clinicLocation(ctx, s.clinicRepo, clinicID)
+	if err != nil {
+		return nil, err
+	}
+
+	day := todayIn(loc)
+	if date != nil {
+		day = dayIn(*date, loc)
+	}
+	start, end := dayBounds(day, loc)
+
+	entries, err := s.repo.ListEntries(ctx, clinicID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := map[model.StatusBoardStage]int{
+		model.StageExpected:  0,
+		model.StageArrived:   0,
+		model.StageInSession: 0,
+		model.StageDone:      0,
+		model.StageCancelled: 0,
+		model.StageNoShow:    0,
+	}
+	for i := range entries {
+		entries[i].StartTime = entries[i].StartTime.In(loc)
+		entries[i].EndTime = entries[i].EndTime.In(loc)
+		counts[entries[i].Stage]++
+	}
+
+	return &model.StatusBoard{
+		ClinicID:    clinicID,
+		Date:        day.Format("2006-01-02"),
+		Timezone:    loc.String(),
+		Entries:     entries,
+		Counts:      counts,
+		GeneratedAt: time.Now().In(loc),
+	}, nil
+}
+
+// CheckIn records a patient's arrival for a scheduled or confirmed
+// appointment. Checking in again is harmless and keeps the first check-in,
+// which is read back into checkIn.
+func (s *statusBoardService) CheckIn(ctx context.Context, checkIn *model.AppointmentCheckIn) (*model.StatusBoardEntry, error) {
+	appointment, err := s.appointmentRepo.GetByID(ctx, checkIn.ClinicID, checkIn.AppointmentID)
+	if err != nil {
+		return nil, err
+	}
+	if !isOpenAppointment(appointment.Status) {
+		return nil, fmt.Errorf("%w: cannot check in to a %s appointment", repository.ErrInvalidInput, appointment.Status)
+	}
+
+	checkIn.CheckedInAt = time.Now()
+	if err := s.repo.CheckIn(ctx, checkIn); err != nil {
+		return nil, err
+	}
+
+	var actorID string
+	if checkIn.CheckedInBy != nil {
+		actorID = *checkIn.CheckedInBy
+	}
+
+	log.Info().
+		Str("appointment_id", checkIn.AppointmentID).
+		Str("patient_id", appointment.PatientID).
+		Str("clinic_id", checkIn.ClinicID).
+		Str("method", string(checkIn.Method)).
+		Str("checked_in_by", actorID).
+		Msg("patient checked in")
+
+	entry, err := s.repo.GetEntry(ctx, checkIn.ClinicID, checkIn.AppointmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Publish(ctx, model.ClinicEvent{
+		ClinicID:      checkIn.ClinicID,
+		Type:          model.EventAppointmentCheckedIn,
+		AppointmentID: checkIn.AppointmentID,
+		PatientID:     appointment.PatientID,
+		TherapistID:   appointment.TherapistID,
+		ActorID:       actorID,
+		Entry:         entry,
+	})
+
+	return entry, nil
+}
+
+// Subscribe starts receiving the clinic's events.
+func (s *statusBoardService) Subscribe(clinicID string) *events.Subscription {
+	return s.bus.Subscribe(clinicID)
+}
+
+// Publish sends an event to the clinic's board. Appointment events carry the
+// appointment's current board entry unless one is given.
+func (s *statusBoardService) Publish(ctx context.Context, event model.ClinicEvent) {
+	if event.ID == "" {
+		event.ID = uuid.New().String()
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	if event.Entry == nil && event.AppointmentID != "" && event.Type != model.EventAppointmentDeleted {
+		entry, err := s.repo.GetEntry(ctx, event.ClinicID, event.AppointmentID)
+		switch {
+		case err == nil:
+			event.Entry = entry
+		case !errors.Is(err, repository.ErrNotFound):
+			log.Warn().Err(err).Str("appointment_id", event.AppointmentID).Msg("failed to load status board entry for event")
+		}
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		log.Warn().Err(err).Str("event", string(event.Type)).Msg("failed to encode clinic event")
+		return
+	}
+
+	if err := s.bus.Publish(ctx, event.ClinicID, payload); err != nil {
+		log.Warn().
+			Err(err).
+			Str("clinic_id", event.ClinicID).
+			Str("event", string(event.Type)).
+			Msg("failed to publish clinic event")
+	}
+}
+
+// IssueStreamTicket signs a short-lived ticket that lets a user open the
+// clinic's event stream without an Authorization header.
+func (s *statusBoardService) IssueStreamTicket(clinicID, userID string) *model.StreamTicket {
+	expires := time.Now().Add(streamTicketTTL)
+	payload := clinicID + "." + userID + "." + strconv.FormatInt(expires.Unix(), 36)
+	return &model.StreamTicket{
+		Ticket:    payload + "." + s.streamSignature(payload),
+		ExpiresAt: expires,
+	}
+}
+
+// VerifyStreamTicket returns the clinic and user a ticket was issued to.
+func (s *statusBoardService) VerifyStreamTicket(ticket string) (string, string, error) {
+	parts := strings.Split(ticket, ".")
+	if len(parts) != 4 {
+		return "", "", ErrInvalidStreamTicket
+	}
+
+	payload := strings.Join(parts[:3], ".")
+	if !hmac.Equal([]byte(parts[3]), []byte(s.streamSignature(payload))) {
+		return "", "", ErrInvalidStreamTicket
+	}
+	expires, err := strconv.ParseInt(parts[2], 36, 64)
+	if err != nil || time.Now().Unix() > expires {
+		return "", "", ErrInvalidStreamTicket
+	}
+	return parts[0], parts[1], nil
+}
+
+func (s *statusBoardService) streamSignature(payload string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte("status-board-stream:" + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:streamSignatureBytes])
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestStreamTicket(t *testing.T) {
+	s := &statusBoardService{secret: []byte("test-secret")}
+
+	ticket := s.IssueStreamTicket("clinic-1", "user-1")
+	if ticket.ExpiresAt.Before(time.Now()) {
+		t.Fatalf("ticket already expired at %v", ticket.ExpiresAt)
+	}
+
+	clinicID, userID, err := s.VerifyStreamTicket(ticket.Ticket)
+	if err != nil {
+		t.Fatalf("VerifyStreamTicket: %v", err)
+	}
+	if clinicID != "clinic-1" || userID != "user-1" {
+		t.Errorf("ticket resolved to clinic %q, user %q", clinicID, userID)
+	}
+
+	expired := "clinic-1.user-1." + strconv.FormatInt(time.Now().Add(-time.Second).Unix(), 36)
+	other := &statusBoardService{secret: []byte("other-secret")}
+
+	invalid := map[string]string{
+		"empty":          "",
+		"malformed":      "clinic-1.user-1",
+		"other clinic":   "clinic-2" + ticket.Ticket[len("clinic-1"):],
+		"other secret":   other.IssueStreamTicket("clinic-1", "user-1").Ticket,
+		"expired":        expired + "." + s.streamSignature(expired),
+		"bad signature":  ticket.Ticket + "x",
+		"bad expiry":     "clinic-1.user-1.!." + s.streamSignature("clinic-1.user-1.!"),
+		"extra segments": "a." + ticket.Ticket,
+	}
+	for name, tok := range invalid {
+		t.Run(name, func(t *testing.T) {
+			if _, _, err := s.VerifyStreamTicket(tok); !errors.Is(err, ErrInvalidStreamTicket) {
+				t.Errorf("VerifyStreamTicket(%q) err = %v, want ErrInvalidStreamTicket", tok, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,297 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tqvdang/physioflow/apps/api/internal/model"
+	"github.com/tqvdang/physioflow/apps/api/internal/repository"
+)
+
+// TimelineService defines the interface for patient timeline business logic.
+type TimelineService interface {
+	GetPatientTimeline(ctx context.Context, params model.TimelineParams) (*model.TimelinePage, error)
+}
+
+// timelineService implements TimelineService.
+type timelineService struct {
+	repo        repository.TimelineRepository
+	patientRepo repository.PatientRepository
+}
+
+// NewTimelineService creates a new timeline service.
+func NewTimelineService(repo repository.TimelineRepository, patientRepo repository.PatientRepository) TimelineService {
+	return &timelineService{
+		repo:        repo,
+		patientRepo: patientRepo,
+	}
+}
+
+// timelineSummaryLength is the maximum number of characters kept from a note.
+const timelineSummaryLength = 200
+
+// GetPatientTimeline returns one page of a patient's clinical timeline, newest first.
+func (s *timelineService) GetPatientTimeline(ctx context.Context, params model.TimelineParams) (*model.TimelinePage, error) {
+	for _, t := range params.Types {
+		if !t.IsValid() {
+			return nil, fmt.Errorf("%w: unknown timeline event type %q", repository.ErrInvalidInput, t)
+		}
+	}
+
+	if _, err := s.patientRepo.GetByID(ctx, params.ClinicID, params.PatientID); err != nil {
+		return nil, err
+	}
+
+	limit := params.Limit()
+	events, err := s.repo.ListEvents(ctx, params, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get timeline: %w", err)
+	}
+
+	page := &model.TimelinePage{}
+	if len(events) > limit {
+		events = events[:limit]
+		page.HasMore = true
+	}
+
+	for i := range events {
+		describeTimelineEvent(&events[i])
+	}
+	page.Data = events
+
+	if page.HasMore {
+		last := events[len(events)-1]
+		page.NextCursor = model.TimelineCursor{OccurredAt: last.OccurredAt, ID: last.ID}.Encode()
+	}
+
+	return page, nil
+}
+
+// bilingualLabel holds an English and Vietnamese display label.
+type bilingualLabel struct {
+	en string
+	vi string
+}
+
+var appointmentTypeLabels = map[string]bilingualLabel{
+	string(model.AppointmentTypeAssessment):   {"Assessment", "đánh giá"},
+	string(model.AppointmentTypeTreatment):    {"Treatment", "điều trị"},
+	string(model.AppointmentTypeFollowUp):     {"Follow-up", "tái khám"},
+	string(model.AppointmentTypeConsultation): {"Consultation", "tư vấn"},
+	string(model.AppointmentTypeOther):        {"Other", "khác"},
+}
+
+var appointmentStatusLabels = map[string]bilingualLabel{
+	string(model.AppointmentStatusScheduled):  {"Scheduled", "Đã đặt lịch"},
+	string(model.AppointmentStatusConfirmed):  {"Confirmed", "Đã xác nhận"},
+	string(model.AppointmentStatusInProgress): {"In progress", "Đang diễn ra"},
+	string(model.AppointmentStatusCompleted):  {"Completed", "Hoàn thành"},
+	string(model.AppointmentStatusCancelled):  {"Cancelled", "Đã hủy"},
+	string(model.AppointmentStatusNoShow):     {"No-show", "Vắng mặt"},
+}
+
+var checklistStatusLabels = map[string]bilingualLabel{
+	string(model.ChecklistStatusNotStarted): {"Not started", "Chưa bắt đầu"},
+	string(model.ChecklistStatusInProgress): {"In progress", "Đang thực hiện"},
+	string(model.ChecklistStatusCompleted):  {"Completed", "Hoàn thành"},
+	string(model.ChecklistStatusReviewed):   {"Reviewed", "Đã xem xét"},
+	string(model.ChecklistStatusLocked):     {"Locked", "Đã khóa"},
+}
+
+var prescriptionStatusLabels = map[string]bilingualLabel{
+	string(model.PrescriptionStatusActive):    {"active", "đang áp dụng"},
+	string(model.PrescriptionStatusCompleted): {"completed", "hoàn thành"},
+	string(model.PrescriptionStatusPaused):    {"paused", "tạm dừng"},
+	string(model.PrescriptionStatusCancelled): {"cancelled", "đã hủy"},
+}
+
+var complianceDifficultyLabels = map[string]bilingualLabel{
+	"easy":     {"easy", "dễ"},
+	"moderate": {"moderate", "vừa"},
+	"hard":     {"hard", "khó"},
+}
+
+// lookupLabel returns the label for key, falling back to the key itself.
+func lookupLabel(labels map[string]bilingualLabel, key string) bilingualLabel {
+	if l, ok := labels[key]; ok {
+		return l
+	}
+	return bilingualLabel{key, key}
+}
+
+// describeTimelineEvent fills in the bilingual title and summary of an event.
+func describeTimelineEvent(ev *model.TimelineEvent) {
+	d := ev.Details
+
+	switch ev.Type {
+	case model.TimelineEventAppointment:
+		typeLabel := lookupLabel(appointmentTypeLabels, detailString(d, "type"))
+		status := lookupLabel(appointmentStatusLabels, detailString(d, "status"))
+		duration := detailInt(d, "duration")
+		ev.Title = fmt.Sprintf("%s appointment", typeLabel.en)
+		ev.TitleVi = fmt.Sprintf("Lịch hẹn %s", typeLabel.vi)
+		ev.Summary = fmt.Sprintf("%s - %d min", status.en, duration)
+		ev.SummaryVi = fmt.Sprintf("%s - %d phút", status.vi, duration)
+
+	case model.TimelineEventVisitChecklist:
+		ev.Title = detailString(d, "template_name")
+		ev.TitleVi = fallback(detailString(d, "template_name_vi"), ev.Title)
+		if ev.Title == "" {
+			ev.Title, ev.TitleVi = "Visit checklist", "Phiếu khám"
+		}
+		ev.Summary = summarizeNote(detailString(d, "note"))
+		ev.SummaryVi = summarizeNote(detailString(d, "note_vi"))
+		if ev.Summary == "" && ev.SummaryVi == "" {
+			status := lookupLabel(checklistStatusLabels, detailString(d, "status"))
+			ev.Summary, ev.SummaryVi = status.en, status.vi
+		}
+		// Full notes are available from the checklist itself; keep the feed light.
+		delete(d, "note")
+		delete(d, "note_vi")
+
+	case model.TimelineEventPainRecord:
+		level := detailInt(d, "level")
+		ev.Title = fmt.Sprintf("Pain level %d/10", level)
+		ev.TitleVi = fmt.Sprintf("Mức độ đau %d/10", level)
+		location := joinNonEmpty(", ", detailString(d, "location"), detailString(d, "body_region"))
+		ev.Summary = location
+		ev.SummaryVi = location
+
+	case model.TimelineEventROMRecord:
+		motion := joinNonEmpty(" ", detailString(d, "joint"), detailString(d, "movement"))
+		if side := detailString(d, "side"); side != "" {
+			motion = fmt.Sprintf("%s (%s)", motion, side)
+		}
+		ev.Title = fmt.Sprintf("ROM: %s", motion)
+		ev.TitleVi = fmt.Sprintf("Tầm vận động: %s", motion)
+
+		var parts, partsVi []string
+		if v, ok := detailFloat(d, "active_rom"); ok {
+			parts = append(parts, fmt.Sprintf("active %.0f°", v))
+			partsVi = append(partsVi, fmt.Sprintf("chủ động %.0f°", v))
+		}
+		if v, ok := detailFloat(d, "passive_rom"); ok {
+			parts = append(parts, fmt.Sprintf("passive %.0f°", v))
+			partsVi = append(partsVi, fmt.Sprintf("thụ động %.0f°", v))
+		}
+		if painful, _ := d["is_painful"].(bool); painful {
+			parts = append(parts, "painful")
+			partsVi = append(partsVi, "có đau")
+		}
+		ev.Summary = strings.Join(parts, ", ")
+		ev.SummaryVi = strings.Join(partsVi, ", ")
+
+	case model.TimelineEventPrescription:
+		name := detailString(d, "exercise_name")
+		ev.Title = fmt.Sprintf("Prescribed: %s", name)
+		ev.TitleVi = fmt.Sprintf("Kê đơn bài tập: %s", fallback(detailString(d, "exercise_name_vi"), name))
+		sets, reps := detailInt(d, "sets"), detailInt(d, "reps")
+		frequency := detailString(d, "frequency")
+		ev.Summary = joinNonEmpty(", ", fmt.Sprintf("%d sets x %d reps", sets, reps), frequency)
+		ev.SummaryVi = joinNonEmpty(", ", fmt.Sprintf("%d bộ x %d lần", sets, reps), frequency)
+
+	case model.TimelineEventComplianceLog:
+		name := detailString(d, "exercise_name")
+		ev.Title = fmt.Sprintf("Exercise completed: %s", name)
+		ev.TitleVi = fmt.Sprintf("Hoàn thành bài tập: %s", fallback(detailString(d, "exercise_name_vi"), name))
+
+		var parts, partsVi []string
+		if sets, ok := detailFloat(d, "sets_completed"); ok {
+			parts = append(parts, fmt.Sprintf("%.0f sets", sets))
+			partsVi = append(partsVi, fmt.Sprintf("%.0f bộ", sets))
+		}
+		if reps, ok := detailFloat(d, "reps_completed"); ok {
+			parts = append(parts, fmt.Sprintf("%.0f reps", reps))
+			partsVi = append(partsVi, fmt.Sprintf("%.0f lần", reps))
+		}
+		if pain, ok := detailFloat(d, "pain_level"); ok {
+			parts = append(parts, fmt.Sprintf("pain %.0f/10", pain))
+			partsVi = append(partsVi, fmt.Sprintf("đau %.0f/10", pain))
+		}
+		if difficulty := detailString(d, "difficulty"); difficulty != "" {
+			label := lookupLabel(complianceDifficultyLabels, difficulty)
+			parts = append(parts, label.en)
+			partsVi = append(partsVi, label.vi)
+		}
+		ev.Summary = strings.Join(parts, ", ")
+		ev.SummaryVi = strings.Join(partsVi, ", ")
+
+	case model.TimelineEventStatusChange:
+		status := detailString(d, "status")
+		if detailString(d, "entity") == "prescription" {
+			name := detailString(d, "exercise_name")
+			label := lookupLabel(prescriptionStatusLabels, status)
+			ev.Title = fmt.Sprintf("Prescription %s: %s", label.en, name)
+			ev.TitleVi = fmt.Sprintf("Bài tập %s: %s", label.vi, fallback(detailString(d, "exercise_name_vi"), name))
+			return
+		}
+
+		label := lookupLabel(appointmentStatusLabels, status)
+		ev.Title = fmt.Sprintf("Appointment: %s", label.en)
+		ev.TitleVi = fmt.Sprintf("Lịch hẹn: %s", label.vi)
+		reason := detailString(d, "reason")
+		ev.Summary = reason
+		ev.SummaryVi = reason
+	}
+}
+
+// summarizeNote collapses a generated SOAP note into a short single-line summary.
+func summarizeNote(note string) string {
+	lines := strings.Split(note, "\n")
+	kept := make([]string, 0, len(lines))
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "===") {
+			continue
+		}
+		kept = append(kept, line)
+	}
+
+	summary := strings.Join(kept, " ")
+	if utf8.RuneCountInString(summary) <= timelineSummaryLength {
+		return summary
+	}
+	runes := []rune(summary)
+	return strings.TrimSpace(string(runes[:timelineSummaryLength])) + "..."
+}
+
+// detailString returns a string value from event details.
+func detailString(d map[string]interface{}, key string) string {
+	if v, ok := d[key].(string); ok {
+		return v
+	}
+	return ""
+}
+
+// detailFloat returns a numeric value from event details.
+func detailFloat(d map[string]interface{}, key string) (float64, bool) {
+	v, ok := d[key].(float64)
+	return v, ok
+}
+
+// detailInt returns a numeric value from event details as an int.
+func detailInt(d map[string]interface{}, key string) int {
+	v, _ := detailFloat(d, key)
+	return int(v)
+}
+
+// fallback returns s, or def if s is empty.
+func fallback(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+// joinNonEmpty joins the non-empty values with sep.
+func joinNonEmpty(sep string, values ...string) string {
+	parts := make([]string, 0, len(values))
+	for _, v := range values {
+		if v != "" {
+			parts = append(parts, v)
+		}
+	}
+	return strings.Join(parts, sep)
+}
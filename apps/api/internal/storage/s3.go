@@ -0,0 +1,362 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// emptySHA256 is the payload hash of a request without a body.
+const emptySHA256 = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// S3Options configures an S3Store.
+type S3Options struct {
+	Endpoint  string // base URL, e.g. http://minio:9000
+	Region    string // defaults to us-east-1, which MinIO accepts
+	Bucket    string
+	AccessKey string
+	SecretKey string
+}
+
+// S3Store implements BlobStore on an S3-compatible object store such as
+// MinIO, so every API instance reads and writes the same blobs. Requests use
+// path-style addressing and are signed with AWS Signature Version 4.
+type S3Store struct {
+	endpoint *url.URL
+	region   string
+	bucket   string
+	access   string
+	secret   string
+	client   *http.Client
+}
+
+// NewS3Store creates an S3 blob store. The bucket is created on the first
+// upload if it does not exist.
+func NewS3Store(opts S3Options) (*S3Store, error) {
+	u, err := url.Parse(strings.TrimSuffix(opts.Endpoint, "/"))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", opts.Endpoint)
+	}
+	if opts.Bucket == "" {
+		return nil, errors.New("S3 bucket is required")
+	}
+
+	region := opts.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	return &S3Store{
+		endpoint: u,
+		region:   region,
+		bucket:   opts.Bucket,
+		access:   opts.AccessKey,
+		secret:   opts.SecretKey,
+		client:   &http.Client{},
+	}, nil
+}
+
+// Put uploads a blob and returns its size and checksum. The content is spooled
+// to a temporary file first, since the payload hash is part of the signature.
+func (s *S3Store) Put(ctx context.Context, key string, r io.Reader, contentType string) (*BlobInfo, error) {
+	objectURL, err := s.objectURL(key)
+	if err != nil {
+		return nil, err
+	}
+
+	tmp, err := os.CreateTemp("", "blob-upload-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write blob: %w", err)
+	}
+	sum := hex.EncodeToString(hash.Sum(nil))
+
+	upload := func() (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPut, objectURL, io.NopCloser(io.NewSectionReader(tmp, 0, size)))
+		if err != nil {
+			return nil, err
+		}
+		req.ContentLength = size
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		req.Header.Set("X-Amz-Meta-Sha256", sum)
+		return s.do(req, sum)
+	}
+
+	resp, err := upload()
+	if err != nil {
+		return nil, fmt.Errorf("failed to store blob: %w", err)
+	}
+	if err := responseError(resp); err != nil {
+		if !isS3Code(err, "NoSuchBucket") {
+			return nil, fmt.Errorf("failed to store blob: %w", err)
+		}
+		if err := s.createBucket(ctx); err != nil {
+			return nil, fmt.Errorf("failed to create bucket: %w", err)
+		}
+		if resp, err = upload(); err == nil {
+			err = responseError(resp)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to store blob: %w", err)
+		}
+	}
+	resp.Body.Close()
+
+	return &BlobInfo{
+		Key:         key,
+		Size:        size,
+		SHA256:      sum,
+		ContentType: contentType,
+		ModifiedAt:  time.Now(),
+	}, nil
+}
+
+// Get opens a blob for reading. The caller must close the reader.
+func (s *S3Store) Get(ctx context.Context, key string) (io.ReadCloser, *BlobInfo, error) {
+	resp, err := s.object(ctx, http.MethodGet, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := responseError(resp); err != nil {
+		if isS3Code(err, "NoSuchKey") {
+			return nil, nil, ErrNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to open blob: %w", err)
+	}
+
+	return resp.Body, blobInfo(key, resp), nil
+}
+
+// Stat returns blob metadata without downloading it.
+func (s *S3Store) Stat(ctx context.Context, key string) (*BlobInfo, error) {
+	resp, err := s.object(ctx, http.MethodHead, key)
+	if err != nil {
+		return nil, err
+	}
+	// A HEAD response has no error body, so a missing bucket also reads as
+	// a missing blob
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+	if err := responseError(resp); err != nil {
+		return nil, fmt.Errorf("failed to stat blob: %w", err)
+	}
+	resp.Body.Close()
+
+	return blobInfo(key, resp), nil
+}
+
+// Delete removes a blob. Deleting a missing blob is not an error.
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	resp, err := s.object(ctx, http.MethodDelete, key)
+	if err != nil {
+		return err
+	}
+	if err := responseError(resp); err != nil && !isS3Code(err, "NoSuchKey") && !isS3Code(err, "NoSuchBucket") {
+		return fmt.Errorf("failed to delete blob: %w", err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// object sends a bodiless request for a blob.
+func (s *S3Store) object(ctx context.Context, method, key string) (*http.Response, error) {
+	objectURL, err := s.objectURL(key)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, objectURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(req, emptySHA256)
+	if err != nil {
+		return nil, fmt.Errorf("blob request failed: %w", err)
+	}
+	return resp, nil
+}
+
+// createBucket creates the store's bucket, tolerating a concurrent create.
+func (s *S3Store) createBucket(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.endpoint.String()+"/"+s.bucket, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(req, emptySHA256)
+	if err != nil {
+		return err
+	}
+	if err := responseError(resp); err != nil && !isS3Code(err, "BucketAlreadyOwnedByYou") {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// objectURL maps a key to its path-style URL, rejecting keys that escape the
+// bucket.
+func (s *S3Store) objectURL(key string) (string, error) {
+	clean := path.Clean("/" + key)
+	if key == "" || strings.Contains(key, "..") || clean == "/" {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+	return s.endpoint.String() + "/" + s.bucket + clean, nil
+}
+
+// do signs and sends a request.
+func (s *S3Store) do(req *http.Request, payloadHash string) (*http.Response, error) {
+	s.sign(req, payloadHash, time.Now())
+	return s.client.Do(req)
+}
+
+// sign adds an AWS Signature Version 4 Authorization header covering the host
+// and every header already set on the request.
+func (s *S3Store) sign(req *http.Request, payloadHash string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonical bytes.Buffer
+	canonical.WriteString(req.Method + "\n")
+	canonical.WriteString(uriEncode(req.URL.Path, false) + "\n")
+	canonical.WriteString(canonicalQuery(req.URL.Query()) + "\n")
+	for _, name := range names {
+		canonical.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+	canonical.WriteString("\n" + signedHeaders + "\n" + payloadHash)
+
+	scope := day + "/" + s.region + "/s3/aws4_request"
+	requestHash := sha256.Sum256(canonical.Bytes())
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.secret), day)
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+s.access+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// canonicalQuery encodes query parameters sorted by name, then value.
+func canonicalQuery(q url.Values) string {
+	pairs := make([]string, 0, len(q))
+	for name, values := range q {
+		for _, v := range values {
+			pairs = append(pairs, uriEncode(name, true)+"="+uriEncode(v, true))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode percent-encodes everything but unreserved characters, and slashes
+// unless encodeSlash is set.
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// blobInfo reads blob metadata from response headers.
+func blobInfo(key string, resp *http.Response) *BlobInfo {
+	info := &BlobInfo{
+		Key:         key,
+		Size:        resp.ContentLength,
+		SHA256:      resp.Header.Get("X-Amz-Meta-Sha256"),
+		ContentType: resp.Header.Get("Content-Type"),
+	}
+	if t, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		info.ModifiedAt = t
+	}
+	return info
+}
+
+// s3Error is an S3 error response.
+type s3Error struct {
+	Status  int
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+func (e *s3Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("s3: status %d", e.Status)
+	}
+	return fmt.Sprintf("s3: %s: %s", e.Code, e.Message)
+}
+
+// responseError returns nil for a successful response. Otherwise it reads the
+// error body, closes the response and returns an *s3Error.
+func responseError(resp *http.Response) error {
+	if resp.StatusCode < 300 {
+		return nil
+	}
+	defer resp.Body.Close()
+
+	e := &s3Error{Status: resp.StatusCode}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	_ = xml.Unmarshal(body, e)
+	return e
+}
+
+// isS3Code reports whether err is an S3 error with the given code.
+func isS3Code(err error, code string) bool {
+	var e *s3Error
+	return errors.As(err, &e) && e.Code == code
+}
@@ -0,0 +1,264 @@
+// Package ical writes RFC 5545 iCalendar data for calendar subscriptions and
+// single-event attachments. Event times are written in one IANA time zone,
+// described by a VTIMEZONE built from the Go zone database so that clients
+// show the right wall-clock time on both sides of daylight saving changes.
+package ical
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Status is the STATUS of an event.
+type Status string
+
+const (
+	StatusTentative Status = "TENTATIVE"
+	StatusConfirmed Status = "CONFIRMED"
+	StatusCancelled Status = "CANCELLED"
+)
+
+// Method is the iTIP METHOD of a calendar object.
+type Method string
+
+// MethodPublish marks a calendar that is published for information, such as
+// a subscribed feed or an event attached to a message.
+const MethodPublish Method = "PUBLISH"
+
+const (
+	localFormat = "20060102T150405"
+	utcFormat   = "20060102T150405Z"
+
+	// maxLineOctets is the longest a content line may be before folding.
+	maxLineOctets = 75
+)
+
+// Calendar is a VCALENDAR holding events in one time zone.
+type Calendar struct {
+	ProdID   string
+	Name     string // X-WR-CALNAME, shown by clients for subscribed feeds
+	Method   Method
+	Location *time.Location
+	// RefreshInterval suggests how often subscribers poll the feed; zero omits it.
+	RefreshInterval time.Duration
+	Events          []Event
+}
+
+// Event is a VEVENT. UID must stay the same for the life of the underlying
+// appointment so that clients update the event rather than adding a copy,
+// and Sequence must increase whenever the event changes.
+type Event struct {
+	UID          string
+	Sequence     int
+	Stamp        time.Time
+	Created      time.Time
+	LastModified time.Time
+	Start        time.Time
+	End          time.Time
+	Summary      string
+	Description  string
+	Location     string
+	Status       Status
+}
+
+// Bytes renders the calendar.
+func (c *Calendar) Bytes() []byte {
+	loc := c.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	w := &writer{}
+	w.line("BEGIN:VCALENDAR")
+	w.line("VERSION:2.0")
+	w.line("PRODID:" + c.ProdID)
+	w.line("CALSCALE:GREGORIAN")
+	if c.Method != "" {
+		w.line("METHOD:" + string(c.Method))
+	}
+	if c.Name != "" {
+		w.line("X-WR-CALNAME:" + escapeText(c.Name))
+	}
+	if loc != time.UTC {
+		w.line("X-WR-TIMEZONE:" + loc.String())
+	}
+	if c.RefreshInterval > 0 {
+		w.line("REFRESH-INTERVAL;VALUE=DURATION:" + duration(c.RefreshInterval))
+		w.line("X-PUBLISHED-TTL:" + duration(c.RefreshInterval))
+	}
+
+	if loc != time.UTC && len(c.Events) > 0 {
+		from, to := c.Events[0].Start, c.Events[0].End
+		for _, e := range c.Events[1:] {
+			if e.Start.Before(from) {
+				from = e.Start
+			}
+			if e.End.After(to) {
+				to = e.End
+			}
+		}
+		writeTimezone(w, loc, from, to)
+	}
+
+	for _, e := range c.Events {
+		writeEvent(w, e, loc)
+	}
+
+	w.line("END:VCALENDAR")
+	return w.buf.Bytes()
+}
+
+func writeEvent(w *writer, e Event, loc *time.Location) {
+	w.line("BEGIN:VEVENT")
+	w.line("UID:" + e.UID)
+	w.line("DTSTAMP:" + e.Stamp.UTC().Format(utcFormat))
+	if !e.Created.IsZero() {
+		w.line("CREATED:" + e.Created.UTC().Format(utcFormat))
+	}
+	if !e.LastModified.IsZero() {
+		w.line("LAST-MODIFIED:" + e.LastModified.UTC().Format(utcFormat))
+	}
+	w.line("SEQUENCE:" + fmt.Sprint(e.Sequence))
+	w.line(dateTime("DTSTART", e.Start, loc))
+	w.line(dateTime("DTEND", e.End, loc))
+	w.line("SUMMARY:" + escapeText(e.Summary))
+	if e.Description != "" {
+		w.line("DESCRIPTION:" + escapeText(e.Description))
+	}
+	if e.Location != "" {
+		w.line("LOCATION:" + escapeText(e.Location))
+	}
+	status := e.Status
+	if status == "" {
+		status = StatusConfirmed
+	}
+	w.line("STATUS:" + string(status))
+	if status == StatusCancelled {
+		// Cancelled events stay in the feed so clients remove or strike them
+		// through; they no longer block time
+		w.line("TRANSP:TRANSPARENT")
+	} else {
+		w.line("TRANSP:OPAQUE")
+	}
+	w.line("END:VEVENT")
+}
+
+// dateTime formats a DATE-TIME property in loc, or in UTC without a TZID.
+func dateTime(name string, t time.Time, loc *time.Location) string {
+	if loc == time.UTC {
+		return name + ":" + t.UTC().Format(utcFormat)
+	}
+	return name + ";TZID=" + loc.String() + ":" + t.In(loc).Format(localFormat)
+}
+
+// writeTimezone writes a VTIMEZONE for loc with one observance for every
+// offset in effect between from and to. Each observance is listed explicitly
+// rather than as a recurrence rule, which keeps the output exact for zones
+// whose rules have changed over the years.
+func writeTimezone(w *writer, loc *time.Location, from, to time.Time) {
+	w.line("BEGIN:VTIMEZONE")
+	w.line("TZID:" + loc.String())
+
+	t := from.In(loc)
+	start, end := t.ZoneBounds()
+	if start.IsZero() {
+		// The offset has been in effect for as long as the zone database knows
+		_, offset := t.Zone()
+		epoch := time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC).Add(-time.Duration(offset) * time.Second)
+		writeObservance(w, t, epoch, offset)
+	} else {
+		_, before := start.Add(-time.Second).Zone()
+		writeObservance(w, start, start, before)
+	}
+
+	for !end.IsZero() && !end.After(to) {
+		_, before := end.Add(-time.Second).Zone()
+		t = end
+		_, end = t.ZoneBounds()
+		writeObservance(w, t, t, before)
+	}
+
+	w.line("END:VTIMEZONE")
+}
+
+// writeObservance writes the STANDARD or DAYLIGHT component of the offset in
+// effect at t. onset is when it takes effect, written as local time in the
+// offset it replaces.
+func writeObservance(w *writer, t, onset time.Time, offsetFrom int) {
+	name, offsetTo := t.Zone()
+	kind := "STANDARD"
+	if t.IsDST() {
+		kind = "DAYLIGHT"
+	}
+
+	w.line("BEGIN:" + kind)
+	w.line("DTSTART:" + onset.UTC().Add(time.Duration(offsetFrom)*time.Second).Format(localFormat))
+	w.line("TZOFFSETFROM:" + utcOffset(offsetFrom))
+	w.line("TZOFFSETTO:" + utcOffset(offsetTo))
+	w.line("TZNAME:" + escapeText(name))
+	w.line("END:" + kind)
+}
+
+// utcOffset formats an offset in seconds east of UTC as +HHMM.
+func utcOffset(seconds int) string {
+	sign := '+'
+	if seconds < 0 {
+		sign = '-'
+		seconds = -seconds
+	}
+	s := fmt.Sprintf("%c%02d%02d", sign, seconds/3600, seconds%3600/60)
+	if rest := seconds % 60; rest != 0 {
+		s += fmt.Sprintf("%02d", rest)
+	}
+	return s
+}
+
+// duration formats a duration of whole minutes or more as an RFC 5545 DURATION.
+func duration(d time.Duration) string {
+	minutes := int(d / time.Minute)
+	if minutes%(24*60) == 0 {
+		return fmt.Sprintf("P%dD", minutes/(24*60))
+	}
+	if minutes%60 == 0 {
+		return fmt.Sprintf("PT%dH", minutes/60)
+	}
+	return fmt.Sprintf("PT%dM", minutes)
+}
+
+// escapeText escapes a TEXT value.
+func escapeText(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	).Replace(s)
+}
+
+// writer accumulates content lines, folding them at 75 octets without
+// splitting a UTF-8 sequence.
+type writer struct {
+	buf bytes.Buffer
+}
+
+func (w *writer) line(s string) {
+	limit := maxLineOctets
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		w.buf.WriteString(s[:cut])
+		w.buf.WriteString("\r\n ")
+		s = s[cut:]
+		// Continuation lines start with a space, which counts toward the limit
+		limit = maxLineOctets - 1
+	}
+	w.buf.WriteString(s)
+	w.buf.WriteString("\r\n")
+}
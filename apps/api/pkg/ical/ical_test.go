@@ -0,0 +1,264 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata" // zone data for tests on hosts without it
+	"unicode/utf8"
+)
+
+func mustLoad(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("LoadLocation(%q): %v", name, err)
+	}
+	return loc
+}
+
+// unfold reverses line folding.
+func unfold(s string) string {
+	return strings.ReplaceAll(s, "\r\n ", "")
+}
+
+// section returns the lines from BEGIN:name to END:name, inclusive.
+func section(t *testing.T, out, name string) []string {
+	t.Helper()
+	start := strings.Index(out, "BEGIN:"+name+"\r\n")
+	end := strings.Index(out, "END:"+name+"\r\n")
+	if start < 0 || end < start {
+		t.Fatalf("no %s in output:\n%s", name, out)
+	}
+	return strings.Split(strings.TrimSuffix(out[start:end+len("END:"+name+"\r\n")], "\r\n"), "\r\n")
+}
+
+func TestLineFolding(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+	}{
+		{"short", "SUMMARY:Knee rehab"},
+		{"exactly 75 octets", "SUMMARY:" + strings.Repeat("a", 67)},
+		{"76 octets", "SUMMARY:" + strings.Repeat("a", 68)},
+		{"several folds", "DESCRIPTION:" + strings.Repeat("0123456789", 30)},
+		{"multibyte", "SUMMARY:" + strings.Repeat("Điều trị vật lý trị liệu ", 10)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &writer{}
+			w.line(tt.line)
+			out := w.buf.String()
+
+			if !strings.HasSuffix(out, "\r\n") {
+				t.Fatalf("output %q does not end with CRLF", out)
+			}
+			lines := strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n")
+			for i, l := range lines {
+				if len(l) > maxLineOctets {
+					t.Errorf("line %d is %d octets: %q", i, len(l), l)
+				}
+				if i > 0 && !strings.HasPrefix(l, " ") {
+					t.Errorf("continuation line %d does not start with a space: %q", i, l)
+				}
+				if !utf8.ValidString(l) {
+					t.Errorf("line %d splits a UTF-8 sequence: %q", i, l)
+				}
+			}
+			if len(tt.line) <= maxLineOctets && len(lines) != 1 {
+				t.Errorf("line of %d octets folded into %d lines", len(tt.line), len(lines))
+			}
+			if len(tt.line) > maxLineOctets && len(lines) < 2 {
+				t.Errorf("line of %d octets was not folded", len(tt.line))
+			}
+			if got := unfold(strings.TrimSuffix(out, "\r\n")); got != tt.line {
+				t.Errorf("unfolded to %q, want %q", got, tt.line)
+			}
+		})
+	}
+}
+
+func TestEscapeText(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Knee rehab", "Knee rehab"},
+		{"Room 2, floor 3", `Room 2\, floor 3`},
+		{"Bring shoes; towel", `Bring shoes\; towel`},
+		{`C:\notes`, `C:\\notes`},
+		{"line one\nline two", `line one\nline two`},
+		{"line one\r\nline two", `line one\nline two`},
+		{"line one\rline two", `line one\nline two`},
+		{`a\,b`, `a\\\,b`},
+		{"Tầng 2, phòng 5", `Tầng 2\, phòng 5`},
+	}
+
+	for _, tt := range tests {
+		if got := escapeText(tt.in); got != tt.want {
+			t.Errorf("escapeText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestEventOutput(t *testing.T) {
+	loc := mustLoad(t, "Asia/Ho_Chi_Minh")
+	stamp := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	cal := &Calendar{
+		ProdID:          "-//PhysioFlow//Test//EN",
+		Name:            "Lịch hẹn, PhysioFlow",
+		Method:          MethodPublish,
+		Location:        loc,
+		RefreshInterval: time.Hour,
+		Events: []Event{
+			{
+				UID:         "appt-1@physioflow",
+				Sequence:    2,
+				Stamp:       stamp,
+				Start:       time.Date(2026, 3, 20, 9, 0, 0, 0, loc),
+				End:         time.Date(2026, 3, 20, 10, 0, 0, 0, loc),
+				Summary:     "Treatment; knee",
+				Description: "Bring shorts\nArrive early",
+				Location:    "Room 2, floor 3",
+				Status:      StatusCancelled,
+			},
+		},
+	}
+	out := string(cal.Bytes())
+
+	if strings.Contains(strings.ReplaceAll(out, "\r\n", ""), "\n") {
+		t.Error("output contains a bare LF")
+	}
+
+	for _, want := range []string{
+		"METHOD:PUBLISH",
+		`X-WR-CALNAME:Lịch hẹn\, PhysioFlow`,
+		"X-WR-TIMEZONE:Asia/Ho_Chi_Minh",
+		"REFRESH-INTERVAL;VALUE=DURATION:PT1H",
+		"SEQUENCE:2",
+		"DTSTAMP:20260301T000000Z",
+		"DTSTART;TZID=Asia/Ho_Chi_Minh:20260320T090000",
+		"DTEND;TZID=Asia/Ho_Chi_Minh:20260320T100000",
+		`SUMMARY:Treatment\; knee`,
+		`DESCRIPTION:Bring shorts\nArrive early`,
+		`LOCATION:Room 2\, floor 3`,
+		"STATUS:CANCELLED",
+		"TRANSP:TRANSPARENT",
+	} {
+		if !strings.Contains(out, want+"\r\n") {
+			t.Errorf("output is missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestUTCCalendar(t *testing.T) {
+	cal := &Calendar{
+		ProdID: "-//PhysioFlow//Test//EN",
+		Events: []Event{{
+			UID:   "appt-1@physioflow",
+			Start: time.Date(2026, 3, 20, 2, 0, 0, 0, time.UTC),
+			End:   time.Date(2026, 3, 20, 3, 0, 0, 0, time.UTC),
+		}},
+	}
+	out := string(cal.Bytes())
+
+	if strings.Contains(out, "VTIMEZONE") || strings.Contains(out, "X-WR-TIMEZONE") {
+		t.Errorf("UTC calendar has a time zone:\n%s", out)
+	}
+	for _, want := range []string{"DTSTART:20260320T020000Z", "STATUS:CONFIRMED", "TRANSP:OPAQUE"} {
+		if !strings.Contains(out, want+"\r\n") {
+			t.Errorf("output is missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestTimezone(t *testing.T) {
+	tests := []struct {
+		name   string
+		zone   string
+		events [][2]time.Time // start, end as wall-clock times in zone
+		want   []string
+	}{
+		{
+			name: "no daylight saving",
+			zone: "Asia/Ho_Chi_Minh",
+			events: [][2]time.Time{
+				{time.Date(2026, 3, 20, 9, 0, 0, 0, time.UTC), time.Date(2026, 3, 20, 10, 0, 0, 0, time.UTC)},
+			},
+			want: []string{
+				"BEGIN:VTIMEZONE",
+				"TZID:Asia/Ho_Chi_Minh",
+				"BEGIN:STANDARD",
+				"DTSTART:19750613T000000",
+				"TZOFFSETFROM:+0800",
+				"TZOFFSETTO:+0700",
+				"TZNAME:+07",
+				"END:STANDARD",
+				"END:VTIMEZONE",
+			},
+		},
+		{
+			name: "events across a daylight saving change",
+			zone: "Europe/Berlin",
+			events: [][2]time.Time{
+				{time.Date(2026, 3, 20, 9, 0, 0, 0, time.UTC), time.Date(2026, 3, 20, 10, 0, 0, 0, time.UTC)},
+				{time.Date(2026, 4, 3, 9, 0, 0, 0, time.UTC), time.Date(2026, 4, 3, 10, 0, 0, 0, time.UTC)},
+			},
+			want: []string{
+				"BEGIN:VTIMEZONE",
+				"TZID:Europe/Berlin",
+				"BEGIN:STANDARD",
+				"DTSTART:20251026T030000",
+				"TZOFFSETFROM:+0200",
+				"TZOFFSETTO:+0100",
+				"TZNAME:CET",
+				"END:STANDARD",
+				"BEGIN:DAYLIGHT",
+				"DTSTART:20260329T020000",
+				"TZOFFSETFROM:+0100",
+				"TZOFFSETTO:+0200",
+				"TZNAME:CEST",
+				"END:DAYLIGHT",
+				"END:VTIMEZONE",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc := mustLoad(t, tt.zone)
+			cal := &Calendar{ProdID: "-//PhysioFlow//Test//EN", Location: loc}
+			for i, e := range tt.events {
+				start, end := e[0], e[1]
+				cal.Events = append(cal.Events, Event{
+					UID:   string(rune('a' + i)),
+					Start: time.Date(start.Year(), start.Month(), start.Day(), start.Hour(), start.Minute(), 0, 0, loc),
+					End:   time.Date(end.Year(), end.Month(), end.Day(), end.Hour(), end.Minute(), 0, 0, loc),
+				})
+			}
+
+			got := section(t, string(cal.Bytes()), "VTIMEZONE")
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("VTIMEZONE =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestUTCOffset(t *testing.T) {
+	tests := []struct {
+		seconds int
+		want    string
+	}{
+		{7 * 3600, "+0700"},
+		{-5 * 3600, "-0500"},
+		{5*3600 + 30*60, "+0530"},
+		{0, "+0000"},
+		{-(3600 + 15*60 + 30), "-011530"},
+	}
+	for _, tt := range tests {
+		if got := utcOffset(tt.seconds); got != tt.want {
+			t.Errorf("utcOffset(%d) = %q, want %q", tt.seconds, got, tt.want)
+		}
+	}
+}
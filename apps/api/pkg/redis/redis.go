@@ -0,0 +1,213 @@
+// Package redis is a minimal Redis client speaking RESP2, covering what the
+// API needs from Redis: authenticating, selecting a database, PING, PUBLISH
+// and pattern subscriptions.
+package redis
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNil is returned for a nil reply.
+var ErrNil = errors.New("redis: nil reply")
+
+// Error is an error reply from the server.
+type Error string
+
+func (e Error) Error() string { return "redis: " + string(e) }
+
+// Options configures a connection.
+type Options struct {
+	Addr        string // host:port
+	Password    string
+	DB          int
+	DialTimeout time.Duration
+}
+
+// ParseURL reads connection options from a redis:// URL such as
+// "redis://:secret@cache:6379/2", or from a plain "host:port" address.
+// A password or database in the URL is used unless password or db are set.
+func ParseURL(rawURL, password string, db int) (Options, error) {
+	opts := Options{Addr: rawURL, Password: password, DB: db}
+	if !strings.Contains(rawURL, "://") {
+		return opts, nil
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return opts, fmt.Errorf("redis: invalid URL: %w", err)
+	}
+	if u.Scheme != "redis" {
+		return opts, fmt.Errorf("redis: unsupported scheme %q", u.Scheme)
+	}
+
+	opts.Addr = u.Host
+	if u.Port() == "" {
+		opts.Addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	if p, ok := u.User.Password(); ok && opts.Password == "" {
+		opts.Password = p
+	}
+	if path := strings.Trim(u.Path, "/"); path != "" && opts.DB == 0 {
+		n, err := strconv.Atoi(path)
+		if err != nil {
+			return opts, fmt.Errorf("redis: invalid database %q", path)
+		}
+		opts.DB = n
+	}
+	return opts, nil
+}
+
+// Conn is a single connection. It is not safe for concurrent use.
+type Conn struct {
+	nc net.Conn
+	r  *bufio.Reader
+	w  *bufio.Writer
+}
+
+// Dial connects, authenticates and selects the database.
+func Dial(ctx context.Context, opts Options) (*Conn, error) {
+	timeout := opts.DialTimeout
+	if timeout == 0 {
+		timeout = 5 * time.Second
+	}
+	d := net.Dialer{Timeout: timeout}
+	nc, err := d.DialContext(ctx, "tcp", opts.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("redis: failed to connect to %s: %w", opts.Addr, err)
+	}
+
+	c := newConn(nc)
+	if opts.Password != "" {
+		if _, err := c.Do("AUTH", opts.Password); err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+	if opts.DB != 0 {
+		if _, err := c.Do("SELECT", strconv.Itoa(opts.DB)); err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func newConn(nc net.Conn) *Conn {
+	return &Conn{nc: nc, r: bufio.NewReader(nc), w: bufio.NewWriter(nc)}
+}
+
+// Do sends a command and reads its reply. Replies are returned as string,
+// int64, []interface{} or nil; error replies are returned as Error.
+func (c *Conn) Do(args ...string) (interface{}, error) {
+	if err := c.Send(args...); err != nil {
+		return nil, err
+	}
+	reply, err := c.Receive()
+	if err != nil {
+		return nil, err
+	}
+	if e, ok := reply.(Error); ok {
+		return nil, e
+	}
+	return reply, nil
+}
+
+// Send writes a command without waiting for the reply.
+func (c *Conn) Send(args ...string) error {
+	fmt.Fprintf(c.w, "*%d\r\n", len(args))
+	for _, a := range args {
+		fmt.Fprintf(c.w, "$%d\r\n%s\r\n", len(a), a)
+	}
+	return c.w.Flush()
+}
+
+// Receive reads the next reply, such as a pub/sub message.
+func (c *Conn) Receive() (interface{}, error) {
+	line, err := c.readLine()
+	if err != nil {
+		return nil, err
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return Error(line[1:]), nil
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("redis: invalid bulk length %q", line)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(c.r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("redis: invalid array length %q", line)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			if items[i], err = c.Receive(); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("redis: unexpected reply %q", line)
+}
+
+// SetDeadline sets the read and write deadline of the connection.
+func (c *Conn) SetDeadline(t time.Time) error {
+	return c.nc.SetDeadline(t)
+}
+
+// Close closes the connection.
+func (c *Conn) Close() error {
+	return c.nc.Close()
+}
+
+func (c *Conn) readLine() (string, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return "", fmt.Errorf("redis: empty reply")
+	}
+	return line, nil
+}
+
+// String converts a reply to a string.
+func String(reply interface{}, err error) (string, error) {
+	if err != nil {
+		return "", err
+	}
+	switch v := reply.(type) {
+	case string:
+		return v, nil
+	case nil:
+		return "", ErrNil
+	}
+	return "", fmt.Errorf("redis: unexpected reply type %T", reply)
+}
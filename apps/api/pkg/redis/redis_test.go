@@ -0,0 +1,183 @@
+package redis
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakeConn is a net.Conn that replays canned server output and records
+// everything the client writes.
+type fakeConn struct {
+	in  *bytes.Reader
+	out bytes.Buffer
+}
+
+func newFakeConn(replies string) *fakeConn {
+	return &fakeConn{in: bytes.NewReader([]byte(replies))}
+}
+
+func (f *fakeConn) Read(b []byte) (int, error)         { return f.in.Read(b) }
+func (f *fakeConn) Write(b []byte) (int, error)        { return f.out.Write(b) }
+func (f *fakeConn) Close() error                       { return nil }
+func (f *fakeConn) LocalAddr() net.Addr                { return nil }
+func (f *fakeConn) RemoteAddr() net.Addr               { return nil }
+func (f *fakeConn) SetDeadline(t time.Time) error      { return nil }
+func (f *fakeConn) SetReadDeadline(t time.Time) error  { return nil }
+func (f *fakeConn) SetWriteDeadline(t time.Time) error { return nil }
+
+func TestSend(t *testing.T) {
+	fc := newFakeConn("")
+	c := newConn(fc)
+
+	if err := c.Send("PUBLISH", "status-board:c1", "hello world"); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	want := "*3\r\n$7\r\nPUBLISH\r\n$15\r\nstatus-board:c1\r\n$11\r\nhello world\r\n"
+	if got := fc.out.String(); got != want {
+		t.Errorf("wrote %q, want %q", got, want)
+	}
+}
+
+func TestDo(t *testing.T) {
+	tests := []struct {
+		name    string
+		reply   string
+		want    interface{}
+		wantErr error
+	}{
+		{"simple string", "+PONG\r\n", "PONG", nil},
+		{"integer", ":3\r\n", int64(3), nil},
+		{"bulk string", "$5\r\nhello\r\n", "hello", nil},
+		{"bulk string with CRLF", "$7\r\nab\r\ncde\r\n", "ab\r\ncde", nil},
+		{"empty bulk string", "$0\r\n\r\n", "", nil},
+		{"nil bulk string", "$-1\r\n", nil, nil},
+		{"nil array", "*-1\r\n", nil, nil},
+		{"empty array", "*0\r\n", []interface{}{}, nil},
+		{
+			"pub/sub message",
+			"*4\r\n$8\r\npmessage\r\n$14\r\nstatus-board:*\r\n$15\r\nstatus-board:c1\r\n$2\r\n{}\r\n",
+			[]interface{}{"pmessage", "status-board:*", "status-board:c1", "{}"},
+			nil,
+		},
+		{
+			"nested array",
+			"*2\r\n:1\r\n*2\r\n+OK\r\n$-1\r\n",
+			[]interface{}{int64(1), []interface{}{"OK", nil}},
+			nil,
+		},
+		{"error reply", "-WRONGPASS invalid password\r\n", nil, Error("WRONGPASS invalid password")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newConn(newFakeConn(tt.reply))
+			got, err := c.Do("PING")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Do: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("reply = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReceiveErrorReplyInArray(t *testing.T) {
+	// Error replies nested in an array are values, not failures of Receive.
+	c := newConn(newFakeConn("*2\r\n+OK\r\n-ERR no such key\r\n"))
+	got, err := c.Receive()
+	if err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+	want := []interface{}{"OK", Error("ERR no such key")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("reply = %#v, want %#v", got, want)
+	}
+}
+
+func TestReceiveMalformed(t *testing.T) {
+	tests := []struct {
+		name  string
+		reply string
+	}{
+		{"unknown type", "?oops\r\n"},
+		{"empty line", "\r\n"},
+		{"bad bulk length", "$x\r\n"},
+		{"bad array length", "*x\r\n"},
+		{"bad integer", ":x\r\n"},
+		{"truncated bulk", "$10\r\nshort\r\n"},
+		{"truncated array", "*2\r\n+OK\r\n"},
+		{"connection closed", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newConn(newFakeConn(tt.reply))
+			if _, err := c.Receive(); err == nil {
+				t.Errorf("Receive(%q) returned no error", tt.reply)
+			}
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	if s, err := String("hello", nil); err != nil || s != "hello" {
+		t.Errorf("String(hello) = %q, %v", s, err)
+	}
+	if _, err := String(nil, nil); !errors.Is(err, ErrNil) {
+		t.Errorf("String(nil) err = %v, want ErrNil", err)
+	}
+	if _, err := String(int64(1), nil); err == nil {
+		t.Error("String(int64) returned no error")
+	}
+	replyErr := Error("ERR boom")
+	if _, err := String(nil, replyErr); !errors.Is(err, replyErr) {
+		t.Errorf("String passed err = %v, want %v", err, replyErr)
+	}
+}
+
+func TestParseURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		password string
+		db       int
+		want     Options
+		wantErr  bool
+	}{
+		{"plain address", "cache:6379", "", 0, Options{Addr: "cache:6379"}, false},
+		{"url with password and db", "redis://:secret@cache:6380/2", "", 0, Options{Addr: "cache:6380", Password: "secret", DB: 2}, false},
+		{"default port", "redis://cache", "", 0, Options{Addr: "cache:6379"}, false},
+		{"explicit settings win", "redis://:secret@cache/2", "override", 5, Options{Addr: "cache:6379", Password: "override", DB: 5}, false},
+		{"unsupported scheme", "rediss://cache:6379", "", 0, Options{}, true},
+		{"invalid database", "redis://cache:6379/abc", "", 0, Options{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseURL(tt.url, tt.password, tt.db)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseURL(%q) returned no error", tt.url)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseURL(%q): %v", tt.url, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseURL(%q) = %+v, want %+v", tt.url, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,479 @@
+// Package rrule parses and expands the subset of RFC 5545 recurrence rules
+// used for appointment series: FREQ (DAILY, WEEKLY, MONTHLY), INTERVAL,
+// BYDAY, BYMONTHDAY, COUNT and UNTIL, with EXDATE exclusions.
+package rrule
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency is the FREQ rule part.
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+)
+
+// ErrUnbounded is returned when a rule has neither COUNT nor UNTIL.
+var ErrUnbounded = errors.New("recurrence rule must set COUNT or UNTIL")
+
+// ErrTooManyOccurrences is returned when a rule expands past the caller's limit.
+var ErrTooManyOccurrences = errors.New("recurrence rule produces too many occurrences")
+
+// WeekdayNum is a BYDAY entry such as "MO", or "2TU" / "-1FR" in monthly rules.
+type WeekdayNum struct {
+	Weekday time.Weekday
+	N       int // 0 means every matching weekday in the period
+}
+
+// Rule is a parsed recurrence rule.
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	Count      int
+	Until      time.Time
+
+	// untilFloating is set when UNTIL has no UTC designator; it is then
+	// interpreted in the location of DTSTART.
+	untilFloating bool
+	untilDateOnly bool
+}
+
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+var weekdayNames = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// Parse parses an RRULE value such as "FREQ=WEEKLY;BYDAY=MO,WE,FR;COUNT=18".
+// A leading "RRULE:" is accepted.
+func Parse(s string) (*Rule, error) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "RRULE:"), "rrule:")
+	if s == "" {
+		return nil, errors.New("empty recurrence rule")
+	}
+
+	r := &Rule{Interval: 1}
+	seen := make(map[string]bool)
+
+	for _, part := range strings.Split(s, ";") {
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid rule part %q", part)
+		}
+		key, value := strings.ToUpper(strings.TrimSpace(kv[0])), strings.ToUpper(strings.TrimSpace(kv[1]))
+		if seen[key] {
+			return nil, fmt.Errorf("duplicate rule part %s", key)
+		}
+		seen[key] = true
+
+		switch key {
+		case "FREQ":
+			switch Frequency(value) {
+			case Daily, Weekly, Monthly:
+				r.Freq = Frequency(value)
+			default:
+				return nil, fmt.Errorf("unsupported FREQ %q", value)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid INTERVAL %q", value)
+			}
+			r.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid COUNT %q", value)
+			}
+			r.Count = n
+		case "UNTIL":
+			if err := r.parseUntil(value); err != nil {
+				return nil, err
+			}
+		case "BYDAY":
+			for _, code := range strings.Split(value, ",") {
+				wd, err := parseWeekdayNum(code)
+				if err != nil {
+					return nil, err
+				}
+				r.ByDay = append(r.ByDay, wd)
+			}
+		case "BYMONTHDAY":
+			for _, v := range strings.Split(value, ",") {
+				n, err := strconv.Atoi(v)
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return nil, fmt.Errorf("invalid BYMONTHDAY %q", v)
+				}
+				r.ByMonthDay = append(r.ByMonthDay, n)
+			}
+		case "WKST":
+			if value != "MO" {
+				return nil, fmt.Errorf("unsupported WKST %q", value)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported rule part %s", key)
+		}
+	}
+
+	if r.Freq == "" {
+		return nil, errors.New("recurrence rule requires FREQ")
+	}
+	if r.Count > 0 && !r.Until.IsZero() {
+		return nil, errors.New("COUNT and UNTIL cannot both be set")
+	}
+	if r.Freq != Monthly {
+		for _, wd := range r.ByDay {
+			if wd.N != 0 {
+				return nil, fmt.Errorf("numbered BYDAY is only supported with FREQ=MONTHLY")
+			}
+		}
+		if len(r.ByMonthDay) > 0 {
+			return nil, fmt.Errorf("BYMONTHDAY is only supported with FREQ=MONTHLY")
+		}
+	}
+
+	return r, nil
+}
+
+// parseUntil accepts DATE ("20261231"), floating DATE-TIME ("20261231T090000")
+// and UTC DATE-TIME ("20261231T090000Z") values.
+func (r *Rule) parseUntil(value string) error {
+	var err error
+	switch {
+	case len(value) == 8:
+		r.Until, err = time.Parse("20060102", value)
+		r.untilFloating, r.untilDateOnly = true, true
+	case strings.HasSuffix(value, "Z"):
+		r.Until, err = time.Parse("20060102T150405Z", value)
+	default:
+		r.Until, err = time.Parse("20060102T150405", value)
+		r.untilFloating = true
+	}
+	if err != nil {
+		return fmt.Errorf("invalid UNTIL %q", value)
+	}
+	return nil
+}
+
+func parseWeekdayNum(code string) (WeekdayNum, error) {
+	code = strings.TrimSpace(code)
+	if len(code) < 2 {
+		return WeekdayNum{}, fmt.Errorf("invalid BYDAY %q", code)
+	}
+	wd, ok := weekdayCodes[code[len(code)-2:]]
+	if !ok {
+		return WeekdayNum{}, fmt.Errorf("invalid BYDAY %q", code)
+	}
+	n := 0
+	if prefix := code[:len(code)-2]; prefix != "" {
+		v, err := strconv.Atoi(prefix)
+		if err != nil || v == 0 || v < -5 || v > 5 {
+			return WeekdayNum{}, fmt.Errorf("invalid BYDAY %q", code)
+		}
+		n = v
+	}
+	return WeekdayNum{Weekday: wd, N: n}, nil
+}
+
+// String returns the rule in canonical RRULE value form.
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		codes := make([]string, len(r.ByDay))
+		for i, wd := range r.ByDay {
+			codes[i] = wd.String()
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, len(r.ByMonthDay))
+		for i, d := range r.ByMonthDay {
+			days[i] = strconv.Itoa(d)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		switch {
+		case r.untilDateOnly:
+			parts = append(parts, "UNTIL="+r.Until.Format("20060102"))
+		case r.untilFloating:
+			parts = append(parts, "UNTIL="+r.Until.Format("20060102T150405"))
+		default:
+			parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+		}
+	}
+	return strings.Join(parts, ";")
+}
+
+// String returns the BYDAY code, e.g. "MO" or "-1FR".
+func (w WeekdayNum) String() string {
+	if w.N == 0 {
+		return weekdayNames[w.Weekday]
+	}
+	return strconv.Itoa(w.N) + weekdayNames[w.Weekday]
+}
+
+// Clone returns a copy of the rule.
+func (r *Rule) Clone() *Rule {
+	c := *r
+	c.ByDay = append([]WeekdayNum(nil), r.ByDay...)
+	c.ByMonthDay = append([]int(nil), r.ByMonthDay...)
+	return &c
+}
+
+// ShiftDays moves the weekdays of the rule by n days, for use when every
+// occurrence of a series moves by the same number of days. Rules that pick
+// numbered weekdays or days of the month cannot be shifted.
+func (r *Rule) ShiftDays(n int) error {
+	if n == 0 {
+		return nil
+	}
+	if len(r.ByMonthDay) > 0 {
+		return errors.New("cannot move a BYMONTHDAY rule to another day")
+	}
+	for i, wd := range r.ByDay {
+		if wd.N != 0 {
+			return errors.New("cannot move a numbered BYDAY rule to another day")
+		}
+		r.ByDay[i].Weekday = time.Weekday(((int(wd.Weekday)+n)%7 + 7) % 7)
+	}
+	return nil
+}
+
+// SetCount bounds the rule to n occurrences and clears UNTIL.
+func (r *Rule) SetCount(n int) {
+	r.Count = n
+	r.Until = time.Time{}
+	r.untilFloating = false
+	r.untilDateOnly = false
+}
+
+// SetUntil bounds the rule so that no occurrence starts at or after t, and
+// clears COUNT. It is used to end a series early.
+func (r *Rule) SetUntil(t time.Time) {
+	r.Count = 0
+	r.Until = t.Add(-time.Second).UTC()
+	r.untilFloating = false
+	r.untilDateOnly = false
+}
+
+// Matches reports whether the rule recurs at dtstart, e.g. whether a start on
+// a Wednesday is one of the days of BYDAY=TU,WE.
+func (r *Rule) Matches(dtstart time.Time) bool {
+	for _, t := range r.candidates(dtstart, 0) {
+		if t.Equal(dtstart) {
+			return true
+		}
+	}
+	return false
+}
+
+// Expand returns the start times of the occurrences of the rule beginning at
+// dtstart, in order, with exdates removed. Exdates are matched as instants.
+// Occurrences keep dtstart's wall clock time in dtstart's location, so they
+// stay at the same local time across daylight saving changes. Excluded dates
+// count towards COUNT, as in RFC 5545. Unlike RFC 5545, DTSTART is only an
+// occurrence when the rule matches it, so a Wednesday DTSTART with BYDAY=TU
+// starts the series on the following Tuesday. An error is returned if the
+// rule is unbounded or yields more than limit occurrences.
+func (r *Rule) Expand(dtstart time.Time, exdates []time.Time, limit int) ([]time.Time, error) {
+	if r.Count == 0 && r.Until.IsZero() {
+		return nil, ErrUnbounded
+	}
+
+	loc := dtstart.Location()
+	until := r.until(loc)
+
+	excluded := make(map[int64]bool, len(exdates))
+	for _, ex := range exdates {
+		excluded[ex.Unix()] = true
+	}
+
+	var occurrences []time.Time
+	generated := 0
+	emit := func(t time.Time) (done bool, err error) {
+		if !until.IsZero() && t.After(until) {
+			return true, nil
+		}
+		generated++
+		if !excluded[t.Unix()] {
+			if len(occurrences) >= limit {
+				return true, ErrTooManyOccurrences
+			}
+			occurrences = append(occurrences, t)
+		}
+		return r.Count > 0 && generated >= r.Count, nil
+	}
+
+	if r.Matches(dtstart) {
+		if done, err := emit(dtstart); done || err != nil {
+			return occurrences, err
+		}
+	}
+
+	// Bound the search so rules that never match (e.g. BYMONTHDAY=31 with
+	// INTERVAL=2 from an even month) cannot loop forever.
+	const maxPeriods = 5000
+	for period := 0; period < maxPeriods; period++ {
+		for _, t := range r.candidates(dtstart, period) {
+			if !t.After(dtstart) {
+				continue
+			}
+			done, err := emit(t)
+			if err != nil {
+				return nil, err
+			}
+			if done {
+				return occurrences, nil
+			}
+		}
+	}
+
+	return occurrences, nil
+}
+
+// until resolves UNTIL in the location of DTSTART.
+func (r *Rule) until(loc *time.Location) time.Time {
+	if r.Until.IsZero() || !r.untilFloating {
+		return r.Until
+	}
+	u := r.Until
+	if r.untilDateOnly {
+		return time.Date(u.Year(), u.Month(), u.Day(), 23, 59, 59, 0, loc)
+	}
+	return time.Date(u.Year(), u.Month(), u.Day(), u.Hour(), u.Minute(), u.Second(), 0, loc)
+}
+
+// candidates returns the sorted occurrence times in the given period (day,
+// week or month) counted in INTERVAL steps from the period containing dtstart.
+func (r *Rule) candidates(dtstart time.Time, period int) []time.Time {
+	y, m, d := dtstart.Date()
+	hh, mm, ss := dtstart.Clock()
+	loc := dtstart.Location()
+	at := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, hh, mm, ss, 0, loc)
+	}
+
+	switch r.Freq {
+	case Daily:
+		t := at(y, m, d+period*r.Interval)
+		if len(r.ByDay) > 0 && !r.hasWeekday(t.Weekday()) {
+			return nil
+		}
+		return []time.Time{t}
+
+	case Weekly:
+		// Weeks start on Monday (WKST=MO)
+		offset := (int(dtstart.Weekday()) + 6) % 7
+		weekStart := d - offset + period*r.Interval*7
+		days := r.ByDay
+		if len(days) == 0 {
+			days = []WeekdayNum{{Weekday: dtstart.Weekday()}}
+		}
+		out := make([]time.Time, 0, len(days))
+		for _, wd := range days {
+			out = append(out, at(y, m, weekStart+(int(wd.Weekday)+6)%7))
+		}
+		sortTimes(out)
+		return out
+
+	case Monthly:
+		first := time.Date(y, m+time.Month(period*r.Interval), 1, 0, 0, 0, 0, loc)
+		my, mm := first.Year(), first.Month()
+		daysIn := time.Date(my, mm+1, 0, 0, 0, 0, 0, loc).Day()
+
+		var days []int
+		switch {
+		case len(r.ByDay) > 0:
+			for _, wd := range r.ByDay {
+				days = append(days, monthWeekdays(my, mm, daysIn, wd, loc)...)
+			}
+		case len(r.ByMonthDay) > 0:
+			for _, md := range r.ByMonthDay {
+				if md < 0 {
+					md = daysIn + md + 1
+				}
+				if md >= 1 && md <= daysIn {
+					days = append(days, md)
+				}
+			}
+		default:
+			if d <= daysIn {
+				days = []int{d}
+			}
+		}
+
+		seen := make(map[int]bool, len(days))
+		out := make([]time.Time, 0, len(days))
+		for _, day := range days {
+			if !seen[day] {
+				seen[day] = true
+				out = append(out, at(my, mm, day))
+			}
+		}
+		sortTimes(out)
+		return out
+	}
+
+	return nil
+}
+
+func (r *Rule) hasWeekday(wd time.Weekday) bool {
+	for _, d := range r.ByDay {
+		if d.Weekday == wd {
+			return true
+		}
+	}
+	return false
+}
+
+// monthWeekdays returns the days of the month matching a BYDAY entry.
+func monthWeekdays(y int, m time.Month, daysIn int, wd WeekdayNum, loc *time.Location) []int {
+	firstWeekday := time.Date(y, m, 1, 0, 0, 0, 0, loc).Weekday()
+	firstMatch := 1 + (int(wd.Weekday)-int(firstWeekday)+7)%7
+
+	var all []int
+	for day := firstMatch; day <= daysIn; day += 7 {
+		all = append(all, day)
+	}
+
+	switch {
+	case wd.N == 0:
+		return all
+	case wd.N > 0 && wd.N <= len(all):
+		return []int{all[wd.N-1]}
+	case wd.N < 0 && -wd.N <= len(all):
+		return []int{all[len(all)+wd.N]}
+	}
+	return nil
+}
+
+func sortTimes(ts []time.Time) {
+	sort.Slice(ts, func(i, j int) bool { return ts[i].Before(ts[j]) })
+}
@@ -0,0 +1,289 @@
+package rrule
+
+import (
+	"errors"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestExpand(t *testing.T) {
+	utc := time.UTC
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("LoadLocation() error = %v", err)
+	}
+	at := func(loc *time.Location, y int, m time.Month, d, hh int) time.Time {
+		return time.Date(y, m, d, hh, 0, 0, 0, loc)
+	}
+
+	tests := []struct {
+		name    string
+		rule    string
+		dtstart time.Time
+		exdates []time.Time
+		want    []time.Time
+	}{
+		{
+			name:    "daily count",
+			rule:    "FREQ=DAILY;COUNT=3",
+			dtstart: at(utc, 2026, 10, 5, 9),
+			want:    []time.Time{at(utc, 2026, 10, 5, 9), at(utc, 2026, 10, 6, 9), at(utc, 2026, 10, 7, 9)},
+		},
+		{
+			name:    "weekly byday",
+			rule:    "FREQ=WEEKLY;BYDAY=MO,WE,FR;COUNT=5",
+			dtstart: at(utc, 2026, 10, 5, 9), // Monday
+			want: []time.Time{
+				at(utc, 2026, 10, 5, 9), at(utc, 2026, 10, 7, 9), at(utc, 2026, 10, 9, 9),
+				at(utc, 2026, 10, 12, 9), at(utc, 2026, 10, 14, 9),
+			},
+		},
+		{
+			name:    "biweekly byday",
+			rule:    "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU,TH;COUNT=4",
+			dtstart: at(utc, 2026, 10, 6, 9), // Tuesday
+			want: []time.Time{
+				at(utc, 2026, 10, 6, 9), at(utc, 2026, 10, 8, 9),
+				at(utc, 2026, 10, 20, 9), at(utc, 2026, 10, 22, 9),
+			},
+		},
+		{
+			name:    "dtstart not in byday is skipped",
+			rule:    "FREQ=WEEKLY;BYDAY=TU;COUNT=2",
+			dtstart: at(utc, 2026, 10, 7, 9), // Wednesday
+			want:    []time.Time{at(utc, 2026, 10, 13, 9), at(utc, 2026, 10, 20, 9)},
+		},
+		{
+			name:    "until date is inclusive",
+			rule:    "FREQ=WEEKLY;UNTIL=20261019",
+			dtstart: at(utc, 2026, 10, 5, 9),
+			want:    []time.Time{at(utc, 2026, 10, 5, 9), at(utc, 2026, 10, 12, 9), at(utc, 2026, 10, 19, 9)},
+		},
+		{
+			name:    "until utc date-time",
+			rule:    "FREQ=DAILY;UNTIL=20261006T090000Z",
+			dtstart: at(utc, 2026, 10, 5, 9),
+			want:    []time.Time{at(utc, 2026, 10, 5, 9), at(utc, 2026, 10, 6, 9)},
+		},
+		{
+			name:    "exdates count towards count",
+			rule:    "FREQ=DAILY;COUNT=4",
+			dtstart: at(utc, 2026, 10, 5, 9),
+			exdates: []time.Time{at(utc, 2026, 10, 6, 9), at(utc, 2026, 10, 8, 9)},
+			want:    []time.Time{at(utc, 2026, 10, 5, 9), at(utc, 2026, 10, 7, 9)},
+		},
+		{
+			name:    "exdates match instants in any location",
+			rule:    "FREQ=DAILY;COUNT=2",
+			dtstart: at(berlin, 2026, 6, 1, 9),
+			exdates: []time.Time{at(utc, 2026, 6, 2, 7)},
+			want:    []time.Time{at(berlin, 2026, 6, 1, 9)},
+		},
+		{
+			name:    "wall clock kept across dst end",
+			rule:    "FREQ=WEEKLY;COUNT=2",
+			dtstart: at(berlin, 2026, 10, 20, 9), // CEST, UTC+2
+			want:    []time.Time{at(berlin, 2026, 10, 20, 9), at(berlin, 2026, 10, 27, 9)},
+		},
+		{
+			name:    "bymonthday skips short months",
+			rule:    "FREQ=MONTHLY;BYMONTHDAY=31;COUNT=3",
+			dtstart: at(utc, 2027, 1, 31, 9),
+			want:    []time.Time{at(utc, 2027, 1, 31, 9), at(utc, 2027, 3, 31, 9), at(utc, 2027, 5, 31, 9)},
+		},
+		{
+			name:    "negative bymonthday",
+			rule:    "FREQ=MONTHLY;BYMONTHDAY=-1;COUNT=3",
+			dtstart: at(utc, 2027, 1, 31, 9),
+			want:    []time.Time{at(utc, 2027, 1, 31, 9), at(utc, 2027, 2, 28, 9), at(utc, 2027, 3, 31, 9)},
+		},
+		{
+			name:    "last friday",
+			rule:    "FREQ=MONTHLY;BYDAY=-1FR;COUNT=3",
+			dtstart: at(utc, 2026, 10, 30, 9),
+			want:    []time.Time{at(utc, 2026, 10, 30, 9), at(utc, 2026, 11, 27, 9), at(utc, 2026, 12, 25, 9)},
+		},
+		{
+			name:    "second tuesday",
+			rule:    "FREQ=MONTHLY;BYDAY=2TU;COUNT=2",
+			dtstart: at(utc, 2026, 10, 1, 9),
+			want:    []time.Time{at(utc, 2026, 10, 13, 9), at(utc, 2026, 11, 10, 9)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.rule, err)
+			}
+			got, err := rule.Expand(tt.dtstart, tt.exdates, 100)
+			if err != nil {
+				t.Fatalf("Expand() error = %v", err)
+			}
+			if !equalTimes(got, tt.want) {
+				t.Errorf("Expand() = %v; want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("dst offsets", func(t *testing.T) {
+		rule, _ := Parse("FREQ=WEEKLY;COUNT=2")
+		got, _ := rule.Expand(at(berlin, 2026, 10, 20, 9), nil, 100)
+		if got[0].UTC().Hour() != 7 || got[1].UTC().Hour() != 8 {
+			t.Errorf("Expand() UTC hours = %d, %d; want 7 and 8", got[0].UTC().Hour(), got[1].UTC().Hour())
+		}
+	})
+}
+
+func TestExpandErrors(t *testing.T) {
+	dtstart := time.Date(2026, 10, 5, 9, 0, 0, 0, time.UTC)
+
+	unbounded, _ := Parse("FREQ=DAILY")
+	if _, err := unbounded.Expand(dtstart, nil, 100); !errors.Is(err, ErrUnbounded) {
+		t.Errorf("Expand() unbounded error = %v; want ErrUnbounded", err)
+	}
+
+	long, _ := Parse("FREQ=DAILY;COUNT=10")
+	if _, err := long.Expand(dtstart, nil, 5); !errors.Is(err, ErrTooManyOccurrences) {
+		t.Errorf("Expand() past limit error = %v; want ErrTooManyOccurrences", err)
+	}
+}
+
+func TestMatches(t *testing.T) {
+	wednesday := time.Date(2026, 10, 7, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		rule string
+		want bool
+	}{
+		{"FREQ=WEEKLY;COUNT=2", true},
+		{"FREQ=WEEKLY;BYDAY=TU,WE;COUNT=2", true},
+		{"FREQ=WEEKLY;BYDAY=TU;COUNT=2", false},
+		{"FREQ=DAILY;BYDAY=MO;COUNT=2", false},
+		{"FREQ=MONTHLY;BYMONTHDAY=7;COUNT=2", true},
+		{"FREQ=MONTHLY;BYDAY=1WE;COUNT=2", true},
+		{"FREQ=MONTHLY;BYDAY=-1WE;COUNT=2", false},
+	}
+
+	for _, tt := range tests {
+		rule, err := Parse(tt.rule)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", tt.rule, err)
+		}
+		if got := rule.Matches(wednesday); got != tt.want {
+			t.Errorf("Matches(%q) = %v; want %v", tt.rule, got, tt.want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{value: "RRULE:freq=weekly;byday=mo,we;count=4", want: "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=4"},
+		{value: "FREQ=MONTHLY;BYDAY=-1FR;UNTIL=20261231T170000Z", want: "FREQ=MONTHLY;BYDAY=-1FR;UNTIL=20261231T170000Z"},
+		{value: "FREQ=DAILY;INTERVAL=2;UNTIL=20261231", want: "FREQ=DAILY;INTERVAL=2;UNTIL=20261231"},
+		{value: "FREQ=YEARLY;COUNT=2", wantErr: true},
+		{value: "FREQ=WEEKLY;BYDAY=2MO;COUNT=2", wantErr: true},
+		{value: "FREQ=WEEKLY;BYMONTHDAY=1;COUNT=2", wantErr: true},
+		{value: "FREQ=DAILY;COUNT=2;UNTIL=20261231", wantErr: true},
+		{value: "FREQ=DAILY;COUNT=2;COUNT=3", wantErr: true},
+		{value: "COUNT=2", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		rule, err := Parse(tt.value)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Parse(%q) = %v; want error", tt.value, rule)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Parse(%q) error = %v", tt.value, err)
+			continue
+		}
+		if got := rule.String(); got != tt.want {
+			t.Errorf("Parse(%q).String() = %q; want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestShiftDays(t *testing.T) {
+	tests := []struct {
+		rule    string
+		days    int
+		want    string
+		wantErr bool
+	}{
+		{rule: "FREQ=WEEKLY;BYDAY=MO,WE,FR;COUNT=6", days: 1, want: "FREQ=WEEKLY;BYDAY=TU,TH,SA;COUNT=6"},
+		{rule: "FREQ=WEEKLY;BYDAY=SA,SU;COUNT=6", days: 1, want: "FREQ=WEEKLY;BYDAY=SU,MO;COUNT=6"},
+		{rule: "FREQ=WEEKLY;BYDAY=MO;COUNT=6", days: -8, want: "FREQ=WEEKLY;BYDAY=SU;COUNT=6"},
+		{rule: "FREQ=DAILY;COUNT=6", days: 3, want: "FREQ=DAILY;COUNT=6"},
+		{rule: "FREQ=MONTHLY;BYDAY=-1FR;COUNT=6", days: 0, want: "FREQ=MONTHLY;BYDAY=-1FR;COUNT=6"},
+		{rule: "FREQ=MONTHLY;BYDAY=-1FR;COUNT=6", days: 1, wantErr: true},
+		{rule: "FREQ=MONTHLY;BYMONTHDAY=15;COUNT=6", days: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		rule, err := Parse(tt.rule)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", tt.rule, err)
+		}
+		err = rule.ShiftDays(tt.days)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ShiftDays(%q, %d) = %q; want error", tt.rule, tt.days, rule)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ShiftDays(%q, %d) error = %v", tt.rule, tt.days, err)
+			continue
+		}
+		if got := rule.String(); got != tt.want {
+			t.Errorf("ShiftDays(%q, %d) = %q; want %q", tt.rule, tt.days, got, tt.want)
+		}
+	}
+}
+
+func TestCloneAndBounds(t *testing.T) {
+	rule, _ := Parse("FREQ=WEEKLY;BYDAY=MO,TH;UNTIL=20261231")
+
+	clone := rule.Clone()
+	if err := clone.ShiftDays(1); err != nil {
+		t.Fatalf("ShiftDays() error = %v", err)
+	}
+	if got := rule.String(); got != "FREQ=WEEKLY;BYDAY=MO,TH;UNTIL=20261231" {
+		t.Errorf("shifting a clone changed the rule to %q", got)
+	}
+
+	clone.SetCount(4)
+	if got, want := clone.String(), "FREQ=WEEKLY;BYDAY=TU,FR;COUNT=4"; got != want {
+		t.Errorf("SetCount() = %q; want %q", got, want)
+	}
+
+	rule.SetUntil(time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC))
+	if got, want := rule.String(), "FREQ=WEEKLY;BYDAY=MO,TH;UNTIL=20261019T085959Z"; got != want {
+		t.Errorf("SetUntil() = %q; want %q", got, want)
+	}
+	got, err := rule.Expand(time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC), nil, 100)
+	if err != nil || len(got) != 2 {
+		t.Errorf("Expand() after SetUntil = %v, %v; want October 12 and 15 only", got, err)
+	}
+}
+
+func equalTimes(a, b []time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,26 @@
+-- Migration: 005_patient_portal.sql
+-- Description: Link patients to Keycloak accounts for the patient self-service portal
+-- Created: 2026-10-18
+
+-- =============================================================================
+-- PATIENT ACCOUNTS
+-- =============================================================================
+
+ALTER TABLE patients ADD COLUMN keycloak_id UUID UNIQUE;
+
+COMMENT ON COLUMN patients.keycloak_id IS 'Keycloak subject of the patient portal account linked to this record';
+
+-- =============================================================================
+-- PORTAL POLICY
+-- =============================================================================
+
+/*
+Self-service rules are read from clinics.settings->'patient_portal':
+{
+    "cancel_notice_hours": 24,       -- Minimum notice for a patient cancellation
+    "reschedule_notice_hours": 24,   -- Minimum notice before the original slot
+    "allow_reschedule": true,        -- Whether patients may move their own appointments
+    "max_advance_days": 60           -- How far ahead a patient may reschedule to
+}
+*/
+COMMENT ON COLUMN clinics.settings IS 'Clinic-specific settings (hours, services, patient_portal policy, etc.)';
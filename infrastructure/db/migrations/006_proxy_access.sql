@@ -0,0 +1,80 @@
+-- Migration: 006_proxy_access.sql
+-- Description: Caregiver/family proxy access grants and the audit trail for actions taken on behalf of patients
+-- Created: 2026-10-18
+
+-- =============================================================================
+-- PATIENT PROXY GRANTS
+-- =============================================================================
+
+CREATE TABLE patient_proxy_grants (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    clinic_id UUID NOT NULL REFERENCES clinics(id) ON DELETE CASCADE,
+    patient_id UUID NOT NULL REFERENCES patients(id) ON DELETE CASCADE,
+
+    -- Caregiver identity (Keycloak subject)
+    proxy_user_id UUID NOT NULL,
+    proxy_name VARCHAR(200) NOT NULL,
+    relationship VARCHAR(100),
+    phone VARCHAR(20),
+
+    -- Permissions
+    scopes TEXT[] NOT NULL,
+    starts_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+
+    -- Revocation
+    revoked_at TIMESTAMPTZ,
+    revoked_by VARCHAR(100),
+
+    -- Audit
+    granted_by VARCHAR(100) NOT NULL,
+    granted_by_patient BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_proxy_grant_window CHECK (expires_at > starts_at)
+);
+
+CREATE INDEX idx_proxy_grants_patient_id ON patient_proxy_grants (patient_id);
+CREATE INDEX idx_proxy_grants_proxy_user ON patient_proxy_grants (proxy_user_id, patient_id)
+    WHERE revoked_at IS NULL;
+
+CREATE TRIGGER trg_patient_proxy_grants_updated_at
+    BEFORE UPDATE ON patient_proxy_grants
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE patient_proxy_grants IS 'Scoped, time-limited permissions for a caregiver to act on a patient''s behalf';
+COMMENT ON COLUMN patient_proxy_grants.scopes IS 'Permitted actions: view_profile, view_appointments, manage_appointments, view_exercises, log_exercises, view_progress, message_clinic';
+COMMENT ON COLUMN patient_proxy_grants.granted_by IS 'Keycloak subject of the patient or staff member who created the grant';
+
+-- =============================================================================
+-- AUDIT LOG
+-- =============================================================================
+
+CREATE TABLE audit_logs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    clinic_id UUID REFERENCES clinics(id) ON DELETE SET NULL,
+
+    -- Who acted, and on whose behalf
+    actor_id VARCHAR(100) NOT NULL,
+    patient_id UUID REFERENCES patients(id) ON DELETE SET NULL,
+    proxy_grant_id UUID REFERENCES patient_proxy_grants(id) ON DELETE SET NULL,
+
+    -- What happened
+    action VARCHAR(100) NOT NULL,
+    resource_type VARCHAR(50),
+    resource_id VARCHAR(100),
+    status_code INTEGER,
+    metadata JSONB DEFAULT '{}',
+    ip_address VARCHAR(45),
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_audit_logs_patient_id ON audit_logs (patient_id, created_at DESC);
+CREATE INDEX idx_audit_logs_actor_id ON audit_logs (actor_id, created_at DESC);
+CREATE INDEX idx_audit_logs_proxy_grant_id ON audit_logs (proxy_grant_id) WHERE proxy_grant_id IS NOT NULL;
+
+COMMENT ON TABLE audit_logs IS 'Append-only trail of sensitive actions, including every action taken by a proxy';
+COMMENT ON COLUMN audit_logs.actor_id IS 'Keycloak subject of the user who performed the action';
+COMMENT ON COLUMN audit_logs.patient_id IS 'Patient the action was performed on or on behalf of';
@@ -0,0 +1,83 @@
+-- Migration: 007_consent.sql
+-- Description: Versioned consent texts and patient consent records (Decree 13/2023/ND-CP on personal data protection)
+-- Created: 2026-10-18
+
+-- =============================================================================
+-- CONSENT TEMPLATES
+-- =============================================================================
+
+CREATE TABLE consent_templates (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    clinic_id UUID REFERENCES clinics(id) ON DELETE CASCADE,  -- NULL for global templates
+
+    consent_type VARCHAR(50) NOT NULL,
+    version INTEGER NOT NULL,
+    is_current BOOLEAN NOT NULL DEFAULT TRUE,
+
+    -- Bilingual text shown to the patient
+    title VARCHAR(255) NOT NULL,
+    title_vi VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    body_vi TEXT NOT NULL,
+
+    -- Audit
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_by VARCHAR(100),
+
+    CONSTRAINT chk_consent_template_type CHECK (
+        consent_type IN ('treatment', 'data_processing', 'marketing_sms', 'marketing_zalo', 'photo_use')
+    )
+);
+
+CREATE UNIQUE INDEX idx_consent_templates_version
+    ON consent_templates (COALESCE(clinic_id, '00000000-0000-0000-0000-000000000000'::uuid), consent_type, version);
+CREATE INDEX idx_consent_templates_current
+    ON consent_templates (consent_type, clinic_id) WHERE is_current = TRUE;
+
+COMMENT ON TABLE consent_templates IS 'Versioned bilingual consent texts; published versions are never edited';
+COMMENT ON COLUMN consent_templates.consent_type IS 'treatment, data_processing, marketing_sms, marketing_zalo, photo_use';
+
+-- =============================================================================
+-- PATIENT CONSENTS
+-- =============================================================================
+
+CREATE TABLE patient_consents (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    clinic_id UUID NOT NULL REFERENCES clinics(id) ON DELETE CASCADE,
+    patient_id UUID NOT NULL REFERENCES patients(id) ON DELETE CASCADE,
+    template_id UUID NOT NULL REFERENCES consent_templates(id),
+
+    consent_type VARCHAR(50) NOT NULL,
+    template_version INTEGER NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active',  -- active, withdrawn, superseded
+
+    -- Signature capture (same shape as checklist signature responses)
+    signature JSONB NOT NULL,
+    signed_by_name VARCHAR(200),
+    signer_relationship VARCHAR(100),  -- NULL when the patient signs personally
+    granted_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    recorded_by VARCHAR(100) NOT NULL,
+
+    -- Withdrawal
+    withdrawn_at TIMESTAMPTZ,
+    withdrawn_by VARCHAR(100),
+    withdrawal_reason TEXT,
+
+    -- Audit
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_patient_consent_status CHECK (status IN ('active', 'withdrawn', 'superseded'))
+);
+
+CREATE INDEX idx_patient_consents_patient_id ON patient_consents (patient_id, consent_type);
+CREATE UNIQUE INDEX idx_patient_consents_active
+    ON patient_consents (patient_id, consent_type) WHERE status = 'active';
+
+CREATE TRIGGER trg_patient_consents_updated_at
+    BEFORE UPDATE ON patient_consents
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE patient_consents IS 'Signed patient consents; at most one active consent per patient and type';
+COMMENT ON COLUMN patient_consents.signature IS 'SignatureResponse JSON: {"signature_data": base64, "signed_at": timestamp}';
+COMMENT ON COLUMN patient_consents.recorded_by IS 'Keycloak subject of the staff member or patient who captured the consent';
@@ -0,0 +1,77 @@
+-- Migration: 008_data_export.sql
+-- Description: Patient attachments and patient data export jobs
+-- Created: 2026-10-18
+
+-- =============================================================================
+-- PATIENT ATTACHMENTS
+-- =============================================================================
+
+CREATE TABLE patient_attachments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    clinic_id UUID NOT NULL REFERENCES clinics(id) ON DELETE CASCADE,
+    patient_id UUID NOT NULL REFERENCES patients(id) ON DELETE CASCADE,
+
+    category VARCHAR(30) NOT NULL DEFAULT 'document',  -- document, photo, imaging, referral, other
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    sha256 CHAR(64) NOT NULL,
+    blob_key VARCHAR(500) NOT NULL,
+    description TEXT,
+
+    -- Audit
+    uploaded_by UUID REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMPTZ,
+
+    CONSTRAINT chk_patient_attachment_category CHECK (
+        category IN ('document', 'photo', 'imaging', 'referral', 'other')
+    )
+);
+
+CREATE INDEX idx_patient_attachments_patient_id ON patient_attachments (patient_id, created_at DESC)
+    WHERE deleted_at IS NULL;
+
+COMMENT ON TABLE patient_attachments IS 'Files attached to a patient record; content lives in the blob store';
+COMMENT ON COLUMN patient_attachments.blob_key IS 'Key of the file in the blob store';
+
+-- =============================================================================
+-- DATA EXPORTS
+-- =============================================================================
+
+CREATE TABLE data_exports (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    clinic_id UUID NOT NULL REFERENCES clinics(id) ON DELETE CASCADE,
+    patient_id UUID NOT NULL REFERENCES patients(id) ON DELETE CASCADE,
+
+    purpose VARCHAR(30) NOT NULL,  -- patient_request, referral
+    recipient VARCHAR(255),        -- receiving clinic or practitioner for referrals
+    include_attachments BOOLEAN NOT NULL DEFAULT TRUE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',  -- pending, running, completed, failed
+
+    -- Result
+    blob_key VARCHAR(500),
+    size_bytes BIGINT,
+    sha256 CHAR(64),
+    error TEXT,
+    completed_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ,
+
+    -- Audit
+    requested_by VARCHAR(100) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_data_export_purpose CHECK (purpose IN ('patient_request', 'referral')),
+    CONSTRAINT chk_data_export_status CHECK (status IN ('pending', 'running', 'completed', 'failed'))
+);
+
+CREATE INDEX idx_data_exports_patient_id ON data_exports (patient_id, created_at DESC);
+CREATE INDEX idx_data_exports_expires_at ON data_exports (expires_at) WHERE status = 'completed';
+
+CREATE TRIGGER trg_data_exports_updated_at
+    BEFORE UPDATE ON data_exports
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE data_exports IS 'Patient record export bundles (zip) for patient requests and referrals';
+COMMENT ON COLUMN data_exports.sha256 IS 'Checksum of the finished zip archive';
@@ -0,0 +1,43 @@
+-- Migration: 009_recurrence_series.sql
+-- Description: RFC 5545 recurrence series for recurring appointments
+-- Created: 2026-10-18
+
+-- =============================================================================
+-- APPOINTMENT SERIES
+-- =============================================================================
+
+CREATE TABLE appointment_series (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    clinic_id UUID NOT NULL REFERENCES clinics(id) ON DELETE CASCADE,
+    patient_id UUID NOT NULL REFERENCES patients(id) ON DELETE CASCADE,
+    therapist_id UUID NOT NULL REFERENCES users(id),
+
+    -- Recurrence
+    rrule TEXT NOT NULL,                      -- e.g. FREQ=WEEKLY;BYDAY=MO,WE,FR;COUNT=18
+    dtstart TIMESTAMPTZ NOT NULL,
+    timezone VARCHAR(64) NOT NULL,            -- UTC offset ("+07:00") or IANA zone the rule repeats in
+    exdates TEXT[] NOT NULL DEFAULT '{}',     -- RFC 3339 start times of excluded occurrences
+
+    -- Template for each occurrence
+    duration INTEGER NOT NULL,
+    type VARCHAR(50) NOT NULL,
+    room VARCHAR(100),
+    notes TEXT,
+
+    -- Audit
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_by UUID REFERENCES users(id)
+);
+
+CREATE INDEX idx_appointment_series_clinic_id ON appointment_series (clinic_id);
+CREATE INDEX idx_appointment_series_patient_id ON appointment_series (patient_id);
+CREATE INDEX idx_appointment_series_therapist_id ON appointment_series (therapist_id);
+
+CREATE TRIGGER trg_appointment_series_updated_at
+    BEFORE UPDATE ON appointment_series
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE appointment_series IS 'Recurring appointment series defined by an RFC 5545 RRULE';
+COMMENT ON COLUMN appointment_series.exdates IS 'Occurrences left out of the series (EXDATE), including skipped conflicts';
+COMMENT ON COLUMN appointments.recurrence_id IS 'Groups recurring appointment series; references appointment_series.id';
@@ -0,0 +1,43 @@
+-- Migration: 010_therapist_schedule_management.sql
+-- Description: Per-weekday working periods and typed schedule exceptions managed through the API
+-- Created: 2026-10-18
+
+-- =============================================================================
+-- THERAPIST SCHEDULES
+-- =============================================================================
+
+-- One row per working period on a weekday; effective_from/effective_to bound
+-- the dates the period applies to. weekly_schedule is kept for existing rows.
+ALTER TABLE therapist_schedules
+    ADD COLUMN IF NOT EXISTS day_of_week SMALLINT CHECK (day_of_week BETWEEN 0 AND 6),  -- 0=Sunday
+    ADD COLUMN IF NOT EXISTS start_time TIME,
+    ADD COLUMN IF NOT EXISTS end_time TIME;
+
+ALTER TABLE therapist_schedules
+    ADD CONSTRAINT chk_therapist_schedules_times CHECK (end_time > start_time),
+    ADD CONSTRAINT chk_therapist_schedules_effective CHECK (effective_to IS NULL OR effective_to >= effective_from);
+
+CREATE INDEX IF NOT EXISTS idx_therapist_schedules_day
+    ON therapist_schedules (clinic_id, therapist_id, day_of_week)
+    WHERE is_active = TRUE;
+
+COMMENT ON COLUMN therapist_schedules.day_of_week IS 'Weekday of the working period, 0=Sunday through 6=Saturday';
+COMMENT ON COLUMN therapist_schedules.effective_to IS 'Last date the working period applies; NULL for ongoing';
+
+-- =============================================================================
+-- SCHEDULE EXCEPTIONS
+-- =============================================================================
+
+-- Exception types managed through the API: vacation, sick, training, holiday,
+-- extra_shift, other. Only extra_shift adds working time (is_available = TRUE).
+ALTER TABLE schedule_exceptions
+    ADD CONSTRAINT chk_schedule_exceptions_times CHECK (
+        (start_time IS NULL AND end_time IS NULL)
+        OR (start_time IS NOT NULL AND end_time IS NOT NULL AND end_time > start_time)
+    );
+
+CREATE INDEX IF NOT EXISTS idx_schedule_exceptions_therapist_date
+    ON schedule_exceptions (clinic_id, therapist_id, exception_date);
+
+COMMENT ON COLUMN schedule_exceptions.exception_type IS 'vacation, sick, training, holiday, extra_shift or other';
+COMMENT ON COLUMN schedule_exceptions.start_time IS 'Start of the exception; NULL with end_time for the whole day';
@@ -0,0 +1,50 @@
+-- Migration: 011_clinic_resources.sql
+-- Description: Bookable rooms and equipment per clinic, required by appointments
+-- Created: 2026-10-18
+
+-- =============================================================================
+-- CLINIC RESOURCES
+-- =============================================================================
+
+CREATE TABLE clinic_resources (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    clinic_id UUID NOT NULL REFERENCES clinics(id) ON DELETE CASCADE,
+
+    name VARCHAR(200) NOT NULL,
+    name_vi VARCHAR(200),
+    resource_type VARCHAR(20) NOT NULL CHECK (resource_type IN ('room', 'equipment')),
+    capacity INTEGER NOT NULL DEFAULT 1 CHECK (capacity >= 1),  -- concurrent appointments
+    description TEXT,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+
+    -- Audit
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_clinic_resources_name UNIQUE (clinic_id, name)
+);
+
+CREATE INDEX idx_clinic_resources_clinic_id ON clinic_resources (clinic_id, resource_type);
+
+CREATE TRIGGER trg_clinic_resources_updated_at
+    BEFORE UPDATE ON clinic_resources
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE clinic_resources IS 'Treatment rooms and equipment that appointments can require';
+COMMENT ON COLUMN clinic_resources.capacity IS 'How many appointments may use the resource at the same time';
+
+-- =============================================================================
+-- APPOINTMENT RESOURCES
+-- =============================================================================
+
+ALTER TABLE appointments
+    ADD COLUMN IF NOT EXISTS resource_ids UUID[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS idx_appointments_resource_ids
+    ON appointments USING GIN (resource_ids);
+
+ALTER TABLE appointment_series
+    ADD COLUMN IF NOT EXISTS resource_ids UUID[] NOT NULL DEFAULT '{}';
+
+COMMENT ON COLUMN appointments.resource_ids IS 'clinic_resources the appointment occupies; cancelled and no-show appointments free them';
+COMMENT ON COLUMN appointment_series.resource_ids IS 'clinic_resources required by each occurrence of the series';
@@ -0,0 +1,94 @@
+-- Migration: 012_group_sessions.sql
+-- Description: Group class sessions with a roster, attendance and waitlist
+-- Created: 2026-10-18
+
+-- =============================================================================
+-- GROUP SESSIONS
+-- =============================================================================
+
+CREATE TABLE group_sessions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    clinic_id UUID NOT NULL REFERENCES clinics(id) ON DELETE CASCADE,
+    therapist_id UUID NOT NULL REFERENCES users(id),
+    appointment_type_id UUID REFERENCES appointment_types(id),
+
+    type VARCHAR(50) NOT NULL,
+    title VARCHAR(200) NOT NULL,
+    start_time TIMESTAMPTZ NOT NULL,
+    end_time TIMESTAMPTZ NOT NULL,
+    duration INTEGER NOT NULL CHECK (duration > 0),  -- minutes
+    capacity INTEGER NOT NULL CHECK (capacity >= 2),
+    room VARCHAR(100),
+    resource_ids UUID[] NOT NULL DEFAULT '{}',
+
+    status VARCHAR(20) NOT NULL DEFAULT 'scheduled' CHECK (status IN ('scheduled', 'cancelled')),
+    notes TEXT,
+    cancellation_reason TEXT,
+
+    -- Audit
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_by UUID REFERENCES users(id),
+    updated_by UUID REFERENCES users(id),
+
+    CONSTRAINT chk_group_sessions_times CHECK (end_time > start_time)
+);
+
+CREATE INDEX idx_group_sessions_clinic_time ON group_sessions (clinic_id, start_time);
+CREATE INDEX idx_group_sessions_therapist_time ON group_sessions (therapist_id, start_time)
+    WHERE status = 'scheduled';
+CREATE INDEX idx_group_sessions_resource_ids ON group_sessions USING GIN (resource_ids);
+
+CREATE TRIGGER trg_group_sessions_updated_at
+    BEFORE UPDATE ON group_sessions
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE group_sessions IS 'Classes led by one therapist for several patients; the session blocks the therapist and its resources once';
+COMMENT ON COLUMN group_sessions.capacity IS 'Places for booked participants; further patients join the waitlist';
+
+-- =============================================================================
+-- PARTICIPANTS
+-- =============================================================================
+
+ALTER TABLE appointments
+    ADD COLUMN IF NOT EXISTS group_session_id UUID REFERENCES group_sessions(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_appointments_group_session_id
+    ON appointments (group_session_id) WHERE group_session_id IS NOT NULL;
+
+COMMENT ON COLUMN appointments.group_session_id IS 'Group session the appointment is a place in; the session, not the appointment, counts for therapist conflicts';
+
+CREATE TABLE group_session_participants (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    session_id UUID NOT NULL REFERENCES group_sessions(id) ON DELETE CASCADE,
+    patient_id UUID NOT NULL REFERENCES patients(id) ON DELETE CASCADE,
+    appointment_id UUID REFERENCES appointments(id) ON DELETE SET NULL,
+
+    status VARCHAR(20) NOT NULL DEFAULT 'booked'
+        CHECK (status IN ('booked', 'waitlisted', 'attended', 'no_show', 'cancelled')),
+    waitlist_position INTEGER CHECK (waitlist_position >= 1),
+    notes TEXT,
+
+    -- Audit
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_by UUID REFERENCES users(id),
+    updated_by UUID REFERENCES users(id),
+
+    CONSTRAINT uq_group_session_participants_patient UNIQUE (session_id, patient_id),
+    CONSTRAINT chk_group_session_participants_waitlist
+        CHECK ((status = 'waitlisted') = (waitlist_position IS NOT NULL))
+);
+
+CREATE INDEX idx_group_session_participants_session ON group_session_participants (session_id, status);
+CREATE INDEX idx_group_session_participants_patient ON group_session_participants (patient_id);
+CREATE INDEX idx_group_session_participants_appointment ON group_session_participants (appointment_id)
+    WHERE appointment_id IS NOT NULL;
+
+CREATE TRIGGER trg_group_session_participants_updated_at
+    BEFORE UPDATE ON group_session_participants
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE group_session_participants IS 'Roster of a group session: booked places with their attendance, and the waitlist';
+COMMENT ON COLUMN group_session_participants.appointment_id IS 'The participant''s own appointment, created when they are booked; kept as cancelled after a cancellation';
+COMMENT ON COLUMN group_session_participants.waitlist_position IS 'Order on the waitlist, from 1; set only while waitlisted';
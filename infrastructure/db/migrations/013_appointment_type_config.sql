@@ -0,0 +1,67 @@
+-- Migration: 013_appointment_type_config.sql
+-- Description: Per-clinic appointment types drive booking: unique codes, evaluation types and seeded defaults
+-- Created: 2026-10-18
+
+-- =============================================================================
+-- APPOINTMENT TYPES
+-- =============================================================================
+
+ALTER TABLE appointment_types
+    ADD COLUMN IF NOT EXISTS is_evaluation BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Every type needs a code, which appointments record in their type column
+UPDATE appointment_types
+SET code = 'type_' || replace(id::text, '-', '')
+WHERE code IS NULL OR code = '';
+
+ALTER TABLE appointment_types
+    ALTER COLUMN code SET NOT NULL;
+
+DROP INDEX IF EXISTS idx_appointment_types_code;
+
+ALTER TABLE appointment_types
+    ADD CONSTRAINT uq_appointment_types_clinic_code UNIQUE (clinic_id, code);
+
+COMMENT ON COLUMN appointment_types.code IS 'Stable identifier recorded in appointments.type; cannot be changed once created';
+COMMENT ON COLUMN appointment_types.requires_evaluation IS 'Patients must have completed an appointment of an evaluation type before booking this type';
+COMMENT ON COLUMN appointment_types.is_evaluation IS 'Completing an appointment of this type counts as the initial evaluation';
+
+-- =============================================================================
+-- DEFAULT TYPES
+-- =============================================================================
+
+-- Clinics without configured types get the built-in ones, matching
+-- model.DefaultAppointmentTypes
+INSERT INTO appointment_types (clinic_id, name, name_vi, code, default_duration_minutes, is_evaluation, sort_order)
+SELECT c.id, d.name, d.name_vi, d.code, d.duration, d.is_evaluation, d.sort_order
+FROM clinics c
+CROSS JOIN (VALUES
+    ('Assessment', 'Đánh giá', 'assessment', 60, TRUE, 1),
+    ('Treatment', 'Điều trị', 'treatment', 45, FALSE, 2),
+    ('Follow-up', 'Tái khám', 'followup', 30, FALSE, 3),
+    ('Consultation', 'Tư vấn', 'consultation', 30, FALSE, 4),
+    ('Other', 'Khác', 'other', 30, FALSE, 5)
+) AS d (name, name_vi, code, duration, is_evaluation, sort_order)
+WHERE NOT EXISTS (
+    SELECT 1 FROM appointment_types t WHERE t.clinic_id = c.id
+);
+
+-- =============================================================================
+-- APPOINTMENTS
+-- =============================================================================
+
+UPDATE appointments a
+SET appointment_type_id = t.id
+FROM appointment_types t
+WHERE a.appointment_type_id IS NULL
+  AND t.clinic_id = a.clinic_id
+  AND t.code = a.type::text;
+
+UPDATE group_sessions gs
+SET appointment_type_id = t.id
+FROM appointment_types t
+WHERE gs.appointment_type_id IS NULL
+  AND t.clinic_id = gs.clinic_id
+  AND t.code = gs.type;
+
+COMMENT ON COLUMN appointments.appointment_type_id IS 'The clinic''s configured type whose code is in type';
@@ -0,0 +1,48 @@
+-- Migration: 014_no_show_tracking.sql
+-- Description: Cancellation timestamps and indexes for no-show and late-cancellation tracking
+-- Created: 2026-10-18
+
+-- =============================================================================
+-- CANCELLATION TIMESTAMPS
+-- =============================================================================
+
+-- Late cancellations are counted from when an appointment was cancelled, so
+-- every path that cancels an appointment records the time
+CREATE OR REPLACE FUNCTION set_appointment_cancelled_at()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.status = 'cancelled' THEN
+        IF TG_OP = 'INSERT' OR OLD.status IS DISTINCT FROM 'cancelled' THEN
+            NEW.cancelled_at = NOW();
+        END IF;
+    ELSE
+        NEW.cancelled_at = NULL;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_appointments_cancelled_at
+    BEFORE INSERT OR UPDATE OF status ON appointments
+    FOR EACH ROW EXECUTE FUNCTION set_appointment_cancelled_at();
+
+-- Earlier cancellations have no timestamp; the last update is the best estimate
+UPDATE appointments
+SET cancelled_at = updated_at
+WHERE status = 'cancelled' AND cancelled_at IS NULL;
+
+COMMENT ON COLUMN appointments.cancelled_at IS 'When the appointment was cancelled; set by trigger and compared with start_time to find late cancellations';
+
+-- =============================================================================
+-- INDEXES
+-- =============================================================================
+
+CREATE INDEX IF NOT EXISTS idx_appointments_patient_attendance
+    ON appointments (clinic_id, patient_id, start_time)
+    WHERE status IN ('no_show', 'cancelled');
+
+CREATE INDEX IF NOT EXISTS idx_appointments_awaiting_check_in
+    ON appointments (start_time)
+    WHERE status IN ('scheduled', 'confirmed') AND group_session_id IS NULL;
+
+COMMENT ON COLUMN clinics.settings IS 'Clinic-specific settings (hours, services, patient_portal, no_show_policy: grace_period_minutes, late_cancel_notice_hours, threshold, action warn|deposit|block, lookback_days)';
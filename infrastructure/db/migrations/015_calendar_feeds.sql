@@ -0,0 +1,39 @@
+-- Migration: 015_calendar_feeds.sql
+-- Description: Secret-token iCalendar feed subscriptions for therapists and patients
+-- Created: 2026-10-18
+
+-- =============================================================================
+-- CALENDAR FEEDS
+-- =============================================================================
+
+CREATE TABLE calendar_feeds (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    clinic_id UUID NOT NULL REFERENCES clinics(id) ON DELETE CASCADE,
+
+    -- Whose appointments the feed publishes: a therapist (user) or a patient
+    kind VARCHAR(20) NOT NULL,
+    owner_id UUID NOT NULL,
+
+    -- Only a hash of the secret token is stored; the token itself is shown once
+    token_hash VARCHAR(64) NOT NULL,
+
+    -- Revocation
+    revoked_at TIMESTAMPTZ,
+    revoked_by VARCHAR(100),
+
+    -- Audit
+    created_by VARCHAR(100) NOT NULL,
+    last_accessed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_calendar_feed_kind CHECK (kind IN ('therapist', 'patient')),
+    CONSTRAINT uq_calendar_feed_token UNIQUE (token_hash)
+);
+
+-- At most one live feed per owner; issuing a new one revokes the old
+CREATE UNIQUE INDEX idx_calendar_feeds_active_owner ON calendar_feeds (clinic_id, kind, owner_id)
+    WHERE revoked_at IS NULL;
+
+COMMENT ON TABLE calendar_feeds IS 'Secret-token iCalendar subscriptions served at /ical/{kind}/{token}.ics without other authentication';
+COMMENT ON COLUMN calendar_feeds.token_hash IS 'Hex SHA-256 of the feed token';
+COMMENT ON COLUMN calendar_feeds.owner_id IS 'Therapist user ID for therapist feeds, patient ID for patient feeds';
@@ -0,0 +1,25 @@
+-- Migration: 016_status_board.sql
+-- Description: Patient check-ins for the real-time clinic status board
+-- Created: 2026-10-18
+
+-- =============================================================================
+-- APPOINTMENT CHECK-INS
+-- =============================================================================
+
+CREATE TABLE appointment_check_ins (
+    appointment_id UUID PRIMARY KEY REFERENCES appointments(id) ON DELETE CASCADE,
+    clinic_id UUID NOT NULL REFERENCES clinics(id) ON DELETE CASCADE,
+
+    -- How the patient checked in: at the front desk or at a kiosk
+    method VARCHAR(20) NOT NULL DEFAULT 'front_desk',
+
+    checked_in_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    checked_in_by UUID REFERENCES users(id),
+
+    CONSTRAINT chk_check_in_method CHECK (method IN ('front_desk', 'kiosk'))
+);
+
+CREATE INDEX idx_appointment_check_ins_clinic ON appointment_check_ins (clinic_id, checked_in_at);
+
+COMMENT ON TABLE appointment_check_ins IS 'Patient arrivals; an appointment with a check-in is never marked as a no-show';
+COMMENT ON COLUMN appointment_check_ins.checked_in_by IS 'Staff member who checked the patient in; NULL for self check-in';
@@ -0,0 +1,46 @@
+-- Migration: 017_kiosk_check_in.sql
+-- Description: Kiosk devices and patient self check-in with QR codes
+-- Created: 2026-10-18
+
+-- =============================================================================
+-- KIOSK DEVICES
+-- =============================================================================
+
+CREATE TABLE kiosk_devices (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    clinic_id UUID NOT NULL REFERENCES clinics(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+
+    -- Only a hash of the device token is stored; the token itself is shown once
+    token_hash VARCHAR(64) NOT NULL,
+
+    -- Revocation
+    revoked_at TIMESTAMPTZ,
+    revoked_by VARCHAR(100),
+
+    -- Audit
+    created_by VARCHAR(100) NOT NULL,
+    last_seen_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_kiosk_device_token UNIQUE (token_hash)
+);
+
+CREATE INDEX idx_kiosk_devices_clinic ON kiosk_devices (clinic_id);
+
+COMMENT ON TABLE kiosk_devices IS 'Self check-in kiosks; a device token only permits kiosk check-in for its clinic';
+COMMENT ON COLUMN kiosk_devices.token_hash IS 'Hex SHA-256 of the device token';
+
+-- =============================================================================
+-- SELF CHECK-IN
+-- =============================================================================
+
+ALTER TABLE appointment_check_ins
+    ADD COLUMN kiosk_id UUID REFERENCES kiosk_devices(id) ON DELETE SET NULL,
+    ADD COLUMN demographics_confirmed BOOLEAN,
+    ADD COLUMN insurance_confirmed BOOLEAN,
+    ADD COLUMN patient_notes TEXT;
+
+COMMENT ON COLUMN appointment_check_ins.demographics_confirmed IS 'Whether the patient confirmed their contact details at the kiosk; NULL when checked in by staff';
+COMMENT ON COLUMN appointment_check_ins.insurance_confirmed IS 'Whether the patient confirmed their insurance is still valid; NULL when checked in by staff';
+COMMENT ON COLUMN appointment_check_ins.patient_notes IS 'Corrections the patient reported at the kiosk, for the front desk';
@@ -0,0 +1,92 @@
+-- Migration: 018_exercise_progression.sql
+-- Description: Declarative progression rules for exercise prescriptions and
+--              the history of prescription parameter changes
+-- Created: 2026-10-18
+--
+-- prescription_id columns carry no foreign key because exercise_prescriptions
+-- is not created by the numbered migrations yet.
+
+-- =============================================================================
+-- PROGRESSION RULES
+-- =============================================================================
+
+CREATE TABLE exercise_progression_rules (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    clinic_id UUID NOT NULL REFERENCES clinics(id) ON DELETE CASCADE,
+    prescription_id UUID NOT NULL,
+
+    -- What to change and by how much, e.g. reps by +2 up to 20
+    parameter VARCHAR(20) NOT NULL,
+    step INTEGER NOT NULL,
+    max_value INTEGER,
+
+    -- How often to evaluate, and what the patient's logs in each window must show
+    interval_days INTEGER NOT NULL DEFAULT 7,
+    min_sessions INTEGER NOT NULL DEFAULT 1,
+    max_pain INTEGER,
+    max_difficulty VARCHAR(20),
+
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    window_start TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    created_by UUID REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_progression_parameter CHECK (parameter IN ('sets', 'reps', 'hold_seconds')),
+    CONSTRAINT chk_progression_step CHECK (step > 0),
+    CONSTRAINT chk_progression_interval CHECK (interval_days > 0),
+    CONSTRAINT chk_progression_max_pain CHECK (max_pain BETWEEN 0 AND 10),
+    CONSTRAINT chk_progression_max_difficulty CHECK (max_difficulty IN ('easy', 'moderate', 'hard'))
+);
+
+CREATE INDEX idx_progression_rules_prescription ON exercise_progression_rules (prescription_id);
+CREATE INDEX idx_progression_rules_due ON exercise_progression_rules (window_start) WHERE is_active;
+
+CREATE TRIGGER trg_exercise_progression_rules_updated_at
+    BEFORE UPDATE ON exercise_progression_rules
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE exercise_progression_rules IS 'Rules that progress a prescription when the patient''s compliance logs meet their conditions';
+COMMENT ON COLUMN exercise_progression_rules.max_value IS 'Highest value the rule progresses to; NULL for the prescription limit';
+COMMENT ON COLUMN exercise_progression_rules.max_pain IS 'Highest pain level logged in the window that still allows progression';
+COMMENT ON COLUMN exercise_progression_rules.max_difficulty IS 'Hardest difficulty logged in the window that still allows progression';
+COMMENT ON COLUMN exercise_progression_rules.window_start IS 'Start of the window of compliance logs the next evaluation looks at';
+
+-- =============================================================================
+-- PRESCRIPTION CHANGES
+-- =============================================================================
+
+CREATE TABLE exercise_prescription_changes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    clinic_id UUID NOT NULL REFERENCES clinics(id) ON DELETE CASCADE,
+    prescription_id UUID NOT NULL,
+    patient_id UUID NOT NULL REFERENCES patients(id) ON DELETE CASCADE,
+    rule_id UUID REFERENCES exercise_progression_rules(id) ON DELETE SET NULL,
+
+    -- rule: proposed by a progression rule; manual: edited by a therapist
+    source VARCHAR(20) NOT NULL,
+    parameter VARCHAR(20) NOT NULL,
+    from_value INTEGER NOT NULL,
+    to_value INTEGER NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    reason TEXT,
+    decision_note TEXT,
+
+    proposed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    decided_at TIMESTAMPTZ,
+    decided_by UUID REFERENCES users(id),
+
+    CONSTRAINT chk_prescription_change_source CHECK (source IN ('rule', 'manual')),
+    CONSTRAINT chk_prescription_change_parameter CHECK (parameter IN ('sets', 'reps', 'hold_seconds', 'duration_weeks')),
+    CONSTRAINT chk_prescription_change_status CHECK (status IN ('proposed', 'applied', 'rejected'))
+);
+
+CREATE INDEX idx_prescription_changes_prescription ON exercise_prescription_changes (prescription_id, proposed_at DESC);
+CREATE INDEX idx_prescription_changes_pending ON exercise_prescription_changes (clinic_id, proposed_at) WHERE status = 'proposed';
+CREATE UNIQUE INDEX idx_prescription_changes_one_pending ON exercise_prescription_changes (rule_id) WHERE status = 'proposed';
+
+COMMENT ON TABLE exercise_prescription_changes IS 'History of prescription parameter changes, including progressions awaiting approval';
+COMMENT ON COLUMN exercise_prescription_changes.decided_by IS 'Therapist who approved, rejected or made the change; NULL when applied automatically';
+COMMENT ON COLUMN exercise_prescription_changes.reason IS 'What the rule found in the compliance logs';
+COMMENT ON COLUMN exercise_prescription_changes.decision_note IS 'Therapist''s note when approving or rejecting';
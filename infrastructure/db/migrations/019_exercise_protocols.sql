@@ -0,0 +1,77 @@
+-- Migration: 019_exercise_protocols.sql
+-- Description: Clinic exercise protocol templates and the phases of the home
+--              exercise programs instantiated from them
+-- Created: 2026-10-18
+--
+-- program_id carries no foreign key because home_exercise_programs is not
+-- created by the numbered migrations yet.
+
+-- =============================================================================
+-- PROTOCOL TEMPLATES
+-- =============================================================================
+
+CREATE TABLE exercise_protocols (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    clinic_id UUID NOT NULL REFERENCES clinics(id) ON DELETE CASCADE,
+
+    name VARCHAR(200) NOT NULL,
+    name_vi VARCHAR(200),
+    description TEXT,
+    description_vi TEXT,
+    frequency VARCHAR(50) NOT NULL,
+
+    -- Ordered phases, each with a duration and exercises with default parameters
+    phases JSONB NOT NULL DEFAULT '[]',
+    version INTEGER NOT NULL DEFAULT 1,
+
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by UUID REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_exercise_protocols_clinic ON exercise_protocols (clinic_id, name);
+
+CREATE TRIGGER trg_exercise_protocols_updated_at
+    BEFORE UPDATE ON exercise_protocols
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE exercise_protocols IS 'Reusable phased exercise programs, e.g. ACL reconstruction or frozen shoulder';
+COMMENT ON COLUMN exercise_protocols.version IS 'Incremented on every edit; programs record the version they were created from';
+
+-- =============================================================================
+-- PROGRAM PHASES
+-- =============================================================================
+
+CREATE TABLE home_exercise_program_phases (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    program_id UUID NOT NULL,
+    clinic_id UUID NOT NULL REFERENCES clinics(id) ON DELETE CASCADE,
+    protocol_id UUID REFERENCES exercise_protocols(id) ON DELETE SET NULL,
+    protocol_version INTEGER NOT NULL,
+
+    phase_number INTEGER NOT NULL,
+    name VARCHAR(200) NOT NULL,
+    name_vi VARCHAR(200),
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+
+    -- Copy of the phase's exercises with the patient's overrides applied, so
+    -- that later template edits do not change the program
+    exercises JSONB NOT NULL DEFAULT '[]',
+    prescription_ids UUID[] NOT NULL DEFAULT '{}',
+
+    status VARCHAR(20) NOT NULL DEFAULT 'upcoming',
+    activated_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+
+    CONSTRAINT uq_program_phase UNIQUE (program_id, phase_number),
+    CONSTRAINT chk_program_phase_dates CHECK (end_date > start_date),
+    CONSTRAINT chk_program_phase_status CHECK (status IN ('upcoming', 'active', 'completed'))
+);
+
+CREATE INDEX idx_program_phases_upcoming ON home_exercise_program_phases (start_date) WHERE status = 'upcoming';
+CREATE INDEX idx_program_phases_active ON home_exercise_program_phases (end_date) WHERE status = 'active';
+
+COMMENT ON TABLE home_exercise_program_phases IS 'Phases of programs created from protocols; each phase gets its prescriptions when it starts';
+COMMENT ON COLUMN home_exercise_program_phases.prescription_ids IS 'Prescriptions created when the phase started, completed when it ends';
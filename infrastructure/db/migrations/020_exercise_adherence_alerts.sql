@@ -0,0 +1,52 @@
+-- Migration: 020_exercise_adherence_alerts.sql
+-- Description: Weekly low exercise adherence alerts for the therapists' dashboard
+-- Created: 2026-10-18
+--
+-- Adherence itself is computed from exercise_prescriptions and
+-- exercise_compliance_logs when requested; only the alerts are stored, so
+-- that each one is nudged and acknowledged once.
+
+-- =============================================================================
+-- ADHERENCE ALERTS
+-- =============================================================================
+
+CREATE TABLE exercise_adherence_alerts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    clinic_id UUID NOT NULL REFERENCES clinics(id) ON DELETE CASCADE,
+    patient_id UUID NOT NULL REFERENCES patients(id) ON DELETE CASCADE,
+
+    -- Monday of the clinic week the alert belongs to
+    week_start DATE NOT NULL,
+    window_start TIMESTAMPTZ NOT NULL,
+    window_end TIMESTAMPTZ NOT NULL,
+
+    expected_sessions DOUBLE PRECISION NOT NULL,
+    completed_sessions INTEGER NOT NULL,
+    adherence DOUBLE PRECISION NOT NULL,
+    previous_adherence DOUBLE PRECISION,
+    threshold DOUBLE PRECISION NOT NULL,
+
+    -- Therapists who prescribed the patient's exercises
+    therapist_ids UUID[] NOT NULL DEFAULT '{}',
+
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    nudged_at TIMESTAMPTZ,
+    acknowledged_at TIMESTAMPTZ,
+    acknowledged_by UUID REFERENCES users(id),
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_adherence_alert_week UNIQUE (patient_id, week_start),
+    CONSTRAINT chk_adherence_alert_status CHECK (status IN ('open', 'acknowledged', 'resolved'))
+);
+
+CREATE INDEX idx_adherence_alerts_open ON exercise_adherence_alerts (clinic_id, week_start) WHERE status = 'open';
+CREATE INDEX idx_adherence_alerts_therapists ON exercise_adherence_alerts USING GIN (therapist_ids);
+
+CREATE TRIGGER trg_exercise_adherence_alerts_updated_at
+    BEFORE UPDATE ON exercise_adherence_alerts
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE exercise_adherence_alerts IS 'Patients whose exercise adherence fell below the clinic threshold in a week';
+COMMENT ON COLUMN exercise_adherence_alerts.adherence IS 'Completed over expected sessions for the trailing 7 days, from 0 to 1';
@@ -0,0 +1,31 @@
+-- Migration: 021_frequency_schedules.sql
+-- Description: Structured frequency schedules for exercise prescriptions and
+--              home exercise programs
+-- Created: 2026-10-18
+--
+-- exercise_prescriptions and home_exercise_programs are not created by the
+-- numbered migrations yet, so the columns are only added where they exist.
+-- Existing free-text frequencies are parsed once, after this migration, by
+-- the backfill-frequencies command (apps/api/cmd/backfill-frequencies); those
+-- it does not understand, e.g. "as needed", keep a NULL schedule.
+
+-- =============================================================================
+-- FREQUENCY SCHEDULES
+-- =============================================================================
+
+ALTER TABLE IF EXISTS exercise_prescriptions
+    ADD COLUMN IF NOT EXISTS frequency_schedule JSONB;
+
+ALTER TABLE IF EXISTS home_exercise_programs
+    ADD COLUMN IF NOT EXISTS frequency_schedule JSONB;
+
+DO $$
+BEGIN
+    IF to_regclass('exercise_prescriptions') IS NOT NULL THEN
+        COMMENT ON COLUMN exercise_prescriptions.frequency_schedule IS 'Times per day, days per week or weekdays, and times of day; NULL when frequency could not be parsed';
+    END IF;
+    IF to_regclass('home_exercise_programs') IS NOT NULL THEN
+        COMMENT ON COLUMN home_exercise_programs.frequency_schedule IS 'Times per day, days per week or weekdays, and times of day; NULL when frequency could not be parsed';
+    END IF;
+END;
+$$;
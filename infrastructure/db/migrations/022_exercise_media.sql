@@ -0,0 +1,49 @@
+-- Migration: 022_exercise_media.sql
+-- Description: Demonstration photos and clips uploaded for exercises
+-- Created: 2026-10-18
+--
+-- The files live in the blob store; this table holds their order, captions
+-- and metadata. exercises.image_urls and video_url remain for external links.
+
+-- =============================================================================
+-- EXERCISE MEDIA
+-- =============================================================================
+
+CREATE TABLE exercise_media (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    exercise_id UUID NOT NULL REFERENCES exercises(id) ON DELETE CASCADE,
+    clinic_id UUID NOT NULL REFERENCES clinics(id) ON DELETE CASCADE,
+
+    kind VARCHAR(10) NOT NULL,
+    position INTEGER NOT NULL,
+    caption VARCHAR(500),
+    caption_vi VARCHAR(500),
+
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    sha256 CHAR(64) NOT NULL,
+    blob_key TEXT NOT NULL,
+    thumbnail_key TEXT,
+
+    -- Read from the file when possible
+    width INTEGER,
+    height INTEGER,
+    duration_seconds DOUBLE PRECISION,
+
+    created_by UUID REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_exercise_media_kind CHECK (kind IN ('image', 'video'))
+);
+
+CREATE INDEX idx_exercise_media_exercise ON exercise_media (exercise_id, position);
+
+CREATE TRIGGER trg_exercise_media_updated_at
+    BEFORE UPDATE ON exercise_media
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE exercise_media IS 'Demonstration photos and short clips uploaded by clinics for their exercises';
+COMMENT ON COLUMN exercise_media.position IS 'Display order within the exercise, from 1';
+COMMENT ON COLUMN exercise_media.thumbnail_key IS 'Blob key of a video thumbnail; NULL when none could be made';
@@ -0,0 +1,66 @@
+-- Seed file: consents.sql
+-- Description: Initial global consent texts for PhysioFlow
+-- Created: 2026-10-18
+
+-- =============================================================================
+-- CONSENT TEMPLATES (VERSION 1)
+-- =============================================================================
+
+INSERT INTO consent_templates (
+    id, clinic_id, consent_type, version, is_current, title, title_vi, body, body_vi
+) VALUES
+(
+    '77777777-7777-7777-7777-777777777701',
+    NULL,  -- Global template
+    'treatment',
+    1,
+    TRUE,
+    'Consent to Physiotherapy Treatment',
+    'Đồng ý điều trị vật lý trị liệu',
+    'I consent to assessment and physiotherapy treatment by the clinic''s therapists. The proposed treatment, its expected benefits and risks have been explained to me, and I may ask questions or stop treatment at any time.',
+    'Tôi đồng ý được đánh giá và điều trị vật lý trị liệu bởi các chuyên viên của phòng khám. Phương pháp điều trị, lợi ích và rủi ro dự kiến đã được giải thích cho tôi, và tôi có thể đặt câu hỏi hoặc ngừng điều trị bất kỳ lúc nào.'
+),
+(
+    '77777777-7777-7777-7777-777777777702',
+    NULL,
+    'data_processing',
+    1,
+    TRUE,
+    'Consent to Processing of Personal Data',
+    'Đồng ý xử lý dữ liệu cá nhân',
+    'In accordance with Decree 13/2023/ND-CP, I consent to the clinic collecting and processing my personal and health data to provide care, send appointment reminders, manage insurance claims and keep medical records. I may withdraw this consent at any time; withdrawal does not affect processing already carried out.',
+    'Theo Nghị định 13/2023/NĐ-CP, tôi đồng ý để phòng khám thu thập và xử lý dữ liệu cá nhân và dữ liệu sức khỏe của tôi nhằm cung cấp dịch vụ chăm sóc, gửi nhắc lịch hẹn, xử lý bảo hiểm và lưu trữ hồ sơ bệnh án. Tôi có thể rút lại sự đồng ý bất kỳ lúc nào; việc rút lại không ảnh hưởng đến việc xử lý đã thực hiện trước đó.'
+),
+(
+    '77777777-7777-7777-7777-777777777703',
+    NULL,
+    'marketing_sms',
+    1,
+    TRUE,
+    'Consent to SMS Marketing',
+    'Đồng ý nhận tin nhắn quảng cáo qua SMS',
+    'I agree to receive news, health tips and promotional offers from the clinic by SMS. I can opt out at any time.',
+    'Tôi đồng ý nhận tin tức, lời khuyên sức khỏe và ưu đãi từ phòng khám qua tin nhắn SMS. Tôi có thể từ chối bất kỳ lúc nào.'
+),
+(
+    '77777777-7777-7777-7777-777777777704',
+    NULL,
+    'marketing_zalo',
+    1,
+    TRUE,
+    'Consent to Zalo Marketing',
+    'Đồng ý nhận tin nhắn quảng cáo qua Zalo',
+    'I agree to receive news, health tips and promotional offers from the clinic on Zalo. I can opt out at any time.',
+    'Tôi đồng ý nhận tin tức, lời khuyên sức khỏe và ưu đãi từ phòng khám qua Zalo. Tôi có thể từ chối bất kỳ lúc nào.'
+),
+(
+    '77777777-7777-7777-7777-777777777705',
+    NULL,
+    'photo_use',
+    1,
+    TRUE,
+    'Consent to Clinical Photography',
+    'Đồng ý chụp ảnh lâm sàng',
+    'I consent to photographs and videos being taken of me for my clinical record and to track my progress. They will not be shared outside the clinic without my separate permission.',
+    'Tôi đồng ý để phòng khám chụp ảnh và quay video tôi nhằm lưu vào hồ sơ bệnh án và theo dõi tiến triển điều trị. Hình ảnh sẽ không được chia sẻ ra ngoài phòng khám nếu không có sự cho phép riêng của tôi.'
+);